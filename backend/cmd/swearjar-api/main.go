@@ -41,6 +41,21 @@ func main() {
 				URL:        chCfg.MustString("DBURL"),
 				ClientName: "swearjar",
 				ClientTag:  "api",
+
+				// traced args are masked unless explicitly turned off (repo names/logins are PII)
+				LogRedact:          chCfg.MayEnum("LOG_REDACT", "strings", "off", "strings", "all"),
+				LogRedactKeepShort: chCfg.MayInt("LOG_REDACT_KEEP_SHORT", 0),
+				LogRedactAllow:     chCfg.MayCSV("LOG_REDACT_ALLOW", nil),
+
+				// dashboard polls repeat the same reads; opt-in short TTL cache
+				QueryCacheTTL:        chCfg.MayDuration("QUERY_CACHE_TTL", 0),
+				QueryCacheMaxEntries: chCfg.MayInt("QUERY_CACHE_MAX_ENTRIES", 512),
+
+				// long-lived process: recover from CH restarts without a redeploy
+				HealthInterval: chCfg.MayDuration("HEALTH_INTERVAL", 0),
+
+				// clustered deployments map logical tables onto their Distributed tables
+				Tables: chCfg.MayCSV("TABLES", nil),
 			},
 		},
 		store.WithLogger(*logger.Get()),
@@ -3,8 +3,9 @@ package main
 import (
 	"context"
 	"flag"
+	"fmt"
 	"os"
-	"strconv"
+	"text/tabwriter"
 	"time"
 
 	"swearjar/internal/modkit"
@@ -13,6 +14,7 @@ import (
 	"swearjar/internal/platform/logger"
 	"swearjar/internal/platform/store"
 
+	backfilldom "swearjar/internal/services/backfill/domain"
 	backfillmod "swearjar/internal/services/backfill/module"
 	detectdom "swearjar/internal/services/detect/domain"
 	detectmod "swearjar/internal/services/detect/module"
@@ -21,12 +23,6 @@ import (
 	utmod "swearjar/internal/services/utterances/module"
 )
 
-func mustSetEnv(key, val string) {
-	if val != "" {
-		_ = os.Setenv(key, val)
-	}
-}
-
 func main() {
 	root := config.New()
 	pgCfg := root.Prefix("SERVICE_PGSQL_")
@@ -47,6 +43,20 @@ func main() {
 			LogSQL:     chCfg.MayBool("LOG_SQL", false),
 			ClientName: "swearjar",
 			ClientTag:  "backfill",
+
+			// traced args are masked unless explicitly turned off (repo names/logins are PII)
+			LogRedact:          chCfg.MayEnum("LOG_REDACT", "strings", "off", "strings", "all"),
+			LogRedactKeepShort: chCfg.MayInt("LOG_REDACT_KEEP_SHORT", 0),
+			LogRedactAllow:     chCfg.MayCSV("LOG_REDACT_ALLOW", nil),
+
+			AsyncInsertTables: chCfg.MayCSV("ASYNC_INSERT_TABLES", nil),
+			AsyncInsertWait:   chCfg.MayBool("ASYNC_INSERT_WAIT", false),
+			InsertSettings:    chCfg.MayCSV("INSERT_SETTINGS", nil),
+
+			MaxConcurrentInserts: chCfg.MayInt("MAX_CONCURRENT_INSERTS", 0),
+
+			// clustered deployments map logical tables onto their Distributed tables
+			Tables: chCfg.MayCSV("TABLES", nil),
 		},
 	}, store.WithLogger(*l))
 	if err != nil {
@@ -63,8 +73,14 @@ func main() {
 		fEnd      = flag.String("end", "", "UTC end hour YYYY-MM-DDTHH inclusive")
 		fDetect   = flag.Bool("detect", false, "also run detection and write hits during backfill")
 		fDetVer   = flag.Int("detver", 1, "detector version to stamp into hits (when --detect)")
+		fForceDet = flag.Bool("force-detect", false, "re-run detection for hours already detected at --detver")
 		fPlanOnly = flag.Bool("plan-only", false, "seed ingest_hours for the range and exit without processing")
 		fResume   = flag.Bool("resume", false, "ignore -start/-end and drain any pending/error hours")
+		fMode     = flag.String("mode", "run", "run | status (status prints range progress from ingest_hours and exits)")
+		fMaxEv    = flag.Int("max-events", 0, "stop each hour after N events (smoke tests; 0 = unlimited)")
+		fDir      = flag.String("dir", "", "read hours from <dir>/<hour>.json.gz instead of gharchive.org (offline)")
+		fCollapse = flag.String("collapse-dupes", "", "fold identical texts per hour before insert: repo | global")
+		fPipeline = flag.Int("pipeline", 0, "insert while reading, queueing at most N chunks (0 = read whole hour first)")
 
 		// Nightshift flags
 		fNightshift  = flag.Bool("nightshift", false, "run Nightshift after backfill for the same range")
@@ -77,9 +93,18 @@ func main() {
 	flag.Parse()
 
 	// Validate flag combos
+	if *fMode != "run" && *fMode != "status" {
+		l.Panic().Str("mode", *fMode).Msg("-mode must be run or status")
+	}
+	if *fMode == "status" && *fResume {
+		l.Panic().Msg("-mode status requires -start/-end and cannot be combined with --resume")
+	}
 	if *fPlanOnly && *fResume {
 		l.Panic().Msg("--plan-only and --resume are mutually exclusive")
 	}
+	if *fCollapse != "" && *fCollapse != "repo" && *fCollapse != "global" {
+		l.Panic().Str("collapse-dupes", *fCollapse).Msg("-collapse-dupes must be repo or global")
+	}
 
 	if !*fResume && (*fStart == "" || *fEnd == "") {
 		l.Panic().Msg("must provide -start and -end (unless --resume or --ns-resume)")
@@ -111,16 +136,6 @@ func main() {
 		Log: *l,
 	}
 
-	// Surface opts to modules that read FromConfig
-	mustSetEnv("CORE_BACKFILL_DETECT", map[bool]string{true: "1", false: "0"}[*fDetect])
-	mustSetEnv("CORE_DETECT_VERSION", strconv.Itoa(*fDetVer))
-
-	// Nightshift envs: modules/nightshift/module/options.go reads CORE_NIGHTSHIFT_*
-	mustSetEnv("CORE_NIGHTSHIFT_WORKERS", strconv.Itoa(*fNSWorkers))
-	mustSetEnv("CORE_NIGHTSHIFT_DET_VERSION", strconv.Itoa(*fNSDetVer))
-	mustSetEnv("CORE_NIGHTSHIFT_RETENTION_MODE", *fNSRetention)
-	mustSetEnv("CORE_NIGHTSHIFT_LEASES", map[bool]string{true: "1", false: "0"}[*fNSLeases])
-
 	// Optional: Detect stack (when --detect)
 	if *fDetect {
 		ut := utmod.New(deps)
@@ -139,11 +154,24 @@ func main() {
 	}
 
 	// Nightshift module (always register; running is controlled by flags)
-	ns := nightshiftmod.New(deps)
+	ns := nightshiftmod.New(deps, nightshiftmod.Options{
+		Workers:         *fNSWorkers,
+		DetectorVersion: *fNSDetVer,
+		RetentionMode:   *fNSRetention,
+		EnableLeases:    *fNSLeases,
+	})
 	module.Register(ns.Name(), ns.Ports())
 
 	// Backfill module
-	bf := backfillmod.New(deps)
+	bf := backfillmod.New(deps, backfillmod.Options{
+		DetectEnabled:    *fDetect,
+		DetectVersion:    *fDetVer,
+		ForceDetect:      *fForceDet,
+		MaxEventsPerHour: *fMaxEv,
+		LocalDir:         *fDir,
+		CollapseDupes:    *fCollapse,
+		PipelineDepth:    *fPipeline,
+	})
 	module.Register(bf.Name(), bf.Ports())
 
 	ctx := context.Background()
@@ -160,6 +188,14 @@ func main() {
 	// Plan-only / resume / run-range for Backfill
 	bfPorts := bf.Ports().(backfillmod.Ports)
 	switch {
+	case *fMode == "status":
+		p, err := bfPorts.Runner.Progress(ctx, start.UTC(), end.UTC())
+		if err != nil {
+			l.Fatal().Err(err).Msg("backfill status failed")
+		}
+		printProgress(p)
+		return
+
 	case *fPlanOnly:
 		if err := bfPorts.Runner.PlanRange(ctx, start.UTC(), end.UTC()); err != nil {
 			l.Fatal().Err(err).Msg("backfill plan-only failed")
@@ -192,3 +228,44 @@ func main() {
 		}
 	}
 }
+
+// printProgress writes a human-readable summary of a backfill range to stdout
+func printProgress(p backfilldom.RangeProgress) {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer func() { _ = tw.Flush() }()
+
+	pct := func(n int) string {
+		if p.TotalHours == 0 {
+			return "0.0%"
+		}
+		return fmt.Sprintf("%.1f%%", 100*float64(n)/float64(p.TotalHours))
+	}
+	ts := func(t *time.Time) string {
+		if t == nil {
+			return "-"
+		}
+		return t.Format(time.RFC3339)
+	}
+
+	_, _ = fmt.Fprintf(tw, "range\t%s .. %s\t(%d hours)\n",
+		p.Start.Format("2006-01-02T15"), p.End.Format("2006-01-02T15"), p.TotalHours)
+	for _, st := range []backfilldom.BackfillStatus{
+		backfilldom.BackfillOK,
+		backfilldom.BackfillRunning,
+		backfilldom.BackfillPending,
+		backfilldom.BackfillError,
+	} {
+		n := p.ByStatus[st]
+		_, _ = fmt.Fprintf(tw, "%s\t%d\t%s\n", st, n, pct(n))
+	}
+	_, _ = fmt.Fprintf(tw, "unplanned\t%d\t%s\n", p.Unplanned, pct(p.Unplanned))
+	_, _ = fmt.Fprintf(tw, "bytes_uncompressed\t%d\t\n", p.BytesUncompressed)
+	_, _ = fmt.Fprintf(tw, "events_scanned\t%d\t\n", p.EventsScanned)
+	_, _ = fmt.Fprintf(tw, "utterances\t%d\t\n", p.Utterances)
+	_, _ = fmt.Fprintf(tw, "inserted\t%d\t\n", p.Inserted)
+	_, _ = fmt.Fprintf(tw, "deduped\t%d\t\n", p.Deduped)
+	_, _ = fmt.Fprintf(tw, "collapsed\t%d\t\n", p.Collapsed)
+	_, _ = fmt.Fprintf(tw, "skipped_short\t%d\t\n", p.SkippedShort)
+	_, _ = fmt.Fprintf(tw, "first_started\t%s\t\n", ts(p.FirstStarted))
+	_, _ = fmt.Fprintf(tw, "last_finished\t%s\t\n", ts(p.LastFinished))
+}
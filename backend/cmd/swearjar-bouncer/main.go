@@ -3,7 +3,6 @@ package main
 import (
 	"context"
 	"flag"
-	"fmt"
 	"os"
 
 	"swearjar/internal/modkit"
@@ -15,12 +14,6 @@ import (
 	bouncermod "swearjar/internal/services/bouncer/module"
 )
 
-func mustSetEnv(key, val string) {
-	if val != "" {
-		_ = os.Setenv(key, val)
-	}
-}
-
 func main() {
 	root := config.New()
 	dbCfg := root.Prefix("SERVICE_PGSQL_")
@@ -47,10 +40,13 @@ func main() {
 
 	// Flags (same spirit as hallmonitor)
 	var (
+		fMode   = flag.String("mode", "worker", "bouncer mode: worker | drain (process up to -limit due jobs, then exit)")
+		fLimit  = flag.Int("limit", 1000, "in drain mode, max jobs to process")
 		fConc   = flag.Int("concurrency", 4, "worker concurrency")
 		fRPS    = flag.Float64("rps", 2.0, "global GitHub API target requests/sec")
 		fBurst  = flag.Int("burst", 4, "token-bucket burst for GitHub API")
 		fTokens = flag.String("tokens", "", "comma-separated GitHub tokens (optional; can also come from env)")
+		fGHBase = flag.String("gh-base-url", "", "GitHub API root, GHES: https://HOST/api/v3 (default api.github.com)")
 		fBatch  = flag.Int("batch", 64, "DB lease batch size per poll")
 		fRetry  = flag.Int("retry_base_ms", 500, "base backoff (ms) for transient/RL")
 		fMaxAtt = flag.Int("max_attempts", 10, "max attempts before giving up")
@@ -63,20 +59,13 @@ func main() {
 		Log: *l,
 	}
 
-	// Export as env so module can also read via FromConfig (parity with HM).
-	mustSetEnv("BOUNCER_WORKER_CONCURRENCY", fmt.Sprintf("%d", *fConc))
-	mustSetEnv("BOUNCER_GH_RPS", fmt.Sprintf("%.3f", *fRPS))
-	mustSetEnv("BOUNCER_GH_BURST", fmt.Sprintf("%d", *fBurst))
-	mustSetEnv("BOUNCER_GH_TOKENS", *fTokens)
-	mustSetEnv("BOUNCER_QUEUE_TAKE_BATCH", fmt.Sprintf("%d", *fBatch))
-	mustSetEnv("BOUNCER_RETRY_BASE", fmt.Sprintf("%dms", *fRetry))
-	mustSetEnv("BOUNCER_MAX_ATTEMPTS", fmt.Sprintf("%d", *fMaxAtt))
-
+	// Flags override the module's BOUNCER_* config (parity with HM)
 	mod := bouncermod.New(deps, bouncermod.Options{
 		Concurrency:    *fConc,
 		RatePerSec:     *fRPS,
 		Burst:          *fBurst,
 		TokensCSV:      *fTokens,
+		GHBaseURL:      *fGHBase,
 		QueueTakeBatch: *fBatch,
 		RetryBaseMs:    *fRetry,
 		MaxAttempts:    *fMaxAtt,
@@ -85,7 +74,39 @@ func main() {
 
 	ports := module.MustPortsOf[bouncermod.Ports](mod)
 
-	if err := ports.Worker.Run(context.Background()); err != nil {
-		l.Fatal().Err(err).Msg("bouncer worker failed")
+	ctx := context.Background()
+
+	switch *fMode {
+	case "worker":
+		if err := ports.Worker.Run(ctx); err != nil {
+			l.Fatal().Err(err).Msg("bouncer worker failed")
+		}
+
+	case "drain":
+		// Work the backlog down once with queue depth before and after, for ops runs
+		before, err := ports.Worker.QueueDepth(ctx)
+		if err != nil {
+			l.Fatal().Err(err).Msg("bouncer queue depth failed")
+		}
+		l.Info().Int("ready", before.Ready).Int("waiting", before.Waiting).Int("leased", before.Leased).
+			Int("limit", *fLimit).Msg("bouncer drain starting")
+
+		res, err := ports.Worker.DrainOnce(ctx, *fLimit)
+		if err != nil {
+			l.Error().Err(err).Msg("bouncer drain stopped early")
+		}
+		after, derr := ports.Worker.QueueDepth(ctx)
+		if derr != nil {
+			l.Fatal().Err(derr).Msg("bouncer queue depth failed")
+		}
+		l.Info().Int("succeeded", res.Succeeded).Int("failed", res.Failed).Int("requeued", res.Requeued).
+			Int("ready", after.Ready).Int("waiting", after.Waiting).Int("leased", after.Leased).
+			Msg("bouncer drain done")
+		if err != nil || res.Failed > 0 {
+			os.Exit(1)
+		}
+
+	default:
+		l.Panic().Str("mode", *fMode).Msg("bouncer unknown -mode (expected: worker | drain)")
 	}
 }
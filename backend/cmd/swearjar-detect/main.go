@@ -5,7 +5,6 @@ import (
 	"flag"
 	"log"
 	"os"
-	"strconv"
 	"time"
 
 	"swearjar/internal/modkit"
@@ -21,27 +20,82 @@ import (
 	utmod "swearjar/internal/services/utterances/module"
 )
 
-func mustSetEnv(k, v string) {
-	if v != "" {
-		_ = os.Setenv(k, v)
+func main() {
+	var (
+		startStr = flag.String("start", "", "inclusive hour, e.g. 2025-08-01T00")
+		endStr   = flag.String("end", "", "exclusive hour, e.g. 2025-08-01T03")
+		ver      = flag.Int("ver", 1, "detector version to stamp")
+		workers  = flag.Int("workers", 2, "concurrency (>=1)")
+		page     = flag.Int("page", 5000, "page size (rows)")
+		dryRun   = flag.Bool("dry-run", false, "compute but do not write hits")
+		langScop = flag.Bool("lang-scoped", false, "only run rules for each utterance's lang_code (+ language-neutral)")
+		stdin    = flag.Bool("stdin", false, "offline: read NDJSON utterances from stdin, write NDJSON hits to stdout")
+		inPath   = flag.String("in", "", "offline: like -stdin but read this file (gzip ok)")
+		incr     = flag.Bool("incremental", false, "detect every hour backfilled since the last incremental run (needs PG)")
+	)
+	flag.Parse()
+
+	// Offline mode never opens a store, so it runs without any SERVICE_* config. It scans with
+	// the pipeline's CORE_DETECT_* options so its hits match what detect would store
+	if *stdin || *inPath != "" {
+		opts := detectmod.FromConfig(config.New()).DetectorOptions()
+		opts.LangScoped = opts.LangScoped || *langScop
+		path := *inPath
+		if path == "" {
+			path = "-"
+		}
+		r, err := openOffline(path)
+		if err != nil {
+			log.Fatalf("open input: %v", err)
+		}
+		defer func() { _ = r.Close() }()
+		lines, hits, err := runOffline(r, os.Stdout, *ver, opts)
+		if err != nil {
+			log.Fatalf("offline detect: %v", err)
+		}
+		log.Printf("offline detect: %d lines, %d hits", lines, hits)
+		return
 	}
-}
 
-func main() {
 	root := config.New()
 	chCfg := root.Prefix("SERVICE_CLICKHOUSE_")
 	l := logger.Get()
 
+	// Only incremental runs need PG (ingest_hours + the detect_state mark)
+	pgConf := store.PGConfig{Enabled: false}
+	if *incr {
+		pgCfg := root.Prefix("SERVICE_PGSQL_")
+		pgConf = store.PGConfig{
+			Enabled:     true,
+			URL:         pgCfg.MustString("DBURL"),
+			MaxConns:    int32(pgCfg.MayInt("MAX_CONNS", 2)),
+			SlowQueryMs: pgCfg.MayInt("SLOW_MS", 500),
+			LogSQL:      pgCfg.MayBool("LOG_SQL", false),
+		}
+	}
+
 	st, err := store.Open(context.Background(), store.Config{
-		PG: store.PGConfig{
-			Enabled: false,
-		},
+		PG: pgConf,
 		CH: store.CHConfig{
 			Enabled:    true,
 			URL:        chCfg.MustString("DBURL"),
 			LogSQL:     chCfg.MayBool("LOG_SQL", true),
 			ClientName: "swearjar",
 			ClientTag:  "detect",
+
+			// traced args are masked unless explicitly turned off (repo names/logins are PII)
+			LogRedact:          chCfg.MayEnum("LOG_REDACT", "strings", "off", "strings", "all"),
+			LogRedactKeepShort: chCfg.MayInt("LOG_REDACT_KEEP_SHORT", 0),
+			LogRedactAllow:     chCfg.MayCSV("LOG_REDACT_ALLOW", nil),
+
+			AsyncInsertTables: chCfg.MayCSV("ASYNC_INSERT_TABLES", nil),
+			AsyncInsertWait:   chCfg.MayBool("ASYNC_INSERT_WAIT", false),
+			InsertSettings:    chCfg.MayCSV("INSERT_SETTINGS", nil),
+
+			MaxConcurrentInserts: chCfg.MayInt("MAX_CONCURRENT_INSERTS", 0),
+
+			// clustered deployments map logical tables onto their Distributed tables
+			Tables: chCfg.MayCSV("TABLES", nil),
 		},
 	}, store.WithLogger(*l))
 	if err != nil {
@@ -53,55 +107,45 @@ func main() {
 		}
 	}()
 
-	var (
-		startStr = flag.String("start", "", "inclusive hour, e.g. 2025-08-01T00")
-		endStr   = flag.String("end", "", "exclusive hour, e.g. 2025-08-01T03")
-		ver      = flag.Int("ver", 1, "detector version to stamp")
-		workers  = flag.Int("workers", 2, "concurrency (>=1)")
-		page     = flag.Int("page", 5000, "page size (rows)")
-		dryRun   = flag.Bool("dry-run", false, "compute but do not write hits")
-	)
-	flag.Parse()
-
-	if *startStr == "" || *endStr == "" {
-		log.Fatal("start/end are required (hour resolution)")
-	}
-	start, err := time.Parse("2006-01-02T15", *startStr)
-	if err != nil {
-		log.Fatalf("bad -start: %v", err)
-	}
-	end, err := time.Parse("2006-01-02T15", *endStr)
-	if err != nil {
-		log.Fatalf("bad -end: %v", err)
-	}
-	if !start.Before(end) {
-		log.Fatal("start must be < end")
+	var start, end time.Time
+	if !*incr {
+		if *startStr == "" || *endStr == "" {
+			log.Fatal("start/end are required (hour resolution), or pass -incremental")
+		}
+		if start, err = time.Parse("2006-01-02T15", *startStr); err != nil {
+			log.Fatalf("bad -start: %v", err)
+		}
+		if end, err = time.Parse("2006-01-02T15", *endStr); err != nil {
+			log.Fatalf("bad -end: %v", err)
+		}
+		if !start.Before(end) {
+			log.Fatal("start must be < end")
+		}
 	}
 
-	// Pass CLI flags into CORE_DETECT_* so the module can read its own config
-	mustSetEnv("CORE_DETECT_VERSION", strconv.Itoa(*ver))
-	mustSetEnv("CORE_DETECT_WORKERS", strconv.Itoa(*workers))
-	mustSetEnv("CORE_DETECT_PAGE_SIZE", strconv.Itoa(*page))
-	mustSetEnv("CORE_DETECT_DRY_RUN", map[bool]string{true: "1", false: "0"}[*dryRun])
-
 	deps := modkit.Deps{
 		Cfg: root,
 		CH:  st.CH,
 		Log: *l,
 	}
+	if *incr {
+		deps.PG = st.PG
+	}
 
 	// Build dependency modules first
 	ut := utmod.New(deps)
 	hm := hitsmod.New(deps)
 
 	// Build detect module with ports injected from deps modules
+	// CLI flags are passed as overrides; the module binds CORE_DETECT_* itself
 	dm := detectmod.New(
 		deps,
 		detectmod.Options{
-			Version:  *ver,
-			Workers:  *workers,
-			PageSize: *page,
-			DryRun:   *dryRun,
+			Version:    *ver,
+			Workers:    *workers,
+			PageSize:   *page,
+			DryRun:     *dryRun,
+			LangScoped: *langScop,
 		},
 		modkit.WithPorts(detectdom.Ports{
 			Utterances: module.MustPortsOf[utmod.Ports](ut).Reader,
@@ -116,6 +160,12 @@ func main() {
 
 	// Kick the runner
 	ports := dm.Ports().(detectmod.Ports)
+	if *incr {
+		if err := ports.Runner.RunIncremental(context.Background()); err != nil {
+			l.Fatal().Err(err).Msg("incremental detect failed")
+		}
+		return
+	}
 	if err := ports.Runner.RunRange(context.Background(), start.UTC(), end.UTC()); err != nil {
 		l.Fatal().Err(err).Msg("detect failed")
 	}
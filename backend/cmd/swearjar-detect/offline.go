@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"swearjar/internal/core/detector"
+	"swearjar/internal/core/normalize"
+	"swearjar/internal/core/rulepack"
+)
+
+// offlineLine is one NDJSON input utterance; text_norm wins over text when both are set
+type offlineLine struct {
+	ID       string `json:"id"`
+	Text     string `json:"text"`
+	TextNorm string `json:"text_norm"`
+	Lang     string `json:"lang"`
+}
+
+// offlineHit is one NDJSON output line: a detector hit on an input utterance
+type offlineHit struct {
+	ID         string   `json:"id,omitempty"`
+	Line       int      `json:"line"`
+	Term       string   `json:"term"`
+	Category   string   `json:"category"`
+	Severity   string   `json:"severity"`
+	Source     string   `json:"source"`
+	Spans      [][2]int `json:"spans"`
+	Zones      []string `json:"zones,omitempty"`
+	TargetType string   `json:"target_type,omitempty"`
+	TargetID   string   `json:"target_id,omitempty"`
+	CtxAction  string   `json:"ctx_action,omitempty"`
+	DetVer     int      `json:"detver"`
+}
+
+// offlineMaxLine bounds one NDJSON input line (GitHub bodies are far smaller)
+const offlineMaxLine = 16 << 20
+
+// openOffline opens path ("-" = stdin) and transparently gunzips gzip input
+func openOffline(path string) (io.ReadCloser, error) {
+	var f io.ReadCloser = os.Stdin
+	if path != "-" {
+		var err error
+		if f, err = os.Open(path); err != nil {
+			return nil, err
+		}
+	}
+	br := bufio.NewReader(f)
+	if magic, err := br.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			_ = f.Close()
+			return nil, fmt.Errorf("gzip: %w", err)
+		}
+		return struct {
+			io.Reader
+			io.Closer
+		}{zr, f}, nil
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{br, f}, nil
+}
+
+// runOffline scans NDJSON utterances from r with opts and writes one NDJSON hit per line to w.
+// No database is touched; the rulepack is the embedded one. Hits written before a bad line are
+// flushed before its error is returned. It returns lines read and hits written
+func runOffline(r io.Reader, w io.Writer, ver int, opts detector.Options) (lines, hits int, err error) {
+	rp, err := rulepack.Load()
+	if err != nil {
+		return 0, 0, err
+	}
+	det := detector.NewWithOptions(rp, ver, opts)
+	norm := normalize.New()
+
+	bw := bufio.NewWriter(w)
+	defer func() {
+		if ferr := bw.Flush(); err == nil {
+			err = ferr
+		}
+	}()
+	enc := json.NewEncoder(bw)
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64<<10), offlineMaxLine)
+	for sc.Scan() {
+		lines++
+		raw := sc.Bytes()
+		if len(raw) == 0 {
+			continue
+		}
+		var in offlineLine
+		if err := json.Unmarshal(raw, &in); err != nil {
+			return lines, hits, fmt.Errorf("line %d: %w", lines, err)
+		}
+		text := in.TextNorm
+		if text == "" {
+			text = norm.Normalize(in.Text)
+		}
+		for _, h := range det.ScanLang(text, in.Lang) {
+			if err := enc.Encode(offlineHit{
+				ID:         in.ID,
+				Line:       lines,
+				Term:       h.Term,
+				Category:   h.Category,
+				Severity:   rp.SeverityLabel(h.Severity),
+				Source:     string(h.Source),
+				Spans:      h.Spans,
+				Zones:      h.Zones,
+				TargetType: h.TargetType,
+				TargetID:   h.TargetID,
+				CtxAction:  h.CtxAction,
+				DetVer:     ver,
+			}); err != nil {
+				return lines, hits, err
+			}
+			hits++
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return lines, hits, fmt.Errorf("line %d: %w", lines+1, err)
+	}
+	return lines, hits, nil
+}
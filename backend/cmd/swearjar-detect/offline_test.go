@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"swearjar/internal/core/detector"
+	"swearjar/internal/platform/config"
+	detectmod "swearjar/internal/services/detect/module"
+)
+
+func TestRunOffline(t *testing.T) {
+	in := strings.Join([]string{
+		`{"id":"a","text":"this build is shit"}`,
+		``,
+		`{"id":"b","text":"all good here"}`,
+		`{"id":"c","text_norm":"well shit","text":"ignored"}`,
+	}, "\n")
+	var out bytes.Buffer
+	lines, hits, err := runOffline(strings.NewReader(in), &out, 7, detectmod.FromConfig(config.New()).DetectorOptions())
+	if err != nil {
+		t.Fatalf("runOffline: %v", err)
+	}
+	if lines != 4 {
+		t.Fatalf("lines = %d, want 4 (blank lines count)", lines)
+	}
+
+	var got []offlineHit
+	dec := json.NewDecoder(&out)
+	for dec.More() {
+		var h offlineHit
+		if err := dec.Decode(&h); err != nil {
+			t.Fatalf("decode output: %v", err)
+		}
+		got = append(got, h)
+	}
+	if len(got) != hits || hits == 0 {
+		t.Fatalf("hits = %d, decoded %d lines, want the same non-zero count", hits, len(got))
+	}
+	ids := map[string]int{}
+	for _, h := range got {
+		ids[h.ID]++
+		if h.DetVer != 7 || len(h.Spans) == 0 || h.Severity == "" {
+			t.Fatalf("hit %+v: want detver 7, spans and a severity label", h)
+		}
+	}
+	if ids["a"] == 0 || ids["c"] == 0 || ids["b"] != 0 {
+		t.Fatalf("hits per id = %v, want a and c only", ids)
+	}
+	if got[len(got)-1].Line != 4 {
+		t.Fatalf("last hit line = %d, want 4", got[len(got)-1].Line)
+	}
+}
+
+func TestRunOfflineFlushesBeforeBadLine(t *testing.T) {
+	in := `{"id":"a","text":"this build is shit"}` + "\n" + `{not json`
+	var out bytes.Buffer
+	_, hits, err := runOffline(strings.NewReader(in), &out, 1, detector.Options{})
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Fatalf("err = %v, want a line 2 error", err)
+	}
+	if hits == 0 || strings.Count(out.String(), "\n") != hits {
+		t.Fatalf("output %q: want the %d hits before the bad line flushed", out.String(), hits)
+	}
+}
+
+func TestRunOfflineUsesOptions(t *testing.T) {
+	in := `{"text":"> this build is shit"}`
+	var out bytes.Buffer
+	if _, hits, err := runOffline(strings.NewReader(in), &out, 1, detector.Options{SkipQuoteZones: true}); err != nil ||
+		hits != 0 {
+		t.Fatalf("SkipQuoteZones: got %d hits (%v), want the quoted line skipped", hits, err)
+	}
+}
+
+type failWriter struct{}
+
+func (failWriter) Write([]byte) (int, error) { return 0, errors.New("disk full") }
+
+func TestRunOfflineReportsWriteError(t *testing.T) {
+	in := `{"text":"this build is shit"}`
+	if _, _, err := runOffline(strings.NewReader(in), failWriter{}, 1, detector.Options{}); err == nil {
+		t.Fatal("want the flush error surfaced")
+	}
+}
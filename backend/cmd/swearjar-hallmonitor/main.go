@@ -4,7 +4,6 @@ import (
 	"context"
 	"flag"
 	"fmt"
-	"os"
 	"time"
 
 	"swearjar/internal/modkit"
@@ -17,12 +16,6 @@ import (
 	hallmod "swearjar/internal/services/hallmonitor/module"
 )
 
-func mustSetEnv(key, val string) {
-	if val != "" {
-		_ = os.Setenv(key, val)
-	}
-}
-
 func parseWhen(label, v string) time.Time {
 	// Accept either date or date+hour, like the backfill tool
 	// - "YYYY-MM-DD" (midnight UTC)
@@ -81,6 +74,7 @@ func main() {
 		fRPS    = flag.Float64("rps", 2.0, "global GitHub API target requests/sec")
 		fBurst  = flag.Int("burst", 4, "token-bucket burst for GitHub API")
 		fTokens = flag.String("tokens", "", "comma-separated GitHub tokens (optional; can also come from env)")
+		fGHBase = flag.String("gh-base-url", "", "GitHub API root, GHES: https://HOST/api/v3 (default api.github.com)")
 		fDryRun = flag.Bool("dryrun", false, "in backfill/refresh modes, plan but do not write (for smoke tests)")
 	)
 	flag.Parse()
@@ -92,13 +86,7 @@ func main() {
 		Log: *l,
 	}
 
-	// Export a few knobs as env so the module can read via FromConfig if desired
-	mustSetEnv("HALLMONITOR_WORKER_CONCURRENCY", fmt.Sprintf("%d", *fConc))
-	mustSetEnv("HALLMONITOR_GH_RPS", fmt.Sprintf("%.3f", *fRPS))
-	mustSetEnv("HALLMONITOR_GH_BURST", fmt.Sprintf("%d", *fBurst))
-	mustSetEnv("HALLMONITOR_GH_TOKENS", *fTokens)
-	mustSetEnv("HALLMONITOR_DRYRUN", map[bool]string{true: "1", false: "0"}[*fDryRun])
-
+	// Flags override the module's HALLMONITOR_* config
 	hm := hallmod.New(
 		deps,
 		hallmod.Options{
@@ -106,6 +94,7 @@ func main() {
 			RatePerSec:  *fRPS,
 			Burst:       *fBurst,
 			TokensCSV:   *fTokens,
+			GHBaseURL:   *fGHBase,
 			DryRun:      *fDryRun,
 		},
 	)
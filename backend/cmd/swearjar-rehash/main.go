@@ -0,0 +1,162 @@
+// Command swearjar-rehash writes the old_hid -> new_hid mapping for a new hidscheme.Scheme.
+// It reads every known GitHub ID from the ident maps, derives the HID under -to, and records
+// rows whose HID changes in ident.hid_rehash_map. Migrating utterances, hits and principals
+// then joins through that table; this tool never rewrites them itself.
+// Synthetic (negative) IDs were hashed from names, not IDs, so they are reported and skipped.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"swearjar/internal/core/hidscheme"
+	"swearjar/internal/platform/config"
+	"swearjar/internal/platform/logger"
+	"swearjar/internal/platform/store"
+)
+
+// principalSpec describes one ident map to rehash
+type principalSpec struct {
+	kind   string // principal_enum value
+	table  string
+	hidCol string
+	idCol  string
+	derive func(hidscheme.Scheme, int64) [32]byte
+}
+
+var principals = []principalSpec{
+	{"repo", "ident.gh_repo_map", "repo_hid", "gh_repo_id", hidscheme.Scheme.Repo},
+	{"actor", "ident.gh_actor_map", "actor_hid", "gh_user_id", hidscheme.Scheme.Actor},
+}
+
+// stats counts what happened to one principal kind
+type stats struct {
+	seen, changed, unchanged, synthetic, written int64
+}
+
+func main() {
+	root := config.New()
+	dbCfg := root.Prefix("SERVICE_PGSQL_")
+
+	var (
+		fTo     = flag.Int("to", int(hidscheme.Current), "target hidscheme version")
+		fBatch  = flag.Int("batch", 5000, "rows per mapping insert")
+		fDryRun = flag.Bool("dryrun", false, "count changes but do not write the mapping")
+	)
+	flag.Parse()
+
+	l := logger.Get()
+	to := hidscheme.Scheme(*fTo)
+	if !to.Valid() {
+		l.Fatal().Int("to", *fTo).Msg("unknown hidscheme version")
+	}
+	if *fBatch <= 0 {
+		*fBatch = 5000
+	}
+
+	ctx := context.Background()
+	st, err := store.Open(ctx, store.Config{
+		PG: store.PGConfig{
+			Enabled:     true,
+			URL:         dbCfg.MustString("DBURL"),
+			MaxConns:    int32(dbCfg.MayInt("MAX_CONNS", 4)),
+			SlowQueryMs: dbCfg.MayInt("SLOW_MS", 500),
+			LogSQL:      dbCfg.MayBool("LOG_SQL", false),
+		},
+	}, store.WithLogger(*l))
+	if err != nil {
+		l.Panic().Err(err).Msg("store.Open failed")
+	}
+	defer func() {
+		if err := st.Close(context.Background()); err != nil {
+			l.Error().Err(err).Msg("failed to close store")
+		}
+	}()
+
+	for _, p := range principals {
+		s, err := rehash(ctx, st.PG, p, to, *fBatch, *fDryRun)
+		if err != nil {
+			l.Fatal().Err(err).Str("principal", p.kind).Msg("rehash failed")
+		}
+		l.Info().
+			Str("principal", p.kind).
+			Int("to", int(to)).
+			Bool("dryrun", *fDryRun).
+			Int64("seen", s.seen).
+			Int64("changed", s.changed).
+			Int64("unchanged", s.unchanged).
+			Int64("synthetic_skipped", s.synthetic).
+			Int64("written", s.written).
+			Msg("rehash done")
+	}
+}
+
+// rehash streams one ident map and writes changed HIDs in batches
+func rehash(
+	ctx context.Context,
+	db store.TxRunner,
+	p principalSpec,
+	to hidscheme.Scheme,
+	batch int,
+	dryRun bool,
+) (stats, error) {
+	var s stats
+	rows, err := db.Query(ctx, fmt.Sprintf(`SELECT %s, %s FROM %s`, p.hidCol, p.idCol, p.table))
+	if err != nil {
+		return s, err
+	}
+	defer rows.Close()
+
+	var olds, news [][]byte
+	flush := func() error {
+		if len(olds) == 0 {
+			return nil
+		}
+		if !dryRun {
+			tag, err := db.Exec(ctx, `
+				INSERT INTO ident.hid_rehash_map (principal, to_scheme, old_hid, new_hid)
+				SELECT $1::principal_enum, $2, o, n
+				FROM unnest($3::bytea[], $4::bytea[]) AS t(o, n)
+				ON CONFLICT (principal, to_scheme, old_hid) DO NOTHING`,
+				p.kind, int(to), olds, news)
+			if err != nil {
+				return err
+			}
+			s.written += tag.RowsAffected()
+		}
+		olds, news = olds[:0], news[:0]
+		return nil
+	}
+
+	for rows.Next() {
+		var (
+			old []byte
+			id  int64
+		)
+		if err := rows.Scan(&old, &id); err != nil {
+			return s, err
+		}
+		s.seen++
+		if id < 0 {
+			s.synthetic++
+			continue
+		}
+		nh := p.derive(to, id)
+		if string(old) == string(nh[:]) {
+			s.unchanged++
+			continue
+		}
+		s.changed++
+		olds, news = append(olds, old), append(news, nh[:])
+		if len(olds) >= batch {
+			if err := flush(); err != nil {
+				return s, err
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return s, err
+	}
+	return s, flush()
+}
@@ -10,9 +10,12 @@ import (
 	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
+
+	"swearjar/internal/core/rulepack"
 )
 
 // todo: centralize with rulepack/pack.go?
@@ -49,6 +52,7 @@ type fragmentFile struct {
 }
 
 type lemma struct {
+	Lang           string         `json:"lang,omitempty"` // from the fragment; omitted = language-neutral
 	Term           string         `json:"term"`
 	Category       string         `json:"category"`
 	Severity       int            `json:"severity"`
@@ -57,13 +61,15 @@ type lemma struct {
 }
 
 type template struct {
-	ID             string         `json:"id"`
-	Pattern        string         `json:"pattern"`
-	Category       string         `json:"category"`
-	Severity       int            `json:"severity"`
-	Variants       []string       `json:"variants,omitempty"`
-	ContextSignals map[string]any `json:"context_signals,omitempty"`
-	Examples       []string       `json:"examples,omitempty"`
+	Lang            string         `json:"lang,omitempty"` // from the fragment; omitted = language-neutral
+	ID              string         `json:"id"`
+	Pattern         string         `json:"pattern"`
+	Category        string         `json:"category"`
+	Severity        int            `json:"severity"`
+	Variants        []string       `json:"variants,omitempty"`
+	ContextSignals  map[string]any `json:"context_signals,omitempty"`
+	Examples        []string       `json:"examples,omitempty"`
+	CounterExamples []string       `json:"counter_examples,omitempty"`
 }
 
 type outV2 struct {
@@ -78,6 +84,11 @@ type outV2 struct {
 	Allowlist    allowlistBlock       `json:"allowlist,omitempty"`
 	EngineHints  map[string]any       `json:"engine_hints,omitempty"`
 	SeverityMods []map[string]any     `json:"severity_mods,omitempty"`
+
+	// Allowlist entries by origin, kept for -split-by-lang: core.json plus neutral fragments,
+	// and each language's fragments
+	coreAllow allowlistBlock
+	langAllow map[string]*allowlistBlock
 }
 
 func readJSON[T any](path string, into *T) error {
@@ -123,6 +134,18 @@ func mergeEngineHints(dst *map[string]any, src map[string]any) {
 	maps.Copy((*dst), src)
 }
 
+// neutralLang is the fragment language for rules that apply regardless of utterance language
+const neutralLang = "mul"
+
+// ruleLang maps a fragment language onto the per-rule lang tag ("" = language-neutral)
+func ruleLang(fragment string) string {
+	l := strings.ToLower(strings.TrimSpace(fragment))
+	if l == neutralLang {
+		return ""
+	}
+	return l
+}
+
 func findFragmentFiles(root string) ([]string, error) {
 	var files []string
 	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
@@ -256,6 +279,8 @@ func assemble(root string) (outV2, error) {
 	var lemRecs []lrec
 	var allTemplates []template
 	mergedAllow := core.Allowlist
+	coreAllow := allowlistBlock{Global: slices.Clone(core.Allowlist.Global), ByZone: maps.Clone(core.Allowlist.ByZone)}
+	langAllow := map[string]*allowlistBlock{}
 	var mergedHints map[string]any
 	mergeEngineHints(&mergedHints, core.EngineHints)
 
@@ -267,11 +292,24 @@ func assemble(root string) (outV2, error) {
 		if fr.Language == "" {
 			return outV2{}, fmt.Errorf("fragment missing language: %s", p)
 		}
+		lang := ruleLang(fr.Language)
 		for _, l := range fr.Lemmas {
+			l.Lang = lang
 			lemRecs = append(lemRecs, lrec{Lang: fr.Language, Val: l})
 		}
-		allTemplates = append(allTemplates, fr.Templates...)
+		for _, t := range fr.Templates {
+			t.Lang = lang
+			allTemplates = append(allTemplates, t)
+		}
 		mergeAllowlist(&mergedAllow, fr.Allowlist)
+		if lang == "" {
+			mergeAllowlist(&coreAllow, fr.Allowlist)
+		} else if fr.Allowlist != nil {
+			if langAllow[lang] == nil {
+				langAllow[lang] = &allowlistBlock{}
+			}
+			mergeAllowlist(langAllow[lang], fr.Allowlist)
+		}
 		mergeEngineHints(&mergedHints, fr.EngineHints)
 	}
 
@@ -297,7 +335,10 @@ func assemble(root string) (outV2, error) {
 		if allLemmas[i].Category != allLemmas[j].Category {
 			return allLemmas[i].Category < allLemmas[j].Category
 		}
-		return strings.ToLower(allLemmas[i].Term) < strings.ToLower(allLemmas[j].Term)
+		if ti, tj := strings.ToLower(allLemmas[i].Term), strings.ToLower(allLemmas[j].Term); ti != tj {
+			return ti < tj
+		}
+		return allLemmas[i].Lang < allLemmas[j].Lang
 	})
 
 	// de-dupe templates by ID (if present) then by (pattern,category,severity)
@@ -351,17 +392,117 @@ func assemble(root string) (outV2, error) {
 		Allowlist:    mergedAllow,
 		EngineHints:  mergedHints,
 		SeverityMods: core.SeverityMods,
+		coreAllow:    coreAllow,
+		langAllow:    langAllow,
 	}, nil
 }
 
+// splitCore is the -split-by-lang key for shared config plus language-neutral rules
+const splitCore = "core"
+
+// splitByLang cuts an assembled pack into a core pack (all shared config, neutral rules and
+// the core allowlist) and one pack per language holding only that language's rules and
+// allowlist; rulepack.LoadSplit puts them back together
+func splitByLang(obj outV2) map[string]outV2 {
+	core := obj
+	core.Lemmas, core.Templates = []lemma{}, []template{}
+	core.Allowlist = obj.coreAllow
+	out := map[string]outV2{}
+	pack := func(lang string) outV2 {
+		if p, ok := out[lang]; ok {
+			return p
+		}
+		meta := maps.Clone(obj.Meta)
+		if meta == nil {
+			meta = map[string]any{}
+		}
+		meta["language"] = lang
+		p := outV2{Version: obj.Version, Meta: meta, Lemmas: []lemma{}, Templates: []template{}}
+		if a := obj.langAllow[lang]; a != nil {
+			p.Allowlist = *a
+		}
+		return p
+	}
+	for _, l := range obj.Lemmas {
+		if l.Lang == "" {
+			core.Lemmas = append(core.Lemmas, l)
+			continue
+		}
+		p := pack(l.Lang)
+		p.Lemmas = append(p.Lemmas, l)
+		out[l.Lang] = p
+	}
+	for _, t := range obj.Templates {
+		if t.Lang == "" {
+			core.Templates = append(core.Templates, t)
+			continue
+		}
+		p := pack(t.Lang)
+		p.Templates = append(p.Templates, t)
+		out[t.Lang] = p
+	}
+	for lang := range obj.langAllow {
+		out[lang] = pack(lang) // allowlist-only language still gets a file
+	}
+	out[splitCore] = core
+	return out
+}
+
+// splitPath names the file for one split pack: rules.json -> rules.<key>.json
+func splitPath(out, key string) string {
+	ext := filepath.Ext(out)
+	return strings.TrimSuffix(out, ext) + "." + key + ext
+}
+
+// validate compiles the assembled pack the way the detector loads it and runs the template
+// self-test, printing every template whose examples or counter_examples disagree with its regex
+func validate(obj outV2, verbose bool) error {
+	enc, err := encode(obj, false)
+	if err != nil {
+		return err
+	}
+	p, err := rulepack.LoadSplit(enc)
+	if err != nil {
+		return err
+	}
+	fails := p.Verify()
+	for _, f := range fails {
+		for _, ex := range f.Missed {
+			_, _ = fmt.Fprintf(os.Stderr, "template %s: example not matched: %q\n", f.ID, ex)
+		}
+		for _, ex := range f.Matched {
+			_, _ = fmt.Fprintf(os.Stderr, "template %s: counter_example matched: %q\n", f.ID, ex)
+		}
+	}
+	if len(fails) > 0 {
+		return fmt.Errorf("%d of %d templates failed their examples", len(fails), len(p.Templates))
+	}
+	if verbose {
+		_, _ = fmt.Fprintf(os.Stderr, "validated %d templates, %d lemmas\n", len(p.Templates), len(p.Lemmas))
+	}
+	return nil
+}
+
+func encode(obj outV2, pretty bool) ([]byte, error) {
+	if pretty {
+		return json.MarshalIndent(obj, "", "  ")
+	}
+	return json.Marshal(obj)
+}
+
 func main() {
 	var (
 		flagRoot = flag.String("root", "", "path to rules version directory (e.g., ./rules/1 or ./rules). If empty, auto-discover") //nolint:lll
 		out      = flag.String("out", "./internal/core/rulepack/rules.json", "output path or '-' for stdout")
 		pretty   = flag.Bool("pretty", true, "pretty-print JSON")
 		verbose  = flag.Bool("v", false, "verbose logging")
+		split    = flag.Bool("split-by-lang", false, "write <out>.core.json plus one <out>.<lang>.json per language")
+		check    = flag.Bool("validate", false, "compile and check templates against their examples; writes nothing")
 	)
 	flag.Parse()
+	if *split && *out == "-" {
+		must(errors.New("-split-by-lang needs a file -out, not stdout"))
+	}
 
 	root, attempts, err := resolveRoot(strings.TrimSpace(*flagRoot))
 	if err != nil {
@@ -379,12 +520,28 @@ func main() {
 	obj, err := assemble(root)
 	must(err)
 
-	var enc []byte
-	if *pretty {
-		enc, err = json.MarshalIndent(obj, "", "  ")
-	} else {
-		enc, err = json.Marshal(obj)
+	if *check {
+		must(validate(obj, *verbose))
+		return
+	}
+
+	if *split {
+		must(os.MkdirAll(filepath.Dir(*out), 0o755))
+		parts := splitByLang(obj)
+		for _, key := range slices.Sorted(maps.Keys(parts)) {
+			enc, err := encode(parts[key], *pretty)
+			must(err)
+			p := splitPath(*out, key)
+			must(os.WriteFile(p, enc, 0o644))
+			if *verbose {
+				_, _ = fmt.Fprintf(os.Stderr, "wrote %s (%d lemmas, %d templates, %d bytes)\n",
+					p, len(parts[key].Lemmas), len(parts[key].Templates), len(enc))
+			}
+		}
+		return
 	}
+
+	enc, err := encode(obj, *pretty)
 	must(err)
 
 	if *out == "-" {
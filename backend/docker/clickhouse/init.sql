@@ -47,7 +47,13 @@ CREATE TABLE utterances
 
   -- ingest metadata / upsert aid (use a stable number per batch; replays can reuse it)
   ingest_batch_id    UInt64 DEFAULT 0,
-  ver                UInt64 DEFAULT ingest_batch_id
+  ver                UInt64 DEFAULT ingest_batch_id,
+
+  -- identical texts in the hour this row stands for (backfill duplicate collapsing); 1 = unique
+  dup_count          UInt32 DEFAULT 1,
+
+  -- 1 when text_raw was cut to the backfill's MAX_TEXT_BYTES (the head is kept)
+  text_truncated     UInt8 DEFAULT 0
 )
 ENGINE = ReplacingMergeTree(ver)
   PARTITION BY toYYYYMM(created_at)
@@ -72,6 +78,7 @@ CREATE TABLE hits
   repo_hid           FixedString(32),
   actor_hid          FixedString(32),
   lang_code          Nullable(String),
+  lang_reliable      Nullable(UInt8),  -- set when detect inferred lang_code; NULL = taken from the utterance
 
   term               String, -- normalized
 
@@ -87,7 +94,7 @@ CREATE TABLE hits
 
   -- context/category gating diagnostics + targeting (persisted from detector)
   ctx_action         Enum8('none' = 0, 'upgraded' = 1, 'downgraded' = 2) DEFAULT 'none',
-  target_type        Enum8('none' = 0, 'bot' = 1, 'tool' = 2, 'lang' = 3, 'framework' = 4) DEFAULT 'none',
+  target_type        Enum8('none' = 0, 'bot' = 1, 'tool' = 2, 'lang' = 3, 'framework' = 4, 'self' = 5) DEFAULT 'none',
   target_id          LowCardinality(String) DEFAULT '',    -- stable alias id from rulepack (e.g., "dependabot", "eslint", "javascript", "react")
   target_name        Nullable(String),                    -- exact surface mention matched (e.g., "@dependabot")
   target_span_start  Nullable(Int32),
@@ -123,6 +130,25 @@ CREATE INDEX IF NOT EXISTS hit_term_tokenbf ON hits (term)
 CREATE INDEX IF NOT EXISTS hit_target_tokenbf ON hits (target_id)
   TYPE tokenbf_v1(1024, 2, 0) GRANULARITY 64;
 
+-- RAW EVENTS (sampled original event lines; debugging/replay only)
+-- Written only when CORE_BACKFILL_RAW_SAMPLE > 0; capped per hour and expired by TTL
+CREATE TABLE raw_events
+(
+  id          UUID,                    -- deterministic event UUID (derived from the raw line)
+  hour_utc    DateTime('UTC'),         -- ingest hour the line came from
+  event_type  String,
+  created_at  DateTime64(3, 'UTC'),
+  repo_hid    FixedString(32),
+  actor_hid   FixedString(32),
+  payload     String CODEC(ZSTD(12)),  -- full original JSON line
+  sampled_at  DateTime DEFAULT now()
+)
+ENGINE = ReplacingMergeTree
+  PARTITION BY toYYYYMM(hour_utc)
+  ORDER BY (hour_utc, id)
+  TTL sampled_at + INTERVAL 30 DAY
+  SETTINGS index_granularity = 8192;
+
 -- ==========================================
 -- Nightshift
 -- Append-only analytic archives (no time to live)
@@ -130,7 +156,7 @@ CREATE INDEX IF NOT EXISTS hit_target_tokenbf ON hits (target_id)
 
 CREATE TABLE term_dict
 (
-  term_id    UInt64, -- cityHash64(lower(term)) or curated ID
+  term_id    UInt64, -- termid.Hash: cityHash64(lower(term)) or curated ID
   term       String,
   updated_at DateTime DEFAULT now()
 )
@@ -177,7 +203,7 @@ CREATE TABLE commit_crimes
 
   -- structured targeting (kept in sync with hits)
   ctx_action         Enum8('none' = 0, 'upgraded' = 1, 'downgraded' = 2) DEFAULT 'none',
-  target_type        Enum8('none' = 0, 'bot' = 1, 'tool' = 2, 'lang' = 3, 'framework' = 4) DEFAULT 'none',
+  target_type        Enum8('none' = 0, 'bot' = 1, 'tool' = 2, 'lang' = 3, 'framework' = 4, 'self' = 5) DEFAULT 'none',
   target_id          LowCardinality(String) DEFAULT '',
   target_name        Nullable(String),
   target_span_start  Nullable(Int32),
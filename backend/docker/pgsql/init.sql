@@ -21,7 +21,7 @@ CREATE TYPE principal_enum      AS ENUM ('repo','actor');
 CREATE TYPE consent_action_enum AS ENUM ('opt_in','opt_out');
 CREATE TYPE consent_state_enum  AS ENUM ('pending','active','revoked','expired');
 CREATE TYPE consent_scope_enum  AS ENUM ('demask_repo','demask_self');
-CREATE TYPE evidence_kind_enum  AS ENUM ('repo_file','actor_gist');
+CREATE TYPE evidence_kind_enum  AS ENUM ('repo_file','actor_gist','org_manifest');
 CREATE TYPE backfill_status     AS ENUM ('pending','running','ok','error');
 CREATE TYPE nightshift_status   AS ENUM ('pending','running','retention_applied','done','error');
 
@@ -64,6 +64,7 @@ CREATE TABLE consent_challenges (
   evidence_kind      evidence_kind_enum NOT NULL,
   artifact_hint      text NOT NULL,
   issued_at          timestamptz NOT NULL DEFAULT now(),
+  issued_day         date NOT NULL DEFAULT (now() AT TIME ZONE 'utc')::date,
   expires_at         timestamptz,
   used_at            timestamptz,
   state              consent_state_enum NOT NULL DEFAULT 'pending'
@@ -71,6 +72,8 @@ CREATE TABLE consent_challenges (
 CREATE INDEX ix_consent_challenges_state   ON consent_challenges(state);
 CREATE INDEX ix_consent_challenges_expires ON consent_challenges(expires_at);
 CREATE INDEX ix_challenges_tuple           ON consent_challenges (principal, resource, action, state);
+-- one challenge per subject per UTC day; Issue upserts on this so repeat calls are idempotent
+CREATE UNIQUE INDEX ux_challenges_subject_day ON consent_challenges (principal, resource, issued_day);
 
 CREATE TABLE consent_receipts (
   consent_id           uuid PRIMARY KEY DEFAULT uuidv7(),
@@ -100,6 +103,38 @@ CREATE INDEX ix_allow_actor_hid_active ON consent_receipts (principal_hid)
   WHERE principal='actor' AND action='opt_in'  AND state='active';
 CREATE INDEX ix_consent_receipts_scope_gin ON consent_receipts USING gin (scope);
 
+-- Append-only history of receipt transitions, written in the same statement as the receipt change.
+-- transition: granted (new receipt) | reinstated (was revoked) | reverified (still active) |
+-- revocation_pending (artifact gone). verified_by: api (inline reverify) | worker (bouncer queue)
+-- No FK to consent_receipts so the trail outlives receipt deletes
+CREATE TABLE consent_audit (
+  audit_id             uuid PRIMARY KEY DEFAULT uuidv7(),
+  consent_id           uuid NOT NULL,
+  principal            principal_enum NOT NULL,
+  principal_hid        hid_bytes NOT NULL,
+  transition           text NOT NULL,
+  action               consent_action_enum NOT NULL,
+  state                consent_state_enum NOT NULL,
+  evidence_kind        evidence_kind_enum NOT NULL,
+  evidence_url         text NOT NULL,
+  evidence_fingerprint text,
+  verified_by          text NOT NULL,
+  at                   timestamptz NOT NULL DEFAULT now()
+);
+CREATE INDEX ix_consent_audit_subject ON consent_audit (principal, principal_hid, at);
+
+CREATE OR REPLACE FUNCTION consent_audit_append_only()
+RETURNS trigger
+LANGUAGE plpgsql
+AS $$
+BEGIN
+  RAISE EXCEPTION 'consent_audit is append-only';
+END;
+$$;
+CREATE TRIGGER consent_audit_no_rewrite
+  BEFORE UPDATE OR DELETE ON consent_audit
+  FOR EACH ROW EXECUTE FUNCTION consent_audit_append_only();
+
 -- High-signal verification queue (user-triggered; used when API limits are tight)
 CREATE TABLE consent_verifications (
   job_id           uuid PRIMARY KEY DEFAULT uuidv7(),
@@ -131,6 +166,18 @@ CREATE INDEX consent_verifications_subject_idx ON consent_verifications (princip
 CREATE TABLE principals_repos  (repo_hid  hid_bytes PRIMARY KEY);
 CREATE TABLE principals_actors (actor_hid hid_bytes PRIMARY KEY);
 
+-- =========
+-- ANALYTICS EXCLUSIONS (legal removals; unlike a consent opt-out, which masks identity,
+-- these principals are dropped from every API aggregate and sample)
+-- =========
+CREATE TABLE analytics_exclusions (
+  principal      principal_enum NOT NULL,
+  principal_hid  hid_bytes NOT NULL,
+  reason         text NOT NULL,
+  created_at     timestamptz NOT NULL DEFAULT now(),
+  PRIMARY KEY (principal, principal_hid)
+);
+
 -- =========
 -- ENRICHMENT CATALOGS (HID-keyed; PII filled only when opted-in)
 -- =========
@@ -191,6 +238,17 @@ CREATE UNIQUE INDEX actors_login_idx  ON actors (lower(login)) WHERE login IS NO
 CREATE INDEX actors_next_refresh_idx  ON actors (next_refresh_at);
 CREATE INDEX actors_gone_idx ON actors (gone_at) WHERE gone_at IS NOT NULL;
 
+-- Rename history for opted-in repos (hallmonitor, HALLMONITOR_RECORD_RENAMES); names are PII,
+-- so rows are tied to the active opt-in receipt and purged on opt-out (trg_purge_on_optout)
+CREATE TABLE repo_renames (
+  repo_hid      hid_bytes   NOT NULL REFERENCES principals_repos(repo_hid) ON DELETE CASCADE,
+  consent_id    uuid        NOT NULL REFERENCES consent_receipts(consent_id) ON DELETE CASCADE,
+  old_full_name text        NOT NULL,
+  new_full_name text        NOT NULL,
+  observed_at   timestamptz NOT NULL DEFAULT now()
+);
+CREATE INDEX repo_renames_repo_idx ON repo_renames (repo_hid, observed_at DESC);
+
 -- "Opt-in views" that surface public identifiers for those who consented
 CREATE VIEW active_allow_repos AS
   SELECT r.principal_hid, c.repo_hid, c.full_name, c.default_branch
@@ -225,9 +283,13 @@ CREATE TABLE ingest_hours (
   db_ms                  int,
   elapsed_ms             int,
   error                  text,
+  event_cap              int,         -- set when the read stopped at MaxEventsPerHour; NULL = full hour
   dropped_due_to_optouts int,
   policy_reverify_count  int,
   policy_reverify_ms     int,
+  collapsed              int,         -- duplicate utterances folded away (CORE_BACKFILL_COLLAPSE_DUPES); 0 when off
+  skipped_short          int,         -- utterances not inserted for short normalized text (CORE_BACKFILL_SKIP_SHORT_TEXT)
+  detected_ver           int,         -- detver backfill --detect last finished the hour at; NULL = never completed
 
   -- Backfill lease (cooperative claim with auto-reclaim)
   bf_lease_claimed_at    timestamptz,
@@ -278,6 +340,28 @@ CREATE INDEX ix_ingest_hours_ns_lease_exp ON ingest_hours (ns_lease_expires_at);
 CREATE INDEX ix_ingest_hours_bf_status ON ingest_hours (bf_status);
 CREATE INDEX ix_ingest_hours_ns_status ON ingest_hours (ns_status);
 
+-- Incremental detect (swearjar-detect -incremental): exclusive high-water hour per detector version
+-- Every backfilled hour before high_water_hour has been detected at that version
+CREATE TABLE detect_state (
+  detector_version int PRIMARY KEY,
+  high_water_hour  timestamptz NOT NULL,
+  updated_at       timestamptz NOT NULL DEFAULT now()
+);
+
+-- Latest hits insert pool snapshot per detver (CORE_DETECT_AUTOSCALE); rendered by /metrics.
+-- Counters restart with each detect process
+CREATE TABLE detect_autoscale (
+  detector_version int PRIMARY KEY,
+  inserters        int NOT NULL,
+  min_inserters    int NOT NULL,
+  max_inserters    int NOT NULL,
+  insert_ewma_ms   bigint NOT NULL,
+  inserts          bigint NOT NULL,
+  grows            bigint NOT NULL,
+  shrinks          bigint NOT NULL,
+  updated_at       timestamptz NOT NULL DEFAULT now()
+);
+
 INSERT INTO rulepacks (version, description, checksum_sha256) VALUES
 (1, 'seed: embedded rules.json v1', '\x644080b9f56902cb95ce7f58dc6115d33819db135dbffbd1cc0f36f7bbcdcdc7');
 
@@ -321,6 +405,17 @@ CREATE TABLE ident.gh_actor_map (
   seen_at    timestamptz NOT NULL DEFAULT now()
 );
 
+-- old_hid -> new_hid per principal, written by swearjar-rehash when the HID derivation
+-- (core/hidscheme) changes; migrations of utterances/hits/principals join through it
+CREATE TABLE ident.hid_rehash_map (
+  principal  principal_enum NOT NULL,
+  to_scheme  smallint       NOT NULL,
+  old_hid    hid_bytes      NOT NULL,
+  new_hid    hid_bytes      NOT NULL,
+  created_at timestamptz    NOT NULL DEFAULT now(),
+  PRIMARY KEY (principal, to_scheme, old_hid)
+);
+
 CREATE TABLE ident.identity_resolve_audit (
   id            uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
   ts            timestamptz NOT NULL DEFAULT now(),
@@ -516,6 +611,7 @@ BEGIN
       DELETE FROM repositories         WHERE repo_hid  = NEW.principal_hid;
       DELETE FROM repo_catalog_queue   WHERE repo_hid  = NEW.principal_hid;
       DELETE FROM ident.gh_repo_map    WHERE repo_hid  = NEW.principal_hid;
+      DELETE FROM repo_renames         WHERE repo_hid  = NEW.principal_hid;
       -- principals row left in place; ingest path should skip via deny checks
     ELSIF NEW.principal='actor' THEN
       DELETE FROM actors               WHERE actor_hid = NEW.principal_hid;
@@ -8,6 +8,7 @@ import (
 
 	"swearjar/internal/adapters/ingest/gharchive"
 	"swearjar/internal/core/langhint"
+	"swearjar/internal/core/normalize"
 )
 
 // Normalizer is a small seam so we don't depend on your concrete type/method names
@@ -23,54 +24,41 @@ type Utterance struct {
 	Actor          string // login
 	CreatedAt      time.Time
 	Source         string // coarse: commit/issue/pr/comment
-	SourceDetail   string // granular: issues:title, pr:body, etc.
+	SourceDetail   string // granular: issues:opened:title, push:commit, etc. (see SourceTable)
 	TextRaw        string
 	TextNormalized string
+	Truncated      bool   // TextRaw was cut to Options.MaxTextBytes
 	LangCode       string // optional; empty => NULL
 	Script         string // optional; empty => NULL
 	Ordinal        int
 }
 
+// Options tunes extraction
+type Options struct {
+	// MaxTextBytes keeps only the first N bytes (at a rune boundary) of each utterance's text,
+	// before normalization, so a pasted log doesn't bloat storage and detection; 0 = no cap
+	MaxTextBytes int
+}
+
 // FromEvent extracts utterances from a GitHub event envelope
 func FromEvent(env gharchive.EventEnvelope, norm Normalizer) []Utterance {
+	return FromEventWithOptions(env, norm, Options{})
+}
+
+// FromEventWithOptions is FromEvent with explicit options
+func FromEventWithOptions(env gharchive.EventEnvelope, norm Normalizer, opts Options) []Utterance {
 	var outs []Utterance
 
 	// keep a stable per-source ordinal for this event
 	ord := map[string]int{}
 
-	coarseOf := func(detail string) string {
-		if i := strings.IndexByte(detail, ':'); i > 0 {
-			switch detail[:i] {
-			case "push":
-				return "commit"
-			case "issues", "issue_comment":
-				return "issue"
-			case "pr", "pr_review_comment":
-				return "pr"
-			case "commit_comment":
-				return "comment"
-			}
-		}
-		// fallback: map known event types to coarse buckets
-		switch env.Type {
-		case "PushEvent":
-			return "commit"
-		case "IssuesEvent", "IssueCommentEvent":
-			return "issue"
-		case "PullRequestEvent", "PullRequestReviewCommentEvent":
-			return "pr"
-		case "CommitCommentEvent":
-			return "comment"
-		default:
-			return "comment"
-		}
-	}
-
-	add := func(detail, txt string) {
+	add := func(src SourceSpec, field, txt string) {
 		t := strings.TrimSpace(txt)
 		if t == "" {
 			return
 		}
+		// keep the head: anger tends to come first, the pasted log after it
+		t, truncated := normalize.TruncateBytes(t, opts.MaxTextBytes)
 
 		var normed string
 		if norm != nil {
@@ -82,21 +70,23 @@ func FromEvent(env gharchive.EventEnvelope, norm Normalizer) []Utterance {
 		script, lang := langhint.DetectScriptAndLang(normed)
 
 		// increment ordinal for this granular source
-		ord[detail]++
-		ordinal := ord[detail]
+		key := src.idKey(field)
+		ord[key]++
+		ordinal := ord[key]
 
-		// build deterministic utterance ID from the envelope's raw payload + (detail, ordinal)
-		uuid := env.DeterministicUUID(detail, ordinal)
+		// build deterministic utterance ID from the envelope's raw payload + (source key, ordinal)
+		uuid := env.DeterministicUUID(key, ordinal)
 		u := Utterance{
 			UtteranceID:    uuid.String(),
 			EventType:      env.Type,
 			Repo:           env.Repo.Name,
 			Actor:          env.Actor.Login,
 			CreatedAt:      time.Time(env.CreatedAt),
-			Source:         coarseOf(detail), // coarse bucket for enums/analytics
-			SourceDetail:   detail,           // granular selector
+			Source:         src.Source,        // coarse bucket for enums/analytics
+			SourceDetail:   src.Detail(field), // granular selector
 			TextRaw:        t,
 			TextNormalized: normed,
+			Truncated:      truncated,
 			LangCode:       lang,
 			Script:         script,
 			Ordinal:        ordinal,
@@ -114,25 +104,29 @@ func FromEvent(env gharchive.EventEnvelope, norm Normalizer) []Utterance {
 			} `json:"commits"`
 		}
 		if err := json.Unmarshal(env.Payload, &p); err == nil {
+			src := ClassifySource(env.Type, "")
 			for _, c := range p.Commits {
-				add("push:commit", c.Message)
+				add(src, "commit", c.Message)
 			}
 		}
 
 	case "IssuesEvent":
 		var p struct {
-			Issue struct {
+			Action string `json:"action"`
+			Issue  struct {
 				Title string `json:"title"`
 				Body  string `json:"body"`
 			} `json:"issue"`
 		}
 		if err := json.Unmarshal(env.Payload, &p); err == nil {
-			add("issues:title", p.Issue.Title)
-			add("issues:body", p.Issue.Body)
+			src := ClassifySource(env.Type, p.Action)
+			add(src, "title", p.Issue.Title)
+			add(src, "body", p.Issue.Body)
 		}
 
 	case "IssueCommentEvent":
 		var p struct {
+			Action  string `json:"action"`
 			Comment struct {
 				Body string `json:"body"`
 			} `json:"comment"`
@@ -141,40 +135,45 @@ func FromEvent(env gharchive.EventEnvelope, norm Normalizer) []Utterance {
 			} `json:"issue"`
 		}
 		if err := json.Unmarshal(env.Payload, &p); err == nil {
-			add("issue_comment:title", p.Issue.Title)
-			add("issue_comment:body", p.Comment.Body)
+			src := ClassifySource(env.Type, p.Action)
+			add(src, "title", p.Issue.Title)
+			add(src, "body", p.Comment.Body)
 		}
 
 	case "PullRequestEvent":
 		var p struct {
+			Action      string `json:"action"`
 			PullRequest struct {
 				Title string `json:"title"`
 				Body  string `json:"body"`
 			} `json:"pull_request"`
 		}
 		if err := json.Unmarshal(env.Payload, &p); err == nil {
-			add("pr:title", p.PullRequest.Title)
-			add("pr:body", p.PullRequest.Body)
+			src := ClassifySource(env.Type, p.Action)
+			add(src, "title", p.PullRequest.Title)
+			add(src, "body", p.PullRequest.Body)
 		}
 
 	case "PullRequestReviewCommentEvent":
 		var p struct {
+			Action  string `json:"action"`
 			Comment struct {
 				Body string `json:"body"`
 			} `json:"comment"`
 		}
 		if err := json.Unmarshal(env.Payload, &p); err == nil {
-			add("pr_review_comment:body", p.Comment.Body)
+			add(ClassifySource(env.Type, p.Action), "body", p.Comment.Body)
 		}
 
 	case "CommitCommentEvent":
 		var p struct {
+			Action  string `json:"action"`
 			Comment struct {
 				Body string `json:"body"`
 			} `json:"comment"`
 		}
 		if err := json.Unmarshal(env.Payload, &p); err == nil {
-			add("commit_comment:body", p.Comment.Body)
+			add(ClassifySource(env.Type, p.Action), "body", p.Comment.Body)
 		}
 	}
 
@@ -0,0 +1,38 @@
+package extract
+
+import (
+	"testing"
+
+	"swearjar/internal/adapters/ingest/gharchive"
+)
+
+func TestFromEventWithOptionsTruncates(t *testing.T) {
+	env := gharchive.EventEnvelope{
+		Type:    "IssueCommentEvent",
+		Payload: []byte(`{"action":"created","comment":{"body":"  naïve fix broke prod  "},"issue":{"title":"ok"}}`),
+	}
+	cases := []struct {
+		max       int
+		body      string
+		truncated bool
+	}{
+		{0, "naïve fix broke prod", false},
+		{64, "naïve fix broke prod", false},
+		{3, "na", true}, // "ï" spans bytes 3-4, so the cut backs off to the rune boundary
+		{4, "naï", true},
+	}
+	for _, tc := range cases {
+		us := FromEventWithOptions(env, nil, Options{MaxTextBytes: tc.max})
+		if len(us) != 2 {
+			t.Fatalf("max %d: got %d utterances, want title and body", tc.max, len(us))
+		}
+		if us[0].TextRaw != "ok" || us[0].Truncated {
+			t.Fatalf("max %d: title %q truncated=%v, want it untouched", tc.max, us[0].TextRaw, us[0].Truncated)
+		}
+		body := us[1]
+		if body.TextRaw != tc.body || body.TextNormalized != tc.body || body.Truncated != tc.truncated {
+			t.Fatalf("max %d: body %q/%q truncated=%v, want %q truncated=%v",
+				tc.max, body.TextRaw, body.TextNormalized, body.Truncated, tc.body, tc.truncated)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package extract
+
+// Coarse sources, matching the utterances.source enum
+const (
+	SourceCommit  = "commit"
+	SourceIssue   = "issue"
+	SourcePR      = "pr"
+	SourceComment = "comment"
+)
+
+// SourceSpec is one row of the source taxonomy: where text from an event type and payload
+// action lands. source_detail reads "<family>:<action>:<field>" ("issue_comment:created:body"),
+// or "<family>:<field>" for the action-less row ("push:commit")
+type SourceSpec struct {
+	EventType string // GH Archive event type, e.g. "IssuesEvent"
+	Action    string // payload action, e.g. "opened"; "" is the type's fallback row
+	Source    string // coarse bucket (SourceCommit ...)
+	Family    string // source_detail prefix, e.g. "issues"
+}
+
+// SourceTable is the (event type, action) -> (source, source_detail) mapping. Every extracted
+// event type has an action-less row; actions not listed (GitHub adds them over time, and
+// older archive hours predate some) use it, so raw payload values never reach source_detail
+var SourceTable = []SourceSpec{
+	{EventType: "PushEvent", Source: SourceCommit, Family: "push"},
+
+	{EventType: "IssuesEvent", Source: SourceIssue, Family: "issues"},
+	{EventType: "IssuesEvent", Action: "opened", Source: SourceIssue, Family: "issues"},
+	{EventType: "IssuesEvent", Action: "edited", Source: SourceIssue, Family: "issues"},
+	{EventType: "IssuesEvent", Action: "closed", Source: SourceIssue, Family: "issues"},
+	{EventType: "IssuesEvent", Action: "reopened", Source: SourceIssue, Family: "issues"},
+
+	{EventType: "IssueCommentEvent", Source: SourceIssue, Family: "issue_comment"},
+	{EventType: "IssueCommentEvent", Action: "created", Source: SourceIssue, Family: "issue_comment"},
+	{EventType: "IssueCommentEvent", Action: "edited", Source: SourceIssue, Family: "issue_comment"},
+
+	{EventType: "PullRequestEvent", Source: SourcePR, Family: "pr"},
+	{EventType: "PullRequestEvent", Action: "opened", Source: SourcePR, Family: "pr"},
+	{EventType: "PullRequestEvent", Action: "edited", Source: SourcePR, Family: "pr"},
+	{EventType: "PullRequestEvent", Action: "closed", Source: SourcePR, Family: "pr"},
+	{EventType: "PullRequestEvent", Action: "reopened", Source: SourcePR, Family: "pr"},
+	{EventType: "PullRequestEvent", Action: "synchronize", Source: SourcePR, Family: "pr"},
+
+	{EventType: "PullRequestReviewCommentEvent", Source: SourcePR, Family: "pr_review_comment"},
+	{EventType: "PullRequestReviewCommentEvent", Action: "created", Source: SourcePR, Family: "pr_review_comment"},
+	{EventType: "PullRequestReviewCommentEvent", Action: "edited", Source: SourcePR, Family: "pr_review_comment"},
+
+	{EventType: "CommitCommentEvent", Source: SourceComment, Family: "commit_comment"},
+	{EventType: "CommitCommentEvent", Action: "created", Source: SourceComment, Family: "commit_comment"},
+}
+
+type sourceKey struct{ eventType, action string }
+
+var sourceIndex = func() map[sourceKey]SourceSpec {
+	m := make(map[sourceKey]SourceSpec, len(SourceTable))
+	for _, s := range SourceTable {
+		m[sourceKey{s.EventType, s.Action}] = s
+	}
+	return m
+}()
+
+// ClassifySource looks up an event's row: the exact (type, action) one, else the type's
+// action-less row. Types missing from the table are coarse "comment" under their own name
+func ClassifySource(eventType, action string) SourceSpec {
+	if s, ok := sourceIndex[sourceKey{eventType, action}]; ok {
+		return s
+	}
+	if s, ok := sourceIndex[sourceKey{eventType, ""}]; ok {
+		return s
+	}
+	return SourceSpec{EventType: eventType, Source: SourceComment, Family: eventType}
+}
+
+// Detail is the source_detail for one text field of the event ("title", "body", "commit")
+func (s SourceSpec) Detail(field string) string {
+	if s.Action == "" {
+		return s.Family + ":" + field
+	}
+	return s.Family + ":" + s.Action + ":" + field
+}
+
+// idKey is the action-less "<family>:<field>" key utterance IDs are derived from. It predates
+// actions in source_detail and is kept so re-ingesting an hour reproduces the same IDs
+func (s SourceSpec) idKey(field string) string { return s.Family + ":" + field }
@@ -0,0 +1,84 @@
+package extract
+
+import (
+	"testing"
+
+	"swearjar/internal/adapters/ingest/gharchive"
+)
+
+func TestSourceTableIsWellFormed(t *testing.T) {
+	coarse := map[string]bool{SourceCommit: true, SourceIssue: true, SourcePR: true, SourceComment: true}
+	seen := map[sourceKey]bool{}
+	fallback := map[string]SourceSpec{}
+	for _, s := range SourceTable {
+		k := sourceKey{s.EventType, s.Action}
+		if seen[k] {
+			t.Fatalf("duplicate row %+v", s)
+		}
+		seen[k] = true
+		if !coarse[s.Source] || s.Family == "" {
+			t.Fatalf("row %+v: want a coarse source enum value and a family", s)
+		}
+		if s.Action == "" {
+			fallback[s.EventType] = s
+		}
+	}
+	// an action row must agree with its type's fallback, so filtering by family or source
+	// never depends on the action
+	for _, s := range SourceTable {
+		fb, ok := fallback[s.EventType]
+		if !ok || fb.Source != s.Source || fb.Family != s.Family {
+			t.Fatalf("row %+v: fallback %+v missing or inconsistent", s, fb)
+		}
+	}
+}
+
+func TestClassifySource(t *testing.T) {
+	cases := []struct {
+		typ, action, field string
+		source, detail     string
+	}{
+		{"PushEvent", "", "commit", SourceCommit, "push:commit"},
+		{"IssuesEvent", "opened", "title", SourceIssue, "issues:opened:title"},
+		{"IssuesEvent", "closed", "body", SourceIssue, "issues:closed:body"},
+		{"IssuesEvent", "labeled", "body", SourceIssue, "issues:body"}, // unlisted action
+		{"IssueCommentEvent", "created", "body", SourceIssue, "issue_comment:created:body"},
+		{"IssueCommentEvent", "", "body", SourceIssue, "issue_comment:body"}, // pre-action archive hours
+		{"PullRequestEvent", "synchronize", "body", SourcePR, "pr:synchronize:body"},
+		{"PullRequestReviewCommentEvent", "created", "body", SourcePR, "pr_review_comment:created:body"},
+		{"CommitCommentEvent", "created", "body", SourceComment, "commit_comment:created:body"},
+		{"GollumEvent", "", "body", SourceComment, "GollumEvent:body"},
+	}
+	for _, tc := range cases {
+		s := ClassifySource(tc.typ, tc.action)
+		if s.Source != tc.source || s.Detail(tc.field) != tc.detail {
+			t.Fatalf("ClassifySource(%q, %q) = %s %s, want %s %s",
+				tc.typ, tc.action, s.Source, s.Detail(tc.field), tc.source, tc.detail)
+		}
+	}
+}
+
+func TestFromEventSourceDetailAndStableIDs(t *testing.T) {
+	env := func(action string) gharchive.EventEnvelope {
+		payload := []byte(`{"action":"` + action + `","comment":{"body":"this is broken"},"issue":{"title":"crash"}}`)
+		return gharchive.EventEnvelope{Type: "IssueCommentEvent", Payload: payload, RawPayload: []byte("same-event")}
+	}
+
+	us := FromEvent(env("created"), nil)
+	if len(us) != 2 {
+		t.Fatalf("got %d utterances, want title and body", len(us))
+	}
+	if us[0].SourceDetail != "issue_comment:created:title" || us[1].SourceDetail != "issue_comment:created:body" {
+		t.Fatalf("details = %q, %q", us[0].SourceDetail, us[1].SourceDetail)
+	}
+	if us[0].Source != SourceIssue || us[1].Source != SourceIssue {
+		t.Fatalf("sources = %q, %q, want issue", us[0].Source, us[1].Source)
+	}
+
+	// IDs derive from the action-less key, so they match what extraction produced before
+	// source_detail carried actions
+	e := env("created")
+	if want := e.DeterministicUUID("issue_comment:body", 1).String(); us[1].UtteranceID != want {
+		t.Fatalf("body id = %s, want %s", us[1].UtteranceID, want)
+	}
+}
@@ -24,6 +24,7 @@ import (
 type CachedFetcher struct {
 	dir             string
 	client          *http.Client
+	url             URLTemplate
 	refreshRecent   time.Duration
 	retainMaxAge    time.Duration
 	retainMaxBytes  int64
@@ -67,12 +68,16 @@ func NewCachedFetcher(dir string, base *HTTPFetcher, opts ...CachedOption) *Cach
 	if base != nil && base.Client != nil {
 		c.client = base.Client
 	}
+	if base != nil {
+		c.url = base.URL
+	}
 	for _, o := range opts {
 		o(c)
 	}
 
 	logger.Named("gharchive").Debug().
 		Str("cache_dir", dir).
+		Str("url_template", c.url.String()).
 		Dur("refresh_recent", c.refreshRecent).
 		Dur("retain_max_age", c.retainMaxAge).
 		Int64("retain_max_bytes", c.retainMaxBytes).
@@ -157,7 +162,7 @@ func (c *CachedFetcher) tryConditionalFetch(
 ) (io.ReadCloser, bool, error) {
 	l := logger.C(ctx)
 
-	url := fmt.Sprintf("%s/%s.json.gz", baseURL, hour.String())
+	url := c.url.URL(hour)
 
 	meta, _ := loadMeta(metaPath)
 
@@ -225,7 +230,7 @@ func (c *CachedFetcher) tryConditionalFetch(
 			f, oerr := os.Open(path)
 			return f, true, oerr
 		}
-		return nil, false, fmt.Errorf("gharchive: unexpected status %d for %s", resp.StatusCode, url)
+		return nil, false, statusError(hour, resp.StatusCode, url)
 	}
 }
 
@@ -238,7 +243,7 @@ func (c *CachedFetcher) downloadAndStore(
 ) (io.ReadCloser, error) {
 	l := logger.C(ctx)
 
-	url := fmt.Sprintf("%s/%s.json.gz", baseURL, hour.String())
+	url := c.url.URL(hour)
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, err
@@ -250,10 +255,11 @@ func (c *CachedFetcher) downloadAndStore(
 		return nil, err
 	}
 	if resp.StatusCode != http.StatusOK {
+		err := statusError(hour, resp.StatusCode, url)
 		if cerr := resp.Body.Close(); cerr != nil {
-			return nil, fmt.Errorf("gharchive: unexpected status %d for %s (body close err: %v)", resp.StatusCode, url, cerr)
+			return nil, fmt.Errorf("%w (body close err: %v)", err, cerr)
 		}
-		return nil, fmt.Errorf("gharchive: unexpected status %d for %s", resp.StatusCode, url)
+		return nil, err
 	}
 	// on first download we return a reader that does not expose Name to keep cache hit metrics correct
 	rc, err := c.writeResponseToCache(ctx, resp, path, metaPath, !markAsHit)
@@ -0,0 +1,42 @@
+package gharchive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"swearjar/internal/platform/logger"
+)
+
+// DirFetcher serves hours from a local directory of <hour>.json.gz files and never
+// touches the network; used to backfill offline against a mounted corpus
+type DirFetcher struct {
+	dir string
+}
+
+// NewDirFetcher builds a read only fetcher over dir
+func NewDirFetcher(dir string) *DirFetcher {
+	logger.Named("gharchive").Debug().
+		Str("dir", dir).
+		Msg("gharchive: dir fetcher initialized")
+	return &DirFetcher{dir: dir}
+}
+
+// Fetch opens the hour file; a missing file wraps os.ErrNotExist with the hour and path
+func (f *DirFetcher) Fetch(ctx context.Context, hour HourRef) (io.ReadCloser, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	path := filepath.Join(f.dir, hour.String()+".json.gz")
+	fh, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("gharchive: hour %s not found in %s: %w", hour.String(), f.dir, os.ErrNotExist)
+		}
+		return nil, fmt.Errorf("gharchive: open %s: %w", path, err)
+	}
+	return fh, nil
+}
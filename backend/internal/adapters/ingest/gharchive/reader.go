@@ -21,6 +21,22 @@ const (
 	sampleRawMax     = 2048 // max bytes of raw JSON to log for the sample
 )
 
+// notYetAvailableWindow is how long after an hour ends a 404 still means "not published yet"
+// GH Archive usually posts an hour within minutes but can lag by a few hours
+const notYetAvailableWindow = 6 * time.Hour
+
+// ErrHourNotYetAvailable is returned for a 404 on a recent hour GH Archive has not posted yet
+// Callers should retry later rather than treat the hour as failed
+var ErrHourNotYetAvailable = errors.New("gharchive: hour not yet available")
+
+// statusError maps a non-200 response to an error; 404s on recent hours wrap ErrHourNotYetAvailable
+func statusError(hour HourRef, status int, url string) error {
+	if status == http.StatusNotFound && !hour.Before(time.Now().Add(-notYetAvailableWindow-time.Hour)) {
+		return fmt.Errorf("%w: status %d for %s", ErrHourNotYetAvailable, status, url)
+	}
+	return fmt.Errorf("gharchive: unexpected status %d for %s", status, url)
+}
+
 // ReaderOptions configures Reader behavior
 type ReaderOptions struct {
 	LogFirstLine     bool // log the very first raw line (even if it fails to decode)
@@ -32,19 +48,44 @@ type Fetcher interface {
 	Fetch(ctx context.Context, hour HourRef) (io.ReadCloser, error)
 }
 
-// HTTPFetcher fetches directly from gharchive.org
+// HTTPFetcher fetches directly from gharchive.org, or the mirror URL renders to
 type HTTPFetcher struct {
 	Client *http.Client
+	URL    URLTemplate // zero value = DefaultURLTemplate
+}
+
+// HTTPOptions tunes the HTTPFetcher client; zero values take the defaults
+type HTTPOptions struct {
+	Timeout             time.Duration // 0 = no client timeout
+	MaxIdleConnsPerHost int           // default 8; size to the number of hours fetched concurrently
+	IdleConnTimeout     time.Duration // default 90s
+	URL                 URLTemplate   // where hours are downloaded from; zero value = DefaultURLTemplate
+}
+
+// NewHTTPFetcher creates a new HTTPFetcher whose transport keeps connections to
+// data.gharchive.org warm between hours
+func NewHTTPFetcher(o HTTPOptions) *HTTPFetcher {
+	if o.MaxIdleConnsPerHost <= 0 {
+		o.MaxIdleConnsPerHost = 8
+	}
+	if o.IdleConnTimeout <= 0 {
+		o.IdleConnTimeout = 90 * time.Second
+	}
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.MaxIdleConns = max(t.MaxIdleConns, o.MaxIdleConnsPerHost)
+	t.MaxIdleConnsPerHost = o.MaxIdleConnsPerHost
+	t.IdleConnTimeout = o.IdleConnTimeout
+	return &HTTPFetcher{Client: &http.Client{Timeout: o.Timeout, Transport: t}, URL: o.URL}
 }
 
 // NewHTTPFetcherWithTimeout creates a new HTTPFetcher with default settings
 func NewHTTPFetcherWithTimeout(d time.Duration) *HTTPFetcher {
-	return &HTTPFetcher{Client: &http.Client{Timeout: d}}
+	return NewHTTPFetcher(HTTPOptions{Timeout: d})
 }
 
 // Fetch returns a reader for the gzip file for the given hour
 func (f *HTTPFetcher) Fetch(ctx context.Context, hour HourRef) (io.ReadCloser, error) {
-	url := fmt.Sprintf("%s/%s.json.gz", baseURL, hour.String())
+	url := f.URL.URL(hour)
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, err
@@ -54,14 +95,11 @@ func (f *HTTPFetcher) Fetch(ctx context.Context, hour HourRef) (io.ReadCloser, e
 		return nil, err
 	}
 	if resp.StatusCode != http.StatusOK {
-		closeErr := resp.Body.Close()
-		if closeErr != nil {
-			return nil, fmt.Errorf(
-				"gharchive: unexpected status %d for %s; error closing body: %v",
-				resp.StatusCode, url, closeErr,
-			)
+		err := statusError(hour, resp.StatusCode, url)
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			return nil, fmt.Errorf("%w; error closing body: %v", err, closeErr)
 		}
-		return nil, fmt.Errorf("gharchive: unexpected status %d for %s", resp.StatusCode, url)
+		return nil, err
 	}
 	return resp.Body, nil
 }
@@ -10,6 +10,7 @@ import (
 	"path"
 	"strings"
 
+	"swearjar/internal/core/hidscheme"
 	identdom "swearjar/internal/services/ident/domain"
 
 	"github.com/google/uuid"
@@ -36,12 +37,12 @@ func SyntheticRepoIDFromName(fullName string) int64 {
 
 // ActorHID32FromLogin makes a stable HID from login (works even when ID=0)
 func ActorHID32FromLogin(login string) identdom.HID32 {
-	return sha256.Sum256([]byte("actor:" + strings.ToLower(strings.TrimSpace(login))))
+	return hidscheme.ActorLogin(strings.ToLower(strings.TrimSpace(login)))
 }
 
 // RepoHID32FromName makes a stable HID from "owner/repo" (works even when ID=0)
 func RepoHID32FromName(fullName string) identdom.HID32 {
-	return sha256.Sum256([]byte("repo:" + CanonRepoName(fullName)))
+	return hidscheme.RepoName(CanonRepoName(fullName))
 }
 
 // HID32 prefers numeric ID, fallback to strings
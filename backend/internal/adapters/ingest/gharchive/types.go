@@ -2,7 +2,6 @@ package gharchive
 
 import (
 	json "encoding/json/v2"
-	"fmt"
 	"strings"
 	"time"
 )
@@ -205,9 +204,10 @@ func NewHourRef(t time.Time) HourRef {
 	}
 }
 
-// String formats as "YYYY-MM-DD-HH"
+// String formats as "YYYY-MM-DD-H" (hour unpadded), GH Archive's file naming; it shares the
+// field formatters with URLTemplate so both agree on padding
 func (h HourRef) String() string {
-	return fmt.Sprintf("%04d-%02d-%02d-%d", h.Year, h.Month, h.Day, h.Hour)
+	return yearField(h) + "-" + monthField(h) + "-" + dayField(h) + "-" + hourField(h)
 }
 
 // Before reports whether the hour is before the given time
@@ -0,0 +1,126 @@
+package gharchive
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// DefaultURLTemplate is GH Archive's own layout, e.g. https://data.gharchive.org/2015-01-01-15.json.gz
+const DefaultURLTemplate = baseURL + "/{hour_ref}.json.gz"
+
+// urlFields are the placeholders a URLTemplate may use. Padding is fixed per field and
+// HourRef.String is built from the same formatters, so {hour_ref} and
+// "{year}-{month}-{day}-{hour}" always render identically
+var urlFields = map[string]func(HourRef) string{
+	"hour_ref": HourRef.String,
+	"year":     yearField,
+	"month":    monthField,
+	"day":      dayField,
+	"hour":     hourField,
+	"hour2":    hour2Field,
+}
+
+func yearField(h HourRef) string  { return fmt.Sprintf("%04d", h.Year) }
+func monthField(h HourRef) string { return fmt.Sprintf("%02d", h.Month) }
+func dayField(h HourRef) string   { return fmt.Sprintf("%02d", h.Day) }
+func hourField(h HourRef) string  { return strconv.Itoa(h.Hour) } // unpadded, as GH Archive names files
+func hour2Field(h HourRef) string { return fmt.Sprintf("%02d", h.Hour) }
+
+// URLTemplate renders the download URL of an archive hour, so fetchers can point at mirrors
+// with other layouts (e.g. "https://mirror.example/{year}/{month}/{day}/{hour2}.json.gz").
+// The zero value renders DefaultURLTemplate
+type URLTemplate struct {
+	raw   string
+	parts []urlPart
+}
+
+// urlPart is either literal text or a placeholder name
+type urlPart struct {
+	lit   string
+	field string
+}
+
+// ParseURLTemplate validates a template: placeholders must be known, braces balanced, the
+// result an absolute http(s) URL, and the fields must pin down one hour ({hour_ref}, or
+// {year}, {month}, {day} and {hour} or {hour2}) so no two hours share a URL.
+// An empty string yields the default template
+func ParseURLTemplate(s string) (URLTemplate, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		s = DefaultURLTemplate
+	}
+	t := URLTemplate{raw: s}
+	rest := s
+	for rest != "" {
+		open := strings.IndexAny(rest, "{}")
+		if open < 0 {
+			t.parts = append(t.parts, urlPart{lit: rest})
+			break
+		}
+		if rest[open] == '}' {
+			return URLTemplate{}, fmt.Errorf("gharchive: url template %q: unmatched '}'", s)
+		}
+		if open > 0 {
+			t.parts = append(t.parts, urlPart{lit: rest[:open]})
+		}
+		name, after, ok := strings.Cut(rest[open+1:], "}")
+		if !ok {
+			return URLTemplate{}, fmt.Errorf("gharchive: url template %q: unclosed '{'", s)
+		}
+		if _, known := urlFields[name]; !known {
+			return URLTemplate{}, fmt.Errorf(
+				"gharchive: url template %q: unknown field {%s} (want hour_ref, year, month, day, hour or hour2)", s, name)
+		}
+		t.parts = append(t.parts, urlPart{field: name})
+		rest = after
+	}
+
+	has := func(f string) bool {
+		return slices.ContainsFunc(t.parts, func(p urlPart) bool { return p.field == f })
+	}
+	if !has("hour_ref") && !(has("year") && has("month") && has("day") && (has("hour") || has("hour2"))) {
+		return URLTemplate{}, fmt.Errorf(
+			"gharchive: url template %q: needs {hour_ref} or {year}, {month}, {day} and {hour}/{hour2}", s)
+	}
+	u, err := url.Parse(t.URL(HourRef{Year: 2015, Month: 1, Day: 1, Hour: 0}))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return URLTemplate{}, fmt.Errorf("gharchive: url template %q: not an absolute http(s) URL", s)
+	}
+	return t, nil
+}
+
+// MustParseURLTemplate is ParseURLTemplate for configuration read at boot; it panics on error
+func MustParseURLTemplate(s string) URLTemplate {
+	t, err := ParseURLTemplate(s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// URL renders the template for an hour
+func (t URLTemplate) URL(h HourRef) string {
+	if len(t.parts) == 0 {
+		return baseURL + "/" + h.String() + ".json.gz"
+	}
+	var b strings.Builder
+	for _, p := range t.parts {
+		if p.field != "" {
+			b.WriteString(urlFields[p.field](h))
+		} else {
+			b.WriteString(p.lit)
+		}
+	}
+	return b.String()
+}
+
+// String returns the template as configured
+func (t URLTemplate) String() string {
+	if t.raw == "" {
+		return DefaultURLTemplate
+	}
+	return t.raw
+}
@@ -0,0 +1,65 @@
+package gharchive
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestURLTemplateLayouts(t *testing.T) {
+	h := HourRef{Year: 2015, Month: 1, Day: 2, Hour: 5}
+	cases := []struct{ tpl, want string }{
+		{"", "https://data.gharchive.org/2015-01-02-5.json.gz"},
+		{DefaultURLTemplate, "https://data.gharchive.org/2015-01-02-5.json.gz"},
+		{"https://mirror.example/{year}/{month}/{day}/{hour2}.json.gz", "https://mirror.example/2015/01/02/05.json.gz"},
+		{"http://10.0.0.7:8080/gha/{year}{month}{day}/{hour}.json.gz", "http://10.0.0.7:8080/gha/20150102/5.json.gz"},
+	}
+	for _, tc := range cases {
+		tpl, err := ParseURLTemplate(tc.tpl)
+		if err != nil {
+			t.Fatalf("ParseURLTemplate(%q): %v", tc.tpl, err)
+		}
+		if got := tpl.URL(h); got != tc.want {
+			t.Fatalf("%q.URL(%v) = %q, want %q", tc.tpl, h, got, tc.want)
+		}
+	}
+}
+
+func TestURLTemplateAgreesWithHourRefString(t *testing.T) {
+	split := MustParseURLTemplate("https://x.example/{year}-{month}-{day}-{hour}.json.gz")
+	for _, h := range []HourRef{{2011, 2, 12, 0}, {2015, 1, 1, 9}, {2024, 12, 31, 10}, {2024, 12, 31, 23}} {
+		want := baseURL + "/" + h.String() + ".json.gz"
+		if got := (URLTemplate{}).URL(h); got != want {
+			t.Fatalf("zero template %q, want %q", got, want)
+		}
+		if got := MustParseURLTemplate(DefaultURLTemplate).URL(h); got != want {
+			t.Fatalf("default template %q, want %q", got, want)
+		}
+		if got, want := split.URL(h), "https://x.example/"+h.String()+".json.gz"; got != want {
+			t.Fatalf("field-wise template %q, want %q", got, want)
+		}
+	}
+}
+
+func TestParseURLTemplateRejects(t *testing.T) {
+	for tpl, want := range map[string]string{
+		"https://m.example/{yyyy}/{hour_ref}.json.gz":  "unknown field",
+		"https://m.example/{hour_ref.json.gz":          "unclosed",
+		"https://m.example/hour_ref}.json.gz":          "unmatched",
+		"https://m.example/{year}/{month}/{hour}.gz":   "needs",
+		"https://m.example/{year}/{month}/{day}.gz":    "needs",
+		"ftp://m.example/{hour_ref}.json.gz":           "absolute http(s)",
+		"/var/mirror/{hour_ref}.json.gz":               "absolute http(s)",
+		"https://m.example/{hour_ref}.json.gz?x={day}": "",
+	} {
+		_, err := ParseURLTemplate(tpl)
+		if want == "" {
+			if err != nil {
+				t.Fatalf("ParseURLTemplate(%q): %v", tpl, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("ParseURLTemplate(%q) err = %v, want %q", tpl, err, want)
+		}
+	}
+}
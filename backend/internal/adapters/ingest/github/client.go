@@ -20,11 +20,18 @@ const (
 	defaultMaxRetry  = 5
 	defaultRetryBase = 500 * time.Millisecond
 
+	// Go's transport keeps only 2 idle conns per host, so a worker pool above that against the
+	// single API host churns TLS handshakes
+	defaultMaxIdlePerHost = 16
+	defaultIdleConnTO     = 90 * time.Second
+
 	apiVersion = "2022-11-28" // keep in sync with GitHub REST docs
 )
 
 // Options configures the Client
 type Options struct {
+	// BaseURL is the REST root; empty means public GitHub. A GitHub Enterprise Server root
+	// includes its API path, e.g. "https://ghe.example.com/api/v3"
 	BaseURL   string
 	UserAgent string
 	Timeout   time.Duration
@@ -36,6 +43,10 @@ type Options struct {
 	// Retry config for transient and rate limited responses
 	MaxRetries int
 	RetryBase  time.Duration
+
+	// Connection reuse; size MaxIdleConnsPerHost to the caller's concurrency
+	MaxIdleConnsPerHost int
+	IdleConnTimeout     time.Duration
 }
 
 // Client is a minimal GitHub REST client with token rotation and ETag support
@@ -52,9 +63,7 @@ type Client struct {
 
 // NewClient creates a new Client with sane defaults
 func NewClient(o Options) *Client {
-	if o.BaseURL == "" {
-		o.BaseURL = baseURLDefault
-	}
+	o.BaseURL = normalizeBaseURL(o.BaseURL)
 	if o.UserAgent == "" {
 		o.UserAgent = defaultUA
 	}
@@ -67,6 +76,12 @@ func NewClient(o Options) *Client {
 	if o.RetryBase <= 0 {
 		o.RetryBase = defaultRetryBase
 	}
+	if o.MaxIdleConnsPerHost <= 0 {
+		o.MaxIdleConnsPerHost = defaultMaxIdlePerHost
+	}
+	if o.IdleConnTimeout <= 0 {
+		o.IdleConnTimeout = defaultIdleConnTO
+	}
 	var toks []string
 	if s := strings.TrimSpace(o.TokensCSV); s != "" {
 		for t := range strings.SplitSeq(s, ",") {
@@ -77,7 +92,7 @@ func NewClient(o Options) *Client {
 		}
 	}
 	return &Client{
-		http:   &http.Client{Timeout: o.Timeout},
+		http:   &http.Client{Timeout: o.Timeout, Transport: newTransport(o)},
 		opts:   o,
 		tokens: toks,
 		state:  make([]tokenState, len(toks)),
@@ -87,6 +102,26 @@ func NewClient(o Options) *Client {
 	}
 }
 
+// newTransport clones the default transport (proxy, dial and TLS timeouts, HTTP/2) and
+// applies the pool settings; every request goes to one host so the per-host cap is the limit
+func newTransport(o Options) *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.MaxIdleConns = max(t.MaxIdleConns, o.MaxIdleConnsPerHost)
+	t.MaxIdleConnsPerHost = o.MaxIdleConnsPerHost
+	t.IdleConnTimeout = o.IdleConnTimeout
+	return t
+}
+
+// normalizeBaseURL defaults an empty root to public GitHub and drops trailing slashes, since
+// every request path starts with one. Anything else is kept as configured
+func normalizeBaseURL(raw string) string {
+	raw = strings.TrimRight(strings.TrimSpace(raw), "/")
+	if raw == "" {
+		return baseURLDefault
+	}
+	return raw
+}
+
 // Do issues an authenticated request with token rotation, etag & retry logic.
 // etagIn is optional and adds If-None-Match for conditional requests
 func (c *Client) Do(ctx context.Context, method, path string, etagIn string) (*http.Response, error) {
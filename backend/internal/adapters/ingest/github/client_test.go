@@ -0,0 +1,21 @@
+package github
+
+import "testing"
+
+func TestNormalizeBaseURL(t *testing.T) {
+	cases := []struct{ in, want string }{
+		{"", "https://api.github.com"},
+		{"  ", "https://api.github.com"},
+		{"https://api.github.com/", "https://api.github.com"},
+		{"https://ghe.example.com/api/v3", "https://ghe.example.com/api/v3"},
+		{"https://ghe.example.com/api/v3//", "https://ghe.example.com/api/v3"},
+		{" https://ghe.example.com/api/v3/ ", "https://ghe.example.com/api/v3"},
+		{"http://127.0.0.1:8080", "http://127.0.0.1:8080"},
+		{"https://ghe.example.com/api%2Fv3", "https://ghe.example.com/api%2Fv3"},
+	}
+	for _, tc := range cases {
+		if got := normalizeBaseURL(tc.in); got != tc.want {
+			t.Errorf("normalizeBaseURL(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
@@ -3,10 +3,12 @@ package github
 
 import (
 	"context"
+	"encoding/base64"
 	json "encoding/json/v2"
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 )
 
 // RepoByID fetches a repository by numeric id with optional etag
@@ -151,6 +153,48 @@ func (c *Client) RepoContent(ctx context.Context, owner, repo, path, ref, etag s
 	return out.HTMLURL, resp.Header.Get("ETag"), false, nil
 }
 
+// RepoFileBody returns html_url and the decoded content of a file, or empty values when 404/missing
+// The contents API inlines files up to 1 MB, which bounds what callers can read this way
+func (c *Client) RepoFileBody(ctx context.Context, owner, repo, path, ref string) (string, []byte, error) {
+	p := fmt.Sprintf("/repos/%s/%s/contents/%s?ref=%s", owner, repo, path, ref)
+	resp, err := c.Do(ctx, http.MethodGet, p, "")
+	if err != nil {
+		return "", nil, err
+	}
+	defer func() {
+		if cerr := resp.Body.Close(); cerr != nil {
+			c.log.Error().Err(cerr).Str("path", p).Msg("github close body failed")
+		}
+	}()
+	if resp.StatusCode == http.StatusNotFound {
+		return "", nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", nil, &GHStatusError{Status: resp.StatusCode, Err: fmt.Errorf("contents %d", resp.StatusCode)}
+	}
+	var out struct {
+		HTMLURL  string `json:"html_url"`
+		Type     string `json:"type"`
+		Content  string `json:"content"`
+		Encoding string `json:"encoding"`
+	}
+	b, _ := io.ReadAll(io.LimitReader(resp.Body, 2<<20))
+	if err := json.Unmarshal(b, &out); err != nil {
+		return "", nil, err
+	}
+	if out.Type != "file" {
+		return "", nil, nil
+	}
+	if out.Encoding != "base64" {
+		return "", nil, fmt.Errorf("contents %s: unsupported encoding %q", path, out.Encoding)
+	}
+	body, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(out.Content, "\n", ""))
+	if err != nil {
+		return "", nil, fmt.Errorf("contents %s: %w", path, err)
+	}
+	return out.HTMLURL, body, nil
+}
+
 // ListPublicGists returns a page of gists for a user.
 // NOTE (2025): Many callers now receive 401 when unauthenticated.
 // We therefore use the authenticated Do() path (Bearer PAT)
@@ -36,6 +36,22 @@ func (p *Probe) RepoFile(ctx context.Context, ownerRepo, defaultBranch, filename
 	return true, html, nil
 }
 
+// RepoFileContent is RepoFile plus the file's decoded content
+// Returns (exists, html_url, body, err)
+func (p *Probe) RepoFileContent(
+	ctx context.Context, ownerRepo, defaultBranch, filename string,
+) (bool, string, []byte, error) {
+	owner, repo, _ := strings.Cut(ownerRepo, "/")
+	html, body, err := p.c.RepoFileBody(ctx, owner, repo, filename, defaultBranch)
+	if err != nil {
+		return false, "", nil, err
+	}
+	if html == "" {
+		return false, "", nil, nil
+	}
+	return true, html, body, nil
+}
+
 // GistFile iterates public gists until we find a file named `filename`.
 // Returns (exists, html_url, err)
 func (p *Probe) GistFile(ctx context.Context, login, filename string) (bool, string, error) {
@@ -2,7 +2,11 @@
 package detector
 
 import (
+	"cmp"
+	"slices"
+	"sort"
 	"strings"
+	"unicode"
 	"unicode/utf8"
 
 	"swearjar/internal/core/normalize"
@@ -27,6 +31,9 @@ type Hit struct {
 	Spans           [][2]int
 	Source          Source
 	DetectorVersion int
+	// RuleID is the template ID or lemma ID (rulepack.Lemma.ID) that fired; set when
+	// Options.RuleIDs is on. A CollapseAdjacent hit carries its best member's rule
+	RuleID string
 
 	Pre   string   // up to Options.ContextWindow bytes preceding the first span
 	Post  string   // up to Options.ContextWindow bytes following the last span
@@ -40,6 +47,16 @@ type Hit struct {
 	TargetEnd      int    // absolute byte offset (exclusive)
 	TargetDistance int    // abs(bytes) from hit center to target start
 	CtxAction      string // "none" | "upgraded" | "downgraded"
+
+	// Shouting is set when the span was all caps in the original text; only ScanCased can tell
+	Shouting bool
+
+	// Merged is how many raw hits CollapseAdjacent folded into this one (0 = not merged)
+	Merged int
+
+	// SuppressedBy is the stoplisted token (or, under SelfSuppress, the self-reference) that
+	// dropped this match; only set on hits returned as suppressed by ScanWithSuppressed
+	SuppressedBy string
 }
 
 // Options controls detector behavior
@@ -48,15 +65,75 @@ type Options struct {
 	MaxTotalHits int
 	// AllowOverlapping allows lemma hits to overlap (default false)
 	AllowOverlapping bool
+	// CollapseOverlapping keeps only the highest-severity hit per overlapping region after
+	// the scan (template wins ties); unlike AllowOverlapping it spans templates and lemmas
+	CollapseOverlapping bool
+	// CollapseAdjacent merges hits whose extents lie within this many bytes of each other into
+	// one "rant" hit carrying every span and the max severity (0 = off). It runs after
+	// CollapseOverlapping; MaxTotalHits still caps the raw hits before they are merged
+	CollapseAdjacent int
 	// Context window size (bytes) for Pre/Post + target search; 0 disables context capture/targeting
 	ContextWindow int
 	// Severity dampening within zones (negative numbers reduce severity)
-	// Clamp is applied after summing deltas (min 1)
+	// Clamp is applied after summing deltas (min 1, max MaxSeverity)
 	SeverityDeltaInCodeFence  int
 	SeverityDeltaInCodeInline int
 	SeverityDeltaInQuote      int
+	SeverityDeltaInURL        int
+	SeverityDeltaInDiff       int
+	// SeverityDeltaShouting is added to hits whose span was all caps before normalization
+	// ("FUCK THIS"), summed and clamped with the zone deltas. Needs ScanCased; 0 = off
+	SeverityDeltaShouting int
+	// SkipQuoteZones drops hits whose span falls in a '>' quoted line, so a reply isn't
+	// charged with the profanity it quotes; dampening via SeverityDeltaInQuote is moot then
+	SkipQuoteZones bool
+	// SeverityDeltaQuotedReport is added to slur_masked hits inside a '>' quote zone when the
+	// utterance's own unquoted text carries one of the pack's reporting cues ("this user
+	// called me ..."), so reporting abuse isn't scored like committing it. Summed and clamped
+	// with the zone deltas, and never lowers exempt categories; 0 = off
+	SeverityDeltaQuotedReport int
+	// MaxSeverity caps every emitted severity after dampening, so hits always land in
+	// [1, MaxSeverity]; 0 keeps the historical behavior (floor of 1, no ceiling)
+	MaxSeverity int
+	// ZoneDampeningExemptCategories lists rule categories (e.g., "harassment") that zone
+	// deltas may never push below the rule's own severity; boosts still apply
+	ZoneDampeningExemptCategories []string
+	// ReportSuppressed makes ScanWithSuppressed return stoplisted matches instead of dropping
+	// them silently; a tuning aid for rule authors, leave off in production
+	ReportSuppressed bool
+	// LangScoped makes ScanLang apply only rules tagged with the utterance's language plus
+	// language-neutral ones; off, every rule runs regardless of lang_code
+	LangScoped bool
+	// SelfDirected enables first-person targeting from the pack's TARGET_SELF slot (e.g. "my
+	// code is broken as fuck"); see SelfTag/SelfDowngrade/SelfSuppress. Concrete bot/tool/lang
+	// targets always win over a self-reference. Empty ignores self-references. The pack leaves
+	// the bare pronoun "i" out: normalization folds '1' and '!' into it ("1 test failed")
+	SelfDirected string
+	// SortHits returns hits ordered by start offset, then category, then source (remaining
+	// ties by end, term, severity, rule ID), instead of scan order; for golden tests and reproducible
+	// exports. It runs last, after any collapsing
+	SortHits bool
+	// FoldHomoglyphs scans with Cyrillic/Greek lookalikes in mixed-script words folded to
+	// Latin ("fuсk" with a Cyrillic с), see normalize.FoldHomoglyphs. Spans, targets and
+	// context still index the text passed in. Whole Cyrillic/Greek words are never folded
+	FoldHomoglyphs bool
+	// RuleIDs stamps Hit.RuleID with the template or lemma that produced each hit, for
+	// attributing hits to rules when debugging or counting per-rule
+	RuleIDs bool
+	// RequireContextSignals enforces the context signals templates declare
+	// (context_signals.requires/requires_any, see rulepack.Signals): a template match whose
+	// neighborhood doesn't satisfy them is dropped, reported as "context:<signal>" when
+	// ReportSuppressed is on. Off, declared requirements are ignored
+	RequireContextSignals bool
 }
 
+// SelfDirected modes
+const (
+	SelfTag       = "tag"       // tag TargetType "self"; frustrated generic hits become self_own
+	SelfDowngrade = "downgrade" // tag, and lower severity by one (still clamped)
+	SelfSuppress  = "suppress"  // drop self-directed hits
+)
+
 // slotType mirrors the logical slot kinds
 type slotType string
 
@@ -65,6 +142,7 @@ const (
 	slotTool      slotType = "tool"
 	slotLang      slotType = "lang"
 	slotFramework slotType = "framework"
+	slotSelf      slotType = "self"
 )
 
 // aliasEntry is a flattened alias name -> (type,id) row
@@ -80,12 +158,19 @@ type Detector struct {
 	version int
 	opts    Options
 
+	dampExempt map[string]struct{} // from Options.ZoneDampeningExemptCategories
+
 	ac         *acAutomaton
 	lemmaIndex []rulepack.Lemma
 	lemmaLens  []int
 
 	// contextual targeting
-	aliases []aliasEntry // flat index to scan quickly
+	aliases []aliasEntry // flat index, sorted by name; position is the automaton pattern ID
+	aliasAC *acAutomaton // over alias names, so a context region is scanned once per hit
+
+	// first-person lexicon (TARGET_SELF), kept apart so it only applies when nothing concrete is near
+	selfAliases []aliasEntry
+	selfAC      *acAutomaton
 }
 
 // New creates a Detector with default options
@@ -96,6 +181,12 @@ func New(p *rulepack.Pack, detectorVersion int) *Detector {
 // NewWithOptions creates a Detector with custom options
 func NewWithOptions(p *rulepack.Pack, detectorVersion int, opts Options) *Detector {
 	d := &Detector{p: p, version: detectorVersion, opts: opts}
+	if len(opts.ZoneDampeningExemptCategories) > 0 {
+		d.dampExempt = make(map[string]struct{}, len(opts.ZoneDampeningExemptCategories))
+		for _, c := range opts.ZoneDampeningExemptCategories {
+			d.dampExempt[c] = struct{}{}
+		}
+	}
 
 	// Build AC automaton over lemmas
 	ac := newAutomaton()
@@ -124,6 +215,7 @@ func (d *Detector) initAliasIndex() {
 		return
 	}
 	out := make([]aliasEntry, 0, len(d.p.SlotNameToRef))
+	var self []aliasEntry
 	for nm, ref := range d.p.SlotNameToRef {
 		var t slotType
 		switch ref.Type {
@@ -135,24 +227,116 @@ func (d *Detector) initAliasIndex() {
 			t = slotLang
 		case "framework":
 			t = slotFramework
+		case "self":
+			t = slotSelf
 		default:
 			continue
 		}
-		out = append(out, aliasEntry{
-			typ:  t,
-			id:   ref.ID,
-			name: strings.ToLower(strings.TrimSpace(nm)),
-		})
+		name := strings.ToLower(strings.TrimSpace(nm))
+		if name == "" {
+			continue
+		}
+		if t == slotSelf {
+			self = append(self, aliasEntry{typ: t, id: ref.ID, name: name})
+			continue
+		}
+		out = append(out, aliasEntry{typ: t, id: ref.ID, name: name})
+	}
+	d.aliases, d.aliasAC = buildAliasIndex(out)
+	if len(self) > 0 {
+		d.selfAliases, d.selfAC = buildAliasIndex(self)
 	}
-	d.aliases = out
+}
+
+// buildAliasIndex sorts entries and builds the automaton over their names
+func buildAliasIndex(out []aliasEntry) ([]aliasEntry, *acAutomaton) {
+	// Stable order makes equal-distance ties deterministic (lowest index wins)
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].name != out[j].name {
+			return out[i].name < out[j].name
+		}
+		return out[i].typ < out[j].typ
+	})
+
+	ac := newAutomaton()
+	for i, al := range out {
+		ac.AddPattern([]byte(al.name), i)
+	}
+	ac.Build()
+	return out, ac
 }
 
 // Scan runs detection over a normalized string, returning hits
 func (d *Detector) Scan(norm string) []Hit {
-	var hits []Hit
+	hits, _ := d.ScanWithSuppressed(norm, "")
+	return hits
+}
+
+// ScanLang is Scan for an utterance whose lang_code is lang (BCP-47; only the base subtag is
+// used). With Options.LangScoped, rules for other languages are skipped; an empty lang
+// applies every rule since an unknown language shouldn't silence detection
+func (d *Detector) ScanLang(norm, lang string) []Hit {
+	hits, _ := d.ScanWithSuppressed(norm, lang)
+	return hits
+}
+
+// ScanWithSuppressed is ScanLang that also returns matches the stoplist dropped, tagged with
+// the suppressing token, when Options.ReportSuppressed is set. Suppressed hits keep the rule's
+// raw severity and zones but get no context or targeting, and never count toward MaxTotalHits
+func (d *Detector) ScanWithSuppressed(norm, lang string) (hits, suppressed []Hit) {
+	return d.ScanCased(norm, "", lang)
+}
+
+// ScanCased is ScanWithSuppressed with the case-preserving projection of norm from
+// normalize.NormalizeCased, which lets hits carry the Shouting signal. An empty cased (or one
+// that doesn't line up with norm) disables the signal rather than guessing
+func (d *Detector) ScanCased(norm, cased, lang string) (hits, suppressed []Hit) {
 	if norm == "" {
-		return hits
+		return hits, nil
+	}
+	if len(cased) != len(norm) {
+		cased = ""
+	}
+	if !d.opts.FoldHomoglyphs {
+		return d.scan(norm, cased, lang)
+	}
+	f := normalize.FoldHomoglyphs(norm)
+	if !f.Changed() {
+		return d.scan(norm, cased, lang)
+	}
+
+	// upper case lookalikes fold in step, so the cased projection shrinks the same way
+	fc := normalize.FoldHomoglyphs(cased).Text
+	if len(fc) != len(f.Text) {
+		fc = ""
+	}
+	hits, suppressed = d.scan(f.Text, fc, lang)
+	d.unfold(hits, f, norm)
+	d.unfold(suppressed, f, norm)
+	return hits, suppressed
+}
+
+// unfold maps hits found over folded text back onto the text it was folded from; Pre/Post
+// are re-cut from the source so stored context shows what was actually written
+func (d *Detector) unfold(hits []Hit, f normalize.Folded, src string) {
+	for i := range hits {
+		h := &hits[i]
+		for j := range h.Spans {
+			h.Spans[j] = [2]int{f.Source(h.Spans[j][0]), f.Source(h.Spans[j][1])}
+		}
+		if h.TargetName != "" {
+			h.TargetStart, h.TargetEnd = f.Source(h.TargetStart), f.Source(h.TargetEnd)
+		}
+		if h.Pre != "" || h.Post != "" {
+			start, end := extent(*h)
+			h.Pre, h.Post = contextAround(src, start, end, d.opts.ContextWindow)
+		}
 	}
+}
+
+// scan is ScanCased over text that is already in its final (possibly folded) form
+func (d *Detector) scan(norm, cased, lang string) (hits, suppressed []Hit) {
+	ruleOK := d.langFilter(lang)
 
 	maxHits := d.opts.MaxTotalHits
 	if maxHits > 0 {
@@ -166,6 +350,16 @@ func (d *Detector) Scan(norm string) []Hit {
 	// Detect zones once per document
 	zones := normalize.DetectZones(norm)
 	cwEnabled := d.opts.ContextWindow > 0
+	reporting := d.opts.SeverityDeltaQuotedReport != 0 && d.reportingContext(norm, zones)
+
+	suppress := func(h Hit, token string) {
+		if !d.opts.ReportSuppressed {
+			return
+		}
+		h.Zones = zoneTagsForSpan(zones, h.Spans[0][0], h.Spans[0][1])
+		h.SuppressedBy = token
+		suppressed = append(suppressed, h)
+	}
 
 	// Stage A: templates (use Pack.Compiled and metadata from Pack.Templates)
 TEMPLATES:
@@ -175,16 +369,32 @@ TEMPLATES:
 			continue
 		}
 		tmeta := d.p.Templates[i] // PatternExpanded/Category/Severity/ContextSignals
+		if !ruleOK(tmeta.Lang) {
+			continue
+		}
 		prs := re.FindAllStringIndex(norm, -1)
 		if len(prs) == 0 {
 			continue
 		}
 
 		isFrustration := hasFrustration(tmeta.ContextSignals)
+		ruleID := d.ruleID(tmeta.ID)
 
 		for _, pr := range prs {
 			start, end := pr[0], pr[1]
-			if !d.boundaryOK(norm, start, end) || d.inStoplist(norm, start, end) {
+			if !d.boundaryOK(norm, start, end) {
+				continue
+			}
+			if tok, banned := d.stoplistToken(norm, start, end); banned {
+				suppress(Hit{
+					Term:            norm[start:end],
+					Category:        tmeta.Category,
+					Severity:        tmeta.Severity,
+					Source:          SourceTemplate,
+					DetectorVersion: d.version,
+					RuleID:          ruleID,
+					Spans:           [][2]int{{start, end}},
+				}, tok)
 				continue
 			}
 
@@ -196,15 +406,29 @@ TEMPLATES:
 				Severity:        tmeta.Severity,
 				Source:          SourceTemplate,
 				DetectorVersion: d.version,
+				RuleID:          ruleID,
 				Spans:           [][2]int{{start, end}},
 			}
 
 			h.Zones = zoneTagsForSpan(zones, start, end)
-			h.Severity = d.applyZoneDampening(h.Severity, h.Zones)
+			if d.quoteSkipped(h.Zones) {
+				continue
+			}
+			if d.opts.RequireContextSignals && !tmeta.Requires.Empty() {
+				if ok, sig := d.contextSignalsMet(norm, zones, tmeta.Requires, start, end); !ok {
+					suppress(h, "context:"+sig)
+					continue
+				}
+			}
+			h.Shouting = shouting(cased, start, end)
+			h.Severity = d.applyZoneDampening(d.shoutBoost(h)+d.quotedReportDelta(h, reporting), h.Category, h.Zones)
 
 			if cwEnabled {
 				h.Pre, h.Post = contextAround(norm, start, end, d.opts.ContextWindow)
-				d.applyTargetingAndGating(norm, &h, isFrustration)
+				if d.applyTargetingAndGating(norm, &h, isFrustration) {
+					suppress(h, h.TargetName)
+					continue
+				}
 			}
 
 			appendHit(h)
@@ -223,35 +447,217 @@ TEMPLATES:
 			if !d.opts.AllowOverlapping && start < lastEnd {
 				return true
 			}
-			if d.boundaryOK(norm, start, end) && !d.inStoplist(norm, start, end) {
-				lm := d.lemmaIndex[lemmaID]
-				h := Hit{
+			lm := d.lemmaIndex[lemmaID]
+			if !ruleOK(lm.Lang) || !d.boundaryOK(norm, start, end) {
+				return true
+			}
+			if tok, banned := d.stoplistToken(norm, start, end); banned {
+				suppress(Hit{
 					Term:            lm.Term,
 					Category:        lm.Category,
 					Severity:        lm.Severity,
 					Source:          SourceLemma,
 					DetectorVersion: d.version,
+					RuleID:          d.ruleID(lm.ID),
 					Spans:           [][2]int{{start, end}},
+				}, tok)
+				return true
+			}
+			h := Hit{
+				Term:            lm.Term,
+				Category:        lm.Category,
+				Severity:        lm.Severity,
+				Source:          SourceLemma,
+				DetectorVersion: d.version,
+				RuleID:          d.ruleID(lm.ID),
+				Spans:           [][2]int{{start, end}},
+			}
+			h.Zones = zoneTagsForSpan(zones, start, end)
+			if d.quoteSkipped(h.Zones) {
+				return true
+			}
+			h.Shouting = shouting(cased, start, end)
+			h.Severity = d.applyZoneDampening(d.shoutBoost(h)+d.quotedReportDelta(h, reporting), h.Category, h.Zones)
+			if cwEnabled {
+				h.Pre, h.Post = contextAround(norm, start, end, d.opts.ContextWindow)
+				if d.applyTargetingAndGating(norm, &h, false) {
+					suppress(h, h.TargetName)
+					return true
 				}
-				h.Zones = zoneTagsForSpan(zones, start, end)
-				h.Severity = d.applyZoneDampening(h.Severity, h.Zones)
-				if cwEnabled {
-					h.Pre, h.Post = contextAround(norm, start, end, d.opts.ContextWindow)
-					d.applyTargetingAndGating(norm, &h, false)
-				}
-				hits = append(hits, h)
-				if !d.opts.AllowOverlapping {
-					lastEnd = end
-				}
-				if maxHits > 0 && len(hits) >= maxHits {
-					return false
-				}
+			}
+			hits = append(hits, h)
+			if !d.opts.AllowOverlapping {
+				lastEnd = end
+			}
+			if maxHits > 0 && len(hits) >= maxHits {
+				return false
 			}
 			return true
 		})
 	}
 
-	return hits
+	if d.opts.CollapseOverlapping {
+		hits = collapseOverlapping(hits)
+	}
+	if d.opts.CollapseAdjacent > 0 {
+		hits = collapseAdjacent(hits, d.opts.CollapseAdjacent)
+	}
+	if d.opts.SortHits {
+		sortHits(hits)
+		sortHits(suppressed)
+	}
+	return hits, suppressed
+}
+
+// langFilter returns the rule-language predicate for an utterance language
+func (d *Detector) langFilter(lang string) func(ruleLang string) bool {
+	base, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(lang)), "-")
+	base, _, _ = strings.Cut(base, "_")
+	if !d.opts.LangScoped || base == "" {
+		return func(string) bool { return true }
+	}
+	return func(ruleLang string) bool { return ruleLang == "" || ruleLang == base }
+}
+
+// collapseOverlapping merges hits whose extents overlap into regions and keeps one hit per
+// region: highest severity, then template over lemma, then earliest/longest. Output is by start
+func collapseOverlapping(hits []Hit) []Hit {
+	if len(hits) < 2 {
+		return hits
+	}
+	order := byStart(hits)
+
+	out := hits[:0:0]
+	best, regionEnd := -1, -1
+	for _, i := range order {
+		s, e := extent(hits[i])
+		if best >= 0 && s < regionEnd {
+			if outranks(hits[i], hits[best]) {
+				best = i
+			}
+			regionEnd = max(regionEnd, e)
+			continue
+		}
+		if best >= 0 {
+			out = append(out, hits[best])
+		}
+		best, regionEnd = i, e
+	}
+	return append(out, hits[best])
+}
+
+// collapseAdjacent folds runs of hits whose extents are at most dist bytes apart into one hit
+// per run. The run's best hit (as collapseOverlapping ranks them) supplies term, category and
+// targeting; Severity is the run max, Spans are every member span by start, Pre comes from the
+// first member and Post from the last. Lone hits pass through with Merged 0
+func collapseAdjacent(hits []Hit, dist int) []Hit {
+	if len(hits) < 2 {
+		return hits
+	}
+	order := byStart(hits)
+
+	out := hits[:0:0]
+	var run []int
+	flush := func() {
+		if len(run) == 1 {
+			out = append(out, hits[run[0]])
+			return
+		}
+		best := run[0]
+		for _, i := range run[1:] {
+			if outranks(hits[i], hits[best]) {
+				best = i
+			}
+		}
+		h := hits[best]
+		h.Spans, h.Zones = nil, nil
+		for _, i := range run {
+			h.Spans = append(h.Spans, hits[i].Spans...)
+			for _, z := range hits[i].Zones {
+				if !slices.Contains(h.Zones, z) {
+					h.Zones = append(h.Zones, z)
+				}
+			}
+		}
+		sort.SliceStable(h.Spans, func(a, b int) bool { return h.Spans[a][0] < h.Spans[b][0] })
+		h.Pre, h.Post = hits[run[0]].Pre, hits[run[len(run)-1]].Post
+		h.Merged = len(run)
+		out = append(out, h)
+	}
+
+	runEnd := -1
+	for _, i := range order {
+		s, e := extent(hits[i])
+		if len(run) > 0 && s-runEnd <= dist {
+			run = append(run, i)
+			runEnd = max(runEnd, e)
+			continue
+		}
+		if len(run) > 0 {
+			flush()
+		}
+		run, runEnd = append(run[:0], i), e
+	}
+	flush()
+	return out
+}
+
+// sortHits orders hits by start, category, source, then end, term, severity and rule ID, so that
+// hits with identical keys are indistinguishable for any snapshot that compares them
+func sortHits(hits []Hit) {
+	slices.SortStableFunc(hits, func(a, b Hit) int {
+		as, ae := extent(a)
+		bs, be := extent(b)
+		switch {
+		case as != bs:
+			return cmp.Compare(as, bs)
+		case a.Category != b.Category:
+			return strings.Compare(a.Category, b.Category)
+		case a.Source != b.Source:
+			return strings.Compare(string(a.Source), string(b.Source))
+		case ae != be:
+			return cmp.Compare(ae, be)
+		case a.Term != b.Term:
+			return strings.Compare(a.Term, b.Term)
+		case a.Severity != b.Severity:
+			return cmp.Compare(a.Severity, b.Severity)
+		}
+		return strings.Compare(a.RuleID, b.RuleID)
+	})
+}
+
+// extent is the [start,end) range covered by a hit's spans
+func extent(h Hit) (int, int) { return h.Spans[0][0], h.Spans[len(h.Spans)-1][1] }
+
+// byStart returns hit indexes ordered by extent start (stable)
+func byStart(hits []Hit) []int {
+	order := make([]int, len(hits))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		sa, _ := extent(hits[order[a]])
+		sb, _ := extent(hits[order[b]])
+		return sa < sb
+	})
+	return order
+}
+
+// outranks reports whether a should represent a region over b: highest severity, then
+// template over lemma, then earliest, then longest
+func outranks(a, b Hit) bool {
+	if a.Severity != b.Severity {
+		return a.Severity > b.Severity
+	}
+	if a.Source != b.Source {
+		return a.Source == SourceTemplate
+	}
+	as, ae := extent(a)
+	bs, be := extent(b)
+	if as != bs {
+		return as < bs
+	}
+	return ae-as > be-bs
 }
 
 func hasFrustration(cs map[string]any) bool {
@@ -271,9 +677,11 @@ func hasFrustration(cs map[string]any) bool {
 
 // applyTargetingAndGating scans for a nearby target and upgrades/downgrades category.
 // Also fills Target* fields on the hit. If no context window configured, this is a no-op
-func (d *Detector) applyTargetingAndGating(s string, h *Hit, isFrustration bool) {
-	if d.opts.ContextWindow <= 0 || len(d.aliases) == 0 {
-		return
+// It reports true when the hit is self-directed and SelfSuppress says to drop it
+func (d *Detector) applyTargetingAndGating(s string, h *Hit, isFrustration bool) bool {
+	selfOn := d.opts.SelfDirected != "" && len(d.selfAliases) > 0
+	if d.opts.ContextWindow <= 0 || (len(d.aliases) == 0 && !selfOn) {
+		return false
 	}
 
 	a := h.Spans[0][0]
@@ -291,6 +699,39 @@ func (d *Detector) applyTargetingAndGating(s string, h *Hit, isFrustration bool)
 	}
 
 	ok, typ, id, name, ts, te, dist := d.scanNearbyTarget(s, a, b, prefer...)
+	if !ok && selfOn {
+		ok, typ, id, name, ts, te, dist = d.nearestAlias(d.selfAC, d.selfAliases, s, a, b)
+	}
+	if ok && typ == slotSelf {
+		h.TargetType = string(typ)
+		h.TargetID = id
+		h.TargetName = name
+		h.TargetStart = ts
+		h.TargetEnd = te
+		h.TargetDistance = dist
+		// Aimed at oneself: frustration is a self-own, and rage categories lose their target
+		switch h.Category {
+		case "generic":
+			if isFrustration {
+				h.Category = "self_own"
+				h.CtxAction = "upgraded"
+			} else {
+				h.CtxAction = "none"
+			}
+		case "bot_rage", "tooling_rage", "lang_rage":
+			h.Category = "generic"
+			h.CtxAction = "downgraded"
+		default:
+			h.CtxAction = "none"
+		}
+		switch d.opts.SelfDirected {
+		case SelfDowngrade:
+			h.Severity = d.clampSeverity(h.Severity - 1)
+		case SelfSuppress:
+			return true
+		}
+		return false
+	}
 	if ok {
 		h.TargetType = string(typ)
 		h.TargetID = id
@@ -322,6 +763,7 @@ func (d *Detector) applyTargetingAndGating(s string, h *Hit, isFrustration bool)
 			h.CtxAction = "none"
 		}
 	}
+	return false
 }
 
 func (d *Detector) boundaryOK(s string, start, end int) bool {
@@ -335,23 +777,36 @@ func (d *Detector) boundaryOK(s string, start, end int) bool {
 	return !isWord(prev) && !isWord(next)
 }
 
-func (d *Detector) inStoplist(s string, start, end int) bool {
+// stoplistToken expands the match to its enclosing token and reports it when stoplisted
+func (d *Detector) stoplistToken(s string, start, end int) (string, bool) {
 	ls, rs := expandToToken(s, start, end)
 	token := s[ls:rs]
 	_, banned := d.p.Stopset[token]
-	return banned
+	return token, banned
+}
+
+// ruleID is the rule identifier stamped onto hits: id under Options.RuleIDs, else ""
+func (d *Detector) ruleID(id string) string {
+	if !d.opts.RuleIDs {
+		return ""
+	}
+	return id
+}
+
+// quoteSkipped reports whether a hit tagged with zones is dropped by SkipQuoteZones
+func (d *Detector) quoteSkipped(zones []string) bool {
+	return d.opts.SkipQuoteZones && slices.Contains(zones, string(normalize.ZoneQuote))
 }
 
 // applyZoneDampening adjusts severity by configured deltas for any overlapping zones.
-// Clamps to a minimum of 1
-func (d *Detector) applyZoneDampening(sev int, zones []string) int {
+// Clamps to [1, MaxSeverity]; exempt categories never drop below their original severity
+func (d *Detector) applyZoneDampening(sev int, category string, zones []string) int {
 	if len(zones) == 0 || (d.opts.SeverityDeltaInCodeFence|
 		d.opts.SeverityDeltaInCodeInline|
-		d.opts.SeverityDeltaInQuote) == 0 {
-		if sev < 1 {
-			return 1
-		}
-		return sev
+		d.opts.SeverityDeltaInQuote|
+		d.opts.SeverityDeltaInURL|
+		d.opts.SeverityDeltaInDiff) == 0 {
+		return d.clampSeverity(sev)
 	}
 
 	delta := 0
@@ -363,125 +818,192 @@ func (d *Detector) applyZoneDampening(sev int, zones []string) int {
 			delta += d.opts.SeverityDeltaInCodeInline
 		case string(normalize.ZoneQuote):
 			delta += d.opts.SeverityDeltaInQuote
+		case string(normalize.ZoneURL):
+			delta += d.opts.SeverityDeltaInURL
+		case string(normalize.ZoneDiff):
+			delta += d.opts.SeverityDeltaInDiff
 		}
 	}
-	sev += delta
-	if sev < 1 {
-		sev = 1
+	if _, exempt := d.dampExempt[category]; exempt && delta < 0 {
+		delta = 0
 	}
-	return sev
+	return d.clampSeverity(sev + delta)
 }
 
-// contextAround returns [pre, post] around [start,end)
+// shoutBoost is the hit's severity plus the shouting delta when it applies; clamping is left
+// to applyZoneDampening so every delta is summed first
+func (d *Detector) shoutBoost(h Hit) int {
+	if h.Shouting {
+		return h.Severity + d.opts.SeverityDeltaShouting
+	}
+	return h.Severity
+}
+
+// quotedReportDelta is SeverityDeltaQuotedReport for a quoted slur_masked hit (rule severity)
+// in a reporting utterance, else 0; like shoutBoost it is clamped by applyZoneDampening
+func (d *Detector) quotedReportDelta(h Hit, reporting bool) int {
+	if !reporting || !slices.Contains(h.Zones, string(normalize.ZoneQuote)) ||
+		d.p.SeverityLabel(h.Severity) != rulepack.SeveritySlurMasked {
+		return 0
+	}
+	if _, exempt := d.dampExempt[h.Category]; exempt && d.opts.SeverityDeltaQuotedReport < 0 {
+		return 0
+	}
+	return d.opts.SeverityDeltaQuotedReport
+}
+
+// reportingContext reports whether a pack reporting cue occurs on word boundaries outside
+// every quote zone: only the author's own words count, so a quoted attack can't vouch for itself
+func (d *Detector) reportingContext(norm string, zones []normalize.ZoneSpan) bool {
+	for _, cue := range d.p.ReportingCues {
+		for off := 0; off < len(norm); {
+			i := strings.Index(norm[off:], cue)
+			if i < 0 {
+				break
+			}
+			start, end := off+i, off+i+len(cue)
+			if d.boundaryOK(norm, start, end) &&
+				!slices.Contains(zoneTagsForSpan(zones, start, end), string(normalize.ZoneQuote)) {
+				return true
+			}
+			off = end
+		}
+	}
+	return false
+}
+
+// shouting reports whether cased[start:end] has at least two letters and all of them are
+// upper case; non-letters (leet digits, punctuation) are ignored
+func shouting(cased string, start, end int) bool {
+	if cased == "" || end > len(cased) {
+		return false
+	}
+	upper := 0
+	for _, r := range cased[start:end] {
+		switch {
+		case unicode.IsLower(r):
+			return false
+		case unicode.IsUpper(r):
+			upper++
+		}
+	}
+	return upper >= 2
+}
+
+// clampSeverity bounds a final severity to [1, MaxSeverity] (no ceiling when MaxSeverity is 0)
+func (d *Detector) clampSeverity(sev int) int {
+	if d.opts.MaxSeverity > 0 && sev > d.opts.MaxSeverity {
+		sev = d.opts.MaxSeverity
+	}
+	return max(sev, 1)
+}
+
+// contextAround returns [pre, post] around [start,end), at most win bytes each.
+// Window edges that land inside a multibyte rune shrink to the nearest rune boundary
+// so Pre/Post stay valid UTF-8
 func contextAround(s string, start, end, win int) (string, string) {
 	if win <= 0 {
 		return "", ""
 	}
 	ls := max(start-win, 0)
+	for ls < start && !utf8.RuneStart(s[ls]) {
+		ls++
+	}
 	rs := min(end+win, len(s))
+	for rs > end && rs < len(s) && !utf8.RuneStart(s[rs]) {
+		rs--
+	}
 	return s[ls:start], s[end:rs]
 }
 
 // scanNearbyTarget searches within the configured ContextWindow around [a,b) for the
-// nearest alias; if 'prefer' is non-empty, it prefers returning a target of those types
+// nearest alias; if 'prefer' is non-empty, it prefers returning a target of those types.
+// The region is scanned once with the alias automaton; ties on distance go to the earlier
+// start, then the lower alias index
 func (d *Detector) scanNearbyTarget(
 	s string,
 	a, b int,
 	prefer ...slotType,
 ) (bool, slotType, string, string, int, int, int) {
-	if len(d.aliases) == 0 || a < 0 || b > len(s) || a >= b {
+	return d.nearestAlias(d.aliasAC, d.aliases, s, a, b, prefer...)
+}
+
+// nearestAlias finds the closest alias of one index around [a,b) within the context window
+func (d *Detector) nearestAlias(
+	ac *acAutomaton,
+	aliases []aliasEntry,
+	s string,
+	a, b int,
+	prefer ...slotType,
+) (bool, slotType, string, string, int, int, int) {
+	if len(aliases) == 0 || ac == nil || a < 0 || b > len(s) || a >= b {
 		return false, "", "", "", 0, 0, 0
 	}
 	win := d.opts.ContextWindow
-	ls := a - win
-	if ls < 0 {
-		ls = 0
-	}
+	ls := max(a-win, 0)
 	rs := min(b+win, len(s))
 	region := s[ls:rs]
 
-	// Preference filter (optional)
-	var hasPrefer bool
-	var preferSet map[slotType]struct{}
-	if len(prefer) > 0 {
-		hasPrefer = true
-		preferSet = make(map[slotType]struct{}, len(prefer))
-		for _, t := range prefer {
-			preferSet[t] = struct{}{}
+	type candidate struct {
+		ok              bool
+		idx, start, end int
+		dist            int
+	}
+	better := func(c, cur candidate) bool {
+		if !cur.ok || c.dist != cur.dist {
+			return !cur.ok || c.dist < cur.dist
 		}
+		if c.start != cur.start {
+			return c.start < cur.start
+		}
+		return c.idx < cur.idx
 	}
 
-	nearest := struct {
-		ok         bool
-		typ        slotType
-		id, name   string
-		start, end int
-		dist       int
-	}{}
-
 	center := (a + b) / 2
+	var nearest, nearestPref candidate
+	var lastEnd map[int]int // per alias: repeats overlapping the previous occurrence are skipped
 
-	consider := func(al aliasEntry) {
-		pos := strings.Index(region, al.name)
-		for pos >= 0 {
-			absStart := ls + pos
-			absEnd := absStart + len(al.name)
-			if d.boundaryOK(s, absStart, absEnd) {
-				dist := abs(center - absStart)
-				if !nearest.ok || dist < nearest.dist ||
-					(dist == nearest.dist && absStart < nearest.start) {
-					nearest.ok = true
-					nearest.typ = al.typ
-					nearest.id = al.id
-					nearest.name = al.name
-					nearest.start = absStart
-					nearest.end = absEnd
-					nearest.dist = dist
-				}
-			}
-			// find next occurrence
-			next := strings.Index(region[pos+len(al.name):], al.name)
-			if next < 0 {
-				break
-			}
-			pos += len(al.name) + next
+	ac.FindAll([]byte(region), func(end, idx int) bool {
+		al := aliases[idx]
+		start := end - len(al.name)
+		if prev, seen := lastEnd[idx]; seen && start < prev {
+			return true
 		}
-	}
+		if lastEnd == nil {
+			lastEnd = make(map[int]int, 4)
+		}
+		lastEnd[idx] = end
 
-	// First pass: preferred types only (if any)
-	if hasPrefer {
-		for _, al := range d.aliases {
-			if _, ok := preferSet[al.typ]; ok {
-				consider(al)
-			}
+		absStart, absEnd := ls+start, ls+end
+		if !d.boundaryOK(s, absStart, absEnd) {
+			return true
 		}
-		// If we found a preferred target, return early
-		if nearest.ok {
-			return true, nearest.typ, nearest.id, nearest.name, nearest.start, nearest.end, nearest.dist
+		c := candidate{ok: true, idx: idx, start: absStart, end: absEnd, dist: abs(center - absStart)}
+		if better(c, nearest) {
+			nearest = c
 		}
-	}
-
-	// Second pass: any type
-	for _, al := range d.aliases {
-		if hasPrefer {
-			if _, ok := preferSet[al.typ]; ok {
-				continue // already scanned these in the first pass
-			}
+		if len(prefer) > 0 && slices.Contains(prefer, al.typ) && better(c, nearestPref) {
+			nearestPref = c
 		}
-		consider(al)
-	}
+		return true
+	})
 
+	// A preferred type wins over any closer non-preferred target
+	if nearestPref.ok {
+		nearest = nearestPref
+	}
 	if !nearest.ok {
 		return false, "", "", "", 0, 0, 0
 	}
-	return true, nearest.typ, nearest.id, nearest.name, nearest.start, nearest.end, nearest.dist
+	al := aliases[nearest.idx]
+	return true, al.typ, al.id, al.name, nearest.start, nearest.end, nearest.dist
 }
 
 func zoneTagsForSpan(zs []normalize.ZoneSpan, start, end int) []string {
 	if len(zs) == 0 {
 		return nil
 	}
-	var inFence, inInline, inQuote bool
+	var inFence, inInline, inQuote, inURL, inDiff bool
 	for _, z := range zs {
 		if end <= z.Start || start >= z.End {
 			continue
@@ -493,13 +1015,17 @@ func zoneTagsForSpan(zs []normalize.ZoneSpan, start, end int) []string {
 			inInline = true
 		case normalize.ZoneQuote:
 			inQuote = true
+		case normalize.ZoneURL:
+			inURL = true
+		case normalize.ZoneDiff:
+			inDiff = true
 		}
 	}
 	// Pack without allocating a map
-	if !(inFence || inInline || inQuote) {
+	if !(inFence || inInline || inQuote || inURL || inDiff) {
 		return nil
 	}
-	out := make([]string, 0, 3)
+	out := make([]string, 0, 5)
 	if inFence {
 		out = append(out, string(normalize.ZoneCodeFence))
 	}
@@ -509,6 +1035,12 @@ func zoneTagsForSpan(zs []normalize.ZoneSpan, start, end int) []string {
 	if inQuote {
 		out = append(out, string(normalize.ZoneQuote))
 	}
+	if inURL {
+		out = append(out, string(normalize.ZoneURL))
+	}
+	if inDiff {
+		out = append(out, string(normalize.ZoneDiff))
+	}
 	return out
 }
 
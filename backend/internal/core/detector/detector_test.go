@@ -0,0 +1,549 @@
+package detector
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"swearjar/internal/core/normalize"
+	"swearjar/internal/core/rulepack"
+)
+
+// testPack builds a tiny pack where templates and lemmas overlap on purpose
+func testPack() *rulepack.Pack {
+	tpls := []rulepack.Template{
+		{PatternExpanded: `fucking build`, Category: "tooling_rage", Severity: 2},
+		{PatternExpanded: `shit show`, Category: "generic", Severity: 1},
+	}
+	p := &rulepack.Pack{
+		Templates: tpls,
+		Lemmas: []rulepack.Lemma{
+			{Term: "fucking", Category: "generic", Severity: 2},
+			{Term: "shit", Category: "generic", Severity: 3},
+			{Term: "damn", Category: "generic", Severity: 1},
+		},
+	}
+	for _, t := range tpls {
+		p.Compiled = append(p.Compiled, regexp.MustCompile(t.PatternExpanded))
+	}
+	return p
+}
+
+func TestScanWithoutCollapseKeepsOverlaps(t *testing.T) {
+	d := New(testPack(), 1)
+	hits := d.Scan("this fucking build is a shit show, damn")
+	if len(hits) != 5 {
+		t.Fatalf("got %d hits, want 5: %+v", len(hits), hits)
+	}
+}
+
+func TestCollapseOverlapping(t *testing.T) {
+	d := NewWithOptions(testPack(), 1, Options{CollapseOverlapping: true})
+	hits := d.Scan("this fucking build is a shit show, damn")
+
+	want := []struct {
+		term   string
+		source Source
+		sev    int
+	}{
+		{"fucking build", SourceTemplate, 2}, // tie with lemma "fucking": template wins
+		{"shit", SourceLemma, 3},             // lemma outranks the milder template
+		{"damn", SourceLemma, 1},             // no overlap, kept as is
+	}
+	if len(hits) != len(want) {
+		t.Fatalf("got %d hits, want %d: %+v", len(hits), len(want), hits)
+	}
+	for i, w := range want {
+		h := hits[i]
+		if h.Term != w.term || h.Source != w.source || h.Severity != w.sev {
+			t.Fatalf("hit %d = {%q %s %d}, want {%q %s %d}", i, h.Term, h.Source, h.Severity, w.term, w.source, w.sev)
+		}
+	}
+}
+
+func TestCollapseOverlappingChainsRegions(t *testing.T) {
+	// a overlaps b, b overlaps c, a does not touch c: all three form one region
+	hits := []Hit{
+		{Term: "a", Severity: 1, Source: SourceLemma, Spans: [][2]int{{0, 4}}},
+		{Term: "c", Severity: 3, Source: SourceLemma, Spans: [][2]int{{6, 10}}},
+		{Term: "b", Severity: 2, Source: SourceTemplate, Spans: [][2]int{{3, 7}}},
+	}
+	got := collapseOverlapping(hits)
+	if len(got) != 1 || got[0].Term != "c" {
+		t.Fatalf("want single hit c, got %+v", got)
+	}
+
+	// adjacent [start,end) spans do not overlap
+	got = collapseOverlapping([]Hit{
+		{Term: "x", Severity: 1, Spans: [][2]int{{0, 3}}},
+		{Term: "y", Severity: 1, Spans: [][2]int{{3, 6}}},
+	})
+	if len(got) != 2 {
+		t.Fatalf("adjacent spans collapsed: %+v", got)
+	}
+}
+
+func TestCollapseAdjacentMergesClusteredHits(t *testing.T) {
+	d := NewWithOptions(testPack(), 1, Options{CollapseAdjacent: 3})
+	hits := d.Scan("damn shit damn, and much later on: damn")
+
+	if len(hits) != 2 {
+		t.Fatalf("got %d hits, want 2: %+v", len(hits), hits)
+	}
+	rant, lone := hits[0], hits[1]
+	if rant.Term != "shit" || rant.Severity != 3 || rant.Merged != 3 {
+		t.Fatalf("rant = {%q sev %d merged %d}, want {shit 3 3}", rant.Term, rant.Severity, rant.Merged)
+	}
+	want := [][2]int{{0, 4}, {5, 9}, {10, 14}}
+	if !slices.Equal(rant.Spans, want) {
+		t.Fatalf("rant spans = %v, want %v", rant.Spans, want)
+	}
+	if lone.Term != "damn" || lone.Merged != 0 || len(lone.Spans) != 1 {
+		t.Fatalf("lone hit = %+v, want unmerged damn", lone)
+	}
+}
+
+func TestCollapseAdjacentLeavesSpreadHits(t *testing.T) {
+	text := "damn, this is fine. shit, that was close. damn"
+	plain := New(testPack(), 1).Scan(text)
+	hits := NewWithOptions(testPack(), 1, Options{CollapseAdjacent: 3}).Scan(text)
+	if len(hits) != 3 || len(hits) != len(plain) {
+		t.Fatalf("got %d hits, want 3 (plain %d): %+v", len(hits), len(plain), hits)
+	}
+	for i, h := range hits {
+		if h.Merged != 0 || h.Term != plain[i].Term {
+			t.Fatalf("hit %d = %+v, want %q unmerged", i, h, plain[i].Term)
+		}
+	}
+}
+
+func TestCollapseAdjacentDistanceBoundary(t *testing.T) {
+	hits := []Hit{
+		{Term: "a", Severity: 1, Source: SourceLemma, Spans: [][2]int{{0, 4}}, Pre: "pa", Post: "qa"},
+		{Term: "b", Severity: 2, Source: SourceLemma, Spans: [][2]int{{7, 10}}, Pre: "pb", Post: "qb"},
+	}
+	// gap is 3 bytes: merged at distance 3, kept apart at 2
+	if got := collapseAdjacent(slices.Clone(hits), 2); len(got) != 2 {
+		t.Fatalf("distance 2 merged: %+v", got)
+	}
+	got := collapseAdjacent(slices.Clone(hits), 3)
+	if len(got) != 1 {
+		t.Fatalf("distance 3 did not merge: %+v", got)
+	}
+	h := got[0]
+	if h.Term != "b" || h.Severity != 2 || h.Merged != 2 || h.Pre != "pa" || h.Post != "qb" {
+		t.Fatalf("merged = %+v", h)
+	}
+}
+
+func TestURLZoneDampening(t *testing.T) {
+	d := NewWithOptions(testPack(), 1, Options{SeverityDeltaInURL: -2})
+	hits := d.Scan("shit, see example.com/shit")
+	if len(hits) != 2 {
+		t.Fatalf("got %d hits, want 2: %+v", len(hits), hits)
+	}
+	prose, url := hits[0], hits[1]
+	if prose.Severity != 3 || len(prose.Zones) != 0 {
+		t.Fatalf("prose hit = sev %d zones %v, want 3 and none", prose.Severity, prose.Zones)
+	}
+	if url.Severity != 1 || len(url.Zones) != 1 || url.Zones[0] != "url" {
+		t.Fatalf("url hit = sev %d zones %v, want 1 and [url]", url.Severity, url.Zones)
+	}
+}
+
+func TestDiffZoneDampening(t *testing.T) {
+	d := NewWithOptions(testPack(), 1, Options{SeverityDeltaInDiff: -2})
+	hits := d.Scan("fixed the shit\n@@ -1 +1 @@\n-// shit\n+// ok")
+	if len(hits) != 2 {
+		t.Fatalf("got %d hits, want 2: %+v", len(hits), hits)
+	}
+	prose, diff := hits[0], hits[1]
+	if prose.Severity != 3 || len(prose.Zones) != 0 {
+		t.Fatalf("prose hit = sev %d zones %v, want 3 and none", prose.Severity, prose.Zones)
+	}
+	if diff.Severity != 1 || len(diff.Zones) != 1 || diff.Zones[0] != "diff" {
+		t.Fatalf("diff hit = sev %d zones %v, want 1 and [diff]", diff.Severity, diff.Zones)
+	}
+}
+
+func TestZoneDampeningExemptCategories(t *testing.T) {
+	p := testPack()
+	p.Lemmas = append(p.Lemmas, rulepack.Lemma{Term: "slurword", Category: "harassment", Severity: 3})
+	opts := Options{SeverityDeltaInCodeFence: -2, ZoneDampeningExemptCategories: []string{"harassment"}}
+	hits := NewWithOptions(p, 1, opts).Scan("```\nslurword shit\n```")
+
+	sev := map[string]int{}
+	for _, h := range hits {
+		sev[h.Term] = h.Severity
+	}
+	if sev["slurword"] != 3 {
+		t.Fatalf("exempt category dampened: slurword severity %d, want 3", sev["slurword"])
+	}
+	if sev["shit"] != 1 {
+		t.Fatalf("generic profanity not dampened: shit severity %d, want 1", sev["shit"])
+	}
+}
+
+func TestMaxSeverityClamps(t *testing.T) {
+	p := testPack()
+	p.Lemmas = append(p.Lemmas, rulepack.Lemma{Term: "hellword", Category: "generic", Severity: 5})
+	text := "hellword here\n> shit"
+
+	sev := func(opts Options) map[string]int {
+		out := map[string]int{}
+		for _, h := range NewWithOptions(p, 1, opts).Scan(text) {
+			out[h.Term] = h.Severity
+		}
+		return out
+	}
+
+	if got := sev(Options{}); got["hellword"] != 5 {
+		t.Fatalf("no cap: hellword severity %d, want 5", got["hellword"])
+	}
+	got := sev(Options{MaxSeverity: 4, SeverityDeltaInQuote: 2})
+	if got["hellword"] != 4 {
+		t.Fatalf("cap: hellword severity %d, want 4", got["hellword"])
+	}
+	if got["shit"] != 4 {
+		t.Fatalf("boosted quote hit not capped: shit severity %d, want 4", got["shit"])
+	}
+	if got := sev(Options{MaxSeverity: 3, SeverityDeltaInQuote: -9}); got["shit"] != 1 {
+		t.Fatalf("floor: shit severity %d, want 1", got["shit"])
+	}
+}
+
+func TestScanWithSuppressedReportsStoplist(t *testing.T) {
+	p := testPack()
+	p.Stopset = map[string]struct{}{"damn": {}}
+	const text = "damn, this shit"
+
+	hits, suppressed := New(p, 1).ScanWithSuppressed(text, "")
+	if len(hits) != 1 || hits[0].Term != "shit" || suppressed != nil {
+		t.Fatalf("default mode: hits %+v suppressed %+v", hits, suppressed)
+	}
+
+	hits, suppressed = NewWithOptions(p, 1, Options{ReportSuppressed: true}).ScanWithSuppressed(text, "")
+	if len(hits) != 1 || hits[0].SuppressedBy != "" {
+		t.Fatalf("reporting changed kept hits: %+v", hits)
+	}
+	if len(suppressed) != 1 {
+		t.Fatalf("got %d suppressed, want 1: %+v", len(suppressed), suppressed)
+	}
+	s := suppressed[0]
+	if s.Term != "damn" || s.SuppressedBy != "damn" || s.Source != SourceLemma || s.Spans[0] != [2]int{0, 4} {
+		t.Fatalf("suppressed hit = %+v", s)
+	}
+}
+
+func TestScanLangScopesRules(t *testing.T) {
+	p := testPack()
+	p.Lemmas = append(p.Lemmas,
+		rulepack.Lemma{Term: "merda", Category: "generic", Severity: 2, Lang: "pt"},
+		rulepack.Lemma{Term: "mist", Category: "generic", Severity: 1, Lang: "de"},
+	)
+	for i := range p.Lemmas[:3] {
+		p.Lemmas[i].Lang = "en"
+	}
+	p.Templates[1].Lang = "" // "shit show" stays language-neutral
+	const text = "merda, shit show, mist"
+
+	terms := func(hs []Hit) string {
+		var out []string
+		for _, h := range hs {
+			out = append(out, h.Term)
+		}
+		return strings.Join(out, ",")
+	}
+
+	// unscoped: every rule fires whatever the lang
+	if got := terms(New(p, 1).ScanLang(text, "pt")); got != "shit show,merda,shit,mist" {
+		t.Fatalf("unscoped = %q", got)
+	}
+	d := NewWithOptions(p, 1, Options{LangScoped: true})
+	if got := terms(d.ScanLang(text, "pt-BR")); got != "shit show,merda" {
+		t.Fatalf("pt-BR = %q", got)
+	}
+	if got := terms(d.ScanLang(text, "")); got != "shit show,merda,shit,mist" {
+		t.Fatalf("unknown lang = %q", got)
+	}
+}
+
+func TestContextAroundSnapsToRuneBoundaries(t *testing.T) {
+	// "ü" and "ß" are 2 bytes, "€" is 3: a 3-byte window splits runes on both sides
+	s := "aü€shitß€b"
+	start := strings.Index(s, "shit")
+	end := start + len("shit")
+
+	for win := 1; win <= 6; win++ {
+		pre, post := contextAround(s, start, end, win)
+		if !utf8.ValidString(pre) || !utf8.ValidString(post) {
+			t.Fatalf("win %d: invalid UTF-8 pre=%q post=%q", win, pre, post)
+		}
+		if len(pre) > win || len(post) > win {
+			t.Fatalf("win %d: context exceeds window pre=%q post=%q", win, pre, post)
+		}
+	}
+
+	pre, post := contextAround(s, start, end, 4)
+	if pre != "€" || post != "ß" {
+		t.Fatalf("win 4: got pre=%q post=%q, want %q/%q", pre, post, "€", "ß")
+	}
+	pre, post = contextAround(s, start, end, 2)
+	if pre != "" || post != "ß" {
+		t.Fatalf("win 2: got pre=%q post=%q, want \"\"/%q", pre, post, "ß")
+	}
+}
+
+func TestScanContextIsValidUTF8(t *testing.T) {
+	d := NewWithOptions(testPack(), 1, Options{ContextWindow: 5})
+	hits := d.Scan("日本語 shit 日本語")
+	if len(hits) != 1 {
+		t.Fatalf("got %d hits, want 1: %+v", len(hits), hits)
+	}
+	if h := hits[0]; h.Pre != "語 " || h.Post != " 日" {
+		t.Fatalf("got pre=%q post=%q, want %q/%q", h.Pre, h.Post, "語 ", " 日")
+	}
+}
+
+func TestSkipQuoteZones(t *testing.T) {
+	text := "> you said shit\n>> and before that damn\n> > fucking build\nstill a shit show"
+	if hits := New(testPack(), 1).Scan(text); len(hits) != 6 {
+		t.Fatalf("default: got %d hits, want 6: %+v", len(hits), hits)
+	}
+	hits := NewWithOptions(testPack(), 1, Options{SkipQuoteZones: true}).Scan(text)
+	if len(hits) != 2 {
+		t.Fatalf("skip quotes: got %d hits, want 2: %+v", len(hits), hits)
+	}
+	reply := strings.LastIndex(text, "\n") + 1
+	for _, h := range hits {
+		if h.Spans[0][0] < reply {
+			t.Fatalf("hit %q at %d is inside the quoted lines", h.Term, h.Spans[0][0])
+		}
+	}
+}
+
+func TestSeverityDeltaQuotedReport(t *testing.T) {
+	p := testPack()
+	p.Severity = rulepack.SeverityScale{Bands: []rulepack.SeverityBand{
+		{Label: rulepack.SeverityMild, Min: 1, Max: 1},
+		{Label: rulepack.SeverityStrong, Min: 2, Max: 2},
+		{Label: rulepack.SeveritySlurMasked, Min: 3, Max: 3},
+	}}
+	p.ReportingCues = []string{"called me", "reported"}
+	p.Lemmas = append(p.Lemmas, rulepack.Lemma{Term: "slurword", Category: "harassment", Severity: 3})
+
+	sev := func(opts Options, text string) map[string]int {
+		out := map[string]int{}
+		for _, h := range NewWithOptions(p, 1, opts).Scan(text) {
+			out[h.Term] = h.Severity
+		}
+		return out
+	}
+	on := Options{SeverityDeltaQuotedReport: -1}
+
+	const report = "this user called me this:\n> you fucking slurword"
+	if got := sev(Options{}, report); got["slurword"] != 3 {
+		t.Fatalf("off: slurword severity %d, want 3", got["slurword"])
+	}
+	got := sev(on, report)
+	if got["slurword"] != 2 {
+		t.Fatalf("quoted report: slurword severity %d, want 2", got["slurword"])
+	}
+	if got["fucking"] != 2 {
+		t.Fatalf("strong hit lowered: fucking severity %d, want 2", got["fucking"])
+	}
+
+	cases := []struct {
+		name, text string
+	}{
+		{"no cue", "look at this:\n> you slurword"},
+		{"cue only quoted", "> he called me a slurword"},
+		{"cue inside a word", "unreported:\n> slurword"},
+		{"unquoted slur", "reported, and you are a slurword"},
+	}
+	for _, tc := range cases {
+		if got := sev(on, tc.text); got["slurword"] != 3 {
+			t.Fatalf("%s: slurword severity %d, want 3", tc.name, got["slurword"])
+		}
+	}
+
+	exempt := Options{SeverityDeltaQuotedReport: -1, ZoneDampeningExemptCategories: []string{"harassment"}}
+	if got := sev(exempt, report); got["slurword"] != 3 {
+		t.Fatalf("exempt category: slurword severity %d, want 3", got["slurword"])
+	}
+	both := Options{SeverityDeltaQuotedReport: -1, SeverityDeltaInQuote: -1}
+	if got := sev(both, report); got["slurword"] != 1 || got["fucking"] != 1 {
+		t.Fatalf("with quote dampening: %v, want slurword 1 and fucking 1", got)
+	}
+}
+
+func TestSortHitsOrdersByStartCategorySource(t *testing.T) {
+	text := "this fucking build is a shit show, damn"
+	hits := NewWithOptions(testPack(), 1, Options{SortHits: true}).Scan(text)
+
+	want := []struct {
+		term   string
+		source Source
+	}{
+		{"fucking", SourceLemma},          // start 5: generic < tooling_rage
+		{"fucking build", SourceTemplate}, // start 5: tooling_rage
+		{"shit", SourceLemma},             // start 24: generic lemma < generic template
+		{"shit show", SourceTemplate},
+		{"damn", SourceLemma},
+	}
+	if len(hits) != len(want) {
+		t.Fatalf("got %d hits, want %d: %+v", len(hits), len(want), hits)
+	}
+	for i, w := range want {
+		if hits[i].Term != w.term || hits[i].Source != w.source {
+			t.Fatalf("hit %d = {%q %s}, want {%q %s}", i, hits[i].Term, hits[i].Source, w.term, w.source)
+		}
+	}
+}
+
+func TestSortHitsIsIndependentOfInputOrder(t *testing.T) {
+	hits := []Hit{
+		{Term: "b", Category: "generic", Source: SourceTemplate, Spans: [][2]int{{0, 4}}},
+		{Term: "c", Category: "generic", Source: SourceLemma, Spans: [][2]int{{9, 12}}},
+		{Term: "a", Category: "generic", Source: SourceLemma, Spans: [][2]int{{0, 4}}},
+		{Term: "d", Category: "bot_rage", Source: SourceLemma, Spans: [][2]int{{0, 6}}},
+		{Term: "e", Category: "generic", Source: SourceLemma, Spans: [][2]int{{0, 2}}},
+	}
+	want := []string{"d", "e", "a", "b", "c"}
+
+	reversed := slices.Clone(hits)
+	slices.Reverse(reversed)
+	for _, in := range [][]Hit{hits, reversed} {
+		sortHits(in)
+		var got []string
+		for _, h := range in {
+			got = append(got, h.Term)
+		}
+		if !slices.Equal(got, want) {
+			t.Fatalf("order = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestShoutingBumpsAllCapsSpans(t *testing.T) {
+	n := normalize.New()
+	scan := func(text string, opts Options) Hit {
+		t.Helper()
+		norm, cased := n.NormalizeCased(text)
+		hits, _ := NewWithOptions(testPack(), 1, opts).ScanCased(norm, cased, "")
+		if len(hits) != 1 {
+			t.Fatalf("%q: got %d hits, want 1: %+v", text, len(hits), hits)
+		}
+		return hits[0]
+	}
+	opts := Options{SeverityDeltaShouting: 1}
+
+	if h := scan("well damn, again", opts); h.Shouting || h.Severity != 1 {
+		t.Fatalf("lowercase: got shouting=%v sev=%d, want false/1", h.Shouting, h.Severity)
+	}
+	if h := scan("well Damn, again", opts); h.Shouting || h.Severity != 1 {
+		t.Fatalf("title case: got shouting=%v sev=%d, want false/1", h.Shouting, h.Severity)
+	}
+	if h := scan("well DAMN, again", opts); !h.Shouting || h.Severity != 2 {
+		t.Fatalf("all caps: got shouting=%v sev=%d, want true/2", h.Shouting, h.Severity)
+	}
+	if h := scan("WELL SH1T", opts); !h.Shouting || h.Severity != 4 {
+		t.Fatalf("leet all caps: got shouting=%v sev=%d, want true/4", h.Shouting, h.Severity)
+	}
+	if h := scan("WELL SH1T", Options{SeverityDeltaShouting: 2, MaxSeverity: 4}); h.Severity != 4 {
+		t.Fatalf("clamp: got sev=%d, want 4", h.Severity)
+	}
+	if h := scan("well DAMN, again", Options{}); !h.Shouting || h.Severity != 1 {
+		t.Fatalf("delta off: got shouting=%v sev=%d, want true/1", h.Shouting, h.Severity)
+	}
+
+	// without the cased projection there is no signal
+	if hits := NewWithOptions(testPack(), 1, opts).Scan(n.Normalize("DAMN")); hits[0].Shouting || hits[0].Severity != 1 {
+		t.Fatalf("plain Scan: got %+v, want no shouting", hits[0])
+	}
+}
+
+func TestFoldHomoglyphsMatchesLookalikesWithSourceSpans(t *testing.T) {
+	text := "ок, the ѕhit show again" // Cyrillic ѕ; the Russian "ок" shifts offsets
+	if hits := New(testPack(), 1).Scan(text); len(hits) != 0 {
+		t.Fatalf("without folding: got %+v, want no hits", hits)
+	}
+
+	d := NewWithOptions(testPack(), 1, Options{FoldHomoglyphs: true, ContextWindow: 10})
+	hits := d.Scan(text)
+	if len(hits) != 2 {
+		t.Fatalf("got %+v, want the shit lemma and the shit show template", hits)
+	}
+	for _, h := range hits {
+		if got := text[h.Spans[0][0]:h.Spans[0][1]]; !strings.HasPrefix(got, "ѕhit") || h.Term[0] != 's' {
+			t.Fatalf("%s span covers %q (term %q), want the source text", h.Source, got, h.Term)
+		}
+		if h.Pre != "ок, the " {
+			t.Fatalf("%s pre = %q, want context cut from the source", h.Source, h.Pre)
+		}
+	}
+}
+
+func TestFoldHomoglyphsKeepsNativeWordsAndShouting(t *testing.T) {
+	d := NewWithOptions(testPack(), 1, Options{FoldHomoglyphs: true, SeverityDeltaShouting: 1})
+
+	// an all-Cyrillic word that folds to a lemma is real Cyrillic text, not an evasion
+	if hits := d.Scan("дамн ԁаmn"); len(hits) != 1 || hits[0].Spans[0][0] != len("дамн ") {
+		t.Fatalf("got %+v, want only the mixed-script damn", hits)
+	}
+
+	norm, cased := normalize.New().NormalizeCased("well DАMN") // Cyrillic А
+	hits, _ := d.ScanCased(norm, cased, "")
+	if len(hits) != 1 || !hits[0].Shouting || hits[0].Severity != 2 {
+		t.Fatalf("got %+v, want one shouting damn", hits)
+	}
+}
+
+func TestRuleIDs(t *testing.T) {
+	p := testPack()
+	p.Templates[0].ID = "en.tooling_rage.fucking_build"
+	p.Lemmas[1].ID = "en.lemma.shit"
+	text := "fucking build, shit"
+
+	for _, h := range NewWithOptions(p, 1, Options{}).Scan(text) {
+		if h.RuleID != "" {
+			t.Fatalf("RuleIDs off: hit %q carries rule %q", h.Term, h.RuleID)
+		}
+	}
+
+	got := map[string]string{}
+	for _, h := range NewWithOptions(p, 1, Options{RuleIDs: true}).Scan(text) {
+		got[string(h.Source)+":"+h.Term] = h.RuleID
+	}
+	want := map[string]string{
+		"template:fucking build": "en.tooling_rage.fucking_build",
+		"lemma:fucking":          "", // lemma without an ID
+		"lemma:shit":             "en.lemma.shit",
+	}
+	for k, id := range want {
+		if got[k] != id {
+			t.Fatalf("%s rule = %q, want %q (all: %v)", k, got[k], id, got)
+		}
+	}
+}
+
+func TestRuleIDsOnSuppressedAndMergedHits(t *testing.T) {
+	p := testPack()
+	p.Lemmas[1].ID = "en.lemma.shit"
+	p.Lemmas[2].ID = "en.lemma.damn"
+	p.Stopset = map[string]struct{}{"damn": {}}
+
+	d := NewWithOptions(p, 1, Options{RuleIDs: true, ReportSuppressed: true})
+	_, sup := d.ScanWithSuppressed("damn", "")
+	if len(sup) != 1 || sup[0].RuleID != "en.lemma.damn" {
+		t.Fatalf("suppressed = %+v, want the damn lemma's rule id", sup)
+	}
+	p.Stopset = nil
+
+	merged := NewWithOptions(p, 1, Options{RuleIDs: true, CollapseAdjacent: 4}).Scan("damn shit")
+	if len(merged) != 1 || merged[0].Merged != 2 || merged[0].RuleID != "en.lemma.shit" {
+		t.Fatalf("merged = %+v, want one rant hit carrying the best member's rule", merged)
+	}
+}
@@ -0,0 +1,71 @@
+package detector
+
+import (
+	"testing"
+	"unicode/utf8"
+
+	"swearjar/internal/core/rulepack"
+)
+
+// FuzzDetectorScan feeds arbitrary bytes through Scan with every index-arithmetic feature on
+// (zones, context windows, targeting, collapse) and checks hits stay inside the input.
+// Seeds live in testdata/fuzz/FuzzDetectorScan; run with: go test -fuzz=FuzzDetectorScan
+func FuzzDetectorScan(f *testing.F) {
+	p, err := rulepack.Load()
+	if err != nil {
+		f.Fatalf("Load(): %v", err)
+	}
+	const win = 24
+	dets := []*Detector{
+		NewWithOptions(p, 1, Options{
+			ContextWindow:             win,
+			SeverityDeltaInCodeFence:  -1,
+			SeverityDeltaInCodeInline: -1,
+			SeverityDeltaInQuote:      -1,
+			SeverityDeltaInURL:        -2,
+		}),
+		NewWithOptions(p, 1, Options{ContextWindow: win, AllowOverlapping: true, CollapseOverlapping: true}),
+		NewWithOptions(p, 1, Options{ContextWindow: 1, MaxTotalHits: 2}),
+	}
+
+	for _, s := range []string{
+		"",
+		"shit",
+		"dependabot is a piece of shit",
+		"```\nfuck this build\n```",
+		"> quoted damn\nsee https://example.com/shit?x=fuck",
+		"@dependabot `fucking` npm",
+		"\xff\xfeshit\xc3",
+		"śhit fu​ck",
+	} {
+		f.Add(s)
+	}
+
+	f.Fuzz(func(t *testing.T, in string) {
+		for di, d := range dets {
+			for _, h := range d.Scan(in) {
+				if len(h.Spans) == 0 {
+					t.Fatalf("det %d: hit %q has no spans", di, h.Term)
+				}
+				for _, sp := range h.Spans {
+					if sp[0] < 0 || sp[0] >= sp[1] || sp[1] > len(in) {
+						t.Fatalf("det %d: span %v out of bounds for len %d (term %q)", di, sp, len(in), h.Term)
+					}
+				}
+				if h.Severity < 1 {
+					t.Fatalf("det %d: hit %q severity %d < 1", di, h.Term, h.Severity)
+				}
+				if w := d.opts.ContextWindow; len(h.Pre) > w || len(h.Post) > w {
+					t.Fatalf("det %d: context %d/%d exceeds window %d", di, len(h.Pre), len(h.Post), w)
+				}
+				if utf8.ValidString(in) && (!utf8.ValidString(h.Pre) || !utf8.ValidString(h.Post)) {
+					t.Fatalf("det %d: context split a rune: pre=%q post=%q", di, h.Pre, h.Post)
+				}
+				if h.TargetType != "" &&
+					(h.TargetStart < 0 || h.TargetStart >= h.TargetEnd || h.TargetEnd > len(in)) {
+					t.Fatalf("det %d: target [%d,%d) out of bounds for len %d", di, h.TargetStart, h.TargetEnd, len(in))
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,130 @@
+package detector
+
+import (
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"swearjar/internal/core/normalize"
+	"swearjar/internal/core/rulepack"
+)
+
+// secondPerson are the normalized words that count as direct address
+var secondPerson = []string{"you", "your", "youre", "yours", "yourself", "yourselves", "u", "ur", "ya", "yall"}
+
+// contextSignalsMet reports whether the template's declared context requirement holds for the
+// match at [a,b), and if not, the first signal that failed. "Nearby" is ContextWindow bytes
+// either side of the match, or the whole utterance when no window is configured
+func (d *Detector) contextSignalsMet(
+	s string,
+	zones []normalize.ZoneSpan,
+	req rulepack.ContextRequirement,
+	a, b int,
+) (bool, string) {
+	holds := func(sig string) bool { return d.contextSignal(s, zones, sig, a, b) }
+	for _, sig := range req.All {
+		if !holds(sig) {
+			return false, sig
+		}
+	}
+	if len(req.Any) > 0 && !slices.ContainsFunc(req.Any, holds) {
+		return false, strings.Join(req.Any, "|")
+	}
+	return true, ""
+}
+
+// contextSignal evaluates one signal from the rulepack vocabulary; unknown names never hold
+func (d *Detector) contextSignal(s string, zones []normalize.ZoneSpan, sig string, a, b int) bool {
+	ls, rs := d.neighborhood(s, a, b)
+	switch sig {
+	case rulepack.SignalTarget:
+		return d.aliasIn(s, ls, rs)
+	case rulepack.SignalDirectAddress:
+		return hasWord(s[ls:rs], secondPerson)
+	case rulepack.SignalFrustration:
+		return d.frustrationNear(s, ls, rs, a, b)
+	case rulepack.SignalOutsideCode:
+		for _, z := range zoneTagsForSpan(zones, a, b) {
+			switch z {
+			case string(normalize.ZoneCodeFence), string(normalize.ZoneCodeInline), string(normalize.ZoneDiff):
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// neighborhood is the [ls,rs) region signals are looked for in, kept on rune boundaries
+func (d *Detector) neighborhood(s string, a, b int) (int, int) {
+	win := d.opts.ContextWindow
+	if win <= 0 {
+		return 0, len(s)
+	}
+	ls := max(a-win, 0)
+	for ls < a && !utf8.RuneStart(s[ls]) {
+		ls++
+	}
+	rs := min(b+win, len(s))
+	for rs > b && rs < len(s) && !utf8.RuneStart(s[rs]) {
+		rs--
+	}
+	return ls, rs
+}
+
+// aliasIn reports whether a bot/tool/lang/framework alias sits on word boundaries in s[ls:rs]
+func (d *Detector) aliasIn(s string, ls, rs int) bool {
+	if d.aliasAC == nil || len(d.aliases) == 0 {
+		return false
+	}
+	found := false
+	d.aliasAC.FindAll([]byte(s[ls:rs]), func(end, idx int) bool {
+		start := end - len(d.aliases[idx].name)
+		found = d.boundaryOK(s, ls+start, ls+end)
+		return !found
+	})
+	return found
+}
+
+// frustrationNear reports whether one of the pack's frustration terms occurs on word
+// boundaries in s[ls:rs] without overlapping the match [a,b) itself
+func (d *Detector) frustrationNear(s string, ls, rs, a, b int) bool {
+	for _, term := range d.p.FrustrationTerms {
+		for off := ls; off < rs; {
+			i := strings.Index(s[off:rs], term)
+			if i < 0 {
+				break
+			}
+			start, end := off+i, off+i+len(term)
+			if (end <= a || start >= b) && d.boundaryOK(s, start, end) {
+				return true
+			}
+			off = end
+		}
+	}
+	return false
+}
+
+// hasWord reports whether any whole word of s is in words
+func hasWord(s string, words []string) bool {
+	for i := 0; i < len(s); {
+		r, sz := utf8.DecodeRuneInString(s[i:])
+		if !isWord(r) {
+			i += sz
+			continue
+		}
+		j := i
+		for j < len(s) {
+			r, sz := utf8.DecodeRuneInString(s[j:])
+			if !isWord(r) {
+				break
+			}
+			j += sz
+		}
+		if slices.Contains(words, s[i:j]) {
+			return true
+		}
+		i = j
+	}
+	return false
+}
@@ -0,0 +1,92 @@
+package detector
+
+import (
+	"regexp"
+	"testing"
+
+	"swearjar/internal/core/rulepack"
+)
+
+// signalPack has one template carrying the given context requirement, plus a bot alias and
+// frustration terms for the signals to find
+func signalPack(req rulepack.ContextRequirement) *rulepack.Pack {
+	tpl := rulepack.Template{PatternExpanded: `is garbage`, Category: "generic", Severity: 2, Requires: req}
+	return &rulepack.Pack{
+		Templates:        []rulepack.Template{tpl},
+		Compiled:         []*regexp.Regexp{regexp.MustCompile(tpl.PatternExpanded)},
+		FrustrationTerms: []string{"wtf", "dumpster fire"},
+		SlotNameToRef: map[string]rulepack.SlotRef{
+			"dependabot":  {Type: "bot", ID: "dependabot"},
+			"@dependabot": {Type: "bot", ID: "dependabot"},
+		},
+	}
+}
+
+func TestRequireContextSignals(t *testing.T) {
+	all := func(sigs ...string) rulepack.ContextRequirement { return rulepack.ContextRequirement{All: sigs} }
+	cases := []struct {
+		name string
+		req  rulepack.ContextRequirement
+		text string
+		want bool
+	}{
+		{"target near", all(rulepack.SignalTarget), "@dependabot this pr is garbage", true},
+		{"target absent", all(rulepack.SignalTarget), "this pr is garbage", false},
+		{"target out of window", all(rulepack.SignalTarget),
+			"dependabot opened it. many many words later on, this one is garbage", false},
+		{"direct address", all(rulepack.SignalDirectAddress), "your patch is garbage", true},
+		{"no direct address", all(rulepack.SignalDirectAddress), "the patch is garbage", false},
+		{"address needs a whole word", all(rulepack.SignalDirectAddress), "youth league is garbage", false},
+		{"frustration", all(rulepack.SignalFrustration), "wtf this is garbage", true},
+		{"frustration phrase", all(rulepack.SignalFrustration), "a dumpster fire, it is garbage", true},
+		{"no frustration", all(rulepack.SignalFrustration), "honestly it is garbage", false},
+		{"outside code", all(rulepack.SignalOutsideCode), "it is garbage", true},
+		{"inside code", all(rulepack.SignalOutsideCode), "see `it is garbage`", false},
+		{"all must hold", all(rulepack.SignalDirectAddress, rulepack.SignalFrustration),
+			"you wrote this and it is garbage", false},
+		{"any may hold", rulepack.ContextRequirement{Any: []string{rulepack.SignalTarget, rulepack.SignalFrustration}},
+			"wtf it is garbage", true},
+		{"any needs one", rulepack.ContextRequirement{Any: []string{rulepack.SignalTarget, rulepack.SignalFrustration}},
+			"it is garbage", false},
+	}
+	for _, tc := range cases {
+		d := NewWithOptions(signalPack(tc.req), 1, Options{ContextWindow: 20, RequireContextSignals: true})
+		if got := len(d.Scan(tc.text)) == 1; got != tc.want {
+			t.Errorf("%s: hit emitted = %v, want %v (%q)", tc.name, got, tc.want, tc.text)
+		}
+	}
+}
+
+func TestRequireContextSignalsOffIgnoresRequirements(t *testing.T) {
+	p := signalPack(rulepack.ContextRequirement{All: []string{rulepack.SignalTarget}})
+	if hits := NewWithOptions(p, 1, Options{ContextWindow: 20}).Scan("it is garbage"); len(hits) != 1 {
+		t.Fatalf("option off: got %d hits, want the template hit regardless of its requirement", len(hits))
+	}
+}
+
+func TestRequireContextSignalsWholeTextWithoutWindow(t *testing.T) {
+	p := signalPack(rulepack.ContextRequirement{All: []string{rulepack.SignalTarget}})
+	d := NewWithOptions(p, 1, Options{RequireContextSignals: true})
+	text := "dependabot opened it. many many words later on, this one is garbage"
+	if hits := d.Scan(text); len(hits) != 1 {
+		t.Fatalf("no window: got %d hits, want the target anywhere in the text to count", len(hits))
+	}
+}
+
+func TestRequireContextSignalsFrustrationExcludesMatch(t *testing.T) {
+	p := signalPack(rulepack.ContextRequirement{All: []string{rulepack.SignalFrustration}})
+	p.FrustrationTerms = append(p.FrustrationTerms, "garbage")
+	d := NewWithOptions(p, 1, Options{ContextWindow: 20, RequireContextSignals: true})
+	if hits := d.Scan("it is garbage"); len(hits) != 0 {
+		t.Fatalf("got %+v, want the match not to satisfy its own frustration requirement", hits)
+	}
+}
+
+func TestRequireContextSignalsReportsSuppressed(t *testing.T) {
+	p := signalPack(rulepack.ContextRequirement{All: []string{rulepack.SignalTarget}})
+	d := NewWithOptions(p, 1, Options{ContextWindow: 20, RequireContextSignals: true, ReportSuppressed: true})
+	hits, sup := d.ScanWithSuppressed("it is garbage", "")
+	if len(hits) != 0 || len(sup) != 1 || sup[0].SuppressedBy != "context:target" {
+		t.Fatalf("hits %+v suppressed %+v, want the hit suppressed by \"context:target\"", hits, sup)
+	}
+}
@@ -0,0 +1,231 @@
+package detector
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"testing"
+
+	"swearjar/internal/core/normalize"
+	"swearjar/internal/core/rulepack"
+)
+
+// naiveNearbyTarget is the previous per-alias strings.Index scan, kept as the reference
+func naiveNearbyTarget(d *Detector, s string, a, b int, prefer ...slotType) (bool, slotType, string, int, int) {
+	ls := max(a-d.opts.ContextWindow, 0)
+	rs := min(b+d.opts.ContextWindow, len(s))
+	region := s[ls:rs]
+	center := (a + b) / 2
+
+	found := false
+	var best struct {
+		typ        slotType
+		name       string
+		start, end int
+		dist       int
+	}
+	consider := func(al aliasEntry) {
+		pos := strings.Index(region, al.name)
+		for pos >= 0 {
+			st, en := ls+pos, ls+pos+len(al.name)
+			if d.boundaryOK(s, st, en) {
+				dist := abs(center - st)
+				if !found || dist < best.dist || (dist == best.dist && st < best.start) {
+					found = true
+					best.typ, best.name, best.start, best.end, best.dist = al.typ, al.name, st, en, dist
+				}
+			}
+			next := strings.Index(region[pos+len(al.name):], al.name)
+			if next < 0 {
+				break
+			}
+			pos += len(al.name) + next
+		}
+	}
+	isPref := func(t slotType) bool {
+		for _, p := range prefer {
+			if p == t {
+				return true
+			}
+		}
+		return false
+	}
+	for _, al := range d.aliases {
+		if len(prefer) > 0 && isPref(al.typ) {
+			consider(al)
+		}
+	}
+	if found {
+		return true, best.typ, best.name, best.start, best.end
+	}
+	for _, al := range d.aliases {
+		if len(prefer) == 0 || !isPref(al.typ) {
+			consider(al)
+		}
+	}
+	return found, best.typ, best.name, best.start, best.end
+}
+
+// aliasPack adds n synthetic aliases across all slot types (plus "@" forms) to testPack
+func aliasPack(n int) *rulepack.Pack {
+	p := testPack()
+	p.SlotNameToRef = make(map[string]rulepack.SlotRef, 2*n+4)
+	types := []string{"bot", "tool", "lang", "framework"}
+	for i := range n {
+		ref := rulepack.SlotRef{Type: types[i%len(types)], ID: fmt.Sprintf("alias%d", i)}
+		name := fmt.Sprintf("tool%dx", i)
+		p.SlotNameToRef[name] = ref
+		p.SlotNameToRef["@"+name] = ref
+	}
+	// overlapping names: prefixes, repeats and shared starts
+	p.SlotNameToRef["npm"] = rulepack.SlotRef{Type: "tool", ID: "npm"}
+	p.SlotNameToRef["npm-cli"] = rulepack.SlotRef{Type: "tool", ID: "npm"}
+	p.SlotNameToRef["go"] = rulepack.SlotRef{Type: "lang", ID: "go"}
+	p.SlotNameToRef["dependabot"] = rulepack.SlotRef{Type: "bot", ID: "dependabot"}
+	return p
+}
+
+func randomDoc(r *rand.Rand, words []string, n int) string {
+	var sb strings.Builder
+	for i := range n {
+		if i > 0 {
+			sb.WriteString([]string{" ", "  ", "-", ", ", "\n", "@"}[r.IntN(6)])
+		}
+		sb.WriteString(words[r.IntN(len(words))])
+	}
+	return sb.String()
+}
+
+func TestScanNearbyTargetMatchesNaive(t *testing.T) {
+	d := NewWithOptions(aliasPack(200), 1, Options{ContextWindow: 40})
+	words := []string{
+		"shit", "damn", "fucking", "build", "npm", "npm-cli", "go", "gogo", "dependabot",
+		"tool7x", "@tool8x", "tool12x", "tool199x", "toolx", "日本", "ok",
+	}
+	r := rand.New(rand.NewPCG(1, 2))
+	prefers := [][]slotType{nil, {slotBot}, {slotTool}, {slotLang, slotFramework}}
+
+	checked := 0
+	for range 2000 {
+		doc := randomDoc(r, words, 4+r.IntN(20))
+		for _, m := range []string{"shit", "damn", "fucking"} {
+			a := strings.Index(doc, m)
+			if a < 0 {
+				continue
+			}
+			b := a + len(m)
+			for _, pref := range prefers {
+				ok, typ, _, name, ts, te, _ := d.scanNearbyTarget(doc, a, b, pref...)
+				wok, wtyp, wname, wts, wte := naiveNearbyTarget(d, doc, a, b, pref...)
+				if ok != wok || typ != wtyp || name != wname || ts != wts || te != wte {
+					t.Fatalf("doc %q hit [%d,%d) prefer %v:\n got  %v %s %q [%d,%d)\n want %v %s %q [%d,%d)",
+						doc, a, b, pref, ok, typ, name, ts, te, wok, wtyp, wname, wts, wte)
+				}
+				checked++
+			}
+		}
+	}
+	if checked == 0 {
+		t.Fatal("no cases checked")
+	}
+}
+
+func BenchmarkScanNearbyTarget(b *testing.B) {
+	d := NewWithOptions(aliasPack(5000), 1, Options{ContextWindow: 80})
+	doc := strings.Repeat("the build is broken again and ", 4) + "shit, ask @tool4242x or npm " +
+		strings.Repeat("while the ci keeps failing ", 4)
+	a := strings.Index(doc, "shit")
+	end := a + len("shit")
+
+	b.Run("automaton", func(b *testing.B) {
+		for b.Loop() {
+			d.scanNearbyTarget(doc, a, end, slotTool)
+		}
+	})
+	b.Run("naive", func(b *testing.B) {
+		for b.Loop() {
+			naiveNearbyTarget(d, doc, a, end, slotTool)
+		}
+	})
+}
+
+// selfPack is testPack with a bot alias, the first-person lexicon and "fuck"
+func selfPack() *rulepack.Pack {
+	p := testPack()
+	p.Lemmas = append(p.Lemmas, rulepack.Lemma{Term: "fuck", Category: "generic", Severity: 3})
+	p.SlotNameToRef = map[string]rulepack.SlotRef{
+		"dependabot":  {Type: "bot", ID: "dependabot"},
+		"@dependabot": {Type: "bot", ID: "dependabot"},
+	}
+	for _, nm := range []string{"i", "i'm", "me", "my", "myself"} {
+		p.SlotNameToRef[nm] = rulepack.SlotRef{Type: "self", ID: "first_person"}
+	}
+	return p
+}
+
+func TestSelfDirectedTargeting(t *testing.T) {
+	scan := func(mode, text string) []Hit {
+		return NewWithOptions(selfPack(), 1, Options{ContextWindow: 40, SelfDirected: mode}).Scan(text)
+	}
+
+	self := scan(SelfTag, "my code is broken as fuck")
+	if len(self) != 1 || self[0].TargetType != "self" || self[0].TargetName != "my" || self[0].Severity != 3 {
+		t.Fatalf("self-directed = %+v, want one hit targeting self via \"my\"", self)
+	}
+
+	bot := scan(SelfTag, "@dependabot is fucking broken")
+	if len(bot) != 1 || bot[0].TargetType != "bot" || bot[0].TargetID != "dependabot" {
+		t.Fatalf("bot-directed = %+v, want one hit targeting dependabot", bot)
+	}
+
+	// a concrete target wins even when the self-reference is closer
+	mixed := scan(SelfTag, "@dependabot broke my fucking build")
+	if len(mixed) == 0 || mixed[0].TargetType != "bot" {
+		t.Fatalf("mixed = %+v, want the bot target to win", mixed)
+	}
+
+	if off := scan("", "my code is broken as fuck"); len(off) != 1 || off[0].TargetType != "" {
+		t.Fatalf("self targeting off = %+v, want an untargeted hit", off)
+	}
+	if down := scan(SelfDowngrade, "my code is broken as fuck"); len(down) != 1 || down[0].Severity != 2 {
+		t.Fatalf("downgrade = %+v, want severity 2", down)
+	}
+	if sup := scan(SelfSuppress, "my code is broken as fuck"); len(sup) != 0 {
+		t.Fatalf("suppress = %+v, want no hits", sup)
+	}
+	if sup := scan(SelfSuppress, "@dependabot is fucking broken"); len(sup) != 1 {
+		t.Fatalf("suppress must keep bot-directed hits, got %+v", sup)
+	}
+}
+
+func TestSelfDirectedIgnoresLeetFoldedI(t *testing.T) {
+	rp, err := rulepack.Load()
+	if err != nil {
+		t.Fatalf("load rulepack: %v", err)
+	}
+	d := NewWithOptions(rp, 1, Options{ContextWindow: 40, SelfDirected: SelfTag})
+	n := normalize.New()
+
+	for _, text := range []string{"shit, 1 test still fails", "this is shit !"} {
+		hits := d.Scan(n.Normalize(text))
+		if len(hits) == 0 {
+			t.Fatalf("%q: want a hit", text)
+		}
+		for _, h := range hits {
+			if h.TargetType == "self" {
+				t.Fatalf("%q: hit %+v targets self via a folded '1'/'!'", text, h)
+			}
+		}
+	}
+	if hits := d.Scan(n.Normalize("my day is shit")); len(hits) == 0 || hits[0].TargetType != "self" {
+		t.Fatalf("my day is shit = %+v, want a self-directed hit", hits)
+	}
+}
+
+func TestSelfSuppressedIsReported(t *testing.T) {
+	d := NewWithOptions(selfPack(), 1, Options{ContextWindow: 40, SelfDirected: SelfSuppress, ReportSuppressed: true})
+	hits, sup := d.ScanWithSuppressed("hate myself, fuck this", "")
+	if len(hits) != 0 || len(sup) != 1 || sup[0].SuppressedBy != "myself" {
+		t.Fatalf("hits %+v suppressed %+v, want the hit suppressed by \"myself\"", hits, sup)
+	}
+}
@@ -0,0 +1,59 @@
+// Package hidscheme derives principal HIDs (hashed identifiers) from GitHub numeric IDs.
+// Every writer and reader of repo_hid/actor_hid must go through here so the derivation
+// cannot drift; changing it means adding a Scheme and migrating with swearjar-rehash
+package hidscheme
+
+import (
+	"crypto/sha256"
+	"strconv"
+)
+
+// Scheme identifies one HID derivation
+type Scheme int
+
+const (
+	// V1 is sha256("repo:"+id) for repos and sha256("actor:"+id) for actors; legacy events
+	// without IDs hash the canonical name/login in place of the ID
+	V1 Scheme = 1
+
+	// Current is the scheme used for all new writes
+	Current = V1
+)
+
+// Valid reports whether s is a known scheme
+func (s Scheme) Valid() bool { return s == V1 }
+
+// Repo returns the repo HID for a GitHub repo ID under s
+func (s Scheme) Repo(id int64) [32]byte { return s.derive("repo:", strconv.FormatInt(id, 10)) }
+
+// Actor returns the actor HID for a GitHub user ID under s
+func (s Scheme) Actor(id int64) [32]byte { return s.derive("actor:", strconv.FormatInt(id, 10)) }
+
+// RepoName returns the name-based repo HID for events without a repo ID
+// The caller canonicalizes the name ("owner/repo", lowercased)
+func (s Scheme) RepoName(canon string) [32]byte { return s.derive("repo:", canon) }
+
+// ActorLogin returns the login-based actor HID for events without an actor ID
+// The caller canonicalizes the login (trimmed, lowercased)
+func (s Scheme) ActorLogin(canon string) [32]byte { return s.derive("actor:", canon) }
+
+func (s Scheme) derive(prefix, key string) [32]byte {
+	switch s {
+	case V1:
+		return sha256.Sum256([]byte(prefix + key))
+	default:
+		panic("hidscheme: unknown scheme " + strconv.Itoa(int(s)))
+	}
+}
+
+// Repo returns the repo HID under the Current scheme
+func Repo(id int64) [32]byte { return Current.Repo(id) }
+
+// Actor returns the actor HID under the Current scheme
+func Actor(id int64) [32]byte { return Current.Actor(id) }
+
+// RepoName returns the name-based repo HID under the Current scheme
+func RepoName(canon string) [32]byte { return Current.RepoName(canon) }
+
+// ActorLogin returns the login-based actor HID under the Current scheme
+func ActorLogin(canon string) [32]byte { return Current.ActorLogin(canon) }
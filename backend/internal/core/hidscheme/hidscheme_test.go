@@ -0,0 +1,49 @@
+package hidscheme
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+// Pinned HIDs; any change here orphans every principal already stored
+func TestV1Pinned(t *testing.T) {
+	cases := []struct {
+		name string
+		got  [32]byte
+		want string
+	}{
+		{"repo 1", V1.Repo(1), "75dc1a71ad642a07b6729829dcce00396e185384a0029665f813eae65e580fd5"},
+		{"actor 1", V1.Actor(1), "fe60e3d3384ca02c193a3d158d3ed0b77a6f34a61e15e6441db6b64699a29099"},
+		{"repo 724712", V1.Repo(724712), "f8094c97697f87f957c02d2badb5fe6c80073ebd26a34835a00bfdcff9813f0a"},
+		{"actor 583231", V1.Actor(583231), "bc87225eb449b80e80e1f8cdf32aec833ebbbd5ddeac87fd311e9c843989d20f"},
+	}
+	for _, c := range cases {
+		if got := hex.EncodeToString(c.got[:]); got != c.want {
+			t.Fatalf("%s = %s, want %s", c.name, got, c.want)
+		}
+	}
+}
+
+func TestCurrentAndKinds(t *testing.T) {
+	if Repo(42) != Current.Repo(42) || Actor(42) != Current.Actor(42) {
+		t.Fatal("package helpers must use Current")
+	}
+	if V1.RepoName("1") != V1.Repo(1) || V1.ActorLogin("1") != V1.Actor(1) {
+		t.Fatal("name-based HIDs share the ID keyspace layout")
+	}
+	if Repo(42) == Actor(42) {
+		t.Fatal("repo and actor HIDs must not collide for the same numeric ID")
+	}
+	if !Current.Valid() || Scheme(0).Valid() {
+		t.Fatal("Valid")
+	}
+}
+
+func TestUnknownSchemePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic")
+		}
+	}()
+	Scheme(99).Repo(1)
+}
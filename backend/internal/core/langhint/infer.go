@@ -0,0 +1,100 @@
+package langhint
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Inference thresholds: Latin text needs this many words and stopword hits, with the winner
+// scoring at least twice the runner-up, before its guess counts as reliable
+const (
+	reliableWords     = 5
+	reliableStopwords = 3
+)
+
+// scriptLangs maps scripts that (nearly) decide the language on their own
+var scriptLangs = map[string]string{
+	"Hiragana": "ja",
+	"Katakana": "ja",
+	"Hangul":   "ko",
+	"Arabic":   "ar",
+	"Hebrew":   "he",
+	"Thai":     "th",
+	"Greek":    "el",
+}
+
+// stopwords are frequent function words per Latin-script language, chosen to overlap little
+var stopwords = map[string]map[string]bool{
+	"en": set("the", "and", "is", "are", "was", "were", "this", "that", "with", "for", "not", "it", "you",
+		"have", "has", "be", "but", "of", "to", "my", "why", "what", "just", "does", "doesn't", "don't"),
+	"es": set("el", "los", "las", "que", "y", "por", "para", "con", "una", "es", "pero", "esto", "como",
+		"del", "muy", "está", "no"),
+	"fr": set("le", "les", "des", "et", "est", "une", "pour", "pas", "dans", "avec", "ce", "je", "sur",
+		"qui", "du", "c'est", "mais"),
+	"de": set("der", "die", "das", "und", "ist", "nicht", "ein", "eine", "mit", "für", "auf", "ich", "zu",
+		"den", "dem", "auch", "wird"),
+	"pt": set("o", "os", "um", "uma", "não", "com", "é", "isso", "mas", "do", "da", "em", "você", "está",
+		"ao"),
+	"it": set("il", "di", "che", "non", "è", "per", "sono", "questo", "della", "gli", "ma", "anche", "mi",
+		"lo", "perché"),
+	"nl": set("het", "een", "van", "niet", "op", "voor", "zijn", "maar", "ook", "dit", "deze", "ik", "je",
+		"wel", "er"),
+}
+
+func set(ws ...string) map[string]bool {
+	m := make(map[string]bool, len(ws))
+	for _, w := range ws {
+		m[w] = true
+	}
+	return m
+}
+
+// Detector is the built-in lightweight language guesser; see Infer
+type Detector struct{}
+
+// DetectLang implements the detect service's language fallback with Infer
+func (Detector) DetectLang(text string) (string, bool) { return Infer(text) }
+
+// Infer guesses a BCP-47 base language for normalized text. Scripts that decide the language
+// (kana, Hangul, Arabic, ...) answer directly; Latin text is scored against small stopword
+// lists. reliable is false for short or ambiguous text, where lang is at most a best guess;
+// lang is "" when there is nothing to go on (no letters, a tie, Cyrillic or Han script)
+func Infer(s string) (lang string, reliable bool) {
+	script, strong := DetectScriptAndLang(s)
+	if strong != "" {
+		return strong, true
+	}
+	if l, ok := scriptLangs[script]; ok {
+		return l, false // decisive script, but under DetectScriptAndLang's letter floor
+	}
+	if script != "Latin" {
+		return "", false
+	}
+
+	words := strings.FieldsFunc(s, func(r rune) bool { return !unicode.IsLetter(r) && r != '\'' })
+	scores := make(map[string]int, len(stopwords))
+	for _, w := range words {
+		for l, sw := range stopwords {
+			if sw[w] {
+				scores[l]++
+			}
+		}
+	}
+
+	best, second := 0, 0
+	for _, l := range slices.Sorted(maps.Keys(scores)) {
+		switch n := scores[l]; {
+		case n > best:
+			second, best, lang = best, n, l
+		case n > second:
+			second = n
+		}
+	}
+	if best == 0 || best == second {
+		return "", false
+	}
+	reliable = len(words) >= reliableWords && best >= reliableStopwords && best >= 2*second
+	return lang, reliable
+}
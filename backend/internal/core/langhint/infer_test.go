@@ -0,0 +1,44 @@
+package langhint
+
+import "testing"
+
+func TestInfer(t *testing.T) {
+	cases := []struct {
+		name     string
+		in       string
+		lang     string
+		reliable bool
+	}{
+		{"english sentence", "why the hell is this build not working with the new config", "en", true},
+		{"german sentence", "das ist nicht mein fehler und der build ist auch kaputt", "de", true},
+		{"spanish sentence", "esto no funciona y el build está roto para los usuarios", "es", true},
+		{"french sentence", "le build est cassé et je ne sais pas pourquoi dans les tests", "fr", true},
+		{"short english", "fix the build", "en", false},
+		{"no stopwords", "wip", "", false},
+		{"tie", "die the", "", false},
+		{"korean long", "이것은 정말 짜증나는 버그입니다 " +
+			"왜 이렇게 안되는지 모르겠어요", "ko", true},
+		{"japanese short", "バグ", "ja", false},
+		{"cyrillic stays unknown", "это просто ошибка в коде и она бесит меня уже неделю", "", false},
+		{"empty", "", "", false},
+		{"digits only", "123 456", "", false},
+	}
+	for _, c := range cases {
+		lang, reliable := Infer(c.in)
+		if lang != c.lang || reliable != c.reliable {
+			t.Fatalf("%s: Infer(%q) = (%q, %v), want (%q, %v)",
+				c.name, c.in, lang, reliable, c.lang, c.reliable)
+		}
+	}
+}
+
+func TestInferMixedSignalsAreUnreliable(t *testing.T) {
+	// english wins on count but the dutch words keep it under the 2x margin
+	lang, reliable := Infer("het is the een van the and niet the")
+	if lang != "en" && lang != "nl" {
+		t.Fatalf("lang = %q, want a best guess", lang)
+	}
+	if reliable {
+		t.Fatalf("mixed stopwords reported reliable (%q)", lang)
+	}
+}
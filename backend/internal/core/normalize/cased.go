@@ -0,0 +1,71 @@
+package normalize
+
+import (
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+	"golang.org/x/text/width"
+)
+
+// casedPool mirrors chainPool without case folding
+var casedPool = sync.Pool{
+	New: func() any {
+		return transform.Chain(
+			norm.NFKC,
+			runes.Remove(runes.In(unicode.Mn)),
+			runes.Remove(runes.In(unicode.Cf)),
+			width.Fold,
+		)
+	},
+}
+
+// NormalizeCased returns Normalize(s) plus a case-preserving projection of it: the same
+// pipeline minus case and leet folding, so cased[i:j] is the original-case text behind
+// norm[i:j] and detector spans index both. When folding changes a rune's width (e.g. "ẞ"
+// folds to "ss") the two can't line up and cased is empty; callers treat that as no signal
+func (n *Normalizer) NormalizeCased(s string) (normalized, cased string) {
+	normalized = n.Normalize(s)
+	if normalized == "" {
+		return "", ""
+	}
+
+	s = strings.ToValidUTF8(Sanitize(s), "")
+	tr := casedPool.Get().(transform.Transformer)
+	cs, _, _ := transform.String(tr, s)
+	tr.Reset()
+	casedPool.Put(tr)
+
+	cs = collapseSpaces(cs)
+	if n.opts.DropQuotes {
+		cs = StripQuotes(cs)
+	}
+	if n.opts.DropDiffs {
+		cs = StripDiffs(cs)
+	}
+	if !runeAligned(normalized, cs) {
+		return normalized, ""
+	}
+	return normalized, cs
+}
+
+// runeAligned reports whether a and b have the same runes at the same byte offsets, modulo
+// the rune values themselves
+func runeAligned(a, b string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := 0; i < len(a); {
+		_, na := utf8.DecodeRuneInString(a[i:])
+		_, nb := utf8.DecodeRuneInString(b[i:])
+		if na != nb {
+			return false
+		}
+		i += na
+	}
+	return true
+}
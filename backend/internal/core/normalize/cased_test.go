@@ -0,0 +1,38 @@
+package normalize
+
+import "testing"
+
+func TestNormalizeCasedAlignsWithNormalize(t *testing.T) {
+	cases := []struct {
+		in, norm, cased string
+	}{
+		{"WHY does  Webpack\tFAIL", "why does webpack fail", "WHY does Webpack FAIL"},
+		{"SH1T build", "shit build", "SH1T build"},
+		{"ＦＵＣＫ this", "fuck this", "FUCK this"},
+		{"  Café DAMN ", "café damn", "Café DAMN"},
+	}
+	for _, c := range cases {
+		norm, cased := New().NormalizeCased(c.in)
+		if norm != c.norm || cased != c.cased {
+			t.Fatalf("%q: got (%q, %q), want (%q, %q)", c.in, norm, cased, c.norm, c.cased)
+		}
+		if norm != New().Normalize(c.in) {
+			t.Fatalf("%q: NormalizeCased norm %q differs from Normalize", c.in, norm)
+		}
+	}
+}
+
+func TestNormalizeCasedDropsUnalignable(t *testing.T) {
+	// U+1E9E folds to "ss": offsets after it would drift, so no cased projection
+	norm, cased := New().NormalizeCased("STRAẞE DAMN")
+	if norm == "" || cased != "" {
+		t.Fatalf("got (%q, %q), want normalized text and empty cased", norm, cased)
+	}
+}
+
+func TestNormalizeCasedDropQuotes(t *testing.T) {
+	norm, cased := NewWithOptions(Options{DropQuotes: true}).NormalizeCased("> Quoted SHIT\nFINE by me")
+	if norm != "fine by me" || cased != "FINE by me" {
+		t.Fatalf("got (%q, %q)", norm, cased)
+	}
+}
@@ -0,0 +1,176 @@
+package normalize
+
+import "strings"
+
+// diffFileHeaders start the git file header lines that may sit right above a hunk
+var diffFileHeaders = []string{
+	"diff --git ", "index ", "--- ", "+++ ",
+	"new file mode ", "deleted file mode ", "old mode ", "new mode ",
+	"similarity index ", "rename from ", "rename to ",
+}
+
+// StripDiffs drops the unified-diff blocks DetectZones tags as ZoneDiff, whole lines at a
+// time. Remaining lines keep their order and edges are trimmed again, as in StripQuotes
+func StripDiffs(norm string) string {
+	zs := diffZones(norm)
+	if len(zs) == 0 {
+		return norm
+	}
+	var b strings.Builder
+	b.Grow(len(norm))
+	prev := 0
+	for _, z := range zs {
+		b.WriteString(norm[prev:z.Start])
+		prev = z.End
+		if prev < len(norm) && norm[prev] == '\n' {
+			prev++
+		}
+	}
+	b.WriteString(norm[prev:])
+	return strings.Trim(b.String(), " \n\t\r")
+}
+
+// diffZones tags unified-diff hunks pasted into text (squash-merge bodies often carry the
+// whole patch): a hunk header "@@ -12,7 +12,9 @@", the body lines its counts cover, and the
+// git file header lines (diff --git, index, ---/+++) right above it.
+// Normalization drops the leading space of context lines and blank lines, so the counts are
+// what bound a hunk: a '-'/'+' line past its side's count, or a context line past either,
+// ends it. Headers are matched leet-folded too ("aa -i2,t +i2,9 aa"), so the case-preserving
+// projection from NormalizeCased yields the same blocks
+func diffZones(s string) []ZoneSpan {
+	if !strings.Contains(s, "@@ -") && !strings.Contains(s, "aa -") {
+		return nil
+	}
+	lines := lineSpans(s)
+	text := func(i int) string { return s[lines[i][0]:lines[i][1]] }
+
+	var out []ZoneSpan
+	floor := 0 // first line a new block may claim
+	for i := 0; i < len(lines); i++ {
+		oldN, newN, ok := hunkHeader(text(i))
+		if !ok {
+			continue
+		}
+		first := i
+		for first > floor && isDiffFileHeader(text(first-1)) {
+			first--
+		}
+
+		last := i
+	body:
+		for j := i + 1; j < len(lines) && (oldN > 0 || newN > 0); j++ {
+			ln := text(j)
+			if _, _, next := hunkHeader(ln); next || strings.HasPrefix(ln, "diff --git ") {
+				break
+			}
+			switch {
+			case strings.HasPrefix(ln, `\`): // "\ No newline at end of file"
+			case strings.HasPrefix(ln, "-"):
+				if oldN == 0 {
+					break body
+				}
+				oldN--
+			case strings.HasPrefix(ln, "+"):
+				if newN == 0 {
+					break body
+				}
+				newN--
+			default:
+				if oldN == 0 || newN == 0 {
+					break body
+				}
+				oldN--
+				newN--
+			}
+			last = j
+		}
+		if last+1 < len(lines) && strings.HasPrefix(text(last+1), `\`) {
+			last++
+		}
+
+		out = append(out, ZoneSpan{Type: ZoneDiff, Start: lines[first][0], End: lines[last][1]})
+		i, floor = last, last+1
+	}
+	return out
+}
+
+// lineSpans returns the [start, end) of every line in s, newlines excluded
+func lineSpans(s string) [][2]int {
+	var out [][2]int
+	start := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\n' {
+			out = append(out, [2]int{start, i})
+			start = i + 1
+		}
+	}
+	return append(out, [2]int{start, len(s)})
+}
+
+func isDiffFileHeader(ln string) bool {
+	ln = strings.ToLower(ln)
+	for _, p := range diffFileHeaders {
+		if strings.HasPrefix(ln, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// hunkHeader parses "@@ -a[,b] +c[,d] @@[ section]" (or its leet-folded "aa ... aa" form) into
+// the old and new line counts; an omitted count is 1
+func hunkHeader(ln string) (oldN, newN int, ok bool) {
+	if !strings.HasPrefix(ln, "@@ -") && !strings.HasPrefix(ln, "aa -") {
+		return 0, 0, false
+	}
+	oldR, rest, ok := strings.Cut(ln[4:], " +")
+	if !ok {
+		return 0, 0, false
+	}
+	newR, tail, ok := strings.Cut(rest, " ")
+	if !ok {
+		return 0, 0, false
+	}
+	if tail != ln[:2] && !strings.HasPrefix(tail, ln[:2]+" ") {
+		return 0, 0, false
+	}
+	if oldN, ok = hunkRange(oldR); !ok {
+		return 0, 0, false
+	}
+	if newN, ok = hunkRange(newR); !ok {
+		return 0, 0, false
+	}
+	return oldN, newN, true
+}
+
+// hunkRange reads the line count of a "start[,count]" hunk range
+func hunkRange(r string) (int, bool) {
+	start, count, hasCount := strings.Cut(r, ",")
+	if _, ok := leetInt(start); !ok {
+		return 0, false
+	}
+	if !hasCount {
+		return 1, true
+	}
+	return leetInt(count)
+}
+
+// leetInt parses a decimal number whose digits may have been leet-folded (0->o 1->i 3->e
+// 4->a 5->s 7->t, see leetFold)
+func leetInt(s string) (int, bool) {
+	if s == "" || len(s) > 9 {
+		return 0, false
+	}
+	n := 0
+	for i := 0; i < len(s); i++ {
+		d := strings.IndexByte("oi2eas6t89", s[i])
+		if c := s[i]; c >= '0' && c <= '9' {
+			d = int(c - '0')
+		}
+		if d < 0 {
+			return 0, false
+		}
+		n = n*10 + d
+	}
+	return n, true
+}
@@ -0,0 +1,96 @@
+package normalize
+
+import "testing"
+
+// squashBody is a squash-merge commit body the way GitHub writes it when the PR description
+// pasted the patch: prose, a full git diff over two files, then more prose
+const squashBody = `Fix flaky retry in uploader (#412)
+
+* handle the damn timeout properly
+
+diff --git a/internal/upload/retry.go b/internal/upload/retry.go
+index 3b18e51..a9c2f4d 100644
+--- a/internal/upload/retry.go
++++ b/internal/upload/retry.go
+@@ -12,7 +12,8 @@ func (r *Retrier) Do(ctx context.Context) error {
+ 	for i := 0; i < r.max; i++ {
+-		// shit, this never backs off
+-		time.Sleep(r.base)
++		// back off exponentially
++		time.Sleep(r.base << i)
++		r.attempts++
+ 		if err := r.fn(ctx); err == nil {
+ 			return nil
+ 		}
+ 	}
+diff --git a/internal/upload/retry_test.go b/internal/upload/retry_test.go
+new file mode 100644
+--- /dev/null
++++ b/internal/upload/retry_test.go
+@@ -0,0 +1,3 @@
++package upload
++
++// TODO: wtf is the fake clock for
+\ No newline at end of file
+
+* bump the fucking timeout too
+Co-authored-by: Someone <someone@example.com>`
+
+func TestDiffZones_SquashMergeBody(t *testing.T) {
+	norm := New().Normalize(squashBody)
+
+	var diffs []string
+	for _, z := range DetectZones(norm) {
+		if z.Type == ZoneDiff {
+			diffs = append(diffs, norm[z.Start:z.End])
+		}
+	}
+	if len(diffs) != 2 {
+		t.Fatalf("got %d diff zones, want 2: %q", len(diffs), diffs)
+	}
+	first, second := diffs[0], diffs[1]
+	if first[:len("diff --git")] != "diff --git" || second[:len("diff --git")] != "diff --git" {
+		t.Fatalf("zones should start at the file headers: %q", diffs)
+	}
+	if want := "}"; first[len(first)-1:] != want {
+		t.Fatalf("first hunk should end on its last context line: %q", first)
+	}
+	if want := "\\ no newline at end of file"; second[len(second)-len(want):] != want {
+		t.Fatalf("second hunk should keep its no-newline marker: %q", second)
+	}
+
+	want := "fix flaky retry in uploader (#ai2)\n* handle the damn timeout properly\n" +
+		"* bump the fucking timeout too\nco-authored-by: someone <someoneaexample.com>"
+	if got := NewWithOptions(Options{DropDiffs: true}).Normalize(squashBody); got != want {
+		t.Fatalf("DropDiffs:\n got %q\nwant %q", got, want)
+	}
+}
+
+func TestStripDiffs(t *testing.T) {
+	cases := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"no diff", "this build is shit", "this build is shit"},
+		{"bare hunk", "before\n@@ -1 +1 @@\n-old crap\n+new crap\nafter", "before\nafter"},
+		{"counts bound prose", "@@ -1,2 +1,2 @@\nkeep\n-a\n+b\nprose after the damn hunk", "prose after the damn hunk"},
+		{"extra minus is prose", "@@ -1 +1,2 @@\n-a\n+b\n+c\n- bullet shit", "- bullet shit"},
+		{"leet folded header", "aa -i2,t +i2,9 aa\n-x", ""},
+		{"not a header", "@@ this is not a hunk @@\n-x", "@@ this is not a hunk @@\n-x"},
+		{"lone header lines kept", "--- a/x\n+++ b/x\nno hunk here", "--- a/x\n+++ b/x\nno hunk here"},
+	}
+	for _, tc := range cases {
+		if got := StripDiffs(tc.in); got != tc.want {
+			t.Fatalf("%s: got %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestNormalizeCased_DropDiffsStaysAligned(t *testing.T) {
+	n := NewWithOptions(Options{DropDiffs: true})
+	norm, cased := n.NormalizeCased(squashBody)
+	if len([]rune(norm)) != len([]rune(cased)) {
+		t.Fatalf("cased projection misaligned:\n norm %q\ncased %q", norm, cased)
+	}
+}
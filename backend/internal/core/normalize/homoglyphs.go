@@ -0,0 +1,115 @@
+package normalize
+
+import (
+	"maps"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// homoglyphs maps Cyrillic and Greek lowercase letters to the Latin letter they render as,
+// a curated subset of Unicode confusables.txt (UTS #39) limited to unambiguous lookalikes.
+// Upper case pairs are derived in init so a case-preserving projection folds in step
+var homoglyphs = map[rune]rune{
+	// Cyrillic
+	'а': 'a', 'е': 'e', 'о': 'o', 'р': 'p', 'с': 'c', 'у': 'y', 'х': 'x',
+	'ѕ': 's', 'і': 'i', 'ј': 'j', 'һ': 'h', 'ԁ': 'd', 'ԛ': 'q', 'ԝ': 'w', 'ӏ': 'l',
+	// Greek
+	'α': 'a', 'ο': 'o', 'ρ': 'p', 'ι': 'i', 'κ': 'k', 'ν': 'v', 'υ': 'u', 'χ': 'x', 'γ': 'y',
+}
+
+func init() {
+	for from, to := range maps.Clone(homoglyphs) {
+		if up := unicode.ToUpper(from); up != from {
+			homoglyphs[up] = unicode.ToUpper(to)
+		}
+	}
+}
+
+// Folded is a string with homoglyphs folded to Latin plus the offset map back to its source
+type Folded struct {
+	Text string
+	src  []int // src[i] is the source byte offset behind Text[i]; len(Text)+1 entries, nil if unchanged
+}
+
+// Changed reports whether any rune was folded (Text != source)
+func (f Folded) Changed() bool { return f.src != nil }
+
+// Source maps a byte offset in Text to the source string, so a span [a,b) over Text is
+// [Source(a),Source(b)) over the source. Offsets past the end clamp to it
+func (f Folded) Source(i int) int {
+	if f.src == nil {
+		return i
+	}
+	return f.src[min(max(i, 0), len(f.src)-1)]
+}
+
+// FoldHomoglyphs rewrites Cyrillic/Greek lookalikes ("fuсk" with a Cyrillic с) to Latin so
+// Latin lemmas match. Only mixed-script words are touched: a word made entirely of Cyrillic or
+// Greek letters is real text in that script and is left alone, as is anything without a Latin
+// letter. Folding shrinks each replaced rune to one byte; Source maps offsets back
+func FoldHomoglyphs(s string) Folded {
+	var (
+		b    strings.Builder
+		src  []int
+		done int // s[:done] is already in b
+	)
+	copyTo := func(end int) {
+		b.WriteString(s[done:end])
+		for k := done; k < end; k++ {
+			src = append(src, k)
+		}
+		done = end
+	}
+
+	for i := 0; i < len(s); {
+		r, n := utf8.DecodeRuneInString(s[i:])
+		if !unicode.IsLetter(r) {
+			i += n
+			continue
+		}
+
+		// one word: a run of letters (and combining marks)
+		j, latin, confusable := i, false, false
+		for j < len(s) {
+			r, n := utf8.DecodeRuneInString(s[j:])
+			if !unicode.IsLetter(r) && !unicode.Is(unicode.Mn, r) {
+				break
+			}
+			if _, ok := homoglyphs[r]; ok {
+				confusable = true
+			} else if unicode.Is(unicode.Latin, r) {
+				latin = true
+			}
+			j += n
+		}
+		if !latin || !confusable {
+			i = j
+			continue
+		}
+
+		if src == nil {
+			b.Grow(len(s))
+			src = make([]int, 0, len(s)+1)
+		}
+		copyTo(i)
+		for k := i; k < j; {
+			r, n := utf8.DecodeRuneInString(s[k:])
+			if to, ok := homoglyphs[r]; ok {
+				b.WriteRune(to)
+				src = append(src, k)
+				done = k + n
+			} else {
+				copyTo(k + n)
+			}
+			k += n
+		}
+		i = j
+	}
+
+	if src == nil {
+		return Folded{Text: s}
+	}
+	copyTo(len(s))
+	return Folded{Text: b.String(), src: append(src, len(s))}
+}
@@ -0,0 +1,61 @@
+package normalize
+
+import "testing"
+
+func TestFoldHomoglyphsMixedScriptWords(t *testing.T) {
+	cases := []struct{ in, want string }{
+		{"what the fuсk", "what the fuck"},   // Cyrillic с
+		{"ѕһit build", "shit build"},         // Cyrillic ѕ, һ
+		{"dаmn it", "damn it"},               // Cyrillic а
+		{"crαp code", "crap code"},           // Greek α
+		{"FUСK THIS", "FUCK THIS"},           // Cyrillic С, upper case folds in step
+		{"fuсk-this сука", "fuck-this сука"}, // the Russian word stays Cyrillic
+	}
+	for _, c := range cases {
+		f := FoldHomoglyphs(c.in)
+		if f.Text != c.want || !f.Changed() {
+			t.Fatalf("%q: got %q (changed=%v), want %q", c.in, f.Text, f.Changed(), c.want)
+		}
+	}
+}
+
+func TestFoldHomoglyphsLeavesNativeScriptsAlone(t *testing.T) {
+	for _, in := range []string{
+		"это просто ошибка в коде", // Russian: every word is Cyrillic, many letters are lookalikes
+		"сор рус осо",              // all-lookalike Cyrillic words still aren't mixed script
+		"καλό πρωί",                // Greek
+		"plain ascii text",
+		"café déjà vu", // Latin with diacritics, nothing confusable
+		"build 42: ок", // digits and punctuation don't make a word mixed
+	} {
+		f := FoldHomoglyphs(in)
+		if f.Changed() || f.Text != in {
+			t.Fatalf("%q: folded to %q", in, f.Text)
+		}
+		if f.Source(3) != 3 {
+			t.Fatalf("%q: unchanged text should map offsets to themselves", in)
+		}
+	}
+}
+
+func TestFoldHomoglyphsSourceOffsets(t *testing.T) {
+	in := "ок, fuсk this" // "ок" is 4 bytes, the Cyrillic с inside fuсk is 2
+	f := FoldHomoglyphs(in)
+	if f.Text != "ок, fuck this" {
+		t.Fatalf("got %q", f.Text)
+	}
+
+	// the span of "fuck" in the folded text maps back onto "fuсk" in the source
+	a := len("ок, ")
+	b := a + len("fuck")
+	if got := in[f.Source(a):f.Source(b)]; got != "fuсk" {
+		t.Fatalf("span maps to %q", got)
+	}
+	// "this" follows a one-byte shrink
+	if got := in[f.Source(b+1):f.Source(len(f.Text))]; got != "this" {
+		t.Fatalf("tail maps to %q", got)
+	}
+	if f.Source(len(f.Text)+10) != len(in) {
+		t.Fatal("offsets past the end should clamp to the source length")
+	}
+}
@@ -7,6 +7,8 @@
 // 5 Width fold fullwidth to ASCII
 // 6 Simple leet folding eg 4/@->a 0->o 1/!->i 3->e 5/$->s 7->t
 // 7 Collapse whitespace to single spaces and trim
+// 8 Optionally drop quoted reply lines (Options.DropQuotes)
+// 9 Optionally drop pasted unified-diff hunks (Options.DropDiffs)
 package normalize
 
 import (
@@ -22,7 +24,18 @@ import (
 )
 
 // Normalizer is concurrency safe when used with the pool below
-type Normalizer struct{}
+type Normalizer struct {
+	opts Options
+}
+
+// Options tunes the pipeline; the zero value is the historical behavior
+type Options struct {
+	// DropQuotes removes '>' quoted lines (any depth) so replies don't inherit quoted text
+	DropQuotes bool
+	// DropDiffs removes pasted unified-diff hunks (see StripDiffs) so squash-merge bodies aren't
+	// scored on the variable names and comments of the patch they carry
+	DropDiffs bool
+}
 
 // pool of fresh transformer chains
 var chainPool = sync.Pool{
@@ -41,6 +54,9 @@ var chainPool = sync.Pool{
 // New constructs a Normalizer
 func New() *Normalizer { return &Normalizer{} }
 
+// NewWithOptions constructs a Normalizer with pipeline options
+func NewWithOptions(o Options) *Normalizer { return &Normalizer{opts: o} }
+
 // Normalize returns the normalized form of s following the pipeline described above
 func (n *Normalizer) Normalize(s string) string {
 	if s == "" {
@@ -64,6 +80,16 @@ func (n *Normalizer) Normalize(s string) string {
 	// 7 collapse whitespace and trim
 	ns = collapseSpaces(ns)
 
+	// 8 drop quoted reply lines
+	if n.opts.DropQuotes {
+		ns = StripQuotes(ns)
+	}
+
+	// 9 drop pasted diffs
+	if n.opts.DropDiffs {
+		ns = StripDiffs(ns)
+	}
+
 	return ns
 }
 
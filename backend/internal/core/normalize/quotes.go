@@ -0,0 +1,22 @@
+package normalize
+
+import "strings"
+
+// StripQuotes drops quoted reply lines from normalized text: any line whose first non-blank
+// byte is '>' goes, whatever the nesting depth ("> a", ">> b", "> > c"). These are the lines
+// DetectZones tags as ZoneQuote, so a reply no longer carries the profanity it quotes.
+// Remaining lines keep their order and are rejoined with '\n'; edges are trimmed again
+func StripQuotes(norm string) string {
+	if !strings.Contains(norm, ">") {
+		return norm
+	}
+	lines := strings.Split(norm, "\n")
+	kept := lines[:0]
+	for _, ln := range lines {
+		if strings.HasPrefix(strings.TrimLeft(ln, " \t"), ">") {
+			continue
+		}
+		kept = append(kept, ln)
+	}
+	return strings.Trim(strings.Join(kept, "\n"), " \n\t\r")
+}
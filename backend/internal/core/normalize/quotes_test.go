@@ -0,0 +1,35 @@
+package normalize
+
+import "testing"
+
+func TestStripQuotes(t *testing.T) {
+	cases := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"no quotes", "this build is shit", "this build is shit"},
+		{"inline gt kept", "a > b, damn", "a > b, damn"},
+		{"single level", "> what the fuck\nagreed", "agreed"},
+		{"nested", "> outer shit\n>> inner fuck\n> > spaced crap\nmy reply", "my reply"},
+		{"nested then reply between", "> a\nfirst\n>> b\n>>> c\nsecond", "first\nsecond"},
+		{"indented", "  > quoted damn\nok", "ok"},
+		{"all quoted", "> one\n>> two", ""},
+		{"trailing quote", "reply\n> tail", "reply"},
+	}
+	for _, tc := range cases {
+		if got := StripQuotes(tc.in); got != tc.want {
+			t.Fatalf("%s: got %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestNormalizeDropQuotes(t *testing.T) {
+	in := "> Quoted SHIT\n>> older  FUCK\nFine by me"
+	if got := New().Normalize(in); got != "> quoted shit\n>> older fuck\nfine by me" {
+		t.Fatalf("default normalize changed quotes: %q", got)
+	}
+	if got := NewWithOptions(Options{DropQuotes: true}).Normalize(in); got != "fine by me" {
+		t.Fatalf("DropQuotes: got %q, want %q", got, "fine by me")
+	}
+}
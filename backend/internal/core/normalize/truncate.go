@@ -0,0 +1,16 @@
+package normalize
+
+import "unicode/utf8"
+
+// TruncateBytes keeps at most limit bytes from the head of s, cutting at a rune boundary so
+// no UTF-8 sequence is split. It reports whether anything was cut; limit <= 0 means no limit
+func TruncateBytes(s string, limit int) (string, bool) {
+	if limit <= 0 || len(s) <= limit {
+		return s, false
+	}
+	cut := limit
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut], true
+}
@@ -0,0 +1,42 @@
+package normalize
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncateBytes(t *testing.T) {
+	cases := []struct {
+		name  string
+		in    string
+		limit int
+		want  string
+		cut   bool
+	}{
+		{"no limit", "what the fuck", 0, "what the fuck", false},
+		{"fits", "what the fuck", 13, "what the fuck", false},
+		{"ascii", "what the fuck", 8, "what the", true},
+		{"mid rune backs off", "fix ßhit", 5, "fix ", true}, // ß is 2 bytes at 4..5
+		{"rune boundary kept", "fix ßhit", 6, "fix ß", true},
+		{"first rune too long", "日本", 2, "", true},
+	}
+	for _, tc := range cases {
+		got, cut := TruncateBytes(tc.in, tc.limit)
+		if got != tc.want || cut != tc.cut {
+			t.Fatalf("%s: got %q %v, want %q %v", tc.name, got, cut, tc.want, tc.cut)
+		}
+	}
+}
+
+func TestTruncateBytes_PastedLog(t *testing.T) {
+	// an angry first line followed by a pasted build log, the shape that bloats utterances
+	body := "this fucking build broke again\n" + strings.Repeat("2025-09-18T14:00:00Z ERROR ünïcode step failed\n", 5000)
+	got, cut := TruncateBytes(body, 4096)
+	if !cut || len(got) > 4096 || !utf8.ValidString(got) {
+		t.Fatalf("truncated to %d bytes (cut %v), want <= 4096 of valid UTF-8", len(got), cut)
+	}
+	if !strings.HasPrefix(got, "this fucking build broke again\n") {
+		t.Fatalf("head of the body should survive: %q", got[:64])
+	}
+}
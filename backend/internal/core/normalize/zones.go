@@ -1,5 +1,11 @@
 package normalize
 
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
 // ZoneType identifies simple layout/markup zones over normalized text
 type ZoneType string
 
@@ -10,6 +16,10 @@ const (
 	ZoneCodeInline ZoneType = "code_inline"
 	// ZoneQuote is a quoted line
 	ZoneQuote ZoneType = "quote"
+	// ZoneURL is a bare URL, an autolink or a markdown link target (not the link text)
+	ZoneURL ZoneType = "url"
+	// ZoneDiff is a pasted unified-diff hunk with its file header lines
+	ZoneDiff ZoneType = "diff"
 )
 
 // ZoneSpan is a byte-range [Start,End) over the normalized string
@@ -22,6 +32,8 @@ type ZoneSpan struct {
 // - fenced code between ``` ... ``` (excluding the backticks)
 // - inline code between ` ... ` (excluding backticks; not inside fences)
 // - quoted lines that start with '>' (after any leading spaces) up to newline
+// - URLs: http(s)://..., www...., bare host/path (github.com/x/y) and markdown link targets
+// - unified-diff hunks pasted into the text, whole lines (see diffZones)
 //
 // Notes: we operate on the *normalized* text; newlines are preserved by collapseSpaces
 func DetectZones(norm string) []ZoneSpan {
@@ -111,6 +123,45 @@ func DetectZones(norm string) []ZoneSpan {
 		lineStart = lineEnd + 1
 	}
 
+	out = append(out, diffZones(norm)...)
+	return append(out, urlZones(norm)...)
+}
+
+var (
+	// reBareURL matches scheme/www URLs and bare host/path tokens; trailing punctuation is trimmed after
+	reBareURL = regexp.MustCompile(
+		`(?:https?://|www\.)[^\s<>()\[\]]+` +
+			`|\b[a-z0-9][a-z0-9-]*(?:\.[a-z0-9-]+)*\.[a-z]{2,}/[^\s<>()\[\]]*`,
+	)
+	// reMDLinkTarget captures the destination of [text](target) and [text](target "title")
+	reMDLinkTarget = regexp.MustCompile(`\]\(\s*([^)\s]+)`)
+)
+
+// urlZones returns merged ZoneURL spans for bare URLs and markdown link targets
+func urlZones(norm string) []ZoneSpan {
+	var spans []ZoneSpan
+	for _, m := range reMDLinkTarget.FindAllStringSubmatchIndex(norm, -1) {
+		spans = append(spans, ZoneSpan{Type: ZoneURL, Start: m[2], End: m[3]})
+	}
+	for _, m := range reBareURL.FindAllStringIndex(norm, -1) {
+		end := m[0] + len(strings.TrimRight(norm[m[0]:m[1]], `.,;:'"`))
+		if end > m[0] {
+			spans = append(spans, ZoneSpan{Type: ZoneURL, Start: m[0], End: end})
+		}
+	}
+	if len(spans) < 2 {
+		return spans
+	}
+	slices.SortFunc(spans, func(a, b ZoneSpan) int { return a.Start - b.Start })
+	out := spans[:1]
+	for _, z := range spans[1:] {
+		last := &out[len(out)-1]
+		if z.Start <= last.End {
+			last.End = max(last.End, z.End)
+			continue
+		}
+		out = append(out, z)
+	}
 	return out
 }
 
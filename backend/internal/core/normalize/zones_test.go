@@ -0,0 +1,59 @@
+package normalize
+
+import "testing"
+
+// urlTexts returns the text covered by each ZoneURL span
+func urlTexts(s string) []string {
+	var out []string
+	for _, z := range DetectZones(s) {
+		if z.Type == ZoneURL {
+			out = append(out, s[z.Start:z.End])
+		}
+	}
+	return out
+}
+
+func TestDetectZonesURL(t *testing.T) {
+	cases := []struct {
+		name string
+		in   string
+		want []string
+	}{
+		{"bare host path", "see github.com/user/fuckit for details", []string{"github.com/user/fuckit"}},
+		{"scheme", "broken: https://example.com/shit?x=1.", []string{"https://example.com/shit?x=1"}},
+		{"www", "go to www.damn.dev, then", []string{"www.damn.dev"}},
+		{"inline link target only", "[this crap](https://x.io/crap) is bad", []string{"https://x.io/crap"}},
+		{"link with title", `[docs](docs.rs/crate "t")`, []string{"docs.rs/crate"}},
+		{"autolink", "<https://a.b/c>", []string{"https://a.b/c"}},
+		{"prose", "this is a shitty build, damn it.", nil},
+	}
+	for _, tc := range cases {
+		got := urlTexts(tc.in)
+		if len(got) != len(tc.want) {
+			t.Fatalf("%s: got %q, want %q", tc.name, got, tc.want)
+		}
+		for i := range got {
+			if got[i] != tc.want[i] {
+				t.Fatalf("%s: got %q, want %q", tc.name, got, tc.want)
+			}
+		}
+	}
+}
+
+func TestDetectZonesKeepsExistingTypes(t *testing.T) {
+	s := "> quoted https://a.io/x\n`inline`"
+	var quote, inline, url bool
+	for _, z := range DetectZones(s) {
+		switch z.Type {
+		case ZoneQuote:
+			quote = true
+		case ZoneCodeInline:
+			inline = true
+		case ZoneURL:
+			url = true
+		}
+	}
+	if !quote || !inline || !url {
+		t.Fatalf("quote=%v inline=%v url=%v, want all true", quote, inline, url)
+	}
+}
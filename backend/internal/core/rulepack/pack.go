@@ -29,16 +29,19 @@ type allowlistBlock struct {
 }
 
 type rawTemplateV2 struct {
-	ID             string         `json:"id"`
-	Pattern        string         `json:"pattern"`
-	Category       string         `json:"category"`
-	Severity       int            `json:"severity"`
-	Variants       []string       `json:"variants,omitempty"`
-	ContextSignals map[string]any `json:"context_signals,omitempty"`
-	Examples       []string       `json:"examples,omitempty"`
+	Lang            string         `json:"lang,omitempty"`
+	ID              string         `json:"id"`
+	Pattern         string         `json:"pattern"`
+	Category        string         `json:"category"`
+	Severity        int            `json:"severity"`
+	Variants        []string       `json:"variants,omitempty"`
+	ContextSignals  map[string]any `json:"context_signals,omitempty"`
+	Examples        []string       `json:"examples,omitempty"`
+	CounterExamples []string       `json:"counter_examples,omitempty"`
 }
 
 type rawLemmaV2 struct {
+	Lang           string         `json:"lang,omitempty"`
 	Term           string         `json:"term"`
 	Category       string         `json:"category"`
 	Severity       int            `json:"severity"`
@@ -89,6 +92,23 @@ type Pack struct {
 	EngineHints  map[string]any
 	SeverityMods []map[string]any
 
+	// DetVer is the detector version the pack declares (meta.detver); 0 when it doesn't. The API
+	// defaults queries to it. Hits are stamped with CORE_DETECT_VERSION (or -ver/-detver) instead,
+	// and the detect module warns at boot when that differs
+	DetVer int
+
+	// ReportingCues are the phrases that mark an utterance as reporting abuse rather than
+	// committing it ("called me", "reporting"), from severity_mods with if.reporting_context
+	ReportingCues []string
+
+	// FrustrationTerms back the "frustration" context signal (engine_hints.frustration_terms)
+	FrustrationTerms []string
+
+	// Severity is the int -> storage label mapping (engine_hints.severity_scale)
+	Severity SeverityScale
+	// SeverityWeights weight storage labels in mean-severity indexes (engine_hints.severity_weights)
+	SeverityWeights SeverityWeights
+
 	// Flattened slot values (escaped later in expandSlots)
 	flatSlots map[string][]string
 
@@ -98,31 +118,83 @@ type Pack struct {
 
 // Template represents a compiled regex template rule
 type Template struct {
+	ID              string
 	PatternExpanded string
 	Category        string
 	Severity        int
+	Lang            string // ISO 639-1 code from the source fragment; "" = language-neutral
 	// forwarded from json (used for context gating, e.g. "frustration": true)
 	ContextSignals map[string]any
+	// Requires is the context predicate parsed from ContextSignals (see ContextRequirement)
+	Requires ContextRequirement
+	// Examples must match and CounterExamples must not (see Verify)
+	Examples        []string
+	CounterExamples []string
 }
 
 // Lemma represents a substring rule
 type Lemma struct {
+	ID             string // derived, lemmas carry none in rules.json (see lemmaID)
 	Term           string
 	Category       string
 	Severity       int
+	Lang           string // ISO 639-1 code from the source fragment; "" = language-neutral
 	ContextSignals map[string]any
 }
 
 // Load returns the compiled pack from the embedded v2 rules.json
-func Load() (*Pack, error) {
+func Load() (*Pack, error) { return parse(embedded) }
+
+// LoadSplit compiles packs written by `swearjar-rulepacker -split-by-lang`: core carries the
+// shared config and language-neutral rules, each of langs adds one language's rules (and
+// allowlist entries). Shared config is taken from core only, so a binary can embed just the
+// languages it needs
+func LoadSplit(core []byte, langs ...[]byte) (*Pack, error) {
+	rp, err := decode(core)
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range langs {
+		lp, err := decode(b)
+		if err != nil {
+			return nil, err
+		}
+		rp.Lemmas = append(rp.Lemmas, lp.Lemmas...)
+		rp.Templates = append(rp.Templates, lp.Templates...)
+		rp.Allowlist.Global = append(rp.Allowlist.Global, lp.Allowlist.Global...)
+		for z, xs := range lp.Allowlist.ByZone {
+			if rp.Allowlist.ByZone == nil {
+				rp.Allowlist.ByZone = make(map[string][]string, len(lp.Allowlist.ByZone))
+			}
+			rp.Allowlist.ByZone[z] = append(rp.Allowlist.ByZone[z], xs...)
+		}
+	}
+	return compile(rp)
+}
+
+// parse compiles a v2 rules.json document
+func parse(data []byte) (*Pack, error) {
+	rp, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+	return compile(rp)
+}
+
+// decode reads a v2 rules.json document without compiling it
+func decode(data []byte) (rawPackV2, error) {
 	var rp rawPackV2
-	if err := json.Unmarshal(embedded, &rp); err != nil {
-		return nil, fmt.Errorf("rulepack: parse rules.json: %w", err)
+	if err := json.Unmarshal(data, &rp); err != nil {
+		return rawPackV2{}, fmt.Errorf("rulepack: parse rules.json: %w", err)
 	}
 	if rp.Version != 2 {
-		return nil, fmt.Errorf("rulepack: unsupported rules.json version %d (want 2)", rp.Version)
+		return rawPackV2{}, fmt.Errorf("rulepack: unsupported rules.json version %d (want 2)", rp.Version)
 	}
+	return rp, nil
+}
 
+// compile builds the detector-ready Pack from a decoded document
+func compile(rp rawPackV2) (*Pack, error) {
 	p := &Pack{
 		Version:       rp.Version,
 		Stopset:       make(map[string]struct{}, 256),
@@ -136,6 +208,23 @@ func Load() (*Pack, error) {
 		SlotNameToRef: make(map[string]SlotRef, 256),
 	}
 
+	scale, err := parseSeverityScale(rp.EngineHints)
+	if err != nil {
+		return nil, fmt.Errorf("rulepack: %w", err)
+	}
+	p.Severity = scale
+
+	weights, err := parseSeverityWeights(rp.EngineHints)
+	if err != nil {
+		return nil, fmt.Errorf("rulepack: %w", err)
+	}
+	p.SeverityWeights = weights
+	p.ReportingCues = reportingCues(rp.SeverityMods)
+	p.FrustrationTerms = frustrationTerms(rp.EngineHints)
+	if v, ok := rp.Meta["detver"].(float64); ok && v > 0 && v == float64(int(v)) {
+		p.DetVer = int(v)
+	}
+
 	// Flatten slots for expansion: map slot -> []names (lowercased, deduped)
 	p.flatSlots = flattenSlots(rp.Slots)
 
@@ -157,20 +246,38 @@ func Load() (*Pack, error) {
 
 	// Compile templates: expand {SLOT} with flattened slot tokens (regex-quoted)
 	for _, t := range rp.Templates {
+		if !scale.Covers(t.Severity) {
+			return nil, fmt.Errorf("rulepack: template %q severity %d outside severity_scale", t.ID, t.Severity)
+		}
 		exp, err := expandSlots(t.Pattern, p.flatSlots)
 		if err != nil {
 			return nil, fmt.Errorf("rulepack: expand %q: %w", t.Pattern, err)
 		}
-		re, err := regexp.Compile(exp)
+		req, err := parseContextRequirement(t.ContextSignals)
 		if err != nil {
-			return nil, fmt.Errorf("rulepack: compile %q: %w", exp, err)
+			return nil, fmt.Errorf("rulepack: template %q: %w", t.ID, err)
 		}
 		p.Templates = append(p.Templates, Template{
+			ID:              t.ID,
 			PatternExpanded: exp,
 			Category:        t.Category,
 			Severity:        t.Severity,
+			Lang:            strings.ToLower(strings.TrimSpace(t.Lang)),
 			ContextSignals:  t.ContextSignals,
+			Requires:        req,
+			Examples:        t.Examples,
+			CounterExamples: t.CounterExamples,
 		})
+	}
+	// Deterministic iteration for tests/debug; sorted before compiling so Compiled is built 1:1
+	sort.SliceStable(p.Templates, func(i, j int) bool {
+		return p.Templates[i].PatternExpanded < p.Templates[j].PatternExpanded
+	})
+	for _, t := range p.Templates {
+		re, err := regexp.Compile(t.PatternExpanded)
+		if err != nil {
+			return nil, fmt.Errorf("rulepack: compile %q: %w", t.PatternExpanded, err)
+		}
 		p.Compiled = append(p.Compiled, re)
 	}
 
@@ -180,10 +287,16 @@ func Load() (*Pack, error) {
 		if term == "" {
 			continue
 		}
+		if !scale.Covers(l.Severity) {
+			return nil, fmt.Errorf("rulepack: lemma %q severity %d outside severity_scale", term, l.Severity)
+		}
+		lang := strings.ToLower(strings.TrimSpace(l.Lang))
 		lemma := Lemma{
+			ID:             lemmaID(lang, term),
 			Term:           term,
 			Category:       l.Category,
 			Severity:       l.Severity,
+			Lang:           lang,
 			ContextSignals: l.ContextSignals,
 		}
 		p.Lemmas = append(p.Lemmas, lemma)
@@ -207,17 +320,16 @@ func Load() (*Pack, error) {
 					continue
 				}
 				p.SlotNameToRef[nm] = SlotRef{Type: st, ID: id}
-				if nm[0] != '@' {
+				if nm[0] != '@' && st != "self" { // "@me" is not a self-reference
 					p.SlotNameToRef["@"+nm] = SlotRef{Type: st, ID: id}
 				}
 			}
 		}
 	}
 
-	// Deterministic iteration for tests/debug
-	sort.Slice(p.Templates, func(i, j int) bool {
-		return p.Templates[i].PatternExpanded < p.Templates[j].PatternExpanded
-	})
+	if err := p.checkCompiled(); err != nil {
+		return nil, err
+	}
 	sort.Slice(p.Lemmas, func(i, j int) bool {
 		return p.Lemmas[i].Term < p.Lemmas[j].Term
 	})
@@ -225,6 +337,20 @@ func Load() (*Pack, error) {
 	return p, nil
 }
 
+// checkCompiled fails when Compiled isn't 1:1 with Templates; the detector reads a template's
+// metadata by its regex's index, so a mismatch would mislabel every hit after it
+func (p *Pack) checkCompiled() error {
+	if len(p.Compiled) != len(p.Templates) {
+		return fmt.Errorf("rulepack: %d compiled patterns for %d templates", len(p.Compiled), len(p.Templates))
+	}
+	for i, re := range p.Compiled {
+		if re.String() != p.Templates[i].PatternExpanded {
+			return fmt.Errorf("rulepack: compiled pattern %d does not match template %q", i, p.Templates[i].ID)
+		}
+	}
+	return nil
+}
+
 // flattenSlots converts the v2 alias blocks into simple lowercased name lists per slot
 func flattenSlots(in map[string]slotBlock) map[string][]string {
 	out := make(map[string][]string, len(in))
@@ -299,7 +425,19 @@ func slotKeyToType(k string) (string, bool) {
 		return "lang", true
 	case "TARGET_FRAMEWORK":
 		return "framework", true
+	case "TARGET_SELF":
+		return "self", true
 	default:
 		return "", false
 	}
 }
+
+// lemmaID names a lemma like template IDs read: "<lang>.lemma.<term>" with spaces as
+// underscores ("en.lemma.wtf"), or "lemma.<term>" for language-neutral lemmas
+func lemmaID(lang, term string) string {
+	id := "lemma." + strings.ReplaceAll(term, " ", "_")
+	if lang != "" {
+		id = lang + "." + id
+	}
+	return id
+}
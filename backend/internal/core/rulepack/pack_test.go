@@ -2,6 +2,7 @@
 package rulepack
 
 import (
+	"encoding/json"
 	"regexp"
 	"testing"
 )
@@ -21,6 +22,9 @@ func TestLoadAndExpand(t *testing.T) {
 		if p.Compiled[i] == nil {
 			t.Fatalf("nil compiled regexp at %d", i)
 		}
+		if p.Compiled[i].String() != tplt.PatternExpanded {
+			t.Fatalf("compiled[%d] %q does not match template %q", i, p.Compiled[i].String(), tplt.PatternExpanded)
+		}
 		if _, err := regexp.Compile(tplt.PatternExpanded); err != nil {
 			t.Fatalf("expanded pattern invalid: %q: %v", tplt.PatternExpanded, err)
 		}
@@ -31,6 +35,30 @@ func TestLoadAndExpand(t *testing.T) {
 	if _, ok := p.Stopset["scunthorpe"]; !ok {
 		t.Fatalf("stoplist missing scunthorpe")
 	}
+	if p.DetVer != 1 {
+		t.Fatalf("meta.detver = %d, want 1", p.DetVer)
+	}
+	for _, l := range p.Lemmas {
+		if l.Lang == "" {
+			t.Fatalf("lemma %q lost its fragment language", l.Term)
+		}
+	}
+}
+
+func TestSelfSlot(t *testing.T) {
+	p, err := Load()
+	if err != nil {
+		t.Fatalf("Load(): %v", err)
+	}
+	if ref, ok := p.SlotNameToRef["my"]; !ok || ref.Type != "self" {
+		t.Fatalf("TARGET_SELF alias \"my\" = %+v, %v", ref, ok)
+	}
+	if _, ok := p.SlotNameToRef["@my"]; ok {
+		t.Fatal("self aliases must not get @ forms")
+	}
+	if ref := p.SlotNameToRef["@dependabot"]; ref.Type != "bot" {
+		t.Fatalf("@dependabot = %+v, want bot", ref)
+	}
 }
 
 func TestExpandSlotsStandalone(t *testing.T) {
@@ -50,3 +78,116 @@ func TestExpandSlotsStandalone(t *testing.T) {
 		}
 	}
 }
+
+func TestLoadSplitMatchesMonolithic(t *testing.T) {
+	rp, err := decode(embedded)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	// Split the embedded pack the way the rulepacker does: neutral rules stay in core
+	core := rp
+	core.Lemmas, core.Templates = nil, nil
+	byLang := map[string]*rawPackV2{}
+	for _, l := range rp.Lemmas {
+		if l.Lang == "" {
+			core.Lemmas = append(core.Lemmas, l)
+			continue
+		}
+		if byLang[l.Lang] == nil {
+			byLang[l.Lang] = &rawPackV2{Version: 2}
+		}
+		byLang[l.Lang].Lemmas = append(byLang[l.Lang].Lemmas, l)
+	}
+	for _, tp := range rp.Templates {
+		if tp.Lang == "" {
+			core.Templates = append(core.Templates, tp)
+			continue
+		}
+		if byLang[tp.Lang] == nil {
+			byLang[tp.Lang] = &rawPackV2{Version: 2}
+		}
+		byLang[tp.Lang].Templates = append(byLang[tp.Lang].Templates, tp)
+	}
+	if len(byLang) == 0 {
+		t.Fatalf("embedded pack has no language-tagged rules")
+	}
+	coreDoc, _ := json.Marshal(core)
+	var langDocs [][]byte
+	for _, lp := range byLang {
+		b, _ := json.Marshal(lp)
+		langDocs = append(langDocs, b)
+	}
+
+	whole, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	merged, err := LoadSplit(coreDoc, langDocs...)
+	if err != nil {
+		t.Fatalf("LoadSplit: %v", err)
+	}
+	if len(merged.Lemmas) != len(whole.Lemmas) || len(merged.Templates) != len(whole.Templates) {
+		t.Fatalf("split = %d lemmas/%d templates, monolithic = %d/%d",
+			len(merged.Lemmas), len(merged.Templates), len(whole.Lemmas), len(whole.Templates))
+	}
+	for i := range whole.Templates {
+		if merged.Templates[i].PatternExpanded != whole.Templates[i].PatternExpanded {
+			t.Fatalf("template %d = %q, want %q", i, merged.Templates[i].PatternExpanded, whole.Templates[i].PatternExpanded)
+		}
+	}
+
+	coreOnly, err := LoadSplit(coreDoc)
+	if err != nil {
+		t.Fatalf("LoadSplit(core): %v", err)
+	}
+	for _, l := range coreOnly.Lemmas {
+		if l.Lang != "" {
+			t.Fatalf("core pack carries %q lemma %q", l.Lang, l.Term)
+		}
+	}
+	if len(coreOnly.Severity.Bands) == 0 || len(coreOnly.SlotNameToRef) == 0 {
+		t.Fatalf("core pack lost shared config")
+	}
+}
+
+func TestRuleIDsAreSetAndUnique(t *testing.T) {
+	p, err := Load()
+	if err != nil {
+		t.Fatalf("Load(): %v", err)
+	}
+	seen := map[string]bool{}
+	check := func(id, what string) {
+		if id == "" || seen[id] {
+			t.Fatalf("%s: rule id %q empty or duplicated", what, id)
+		}
+		seen[id] = true
+	}
+	for _, tpl := range p.Templates {
+		check(tpl.ID, tpl.PatternExpanded)
+	}
+	for _, lm := range p.Lemmas {
+		check(lm.ID, lm.Term)
+	}
+
+	if got := lemmaID("en", "what the fuck"); got != "en.lemma.what_the_fuck" {
+		t.Fatalf("lemmaID(en) = %q", got)
+	}
+	if got := lemmaID("", "wtf"); got != "lemma.wtf" {
+		t.Fatalf("lemmaID(neutral) = %q", got)
+	}
+}
+
+func TestCheckCompiledRejectsMismatch(t *testing.T) {
+	a, b := regexp.MustCompile(`\ba\b`), regexp.MustCompile(`\bb\b`)
+	tpls := []Template{{ID: "t.a", PatternExpanded: a.String()}, {ID: "t.b", PatternExpanded: b.String()}}
+
+	if err := (&Pack{Templates: tpls, Compiled: []*regexp.Regexp{a, b}}).checkCompiled(); err != nil {
+		t.Fatalf("aligned pack: %v", err)
+	}
+	if err := (&Pack{Templates: tpls, Compiled: []*regexp.Regexp{b, a}}).checkCompiled(); err == nil {
+		t.Fatal("out-of-order Compiled should fail")
+	}
+	if err := (&Pack{Templates: tpls, Compiled: []*regexp.Regexp{a}}).checkCompiled(); err == nil {
+		t.Fatal("short Compiled should fail")
+	}
+}
@@ -0,0 +1,243 @@
+package rulepack
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Severity storage labels; these mirror the Enum8 used by ClickHouse hits/commit_crimes
+const (
+	SeverityMild       = "mild"
+	SeverityStrong     = "strong"
+	SeveritySlurMasked = "slur_masked"
+)
+
+var knownSeverityLabels = map[string]struct{}{
+	SeverityMild:       {},
+	SeverityStrong:     {},
+	SeveritySlurMasked: {},
+}
+
+// severityRanks are the labels' Enum8 values, which order them from mildest to worst
+var severityRanks = map[string]int{
+	SeverityMild:       1,
+	SeverityStrong:     2,
+	SeveritySlurMasked: 3,
+}
+
+// ParseMinSeverity reads a severity floor given as a storage label ("strong") or its Enum8
+// rank ("2") and returns the rank; empty means no floor (0)
+func ParseMinSeverity(s string) (int, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return 0, nil
+	}
+	if r, ok := severityRanks[s]; ok {
+		return r, nil
+	}
+	if n, err := strconv.Atoi(s); err == nil && n >= 1 && n <= len(severityRanks) {
+		return n, nil
+	}
+	return 0, fmt.Errorf("min severity %q: want mild, strong, slur_masked or 1-%d", s, len(severityRanks))
+}
+
+// SeverityBand maps an inclusive int range [Min, Max] to a storage label
+type SeverityBand struct {
+	Label string `json:"label"`
+	Min   int    `json:"min"`
+	Max   int    `json:"max"`
+}
+
+// SeverityScale is the single int -> label mapping (engine_hints.severity_scale)
+// Bands are ascending, contiguous and non-overlapping
+type SeverityScale struct {
+	Bands []SeverityBand `json:"bands"`
+}
+
+// DefaultSeverityScale is used when a pack does not declare engine_hints.severity_scale
+// It matches the historical mapping: 1 is mild, anything above is strong
+func DefaultSeverityScale() SeverityScale {
+	return SeverityScale{Bands: []SeverityBand{
+		{Label: SeverityMild, Min: 1, Max: 1},
+		{Label: SeverityStrong, Min: 2, Max: 3},
+	}}
+}
+
+// Validate checks the bands are usable for labelling
+func (s SeverityScale) Validate() error {
+	if len(s.Bands) == 0 {
+		return fmt.Errorf("severity_scale: no bands")
+	}
+	for i, b := range s.Bands {
+		if _, ok := knownSeverityLabels[b.Label]; !ok {
+			return fmt.Errorf("severity_scale: band %d: unknown label %q", i, b.Label)
+		}
+		if b.Min > b.Max {
+			return fmt.Errorf("severity_scale: band %q: min %d > max %d", b.Label, b.Min, b.Max)
+		}
+		if i > 0 && b.Min != s.Bands[i-1].Max+1 {
+			return fmt.Errorf("severity_scale: band %q must start at %d (got %d)", b.Label, s.Bands[i-1].Max+1, b.Min)
+		}
+	}
+	return nil
+}
+
+// Covers reports whether n falls inside a declared band
+func (s SeverityScale) Covers(n int) bool {
+	return len(s.Bands) > 0 && n >= s.Bands[0].Min && n <= s.Bands[len(s.Bands)-1].Max
+}
+
+// Label returns the storage label for severity n
+// Values outside the scale (e.g., after zone dampening or boosts) clamp to the first/last band
+func (s SeverityScale) Label(n int) string {
+	if len(s.Bands) == 0 {
+		return SeverityMild
+	}
+	for _, b := range s.Bands {
+		if n <= b.Max {
+			return b.Label
+		}
+	}
+	return s.Bands[len(s.Bands)-1].Label
+}
+
+// SeverityLabel maps a detector severity to its storage label using the pack's scale
+func (p *Pack) SeverityLabel(n int) string { return p.Severity.Label(n) }
+
+// parseSeverityScale reads engine_hints.severity_scale, falling back to the default
+func parseSeverityScale(hints map[string]any) (SeverityScale, error) {
+	raw, ok := hints["severity_scale"]
+	if !ok || raw == nil {
+		return DefaultSeverityScale(), nil
+	}
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return SeverityScale{}, fmt.Errorf("severity_scale: %w", err)
+	}
+	var s SeverityScale
+	if err := json.Unmarshal(b, &s); err != nil {
+		return SeverityScale{}, fmt.Errorf("severity_scale: %w", err)
+	}
+	return s, s.Validate()
+}
+
+// SeverityWeights maps storage labels to the weight used in severity indexes
+// (engine_hints.severity_weights); every endpoint computing a mean severity uses it
+type SeverityWeights map[string]float64
+
+// DefaultSeverityWeights matches the historical index: mild=1, strong=2, slur_masked=3
+func DefaultSeverityWeights() SeverityWeights {
+	return SeverityWeights{SeverityMild: 1, SeverityStrong: 2, SeveritySlurMasked: 3}
+}
+
+// Validate checks labels are known and weights are finite and non-negative
+func (w SeverityWeights) Validate() error {
+	for label, v := range w {
+		if _, ok := knownSeverityLabels[label]; !ok {
+			return fmt.Errorf("severity_weights: unknown label %q", label)
+		}
+		if v < 0 || math.IsNaN(v) || math.IsInf(v, 0) {
+			return fmt.Errorf("severity_weights: %q weight %v must be a finite number >= 0", label, v)
+		}
+	}
+	return nil
+}
+
+// Weight returns the weight for label; labels without one fall back to the default weight
+func (w SeverityWeights) Weight(label string) float64 {
+	if v, ok := w[label]; ok {
+		return v
+	}
+	return DefaultSeverityWeights()[label]
+}
+
+// Index is the weighted mean severity over per-label hit counts (0 when there are no hits)
+func (w SeverityWeights) Index(counts map[string]int64) float64 {
+	var sum float64
+	var n int64
+	for label, c := range counts {
+		if c <= 0 {
+			continue
+		}
+		sum += w.Weight(label) * float64(c)
+		n += c
+	}
+	if n == 0 {
+		return 0
+	}
+	return sum / float64(n)
+}
+
+// ParseSeverityWeights reads "label=weight" pairs (e.g., "slur_masked=10,strong=3") over base
+// Labels not mentioned keep their base weight
+func ParseSeverityWeights(base SeverityWeights, s string) (SeverityWeights, error) {
+	out := make(SeverityWeights, len(base))
+	for k, v := range base {
+		out[k] = v
+	}
+	for pair := range strings.SplitSeq(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		label, raw, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("severity_weights: %q: want label=weight", pair)
+		}
+		v, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+		if err != nil {
+			return nil, fmt.Errorf("severity_weights: %q: %w", pair, err)
+		}
+		out[strings.TrimSpace(label)] = v
+	}
+	return out, out.Validate()
+}
+
+// parseSeverityWeights reads engine_hints.severity_weights over the defaults
+func parseSeverityWeights(hints map[string]any) (SeverityWeights, error) {
+	w := DefaultSeverityWeights()
+	raw, ok := hints["severity_weights"]
+	if !ok || raw == nil {
+		return w, nil
+	}
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("severity_weights: %w", err)
+	}
+	var declared map[string]float64
+	if err := json.Unmarshal(b, &declared); err != nil {
+		return nil, fmt.Errorf("severity_weights: %w", err)
+	}
+	for k, v := range declared {
+		w[k] = v
+	}
+	return w, w.Validate()
+}
+
+// reportingCues collects the lowercased, deduped cues of every severity_mod conditioned on
+// reporting_context; the detector decides when they apply, and the delta comes from
+// CORE_DETECT_QUOTED_REPORT_DELTA, so these mods carry cues only
+func reportingCues(mods []map[string]any) []string {
+	var out []string
+	seen := map[string]struct{}{}
+	for _, m := range mods {
+		cond, _ := m["if"].(map[string]any)
+		if on, _ := cond["reporting_context"].(bool); !on {
+			continue
+		}
+		cues, _ := m["cues"].([]any)
+		for _, c := range cues {
+			s, _ := c.(string)
+			s = strings.ToLower(strings.TrimSpace(s))
+			if _, dup := seen[s]; s == "" || dup {
+				continue
+			}
+			seen[s] = struct{}{}
+			out = append(out, s)
+		}
+	}
+	return out
+}
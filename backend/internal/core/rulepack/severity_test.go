@@ -0,0 +1,207 @@
+package rulepack
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSeverityLabel(t *testing.T) {
+	p, err := Load()
+	if err != nil {
+		t.Fatalf("Load(): %v", err)
+	}
+	cases := map[int]string{
+		-3: SeverityMild, // dampened below the scale clamps to the first band
+		0:  SeverityMild,
+		1:  SeverityMild,
+		2:  SeverityStrong,
+		3:  SeverityStrong,
+		9:  SeverityStrong, // boosted above the scale clamps to the last band
+	}
+	for n, want := range cases {
+		if got := p.SeverityLabel(n); got != want {
+			t.Fatalf("SeverityLabel(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
+
+func TestEveryRuleSeverityIsCovered(t *testing.T) {
+	p, err := Load()
+	if err != nil {
+		t.Fatalf("Load(): %v", err)
+	}
+	for _, tpl := range p.Templates {
+		if !p.Severity.Covers(tpl.Severity) {
+			t.Fatalf("template %q severity %d not covered", tpl.PatternExpanded, tpl.Severity)
+		}
+	}
+	for _, lm := range p.Lemmas {
+		if !p.Severity.Covers(lm.Severity) {
+			t.Fatalf("lemma %q severity %d not covered", lm.Term, lm.Severity)
+		}
+	}
+}
+
+func TestSeverityScaleValidate(t *testing.T) {
+	cases := []struct {
+		name  string
+		scale SeverityScale
+		want  string // substring of the error; "" means valid
+	}{
+		{"default", DefaultSeverityScale(), ""},
+		{"three bands", SeverityScale{Bands: []SeverityBand{
+			{SeverityMild, 1, 1}, {SeverityStrong, 2, 2}, {SeveritySlurMasked, 3, 3},
+		}}, ""},
+		{"empty", SeverityScale{}, "no bands"},
+		{"unknown label", SeverityScale{Bands: []SeverityBand{{"spicy", 1, 3}}}, "unknown label"},
+		{"inverted", SeverityScale{Bands: []SeverityBand{{SeverityMild, 3, 1}}}, "min 3 > max 1"},
+		{"gap", SeverityScale{Bands: []SeverityBand{
+			{SeverityMild, 1, 1}, {SeverityStrong, 3, 4},
+		}}, "must start at 2"},
+		{"overlap", SeverityScale{Bands: []SeverityBand{
+			{SeverityMild, 1, 2}, {SeverityStrong, 2, 3},
+		}}, "must start at 3"},
+	}
+	for _, tc := range cases {
+		err := tc.scale.Validate()
+		switch {
+		case tc.want == "" && err != nil:
+			t.Fatalf("%s: unexpected error: %v", tc.name, err)
+		case tc.want != "" && (err == nil || !strings.Contains(err.Error(), tc.want)):
+			t.Fatalf("%s: error = %v, want %q", tc.name, err, tc.want)
+		}
+	}
+}
+
+func TestParseRejectsUncoveredSeverity(t *testing.T) {
+	doc := `{
+		"version": 2,
+		"engine_hints": {"severity_scale": {"bands": [{"label": "mild", "min": 1, "max": 2}]}},
+		"lemmas": [{"term": "heck", "category": "generic", "severity": 3}]
+	}`
+	_, err := parse([]byte(doc))
+	if err == nil || !strings.Contains(err.Error(), `lemma "heck" severity 3`) {
+		t.Fatalf("expected uncovered severity error, got %v", err)
+	}
+}
+
+func TestParseDefaultsSeverityScale(t *testing.T) {
+	p, err := parse([]byte(`{"version": 2, "lemmas": [{"term": "heck", "category": "generic", "severity": 2}]}`))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if p.SeverityLabel(2) != SeverityStrong {
+		t.Fatalf("default scale not applied: %+v", p.Severity)
+	}
+}
+
+func TestSeverityWeightsIndex(t *testing.T) {
+	counts := map[string]int64{SeverityMild: 6, SeverityStrong: 3, SeveritySlurMasked: 1}
+
+	def := DefaultSeverityWeights().Index(counts)
+	if want := (6*1.0 + 3*2.0 + 1*3.0) / 10; def != want {
+		t.Fatalf("default index = %v, want %v", def, want)
+	}
+
+	heavy, err := ParseSeverityWeights(DefaultSeverityWeights(), "slur_masked=10")
+	if err != nil {
+		t.Fatalf("ParseSeverityWeights: %v", err)
+	}
+	got := heavy.Index(counts)
+	if want := (6*1.0 + 3*2.0 + 1*10.0) / 10; got != want {
+		t.Fatalf("weighted index = %v, want %v", got, want)
+	}
+	if got <= def {
+		t.Fatalf("raising the slur weight did not raise the index: %v <= %v", got, def)
+	}
+
+	if (SeverityWeights{}).Index(nil) != 0 {
+		t.Fatal("empty counts should index to 0")
+	}
+}
+
+func TestParseSeverityWeights(t *testing.T) {
+	cases := []struct {
+		in   string
+		want string // substring of the error; "" means valid
+	}{
+		{"", ""},
+		{" strong = 2.5 , slur_masked=8", ""},
+		{"spicy=2", "unknown label"},
+		{"mild", "want label=weight"},
+		{"mild=x", "invalid syntax"},
+		{"mild=-1", ">= 0"},
+	}
+	for _, tc := range cases {
+		_, err := ParseSeverityWeights(DefaultSeverityWeights(), tc.in)
+		switch {
+		case tc.want == "" && err != nil:
+			t.Fatalf("%q: unexpected error: %v", tc.in, err)
+		case tc.want != "" && (err == nil || !strings.Contains(err.Error(), tc.want)):
+			t.Fatalf("%q: error = %v, want %q", tc.in, err, tc.want)
+		}
+	}
+}
+
+func TestParseSeverityWeightsFromHints(t *testing.T) {
+	p, err := parse([]byte(`{
+		"version": 2,
+		"engine_hints": {"severity_weights": {"slur_masked": 6}},
+		"lemmas": [{"term": "heck", "category": "generic", "severity": 1}]
+	}`))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	w := p.SeverityWeights
+	if w.Weight(SeveritySlurMasked) != 6 || w.Weight(SeverityMild) != 1 || w.Weight(SeverityStrong) != 2 {
+		t.Fatalf("hint weights not merged over defaults: %v", w)
+	}
+
+	_, err = parse([]byte(`{"version": 2, "engine_hints": {"severity_weights": {"mild": -2}}}`))
+	if err == nil || !strings.Contains(err.Error(), "severity_weights") {
+		t.Fatalf("expected severity_weights error, got %v", err)
+	}
+}
+
+func TestParseMinSeverity(t *testing.T) {
+	cases := []struct {
+		in   string
+		want int
+		ok   bool
+	}{
+		{"", 0, true},
+		{"mild", 1, true},
+		{" Strong ", 2, true},
+		{"slur_masked", 3, true},
+		{"3", 3, true},
+		{"0", 0, false},
+		{"4", 0, false},
+		{"spicy", 0, false},
+	}
+	for _, tc := range cases {
+		got, err := ParseMinSeverity(tc.in)
+		if (err == nil) != tc.ok || got != tc.want {
+			t.Fatalf("%q: got (%d, %v), want (%d, ok=%v)", tc.in, got, err, tc.want, tc.ok)
+		}
+	}
+}
+
+func TestReportingCues(t *testing.T) {
+	p, err := Load()
+	if err != nil {
+		t.Fatalf("Load(): %v", err)
+	}
+	if !slices.Contains(p.ReportingCues, "called me") {
+		t.Fatalf("embedded pack cues %v missing \"called me\"", p.ReportingCues)
+	}
+
+	got := reportingCues([]map[string]any{
+		{"id": "reduce.quote", "if": map[string]any{"zone": "quote"}, "cues": []any{"ignored"}},
+		{"id": "a", "if": map[string]any{"reporting_context": true}, "cues": []any{" Told Me ", "", 7}},
+		{"id": "b", "if": map[string]any{"reporting_context": true}, "cues": []any{"told me", "reported"}},
+	})
+	if want := []string{"told me", "reported"}; !slices.Equal(got, want) {
+		t.Fatalf("cues %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,95 @@
+package rulepack
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Context signals a template can require (context_signals.requires / requires_any) before the
+// detector emits its hits. "Nearby" is the detector's context window around the match
+const (
+	// SignalTarget is a bot, tool, language or framework alias nearby (the match included)
+	SignalTarget = "target"
+	// SignalDirectAddress is a second-person word nearby (you, your, u, ...; the match included)
+	SignalDirectAddress = "direct_address"
+	// SignalFrustration is an engine_hints.frustration_terms entry nearby, outside the match
+	SignalFrustration = "frustration"
+	// SignalOutsideCode holds when the match is not in a code fence, inline code or a pasted diff
+	SignalOutsideCode = "outside_code"
+)
+
+// Signals is the context signal vocabulary
+var Signals = []string{SignalTarget, SignalDirectAddress, SignalFrustration, SignalOutsideCode}
+
+// ContextRequirement is a template's declared context predicate: every All signal and, when
+// Any is non-empty, at least one Any signal must hold
+type ContextRequirement struct {
+	All []string
+	Any []string
+}
+
+// Empty reports whether the requirement constrains nothing
+func (r ContextRequirement) Empty() bool { return len(r.All) == 0 && len(r.Any) == 0 }
+
+// parseContextRequirement reads requires/requires_any from context_signals. The older boolean
+// flags map onto the vocabulary: requires_direct_address -> direct_address and
+// disallow_in_code -> outside_code. Unknown signal names are an error so typos fail the build
+func parseContextRequirement(cs map[string]any) (ContextRequirement, error) {
+	var r ContextRequirement
+	var err error
+	if r.All, err = signalList(cs, "requires"); err != nil {
+		return r, err
+	}
+	if r.Any, err = signalList(cs, "requires_any"); err != nil {
+		return r, err
+	}
+	for flag, sig := range map[string]string{
+		"requires_direct_address": SignalDirectAddress,
+		"disallow_in_code":        SignalOutsideCode,
+	} {
+		if on, _ := cs[flag].(bool); on && !slices.Contains(r.All, sig) {
+			r.All = append(r.All, sig)
+		}
+	}
+	slices.Sort(r.All)
+	return r, nil
+}
+
+func signalList(cs map[string]any, key string) ([]string, error) {
+	v, ok := cs[key]
+	if !ok {
+		return nil, nil
+	}
+	xs, ok := v.([]any)
+	if !ok {
+		return nil, fmt.Errorf("context_signals.%s: want a list of signals", key)
+	}
+	out := make([]string, 0, len(xs))
+	for _, x := range xs {
+		s, _ := x.(string)
+		s = strings.ToLower(strings.TrimSpace(s))
+		if !slices.Contains(Signals, s) {
+			return nil, fmt.Errorf("context_signals.%s: unknown signal %q (want one of %s)",
+				key, x, strings.Join(Signals, ", "))
+		}
+		if !slices.Contains(out, s) {
+			out = append(out, s)
+		}
+	}
+	return out, nil
+}
+
+// frustrationTerms reads engine_hints.frustration_terms, lowercased, trimmed and deduped
+func frustrationTerms(hints map[string]any) []string {
+	xs, _ := hints["frustration_terms"].([]any)
+	out := make([]string, 0, len(xs))
+	for _, x := range xs {
+		s, _ := x.(string)
+		s = strings.ToLower(strings.TrimSpace(s))
+		if s != "" && !slices.Contains(out, s) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
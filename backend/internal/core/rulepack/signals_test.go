@@ -0,0 +1,56 @@
+package rulepack
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseContextRequirement(t *testing.T) {
+	r, err := parseContextRequirement(map[string]any{
+		"requires":                []any{"Target", "frustration", "target"},
+		"requires_any":            []any{"direct_address"},
+		"requires_direct_address": true,
+		"disallow_in_code":        true,
+		"boost_if_mention":        true, // not a requirement, ignored
+	})
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	wantAll := []string{SignalDirectAddress, SignalFrustration, SignalOutsideCode, SignalTarget}
+	if !slices.Equal(r.All, wantAll) || !slices.Equal(r.Any, []string{SignalDirectAddress}) {
+		t.Fatalf("got all=%v any=%v, want all=%v any=[direct_address]", r.All, r.Any, wantAll)
+	}
+
+	if r, err := parseContextRequirement(nil); err != nil || !r.Empty() {
+		t.Fatalf("no signals: got %+v, %v, want an empty requirement", r, err)
+	}
+}
+
+func TestParseContextRequirementRejectsUnknown(t *testing.T) {
+	for _, cs := range []map[string]any{
+		{"requires": []any{"targett"}},
+		{"requires_any": []any{"frustration", 3}},
+		{"requires": "target"},
+	} {
+		if _, err := parseContextRequirement(cs); err == nil || !strings.Contains(err.Error(), "context_signals.") {
+			t.Fatalf("%v: err = %v, want a context_signals error", cs, err)
+		}
+	}
+}
+
+func TestLoadParsesRequirementsAndFrustrationTerms(t *testing.T) {
+	p, err := Load()
+	if err != nil {
+		t.Fatalf("Load(): %v", err)
+	}
+	if !slices.Contains(p.FrustrationTerms, "dumpster fire") {
+		t.Fatalf("frustration terms %v missing \"dumpster fire\"", p.FrustrationTerms)
+	}
+	for _, tpl := range p.Templates {
+		on, _ := tpl.ContextSignals["disallow_in_code"].(bool)
+		if on && !slices.Contains(tpl.Requires.All, SignalOutsideCode) {
+			t.Fatalf("%s: disallow_in_code not mapped to outside_code: %+v", tpl.ID, tpl.Requires)
+		}
+	}
+}
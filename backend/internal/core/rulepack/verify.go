@@ -0,0 +1,41 @@
+package rulepack
+
+import "swearjar/internal/core/normalize"
+
+// TemplateFailure is a template whose compiled regex disagrees with its declared examples
+type TemplateFailure struct {
+	ID      string   // template id, or the expanded pattern when the rule has none
+	Missed  []string // examples the regex does not match
+	Matched []string // counter_examples the regex does match
+}
+
+// Verify checks each template's compiled regex against its examples (all must match) and
+// counter_examples (none may match), returning the templates that fail, in pack order.
+// Examples are normalized like utterance text before matching, so they can be written as a
+// human would type them. Templates without examples are not checked
+func (p *Pack) Verify() []TemplateFailure {
+	n := normalize.New()
+	var out []TemplateFailure
+	for i, t := range p.Templates {
+		re := p.Compiled[i]
+		f := TemplateFailure{ID: t.ID}
+		for _, ex := range t.Examples {
+			if !re.MatchString(n.Normalize(ex)) {
+				f.Missed = append(f.Missed, ex)
+			}
+		}
+		for _, ex := range t.CounterExamples {
+			if re.MatchString(n.Normalize(ex)) {
+				f.Matched = append(f.Matched, ex)
+			}
+		}
+		if len(f.Missed) == 0 && len(f.Matched) == 0 {
+			continue
+		}
+		if f.ID == "" {
+			f.ID = t.PatternExpanded
+		}
+		out = append(out, f)
+	}
+	return out
+}
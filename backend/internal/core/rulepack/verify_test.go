@@ -0,0 +1,41 @@
+package rulepack
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestVerifyEmbeddedPack(t *testing.T) {
+	p, err := Load()
+	if err != nil {
+		t.Fatalf("Load(): %v", err)
+	}
+	if fs := p.Verify(); len(fs) != 0 {
+		t.Fatalf("embedded templates drifted from their examples: %+v", fs)
+	}
+}
+
+func TestVerifyReportsDrift(t *testing.T) {
+	doc := `{
+		"version": 2,
+		"slots": {"TARGET_BOT": {"aliases": [{"id": "dependabot", "names": ["dependabot"]}]}},
+		"templates": [
+			{"id": "ok", "pattern": "(?:{TARGET_BOT})\\s+sucks", "category": "bot_rage", "severity": 1,
+			 "examples": ["Dependabot   SUCKS"], "counter_examples": ["renovate sucks"]},
+			{"id": "drifted", "pattern": "\\bwtf\\b", "category": "generic", "severity": 1,
+			 "examples": ["wtf", "what the fuck"], "counter_examples": ["wtfs", "ok wtf"]},
+			{"pattern": "\\bugh\\b", "category": "generic", "severity": 1}
+		]
+	}`
+	p, err := parse([]byte(doc))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	fs := p.Verify()
+	if len(fs) != 1 || fs[0].ID != "drifted" {
+		t.Fatalf("expected only the drifted template to fail, got %+v", fs)
+	}
+	if !slices.Equal(fs[0].Missed, []string{"what the fuck"}) || !slices.Equal(fs[0].Matched, []string{"ok wtf"}) {
+		t.Fatalf("unexpected failure detail: %+v", fs[0])
+	}
+}
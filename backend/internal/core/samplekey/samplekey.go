@@ -0,0 +1,21 @@
+// Package samplekey derives the seeded sort key used for reproducible random sampling.
+// Rows are ordered by Key and the first N are the sample, so equal seeds over equal data
+// always pick the same rows in the same order. The key is computed in ClickHouse; Key is the
+// Go mirror so the guarantee can be checked without a database
+package samplekey
+
+import (
+	"strconv"
+
+	"github.com/go-faster/city"
+)
+
+// Key returns the sort key for id under seed; equal to cityHash64(concat(id, ':', toString(seed)))
+func Key(id string, seed uint64) uint64 {
+	return city.CH64([]byte(id + ":" + strconv.FormatUint(seed, 10)))
+}
+
+// SQL returns the ClickHouse expression computing Key over col with the seed bound to one ? arg
+func SQL(col string) string {
+	return "cityHash64(concat(toString(" + col + "), ':', toString(toUInt64(?))))"
+}
@@ -0,0 +1,59 @@
+package samplekey
+
+import (
+	"slices"
+	"testing"
+)
+
+var ids = []string{
+	"00000000-0000-0000-0000-000000000001",
+	"00000000-0000-0000-0000-000000000002",
+	"0b3f6a52-3c1e-4d8a-9a5e-6f1d2c3b4a59",
+	"7c9e6679-7425-40de-944b-e07fc1f90ae7",
+	"f47ac10b-58cc-4372-a567-0e02b2c3d479",
+}
+
+// sample mimics the query: order by key, then id, and keep the first n
+func sample(seed uint64, n int) []string {
+	out := slices.Clone(ids)
+	slices.SortFunc(out, func(a, b string) int {
+		ka, kb := Key(a, seed), Key(b, seed)
+		switch {
+		case ka < kb:
+			return -1
+		case ka > kb:
+			return 1
+		}
+		return 0
+	})
+	return out[:n]
+}
+
+func TestEqualSeedsEqualSamples(t *testing.T) {
+	for _, seed := range []uint64{0, 1, 42, 1<<64 - 1} {
+		a, b := sample(seed, 3), sample(seed, 3)
+		if !slices.Equal(a, b) {
+			t.Fatalf("seed %d: %v != %v", seed, a, b)
+		}
+	}
+}
+
+func TestSeedChangesOrder(t *testing.T) {
+	base := sample(1, len(ids))
+	for seed := uint64(2); seed < 50; seed++ {
+		if !slices.Equal(sample(seed, len(ids)), base) {
+			return
+		}
+	}
+	t.Fatal("49 seeds produced the same permutation")
+}
+
+func TestSQL(t *testing.T) {
+	want := "cityHash64(concat(toString(utterance_id), ':', toString(toUInt64(?))))"
+	if got := SQL("utterance_id"); got != want {
+		t.Fatalf("SQL = %q", got)
+	}
+	if Key("a", 1) == Key("a", 2) || Key("a", 1) != Key("a", 1) {
+		t.Fatal("Key must depend on seed and be stable")
+	}
+}
@@ -0,0 +1,35 @@
+// Package termid derives the stable uint64 term IDs shared by the write path (ClickHouse
+// archives) and the read path (API). IDs must agree everywhere, so this is the only place
+// the algorithm lives: ClickHouse cityHash64 (CityHash v1.0.2) over the ASCII-lowercased term
+package termid
+
+import (
+	"github.com/go-faster/city"
+)
+
+// Hash returns the term ID; equal to cityHash64(lower(term)) evaluated in ClickHouse
+func Hash(term string) uint64 {
+	return city.CH64([]byte(Canonical(term)))
+}
+
+// Canonical folds ASCII letters only, matching ClickHouse lower() (not lowerUTF8)
+// Detector terms are already casefolded, so this only matters for ad-hoc API input
+func Canonical(term string) string {
+	for i := 0; i < len(term); i++ {
+		if c := term[i]; 'A' <= c && c <= 'Z' {
+			b := []byte(term)
+			for j := i; j < len(b); j++ {
+				if 'A' <= b[j] && b[j] <= 'Z' {
+					b[j] += 'a' - 'A'
+				}
+			}
+			return string(b)
+		}
+	}
+	return term
+}
+
+// SQL returns the ClickHouse expression computing Hash over a String column
+func SQL(col string) string {
+	return "cityHash64(lower(" + col + "))"
+}
@@ -0,0 +1,35 @@
+package termid
+
+import "testing"
+
+// Pinned IDs; any change here orphans every term_id already stored in ClickHouse
+func TestHashPinned(t *testing.T) {
+	cases := []struct {
+		term string
+		want uint64
+	}{
+		{"", 11160318154034397263}, // SELECT cityHash64(lower(''))
+		{"fuck", 9732211836591299121},
+		{"shit", 330984939575324252},
+		{"dependabot", 4155459521516504780},
+		{"scheiße", 1963136306365600565},
+	}
+	for _, c := range cases {
+		if got := Hash(c.term); got != c.want {
+			t.Fatalf("Hash(%q) = %d, want %d", c.term, got, c.want)
+		}
+	}
+}
+
+func TestCanonicalIsASCIIOnly(t *testing.T) {
+	if Hash("FuCk") != Hash("fuck") {
+		t.Fatal("ASCII case should not change the ID")
+	}
+	// ClickHouse lower() leaves non-ASCII letters alone, so must we
+	if got := Canonical("ÄRGER Scheiße"); got != "Ärger scheiße" {
+		t.Fatalf("Canonical = %q", got)
+	}
+	if got := SQL("h.term"); got != "cityHash64(lower(h.term))" {
+		t.Fatalf("SQL = %q", got)
+	}
+}
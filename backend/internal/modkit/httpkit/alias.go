@@ -82,3 +82,9 @@ func Call(fn func(*http.Request) (any, error)) Handler {
 func Handle(fn func(*http.Request) Response) Handler {
 	return phttp.Handle(fn)
 }
+
+// ParseJSON decodes and validates a JSON body for handlers that write their own response (e.g., streams)
+func ParseJSON[T any](r *http.Request) (T, error) { return bind.ParseJSON[T](r) }
+
+// WriteError writes err as an error envelope; use before a streaming handler has written anything
+func WriteError(w http.ResponseWriter, r *http.Request, err error) { phttp.RespondError(w, r, err) }
@@ -41,3 +41,19 @@ func Auth(p middleware.AuthPort) func(http.Handler) http.Handler {
 	// use phttp.JSON which matches that signature
 	return middleware.Auth(p, phttp.JSON)
 }
+
+// RateLimitOptions configures RateLimit (see middleware.RateLimitOptions)
+type RateLimitOptions = middleware.RateLimitOptions
+
+// RateLimit wires the per-client rate limiter to the platform JSON writer
+func RateLimit(o RateLimitOptions) func(http.Handler) http.Handler {
+	return middleware.RateLimit(o, phttp.JSON)
+}
+
+// MaxInFlightOptions configures MaxInFlight (see middleware.MaxInFlightOptions)
+type MaxInFlightOptions = middleware.MaxInFlightOptions
+
+// MaxInFlight wires the global in-flight limiter to the platform JSON writer
+func MaxInFlight(o MaxInFlightOptions) func(http.Handler) http.Handler {
+	return middleware.MaxInFlight(o, phttp.JSON)
+}
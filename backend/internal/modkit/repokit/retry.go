@@ -0,0 +1,43 @@
+package repokit
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+
+	perr "swearjar/internal/platform/errors"
+)
+
+const (
+	retryBase = 50 * time.Millisecond
+	retryCap  = 2 * time.Second
+)
+
+// retryBackoff returns the sleep before the given retry (1-based), full jitter over the upper half
+// It is a var so tests can avoid real sleeps
+var retryBackoff = func(attempt int) time.Duration {
+	d := min(retryBase<<(attempt-1), retryCap)
+	return d/2 + time.Duration(rand.Int64N(int64(d/2)))
+}
+
+// TxRetry runs fn inside a transaction, retrying the whole tx when it fails with
+// a perr.Retryable error (serialization failure, deadlock, lock timeout)
+// fn must be safe to re-run: each attempt gets a fresh tx and any captured results
+// should be assigned only on success. maxAttempts < 1 is treated as 1
+func TxRetry(ctx context.Context, tx TxRunner, maxAttempts int, fn func(q Queryer) error) error {
+	maxAttempts = max(maxAttempts, 1)
+	var err error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		if err = tx.Tx(ctx, fn); err == nil || !perr.Retryable(err) || attempt == maxAttempts {
+			return err
+		}
+		t := time.NewTimer(retryBackoff(attempt))
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return err
+		case <-t.C:
+		}
+	}
+	return err
+}
@@ -0,0 +1,105 @@
+package repokit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// fakeRetryTx fails the first len(errs) Tx calls with the queued errors, then runs fn
+type fakeRetryTx struct {
+	fakeQHooks
+	errs  []error
+	calls int
+}
+
+func (f *fakeRetryTx) Tx(ctx context.Context, fn func(q Queryer) error) error {
+	f.calls++
+	if len(f.errs) > 0 {
+		err := f.errs[0]
+		f.errs = f.errs[1:]
+		return err
+	}
+	return fn(&f.fakeQHooks)
+}
+
+func noBackoff(t *testing.T) {
+	t.Helper()
+	prev := retryBackoff
+	retryBackoff = func(int) time.Duration { return 0 }
+	t.Cleanup(func() { retryBackoff = prev })
+}
+
+var errSerialization = &pgconn.PgError{Code: "40001", Message: "could not serialize access"}
+
+func TestTxRetry_RetriesRetryableThenSucceeds(t *testing.T) {
+	noBackoff(t)
+	f := &fakeRetryTx{errs: []error{errSerialization, &pgconn.PgError{Code: "40P01"}}}
+	ran := 0
+	err := TxRetry(context.Background(), f, 3, func(q Queryer) error { ran++; return nil })
+	if err != nil {
+		t.Fatalf("TxRetry: %v", err)
+	}
+	if f.calls != 3 || ran != 1 {
+		t.Fatalf("calls=%d ran=%d, want 3 and 1", f.calls, ran)
+	}
+}
+
+func TestTxRetry_StopsAtMaxAttempts(t *testing.T) {
+	noBackoff(t)
+	f := &fakeRetryTx{errs: []error{errSerialization, errSerialization, errSerialization}}
+	err := TxRetry(context.Background(), f, 2, func(Queryer) error { return nil })
+	if !errors.Is(err, errSerialization) {
+		t.Fatalf("want serialization error, got %v", err)
+	}
+	if f.calls != 2 {
+		t.Fatalf("calls=%d, want 2", f.calls)
+	}
+}
+
+func TestTxRetry_DoesNotRetryOtherErrors(t *testing.T) {
+	noBackoff(t)
+	boom := errors.New("boom")
+	f := &fakeRetryTx{}
+	err := TxRetry(context.Background(), f, 5, func(Queryer) error { return boom })
+	if !errors.Is(err, boom) || f.calls != 1 {
+		t.Fatalf("err=%v calls=%d, want boom after 1 call", err, f.calls)
+	}
+
+	f = &fakeRetryTx{errs: []error{context.DeadlineExceeded}}
+	if err := TxRetry(context.Background(), f, 5, func(Queryer) error { return nil }); f.calls != 1 || err == nil {
+		t.Fatalf("deadline should not retry: err=%v calls=%d", err, f.calls)
+	}
+}
+
+func TestTxRetry_ZeroAttemptsRunsOnce(t *testing.T) {
+	f := &fakeRetryTx{}
+	if err := TxRetry(context.Background(), f, 0, func(Queryer) error { return nil }); err != nil || f.calls != 1 {
+		t.Fatalf("err=%v calls=%d, want nil after 1 call", err, f.calls)
+	}
+}
+
+func TestTxRetry_CanceledContextStopsBackoff(t *testing.T) {
+	prev := retryBackoff
+	retryBackoff = func(int) time.Duration { return time.Hour }
+	t.Cleanup(func() { retryBackoff = prev })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	f := &fakeRetryTx{errs: []error{errSerialization, errSerialization}}
+	if err := TxRetry(ctx, f, 3, func(Queryer) error { return nil }); !errors.Is(err, errSerialization) || f.calls != 1 {
+		t.Fatalf("err=%v calls=%d, want serialization error after 1 call", err, f.calls)
+	}
+}
+
+func TestRetryBackoffBounds(t *testing.T) {
+	for attempt := 1; attempt <= 10; attempt++ {
+		d := retryBackoff(attempt)
+		if d < 0 || d > retryCap {
+			t.Fatalf("attempt %d: backoff %v out of [0, %v]", attempt, d, retryCap)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"swearjar/internal/platform/logger"
+)
+
+// Struct tags understood by Bind
+//
+//	env:"WORKERS"       env var name, relative to the Conf prefix
+//	default:"2"         value used when the env var is missing/empty
+//	required:"true"     error when both the env var and default are empty
+//	prefix:"DETECT_"    on nested structs, extends the prefix for their fields
+//
+// Supported field kinds: string, bool, int*, uint*, float*, time.Duration and
+// []string (comma-separated, same rules as MayCSV). Nested structs (and
+// pointers to structs) are walked recursively; untagged scalar fields are left alone
+const (
+	tagEnv      = "env"
+	tagDefault  = "default"
+	tagRequired = "required"
+	tagPrefix   = "prefix"
+)
+
+var durationType = reflect.TypeFor[time.Duration]()
+
+// Bind populates the struct pointed to by dst from the process environment
+// It is shorthand for New().Bind(dst)
+func Bind(dst any) error { return New().Bind(dst) }
+
+// Bind populates the struct pointed to by dst from env vars under this Conf's prefix
+// All field errors are collected and returned together so a bad deploy reports
+// every problem at once rather than one per restart
+func (c Conf) Bind(dst any) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("config: Bind requires a non-nil pointer to a struct, got %T", dst)
+	}
+	var errs []error
+	c.bindStruct(rv.Elem(), &errs)
+	return errors.Join(errs...)
+}
+
+// MustBind is Bind but panics (via the logger) on any error, matching the Must* helpers
+func (c Conf) MustBind(dst any) {
+	if err := c.Bind(dst); err != nil {
+		logger.Get().Panic().Err(err).Str("prefix", c.prefix).Msg("invalid config")
+	}
+}
+
+// bindStruct walks the exported fields of v, appending any failures to errs
+func (c Conf) bindStruct(v reflect.Value, errs *[]error) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		fv := v.Field(i)
+
+		name, tagged := sf.Tag.Lookup(tagEnv)
+		if !tagged {
+			// Recurse into nested config blocks
+			switch {
+			case sf.Type.Kind() == reflect.Struct:
+				c.Prefix(sf.Tag.Get(tagPrefix)).bindStruct(fv, errs)
+			case sf.Type.Kind() == reflect.Pointer && sf.Type.Elem().Kind() == reflect.Struct:
+				if fv.IsNil() {
+					fv.Set(reflect.New(sf.Type.Elem()))
+				}
+				c.Prefix(sf.Tag.Get(tagPrefix)).bindStruct(fv.Elem(), errs)
+			}
+			continue
+		}
+
+		key := c.key(name)
+		raw := strings.TrimSpace(os.Getenv(key))
+		if raw == "" {
+			raw = sf.Tag.Get(tagDefault)
+		}
+		if raw == "" {
+			if req, _ := strconv.ParseBool(sf.Tag.Get(tagRequired)); req {
+				*errs = append(*errs, fmt.Errorf("config: %s: missing required env", key))
+			}
+			continue
+		}
+		if err := setField(fv, raw); err != nil {
+			*errs = append(*errs, fmt.Errorf("config: %s=%q: %w", key, raw, err))
+		}
+	}
+}
+
+// setField parses s into fv according to its kind
+func setField(fv reflect.Value, s string) error {
+	if fv.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return errors.New("invalid duration (e.g., 250ms, 2s, 1h)")
+		}
+		fv.SetInt(int64(d))
+		return nil
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return errors.New("invalid bool value")
+		}
+		fv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, fv.Type().Bits())
+		if err != nil {
+			return errors.New("invalid int value")
+		}
+		fv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, fv.Type().Bits())
+		if err != nil {
+			return errors.New("invalid uint value")
+		}
+		fv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, fv.Type().Bits())
+		if err != nil {
+			return errors.New("invalid float value")
+		}
+		fv.SetFloat(f)
+	case reflect.Slice:
+		if fv.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported slice type %s", fv.Type())
+		}
+		parts := strings.Split(s, ",")
+		out := make([]string, 0, len(parts))
+		for _, p := range parts {
+			if p = strings.TrimSpace(p); p != "" {
+				out = append(out, p)
+			}
+		}
+		fv.Set(reflect.ValueOf(out).Convert(fv.Type()))
+	default:
+		return fmt.Errorf("unsupported field type %s", fv.Type())
+	}
+	return nil
+}
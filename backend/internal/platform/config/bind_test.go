@@ -0,0 +1,115 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	kit "swearjar/internal/platform/testkit"
+)
+
+type bindInner struct {
+	Host string `env:"HOST" default:"localhost"`
+	Port uint16 `env:"PORT" default:"8123"`
+}
+
+type bindTarget struct {
+	Name     string        `env:"NAME" required:"true"`
+	Workers  int           `env:"WORKERS" default:"2"`
+	Ratio    float64       `env:"RATIO" default:"0.5"`
+	DryRun   bool          `env:"DRY_RUN"`
+	Timeout  time.Duration `env:"TIMEOUT" default:"250ms"`
+	Langs    []string      `env:"LANGS" default:"en,de"`
+	CH       bindInner     `prefix:"CH_"`
+	PG       *bindInner    `prefix:"PG_"`
+	Untagged int
+	internal int `env:"INTERNAL"`
+}
+
+func TestBindDefaultsAndValues(t *testing.T) {
+	c := New().Prefix("BIND_")
+	t.Setenv("BIND_NAME", " swearjar ")
+	t.Setenv("BIND_DRY_RUN", "true")
+	t.Setenv("BIND_LANGS", " en , ,fr ")
+	t.Setenv("BIND_CH_PORT", "9000")
+	t.Setenv("BIND_PG_HOST", "db")
+	t.Setenv("BIND_INTERNAL", "7")
+
+	got := bindTarget{Untagged: 42}
+	if err := c.Bind(&got); err != nil {
+		t.Fatalf("Bind error: %v", err)
+	}
+
+	if got.Name != "swearjar" || got.Workers != 2 || got.Ratio != 0.5 || !got.DryRun {
+		t.Fatalf("scalars mismatch: %+v", got)
+	}
+	if got.Timeout != 250*time.Millisecond {
+		t.Fatalf("Timeout = %v, want 250ms", got.Timeout)
+	}
+	if len(got.Langs) != 2 || got.Langs[0] != "en" || got.Langs[1] != "fr" {
+		t.Fatalf("Langs = %#v", got.Langs)
+	}
+	if got.CH.Host != "localhost" || got.CH.Port != 9000 {
+		t.Fatalf("CH = %+v", got.CH)
+	}
+	if got.PG == nil || got.PG.Host != "db" || got.PG.Port != 8123 {
+		t.Fatalf("PG = %+v", got.PG)
+	}
+	if got.Untagged != 42 || got.internal != 0 {
+		t.Fatalf("untagged/unexported fields should be left alone: %+v", got)
+	}
+}
+
+func TestBindCollectsErrors(t *testing.T) {
+	c := New().Prefix("BERR_")
+	t.Setenv("BERR_WORKERS", "many")
+	t.Setenv("BERR_TIMEOUT", "soon")
+	t.Setenv("BERR_CH_PORT", "70000")
+
+	var got bindTarget
+	err := c.Bind(&got)
+	if err == nil {
+		t.Fatalf("expected error")
+	}
+	msg := err.Error()
+	for _, want := range []string{"BERR_NAME", "BERR_WORKERS", "BERR_TIMEOUT", "BERR_CH_PORT"} {
+		if !strings.Contains(msg, want) {
+			t.Fatalf("error %q missing %s", msg, want)
+		}
+	}
+}
+
+func TestBindRejectsNonStructPointer(t *testing.T) {
+	var n int
+	if err := Bind(&n); err == nil {
+		t.Fatalf("expected error for *int")
+	}
+	if err := Bind(bindTarget{}); err == nil {
+		t.Fatalf("expected error for non-pointer")
+	}
+	var nilp *bindTarget
+	if err := Bind(nilp); err == nil {
+		t.Fatalf("expected error for nil pointer")
+	}
+}
+
+func TestBindUnsupportedType(t *testing.T) {
+	type bad struct {
+		Vals []int `env:"VALS" default:"1,2"`
+	}
+	if err := New().Prefix("BUNS_").Bind(&bad{}); err == nil {
+		t.Fatalf("expected unsupported type error")
+	}
+}
+
+func TestMustBind(t *testing.T) {
+	c := New().Prefix("MB_")
+	kit.MustPanic(t, func() { c.MustBind(&bindTarget{}) })
+
+	t.Setenv("MB_NAME", "ok")
+	var got bindTarget
+	c.MustBind(&got)
+	if got.Name != "ok" {
+		t.Fatalf("MustBind Name = %q", got.Name)
+	}
+}
@@ -58,6 +58,9 @@ const (
 
 	// ErrorCodeLegal represents legal/DMCA style blocks (HTTP 451)
 	ErrorCodeLegal
+
+	// ErrorCodeTooLarge is for results too big to return; the caller should narrow the query (HTTP 413)
+	ErrorCodeTooLarge
 )
 
 // HTTPStatusCode turns an ErrorCode into an http status code
@@ -85,6 +88,8 @@ func HTTPStatusCode(c ErrorCode) int {
 		return http.StatusGone // 410
 	case ErrorCodeLegal:
 		return http.StatusUnavailableForLegalReasons // 451
+	case ErrorCodeTooLarge:
+		return http.StatusRequestEntityTooLarge // 413
 
 	default:
 		return http.StatusInternalServerError
@@ -25,6 +25,7 @@ func TestHTTPStatusCodeMapping(t *testing.T) {
 		{ErrorCodeDB, http.StatusInternalServerError},
 		{ErrorCodePanic, http.StatusInternalServerError},
 		{ErrorCodeUnknown, http.StatusInternalServerError},
+		{ErrorCodeTooLarge, http.StatusRequestEntityTooLarge},
 		{9999, http.StatusInternalServerError}, // default branch
 	}
 	for _, c := range cases {
@@ -95,6 +95,12 @@ func RegisterValidation(tag string, fn validator.Func) error {
 	return Get().Validator.RegisterValidation(tag, fn)
 }
 
+// Canonicalizer is implemented by payloads that tidy their own fields (trim, lowercase, dedupe)
+// after decoding and before validation; an error is reported as a validation failure
+type Canonicalizer interface {
+	Canonicalize() error
+}
+
 // JSONOptions controls parsing behavior
 type JSONOptions struct {
 	MaxBytes        int64 // default 1MB
@@ -168,6 +174,15 @@ func ParseJSON[T any](r *http.Request, opts ...JSONOptions) (T, error) {
 		return zero, perr.JSONErrf("unexpected trailing data")
 	}
 
+	if c, ok := any(&dst).(Canonicalizer); ok {
+		if err := c.Canonicalize(); err != nil {
+			if _, ok := perr.As(err); ok {
+				return zero, err
+			}
+			return zero, perr.Newf(perr.ErrorCodeValidation, "%s", err.Error())
+		}
+	}
+
 	if err := Get().Validator.Struct(dst); err != nil {
 		if inv, ok := err.(*validator.InvalidValidationError); ok {
 			log := logger.Get()
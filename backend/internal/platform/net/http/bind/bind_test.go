@@ -322,3 +322,35 @@ func TestRegisterValidation_DuplicateTag_Overwrites(t *testing.T) {
 		t.Fatalf("expected validation to pass after overwrite, got %v", err)
 	}
 }
+
+type canonPayload struct {
+	Tags []string `json:"tags" validate:"dive,alpha"`
+}
+
+func (p *canonPayload) Canonicalize() error {
+	for i, t := range p.Tags {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t == "" {
+			return errors.New("tags must not be blank")
+		}
+		p.Tags[i] = t
+	}
+	return nil
+}
+
+func TestParseJSON_Canonicalize(t *testing.T) {
+	req := httptest.NewRequest("POST", "/", strings.NewReader(`{"tags":[" Foo ","BAR"]}`))
+	got, err := ParseJSON[canonPayload](req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Tags[0] != "foo" || got.Tags[1] != "bar" {
+		t.Fatalf("not canonicalized: %+v", got)
+	}
+
+	req = httptest.NewRequest("POST", "/", strings.NewReader(`{"tags":["  "]}`))
+	_, err = ParseJSON[canonPayload](req)
+	if perr.CodeOf(err) != perr.ErrorCodeValidation || !strings.Contains(err.Error(), "blank") {
+		t.Fatalf("expected validation error from Canonicalize, got %v (%v)", perr.CodeOf(err), err)
+	}
+}
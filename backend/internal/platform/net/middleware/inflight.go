@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	perr "swearjar/internal/platform/errors"
+	pnet "swearjar/internal/platform/net"
+)
+
+// MaxInFlightOptions configures global admission control
+type MaxInFlightOptions struct {
+	// Limit is the number of requests served at once (<= 0 disables limiting)
+	Limit int
+	// Queue is how many requests may wait for a slot; beyond it requests are refused at once
+	Queue int
+	// Wait bounds how long a queued request waits for a slot (default 2s)
+	Wait time.Duration
+	// Bypass exempts requests from the limit (default: paths ending in /health or /ready)
+	Bypass func(r *http.Request) bool
+}
+
+// MaxInFlight caps concurrently served requests across all clients, protecting the backing
+// stores under a spike. Up to Queue requests wait (at most Wait) for a slot; the rest get a
+// fast 503 with Retry-After. Unlike RateLimit it is not per client
+func MaxInFlight(
+	o MaxInFlightOptions,
+	write func(w http.ResponseWriter, status int, body any),
+) func(http.Handler) http.Handler {
+	if o.Limit <= 0 {
+		return func(next http.Handler) http.Handler { return next }
+	}
+	if o.Queue < 0 {
+		o.Queue = 0
+	}
+	if o.Wait <= 0 {
+		o.Wait = 2 * time.Second
+	}
+	if o.Bypass == nil {
+		o.Bypass = healthPath
+	}
+
+	slots := make(chan struct{}, o.Limit)
+	var waiting atomic.Int64
+
+	refuse := func(w http.ResponseWriter, r *http.Request, msg string) {
+		w.Header().Set("Retry-After", "1")
+		status, body := pnet.Error(
+			perr.New(perr.ErrorCodeUnavailable, msg),
+			pnet.RequestID(r.Context()),
+		)
+		write(w, status, body)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if o.Bypass(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			select {
+			case slots <- struct{}{}:
+			default:
+				// full: join the queue if there's room, else shed the request now
+				if waiting.Add(1) > int64(o.Queue) {
+					waiting.Add(-1)
+					refuse(w, r, "server busy; too many requests in flight")
+					return
+				}
+				t := time.NewTimer(o.Wait)
+				select {
+				case slots <- struct{}{}:
+					t.Stop()
+					waiting.Add(-1)
+				case <-t.C:
+					waiting.Add(-1)
+					refuse(w, r, "server busy; timed out waiting for a request slot")
+					return
+				case <-r.Context().Done():
+					t.Stop()
+					waiting.Add(-1)
+					return // client gone
+				}
+			}
+			defer func() { <-slots }()
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// healthPath matches liveness/readiness probes, which must answer even when the API is saturated
+func healthPath(r *http.Request) bool {
+	p := strings.TrimSuffix(r.URL.Path, "/")
+	return strings.HasSuffix(p, "/health") || strings.HasSuffix(p, "/ready")
+}
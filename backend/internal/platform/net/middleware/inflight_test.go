@@ -0,0 +1,149 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"swearjar/internal/platform/net/middleware"
+)
+
+// blocking serves 200 once release is closed, signalling entered as each request starts
+func blocking(entered chan<- struct{}, release <-chan struct{}) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		entered <- struct{}{}
+		<-release
+		w.WriteHeader(200)
+	})
+}
+
+func serve(h http.Handler, path string) *httptest.ResponseRecorder {
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+	return rr
+}
+
+func TestMaxInFlight_ShedsWhenQueueFull(t *testing.T) {
+	entered, release := make(chan struct{}, 4), make(chan struct{})
+	h := middleware.MaxInFlight(middleware.MaxInFlightOptions{Limit: 1}, writeStub)(blocking(entered, release))
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() { defer wg.Done(); serve(h, "/api/v1/x") }()
+	<-entered
+
+	rr := serve(h, "/api/v1/x")
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Fatalf("over limit: got %d, want 503", rr.Code)
+	}
+	if got := rr.Header().Get("Retry-After"); got != "1" {
+		t.Fatalf("Retry-After = %q, want 1", got)
+	}
+
+	close(release)
+	wg.Wait()
+	if rr := serve(h, "/api/v1/x"); rr.Code != 200 {
+		t.Fatalf("after release: got %d, want 200", rr.Code)
+	}
+}
+
+func TestMaxInFlight_QueuedRequestGetsFreedSlot(t *testing.T) {
+	entered, release := make(chan struct{}, 4), make(chan struct{})
+	h := middleware.MaxInFlight(middleware.MaxInFlightOptions{Limit: 1, Queue: 1, Wait: 5 * time.Second},
+		writeStub)(blocking(entered, release))
+
+	codes := make(chan int, 3)
+	go func() { codes <- serve(h, "/api/v1/x").Code }()
+	<-entered
+
+	// of the next two, one queues and the other finds the queue full
+	for range 2 {
+		go func() { codes <- serve(h, "/api/v1/x").Code }()
+	}
+	select {
+	case code := <-codes:
+		if code != http.StatusServiceUnavailable {
+			t.Fatalf("over queue: got %d, want 503", code)
+		}
+	case <-entered:
+		t.Fatal("a request was admitted past the limit")
+	case <-time.After(2 * time.Second):
+		t.Fatal("no request refused")
+	}
+
+	close(release)
+	<-entered // the queued request took the freed slot
+	for range 2 {
+		if code := <-codes; code != 200 {
+			t.Fatalf("got %d, want 200", code)
+		}
+	}
+}
+
+func TestMaxInFlight_QueueWaitTimesOut(t *testing.T) {
+	entered, release := make(chan struct{}, 4), make(chan struct{})
+	defer close(release)
+	h := middleware.MaxInFlight(middleware.MaxInFlightOptions{Limit: 1, Queue: 1, Wait: 20 * time.Millisecond},
+		writeStub)(blocking(entered, release))
+
+	go serve(h, "/api/v1/x")
+	<-entered
+
+	if rr := serve(h, "/api/v1/x"); rr.Code != http.StatusServiceUnavailable {
+		t.Fatalf("queued past Wait: got %d, want 503", rr.Code)
+	}
+}
+
+func TestMaxInFlight_CancelledWhileQueuedWritesNothing(t *testing.T) {
+	entered, release := make(chan struct{}, 4), make(chan struct{})
+	defer close(release)
+	h := middleware.MaxInFlight(middleware.MaxInFlightOptions{Limit: 1, Queue: 1, Wait: time.Minute},
+		writeStub)(blocking(entered, release))
+
+	go serve(h, "/api/v1/x")
+	<-entered
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/x", nil).WithContext(ctx))
+	if rr.Code != 200 || rr.Body.Len() != 0 || rr.Header().Get("Retry-After") != "" {
+		t.Fatalf("cancelled request should get no reply, got %d %q", rr.Code, rr.Body.String())
+	}
+}
+
+func TestMaxInFlight_HealthBypassesAndDisabledPassesThrough(t *testing.T) {
+	entered, release := make(chan struct{}, 4), make(chan struct{})
+	defer close(release)
+
+	probes := 0
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/x" {
+			blocking(entered, release).ServeHTTP(w, r)
+			return
+		}
+		probes++
+		w.WriteHeader(200)
+	})
+	h := middleware.MaxInFlight(middleware.MaxInFlightOptions{Limit: 1}, writeStub)(next)
+
+	go serve(h, "/api/v1/x")
+	<-entered
+
+	for _, p := range []string{"/api/v1/meta/health", "/api/v1/meta/ready/"} {
+		if rr := serve(h, p); rr.Code != 200 {
+			t.Fatalf("%s while saturated: got %d, want 200", p, rr.Code)
+		}
+	}
+	if probes != 2 {
+		t.Fatalf("probes reached next %d times, want 2", probes)
+	}
+
+	off := middleware.MaxInFlight(middleware.MaxInFlightOptions{}, writeStub)(next)
+	if rr := serve(off, "/api/v1/meta/health"); rr.Code != 200 {
+		t.Fatalf("disabled: got %d, want 200", rr.Code)
+	}
+}
@@ -0,0 +1,127 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	perr "swearjar/internal/platform/errors"
+	pnet "swearjar/internal/platform/net"
+)
+
+// RateLimitOptions configures a per-client token bucket
+type RateLimitOptions struct {
+	// Rate is the sustained number of requests allowed per Per (<= 0 disables limiting)
+	Rate int
+	// Per is the refill window for Rate (default 1m)
+	Per time.Duration
+	// Burst is the bucket size (default Rate)
+	Burst int
+	// Key picks the client identity (default: RemoteAddr host; pair with RealIP)
+	Key func(r *http.Request) string
+	// Now is the clock (tests)
+	Now func() time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+type limiter struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	rate    float64 // tokens per second
+	burst   float64
+	now     func() time.Time
+}
+
+// maxIdleBuckets triggers a sweep of full buckets once the map grows past it
+const maxIdleBuckets = 10000
+
+// take refills the client's bucket and consumes one token; on refusal it returns the wait
+func (l *limiter) take(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	b := l.buckets[key]
+	if b == nil {
+		if len(l.buckets) >= maxIdleBuckets {
+			l.sweep(now)
+		}
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	return false, time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+}
+
+// sweep drops buckets that would be full by now (indistinguishable from new clients)
+func (l *limiter) sweep(now time.Time) {
+	for k, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, k)
+		}
+	}
+}
+
+// RateLimit limits requests per client with a token bucket and writes 429 with Retry-After when exceeded
+func RateLimit(
+	o RateLimitOptions,
+	write func(w http.ResponseWriter, status int, body any),
+) func(http.Handler) http.Handler {
+	if o.Rate <= 0 {
+		return func(next http.Handler) http.Handler { return next }
+	}
+	if o.Per <= 0 {
+		o.Per = time.Minute
+	}
+	if o.Burst <= 0 {
+		o.Burst = o.Rate
+	}
+	if o.Key == nil {
+		o.Key = clientIP
+	}
+	if o.Now == nil {
+		o.Now = time.Now
+	}
+	l := &limiter{
+		buckets: map[string]*bucket{},
+		rate:    float64(o.Rate) / o.Per.Seconds(),
+		burst:   float64(o.Burst),
+		now:     o.Now,
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ok, wait := l.take(o.Key(r))
+			if !ok {
+				secs := max(1, int(wait.Round(time.Second)/time.Second))
+				w.Header().Set("Retry-After", strconv.Itoa(secs))
+				status, body := pnet.Error(
+					perr.Newf(perr.ErrorCodeTooManyRequests, "rate limit exceeded; retry in %ds", secs),
+					pnet.RequestID(r.Context()),
+				)
+				write(w, status, body)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// clientIP returns the host part of RemoteAddr (RealIP rewrites it from proxy headers)
+func clientIP(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
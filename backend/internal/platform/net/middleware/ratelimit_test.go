@@ -0,0 +1,67 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"swearjar/internal/platform/net/middleware"
+)
+
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+func doReq(h http.Handler, ip string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	req.RemoteAddr = ip + ":1234"
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestRateLimit_BurstThenRefill(t *testing.T) {
+	clk := &fakeClock{t: time.Unix(1_700_000_000, 0)}
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(200) })
+	h := middleware.RateLimit(middleware.RateLimitOptions{Rate: 2, Per: time.Minute, Now: clk.now}, writeStub)(next)
+
+	for i := range 2 {
+		if rr := doReq(h, "10.0.0.1"); rr.Code != 200 {
+			t.Fatalf("request %d: got %d, want 200", i, rr.Code)
+		}
+	}
+	rr := doReq(h, "10.0.0.1")
+	if rr.Code != http.StatusTooManyRequests {
+		t.Fatalf("over burst: got %d, want 429", rr.Code)
+	}
+	if got := rr.Header().Get("Retry-After"); got != "30" {
+		t.Fatalf("Retry-After = %q, want 30", got)
+	}
+
+	// other clients have their own bucket
+	if rr := doReq(h, "10.0.0.2"); rr.Code != 200 {
+		t.Fatalf("second client: got %d, want 200", rr.Code)
+	}
+
+	// one token refills after Per/Rate
+	clk.t = clk.t.Add(30 * time.Second)
+	if rr := doReq(h, "10.0.0.1"); rr.Code != 200 {
+		t.Fatalf("after refill: got %d, want 200", rr.Code)
+	}
+	if rr := doReq(h, "10.0.0.1"); rr.Code != http.StatusTooManyRequests {
+		t.Fatalf("after refill spent: got %d, want 429", rr.Code)
+	}
+}
+
+func TestRateLimit_DisabledPassesThrough(t *testing.T) {
+	calls := 0
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { calls++ })
+	h := middleware.RateLimit(middleware.RateLimitOptions{}, writeStub)(next)
+	for range 50 {
+		doReq(h, "10.0.0.1")
+	}
+	if calls != 50 {
+		t.Fatalf("calls = %d, want 50", calls)
+	}
+}
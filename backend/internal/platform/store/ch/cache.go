@@ -0,0 +1,217 @@
+package ch
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+)
+
+// QueryCache defaults; a zero TTL leaves caching off
+const (
+	defaultQueryCacheEntries = 512
+	defaultQueryCacheMaxRows = 10000
+)
+
+type noQueryCacheKey struct{}
+
+type refreshQueryCacheKey struct{}
+
+// WithoutQueryCache marks ctx so Query always hits the server (e.g., read-after-write checks)
+func WithoutQueryCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noQueryCacheKey{}, true)
+}
+
+// WithQueryCacheRefresh marks ctx so Query skips cached entries yet still records the result,
+// replacing any entry with a fresh TTL; a pre-warmer renews entries this way before they lapse
+func WithQueryCacheRefresh(ctx context.Context) context.Context {
+	return context.WithValue(ctx, refreshQueryCacheKey{}, true)
+}
+
+// refreshing reports whether ctx asks to bypass cache reads (see WithQueryCacheRefresh)
+func refreshing(ctx context.Context) bool {
+	v, _ := ctx.Value(refreshQueryCacheKey{}).(bool)
+	return v
+}
+
+// queryCache is a TTL'd LRU of fully read result sets keyed by normalized SQL + args
+type queryCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	max     int
+	maxRows int
+	ll      *list.List // front = most recently used
+	items   map[string]*list.Element
+	now     func() time.Time
+}
+
+type cacheEntry struct {
+	key     string
+	cols    []string
+	rows    [][]reflect.Value
+	expires time.Time
+}
+
+func newQueryCache(ttl time.Duration, maxEntries, maxRows int) *queryCache {
+	if ttl <= 0 {
+		return nil
+	}
+	if maxEntries <= 0 {
+		maxEntries = defaultQueryCacheEntries
+	}
+	if maxRows <= 0 {
+		maxRows = defaultQueryCacheMaxRows
+	}
+	return &queryCache{
+		ttl:     ttl,
+		max:     maxEntries,
+		maxRows: maxRows,
+		ll:      list.New(),
+		items:   map[string]*list.Element{},
+		now:     time.Now,
+	}
+}
+
+func (c *queryCache) get(key string) (*cacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	e := el.Value.(*cacheEntry)
+	if !c.now().Before(e.expires) {
+		c.ll.Remove(el)
+		delete(c.items, key)
+		return nil, false
+	}
+	c.ll.MoveToFront(el)
+	return e, true
+}
+
+func (c *queryCache) put(e *cacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e.expires = c.now().Add(c.ttl)
+	if el, ok := c.items[e.key]; ok {
+		el.Value = e
+		c.ll.MoveToFront(el)
+		return
+	}
+	c.items[e.key] = c.ll.PushFront(e)
+	for c.ll.Len() > c.max {
+		last := c.ll.Back()
+		c.ll.Remove(last)
+		delete(c.items, last.Value.(*cacheEntry).key)
+	}
+}
+
+func (c *queryCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ll.Len()
+}
+
+// cacheable reports whether a statement is a read that may be served from cache
+func cacheable(ctx context.Context, sql string) bool {
+	if v, _ := ctx.Value(noQueryCacheKey{}).(bool); v {
+		return false
+	}
+	head := strings.ToUpper(strings.TrimLeft(sql, " \t\r\n("))
+	return strings.HasPrefix(head, "SELECT") || strings.HasPrefix(head, "WITH")
+}
+
+// cacheKey hashes whitespace-normalized SQL with the full Go-syntax args so filtered
+// queries never collide (time.Time and slices render every field/element)
+func cacheKey(sql string, args []any) string {
+	h := sha256.New()
+	h.Write([]byte(strings.Join(strings.Fields(sql), " ")))
+	for _, a := range args {
+		fmt.Fprintf(h, "\x00%T=%#v", a, a)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// recordingRows streams rows to the caller while keeping a copy for the cache
+// Values are scanned into the driver's ScanType and assigned to the caller's dest
+type recordingRows struct {
+	r       driver.Rows
+	cache   *queryCache
+	key     string
+	cols    []string
+	types   []reflect.Type
+	cur     []reflect.Value
+	rec     [][]reflect.Value
+	skip    bool // too many rows or a scan failed; don't cache
+	scanErr error
+}
+
+func newRecordingRows(r driver.Rows, c *queryCache, key string) *recordingRows {
+	return &recordingRows{r: r, cache: c, key: key, cols: r.Columns(), types: scanTypes(r)}
+}
+
+func (r *recordingRows) Next() bool {
+	if !r.r.Next() {
+		if !r.skip && r.r.Err() == nil {
+			r.cache.put(&cacheEntry{key: r.key, cols: r.cols, rows: r.rec})
+		}
+		r.skip = true // store at most once
+		return false
+	}
+	r.cur, r.scanErr = scanHolders(r.r, r.types)
+	if r.scanErr != nil {
+		r.skip, r.rec = true, nil
+		return true
+	}
+	if !r.skip {
+		if len(r.rec) >= r.cache.maxRows {
+			r.skip, r.rec = true, nil
+		} else {
+			r.rec = append(r.rec, r.cur)
+		}
+	}
+	return true
+}
+
+func (r *recordingRows) Scan(dest ...any) error {
+	if r.scanErr != nil {
+		return r.scanErr
+	}
+	return assignRow(r.cols, r.cur, dest)
+}
+
+func (r *recordingRows) Err() error        { return r.r.Err() }
+func (r *recordingRows) Close() error      { return r.r.Close() }
+func (r *recordingRows) Columns() []string { return r.r.Columns() }
+
+// cachedRows replays a cache entry
+type cachedRows struct {
+	e *cacheEntry
+	i int
+}
+
+func (r *cachedRows) Next() bool {
+	if r.i >= len(r.e.rows) {
+		return false
+	}
+	r.i++
+	return true
+}
+
+func (r *cachedRows) Scan(dest ...any) error {
+	if r.i == 0 || r.i > len(r.e.rows) {
+		return fmt.Errorf("ch: scan called without a current row")
+	}
+	return assignRow(r.e.cols, r.e.rows[r.i-1], dest)
+}
+
+func (r *cachedRows) Err() error        { return nil }
+func (r *cachedRows) Close() error      { return nil }
+func (r *cachedRows) Columns() []string { return r.e.cols }
@@ -0,0 +1,270 @@
+package ch
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+)
+
+type fakeColumn struct {
+	name string
+	typ  reflect.Type
+}
+
+func (c fakeColumn) Name() string             { return c.name }
+func (c fakeColumn) Nullable() bool           { return c.typ.Kind() == reflect.Pointer }
+func (c fakeColumn) ScanType() reflect.Type   { return c.typ }
+func (c fakeColumn) DatabaseTypeName() string { return c.typ.String() }
+
+// fakeRows serves fixed rows the way the driver does: Scan into typed pointers
+type fakeRows struct {
+	cols []fakeColumn
+	data [][]any
+	i    int
+	err  error
+}
+
+func (r *fakeRows) Next() bool {
+	if r.i >= len(r.data) {
+		return false
+	}
+	r.i++
+	return true
+}
+
+func (r *fakeRows) Scan(dest ...any) error {
+	for i, d := range dest {
+		dv := reflect.ValueOf(d).Elem()
+		v := reflect.ValueOf(r.data[r.i-1][i])
+		if !v.Type().AssignableTo(dv.Type()) {
+			return fmt.Errorf("fake: cannot scan %s into %s", v.Type(), dv.Type())
+		}
+		dv.Set(v)
+	}
+	return nil
+}
+
+func (r *fakeRows) ScanStruct(any) error { return fmt.Errorf("fake: not implemented") }
+func (r *fakeRows) ColumnTypes() []driver.ColumnType {
+	out := make([]driver.ColumnType, len(r.cols))
+	for i, c := range r.cols {
+		out[i] = c
+	}
+	return out
+}
+func (r *fakeRows) Totals(...any) error { return nil }
+func (r *fakeRows) Columns() []string {
+	out := make([]string, len(r.cols))
+	for i, c := range r.cols {
+		out[i] = c.name
+	}
+	return out
+}
+func (r *fakeRows) Close() error { return nil }
+func (r *fakeRows) Err() error   { return r.err }
+
+func termRows() *fakeRows {
+	return &fakeRows{
+		cols: []fakeColumn{
+			{"term", reflect.TypeFor[string]()},
+			{"hits", reflect.TypeFor[uint64]()},
+			{"repos", reflect.TypeFor[[]string]()},
+		},
+		data: [][]any{
+			{"shit", uint64(7), []string{"a/b", "c/d"}},
+			{"damn", uint64(3), []string{"e/f"}},
+		},
+	}
+}
+
+type termRow struct {
+	term  string
+	hits  int64 // differs from the UInt64 scan type on purpose
+	repos []string
+}
+
+func drain(t *testing.T, r Rows) []termRow {
+	t.Helper()
+	var out []termRow
+	for r.Next() {
+		var tr termRow
+		if err := r.Scan(&tr.term, &tr.hits, &tr.repos); err != nil {
+			t.Fatalf("scan: %v", err)
+		}
+		out = append(out, tr)
+	}
+	if err := r.Err(); err != nil {
+		t.Fatalf("rows err: %v", err)
+	}
+	return out
+}
+
+func TestQueryCache_RecordThenReplay(t *testing.T) {
+	c := newQueryCache(time.Minute, 8, 100)
+	live := drain(t, newRecordingRows(termRows(), c, "k"))
+
+	e, ok := c.get("k")
+	if !ok {
+		t.Fatal("fully read result was not cached")
+	}
+	replayed := drain(t, &cachedRows{e: e})
+	if !reflect.DeepEqual(live, replayed) {
+		t.Fatalf("replay mismatch:\n live %+v\n replay %+v", live, replayed)
+	}
+	if len(replayed) != 2 || replayed[0].hits != 7 || replayed[0].repos[1] != "c/d" {
+		t.Fatalf("unexpected rows %+v", replayed)
+	}
+
+	// callers mutating scanned slices must not corrupt the cached copy
+	replayed[0].repos[0] = "mutated"
+	again := drain(t, &cachedRows{e: e})
+	if again[0].repos[0] != "a/b" {
+		t.Fatalf("cache entry mutated through caller slice: %v", again[0].repos)
+	}
+}
+
+func TestQueryCache_SkipsPartialAndOversizedResults(t *testing.T) {
+	c := newQueryCache(time.Minute, 8, 1)
+	drain(t, newRecordingRows(termRows(), c, "big"))
+	if _, ok := c.get("big"); ok {
+		t.Fatal("result over maxRows was cached")
+	}
+
+	c = newQueryCache(time.Minute, 8, 100)
+	r := newRecordingRows(termRows(), c, "early")
+	r.Next()
+	_ = r.Close()
+	if _, ok := c.get("early"); ok {
+		t.Fatal("partially read result was cached")
+	}
+
+	failing := termRows()
+	failing.err = fmt.Errorf("boom")
+	r = newRecordingRows(failing, c, "err")
+	for r.Next() {
+	}
+	if _, ok := c.get("err"); ok {
+		t.Fatal("result ending in an error was cached")
+	}
+}
+
+func TestQueryCache_TTLExpiry(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	c := newQueryCache(30*time.Second, 8, 100)
+	c.now = func() time.Time { return now }
+
+	c.put(&cacheEntry{key: "k"})
+	now = now.Add(29 * time.Second)
+	if _, ok := c.get("k"); !ok {
+		t.Fatal("entry expired before TTL")
+	}
+	now = now.Add(time.Second)
+	if _, ok := c.get("k"); ok {
+		t.Fatal("entry served after TTL")
+	}
+	if c.len() != 0 {
+		t.Fatalf("expired entry not evicted, len=%d", c.len())
+	}
+}
+
+func TestQueryCache_LRUEviction(t *testing.T) {
+	c := newQueryCache(time.Minute, 2, 100)
+	c.put(&cacheEntry{key: "a"})
+	c.put(&cacheEntry{key: "b"})
+	c.get("a") // a is now most recent
+	c.put(&cacheEntry{key: "c"})
+
+	if _, ok := c.get("b"); ok {
+		t.Fatal("least recently used entry survived eviction")
+	}
+	for _, k := range []string{"a", "c"} {
+		if _, ok := c.get(k); !ok {
+			t.Fatalf("entry %q evicted", k)
+		}
+	}
+	if c.len() != 2 {
+		t.Fatalf("len = %d, want 2", c.len())
+	}
+}
+
+func TestCacheKey_IncludesArgsAndNormalizesSQL(t *testing.T) {
+	sql := "SELECT term, count() FROM hits\n\tWHERE created_at >= ? AND repo_hid = ?"
+	day := time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC)
+
+	base := cacheKey(sql, []any{day, []byte{1, 2}})
+	if got := cacheKey("SELECT term, count() FROM hits WHERE created_at >= ?  AND repo_hid = ?",
+		[]any{day, []byte{1, 2}}); got != base {
+		t.Fatal("whitespace-only SQL differences changed the key")
+	}
+	for name, args := range map[string][]any{
+		"different time":  {day.Add(time.Hour), []byte{1, 2}},
+		"different bytes": {day, []byte{1, 3}},
+		"missing arg":     {day},
+		"string vs bytes": {day, "\x01\x02"},
+	} {
+		if cacheKey(sql, args) == base {
+			t.Fatalf("%s: key collided", name)
+		}
+	}
+	if cacheKey("SELECT ?", []any{1}) == cacheKey("SELECT ?", []any{int64(1)}) {
+		t.Fatal("args of different types collided")
+	}
+}
+
+func TestCacheable(t *testing.T) {
+	ctx := context.Background()
+	for sql, want := range map[string]bool{
+		"SELECT 1":                               true,
+		"  with x AS (SELECT 1) SELECT * FROM x": true,
+		"(SELECT 1) UNION ALL (SELECT 2)":        true,
+		"INSERT INTO hits SELECT * FROM tmp":     false,
+		"ALTER TABLE hits DELETE WHERE 1":        false,
+		"OPTIMIZE TABLE hits FINAL":              false,
+	} {
+		if got := cacheable(ctx, sql); got != want {
+			t.Fatalf("cacheable(%q) = %v, want %v", sql, got, want)
+		}
+	}
+	if cacheable(WithoutQueryCache(ctx), "SELECT 1") {
+		t.Fatal("WithoutQueryCache did not bypass the cache")
+	}
+
+	// a refresh still records, it only skips the lookup
+	rctx := WithQueryCacheRefresh(ctx)
+	if !cacheable(rctx, "SELECT 1") || !refreshing(rctx) || refreshing(ctx) {
+		t.Fatal("WithQueryCacheRefresh should keep reads cacheable and mark only its ctx")
+	}
+}
+
+func TestAssignValue_NullableAndMismatch(t *testing.T) {
+	s := "x"
+	var plain string
+	if err := assignValue(&plain, reflect.ValueOf(&s)); err != nil || plain != "x" {
+		t.Fatalf("nullable -> plain: %q, %v", plain, err)
+	}
+	var nilStr *string
+	if err := assignValue(&plain, reflect.ValueOf(nilStr)); err == nil {
+		t.Fatal("NULL into plain string should fail")
+	}
+	var n int
+	if err := assignValue(&n, reflect.ValueOf("7")); err == nil {
+		t.Fatal("string -> int conversion should fail")
+	}
+	if err := assignValue(n, reflect.ValueOf(1)); err == nil {
+		t.Fatal("non-pointer destination should fail")
+	}
+}
+
+func TestNewQueryCache_DisabledWithoutTTL(t *testing.T) {
+	if newQueryCache(0, 10, 10) != nil {
+		t.Fatal("zero TTL should disable the cache")
+	}
+	c := newQueryCache(time.Second, 0, 0)
+	if c.max != defaultQueryCacheEntries || c.maxRows != defaultQueryCacheMaxRows {
+		t.Fatalf("defaults not applied: %+v", c)
+	}
+}
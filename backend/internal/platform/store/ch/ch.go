@@ -10,10 +10,11 @@ import (
 	"net"
 	"runtime/debug"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ClickHouse/clickhouse-go/v2"
-	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
 
 	"swearjar/internal/platform/logger"
 )
@@ -26,6 +27,8 @@ type QueryEvent struct {
 	Err       error
 	Slow      bool
 	Op        string // "query" or "insert"
+	Async     bool   // insert was sent with async_insert=1 (server-side buffering)
+	Cached    bool   // query was answered from the in-process result cache
 }
 
 // QueryTracer receives CH events (mirrors pg.QueryTracer)
@@ -35,11 +38,19 @@ type QueryTracer interface {
 
 // Tracer returns a logger-backed tracer (same idea as pg.Tracer)
 func Tracer(root logger.Logger) QueryTracer {
+	return TracerWithRedaction(root, Redaction{})
+}
+
+// TracerWithRedaction is Tracer with query args masked per the policy before they are logged
+func TracerWithRedaction(root logger.Logger, r Redaction) QueryTracer {
 	ll := root.With().Str("component", "ch").Logger()
-	return &zlTracer{log: ll}
+	return &zlTracer{log: ll, redact: r}
 }
 
-type zlTracer struct{ log logger.Logger }
+type zlTracer struct {
+	log    logger.Logger
+	redact Redaction
+}
 
 func (z *zlTracer) OnQuery(_ context.Context, ev QueryEvent) {
 	const (
@@ -52,8 +63,8 @@ func (z *zlTracer) OnQuery(_ context.Context, ev QueryEvent) {
 		sql = sql[:maxSQL] + "..."
 	}
 
-	args := ev.Args
-	switch v := ev.Args.(type) {
+	args := z.redact.args(ev.Args)
+	switch v := args.(type) {
 	case string:
 		if len(v) > maxArgs {
 			args = v[:maxArgs] + "..."
@@ -76,6 +87,8 @@ func (z *zlTracer) OnQuery(_ context.Context, ev QueryEvent) {
 		Str("op", ev.Op).
 		Float64("elapsed_ms", float64(ev.ElapsedUS)/1000.0).
 		Bool("slow", ev.Slow).
+		Bool("async", ev.Async).
+		Bool("cached", ev.Cached).
 		Str("sql", sql).
 		Interface("args", args).
 		Err(ev.Err)
@@ -110,6 +123,40 @@ type Config struct {
 	MaxRetries  int
 	RetryBase   time.Duration
 
+	// AsyncInsertTables lists tables ("hits" or "swearjar.hits") whose inserts use
+	// async_insert=1: the server buffers rows and flushes them in the background
+	AsyncInsertTables []string
+	// AsyncInsertWait sets wait_for_async_insert; false acks before the buffer is flushed,
+	// so a crash or flush error can silently drop rows and reads may lag the write
+	AsyncInsertWait bool
+
+	// InsertSettings tunes inserts per table as "table:setting=value" entries (e.g.,
+	// "hits:max_insert_block_size=1048576"), matched like AsyncInsertTables. Values go to the
+	// server as strings; WithInsertSettings on a call's ctx overrides them
+	InsertSettings []string
+
+	// MaxConcurrentInserts caps chunk sends in flight across all writers sharing this client;
+	// extra inserts wait for a slot (or their ctx). 0 leaves inserts uncapped
+	MaxConcurrentInserts int
+
+	// QueryCacheTTL enables an in-process LRU for read-only Query results (SELECT/WITH);
+	// 0 disables it. Entries are keyed by normalized SQL + full args, inserts/execs bypass it
+	QueryCacheTTL time.Duration
+	// QueryCacheMaxEntries bounds the LRU (default 512)
+	QueryCacheMaxEntries int
+	// QueryCacheMaxRows skips caching result sets larger than this (default 10000)
+	QueryCacheMaxRows int
+
+	// Tables maps logical to physical tables as "db.table=db.physical" pairs (e.g., a cluster's
+	// "swearjar.hits=swearjar.hits_dist"); every query, exec and insert target is rewritten.
+	// Async insert matching uses the logical name. Empty leaves SQL untouched
+	Tables []string
+
+	// HealthInterval pings the server in the background at this interval; a failed ping marks the
+	// client unhealthy and re-opens the connection so outages recover without a restart.
+	// EOF-ish call failures trigger an early probe. 0 disables the loop
+	HealthInterval time.Duration
+
 	// Driver debug hook (optional)
 	Debugf func(format string, args ...any)
 }
@@ -125,12 +172,30 @@ type Rows interface {
 
 // CH is a minimal ClickHouse client with retry and tracing
 type CH struct {
+	mu          sync.RWMutex // guards conn (swapped on reconnect)
 	conn        clickhouse.Conn
 	tracer      QueryTracer
 	slowUS      int64
 	insertChunk int
 	maxRetries  int
 	retryBase   time.Duration
+
+	asyncTables   map[string]struct{}
+	asyncWait     bool
+	tableSettings map[string]clickhouse.Settings // nil when no table is tuned
+
+	inserts *insertLimiter // nil when uncapped
+	cache   *queryCache    // nil when disabled
+	tables  *tableMap      // nil when no tables are remapped
+
+	// health loop (nil channels when HealthInterval is 0)
+	opts     *clickhouse.Options
+	open     func(*clickhouse.Options) (clickhouse.Conn, error)
+	healthy  atomic.Bool
+	kick     chan struct{}
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
 }
 
 // Open establishes the connection and pings the server with small retry
@@ -139,6 +204,16 @@ func Open(ctx context.Context, cfg Config) (*CH, error) {
 		return nil, fmt.Errorf("ch: no addresses provided")
 	}
 
+	tables, err := newTableMap(cfg.Tables)
+	if err != nil {
+		return nil, err
+	}
+
+	tableSettings, err := parseInsertSettings(cfg.InsertSettings)
+	if err != nil {
+		return nil, err
+	}
+
 	insertChunk := cfg.InsertChunk
 	if insertChunk <= 0 {
 		insertChunk = 5000
@@ -189,20 +264,33 @@ func Open(ctx context.Context, cfg Config) (*CH, error) {
 		return nil, fmt.Errorf("ch: ping: %w", last)
 	}
 
-	return &CH{
-		conn:        conn,
-		tracer:      cfg.Tracer,
-		slowUS:      int64(cfg.SlowMs) * 1000,
-		insertChunk: insertChunk,
-		maxRetries:  maxRetries,
-		retryBase:   retryBase,
-	}, nil
+	c := &CH{
+		conn:          conn,
+		tracer:        cfg.Tracer,
+		slowUS:        int64(cfg.SlowMs) * 1000,
+		insertChunk:   insertChunk,
+		maxRetries:    maxRetries,
+		retryBase:     retryBase,
+		asyncTables:   asyncTableSet(cfg.AsyncInsertTables),
+		asyncWait:     cfg.AsyncInsertWait,
+		tableSettings: tableSettings,
+		inserts:       newInsertLimiter(cfg.MaxConcurrentInserts),
+		cache:         newQueryCache(cfg.QueryCacheTTL, cfg.QueryCacheMaxEntries, cfg.QueryCacheMaxRows),
+		tables:        tables,
+		opts:          opts,
+		open:          clickhouse.Open,
+	}
+	c.healthy.Store(true)
+	if cfg.HealthInterval > 0 {
+		c.startHealth(cfg.HealthInterval)
+	}
+	return c, nil
 }
 
 // Insert inserts rows into table in chunks with retry on EOF-ish failures.
 // Expects rows as [][]any (shape matching PrepareBatch.Append)
 func (c *CH) Insert(ctx context.Context, table string, rows [][]any) error {
-	if c == nil || c.conn == nil {
+	if c == nil || c.current() == nil {
 		return fmt.Errorf("ch: nil client")
 	}
 	if table = strings.TrimSpace(table); table == "" {
@@ -213,6 +301,9 @@ func (c *CH) Insert(ctx context.Context, table string, rows [][]any) error {
 	}
 
 	chunk := c.insertChunk
+	async := c.isAsync(table)
+	settings := c.insertSettings(ctx, table, async)
+	table = c.tables.sql(table)
 	startAll := time.Now()
 	var last error
 
@@ -227,9 +318,15 @@ func (c *CH) Insert(ctx context.Context, table string, rows [][]any) error {
 		// retry per chunk
 		last = nil
 		for attempt := 1; attempt <= c.maxRetries; attempt++ {
+			// wait for a send slot outside the timed window so slow logs reflect the server
+			release, err := c.inserts.acquire(ctx)
+			if err != nil {
+				return err
+			}
 			startChunk := time.Now()
-			err := c.insertChunkDo(ctx, table, rows[start:end])
+			err = c.insertChunkDo(ctx, table, rows[start:end], settings)
 			elapsedUS := time.Since(startChunk).Microseconds()
+			release()
 
 			// Track stats for final summary
 			sumChunkUS += elapsedUS
@@ -247,6 +344,7 @@ func (c *CH) Insert(ctx context.Context, table string, rows [][]any) error {
 					Err:       err,
 					Slow:      isSlow,
 					Op:        "insert",
+					Async:     async,
 				})
 			}
 
@@ -254,6 +352,7 @@ func (c *CH) Insert(ctx context.Context, table string, rows [][]any) error {
 				break
 			}
 			last = err
+			c.noteErr(err)
 			if !isEOFish(err) || attempt == c.maxRetries {
 				return err
 			}
@@ -272,20 +371,54 @@ func (c *CH) Insert(ctx context.Context, table string, rows [][]any) error {
 		}
 		c.tracer.OnQuery(ctx, QueryEvent{
 			SQL: "INSERT BULK",
-			Args: fmt.Sprintf("table=%s rows=%d chunks=%d total_ms=%.3f avg_chunk_ms=%.3f max_chunk_ms=%.3f",
-				table, totalRows, totalChunks, float64(elapsedUS)/1000.0, avgChunkMS, float64(maxChunkUS)/1000.0),
+			Args: fmt.Sprintf(
+				"table=%s rows=%d chunks=%d total_ms=%.3f avg_chunk_ms=%.3f max_chunk_ms=%.3f in_flight=%d",
+				table, totalRows, totalChunks, float64(elapsedUS)/1000.0, avgChunkMS, float64(maxChunkUS)/1000.0,
+				InsertsInFlight(),
+			),
 			ElapsedUS: elapsedUS,
 			Err:       nil,
 			Slow:      c.slowUS > 0 && elapsedUS >= c.slowUS,
 			Op:        "insert",
+			Async:     async,
 		})
 	}
 	return nil
 }
 
-func (c *CH) insertChunkDo(ctx context.Context, table string, rows [][]any) error {
+// asyncTableSet normalizes configured table names (lowercased, trimmed)
+func asyncTableSet(tables []string) map[string]struct{} {
+	out := map[string]struct{}{}
+	for _, t := range tables {
+		if t = strings.ToLower(strings.TrimSpace(t)); t != "" {
+			out[t] = struct{}{}
+		}
+	}
+	return out
+}
+
+// isAsync reports whether inserts into table should use async_insert.
+// table may carry a column list ("swearjar.hits (id, ...)"); both the qualified and bare name match
+func (c *CH) isAsync(table string) bool {
+	_, ok := lookupTable(c.asyncTables, table)
+	return ok
+}
+
+func (c *CH) asyncSettings() clickhouse.Settings {
+	wait := 0
+	if c.asyncWait {
+		wait = 1
+	}
+	return clickhouse.Settings{"async_insert": 1, "wait_for_async_insert": wait}
+}
+
+// insertChunkDo sends one chunk; non-nil settings ride on the query context for this batch only
+func (c *CH) insertChunkDo(ctx context.Context, table string, rows [][]any, settings clickhouse.Settings) error {
+	if settings != nil {
+		ctx = clickhouse.Context(ctx, clickhouse.WithSettings(settings))
+	}
 	stmt := "INSERT INTO " + table + " VALUES"
-	batch, err := c.conn.PrepareBatch(ctx, stmt)
+	batch, err := c.current().PrepareBatch(ctx, stmt)
 	if err != nil {
 		return fmt.Errorf("ch: prepare: %w", err)
 	}
@@ -300,16 +433,30 @@ func (c *CH) insertChunkDo(ctx context.Context, table string, rows [][]any) erro
 	return nil
 }
 
-// Query executes SQL with args and returns rows (retry on EOF-ish)
+// Query executes SQL with args and returns rows (retry on EOF-ish).
+// With the query cache enabled, repeated reads within the TTL are replayed from memory
+// (unless ctx is marked WithQueryCacheRefresh, which re-reads and re-records)
 func (c *CH) Query(ctx context.Context, sql string, args ...any) (Rows, error) {
-	if c == nil || c.conn == nil {
+	if c == nil || c.current() == nil {
 		return nil, fmt.Errorf("ch: nil client")
 	}
+	sql = c.tables.sql(sql)
+
+	var key string
+	if c.cache != nil && cacheable(ctx, sql) {
+		key = cacheKey(sql, args)
+		if e, ok := c.cache.get(key); ok && !refreshing(ctx) {
+			if c.tracer != nil {
+				c.tracer.OnQuery(ctx, QueryEvent{SQL: sql, Args: args, Op: "query", Cached: true})
+			}
+			return &cachedRows{e: e}, nil
+		}
+	}
 
 	var last error
 	for attempt := 1; attempt <= c.maxRetries; attempt++ {
 		start := time.Now()
-		r, err := c.conn.Query(ctx, sql, args...)
+		r, err := c.current().Query(ctx, sql, args...)
 		elapsedUS := time.Since(start).Microseconds()
 		if c.tracer != nil {
 			c.tracer.OnQuery(ctx, QueryEvent{
@@ -322,9 +469,13 @@ func (c *CH) Query(ctx context.Context, sql string, args ...any) (Rows, error) {
 			})
 		}
 		if err == nil {
-			return &rows{r}, nil
+			if key != "" {
+				return newRecordingRows(r, c.cache, key), nil
+			}
+			return &rows{Rows: r}, nil
 		}
 		last = err
+		c.noteErr(err)
 		if !isEOFish(err) || attempt == c.maxRetries {
 			return nil, err
 		}
@@ -336,13 +487,14 @@ func (c *CH) Query(ctx context.Context, sql string, args ...any) (Rows, error) {
 // Exec runs a statement that doesn't return rows (DDL/DML like ALTER ... DELETE, INSERT ... SELECT).
 // Retries on transient EOF-ish errors, with tracing and slow-query logging
 func (c *CH) Exec(ctx context.Context, sql string, args ...any) error {
-	if c == nil || c.conn == nil {
+	if c == nil || c.current() == nil {
 		return fmt.Errorf("ch: nil client")
 	}
+	sql = c.tables.sql(sql)
 	var last error
 	for attempt := 1; attempt <= c.maxRetries; attempt++ {
 		start := time.Now()
-		err := c.conn.Exec(ctx, sql, args...)
+		err := c.current().Exec(ctx, sql, args...)
 		elapsedUS := time.Since(start).Microseconds()
 		if c.tracer != nil {
 			c.tracer.OnQuery(ctx, QueryEvent{
@@ -358,6 +510,7 @@ func (c *CH) Exec(ctx context.Context, sql string, args ...any) error {
 			return nil
 		}
 		last = err
+		c.noteErr(err)
 		if !isEOFish(err) || attempt == c.maxRetries {
 			return err
 		}
@@ -368,13 +521,14 @@ func (c *CH) Exec(ctx context.Context, sql string, args ...any) error {
 
 // ScalarUInt64 executes a query expected to return exactly one row with a single UInt64 column
 func (c *CH) ScalarUInt64(ctx context.Context, sql string, args ...any) (uint64, error) {
-	if c == nil || c.conn == nil {
+	if c == nil || c.current() == nil {
 		return 0, fmt.Errorf("ch: nil client")
 	}
+	sql = c.tables.sql(sql)
 	var last error
 	for attempt := 1; attempt <= c.maxRetries; attempt++ {
 		start := time.Now()
-		rows, err := c.conn.Query(ctx, sql, args...)
+		rows, err := c.current().Query(ctx, sql, args...)
 		elapsedUS := time.Since(start).Microseconds()
 		if c.tracer != nil {
 			c.tracer.OnQuery(ctx, QueryEvent{
@@ -388,6 +542,7 @@ func (c *CH) ScalarUInt64(ctx context.Context, sql string, args ...any) (uint64,
 		}
 		if err != nil {
 			last = err
+			c.noteErr(err)
 			if !isEOFish(err) || attempt == c.maxRetries {
 				return 0, err
 			}
@@ -416,13 +571,14 @@ func (c *CH) ScalarUInt64(ctx context.Context, sql string, args ...any) (uint64,
 
 // ScalarInt64 executes a query expected to return exactly one row with a single Int64 column
 func (c *CH) ScalarInt64(ctx context.Context, sql string, args ...any) (int64, error) {
-	if c == nil || c.conn == nil {
+	if c == nil || c.current() == nil {
 		return 0, fmt.Errorf("ch: nil client")
 	}
+	sql = c.tables.sql(sql)
 	var last error
 	for attempt := 1; attempt <= c.maxRetries; attempt++ {
 		start := time.Now()
-		rows, err := c.conn.Query(ctx, sql, args...)
+		rows, err := c.current().Query(ctx, sql, args...)
 		elapsedUS := time.Since(start).Microseconds()
 		if c.tracer != nil {
 			c.tracer.OnQuery(ctx, QueryEvent{
@@ -436,6 +592,7 @@ func (c *CH) ScalarInt64(ctx context.Context, sql string, args ...any) (int64, e
 		}
 		if err != nil {
 			last = err
+			c.noteErr(err)
 			if !isEOFish(err) || attempt == c.maxRetries {
 				return 0, err
 			}
@@ -462,19 +619,19 @@ func (c *CH) ScalarInt64(ctx context.Context, sql string, args ...any) (int64, e
 	return 0, last
 }
 
-// Close closes the connection
+// Close stops the health loop (if any) and closes the connection
 func (c *CH) Close() error {
-	if c == nil || c.conn == nil {
+	if c == nil || c.current() == nil {
 		return nil
 	}
-	return c.conn.Close()
+	if c.stop != nil {
+		c.stopOnce.Do(func() { close(c.stop) })
+		<-c.done
+	}
+	return c.current().Close()
 }
 
 // rows adapts driver.Rows to our local Rows
-type rows struct{ driver.Rows }
-
-func (r *rows) Close() error { return r.Rows.Close() }
-
 // isEOFish - classify driver/network churns we should retry
 func isEOFish(err error) bool {
 	if err == nil {
@@ -0,0 +1,110 @@
+package ch
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+)
+
+// current returns the live connection; it changes only when the health loop reconnects
+func (c *CH) current() clickhouse.Conn {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.conn
+}
+
+// Healthy reports whether the last background probe succeeded. Without a probe
+// (Config.HealthInterval 0) it stays true after Open; query errors still surface to callers
+func (c *CH) Healthy() bool {
+	return c != nil && c.healthy.Load()
+}
+
+// Ping checks the live connection once
+func (c *CH) Ping(ctx context.Context) error {
+	if c == nil || c.current() == nil {
+		return fmt.Errorf("ch: nil client")
+	}
+	return c.current().Ping(ctx)
+}
+
+// noteErr wakes the health loop early when a call failed like a dropped connection
+func (c *CH) noteErr(err error) {
+	if c.kick == nil || !isEOFish(err) {
+		return
+	}
+	select {
+	case c.kick <- struct{}{}:
+	default: // a probe is already pending
+	}
+}
+
+// startHealth runs the probe loop until Close
+func (c *CH) startHealth(interval time.Duration) {
+	c.kick = make(chan struct{}, 1)
+	c.stop = make(chan struct{})
+	c.done = make(chan struct{})
+	go func() {
+		defer close(c.done)
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-c.stop:
+				return
+			case <-t.C:
+			case <-c.kick:
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), interval)
+			c.probe(ctx)
+			cancel()
+		}
+	}()
+}
+
+// probe pings the live connection and, when that fails, opens a fresh one and swaps it in.
+// The client stays unhealthy until a ping (old or new connection) succeeds
+func (c *CH) probe(ctx context.Context) {
+	err := c.current().Ping(ctx)
+	if err == nil {
+		c.healthy.Store(true)
+		return
+	}
+	c.healthy.Store(false)
+
+	start := time.Now()
+	err = c.reconnect(ctx)
+	if c.tracer != nil {
+		c.tracer.OnQuery(ctx, QueryEvent{
+			SQL:       "RECONNECT",
+			Args:      fmt.Sprintf("after failed ping; addrs=%v", c.opts.Addr),
+			ElapsedUS: time.Since(start).Microseconds(),
+			Err:       err,
+			Op:        "reconnect",
+		})
+	}
+	if err == nil {
+		c.healthy.Store(true)
+	}
+}
+
+// reconnect opens and pings a new connection, then replaces (and closes) the old one
+func (c *CH) reconnect(ctx context.Context) error {
+	conn, err := c.open(c.opts)
+	if err != nil {
+		return fmt.Errorf("ch: reopen: %w", err)
+	}
+	if err := conn.Ping(ctx); err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("ch: reopen ping: %w", err)
+	}
+	c.mu.Lock()
+	old := c.conn
+	c.conn = conn
+	c.mu.Unlock()
+	if old != nil {
+		_ = old.Close() // in-flight calls on the old conn fail and retry on the new one
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package ch
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+)
+
+// fakeConn implements only Ping and Close; any other call panics via the nil embedded Conn
+type fakeConn struct {
+	clickhouse.Conn
+	pingErr error
+	closed  bool
+}
+
+func (f *fakeConn) Ping(context.Context) error { return f.pingErr }
+func (f *fakeConn) Close() error               { f.closed = true; return nil }
+
+func newProbeClient(conn *fakeConn, open func(*clickhouse.Options) (clickhouse.Conn, error)) *CH {
+	c := &CH{conn: conn, opts: &clickhouse.Options{Addr: []string{"fake:9000"}}, open: open}
+	c.healthy.Store(true)
+	return c
+}
+
+func TestProbe_HealthyConnIsKept(t *testing.T) {
+	conn := &fakeConn{}
+	c := newProbeClient(conn, func(*clickhouse.Options) (clickhouse.Conn, error) {
+		t.Fatal("reopened a healthy connection")
+		return nil, nil
+	})
+
+	c.probe(context.Background())
+	if !c.Healthy() || c.current() != conn {
+		t.Fatalf("healthy=%v swapped=%v, want healthy and same conn", c.Healthy(), c.current() != conn)
+	}
+}
+
+func TestProbe_DeadConnIsReplaced(t *testing.T) {
+	dead := &fakeConn{pingErr: io.EOF}
+	fresh := &fakeConn{}
+	c := newProbeClient(dead, func(*clickhouse.Options) (clickhouse.Conn, error) { return fresh, nil })
+
+	c.probe(context.Background())
+	if !c.Healthy() {
+		t.Fatal("client unhealthy after successful reconnect")
+	}
+	if c.current() != fresh {
+		t.Fatal("connection not swapped")
+	}
+	if !dead.closed {
+		t.Fatal("old connection not closed")
+	}
+}
+
+func TestProbe_FailedReconnectStaysUnhealthy(t *testing.T) {
+	dead := &fakeConn{pingErr: io.EOF}
+	stillDown := &fakeConn{pingErr: errors.New("connection refused")}
+	c := newProbeClient(dead, func(*clickhouse.Options) (clickhouse.Conn, error) { return stillDown, nil })
+
+	c.probe(context.Background())
+	if c.Healthy() {
+		t.Fatal("client healthy while server is down")
+	}
+	if c.current() != dead || !stillDown.closed {
+		t.Fatal("failed reconnect must keep the old conn and close the new one")
+	}
+
+	// server comes back: the next probe recovers through the old conn's ping
+	dead.pingErr = nil
+	c.probe(context.Background())
+	if !c.Healthy() {
+		t.Fatal("client still unhealthy after recovery")
+	}
+}
+
+func TestNoteErr_KicksOnlyOnEOFish(t *testing.T) {
+	c := &CH{kick: make(chan struct{}, 1)}
+
+	c.noteErr(errors.New("syntax error"))
+	if len(c.kick) != 0 {
+		t.Fatal("non-connection error kicked the probe")
+	}
+	c.noteErr(io.EOF)
+	c.noteErr(io.EOF) // coalesced, must not block
+	if len(c.kick) != 1 {
+		t.Fatalf("kick len = %d, want 1", len(c.kick))
+	}
+}
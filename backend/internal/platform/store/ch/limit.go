@@ -0,0 +1,45 @@
+package ch
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// insertsInFlight counts chunk sends currently on the wire across every client in the process
+var insertsInFlight atomic.Int64
+
+// InsertsInFlight reports how many insert chunks this process is sending right now; the
+// writers (backfill, detect) report it as in_flight on each INSERT BULK trace
+func InsertsInFlight() int64 { return insertsInFlight.Load() }
+
+// insertLimiter caps concurrent chunk sends for every writer sharing a client
+// (detect workers, backfill hours, nightshift) so fan-out can't swamp ClickHouse
+type insertLimiter struct {
+	sem chan struct{}
+}
+
+// newInsertLimiter returns nil (no cap) when n <= 0
+func newInsertLimiter(n int) *insertLimiter {
+	if n <= 0 {
+		return nil
+	}
+	return &insertLimiter{sem: make(chan struct{}, n)}
+}
+
+// acquire blocks for a slot or until ctx is done; release must be called once on success
+func (l *insertLimiter) acquire(ctx context.Context) (release func(), err error) {
+	if l != nil {
+		select {
+		case l.sem <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	insertsInFlight.Add(1)
+	return func() {
+		insertsInFlight.Add(-1)
+		if l != nil {
+			<-l.sem
+		}
+	}, nil
+}
@@ -0,0 +1,77 @@
+package ch
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestInsertLimiter_CapsInFlight(t *testing.T) {
+	l := newInsertLimiter(2)
+	base := InsertsInFlight()
+
+	r1, err := l.acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	r2, err := l.acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := InsertsInFlight() - base; got != 2 {
+		t.Fatalf("in flight = %d, want 2", got)
+	}
+
+	// a third writer waits until its ctx gives up
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := l.acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("acquire over cap: %v, want deadline exceeded", err)
+	}
+
+	// releasing a slot lets a waiter through
+	got := make(chan error, 1)
+	go func() {
+		r3, err := l.acquire(context.Background())
+		if err == nil {
+			r3()
+		}
+		got <- err
+	}()
+	r1()
+	select {
+	case err := <-got:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("waiter not admitted after release")
+	}
+	r2()
+	if got := InsertsInFlight() - base; got != 0 {
+		t.Fatalf("in flight after release = %d, want 0", got)
+	}
+}
+
+func TestInsertLimiter_NilIsUncapped(t *testing.T) {
+	l := newInsertLimiter(0)
+	if l != nil {
+		t.Fatal("n <= 0 should disable the cap")
+	}
+	base := InsertsInFlight()
+	releases := make([]func(), 0, 10)
+	for range 10 {
+		r, err := l.acquire(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		releases = append(releases, r)
+	}
+	if got := InsertsInFlight() - base; got != 10 {
+		t.Fatalf("uncapped in flight = %d, want 10 (gauge still counts)", got)
+	}
+	for _, r := range releases {
+		r()
+	}
+}
@@ -0,0 +1,88 @@
+package ch
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// Redaction modes for traced query args
+const (
+	RedactOff     = "off"     // log args verbatim
+	RedactStrings = "strings" // mask string and byte args (names, logins, text); keep numbers, bools, times
+	RedactAll     = "all"     // mask every arg; only shape survives
+)
+
+// Redaction is the tracer's log-redaction policy for query args. Repo names, logins and
+// utterance text reach CH as string args, so RedactStrings keeps the useful parts of a trace
+// (ids, bounds, versions) without them. The SQL text itself is never rewritten: values must be
+// passed as args, not inlined, to be covered
+type Redaction struct {
+	Mode string
+	// KeepShort keeps strings up to this many bytes under RedactStrings (enum-like values such as
+	// "commit" or "repo"); 0 masks every string
+	KeepShort int
+	// Allow lists string values always logged verbatim under RedactStrings
+	Allow []string
+}
+
+// args returns a log-safe copy of a query's args under the policy
+func (r Redaction) args(v any) any {
+	if r.Mode == "" || r.Mode == RedactOff {
+		return v
+	}
+	return r.value(reflect.ValueOf(v))
+}
+
+func (r Redaction) value(rv reflect.Value) any {
+	if !rv.IsValid() {
+		return nil
+	}
+	switch rv.Kind() {
+	case reflect.Interface, reflect.Pointer:
+		if rv.IsNil() {
+			return nil
+		}
+		return r.value(rv.Elem())
+	case reflect.String:
+		return r.str(rv.String())
+	case reflect.Slice, reflect.Array:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			return masked(rv.Len()) // []byte / FixedString: HIDs or raw text, never logged
+		}
+		out := make([]any, rv.Len())
+		for i := range out {
+			out[i] = r.value(rv.Index(i))
+		}
+		return out
+	}
+
+	if r.Mode == RedactAll {
+		return "[redacted]"
+	}
+	switch x := rv.Interface().(type) {
+	case time.Time:
+		return x
+	case fmt.Stringer:
+		return r.str(x.String())
+	}
+	switch rv.Kind() {
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return rv.Interface()
+	}
+	return "[redacted " + rv.Type().String() + "]"
+}
+
+func (r Redaction) str(s string) any {
+	if r.Mode == RedactStrings && (len(s) <= r.KeepShort || slices.Contains(r.Allow, s)) {
+		return s
+	}
+	return masked(len(s))
+}
+
+func masked(n int) string { return "[redacted len=" + strconv.Itoa(n) + "]" }
@@ -0,0 +1,68 @@
+package ch
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+func TestRedactionStringsMasksNamesKeepsScalars(t *testing.T) {
+	at := time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC)
+	r := Redaction{Mode: RedactStrings, KeepShort: 6, Allow: []string{"opt_in"}}
+	got := r.args([]any{
+		"octocat/hello-world", "commit", "opt_in", 42, true, at, []byte{1, 2, 3}, []string{"torvalds", "repo"},
+	})
+	want := []any{
+		"[redacted len=19]",
+		"commit", // short enough to keep
+		"opt_in", // allowlisted
+		42,
+		true,
+		at,
+		"[redacted len=3]",
+		[]any{"[redacted len=8]", "repo"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("args = %#v\nwant %#v", got, want)
+	}
+}
+
+func TestRedactionAllAndOff(t *testing.T) {
+	in := []any{"x", 7, [][]byte{{1}}}
+	if got := (Redaction{Mode: RedactAll}).args(in); !reflect.DeepEqual(got, []any{
+		"[redacted len=1]", "[redacted]", []any{"[redacted len=1]"},
+	}) {
+		t.Fatalf("all = %#v", got)
+	}
+	if got := (Redaction{}).args(in); !reflect.DeepEqual(got, in) {
+		t.Fatalf("off = %#v", got)
+	}
+}
+
+func TestTracerRedactsLoggedArgs(t *testing.T) {
+	var buf bytes.Buffer
+	tr := TracerWithRedaction(zerolog.New(&buf), Redaction{Mode: RedactStrings})
+	tr.OnQuery(t.Context(), QueryEvent{
+		SQL:  "SELECT 1 FROM swearjar.utterances WHERE repo_name = ? AND detver = ?",
+		Args: []any{"octocat/hello-world", 1},
+		Op:   "query",
+	})
+
+	if strings.Contains(buf.String(), "octocat") {
+		t.Fatalf("log leaked a repo name: %s", buf.String())
+	}
+	var line struct {
+		Args []any `json:"args"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("unmarshal %q: %v", buf.String(), err)
+	}
+	if len(line.Args) != 2 || line.Args[0] != "[redacted len=19]" || line.Args[1] != float64(1) {
+		t.Fatalf("args = %#v", line.Args)
+	}
+}
@@ -0,0 +1,135 @@
+package ch
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+)
+
+// rows wraps live driver rows
+type rows struct {
+	driver.Rows
+	types []reflect.Type // lazily resolved on the first coerced Scan
+}
+
+func (r *rows) Close() error { return r.Rows.Close() }
+
+// Scan takes the driver fast path and, when the driver rejects a destination, rescans the
+// row into the column scan types and coerces: Nullable -> value, value -> pointer, and
+// numeric widening. Repos keep working when a column flips Nullable, and a real mismatch
+// names the column instead of surfacing the opaque driver error
+func (r *rows) Scan(dest ...any) error {
+	err := r.Rows.Scan(dest...)
+	if err == nil {
+		return nil
+	}
+	if r.types == nil {
+		r.types = scanTypes(r.Rows)
+	}
+	if len(dest) != len(r.types) {
+		return err
+	}
+	row, herr := scanHolders(r.Rows, r.types)
+	if herr != nil {
+		return err // not a destination problem (e.g. no current row)
+	}
+	return assignRow(r.Rows.Columns(), row, dest)
+}
+
+// scanTypes returns the driver's Go scan type per column (pointer types for Nullable)
+func scanTypes(r driver.Rows) []reflect.Type {
+	cts := r.ColumnTypes()
+	types := make([]reflect.Type, len(cts))
+	for i, ct := range cts {
+		types[i] = ct.ScanType()
+	}
+	return types
+}
+
+// scanHolders scans the current row into fresh values of the given types
+func scanHolders(r driver.Rows, types []reflect.Type) ([]reflect.Value, error) {
+	holders := make([]any, len(types))
+	for i, t := range types {
+		holders[i] = reflect.New(t).Interface()
+	}
+	if err := r.Scan(holders...); err != nil {
+		return nil, err
+	}
+	row := make([]reflect.Value, len(holders))
+	for i, h := range holders {
+		row[i] = reflect.ValueOf(h).Elem()
+	}
+	return row, nil
+}
+
+// assignRow copies a scanned row into dest pointers, naming the column on failure
+func assignRow(cols []string, row []reflect.Value, dest []any) error {
+	if len(dest) != len(row) {
+		return fmt.Errorf("ch: expected %d destination arguments in Scan, not %d", len(row), len(dest))
+	}
+	for i := range dest {
+		if err := assignValue(dest[i], row[i]); err != nil {
+			name := fmt.Sprintf("#%d", i)
+			if i < len(cols) {
+				name = fmt.Sprintf("%q", cols[i])
+			}
+			return fmt.Errorf("ch: scan column %s into %T: %w", name, dest[i], err)
+		}
+	}
+	return nil
+}
+
+// assignValue sets *dst from v; slices are copied so callers can't mutate the cache
+// Nullable sources fill plain destinations when non-NULL, plain sources fill pointer
+// destinations, and numeric/string values convert within their kind family
+func assignValue(dst any, v reflect.Value) error {
+	dv := reflect.ValueOf(dst)
+	if dv.Kind() != reflect.Pointer || dv.IsNil() {
+		return fmt.Errorf("destination %T is not a non-nil pointer", dst)
+	}
+	t := dv.Elem()
+	if v.Kind() == reflect.Pointer && !v.Type().AssignableTo(t.Type()) {
+		if v.IsNil() {
+			if t.Kind() != reflect.Pointer {
+				return fmt.Errorf("cannot assign NULL to %s; scan into *%s to accept NULLs", t.Type(), t.Type())
+			}
+			t.Set(reflect.Zero(t.Type()))
+			return nil
+		}
+		v = v.Elem()
+	}
+	if t.Kind() == reflect.Pointer && v.Kind() != reflect.Pointer {
+		p := reflect.New(t.Type().Elem())
+		if err := assignValue(p.Interface(), v); err != nil {
+			return err
+		}
+		t.Set(p)
+		return nil
+	}
+	if v.Kind() == reflect.Slice && !v.IsNil() {
+		cp := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		reflect.Copy(cp, v)
+		v = cp
+	}
+	switch {
+	case v.Type().AssignableTo(t.Type()):
+		t.Set(v)
+	case convertibleScalar(v.Kind()) && convertibleScalar(t.Kind()) &&
+		(v.Kind() == reflect.String) == (t.Kind() == reflect.String):
+		t.Set(v.Convert(t.Type()))
+	default:
+		return fmt.Errorf("cannot assign %s to %s", v.Type(), t.Type())
+	}
+	return nil
+}
+
+func convertibleScalar(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64, reflect.Bool, reflect.String:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+package ch
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func langRows(data ...[]any) *rows {
+	return &rows{Rows: &fakeRows{
+		cols: []fakeColumn{
+			{"lang_code", reflect.TypeFor[*string]()}, // Nullable(String)
+			{"hits", reflect.TypeFor[uint64]()},
+		},
+		data: data,
+	}}
+}
+
+func TestRowsScan_CoercesNullability(t *testing.T) {
+	en := "en"
+	r := langRows([]any{&en, uint64(4)})
+	r.Next()
+
+	// Nullable column into a plain string, UInt64 into int64
+	var lang string
+	var hits int64
+	if err := r.Scan(&lang, &hits); err != nil {
+		t.Fatalf("scan: %v", err)
+	}
+	if lang != "en" || hits != 4 {
+		t.Fatalf("got %q/%d", lang, hits)
+	}
+
+	// exact types still take the driver path
+	var langp *string
+	var hitsu uint64
+	if err := r.Scan(&langp, &hitsu); err != nil || langp == nil || *langp != "en" || hitsu != 4 {
+		t.Fatalf("direct scan: %v %v %d", err, langp, hitsu)
+	}
+
+	// non-Nullable column into a pointer destination
+	var hitsp *int64
+	if err := r.Scan(&langp, &hitsp); err != nil || hitsp == nil || *hitsp != 4 {
+		t.Fatalf("value -> pointer: %v %v", err, hitsp)
+	}
+}
+
+func TestRowsScan_NullIntoPointerAndPlain(t *testing.T) {
+	r := langRows([]any{(*string)(nil), uint64(1)})
+	r.Next()
+
+	langp := new(string)
+	var hits uint64
+	if err := r.Scan(&langp, &hits); err != nil || langp != nil {
+		t.Fatalf("NULL into *string: %v %v", err, langp)
+	}
+
+	var lang string
+	err := r.Scan(&lang, &hits)
+	if err == nil {
+		t.Fatal("NULL into plain string should fail")
+	}
+	if !strings.Contains(err.Error(), `"lang_code"`) {
+		t.Fatalf("error does not name the column: %v", err)
+	}
+}
+
+func TestRowsScan_MismatchNamesColumn(t *testing.T) {
+	en := "en"
+	r := langRows([]any{&en, uint64(4)})
+	r.Next()
+
+	var lang string
+	var hits []string
+	err := r.Scan(&lang, &hits)
+	if err == nil {
+		t.Fatal("UInt64 into []string should fail")
+	}
+	if !strings.Contains(err.Error(), `column "hits"`) || !strings.Contains(err.Error(), "*[]string") {
+		t.Fatalf("unhelpful error: %v", err)
+	}
+}
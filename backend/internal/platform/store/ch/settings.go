@@ -0,0 +1,92 @@
+package ch
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strings"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+)
+
+type insertSettingsKey struct{}
+
+// WithInsertSettings attaches ClickHouse settings (e.g., max_insert_block_size) to every Insert
+// made with ctx. They override per-table InsertSettings and the async_insert pair for that call
+func WithInsertSettings(ctx context.Context, s clickhouse.Settings) context.Context {
+	if len(s) == 0 {
+		return ctx
+	}
+	merged := clickhouse.Settings{}
+	if prev, ok := ctx.Value(insertSettingsKey{}).(clickhouse.Settings); ok {
+		maps.Copy(merged, prev)
+	}
+	maps.Copy(merged, s)
+	return context.WithValue(ctx, insertSettingsKey{}, merged)
+}
+
+// parseInsertSettings reads "table:setting=value" entries into per-table settings keyed like
+// asyncTableSet (lowercased, "hits" or "swearjar.hits"); repeat a table for more settings
+func parseInsertSettings(entries []string) (map[string]clickhouse.Settings, error) {
+	out := map[string]clickhouse.Settings{}
+	for _, e := range entries {
+		if e = strings.TrimSpace(e); e == "" {
+			continue
+		}
+		table, kv, ok := strings.Cut(e, ":")
+		name, val, ok2 := strings.Cut(kv, "=")
+		table, name = strings.ToLower(strings.TrimSpace(table)), strings.TrimSpace(name)
+		if !ok || !ok2 || table == "" || name == "" || strings.ContainsAny(table, " \t(") {
+			return nil, fmt.Errorf("ch: insert setting %q: want table:setting=value", e)
+		}
+		if out[table] == nil {
+			out[table] = clickhouse.Settings{}
+		}
+		out[table][name] = strings.TrimSpace(val)
+	}
+	return out, nil
+}
+
+// insertSettings merges, lowest precedence first, the async_insert pair, the table's configured
+// settings and any WithInsertSettings on ctx. nil means the insert runs on connection defaults
+func (c *CH) insertSettings(ctx context.Context, table string, async bool) clickhouse.Settings {
+	var out clickhouse.Settings
+	add := func(s clickhouse.Settings) {
+		if len(s) == 0 {
+			return
+		}
+		if out == nil {
+			out = clickhouse.Settings{}
+		}
+		maps.Copy(out, s)
+	}
+	if async {
+		add(c.asyncSettings())
+	}
+	if s, ok := lookupTable(c.tableSettings, table); ok {
+		add(s)
+	}
+	if s, ok := ctx.Value(insertSettingsKey{}).(clickhouse.Settings); ok {
+		add(s)
+	}
+	return out
+}
+
+// lookupTable finds table in a set keyed by configured names. table may carry a column list
+// ("swearjar.hits (id, ...)"); both the qualified and bare name match
+func lookupTable[V any](m map[string]V, table string) (V, bool) {
+	var zero V
+	if len(m) == 0 {
+		return zero, false
+	}
+	name, _, _ := strings.Cut(table, "(")
+	name = strings.ToLower(strings.TrimSpace(name))
+	if v, ok := m[name]; ok {
+		return v, true
+	}
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		v, ok := m[name[i+1:]]
+		return v, ok
+	}
+	return zero, false
+}
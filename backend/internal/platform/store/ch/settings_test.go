@@ -0,0 +1,114 @@
+package ch
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+)
+
+// batchConn records the ctx each PrepareBatch sees; any other call panics via the nil embedded Conn
+type batchConn struct {
+	clickhouse.Conn
+	ctxs []context.Context
+}
+
+func (b *batchConn) PrepareBatch(ctx context.Context, _ string, _ ...driver.PrepareBatchOption) (driver.Batch, error) {
+	b.ctxs = append(b.ctxs, ctx)
+	return nopBatch{}, nil
+}
+
+type nopBatch struct{ driver.Batch }
+
+func (nopBatch) Append(...any) error { return nil }
+func (nopBatch) Send() error         { return nil }
+
+func newInsertClient(t *testing.T, conn *batchConn, cfg Config) *CH {
+	t.Helper()
+	ts, err := parseInsertSettings(cfg.InsertSettings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &CH{
+		conn:          conn,
+		insertChunk:   2,
+		maxRetries:    1,
+		asyncTables:   asyncTableSet(cfg.AsyncInsertTables),
+		tableSettings: ts,
+	}
+}
+
+var threeRows = [][]any{{1}, {2}, {3}}
+
+func TestInsert_AttachesMergedSettingsToEveryChunk(t *testing.T) {
+	conn := &batchConn{}
+	c := newInsertClient(t, conn, Config{
+		AsyncInsertTables: []string{"hits"},
+		InsertSettings:    []string{"hits:max_insert_block_size=1048576", "HITS:min_insert_block_size_rows=1000"},
+	})
+
+	ctx := WithInsertSettings(context.Background(), clickhouse.Settings{"min_insert_block_size_rows": 5})
+	if err := c.Insert(ctx, "swearjar.hits (id)", threeRows); err != nil {
+		t.Fatal(err)
+	}
+	if len(conn.ctxs) != 2 {
+		t.Fatalf("chunks = %d, want 2", len(conn.ctxs))
+	}
+	want := clickhouse.Context(ctx, clickhouse.WithSettings(clickhouse.Settings{
+		"async_insert":               1,
+		"wait_for_async_insert":      0,
+		"max_insert_block_size":      "1048576",
+		"min_insert_block_size_rows": 5, // per-call beats per-table
+	}))
+	for i, got := range conn.ctxs {
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("chunk %d ctx does not carry the merged settings", i+1)
+		}
+	}
+}
+
+func TestInsert_UntunedTableKeepsCallerCtx(t *testing.T) {
+	conn := &batchConn{}
+	c := newInsertClient(t, conn, Config{InsertSettings: []string{"hits:max_insert_block_size=1048576"}})
+
+	ctx := context.Background()
+	if err := c.Insert(ctx, "swearjar.utterances (id)", threeRows[:1]); err != nil {
+		t.Fatal(err)
+	}
+	if len(conn.ctxs) != 1 || conn.ctxs[0] != ctx {
+		t.Fatalf("untuned insert should reuse the caller ctx unchanged")
+	}
+}
+
+func TestWithInsertSettings_Layers(t *testing.T) {
+	ctx := WithInsertSettings(context.Background(), clickhouse.Settings{"a": 1, "b": 1})
+	ctx = WithInsertSettings(ctx, clickhouse.Settings{"b": 2})
+	if same := WithInsertSettings(ctx, nil); same != ctx {
+		t.Fatal("empty settings should return ctx as is")
+	}
+	got := (&CH{}).insertSettings(ctx, "hits", false)
+	if !reflect.DeepEqual(got, clickhouse.Settings{"a": 1, "b": 2}) {
+		t.Fatalf("settings = %v", got)
+	}
+}
+
+func TestParseInsertSettings(t *testing.T) {
+	got, err := parseInsertSettings([]string{" Hits:max_insert_block_size = 10 ", "", "swearjar.hits:x=y"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]clickhouse.Settings{
+		"hits":          {"max_insert_block_size": "10"},
+		"swearjar.hits": {"x": "y"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("parsed = %v, want %v", got, want)
+	}
+	for _, bad := range []string{"hits", "hits:novalue", ":a=1", "hits:=1", "swearjar.hits (id):a=1"} {
+		if _, err := parseInsertSettings([]string{bad}); err == nil {
+			t.Fatalf("%q: expected an error", bad)
+		}
+	}
+}
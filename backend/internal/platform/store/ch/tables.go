@@ -0,0 +1,60 @@
+package ch
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// tableMap rewrites logical table names in SQL to the physical tables of a deployment, so the
+// same queries run single-node ("swearjar.hits") and clustered ("swearjar.hits_dist") without
+// editing query strings. Only qualified, unquoted "db.table" references are rewritten
+type tableMap struct {
+	names map[string]string // logical -> physical, lowercased keys
+	re    *regexp.Regexp
+}
+
+// newTableMap parses "db.table=db.physical" pairs; it returns nil (no rewriting) for none
+func newTableMap(pairs []string) (*tableMap, error) {
+	names := map[string]string{}
+	for _, p := range pairs {
+		if p = strings.TrimSpace(p); p == "" {
+			continue
+		}
+		from, to, ok := strings.Cut(p, "=")
+		from, to = strings.ToLower(strings.TrimSpace(from)), strings.TrimSpace(to)
+		if !ok || !qualifiedName(from) || !qualifiedName(to) {
+			return nil, fmt.Errorf("ch: table mapping %q: want db.table=db.table", p)
+		}
+		names[from] = to
+	}
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	// Longest first so "swearjar.hits_x" never matches as "swearjar.hits" plus a suffix
+	keys := make([]string, 0, len(names))
+	for k := range names {
+		keys = append(keys, regexp.QuoteMeta(k))
+	}
+	slices.SortFunc(keys, func(a, b string) int { return len(b) - len(a) })
+	re := regexp.MustCompile(`(?i)(^|[^\w.` + "`" + `])(` + strings.Join(keys, "|") + `)\b`)
+	return &tableMap{names: names, re: re}, nil
+}
+
+func qualifiedName(s string) bool {
+	db, table, ok := strings.Cut(s, ".")
+	return ok && db != "" && table != "" && !strings.ContainsAny(s, " \t\n`\"(")
+}
+
+// sql rewrites every mapped table reference in s
+func (t *tableMap) sql(s string) string {
+	if t == nil {
+		return s
+	}
+	return t.re.ReplaceAllStringFunc(s, func(m string) string {
+		sub := t.re.FindStringSubmatch(m)
+		return sub[1] + t.names[strings.ToLower(sub[2])]
+	})
+}
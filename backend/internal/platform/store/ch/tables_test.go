@@ -0,0 +1,49 @@
+package ch
+
+import "testing"
+
+func TestTableMapRewritesQualifiedNames(t *testing.T) {
+	tm, err := newTableMap([]string{"swearjar.hits=swearjar.hits_dist", " swearjar.utterances = swearjar.utt_dist "})
+	if err != nil {
+		t.Fatalf("newTableMap: %v", err)
+	}
+
+	cases := []struct{ in, want string }{
+		{"SELECT 1 FROM swearjar.hits FINAL", "SELECT 1 FROM swearjar.hits_dist FINAL"},
+		{"FROM swearjar.hits\nJOIN swearjar.utterances u", "FROM swearjar.hits_dist\nJOIN swearjar.utt_dist u"},
+		{"IN (SELECT id FROM swearjar.hits)", "IN (SELECT id FROM swearjar.hits_dist)"},
+		{"FROM SWEARJAR.HITS", "FROM swearjar.hits_dist"},
+		// unmapped or merely prefixed names pass through
+		{"FROM swearjar.hits_by_day", "FROM swearjar.hits_by_day"},
+		{"FROM swearjar.commit_crimes", "FROM swearjar.commit_crimes"},
+		{"FROM other_swearjar.hits", "FROM other_swearjar.hits"},
+		{"FROM x.swearjar.hits", "FROM x.swearjar.hits"},
+	}
+	for _, c := range cases {
+		if got := tm.sql(c.in); got != c.want {
+			t.Errorf("sql(%q) = %q, want %q", c.in, got, c.want)
+		}
+	}
+
+	if got := tm.sql("swearjar.hits (id, term)"); got != "swearjar.hits_dist (id, term)" {
+		t.Errorf("insert target = %q", got)
+	}
+}
+
+func TestTableMapEmptyIsNil(t *testing.T) {
+	tm, err := newTableMap([]string{"", "  "})
+	if err != nil || tm != nil {
+		t.Fatalf("want nil map, got %v, %v", tm, err)
+	}
+	if got := tm.sql("FROM swearjar.hits"); got != "FROM swearjar.hits" {
+		t.Fatalf("nil map rewrote %q", got)
+	}
+}
+
+func TestTableMapRejectsMalformedPairs(t *testing.T) {
+	for _, p := range []string{"hits=hits_dist", "swearjar.hits", "swearjar.hits=", "swearjar.hits=swearjar.`x`"} {
+		if _, err := newTableMap([]string{p}); err == nil {
+			t.Errorf("newTableMap(%q) = nil error", p)
+		}
+	}
+}
@@ -61,6 +61,11 @@ func (r *rowsAdapter) Err() error             { return r.r.Err() }
 func (r *rowsAdapter) Close()                 { _ = r.r.Close() }
 func (r *rowsAdapter) Columns() []string      { return r.r.Columns() }
 
+// Healthy reports the CH client's background probe state (always true when the probe is off)
+func (a *clickhouseAdapter) Healthy() bool {
+	return a != nil && a.inner.Healthy()
+}
+
 // Ping verifies connectivity with ClickHouse
 func (a *clickhouseAdapter) Ping(ctx context.Context) error {
 	if a == nil || a.inner == nil {
@@ -35,6 +35,31 @@ type CHConfig struct {
 	LogSQL      bool
 	ClientTag   string
 	ClientName  string
+
+	// LogRedact* mask traced query args when LogSQL is on (see ch.Redaction); "" logs them verbatim
+	LogRedact          string
+	LogRedactKeepShort int
+	LogRedactAllow     []string
+
+	// AsyncInsertTables opts tables into ClickHouse async inserts (see ch.Config)
+	AsyncInsertTables []string
+	AsyncInsertWait   bool
+
+	// InsertSettings tunes inserts per table, "table:setting=value" (see ch.Config)
+	InsertSettings []string
+
+	// MaxConcurrentInserts caps in-flight CH insert chunks process-wide (see ch.Config); 0 is uncapped
+	MaxConcurrentInserts int
+
+	// QueryCache* enable the in-process read cache (see ch.Config); TTL 0 disables it
+	QueryCacheTTL        time.Duration
+	QueryCacheMaxEntries int
+
+	// HealthInterval enables the background ping/reconnect loop (see ch.Config); 0 disables it
+	HealthInterval time.Duration
+
+	// Tables remaps logical to physical tables, "db.table=db.physical" (see ch.Config)
+	Tables []string
 }
 
 // NATSConfig configures nats connectivity
@@ -2,6 +2,7 @@ package store
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 	"reflect"
@@ -9,8 +10,16 @@ import (
 	"time"
 
 	perr "swearjar/internal/platform/errors"
+
+	"github.com/jackc/pgx/v5"
 )
 
+// IsNoRows reports whether err is a QueryRow Scan that found no row. pgx's ErrNoRows is
+// distinct from database/sql's, so callers checking only sql.ErrNoRows miss it
+func IsNoRows(err error) bool {
+	return errors.Is(err, sql.ErrNoRows) || errors.Is(err, pgx.ErrNoRows)
+}
+
 // Exec runs a write and returns the raw CommandTag
 func Exec(ctx context.Context, q RowQuerier, sql string, args ...any) (CommandTag, error) {
 	return q.Exec(ctx, sql, args...)
@@ -2,7 +2,9 @@ package store
 
 import (
 	"context"
+	"database/sql"
 	"errors"
+	"fmt"
 	"reflect"
 	"strconv"
 	"strings"
@@ -10,6 +12,8 @@ import (
 	"time"
 
 	perr "swearjar/internal/platform/errors"
+
+	"github.com/jackc/pgx/v5"
 )
 
 type cmdTag string
@@ -743,3 +747,21 @@ func TestMap_SingleRow_HappyPath_Again(t *testing.T) {
 		t.Fatalf("name mismatch: %#v", m["name"])
 	}
 }
+
+func TestIsNoRows(t *testing.T) {
+	cases := []struct {
+		err  error
+		want bool
+	}{
+		{sql.ErrNoRows, true},
+		{pgx.ErrNoRows, true},
+		{fmt.Errorf("lease: %w", pgx.ErrNoRows), true},
+		{errors.New("conn reset by peer"), false},
+		{nil, false},
+	}
+	for _, tc := range cases {
+		if got := IsNoRows(tc.err); got != tc.want {
+			t.Errorf("IsNoRows(%v) = %v, want %v", tc.err, got, tc.want)
+		}
+	}
+}
@@ -181,10 +181,27 @@ func openCH(ctx context.Context, c CHConfig, s *Store) (*ch.CH, error) {
 		InsertChunk: c.InsertChunk,
 		MaxRetries:  c.MaxRetries,
 		RetryBase:   time.Duration(c.RetryBaseMs) * time.Millisecond,
+
+		AsyncInsertTables: c.AsyncInsertTables,
+		AsyncInsertWait:   c.AsyncInsertWait,
+		InsertSettings:    c.InsertSettings,
+
+		MaxConcurrentInserts: c.MaxConcurrentInserts,
+
+		QueryCacheTTL:        c.QueryCacheTTL,
+		QueryCacheMaxEntries: c.QueryCacheMaxEntries,
+
+		HealthInterval: c.HealthInterval,
+
+		Tables: c.Tables,
 	}
 
 	if c.LogSQL && s != nil {
-		ccfg.Tracer = ch.Tracer(s.Log)
+		ccfg.Tracer = ch.TracerWithRedaction(s.Log, ch.Redaction{
+			Mode:      strings.ToLower(c.LogRedact),
+			KeepShort: c.LogRedactKeepShort,
+			Allow:     c.LogRedactAllow,
+		})
 	}
 
 	return ch.Open(ctx, ccfg)
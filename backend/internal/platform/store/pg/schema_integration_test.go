@@ -0,0 +1,135 @@
+//go:build integration_pg
+// +build integration_pg
+
+package pg
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	tc "github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// startSchemaPostgres boots the compose PG image with docker/pgsql mounted as init scripts,
+// so tests run against the real schema, triggers and roles
+func startSchemaPostgres(t *testing.T) (dsn string, stop func()) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
+
+	dir, err := filepath.Abs("../../../../docker/pgsql")
+	if err != nil {
+		cancel()
+		t.Fatalf("resolve docker/pgsql: %v", err)
+	}
+	req := tc.ContainerRequest{
+		Image:        "postgres:18beta3", // uuidv7() needs 18
+		ExposedPorts: []string{"5432/tcp"},
+		Env: map[string]string{
+			"POSTGRES_USER":     "postgres",
+			"POSTGRES_PASSWORD": "postgres",
+			"POSTGRES_DB":       "postgres",
+		},
+		Files: []tc.ContainerFile{
+			{
+				HostFilePath:      filepath.Join(dir, "00-create-users.sh"),
+				ContainerFilePath: "/docker-entrypoint-initdb.d/00-create-users.sh",
+				FileMode:          0o755,
+			},
+			{
+				HostFilePath:      filepath.Join(dir, "init.sql"),
+				ContainerFilePath: "/docker-entrypoint-initdb.d/100-init.sql",
+				FileMode:          0o644,
+			},
+		},
+		// the entrypoint runs init scripts on a temporary server first; wait for the real one
+		WaitingFor: wait.ForAll(
+			wait.ForListeningPort("5432/tcp"),
+			wait.ForLog("database system is ready to accept connections").WithOccurrence(2),
+		).WithDeadline(2 * time.Minute),
+	}
+	c, err := tc.GenericContainer(ctx, tc.GenericContainerRequest{
+		ContainerRequest: req,
+		Started:          true,
+	})
+	if err != nil {
+		cancel()
+		t.Fatalf("failed to start postgres container: %v", err)
+	}
+
+	host, err := c.Host(ctx)
+	if err != nil {
+		_ = c.Terminate(context.Background())
+		cancel()
+		t.Fatalf("failed to get container host: %v", err)
+	}
+	mapped, err := c.MappedPort(ctx, "5432/tcp")
+	if err != nil {
+		_ = c.Terminate(context.Background())
+		cancel()
+		t.Fatalf("failed to get mapped port: %v", err)
+	}
+
+	dsn = fmt.Sprintf("postgres://postgres:postgres@%s:%s/postgres?sslmode=disable", host, mapped.Port())
+	stop = func() {
+		_ = c.Terminate(context.Background())
+		cancel()
+	}
+	return dsn, stop
+}
+
+func TestOptOutPurgesRepoRenames_Integration(t *testing.T) {
+	dsn, stop := startSchemaPostgres(t)
+	defer stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 90*time.Second)
+	defer cancel()
+
+	WithTestDB(t, dsn, nil, func(p *PG) {
+		hid := bytes.Repeat([]byte{0x5a}, 32)
+		other := bytes.Repeat([]byte{0x5b}, 32)
+
+		for _, h := range [][]byte{hid, other} {
+			if _, err := p.Pool.Exec(ctx, `INSERT INTO principals_repos (repo_hid) VALUES ($1)`, h); err != nil {
+				t.Fatalf("insert principal: %v", err)
+			}
+			var consentID string
+			if err := p.Pool.QueryRow(ctx, `
+				INSERT INTO consent_receipts (principal, principal_hid, action, evidence_kind, evidence_url)
+				VALUES ('repo', $1, 'opt_in', 'repo_file', 'https://github.com/o/r/blob/HEAD/.swearjar')
+				RETURNING consent_id::text`, h).Scan(&consentID); err != nil {
+				t.Fatalf("insert opt-in: %v", err)
+			}
+			if _, err := p.Pool.Exec(ctx, `
+				INSERT INTO repo_renames (repo_hid, consent_id, old_full_name, new_full_name)
+				VALUES ($1, $2::uuid, 'o/old', 'o/new')`, h, consentID); err != nil {
+				t.Fatalf("insert rename: %v", err)
+			}
+		}
+
+		if _, err := p.Pool.Exec(ctx, `
+			INSERT INTO consent_receipts (principal, principal_hid, action, evidence_kind, evidence_url)
+			VALUES ('repo', $1, 'opt_out', 'repo_file', 'https://github.com/o/r/blob/HEAD/.swearjar')`, hid); err != nil {
+			t.Fatalf("insert opt-out: %v", err)
+		}
+
+		count := func(h []byte) int {
+			var n int
+			if err := p.Pool.QueryRow(ctx, `SELECT count(*) FROM repo_renames WHERE repo_hid = $1`, h).Scan(&n); err != nil {
+				t.Fatalf("count renames: %v", err)
+			}
+			return n
+		}
+		if n := count(hid); n != 0 {
+			t.Fatalf("opted-out repo still has %d rename rows", n)
+		}
+		if n := count(other); n != 1 {
+			t.Fatalf("unrelated repo has %d rename rows, want 1", n)
+		}
+	})
+}
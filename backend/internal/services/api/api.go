@@ -2,6 +2,8 @@
 package api
 
 import (
+	"time"
+
 	"swearjar/internal/platform/config"
 	"swearjar/internal/platform/logger"
 	phttp "swearjar/internal/platform/net/http"
@@ -14,6 +16,7 @@ import (
 
 	apibouncer "swearjar/internal/services/api/bouncer/module"
 	metamod "swearjar/internal/services/api/meta/module"
+	metricsmod "swearjar/internal/services/api/metrics/module"
 	samplesmod "swearjar/internal/services/api/samples/module"
 	statsmod "swearjar/internal/services/api/stats/module"
 	swearjarmod "swearjar/internal/services/api/swearjar/module"
@@ -62,8 +65,16 @@ func Mount(r phttp.Router, opt Options) {
 		apiBouncer,    // API module that depends on the worker's Enqueuer
 	}
 
+	// admission control: cap concurrent requests (and so concurrent CH queries) across clients
+	// CORE_API_MAX_INFLIGHT 0 leaves it off; health/ready probes always bypass it
+	stack := append(httpkit.CommonStack(), httpkit.MaxInFlight(httpkit.MaxInFlightOptions{
+		Limit: opt.Config.MayInt("MAX_INFLIGHT", 0),
+		Queue: opt.Config.MayInt("MAX_INFLIGHT_QUEUE", 32),
+		Wait:  opt.Config.MayDuration("MAX_INFLIGHT_WAIT", 2*time.Second),
+	}))
+
 	// versioned API with a common middleware stack
-	httpkit.MountAPIV1(r, httpkit.CommonStack(), func(api httpkit.Router) {
+	httpkit.MountAPIV1(r, stack, func(api httpkit.Router) {
 		// Swagger + profiler
 		swaggerkit.Mount(r, opt.EnableSwagger)
 		phttp.MountProfiler(r, "/debug", opt.EnableProfiler)
@@ -77,6 +88,11 @@ func Mount(r phttp.Router, opt Options) {
 		}
 	})
 
+	// Prometheus scrape endpoint lives at the server root (/metrics), outside the versioned API
+	metrics := metricsmod.New(deps)
+	module.Register(metrics.Name(), metrics.Ports())
+	metrics.MountRoutes(r)
+
 	// TODO: Remove/create middleware or endpoint for this
 	// if mux, ok := r.Mux().(*chi.Mux); ok {
 	// 	_ = chi.Walk(mux, func(method string, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
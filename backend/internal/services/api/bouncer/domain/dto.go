@@ -3,7 +3,7 @@ package domain
 
 // IssueInput asks the service to mint and record a challenge for a subject and scope
 type IssueInput struct {
-	SubjectType SubjectType `json:"subject_type" validate:"required,oneof=repo actor" example:"repo"`
+	SubjectType SubjectType `json:"subject_type" validate:"required,oneof=repo actor org" example:"repo"`
 	SubjectKey  string      `json:"subject_key"  validate:"required,min=1,max=200,printascii" example:"golang/go"`
 	Scope       Scope       `json:"scope"        validate:"required,oneof=allow deny" example:"allow"`
 }
@@ -17,8 +17,9 @@ type IssueOutput struct {
 }
 
 // ReverifyInput asks the service to re check the artifact for this subject
+// For an org subject every repo listed in its consent manifest is verified and granted at once
 type ReverifyInput struct {
-	SubjectType SubjectType `json:"subject_type" validate:"required,oneof=repo actor" example:"actor"`
+	SubjectType SubjectType `json:"subject_type" validate:"required,oneof=repo actor org" example:"actor"`
 	SubjectKey  string      `json:"subject_key"  validate:"required,min=1,max=200,printascii" example:"octocat"`
 }
 
@@ -31,19 +32,56 @@ type StatusQuery struct {
 
 // StatusRow is the service view of effective consent and freshness
 type StatusRow struct {
-	State          EffectiveState `json:"state" example:"allow"`
-	SinceUnix      int64          `json:"since_unix,omitempty"  example:"1725731200"`
-	EvidenceKind   EvidenceKind   `json:"evidence_kind,omitempty" example:"repo_file"`
-	EvidenceURL    string         `json:"evidence_url,omitempty" example:"https://raw.githubusercontent.com/golang/go/HEAD/.b4b1f6....txt"` //nolint:lll
-	Hash           string         `json:"hash,omitempty"          example:"b4b1f6c9a3e44d2f8a4f5b6c..."`
-	LastVerifiedAt int64          `json:"last_verified_unix,omitempty" example:"1725734800"`
-	Staleness      string         `json:"staleness,omitempty"    validate:"omitempty,oneof=fresh stale revocation_pending" example:"fresh"` //nolint:lll
+	State          EffectiveState  `json:"state" example:"allow"`
+	SinceUnix      int64           `json:"since_unix,omitempty"  example:"1725731200"`
+	EvidenceKind   EvidenceKind    `json:"evidence_kind,omitempty" example:"repo_file"`
+	EvidenceURL    string          `json:"evidence_url,omitempty" example:"https://raw.githubusercontent.com/golang/go/HEAD/.b4b1f6....txt"` //nolint:lll
+	Hash           string          `json:"hash,omitempty"          example:"b4b1f6c9a3e44d2f8a4f5b6c..."`
+	LastVerifiedAt int64           `json:"last_verified_unix,omitempty" example:"1725734800"`
+	Staleness      string          `json:"staleness,omitempty"    validate:"omitempty,oneof=fresh stale revocation_pending" example:"fresh"` //nolint:lll
+	Manifest       *ManifestResult `json:"manifest,omitempty"`
+}
+
+// ManifestResult reports what an org manifest reverify granted and what it skipped
+type ManifestResult struct {
+	URL     string         `json:"url" example:"https://github.com/acme/.github/blob/main/.swearjar-consent.json"`
+	Granted []string       `json:"granted" example:"acme/widgets"`
+	Skipped []ManifestSkip `json:"skipped"`
+}
+
+// ManifestSkip is a manifest entry that was not granted, with the reason
+type ManifestSkip struct {
+	Repo   string `json:"repo"   example:"other/repo"`
+	Reason string `json:"reason" example:"not in org"` // not in org|invalid name|duplicate|not found
+}
+
+// HistoryQuery asks for the consent audit trail of a subject
+type HistoryQuery struct {
+	SubjectType SubjectType `json:"subject_type" validate:"required,oneof=repo actor" example:"repo"`
+	SubjectKey  string      `json:"subject_key"  validate:"required,min=1,max=200,printascii" example:"golang/go"`
+}
+
+// ConsentTransition is one consent_audit row: a receipt change and who verified it
+type ConsentTransition struct {
+	AtUnix       int64  `json:"at_unix"            example:"1725731200"`
+	Transition   string `json:"transition"         example:"granted"` // granted|reinstated|reverified|revocation_pending
+	Action       string `json:"action"             example:"opt_in"`
+	State        string `json:"state"              example:"active"`
+	EvidenceKind string `json:"evidence_kind"      example:"repo_file"`
+	EvidenceURL  string `json:"evidence_url"       example:"https://github.com/golang/go/blob/master/.b4b1f6....txt"`
+	Hash         string `json:"hash,omitempty"     example:"b4b1f6c9a3e44d2f8a4f5b6c..."`
+	VerifiedBy   string `json:"verified_by"        example:"worker"` // api|worker
+}
+
+// HistoryOutput lists a subject's consent transitions, oldest first
+type HistoryOutput struct {
+	Transitions []ConsentTransition `json:"transitions"`
 }
 
 // LatestChallenge is a recent challenge row
 type LatestChallenge struct {
 	Action       string // 'opt_in'|'opt_out'
-	EvidenceKind string // 'repo_file'|'actor_gist'
+	EvidenceKind string // 'repo_file'|'actor_gist'|'org_manifest'
 	ArtifactHint string // ".<hash>.txt" or "<hash>.txt"
 	Hash         string
 	IssuedAtUnix int64
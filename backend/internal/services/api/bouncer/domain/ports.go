@@ -7,4 +7,5 @@ type ServicePort interface {
 	Issue(ctx context.Context, in IssueInput) (IssueOutput, error)
 	Reverify(ctx context.Context, in ReverifyInput) (StatusRow, error)
 	Status(ctx context.Context, in StatusQuery) (StatusRow, error)
+	History(ctx context.Context, in HistoryQuery) (HistoryOutput, error)
 }
@@ -1,7 +1,11 @@
 // Package domain holds bouncer core types independent of transport or storage
 package domain
 
-import "time"
+import (
+	"time"
+
+	perrs "swearjar/internal/platform/errors"
+)
 
 // SubjectType marks what entity is giving consent
 type SubjectType string
@@ -12,6 +16,9 @@ const (
 
 	// SubjectActor is a GitHub user or org
 	SubjectActor SubjectType = "actor"
+
+	// SubjectOrg is a GitHub org opting in repos in bulk through a consent manifest
+	SubjectOrg SubjectType = "org"
 )
 
 // Scope is the intent of a consent action
@@ -51,8 +58,38 @@ const (
 
 	// EvidenceGist is a public gist file owned by the actor
 	EvidenceGist EvidenceKind = "gist_file"
+
+	// EvidenceManifest is a consent manifest in the org's .github repo listing repos to opt in
+	EvidenceManifest EvidenceKind = "org_manifest"
 )
 
+// ManifestFilename is the consent manifest path in an org's .github repo
+const ManifestFilename = ".swearjar-consent.json"
+
+// ManifestRepo is the org repo that carries the consent manifest
+const ManifestRepo = ".github"
+
+// MaxManifestRepos caps how many repos a single manifest may list
+const MaxManifestRepos = 500
+
+// ErrRepoMoved is returned when an owner/name resolves to a repo under another owner (GitHub
+// redirects transferred repos); consent from the old owner doesn't cover it
+var ErrRepoMoved = perrs.New(perrs.ErrorCodeConflict, "repo has moved to another owner")
+
+// OrgManifest is the schema of .swearjar-consent.json
+//
+//	{"version": 1, "org": "acme", "hash": "<challenge hash>", "repos": ["widgets", "acme/gadgets"]}
+//
+// The hash ties the file to an issued org challenge, so only someone able to commit to the org's
+// .github repo can produce a valid manifest. Repos are names inside the org, with or without the
+// "org/" prefix; entries naming another owner are skipped, never granted
+type OrgManifest struct {
+	Version int      `json:"version"`
+	Org     string   `json:"org"`
+	Hash    string   `json:"hash"`
+	Repos   []string `json:"repos"`
+}
+
 // VerificationJob is a leased unit of work returned to the worker
 type VerificationJob struct {
 	JobID         string
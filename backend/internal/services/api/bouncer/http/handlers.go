@@ -15,6 +15,7 @@ func Register(r httpkit.Router, s svc.Service) {
 	httpkit.PostJSON[domain.IssueInput](r, "/issue", h.issue)
 	httpkit.PostJSON[domain.ReverifyInput](r, "/reverify", h.reverify)
 	httpkit.PostJSON[domain.StatusQuery](r, "/status", h.status)
+	httpkit.PostJSON[domain.HistoryQuery](r, "/history", h.history)
 }
 
 type handlers struct{ svc svc.Service }
@@ -54,3 +55,15 @@ func (h *handlers) reverify(r *stdhttp.Request, in domain.ReverifyInput) (any, e
 func (h *handlers) status(r *stdhttp.Request, in domain.StatusQuery) (any, error) {
 	return h.svc.Status(r.Context(), in)
 }
+
+// swagger:route POST /bouncer/history Bouncer History
+// @Summary Consent audit trail (append-only receipt transitions, oldest first)
+// @Tags bouncer
+// @Accept json
+// @Produce json
+// @Param payload body domain.HistoryQuery true "History"
+// @Success 200 {object} domain.HistoryOutput "ok"
+// @Router /bouncer/history [post]
+func (h *handlers) history(r *stdhttp.Request, in domain.HistoryQuery) (any, error) {
+	return h.svc.History(r.Context(), in)
+}
@@ -12,6 +12,7 @@ import (
 	"swearjar/internal/modkit/httpkit"
 	"swearjar/internal/modkit/repokit"
 
+	"swearjar/internal/services/api/bouncer/domain"
 	bhttp "swearjar/internal/services/api/bouncer/http"
 	brepo "swearjar/internal/services/api/bouncer/repo"
 	bsvc "swearjar/internal/services/api/bouncer/service"
@@ -59,6 +60,10 @@ func (g ghIdentity) RepoID(ctx context.Context, full string) (int64, error) {
 	if repo.ID == 0 {
 		return 0, fmt.Errorf("github returned empty id for %q", full)
 	}
+	// GitHub follows transfers: the old name answers with the new owner's repo
+	if !strings.EqualFold(repo.Owner.Login, parts[0]) {
+		return 0, fmt.Errorf("%w: %s is now %s", domain.ErrRepoMoved, full, repo.FullName)
+	}
 	return repo.ID, nil
 }
 
@@ -104,6 +109,9 @@ func New(deps modkit.Deps, opts ...modkit.Option) modkit.Module {
 		TokensCSV:  cfg.TokensCSV,
 		MaxRetries: cfg.MaxRetries,
 		RetryBase:  cfg.RetryBase,
+
+		MaxIdleConnsPerHost: cfg.MaxIdleConnsPerHost,
+		IdleConnTimeout:     cfg.IdleConnTimeout,
 	})
 	evidence := gh.NewProbe(ghc)
 
@@ -18,6 +18,9 @@ type Options struct {
 	Timeout    time.Duration
 	MaxRetries int
 	RetryBase  time.Duration
+
+	MaxIdleConnsPerHost int
+	IdleConnTimeout     time.Duration
 }
 
 // FromConfig reads BOUNCER_* values from process config/env
@@ -32,5 +35,8 @@ func FromConfig(cfg config.Conf) Options {
 		Timeout:    bc.MayDuration("GH_TIMEOUT", 10*time.Second),
 		MaxRetries: bc.MayInt("GH_MAX_RETRIES", 5),
 		RetryBase:  bc.MayDuration("GH_RETRY_BASE", 500*time.Millisecond),
+
+		MaxIdleConnsPerHost: bc.MayInt("GH_MAX_IDLE_PER_HOST", 0),
+		IdleConnTimeout:     bc.MayDuration("GH_IDLE_CONN_TIMEOUT", 0),
 	}
 }
@@ -9,4 +9,7 @@ type EvidenceProbe interface {
 	DefaultBranch(ctx context.Context, ownerRepo string) (string, error)
 	RepoFile(ctx context.Context, ownerRepo, defaultBranch, filename string) (bool, string, error)
 	GistFile(ctx context.Context, login, filename string) (bool, string, error)
+
+	// RepoFileContent is RepoFile plus the file body, used to read consent manifests
+	RepoFileContent(ctx context.Context, ownerRepo, defaultBranch, filename string) (bool, string, []byte, error)
 }
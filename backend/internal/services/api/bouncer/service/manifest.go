@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	gh "swearjar/internal/adapters/ingest/github"
+	"swearjar/internal/modkit/repokit"
+	perrs "swearjar/internal/platform/errors"
+	"swearjar/internal/services/api/bouncer/domain"
+)
+
+var (
+	// GitHub logins: alphanumerics and single hyphens, at most 39 chars
+	orgLoginRe = regexp.MustCompile(`^[A-Za-z0-9](?:[A-Za-z0-9-]{0,37}[A-Za-z0-9])?$`)
+	repoNameRe = regexp.MustCompile(`^[A-Za-z0-9._-]{1,100}$`)
+)
+
+// manifestResource is the org's .github repo, where the manifest lives
+func manifestResource(org string) string { return org + "/" + domain.ManifestRepo }
+
+// orgChallengeKey is the challenge resource for an org. It can't collide with a repo resource
+// ("owner/name"), so a plain repo challenge for <org>/.github never shares its slot
+func orgChallengeKey(org string) string { return "org:" + org }
+
+// issueOrg records an org_manifest challenge for the org; the evidence lives in its .github repo
+func (s *Svc) issueOrg(ctx context.Context, org, action, hash string, day time.Time) (domain.IssueOutput, error) {
+	if !orgLoginRe.MatchString(org) {
+		return domain.IssueOutput{}, perrs.WithField(perrs.InvalidArgf("invalid org login %q", org), "subject_key")
+	}
+	resource := manifestResource(org)
+	lc, err := s.Repo.InsertChallengeArgs(ctx,
+		"repo", orgChallengeKey(org), action, hash, string(domain.EvidenceManifest), domain.ManifestFilename, day,
+	)
+	if err != nil {
+		return domain.IssueOutput{}, err
+	}
+
+	return domain.IssueOutput{
+		Hash:         lc.Hash,
+		RepoFilename: lc.ArtifactHint,
+		Instructions: "Commit " + lc.ArtifactHint + " to the DEFAULT branch of " + resource + " containing " +
+			`{"version":1,"org":"` + org + `","hash":"` + lc.Hash + `","repos":["<repo>", ...]}. ` +
+			"Then POST to /api/v1/bouncer/reverify with subject_type org.",
+	}, nil
+}
+
+// parseManifest decodes a consent manifest and checks it belongs to this org and challenge
+func parseManifest(org, hash string, body []byte) (domain.OrgManifest, error) {
+	bad := func(format string, a ...any) (domain.OrgManifest, error) {
+		return domain.OrgManifest{}, perrs.WithField(perrs.InvalidArgf(format, a...), "manifest")
+	}
+	var m domain.OrgManifest
+	if err := json.Unmarshal(body, &m); err != nil {
+		return bad("manifest is not valid json: %v", err)
+	}
+	switch {
+	case m.Version != 1:
+		return bad("unsupported manifest version %d", m.Version)
+	case !strings.EqualFold(strings.TrimSpace(m.Org), org):
+		return bad("manifest org %q does not match %q", m.Org, org)
+	case strings.TrimSpace(m.Hash) != hash:
+		return bad("manifest hash does not match the issued challenge")
+	case len(m.Repos) == 0:
+		return bad("manifest lists no repos")
+	case len(m.Repos) > domain.MaxManifestRepos:
+		return bad("manifest lists %d repos (max %d)", len(m.Repos), domain.MaxManifestRepos)
+	}
+	return m, nil
+}
+
+// planManifest normalizes manifest entries to "org/name", in manifest order, and reports the
+// entries it refuses: other owners, malformed names and repeats
+func planManifest(org string, repos []string) ([]string, []domain.ManifestSkip) {
+	var (
+		full    []string
+		skipped []domain.ManifestSkip
+		seen    = map[string]bool{}
+	)
+	for _, raw := range repos {
+		entry := strings.TrimSpace(raw)
+		name := entry
+		if owner, rest, ok := strings.Cut(entry, "/"); ok {
+			if !strings.EqualFold(owner, org) {
+				skipped = append(skipped, domain.ManifestSkip{Repo: raw, Reason: "not in org"})
+				continue
+			}
+			name = rest
+		}
+		if !repoNameRe.MatchString(name) || name == "." || name == ".." {
+			skipped = append(skipped, domain.ManifestSkip{Repo: raw, Reason: "invalid name"})
+			continue
+		}
+		key := strings.ToLower(name)
+		if seen[key] {
+			skipped = append(skipped, domain.ManifestSkip{Repo: raw, Reason: "duplicate"})
+			continue
+		}
+		seen[key] = true
+		full = append(full, org+"/"+name)
+	}
+	return full, skipped
+}
+
+// reverifyOrg reads the org's consent manifest and grants a repo receipt for every listed repo
+// that resolves and is still owned by the org (GitHub redirects transferred repos, so a listed
+// name can resolve to another owner's repo; those are skipped). All receipts are written in one Tx; a rate limit or transient GitHub error while
+// resolving aborts before anything is written so a retry sees the manifest fresh. Manifests are
+// verified inline only, the worker never leases them
+func (s *Svc) reverifyOrg(ctx context.Context, org string) (domain.StatusRow, error) {
+	if !orgLoginRe.MatchString(org) {
+		return domain.StatusRow{}, perrs.WithField(perrs.InvalidArgf("invalid org login %q", org), "subject_key")
+	}
+	resource := manifestResource(org)
+
+	lc, err := s.Repo.LatestChallenge(ctx, "repo", orgChallengeKey(org))
+	if err != nil {
+		return domain.StatusRow{}, perrs.ErrNotFound
+	}
+	if lc.Hash == "" || lc.EvidenceKind != string(domain.EvidenceManifest) {
+		return domain.StatusRow{State: domain.StateNone}, nil
+	}
+
+	branch, err := s.evidence.DefaultBranch(ctx, resource)
+	if err != nil {
+		return domain.StatusRow{}, err
+	}
+	exists, url, body, err := s.evidence.RepoFileContent(ctx, resource, branch, lc.ArtifactHint)
+	if err != nil && !isGHNotFound(err) {
+		return domain.StatusRow{}, err
+	}
+	if !exists {
+		return domain.StatusRow{State: domain.StateNone, Hash: lc.Hash}, nil
+	}
+
+	m, err := parseManifest(org, lc.Hash, body)
+	if err != nil {
+		return domain.StatusRow{}, err
+	}
+	names, skipped := planManifest(org, m.Repos)
+
+	res := &domain.ManifestResult{URL: url, Granted: []string{}, Skipped: skipped}
+	var hids [][]byte
+	for _, full := range names {
+		hid, ok, err := s.resolver.RepoHID(ctx, full)
+		if errors.Is(err, domain.ErrRepoMoved) {
+			res.Skipped = append(res.Skipped, domain.ManifestSkip{Repo: full, Reason: "not in org"})
+			continue
+		}
+		if err != nil && !isGHNotFound(err) {
+			return domain.StatusRow{}, err
+		}
+		if !ok || len(hid) == 0 {
+			res.Skipped = append(res.Skipped, domain.ManifestSkip{Repo: full, Reason: "not found"})
+			continue
+		}
+		hids = append(hids, hid)
+		res.Granted = append(res.Granted, full)
+	}
+	if res.Skipped == nil {
+		res.Skipped = []domain.ManifestSkip{}
+	}
+
+	out := domain.StatusRow{State: domain.StateNone, Hash: lc.Hash, Manifest: res}
+	if len(hids) == 0 {
+		return out, nil
+	}
+	if err := s.db.Tx(ctx, func(q repokit.Queryer) error {
+		return s.binder.Bind(q).UpsertReceiptBatch(ctx,
+			"repo", hids, lc.Action, string(domain.EvidenceManifest), url, lc.Hash,
+		)
+	}); err != nil {
+		return domain.StatusRow{}, err
+	}
+
+	now := time.Now().UTC().Unix()
+	out.State = domain.StateAllow
+	if lc.Action == "opt_out" {
+		out.State = domain.StateDeny
+	}
+	out.SinceUnix = now
+	out.EvidenceKind = domain.EvidenceManifest
+	out.EvidenceURL = url
+	out.LastVerifiedAt = now
+	out.Staleness = "fresh"
+	return out, nil
+}
+
+// isGHNotFound reports a GitHub 404, which the client surfaces as an error rather than a miss
+func isGHNotFound(err error) bool {
+	var se *gh.GHStatusError
+	return errors.As(err, &se) && se.Status == http.StatusNotFound
+}
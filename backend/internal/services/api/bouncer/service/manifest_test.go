@@ -0,0 +1,218 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	gh "swearjar/internal/adapters/ingest/github"
+	"swearjar/internal/modkit/repokit"
+	"swearjar/internal/platform/store"
+	"swearjar/internal/services/api/bouncer/domain"
+	"swearjar/internal/services/api/bouncer/repo"
+)
+
+func TestParseManifest(t *testing.T) {
+	cases := []struct {
+		name string
+		body string
+		ok   bool
+	}{
+		{"valid", `{"version":1,"org":"Acme","hash":"h1","repos":["widgets"]}`, true},
+		{"bad json", `{"version":1,`, false},
+		{"wrong version", `{"version":2,"org":"acme","hash":"h1","repos":["widgets"]}`, false},
+		{"other org", `{"version":1,"org":"evil","hash":"h1","repos":["widgets"]}`, false},
+		{"stale hash", `{"version":1,"org":"acme","hash":"h0","repos":["widgets"]}`, false},
+		{"no repos", `{"version":1,"org":"acme","hash":"h1","repos":[]}`, false},
+	}
+	for _, c := range cases {
+		_, err := parseManifest("acme", "h1", []byte(c.body))
+		if (err == nil) != c.ok {
+			t.Fatalf("%s: err=%v, want ok=%v", c.name, err, c.ok)
+		}
+	}
+}
+
+func TestPlanManifest(t *testing.T) {
+	full, skipped := planManifest("acme", []string{
+		"widgets", "acme/gadgets", "ACME/Widgets", "other/repo", "bad name", "..", " tools ",
+	})
+	if want := []string{"acme/widgets", "acme/gadgets", "acme/tools"}; !slices.Equal(full, want) {
+		t.Fatalf("full = %v, want %v", full, want)
+	}
+	reasons := map[string]string{}
+	for _, s := range skipped {
+		reasons[s.Repo] = s.Reason
+	}
+	want := map[string]string{
+		"ACME/Widgets": "duplicate", "other/repo": "not in org", "bad name": "invalid name", "..": "invalid name",
+	}
+	if len(reasons) != len(want) {
+		t.Fatalf("skipped = %+v", skipped)
+	}
+	for k, v := range want {
+		if reasons[k] != v {
+			t.Fatalf("skip %q = %q, want %q", k, reasons[k], v)
+		}
+	}
+}
+
+// memManifest serves one org challenge and records batch receipts
+type memManifest struct {
+	repo.Repo
+
+	lc      domain.LatestChallenge
+	granted [][]byte
+	url     string
+}
+
+func (m *memManifest) LatestChallenge(_ context.Context, principal, resource string) (domain.LatestChallenge, error) {
+	if principal != "repo" || resource != "org:acme" {
+		return domain.LatestChallenge{}, nil
+	}
+	return m.lc, nil
+}
+
+func (m *memManifest) UpsertReceiptBatch(_ context.Context,
+	_ string, hids [][]byte, _ string, _ string, url string, _ string,
+) error {
+	m.granted = append(m.granted, hids...)
+	m.url = url
+	return nil
+}
+
+type runTx struct{ store.RowQuerier }
+
+func (runTx) Tx(_ context.Context, fn func(store.RowQuerier) error) error { return fn(nil) }
+
+// manifestEvidence serves a fixed manifest body from acme/.github
+type manifestEvidence struct {
+	nopEvidence
+
+	body []byte
+}
+
+func (e manifestEvidence) RepoFileContent(
+	_ context.Context, ownerRepo, _ string, filename string,
+) (bool, string, []byte, error) {
+	if ownerRepo != "acme/.github" || filename != domain.ManifestFilename || e.body == nil {
+		return false, "", nil, &gh.GHStatusError{Status: 404}
+	}
+	return true, "https://github.com/acme/.github/blob/main/" + filename, e.body, nil
+}
+
+// mapResolver knows a fixed set of repos; unknown repos 404 like GitHub does
+type mapResolver struct {
+	nopResolver
+
+	repos map[string][]byte
+	moved map[string]bool
+	err   error
+}
+
+func (r mapResolver) RepoHID(_ context.Context, full string) ([]byte, bool, error) {
+	if r.err != nil {
+		return nil, false, r.err
+	}
+	if r.moved[full] {
+		return nil, false, fmt.Errorf("%w: %s", domain.ErrRepoMoved, full)
+	}
+	if h, ok := r.repos[full]; ok {
+		return h, true, nil
+	}
+	return nil, false, &gh.GHStatusError{Status: 404}
+}
+
+func newManifestSvc(mem *memManifest, body string, res mapResolver) *Svc {
+	ev := manifestEvidence{}
+	if body != "" {
+		ev.body = []byte(body)
+	}
+	return New(runTx{}, repokit.BindFunc[repo.Repo](func(repokit.Queryer) repo.Repo { return mem }), Options{
+		Secret:   "test-secret",
+		Resolver: res,
+		Evidence: ev,
+		Enqueuer: nopEnqueuer{},
+	})
+}
+
+func TestReverifyOrgGrantsListedRepos(t *testing.T) {
+	mem := &memManifest{lc: domain.LatestChallenge{
+		Action: "opt_in", EvidenceKind: "org_manifest", ArtifactHint: domain.ManifestFilename, Hash: "h1",
+	}}
+	res := mapResolver{repos: map[string][]byte{"acme/widgets": {1}, "acme/gadgets": {2}}}
+	s := newManifestSvc(mem, `{"version":1,"org":"acme","hash":"h1","repos":["widgets","gadgets","ghost","x/y"]}`, res)
+
+	out, err := s.Reverify(context.Background(), domain.ReverifyInput{SubjectType: domain.SubjectOrg, SubjectKey: "acme"})
+	if err != nil {
+		t.Fatalf("Reverify: %v", err)
+	}
+	if out.State != domain.StateAllow || out.EvidenceKind != domain.EvidenceManifest || out.Manifest == nil {
+		t.Fatalf("unexpected status: %+v", out)
+	}
+	if !slices.Equal(out.Manifest.Granted, []string{"acme/widgets", "acme/gadgets"}) || len(out.Manifest.Skipped) != 2 {
+		t.Fatalf("unexpected manifest result: %+v", out.Manifest)
+	}
+	if len(mem.granted) != 2 || mem.url != out.Manifest.URL {
+		t.Fatalf("expected 2 receipts at %s, got %d at %s", out.Manifest.URL, len(mem.granted), mem.url)
+	}
+}
+
+func TestReverifyOrgWithoutManifestGrantsNothing(t *testing.T) {
+	mem := &memManifest{lc: domain.LatestChallenge{
+		Action: "opt_in", EvidenceKind: "org_manifest", ArtifactHint: domain.ManifestFilename, Hash: "h1",
+	}}
+	s := newManifestSvc(mem, "", mapResolver{})
+
+	out, err := s.Reverify(context.Background(), domain.ReverifyInput{SubjectType: domain.SubjectOrg, SubjectKey: "acme"})
+	if err != nil {
+		t.Fatalf("Reverify: %v", err)
+	}
+	if out.State != domain.StateNone || len(mem.granted) != 0 {
+		t.Fatalf("expected no grants, got %+v (%d receipts)", out, len(mem.granted))
+	}
+}
+
+func TestReverifyOrgAbortsOnTransientResolve(t *testing.T) {
+	mem := &memManifest{lc: domain.LatestChallenge{
+		Action: "opt_in", EvidenceKind: "org_manifest", ArtifactHint: domain.ManifestFilename, Hash: "h1",
+	}}
+	res := mapResolver{err: &gh.GHStatusError{Status: 502}}
+	s := newManifestSvc(mem, `{"version":1,"org":"acme","hash":"h1","repos":["widgets"]}`, res)
+
+	if _, err := s.Reverify(
+		context.Background(), domain.ReverifyInput{SubjectType: domain.SubjectOrg, SubjectKey: "acme"},
+	); err == nil {
+		t.Fatalf("expected transient error to surface")
+	}
+	if len(mem.granted) != 0 {
+		t.Fatalf("expected nothing written, got %d receipts", len(mem.granted))
+	}
+}
+
+func TestReverifyOrgSkipsTransferredRepos(t *testing.T) {
+	mem := &memManifest{lc: domain.LatestChallenge{
+		Action: "opt_in", EvidenceKind: "org_manifest", ArtifactHint: domain.ManifestFilename, Hash: "h1",
+	}}
+	res := mapResolver{repos: map[string][]byte{"acme/widgets": {1}}, moved: map[string]bool{"acme/gadgets": true}}
+	s := newManifestSvc(mem, `{"version":1,"org":"acme","hash":"h1","repos":["widgets","gadgets"]}`, res)
+
+	out, err := s.Reverify(context.Background(), domain.ReverifyInput{SubjectType: domain.SubjectOrg, SubjectKey: "acme"})
+	if err != nil {
+		t.Fatalf("Reverify: %v", err)
+	}
+	if !slices.Equal(out.Manifest.Granted, []string{"acme/widgets"}) || len(mem.granted) != 1 {
+		t.Fatalf("granted %v (%d receipts), want only acme/widgets", out.Manifest.Granted, len(mem.granted))
+	}
+	if len(out.Manifest.Skipped) != 1 || out.Manifest.Skipped[0].Reason != "not in org" {
+		t.Fatalf("skipped = %+v, want acme/gadgets as not in org", out.Manifest.Skipped)
+	}
+}
+
+func TestOrgChallengeKeyIsNotARepo(t *testing.T) {
+	if key := orgChallengeKey("acme"); key == manifestResource("acme") || strings.Contains(key, "/") {
+		t.Fatalf("org challenge key %q would share a repo challenge's slot", key)
+	}
+}
@@ -89,10 +89,12 @@ func New(db repokit.TxRunner, binder repokit.Binder[repo.Repo], opt Options) *Sv
 	}
 }
 
-// Issue mints a deterministic hash and inserts a challenge row with explicit args
+// Issue mints a deterministic daily hash and records the challenge for the subject
+// It is idempotent per (subject, UTC day): repeat calls return the already-issued challenge
 func (s *Svc) Issue(ctx context.Context, in domain.IssueInput) (domain.IssueOutput, error) {
 	// deterministic daily hash
-	base := string(in.SubjectType) + ":" + in.SubjectKey + ":" + time.Now().UTC().Format("2006-01-02")
+	day := time.Now().UTC()
+	base := string(in.SubjectType) + ":" + in.SubjectKey + ":" + day.Format("2006-01-02")
 	mac := hmac.New(sha256.New, s.secret)
 	mac.Write([]byte(base))
 	hash := hex.EncodeToString(mac.Sum(nil))
@@ -103,6 +105,9 @@ func (s *Svc) Issue(ctx context.Context, in domain.IssueInput) (domain.IssueOutp
 	if action == "" {
 		action = "opt_in"
 	}
+	if in.SubjectType == domain.SubjectOrg {
+		return s.issueOrg(ctx, resource, action, hash, day)
+	}
 
 	evidenceKind := "repo_file"
 	artifactHint := "." + hash + ".txt"
@@ -111,21 +116,22 @@ func (s *Svc) Issue(ctx context.Context, in domain.IssueInput) (domain.IssueOutp
 		artifactHint = hash + ".txt"
 	}
 
-	// persist challenge
-	if err := s.Repo.InsertChallengeArgs(ctx, principal, resource, action, hash, evidenceKind, artifactHint); err != nil {
+	// persist challenge (or fetch today's existing one)
+	lc, err := s.Repo.InsertChallengeArgs(ctx, principal, resource, action, hash, evidenceKind, artifactHint, day)
+	if err != nil {
 		return domain.IssueOutput{}, err
 	}
 
-	// craft subject-specific output
-	out := domain.IssueOutput{Hash: hash}
+	// craft subject-specific output from the stored row
+	out := domain.IssueOutput{Hash: lc.Hash}
 	if principal == "repo" {
-		out.RepoFilename = "." + hash + ".txt"
+		out.RepoFilename = lc.ArtifactHint
 		out.Instructions = "Create a file at the repository root on the DEFAULT branch (e.g., main/master) " +
-			"named ." + hash + ".txt. Commit & push. Then POST to /api/v1/bouncer/reverify with the same subject."
+			"named " + lc.ArtifactHint + ". Commit & push. Then POST to /api/v1/bouncer/reverify with the same subject."
 	} else { // actor
-		out.GistFilename = hash + ".txt"
-		out.Instructions = "Create a PUBLIC GitHub Gist with a file named " + hash +
-			".txt (content may be empty). Then POST to /api/v1/bouncer/reverify with the same subject."
+		out.GistFilename = lc.ArtifactHint
+		out.Instructions = "Create a PUBLIC GitHub Gist with a file named " + lc.ArtifactHint +
+			" (content may be empty). Then POST to /api/v1/bouncer/reverify with the same subject."
 	}
 
 	return out, nil
@@ -133,6 +139,10 @@ func (s *Svc) Issue(ctx context.Context, in domain.IssueInput) (domain.IssueOutp
 
 // Reverify returns status after resolving principal HID
 func (s *Svc) Reverify(ctx context.Context, in domain.ReverifyInput) (domain.StatusRow, error) {
+	if in.SubjectType == domain.SubjectOrg {
+		return s.reverifyOrg(ctx, in.SubjectKey)
+	}
+
 	// Resolve subject: principal + HID + resource
 	rs, err := s.resolveHID(ctx, in.SubjectType, in.SubjectKey)
 	if err != nil {
@@ -288,6 +298,19 @@ func (s *Svc) Status(ctx context.Context, q domain.StatusQuery) (domain.StatusRo
 	}, nil
 }
 
+// History returns the append-only consent audit trail for a principal, oldest first
+func (s *Svc) History(ctx context.Context, q domain.HistoryQuery) (domain.HistoryOutput, error) {
+	rs, err := s.resolveHID(ctx, q.SubjectType, q.SubjectKey)
+	if err != nil {
+		return domain.HistoryOutput{}, err
+	}
+	ts, err := s.Repo.ConsentHistory(ctx, rs.principal, rs.hid)
+	if err != nil {
+		return domain.HistoryOutput{}, err
+	}
+	return domain.HistoryOutput{Transitions: ts}, nil
+}
+
 type hidPair struct {
 	principal string
 	hid       []byte
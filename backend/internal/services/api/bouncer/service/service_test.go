@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"swearjar/internal/modkit/repokit"
+	"swearjar/internal/platform/store"
+	"swearjar/internal/services/api/bouncer/domain"
+	"swearjar/internal/services/api/bouncer/repo"
+	bdom "swearjar/internal/services/bouncer/domain"
+)
+
+// memChallenges mimics consent_challenges with the (principal, resource, issued_day) upsert
+type memChallenges struct {
+	repo.Repo // unimplemented methods panic if the test reaches them
+
+	rows map[string]domain.LatestChallenge
+}
+
+func (m *memChallenges) InsertChallengeArgs(
+	_ context.Context,
+	principal, resource, action, hash, evidenceKind, artifactHint string,
+	day time.Time,
+) (domain.LatestChallenge, error) {
+	k := principal + "|" + resource + "|" + day.UTC().Format("2006-01-02")
+	if lc, ok := m.rows[k]; ok {
+		lc.Action = action
+		m.rows[k] = lc
+		return lc, nil
+	}
+	lc := domain.LatestChallenge{
+		Action:       action,
+		EvidenceKind: evidenceKind,
+		ArtifactHint: artifactHint,
+		Hash:         hash,
+		IssuedAtUnix: time.Now().Unix(),
+	}
+	m.rows[k] = lc
+	return lc, nil
+}
+
+type nopTx struct{ store.RowQuerier }
+
+func (nopTx) Tx(context.Context, func(store.RowQuerier) error) error { return nil }
+
+type nopResolver struct{}
+
+func (nopResolver) RepoHID(context.Context, string) ([]byte, bool, error)  { return nil, false, nil }
+func (nopResolver) ActorHID(context.Context, string) ([]byte, bool, error) { return nil, false, nil }
+
+type nopEvidence struct{}
+
+func (nopEvidence) DefaultBranch(context.Context, string) (string, error) { return "main", nil }
+func (nopEvidence) RepoFile(context.Context, string, string, string) (bool, string, error) {
+	return false, "", nil
+}
+func (nopEvidence) GistFile(context.Context, string, string) (bool, string, error) {
+	return false, "", nil
+}
+func (nopEvidence) RepoFileContent(context.Context, string, string, string) (bool, string, []byte, error) {
+	return false, "", nil, nil
+}
+
+type nopEnqueuer struct{}
+
+func (nopEnqueuer) EnqueueVerification(context.Context, bdom.EnqueueArgs) error { return nil }
+
+func newTestSvc(mem *memChallenges) *Svc {
+	return New(nopTx{}, repokit.BindFunc[repo.Repo](func(repokit.Queryer) repo.Repo { return mem }), Options{
+		Secret:   "test-secret",
+		Resolver: nopResolver{},
+		Evidence: nopEvidence{},
+		Enqueuer: nopEnqueuer{},
+	})
+}
+
+func TestIssueIsIdempotentPerDay(t *testing.T) {
+	mem := &memChallenges{rows: map[string]domain.LatestChallenge{}}
+	s := newTestSvc(mem)
+	ctx := context.Background()
+
+	for _, in := range []domain.IssueInput{
+		{SubjectType: domain.SubjectRepo, SubjectKey: "golang/go", Scope: domain.ScopeAllow},
+		{SubjectType: domain.SubjectActor, SubjectKey: "octocat", Scope: domain.ScopeAllow},
+	} {
+		first, err := s.Issue(ctx, in)
+		if err != nil {
+			t.Fatalf("first Issue(%s): %v", in.SubjectKey, err)
+		}
+		second, err := s.Issue(ctx, in)
+		if err != nil {
+			t.Fatalf("second Issue(%s): %v", in.SubjectKey, err)
+		}
+		if first != second {
+			t.Fatalf("Issue(%s) not idempotent:\n first=%+v\nsecond=%+v", in.SubjectKey, first, second)
+		}
+	}
+	if len(mem.rows) != 2 {
+		t.Fatalf("expected one challenge row per subject, got %d", len(mem.rows))
+	}
+}
+
+func TestIssueReturnsExistingChallenge(t *testing.T) {
+	mem := &memChallenges{rows: map[string]domain.LatestChallenge{}}
+	s := newTestSvc(mem)
+
+	// A row issued earlier today (e.g., before a secret rotation) wins over a freshly minted hash
+	day := time.Now().UTC().Format("2006-01-02")
+	mem.rows["repo|golang/go|"+day] = domain.LatestChallenge{
+		Action:       "opt_in",
+		EvidenceKind: "repo_file",
+		ArtifactHint: ".existing.txt",
+		Hash:         "existing",
+	}
+
+	out, err := s.Issue(context.Background(), domain.IssueInput{
+		SubjectType: domain.SubjectRepo, SubjectKey: "golang/go", Scope: domain.ScopeDeny,
+	})
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+	if out.Hash != "existing" || out.RepoFilename != ".existing.txt" {
+		t.Fatalf("expected existing challenge, got %+v", out)
+	}
+	if got := mem.rows["repo|golang/go|"+day].Action; got != "opt_out" {
+		t.Fatalf("expected action refreshed to opt_out, got %q", got)
+	}
+}
+
+// memHistory serves a fixed audit trail for one principal HID
+type memHistory struct {
+	repo.Repo
+
+	hid  []byte
+	rows []domain.ConsentTransition
+}
+
+func (m *memHistory) ConsentHistory(
+	_ context.Context, principal string, hid []byte,
+) ([]domain.ConsentTransition, error) {
+	if principal != "repo" || string(hid) != string(m.hid) {
+		return []domain.ConsentTransition{}, nil
+	}
+	return m.rows, nil
+}
+
+type fixedResolver struct{ hid []byte }
+
+func (f fixedResolver) RepoHID(context.Context, string) ([]byte, bool, error) {
+	return f.hid, true, nil
+}
+func (f fixedResolver) ActorHID(context.Context, string) ([]byte, bool, error) {
+	return nil, false, nil
+}
+
+func TestHistoryReturnsAuditTrail(t *testing.T) {
+	hid := []byte{0xab, 0xcd}
+	mem := &memHistory{hid: hid, rows: []domain.ConsentTransition{
+		{AtUnix: 100, Transition: "granted", Action: "opt_in", State: "active", VerifiedBy: "api"},
+		{AtUnix: 200, Transition: "revocation_pending", Action: "opt_in", State: "active", VerifiedBy: "worker"},
+		{AtUnix: 300, Transition: "reinstated", Action: "opt_in", State: "active", VerifiedBy: "worker"},
+	}}
+	s := New(nopTx{}, repokit.BindFunc[repo.Repo](func(repokit.Queryer) repo.Repo { return mem }), Options{
+		Secret:   "test-secret",
+		Resolver: fixedResolver{hid: hid},
+		Evidence: nopEvidence{},
+		Enqueuer: nopEnqueuer{},
+	})
+
+	ctx := context.Background()
+	out, err := s.History(ctx, domain.HistoryQuery{SubjectType: domain.SubjectRepo, SubjectKey: "golang/go"})
+	if err != nil {
+		t.Fatalf("History: %v", err)
+	}
+	ts := out.Transitions
+	if len(ts) != 3 || ts[0].Transition != "granted" || ts[2].VerifiedBy != "worker" {
+		t.Fatalf("unexpected trail: %+v", ts)
+	}
+
+	if _, err := s.History(ctx, domain.HistoryQuery{SubjectType: domain.SubjectActor, SubjectKey: "ghost"}); err == nil {
+		t.Fatalf("expected error for unresolvable subject")
+	}
+}
@@ -15,6 +15,11 @@ type Pinger interface {
 	Ping(stdctx.Context) error
 }
 
+// HealthReporter is satisfied by adapters with a background probe (e.g. CH auto-reconnect)
+type HealthReporter interface {
+	Healthy() bool
+}
+
 // Deps are the handler dependencies
 type Deps struct {
 	ServiceName string
@@ -108,6 +113,9 @@ func (h *handlers) ready(_ *http.Request) (any, error) {
 		if c == nil {
 			return ReadyCheck{Name: name, Status: "skipped"}
 		}
+		if hr, ok := c.(HealthReporter); ok && !hr.Healthy() {
+			return ReadyCheck{Name: name, Status: "fail", Error: "background probe failing; reconnecting"}
+		}
 		if p, ok := c.(Pinger); ok {
 			if err := p.Ping(ctx); err != nil {
 				return ReadyCheck{Name: name, Status: "fail", Error: err.Error()}
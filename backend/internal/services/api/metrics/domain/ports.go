@@ -0,0 +1,8 @@
+package domain
+
+import "context"
+
+// CollectorPort produces a metrics snapshot (cached briefly between scrapes)
+type CollectorPort interface {
+	Snapshot(ctx context.Context) (Snapshot, error)
+}
@@ -0,0 +1,54 @@
+// Package domain holds the types and ports for the metrics module
+package domain
+
+import (
+	"time"
+
+	hmdomain "swearjar/internal/services/hallmonitor/domain"
+)
+
+// Snapshot is a point-in-time view of pipeline gauges rendered on scrape
+// Each source (PG, CH) is collected independently; a failing source is
+// reported via SourceUp rather than failing the whole scrape
+type Snapshot struct {
+	CollectedAt time.Time
+
+	// Hallmonitor catalog queues (PG)
+	RepoQueue  hmdomain.QueueDepth
+	ActorQueue hmdomain.QueueDepth
+
+	// ingest_hours lifecycle counts keyed by status (PG)
+	BackfillHours   map[string]int64
+	NightshiftHours map[string]int64
+
+	// Sum of ingest_hours.inserted (PG); drops when hours are reset or pruned, so use deriv()
+	// rather than rate() for throughput
+	UtterancesInserted int64
+
+	// Hits per detector version (CH); monotonic, use rate() for detect throughput
+	HitsByDetver map[int32]uint64
+
+	// Last insert pool snapshot each detector version's detect run saved (PG, detect_autoscale)
+	DetectAutoscale []DetectAutoscale
+
+	// SourceUp reports whether each backing store answered ("pg", "ch")
+	SourceUp map[string]bool
+}
+
+// DetectAutoscale is one detect_autoscale row. Counters restart with each detect process
+type DetectAutoscale struct {
+	Detver       int32
+	Inserters    int64
+	InsertEWMAMS int64
+	Inserts      int64
+	Grows        int64
+	Shrinks      int64
+	UpdatedAt    time.Time
+}
+
+// IngestCounts is the ingest_hours rollup used to build a Snapshot
+type IngestCounts struct {
+	BackfillHours      map[string]int64
+	NightshiftHours    map[string]int64
+	UtterancesInserted int64
+}
@@ -0,0 +1,41 @@
+// Package http exposes the metrics snapshot in Prometheus text format
+package http
+
+import (
+	"bytes"
+	stdhttp "net/http"
+
+	"swearjar/internal/modkit/httpkit"
+	"swearjar/internal/services/api/metrics/domain"
+)
+
+// Register mounts the scrape endpoint on the given router
+func Register(r httpkit.Router, c domain.CollectorPort) {
+	h := &handlers{c: c}
+	r.Get("/", h.scrape)
+}
+
+type handlers struct{ c domain.CollectorPort }
+
+// contentType is the Prometheus text exposition format
+const contentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// swagger:route GET /metrics Meta metrics
+// @Summary Prometheus metrics (queue depth, ingest hours, detect throughput)
+// @Tags Meta
+// @Produce plain
+// @Success 200 {string} string "Prometheus text exposition"
+// @Router /metrics [get]
+func (h *handlers) scrape(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+	snap, err := h.c.Snapshot(r.Context())
+	if err != nil {
+		stdhttp.Error(w, err.Error(), stdhttp.StatusServiceUnavailable)
+		return
+	}
+	var buf bytes.Buffer
+	Render(&buf, snap)
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", "no-store")
+	_, _ = w.Write(buf.Bytes())
+}
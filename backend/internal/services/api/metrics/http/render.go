@@ -0,0 +1,125 @@
+package http
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+
+	"swearjar/internal/services/api/metrics/domain"
+	hmdomain "swearjar/internal/services/hallmonitor/domain"
+)
+
+// Render writes snap in Prometheus text exposition format
+// Label sets are emitted in sorted order so output is stable between scrapes
+func Render(w io.Writer, snap domain.Snapshot) {
+	family(w, "swearjar_catalog_queue_depth", "gauge", "Rows in the hallmonitor catalog queues")
+	queue(w, "repo", snap.RepoQueue)
+	queue(w, "actor", snap.ActorQueue)
+
+	family(w, "swearjar_ingest_hours", "gauge", "ingest_hours rows by pipeline stage and status")
+	for _, k := range sortedKeys(snap.BackfillHours) {
+		sample(w, "swearjar_ingest_hours", labels("stage", "backfill", "status", k), snap.BackfillHours[k])
+	}
+	for _, k := range sortedKeys(snap.NightshiftHours) {
+		sample(w, "swearjar_ingest_hours", labels("stage", "nightshift", "status", k), snap.NightshiftHours[k])
+	}
+
+	// A sum over ingest_hours rows, which can be reset or pruned, so it can go down: not a counter
+	family(w, "swearjar_ingest_utterances_inserted", "gauge", "Utterances inserted by backfill")
+	sample(w, "swearjar_ingest_utterances_inserted", "", snap.UtterancesInserted)
+
+	family(w, "swearjar_detect_hits_total", "counter", "Stored hits by detector version")
+	vers := make([]int32, 0, len(snap.HitsByDetver))
+	for v := range snap.HitsByDetver {
+		vers = append(vers, v)
+	}
+	slices.Sort(vers)
+	for _, v := range vers {
+		sample(w, "swearjar_detect_hits_total", labels("detver", strconv.Itoa(int(v))), snap.HitsByDetver[v])
+	}
+
+	detectAutoscale(w, snap.DetectAutoscale)
+
+	family(w, "swearjar_metrics_source_up", "gauge", "Whether the backing store answered the last collection")
+	for _, k := range sortedKeys(snap.SourceUp) {
+		up := 0
+		if snap.SourceUp[k] {
+			up = 1
+		}
+		sample(w, "swearjar_metrics_source_up", labels("source", k), up)
+	}
+
+	family(w, "swearjar_metrics_collected_timestamp_seconds", "gauge", "Unix time of the cached collection")
+	sample(w, "swearjar_metrics_collected_timestamp_seconds", "", snap.CollectedAt.Unix())
+}
+
+// detectAutoscale renders the detect insert pool per detver; absent until a run with
+// CORE_DETECT_AUTOSCALE saves a snapshot
+func detectAutoscale(w io.Writer, rows []domain.DetectAutoscale) {
+	family(w, "swearjar_detect_inserters", "gauge", "Hits inserts the detect autoscaler allows in flight")
+	for _, r := range rows {
+		sample(w, "swearjar_detect_inserters", detverLabel(r.Detver), r.Inserters)
+	}
+	family(w, "swearjar_detect_insert_latency_ewma_milliseconds", "gauge", "Smoothed hits insert latency")
+	for _, r := range rows {
+		sample(w, "swearjar_detect_insert_latency_ewma_milliseconds", detverLabel(r.Detver), r.InsertEWMAMS)
+	}
+	family(w, "swearjar_detect_inserts_total", "counter", "Hits inserts observed by the detect autoscaler")
+	for _, r := range rows {
+		sample(w, "swearjar_detect_inserts_total", detverLabel(r.Detver), r.Inserts)
+	}
+	family(w, "swearjar_detect_insert_pool_resizes_total", "counter", "Detect insert pool resizes by direction")
+	for _, r := range rows {
+		v := strconv.Itoa(int(r.Detver))
+		sample(w, "swearjar_detect_insert_pool_resizes_total", labels("detver", v, "direction", "grow"), r.Grows)
+		sample(w, "swearjar_detect_insert_pool_resizes_total", labels("detver", v, "direction", "shrink"), r.Shrinks)
+	}
+	family(w, "swearjar_detect_autoscale_updated_timestamp_seconds", "gauge", "Unix time of the saved snapshot")
+	for _, r := range rows {
+		sample(w, "swearjar_detect_autoscale_updated_timestamp_seconds", detverLabel(r.Detver), r.UpdatedAt.Unix())
+	}
+}
+
+func detverLabel(v int32) string { return labels("detver", strconv.Itoa(int(v))) }
+
+func queue(w io.Writer, name string, d hmdomain.QueueDepth) {
+	sample(w, "swearjar_catalog_queue_depth", labels("queue", name, "state", "total"), d.Total)
+	sample(w, "swearjar_catalog_queue_depth", labels("queue", name, "state", "due"), d.Due)
+	sample(w, "swearjar_catalog_queue_depth", labels("queue", name, "state", "retrying"), d.Retrying)
+}
+
+func family(w io.Writer, name, typ, help string) {
+	_, _ = fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+func sample[N int | int64 | uint64](w io.Writer, name, lbls string, v N) {
+	_, _ = fmt.Fprintf(w, "%s%s %d\n", name, lbls, v)
+}
+
+// labels renders k/v pairs as {k="v",...}; values are quoted with Go escaping,
+// which covers the backslash, quote and newline escapes the format requires
+func labels(kv ...string) string {
+	if len(kv) == 0 {
+		return ""
+	}
+	b := []byte{'{'}
+	for i := 0; i+1 < len(kv); i += 2 {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		b = append(b, kv[i]...)
+		b = append(b, '=')
+		b = strconv.AppendQuote(b, kv[i+1])
+	}
+	return string(append(b, '}'))
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	slices.Sort(out)
+	return out
+}
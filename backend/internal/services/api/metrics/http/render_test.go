@@ -0,0 +1,83 @@
+package http
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"swearjar/internal/services/api/metrics/domain"
+	hmdomain "swearjar/internal/services/hallmonitor/domain"
+)
+
+func TestRender(t *testing.T) {
+	t.Parallel()
+
+	snap := domain.Snapshot{
+		CollectedAt: time.Unix(1700000000, 0),
+		RepoQueue:   hmdomain.QueueDepth{Total: 10, Due: 4, Retrying: 1},
+		ActorQueue:  hmdomain.QueueDepth{Total: 3},
+		// map order is random; output must not be
+		BackfillHours:      map[string]int64{"retry": 2, "done": 40, "error": 1},
+		NightshiftHours:    map[string]int64{"say \"hi\"\\\n": 5},
+		UtterancesInserted: 1234,
+		HitsByDetver:       map[int32]uint64{10: 7, 2: 9},
+		DetectAutoscale: []domain.DetectAutoscale{{
+			Detver: 2, Inserters: 4, InsertEWMAMS: 120, Inserts: 50, Grows: 3, Shrinks: 1,
+			UpdatedAt: time.Unix(1699999990, 0),
+		}},
+		SourceUp: map[string]bool{"pg": true, "ch": false},
+	}
+
+	want := `# HELP swearjar_catalog_queue_depth Rows in the hallmonitor catalog queues
+# TYPE swearjar_catalog_queue_depth gauge
+swearjar_catalog_queue_depth{queue="repo",state="total"} 10
+swearjar_catalog_queue_depth{queue="repo",state="due"} 4
+swearjar_catalog_queue_depth{queue="repo",state="retrying"} 1
+swearjar_catalog_queue_depth{queue="actor",state="total"} 3
+swearjar_catalog_queue_depth{queue="actor",state="due"} 0
+swearjar_catalog_queue_depth{queue="actor",state="retrying"} 0
+# HELP swearjar_ingest_hours ingest_hours rows by pipeline stage and status
+# TYPE swearjar_ingest_hours gauge
+swearjar_ingest_hours{stage="backfill",status="done"} 40
+swearjar_ingest_hours{stage="backfill",status="error"} 1
+swearjar_ingest_hours{stage="backfill",status="retry"} 2
+swearjar_ingest_hours{stage="nightshift",status="say \"hi\"\\\n"} 5
+# HELP swearjar_ingest_utterances_inserted Utterances inserted by backfill
+# TYPE swearjar_ingest_utterances_inserted gauge
+swearjar_ingest_utterances_inserted 1234
+# HELP swearjar_detect_hits_total Stored hits by detector version
+# TYPE swearjar_detect_hits_total counter
+swearjar_detect_hits_total{detver="2"} 9
+swearjar_detect_hits_total{detver="10"} 7
+# HELP swearjar_detect_inserters Hits inserts the detect autoscaler allows in flight
+# TYPE swearjar_detect_inserters gauge
+swearjar_detect_inserters{detver="2"} 4
+# HELP swearjar_detect_insert_latency_ewma_milliseconds Smoothed hits insert latency
+# TYPE swearjar_detect_insert_latency_ewma_milliseconds gauge
+swearjar_detect_insert_latency_ewma_milliseconds{detver="2"} 120
+# HELP swearjar_detect_inserts_total Hits inserts observed by the detect autoscaler
+# TYPE swearjar_detect_inserts_total counter
+swearjar_detect_inserts_total{detver="2"} 50
+# HELP swearjar_detect_insert_pool_resizes_total Detect insert pool resizes by direction
+# TYPE swearjar_detect_insert_pool_resizes_total counter
+swearjar_detect_insert_pool_resizes_total{detver="2",direction="grow"} 3
+swearjar_detect_insert_pool_resizes_total{detver="2",direction="shrink"} 1
+# HELP swearjar_detect_autoscale_updated_timestamp_seconds Unix time of the saved snapshot
+# TYPE swearjar_detect_autoscale_updated_timestamp_seconds gauge
+swearjar_detect_autoscale_updated_timestamp_seconds{detver="2"} 1699999990
+# HELP swearjar_metrics_source_up Whether the backing store answered the last collection
+# TYPE swearjar_metrics_source_up gauge
+swearjar_metrics_source_up{source="ch"} 0
+swearjar_metrics_source_up{source="pg"} 1
+# HELP swearjar_metrics_collected_timestamp_seconds Unix time of the cached collection
+# TYPE swearjar_metrics_collected_timestamp_seconds gauge
+swearjar_metrics_collected_timestamp_seconds 1700000000
+`
+	for range 5 { // a few renders so map iteration order gets a chance to differ
+		var b strings.Builder
+		Render(&b, snap)
+		if got := b.String(); got != want {
+			t.Fatalf("exposition mismatch\n got:\n%s\nwant:\n%s", got, want)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+// Package module wires the Prometheus metrics endpoint using modkit
+package module
+
+import (
+	"net/http"
+
+	modkit "swearjar/internal/modkit"
+	"swearjar/internal/modkit/httpkit"
+	"swearjar/internal/modkit/repokit"
+	str "swearjar/internal/platform/strings"
+
+	"swearjar/internal/services/api/metrics/domain"
+	metricshttp "swearjar/internal/services/api/metrics/http"
+	metricsrepo "swearjar/internal/services/api/metrics/repo"
+	metricssvc "swearjar/internal/services/api/metrics/service"
+)
+
+// Ports exposes the collector for cross-module lookups
+type Ports struct {
+	Collector domain.CollectorPort
+}
+
+// Module implements the metrics module
+// It is mounted at the server root (not under /api/v1) so scrapers can use /metrics
+type Module struct {
+	deps   modkit.Deps
+	name   string
+	prefix string
+	opts   Options
+
+	mws   []func(http.Handler) http.Handler
+	ports Ports
+
+	register func(httpkit.Router)
+}
+
+// New constructs the metrics module
+func New(deps modkit.Deps, opts ...modkit.Option) modkit.Module {
+	b := modkit.Build(append([]modkit.Option{modkit.WithName("metrics"), modkit.WithPrefix("/metrics")}, opts...)...)
+
+	o := FromConfig(deps.Cfg)
+	svc := metricssvc.New(repokit.TxRunner(deps.PG), metricsrepo.NewHybrid(deps.CH), o.CacheTTL)
+
+	m := &Module{
+		deps:   deps,
+		name:   b.Name,
+		prefix: b.Prefix,
+		opts:   o,
+		mws:    b.Mw,
+		ports:  Ports{Collector: svc},
+	}
+
+	external := b.Register
+	m.register = func(r httpkit.Router) {
+		metricshttp.Register(r, svc)
+		if external != nil {
+			external(r)
+		}
+	}
+	return m
+}
+
+// MountRoutes mounts the scrape endpoint unless disabled via CORE_METRICS_ENABLED
+func (m *Module) MountRoutes(r httpkit.Router) {
+	if !m.opts.Enabled {
+		return
+	}
+	r.Route(m.prefix, func(rr httpkit.Router) {
+		for _, mw := range m.mws {
+			rr.Use(mw)
+		}
+		if m.register != nil {
+			m.register(rr)
+		}
+	})
+}
+
+// Name is the module name
+func (m *Module) Name() string { return str.MustString(m.name, "module name") }
+
+// Prefix is the module route prefix
+func (m *Module) Prefix() string { return str.MustPrefix(m.prefix) }
+
+// Middlewares is the module middlewares
+func (m *Module) Middlewares() []func(http.Handler) http.Handler { return m.mws }
+
+// Ports returns the module ports
+func (m *Module) Ports() any { return m.ports }
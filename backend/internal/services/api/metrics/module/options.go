@@ -0,0 +1,20 @@
+package module
+
+import (
+	"time"
+
+	"swearjar/internal/platform/config"
+)
+
+// Options controls the metrics endpoint
+type Options struct {
+	Enabled  bool          `env:"ENABLED" default:"true"`
+	CacheTTL time.Duration `env:"CACHE_TTL" default:"15s"`
+}
+
+// FromConfig reads CORE_METRICS_* values from process config/env
+func FromConfig(cfg config.Conf) Options {
+	var o Options
+	cfg.Prefix("CORE_METRICS_").MustBind(&o)
+	return o
+}
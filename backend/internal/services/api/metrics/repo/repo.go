@@ -0,0 +1,142 @@
+// Package repo provides the read-only count queries behind the metrics module
+package repo
+
+import (
+	"context"
+
+	"swearjar/internal/modkit/repokit"
+	"swearjar/internal/platform/store"
+	"swearjar/internal/services/api/metrics/domain"
+
+	hmdomain "swearjar/internal/services/hallmonitor/domain"
+	hmrepo "swearjar/internal/services/hallmonitor/repo"
+)
+
+// Repo defines the metrics count queries
+type Repo interface {
+	// PG
+	QueueDepths(ctx context.Context) (repoQ, actorQ hmdomain.QueueDepth, err error)
+	IngestCounts(ctx context.Context) (domain.IngestCounts, error)
+	DetectAutoscale(ctx context.Context) ([]domain.DetectAutoscale, error)
+
+	// CH
+	HitsByDetver(ctx context.Context) (map[int32]uint64, error)
+}
+
+// NewHybrid constructs a metrics binder using PG (via Bind) and CH
+func NewHybrid(ch store.Clickhouse) repokit.Binder[Repo] { return &hybridBinder{ch: ch} }
+
+type hybridBinder struct{ ch store.Clickhouse }
+
+// Bind binds a Queryer to produce a Repo
+func (b *hybridBinder) Bind(q repokit.Queryer) Repo {
+	return &hybridStore{pg: q, ch: b.ch, hm: hmrepo.NewPG().Bind(q)}
+}
+
+type hybridStore struct {
+	pg repokit.Queryer
+	ch store.Clickhouse
+	hm hmrepo.Repo
+}
+
+// QueueDepths delegates to the hallmonitor queue counters
+func (s *hybridStore) QueueDepths(ctx context.Context) (hmdomain.QueueDepth, hmdomain.QueueDepth, error) {
+	rq, err := s.hm.CountRepoQueue(ctx)
+	if err != nil {
+		return hmdomain.QueueDepth{}, hmdomain.QueueDepth{}, err
+	}
+	aq, err := s.hm.CountActorQueue(ctx)
+	if err != nil {
+		return hmdomain.QueueDepth{}, hmdomain.QueueDepth{}, err
+	}
+	return rq, aq, nil
+}
+
+// IngestCounts rolls up ingest_hours by backfill and nightshift status
+func (s *hybridStore) IngestCounts(ctx context.Context) (domain.IngestCounts, error) {
+	out := domain.IngestCounts{
+		BackfillHours:   map[string]int64{},
+		NightshiftHours: map[string]int64{},
+	}
+
+	rows, err := s.pg.Query(ctx, `
+		SELECT bf_status::text, ns_status::text, count(*), coalesce(sum(inserted), 0)
+		FROM ingest_hours
+		GROUP BY 1, 2
+	`)
+	if err != nil {
+		return out, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			bf, ns string
+			n, ins int64
+		)
+		if err := rows.Scan(&bf, &ns, &n, &ins); err != nil {
+			return out, err
+		}
+		out.BackfillHours[bf] += n
+		out.NightshiftHours[ns] += n
+		out.UtterancesInserted += ins
+	}
+	return out, rows.Err()
+}
+
+// DetectAutoscale reads the insert pool snapshots detect runs saved, by detector version
+func (s *hybridStore) DetectAutoscale(ctx context.Context) ([]domain.DetectAutoscale, error) {
+	rows, err := s.pg.Query(ctx, `
+		SELECT detector_version, inserters, insert_ewma_ms, inserts, grows, shrinks, updated_at
+		FROM detect_autoscale
+		ORDER BY detector_version
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.DetectAutoscale
+	for rows.Next() {
+		var (
+			d         domain.DetectAutoscale
+			inserters int32
+		)
+		err := rows.Scan(&d.Detver, &inserters, &d.InsertEWMAMS, &d.Inserts, &d.Grows, &d.Shrinks, &d.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		d.Inserters = int64(inserters)
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
+
+// HitsByDetver counts stored hits per detector version
+func (s *hybridStore) HitsByDetver(ctx context.Context) (map[int32]uint64, error) {
+	out := map[int32]uint64{}
+	if s.ch == nil {
+		return out, nil
+	}
+	rs, err := s.ch.Query(ctx, `
+		SELECT detector_version, count()
+		FROM swearjar.hits
+		GROUP BY detector_version
+	`)
+	if err != nil {
+		return out, err
+	}
+	defer rs.Close()
+
+	for rs.Next() {
+		var (
+			ver int32
+			n   uint64
+		)
+		if err := rs.Scan(&ver, &n); err != nil {
+			return out, err
+		}
+		out[ver] = n
+	}
+	return out, rs.Err()
+}
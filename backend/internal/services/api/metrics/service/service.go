@@ -0,0 +1,98 @@
+// Package service collects pipeline gauges for the metrics endpoint
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"swearjar/internal/modkit/repokit"
+	"swearjar/internal/platform/logger"
+	"swearjar/internal/services/api/metrics/domain"
+	"swearjar/internal/services/api/metrics/repo"
+)
+
+// Service defines the metrics service contract
+type Service interface {
+	domain.CollectorPort
+}
+
+// Svc implements the metrics collector with a short-lived snapshot cache
+// Scrapers (and multiple Prometheus replicas) hit this often; counting queue
+// rows and hits on every request is wasteful, so results are reused for TTL
+type Svc struct {
+	repo repo.Repo
+	ttl  time.Duration
+	now  func() time.Time
+
+	mu     sync.Mutex
+	cached domain.Snapshot
+	have   bool
+}
+
+// New constructs a metrics collector
+func New(db repokit.TxRunner, binder repokit.Binder[repo.Repo], ttl time.Duration) *Svc {
+	if db == nil {
+		panic("metrics.Service requires a non nil TxRunner")
+	}
+	if binder == nil {
+		panic("metrics.Service requires a non nil Repo binder")
+	}
+	return &Svc{repo: binder.Bind(db), ttl: ttl, now: time.Now}
+}
+
+// Snapshot returns the cached snapshot if fresh, otherwise collects a new one
+// Concurrent scrapes during a refresh wait for it rather than stampeding the DBs
+func (s *Svc) Snapshot(ctx context.Context) (domain.Snapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.have || s.now().Sub(s.cached.CollectedAt) >= s.ttl {
+		s.cached, s.have = s.collect(ctx), true
+	}
+	return s.cached, nil
+}
+
+// collect queries each source independently; failures mark the source down
+func (s *Svc) collect(ctx context.Context) domain.Snapshot {
+	snap := domain.Snapshot{
+		CollectedAt:     s.now().UTC(),
+		BackfillHours:   map[string]int64{},
+		NightshiftHours: map[string]int64{},
+		HitsByDetver:    map[int32]uint64{},
+		SourceUp:        map[string]bool{"pg": true, "ch": true},
+	}
+	log := logger.C(ctx)
+
+	if rq, aq, err := s.repo.QueueDepths(ctx); err != nil {
+		log.Warn().Err(err).Msg("metrics: queue depths failed")
+		snap.SourceUp["pg"] = false
+	} else {
+		snap.RepoQueue, snap.ActorQueue = rq, aq
+	}
+
+	if ic, err := s.repo.IngestCounts(ctx); err != nil {
+		log.Warn().Err(err).Msg("metrics: ingest counts failed")
+		snap.SourceUp["pg"] = false
+	} else {
+		snap.BackfillHours = ic.BackfillHours
+		snap.NightshiftHours = ic.NightshiftHours
+		snap.UtterancesInserted = ic.UtterancesInserted
+	}
+
+	if as, err := s.repo.DetectAutoscale(ctx); err != nil {
+		log.Warn().Err(err).Msg("metrics: detect autoscale failed")
+		snap.SourceUp["pg"] = false
+	} else {
+		snap.DetectAutoscale = as
+	}
+
+	if hits, err := s.repo.HitsByDetver(ctx); err != nil {
+		log.Warn().Err(err).Msg("metrics: hits by detver failed")
+		snap.SourceUp["ch"] = false
+	} else {
+		snap.HitsByDetver = hits
+	}
+
+	return snap
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"swearjar/internal/modkit/repokit"
+	"swearjar/internal/services/api/metrics/domain"
+	"swearjar/internal/services/api/metrics/repo"
+	hmdomain "swearjar/internal/services/hallmonitor/domain"
+)
+
+// countingRepo answers every query, counting collections; chErr fails the CH source
+type countingRepo struct {
+	calls int
+	chErr error
+}
+
+func (r *countingRepo) QueueDepths(context.Context) (hmdomain.QueueDepth, hmdomain.QueueDepth, error) {
+	r.calls++
+	return hmdomain.QueueDepth{Total: int64(r.calls)}, hmdomain.QueueDepth{}, nil
+}
+
+func (r *countingRepo) IngestCounts(context.Context) (domain.IngestCounts, error) {
+	return domain.IngestCounts{BackfillHours: map[string]int64{"done": 1}, UtterancesInserted: 9}, nil
+}
+
+func (r *countingRepo) DetectAutoscale(context.Context) ([]domain.DetectAutoscale, error) {
+	return nil, nil
+}
+
+func (r *countingRepo) HitsByDetver(context.Context) (map[int32]uint64, error) {
+	if r.chErr != nil {
+		return nil, r.chErr
+	}
+	return map[int32]uint64{1: 5}, nil
+}
+
+type nopTx struct{ repokit.TxRunner }
+
+func newTestSvc(r repo.Repo, ttl time.Duration, clock *time.Time) *Svc {
+	s := New(nopTx{}, repokit.BindFunc[repo.Repo](func(repokit.Queryer) repo.Repo { return r }), ttl)
+	s.now = func() time.Time { return *clock }
+	return s
+}
+
+func TestSnapshotReusesWithinTTL(t *testing.T) {
+	t.Parallel()
+
+	clock := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	r := &countingRepo{}
+	s := newTestSvc(r, 30*time.Second, &clock)
+	ctx := context.Background()
+
+	first, _ := s.Snapshot(ctx)
+	clock = clock.Add(29 * time.Second)
+	again, _ := s.Snapshot(ctx)
+	if r.calls != 1 || again.RepoQueue.Total != 1 || !again.CollectedAt.Equal(first.CollectedAt) {
+		t.Fatalf("within TTL: %d collections, snapshot %+v", r.calls, again)
+	}
+
+	clock = clock.Add(time.Second)
+	fresh, _ := s.Snapshot(ctx)
+	if r.calls != 2 || fresh.RepoQueue.Total != 2 || !fresh.CollectedAt.Equal(clock) {
+		t.Fatalf("at TTL: %d collections, snapshot %+v", r.calls, fresh)
+	}
+}
+
+func TestSnapshotMarksFailingSourceDown(t *testing.T) {
+	t.Parallel()
+
+	clock := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	r := &countingRepo{chErr: errors.New("ch down")}
+	s := newTestSvc(r, time.Minute, &clock)
+
+	snap, err := s.Snapshot(context.Background())
+	if err != nil {
+		t.Fatalf("a failing source should not fail the scrape: %v", err)
+	}
+	if snap.SourceUp["ch"] || !snap.SourceUp["pg"] {
+		t.Fatalf("source_up %v, want ch down and pg up", snap.SourceUp)
+	}
+	if snap.HitsByDetver == nil || len(snap.HitsByDetver) != 0 || snap.UtterancesInserted != 9 {
+		t.Fatalf("snapshot %+v, want empty hits alongside the PG counts", snap)
+	}
+
+	// The next collection after the TTL picks the source back up
+	r.chErr = nil
+	clock = clock.Add(time.Minute)
+	if snap, _ = s.Snapshot(context.Background()); !snap.SourceUp["ch"] || snap.HitsByDetver[1] != 5 {
+		t.Fatalf("recovered snapshot %+v", snap)
+	}
+}
@@ -16,11 +16,19 @@ type PageOpts struct {
 	Limit  int    `json:"limit,omitempty"  validate:"omitempty,min=1,max=200" example:"100"`
 }
 
+// Freshness labels a window that reaches into hours detection may not have processed yet
+// ProcessedThrough is the exclusive UTC hour hits are complete up to (RFC3339; empty when none is)
+type Freshness struct {
+	Complete         bool   `json:"complete"                    example:"false"`
+	ProcessedThrough string `json:"processed_through,omitempty" example:"2025-09-18T14:00:00Z"`
+}
+
 // GlobalOptions is a shared bundle of filters and options for queries
 // Embed this in endpoint specific inputs to keep shapes consistent
+// Interval buckets are labeled by their start date; month, quarter and year series are sparse
 type GlobalOptions struct {
 	Range        TimeRange `json:"range"`
-	Interval     string    `json:"interval,omitempty"  validate:"omitempty,oneof=auto hour day week month" example:"day"`
+	Interval     string    `json:"interval,omitempty"  validate:"omitempty,oneof=auto hour day week month quarter year" example:"day"` //nolint:lll
 	TZ           string    `json:"tz,omitempty"        validate:"omitempty,printascii,max=64" example:"UTC"`
 	Normalize    string    `json:"normalize,omitempty" validate:"omitempty,oneof=none per_utterance" example:"none"`
 	LangReliable *bool     `json:"lang_reliable,omitempty" example:"true"`
@@ -33,6 +41,12 @@ type GlobalOptions struct {
 	NLLangs   []string `json:"nl_langs,omitempty"   validate:"omitempty,dive" example:"en"`
 	CodeLangs []string `json:"code_langs,omitempty" validate:"omitempty,dive,printascii" example:"JavaScript"`
 
+	// Bot filters; both are off by default and may be combined for "humans only, about humans"
+	// ExcludeBotActors drops utterances authored by cataloged bot accounts (hits and denominators)
+	// ExcludeBotTargets drops hits whose target is a bot (target_type = bot); denominators are unaffected
+	ExcludeBotActors  bool `json:"exclude_bot_actors,omitempty"  example:"true"`
+	ExcludeBotTargets bool `json:"exclude_bot_targets,omitempty" example:"false"`
+
 	Metric string `json:"metric,omitempty" validate:"omitempty,oneof=intensity coverage rarity counts" example:"counts"`
 	Series string `json:"series,omitempty" validate:"omitempty,oneof=hits offending_utterances all_utterances" example:"hits"` //nolint:lll
 
@@ -47,7 +61,7 @@ type KPIStripInput struct {
 
 // KPIStripResp returns totals for the window
 type KPIStripResp struct {
-	// Echo back the window start (YYYY-MM-DD) for labeling on UI
+	// Echo back the window start (YYYY-MM-DD in the requested TZ) for labeling on UI
 	Day                 string  `json:"day"                    example:"2025-09-18"`
 	Hits                int64   `json:"hits"                   example:"6157"`
 	OffendingUtterances int64   `json:"offending_utterances"   example:"4123"`
@@ -57,6 +71,30 @@ type KPIStripResp struct {
 	Intensity           float64 `json:"intensity,omitempty"      example:"1.49"`   // hits / offending_utterances
 	Coverage            float64 `json:"coverage,omitempty"       example:"0.3"`    // offending_utterances / all_utter.
 	Rarity              float64 `json:"rarity,omitempty"         example:"0.0045"` // hits / all_utterances
+
+	// Freshness is set when the window reaches into the last FRESHNESS_LAG of data
+	Freshness *Freshness `json:"freshness,omitempty"`
+}
+
+// OverviewInput requests the homepage hero panels for one window
+// TopN bounds the top terms list (default 10); any page options are ignored
+type OverviewInput struct {
+	GlobalOptions
+	TopN int `json:"top_n,omitempty" validate:"omitempty,min=1,max=50" example:"10"`
+}
+
+// OverviewResp bundles the KPI strip, top terms and weekly heatmap
+// A panel whose query failed is null and listed in Failed; the others are intact
+type OverviewResp struct {
+	KPI      *KPIStripResp      `json:"kpi"`
+	TopTerms *TopTermsResp      `json:"top_terms"`
+	Heatmap  *HeatmapWeeklyResp `json:"heatmap"`
+
+	Partial bool     `json:"partial,omitempty" example:"false"`
+	Failed  []string `json:"failed,omitempty"  example:"top_terms"`
+
+	// Freshness is set when the window reaches into the last FRESHNESS_LAG of data
+	Freshness *Freshness `json:"freshness,omitempty"`
 }
 
 // TimeseriesHitsInput carries shared options for the hits series
@@ -87,11 +125,30 @@ type TimeseriesPoint struct {
 type TimeseriesHitsResp struct {
 	Interval string            `json:"interval" example:"day"`
 	Series   []TimeseriesPoint `json:"series"`
+
+	// Partial is set when the utterance aggregate failed; hits are intact but utterance-derived
+	// fields (all_utterances, coverage, rarity) are zero
+	Partial bool `json:"partial,omitempty" example:"false"`
+
+	// Freshness is set when the window reaches into the last FRESHNESS_LAG of data
+	Freshness *Freshness `json:"freshness,omitempty"`
 }
 
 // HeatmapWeeklyInput carries shared options for the weekly heatmap
 type HeatmapWeeklyInput struct {
 	GlobalOptions
+
+	// BusinessHours, when set, adds an in-hours vs off-hours split to the response
+	BusinessHours *WorkWeek `json:"business_hours,omitempty"`
+}
+
+// WorkWeek defines business hours as a set of weekdays and an hour range in the request TZ
+// Days use the heatmap convention (0=Sun..6=Sat) and default to Mon..Fri when empty
+// The range is [StartHour, EndHour); StartHour > EndHour wraps past midnight
+type WorkWeek struct {
+	Days      []int `json:"days,omitempty" validate:"omitempty,max=7,dive,min=0,max=6" example:"1"`
+	StartHour int   `json:"start_hour"     validate:"min=0,max=23" example:"9"`
+	EndHour   int   `json:"end_hour"       validate:"min=0,max=24,nefield=StartHour" example:"17"`
 }
 
 // HeatmapCell is a single cell in the weekly heatmap
@@ -104,10 +161,30 @@ type HeatmapCell struct {
 	Ratio               float64 `json:"ratio,omitempty" example:"0.0167"`
 }
 
+// HeatmapBucket aggregates heatmap cells on one side of the business-hours split
+type HeatmapBucket struct {
+	Cells               int     `json:"cells"                example:"40"`
+	Hits                int64   `json:"hits"                 example:"1520"`
+	OffendingUtterances int64   `json:"offending_utterances" example:"1033"`
+	Utterances          int64   `json:"utterances"           example:"402113"`
+	Rarity              float64 `json:"rarity,omitempty"     example:"0.0038"` // hits / utterances
+	Coverage            float64 `json:"coverage,omitempty"   example:"0.0026"` // offending_utterances / utterances
+}
+
+// HeatmapSplit compares business hours against everything else
+type HeatmapSplit struct {
+	InHours  HeatmapBucket `json:"in_hours"`
+	OffHours HeatmapBucket `json:"off_hours"`
+}
+
 // HeatmapWeeklyResp is the response for the weekly heatmap
 type HeatmapWeeklyResp struct {
-	Z    string        `json:"z" example:"hits"`
-	Grid []HeatmapCell `json:"grid"`
+	Z             string        `json:"z" example:"hits"`
+	Grid          []HeatmapCell `json:"grid"`
+	BusinessHours *HeatmapSplit `json:"business_hours,omitempty"`
+
+	// Freshness is set when the window reaches into the last FRESHNESS_LAG of data
+	Freshness *Freshness `json:"freshness,omitempty"`
 }
 
 // LangBarsInput carries shared options for natural language bars
@@ -166,6 +243,33 @@ type CodeLangBarsResp struct {
 	Items []CodeLangBarItem `json:"items"`
 }
 
+// CodeLangTimeseriesInput carries options for code language trends over time
+// Buckets follow Interval and are dense-filled; CodeLangs narrows the series, TopN caps them
+type CodeLangTimeseriesInput struct {
+	GlobalOptions
+
+	TopN int `json:"top_n,omitempty" validate:"omitempty,min=1,max=20" example:"8"`
+}
+
+// CodeLangTimeseriesPoint is one bucket for one code language
+type CodeLangTimeseriesPoint struct {
+	T     string `json:"t"     example:"2025-08-01"`
+	Hits  int64  `json:"hits"  example:"320"`
+	Repos int64  `json:"repos" example:"41"`
+}
+
+// CodeLangSeries is the timeseries for one repo primary language
+type CodeLangSeries struct {
+	CodeLang string                    `json:"code_lang" example:"Rust"`
+	Points   []CodeLangTimeseriesPoint `json:"points"`
+}
+
+// CodeLangTimeseriesResp is the response for code language trends
+type CodeLangTimeseriesResp struct {
+	Interval string           `json:"interval" example:"week"`
+	Series   []CodeLangSeries `json:"series"`
+}
+
 // CategoriesStackInput carries options for category and severity mix
 type CategoriesStackInput struct {
 	GlobalOptions
@@ -209,15 +313,19 @@ type CategoriesStackResp struct {
 
 	Stack     []CategoryStackItem `json:"stack"`
 	TotalHits int64               `json:"total_hits" example:"20000"`
+
+	// set when Window reaches into the last FRESHNESS_LAG of data
+	Freshness *Freshness `json:"freshness,omitempty"`
 }
 
 // TopTermsInput carries options for ranked top terms
 type TopTermsInput struct{ GlobalOptions }
 
 // TopTermItem is a ranked term with counts and optional ratio
+// TermID is termid.Hash(Term), the same value ClickHouse archives store as term_id
 type TopTermItem struct {
 	Term       string  `json:"term"        example:"fuck"`
-	TermID     uint64  `json:"term_id"     example:"123456789"`
+	TermID     uint64  `json:"term_id"     example:"9732211836591299121"`
 	Hits       int64   `json:"hits"        example:"5400"`
 	Utterances int64   `json:"utterances,omitempty" example:"420000"`
 	Ratio      float64 `json:"ratio,omitempty" example:"0.0129"`
@@ -286,6 +394,8 @@ type TermsMatrixCell struct {
 }
 
 // TermsMatrixResp is the response for the term by language matrix
+// Past the server's cell cap the request is refused with 413 rather than returning a matrix
+// whose missing cells would read as zeros
 type TermsMatrixResp struct {
 	Terms []string          `json:"terms"`
 	Langs []TermsMatrixLang `json:"langs"`
@@ -296,6 +406,13 @@ type TermsMatrixResp struct {
 type RepoOverviewInput struct {
 	GlobalOptions
 	RepoHID string `json:"repo_hid" validate:"required,hexadecimal,len=64" example:"0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"` //nolint:lll
+
+	// FirstOffense adds the earliest-ever offending utterance (masked; outside Range)
+	FirstOffense bool `json:"first_offense,omitempty" example:"true"`
+
+	// CompareGlobal adds GlobalRatio: the same buckets over every repo (still honouring
+	// detver, language and exclusion filters) so the UI can overlay "this repo vs everyone"
+	CompareGlobal bool `json:"compare_global,omitempty" example:"true"`
 }
 
 // RepoRef identifies a repository with stable id and labels
@@ -305,7 +422,7 @@ type RepoRef struct {
 	NameOptIn *string `json:"name_optin,omitempty" example:"owner/name"`
 }
 
-// RepoOverviewSeriesPoint is a bucket for repo overview series
+// RepoOverviewSeriesPoint is a bucket for repo overview series (ratio = hits / utterances)
 type RepoOverviewSeriesPoint struct {
 	T          string  `json:"t" example:"2025-08-01"`
 	Hits       int64   `json:"hits" example:"10"`
@@ -319,6 +436,16 @@ type RepoOverviewResp struct {
 	Series   []RepoOverviewSeriesPoint `json:"series"`
 	Mix      map[string]int64          `json:"mix"` // category to count
 	TopTerms []TopTermItem             `json:"top_terms"`
+
+	// FirstOffense is present when requested and the repo has any hit
+	FirstOffense *SampleItem `json:"first_offense,omitempty"`
+
+	// GlobalRatio is present when CompareGlobal is set. It uses Series' interval and labels but
+	// is sparse on its own, so a bucket can appear in one and not the other
+	GlobalRatio []RepoOverviewSeriesPoint `json:"global_ratio,omitempty"`
+
+	// Partial is set when the utterance aggregate failed; hits are intact, utterances/ratios are zero
+	Partial bool `json:"partial,omitempty" example:"false"`
 }
 
 // SamplesInput fetches example utterances and hits
@@ -326,6 +453,21 @@ type SamplesInput struct {
 	GlobalOptions
 	Term  string `json:"term,omitempty" validate:"omitempty,printascii" example:"fuck"`
 	Limit int    `json:"limit,omitempty" validate:"omitempty,min=1,max=200" example:"20"`
+
+	// MinSeverity keeps only utterances with at least one hit this severe (a label or its rank
+	// 1-3); their milder hits stay on the card so masking still covers them
+	MinSeverity string `json:"min_severity,omitempty" validate:"omitempty,oneof=mild strong slur_masked 1 2 3" example:"strong"` //nolint:lll
+
+	// MaxChars truncates TextMasked to about this many characters (runes), centered on the
+	// first hit and cut on word boundaries with "…"; spans are re-based onto the truncated text
+	MaxChars int `json:"max_chars,omitempty" validate:"omitempty,min=20,max=10000" example:"280"`
+
+	// Random returns a seeded random sample instead of the newest rows; setting Seed implies it.
+	// The same seed over the same filters and data returns the same cards in the same order,
+	// and pages continue under the seed carried in the cursor. Without a seed the server draws
+	// one (nondeterministic) and echoes it in the response so the sample can be replayed
+	Random bool    `json:"random,omitempty" example:"true"`
+	Seed   *uint64 `json:"seed,omitempty"   example:"42"`
 }
 
 // SampleRepo identifies a repository in a sample
@@ -342,12 +484,15 @@ type SampleActor struct {
 	LoginOptIn *string `json:"login_optin,omitempty"`
 }
 
-// SampleHit summarizes one detected hit in a sample
+// SampleHit summarizes one detected term in a sample
+// Spans lists every [start,end) byte range of the term in TextMasked, ascending
 // For Swagger v2 avoid examples on fixed size arrays
+// Term and Spans are empty when the card's text was omitted (see SampleItem.TextOmitted)
 type SampleHit struct {
-	Term     string `json:"term"  example:"fuck"`
-	Span     [2]int `json:"span"`
-	Severity string `json:"severity" example:"mild"`
+	Term     string   `json:"term"  example:"fuck"`
+	Spans    [][2]int `json:"spans"`
+	Category string   `json:"category" example:"tooling_rage"`
+	Severity string   `json:"severity" example:"mild"`
 }
 
 // SampleItem is a single sample with context and hits
@@ -360,12 +505,52 @@ type SampleItem struct {
 	TextMasked  string      `json:"text_masked"  example:"f*** this build again"`
 	Hits        []SampleHit `json:"hits"`
 	DetVer      int         `json:"detver" example:"1"`
+
+	// TextOmitted is set when the server only returns text for consented principals and neither
+	// the repo nor the actor has opted in: TextMasked is empty and hits carry only category
+	// and severity. IDs, HIDs, time, source and detver are always kept
+	TextOmitted bool `json:"text_omitted,omitempty" example:"false"`
 }
 
 // SamplesResp is the response for samples
 type SamplesResp struct {
 	Items      []SampleItem `json:"items"`
 	NextCursor string       `json:"next_cursor,omitempty"`
+	Seed       *uint64      `json:"seed,omitempty" example:"42"` // set for random samples
+}
+
+// ExportHitsInput selects anonymized hits for bulk NDJSON export (newest first)
+// Page.Cursor resumes from a previous export's next_cursor; Limit is clamped to the server row cap
+// Parallel trades ordering for throughput: the window is scanned as concurrent sub-ranges, records
+// arrive in no particular order, and the cursor only resumes another parallel export
+type ExportHitsInput struct {
+	GlobalOptions
+	Term     string `json:"term,omitempty"     validate:"omitempty,printascii" example:"fuck"`
+	Limit    int    `json:"limit,omitempty"    validate:"omitempty,min=1" example:"5000"`
+	Parallel bool   `json:"parallel,omitempty" example:"false"`
+}
+
+// ExportHitRecord is one NDJSON line: an utterance with every hit on it
+// Subjects are HIDs only (never names, even when opted in) and text is always masked (or omitted)
+type ExportHitRecord struct {
+	UtteranceID string      `json:"utterance_id" example:"00000000-0000-0000-0000-000000000000"`
+	CreatedAt   string      `json:"created_at"   example:"2025-08-01T12:34:56Z"`
+	Source      string      `json:"source"       example:"commit"`
+	RepoHID     string      `json:"repo_hid"     example:"0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"` //nolint:lll
+	ActorHID    string      `json:"actor_hid"    example:"abcdefabcdefabcdefabcdefabcdefabcdefabcdefabcdefabcdefabcdefabcd"` //nolint:lll
+	DetVer      int         `json:"detver"       example:"1"`
+	Hits        []SampleHit `json:"hits"`
+	TextMasked  string      `json:"text_masked"  example:"f*** this build again"`
+	TextOmitted bool        `json:"text_omitted,omitempty" example:"false"` // as SampleItem.TextOmitted
+}
+
+// ExportTrailer is the last NDJSON line of an export
+// NextCursor is set when the row cap was reached; Error is set if the stream failed midway
+type ExportTrailer struct {
+	Done       bool   `json:"done"`
+	Rows       int    `json:"rows"                  example:"5000"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	Error      string `json:"error,omitempty"`
 }
 
 // RatiosTimeInput carries options for ratios over time
@@ -431,8 +616,20 @@ type SpikeDriversResp struct {
 	} `json:"drivers"`
 }
 
+// LeaderboardOptions are shared by the actor and repo leaderboards
+// By default principals with hits in the window rank by hits, most first. IncludeZero instead
+// ranks a cohort ascending ("cleanest first"), principals without any hits included:
+// "seen" (default) is every principal with utterances in the window, "known" every cataloged one
+type LeaderboardOptions struct {
+	IncludeZero bool   `json:"include_zero,omitempty" example:"false"`
+	Cohort      string `json:"cohort,omitempty"       validate:"omitempty,oneof=seen known" example:"seen"`
+}
+
 // ActorsLeaderboardInput carries options for actors leaderboard
-type ActorsLeaderboardInput struct{ GlobalOptions }
+type ActorsLeaderboardInput struct {
+	GlobalOptions
+	LeaderboardOptions
+}
 
 // ActorsLeaderboardRow is a ranked row for an actor
 type ActorsLeaderboardRow struct {
@@ -449,7 +646,10 @@ type ActorsLeaderboardResp struct {
 }
 
 // ReposLeaderboardInput carries options for repos leaderboard
-type ReposLeaderboardInput struct{ GlobalOptions }
+type ReposLeaderboardInput struct {
+	GlobalOptions
+	LeaderboardOptions
+}
 
 // ReposLeaderboardRow is a ranked row for a repository
 type ReposLeaderboardRow struct {
@@ -465,6 +665,30 @@ type ReposLeaderboardResp struct {
 	NextPage string                `json:"next_page,omitempty" example:"eyJvZmZzZXQiOjEwMH0"`
 }
 
+// QuietStreaksInput ranks repos or actors by time since their last offense
+// The window is ignored (last offense is all-time); DetVer, RepoHIDs/ActorHIDs and Page.Limit apply
+type QuietStreaksInput struct {
+	GlobalOptions
+	Principal string `json:"principal" validate:"required,oneof=repo actor" example:"repo"`
+	// Order "longest" (default) puts the most reformed first; "shortest" the most recent offenders
+	Order string `json:"order,omitempty" validate:"omitempty,oneof=longest shortest" example:"longest"`
+}
+
+// QuietStreakRow is one principal's last offense and the gap to now
+type QuietStreakRow struct {
+	HID           string  `json:"hid" example:"0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"`
+	Label         string  `json:"label" example:"abc…def"`
+	NameOptIn     *string `json:"name_optin,omitempty"`
+	LastOffenseAt string  `json:"last_offense_at" example:"2025-08-01T12:34:56Z"`
+	DaysQuiet     int64   `json:"days_quiet" example:"42"`
+}
+
+// QuietStreaksResp is the response for the quiet streak leaderboard
+type QuietStreaksResp struct {
+	Principal string           `json:"principal" example:"repo"`
+	Items     []QuietStreakRow `json:"items"`
+}
+
 // TermsSuggestInput carries options for term autocomplete
 type TermsSuggestInput struct {
 	GlobalOptions
@@ -477,8 +701,10 @@ type TermsSuggestResp struct {
 }
 
 // DetectorMetaResp reports detector versions and tags
+// Default is the detver applied to queries that send none (0 = they aggregate every detver)
 type DetectorMetaResp struct {
 	Current int      `json:"current" example:"2"`
+	Default int      `json:"default" example:"2"`
 	Known   []int    `json:"known"   example:"1"`
 	Notes   string   `json:"notes,omitempty" example:"rolling backfill in progress"`
 	Tags    []string `json:"tags,omitempty"  example:"stable"`
@@ -502,6 +728,9 @@ type TimeseriesHourlyResp struct {
 type ActorOverviewInput struct {
 	GlobalOptions
 	ActorHID string `json:"actor_hid" validate:"required,hexadecimal,len=64" example:"abcdefabcdefabcdefabcdefabcdefabcdefabcdefabcdefabcdefabcdefabcd"` //nolint:lll
+
+	// FirstOffense adds the earliest-ever offending utterance (masked; outside Range)
+	FirstOffense bool `json:"first_offense,omitempty" example:"true"`
 }
 
 // ActorOverviewResp is the response for the actor lens
@@ -517,6 +746,9 @@ type ActorOverviewResp struct {
 		Term string `json:"term" example:"fuck"`
 		Hits int64  `json:"hits" example:"4"`
 	} `json:"top_terms"`
+
+	// FirstOffense is present when requested and the actor has any hit
+	FirstOffense *SampleItem `json:"first_offense,omitempty"`
 }
 
 // RepoActorCrosstabInput carries options for repo by actor cross tab
@@ -549,6 +781,10 @@ type YearlyTrendsInput struct {
 
 	// Optional toggles to trim payload. Default: all true
 	Include []string `json:"include,omitempty" validate:"omitempty,dive,oneof=hits rate severity mix detver_markers seasonality"` //nolint:lll
+
+	// ScopedMarkers computes detver first-seen markers under the same scope filters as the
+	// rest of the response (repo/actor/lang/detver/exclusions). Default: global markers
+	ScopedMarkers bool `json:"scoped_markers,omitempty" example:"false"`
 }
 
 // MonthBand provides the seasonality ribbon (per metric, 12 items for Jan..Dec)
@@ -573,6 +809,8 @@ type DetverMarker struct {
 }
 
 // YearlyTrendsResp is one payload to render the entire panel
+// Note: the severity index is the weighted mean of hit severity labels; weights come from the
+// rulepack's engine_hints.severity_weights (default mild=1, strong=2, slur_masked=3)
 type YearlyTrendsResp struct {
 	Years []int `json:"years"`
 
@@ -595,6 +833,10 @@ type YearlyTrendsResp struct {
 
 	DetverMarkers []DetverMarker `json:"detver_markers,omitempty"`
 
+	// Partial is set when the utterance aggregate failed; hits, severity and mix are intact
+	// but Monthly.Rate and the rate seasonality band are zero
+	Partial bool `json:"partial,omitempty" example:"false"`
+
 	Meta struct {
 		DataMinYear int    `json:"data_min_year" example:"2011"`
 		DataMaxYear int    `json:"data_max_year" example:"2014"`
@@ -602,3 +844,222 @@ type YearlyTrendsResp struct {
 		GeneratedAt string `json:"generated_at"  example:"2025-09-19T10:00:00Z"`
 	} `json:"meta"`
 }
+
+// DetectTryOptions overrides detector knobs for a single ad-hoc run
+// Omitted fields fall back to the pipeline defaults used by the detect service
+type DetectTryOptions struct {
+	ContextWindow       *int  `json:"context_window,omitempty"       validate:"omitempty,min=0,max=512" example:"64"`
+	AllowOverlapping    *bool `json:"allow_overlapping,omitempty"    example:"false"`
+	CollapseOverlapping *bool `json:"collapse_overlapping,omitempty" example:"false"`
+	MaxHits             int   `json:"max_hits,omitempty"             validate:"omitempty,min=1,max=1000" example:"100"`
+	// ReportSuppressed also returns matches the stoplist dropped, tagged with the suppressing token
+	ReportSuppressed *bool `json:"report_suppressed,omitempty" example:"true"`
+	// LangScoped runs only rules for Lang plus language-neutral ones (as CORE_DETECT_LANG_SCOPED would)
+	LangScoped *bool `json:"lang_scoped,omitempty" example:"true"`
+	// ShoutingDelta bumps hits that were all caps in the submitted text ("FUCK THIS")
+	ShoutingDelta *int `json:"shouting_delta,omitempty" validate:"omitempty,min=0,max=5" example:"1"`
+	// FoldHomoglyphs matches Cyrillic/Greek lookalikes in Latin words (as CORE_DETECT_FOLD_HOMOGLYPHS would)
+	FoldHomoglyphs *bool `json:"fold_homoglyphs,omitempty" example:"true"`
+	// RequireContext enforces templates' declared context signals (as CORE_DETECT_REQUIRE_CONTEXT would);
+	// with report_suppressed, dropped matches come back tagged "context:<signal>"
+	RequireContext *bool `json:"require_context,omitempty" example:"true"`
+}
+
+// DetectTryInput is raw text to run through normalize + detector
+type DetectTryInput struct {
+	Text    string            `json:"text"              validate:"required" example:"why does webpack keep fucking up"`
+	Lang    string            `json:"lang,omitempty"    validate:"omitempty,max=16" example:"en"` // utterance lang_code
+	Options *DetectTryOptions `json:"options,omitempty"`
+}
+
+// DetectTryHit mirrors detector.Hit for JSON; spans are [start,end) byte offsets into Norm
+type DetectTryHit struct {
+	Term            string   `json:"term"             example:"fuck"`
+	Category        string   `json:"category"         example:"tooling_rage"`
+	Severity        int      `json:"severity"         example:"2"`
+	Spans           [][2]int `json:"spans"`
+	Source          string   `json:"source"           example:"template"`
+	RuleID          string   `json:"rule_id"          example:"en.tool_rage.keeps_breaking"`
+	DetectorVersion int      `json:"detector_version" example:"1"`
+	Pre             string   `json:"pre,omitempty"`
+	Post            string   `json:"post,omitempty"`
+	Zones           []string `json:"zones,omitempty"  example:"code_inline"`
+
+	TargetType     string `json:"target_type,omitempty"     example:"tool"`
+	TargetID       string `json:"target_id,omitempty"       example:"webpack"`
+	TargetName     string `json:"target_name,omitempty"     example:"webpack"`
+	TargetStart    int    `json:"target_start,omitempty"`
+	TargetEnd      int    `json:"target_end,omitempty"`
+	TargetDistance int    `json:"target_distance,omitempty"`
+	CtxAction      string `json:"ctx_action,omitempty"      example:"upgraded"`
+	Shouting       bool   `json:"shouting,omitempty"        example:"true"`
+
+	// SuppressedBy is the stoplisted token that dropped this match (suppressed list only)
+	SuppressedBy string `json:"suppressed_by,omitempty" example:"scunthorpe"`
+}
+
+// DetectTryResp returns the normalized text and every hit the detector emitted
+// Suppressed lists stoplisted matches when options.report_suppressed is set
+type DetectTryResp struct {
+	Norm       string         `json:"norm" example:"why does webpack keep fucking up"`
+	Hits       []DetectTryHit `json:"hits"`
+	Count      int            `json:"count" example:"1"`
+	Suppressed []DetectTryHit `json:"suppressed,omitempty"`
+}
+
+// FacetCount is one selectable filter value with how often it occurs
+type FacetCount struct {
+	Value string `json:"value" example:"en"`
+	Count int64  `json:"count" example:"15234"`
+}
+
+// FacetsResp lists the filter values present in the data, most common first
+// NLLangs counts hits per lang_code (commit_crimes); CodeLangs counts repos per primary_lang
+type FacetsResp struct {
+	NLLangs     []FacetCount `json:"nl_langs"`
+	CodeLangs   []FacetCount `json:"code_langs"`
+	GeneratedAt string       `json:"generated_at" example:"2025-09-19T10:00:00Z"`
+}
+
+// SearchInput looks up opted-in principals by name; only consented names are ever matched
+// Kind narrows to "repo" (full_name) or "actor" (login); omitted searches both
+type SearchInput struct {
+	Query string `json:"q"               validate:"required,min=2,max=100,printascii" example:"octo"`
+	Kind  string `json:"kind,omitempty"  validate:"omitempty,oneof=repo actor" example:"repo"`
+	Limit int    `json:"limit,omitempty" validate:"omitempty,min=1,max=50" example:"10"`
+}
+
+// SearchItem is one opted-in principal whose name matched; pass HID to the analytics endpoints
+type SearchItem struct {
+	Kind  string `json:"kind"  example:"repo"`
+	HID   string `json:"hid"   example:"0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"`
+	Name  string `json:"name"  example:"octocat/hello-world"`
+	Match string `json:"match" example:"prefix"` // exact, prefix or substring
+}
+
+// SearchResp lists matches, best first (exact, prefix, substring; shorter names first)
+type SearchResp struct {
+	Items []SearchItem `json:"items"`
+}
+
+// RedetectInput selects one stored utterance to re-run through the current detector
+// CreatedAt (RFC3339) is an optional hint that narrows the lookup to an hour either side
+type RedetectInput struct {
+	UtteranceID string `json:"utterance_id"         validate:"required,uuid" example:"3f1c2a7e-9d8b-5c4a-8e2f-1a2b3c4d5e6f"` //nolint:lll
+	CreatedAt   string `json:"created_at,omitempty" example:"2025-08-01T00:12:34Z"`
+	// DetVer picks which stored detector version to diff against; omitted = newest stored
+	DetVer int `json:"detver,omitempty" validate:"omitempty,min=1" example:"1"`
+}
+
+// RedetectStoredHit is one swearjar.hits row (one span) as persisted
+type RedetectStoredHit struct {
+	Term            string `json:"term"             example:"fuck"`
+	Category        string `json:"category"         example:"tooling_rage"`
+	Severity        string `json:"severity"         example:"strong"`
+	SpanStart       int    `json:"span_start"       example:"26"`
+	SpanEnd         int    `json:"span_end"         example:"33"`
+	TargetType      string `json:"target_type"      example:"tool"`
+	TargetID        string `json:"target_id"        example:"webpack"`
+	CtxAction       string `json:"ctx_action"       example:"none"`
+	DetectorVersion int    `json:"detector_version" example:"1"`
+}
+
+// RedetectChange pairs a stored row with the current hit at the same term and span
+// when category, severity or targeting differ
+type RedetectChange struct {
+	Stored  RedetectStoredHit `json:"stored"`
+	Current RedetectStoredHit `json:"current"`
+}
+
+// RedetectResp is the current detector output for a stored utterance, diffed per (term, span)
+// against the stored hits. Nothing is written
+type RedetectResp struct {
+	UtteranceID   string `json:"utterance_id"   example:"3f1c2a7e-9d8b-5c4a-8e2f-1a2b3c4d5e6f"`
+	Norm          string `json:"norm"           example:"why does webpack keep fucking up"`
+	Lang          string `json:"lang,omitempty" example:"en"`
+	CreatedAt     string `json:"created_at"     example:"2025-08-01T00:12:34Z"`
+	CurrentDetver int    `json:"current_detver" example:"2"`
+
+	// LangReliable is the inference verdict when Lang was guessed (no stored lang_code); an
+	// unreliable guess is scanned language-neutral, as the pipeline does
+	LangReliable *bool `json:"lang_reliable,omitempty" example:"true"`
+
+	Current []DetectTryHit      `json:"current"`
+	Stored  []RedetectStoredHit `json:"stored"`
+
+	Added     []RedetectStoredHit `json:"added"`   // current only
+	Removed   []RedetectStoredHit `json:"removed"` // stored only
+	Changed   []RedetectChange    `json:"changed"`
+	Unchanged int                 `json:"unchanged" example:"1"`
+}
+
+// DetverDiffInput samples utterances in the window with stored hits at detector version A or B,
+// in hours both versions have hits in, and diffs their hits per (term, span). Sample defaults
+// to 500 utterances
+type DetverDiffInput struct {
+	GlobalOptions
+	A        int `json:"a"                  validate:"required,min=1" example:"1"`
+	B        int `json:"b"                  validate:"required,min=1,nefield=A" example:"2"`
+	Sample   int `json:"sample,omitempty"   validate:"omitempty,min=1,max=5000" example:"500"`
+	Examples int `json:"examples,omitempty" validate:"omitempty,min=1,max=50" example:"10"`
+}
+
+// DetverDiffRow is one stored hit row read for a detver diff
+type DetverDiffRow struct {
+	UtteranceID string
+	Detver      int
+	Term        string
+	Category    string
+	Severity    string
+	SpanStart   int
+	SpanEnd     int
+}
+
+// DetverDiffHit is one hit (one span) at a single detector version
+type DetverDiffHit struct {
+	Term      string `json:"term"       example:"fuck"`
+	Category  string `json:"category"   example:"tooling_rage"`
+	Severity  string `json:"severity"   example:"strong"`
+	SpanStart int    `json:"span_start" example:"22"`
+	SpanEnd   int    `json:"span_end"   example:"29"`
+}
+
+// DetverDiffChange pairs the A and B hits at the same term and span when category or severity differ
+type DetverDiffChange struct {
+	A DetverDiffHit `json:"a"`
+	B DetverDiffHit `json:"b"`
+}
+
+// DetverDiffTerm tallies the diff for one term
+type DetverDiffTerm struct {
+	Term    string `json:"term"    example:"fuck"`
+	Gained  int    `json:"gained"  example:"3"`
+	Lost    int    `json:"lost"    example:"1"`
+	Changed int    `json:"changed" example:"2"`
+}
+
+// DetverDiffExample is the per-hit diff for one sampled utterance that changed
+type DetverDiffExample struct {
+	UtteranceID string             `json:"utterance_id" example:"3f1c2a7e-9d8b-5c4a-8e2f-1a2b3c4d5e6f"`
+	Gained      []DetverDiffHit    `json:"gained"` // B only
+	Lost        []DetverDiffHit    `json:"lost"`   // A only
+	Changed     []DetverDiffChange `json:"changed"`
+}
+
+// DetverDiffResp summarizes how detver B's hits differ from A's on the same utterances
+// Gained hits exist only at B, lost only at A; changed keep the span but not the category/severity
+type DetverDiffResp struct {
+	A          int `json:"a"          example:"1"`
+	B          int `json:"b"          example:"2"`
+	Utterances int `json:"utterances" example:"500"` // sampled utterances with hits at either version
+
+	Unchanged       int `json:"unchanged"        example:"1840"`
+	Gained          int `json:"gained"           example:"42"`
+	Lost            int `json:"lost"             example:"17"`
+	Changed         int `json:"changed"          example:"9"`
+	CategoryChanged int `json:"category_changed" example:"6"`
+	SeverityChanged int `json:"severity_changed" example:"4"`
+
+	Terms    []DetverDiffTerm    `json:"terms"`    // terms with any difference, most affected first
+	Examples []DetverDiffExample `json:"examples"` // first few utterances that differ
+}
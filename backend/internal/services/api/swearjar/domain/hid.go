@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// hidHexLen is the length of a hex-encoded 32-byte HID
+const hidHexLen = 64
+
+// CanonicalHID trims and lowercases a hex HID, rejecting anything that is not 64 hex chars
+func CanonicalHID(field, s string) (string, error) {
+	h := strings.ToLower(strings.TrimSpace(s))
+	if len(h) != hidHexLen {
+		return "", fmt.Errorf("%s must be a %d-character hex HID, got %d characters", field, hidHexLen, len(h))
+	}
+	if _, err := hex.DecodeString(h); err != nil {
+		return "", fmt.Errorf("%s must be a %d-character hex HID: %q is not hex", field, hidHexLen, s)
+	}
+	return h, nil
+}
+
+// CanonicalHIDs applies CanonicalHID per element and drops duplicates, keeping first-seen order
+func CanonicalHIDs(field string, xs []string) ([]string, error) {
+	if len(xs) == 0 {
+		return xs, nil
+	}
+	out := make([]string, 0, len(xs))
+	seen := make(map[string]struct{}, len(xs))
+	for i, x := range xs {
+		h, err := CanonicalHID(fmt.Sprintf("%s[%d]", field, i), x)
+		if err != nil {
+			return nil, err
+		}
+		if _, dup := seen[h]; dup {
+			continue
+		}
+		seen[h] = struct{}{}
+		out = append(out, h)
+	}
+	return out, nil
+}
+
+// Canonicalize normalizes HID filters before validation so every endpoint embedding
+// GlobalOptions rejects malformed HIDs with a 400 instead of silently matching nothing
+func (g *GlobalOptions) Canonicalize() error {
+	var err error
+	if g.RepoHIDs, err = CanonicalHIDs("repo_hids", g.RepoHIDs); err != nil {
+		return err
+	}
+	g.ActorHIDs, err = CanonicalHIDs("actor_hids", g.ActorHIDs)
+	return err
+}
+
+// Canonicalize normalizes the shared filters and the focused repo HID
+func (in *RepoOverviewInput) Canonicalize() error {
+	if err := in.GlobalOptions.Canonicalize(); err != nil {
+		return err
+	}
+	var err error
+	in.RepoHID, err = CanonicalHID("repo_hid", in.RepoHID)
+	return err
+}
+
+// Canonicalize normalizes the shared filters and the focused actor HID
+func (in *ActorOverviewInput) Canonicalize() error {
+	if err := in.GlobalOptions.Canonicalize(); err != nil {
+		return err
+	}
+	var err error
+	in.ActorHID, err = CanonicalHID("actor_hid", in.ActorHID)
+	return err
+}
@@ -12,6 +12,7 @@ type ServicePort interface {
 
 	TimeseriesByDetver(ctx context.Context, in TimeseriesDetverInput) (TimeseriesDetverResp, error)
 	CodeLangBars(ctx context.Context, in CodeLangBarsInput) (CodeLangBarsResp, error)
+	CodeLangTimeseries(ctx context.Context, in CodeLangTimeseriesInput) (CodeLangTimeseriesResp, error)
 	CategoriesStack(ctx context.Context, in CategoriesStackInput) (CategoriesStackResp, error)
 	TopTerms(ctx context.Context, in TopTermsInput) (TopTermsResp, error)
 	TermTimeline(ctx context.Context, in TermTimelineInput) (TermTimelineResp, error)
@@ -19,17 +20,26 @@ type ServicePort interface {
 	TermsMatrix(ctx context.Context, in TermsMatrixInput) (TermsMatrixResp, error)
 	RepoOverview(ctx context.Context, in RepoOverviewInput) (RepoOverviewResp, error)
 	Samples(ctx context.Context, in SamplesInput) (SamplesResp, error)
+	ExportHits(
+		ctx context.Context, in ExportHitsInput, maxRows, workers int, emit func(ExportHitRecord) error,
+	) (string, error)
 	RatiosTime(ctx context.Context, in RatiosTimeInput) (RatiosTimeResp, error)
 	SeverityTimeseries(ctx context.Context, in SeverityTimeseriesInput) (SeverityTimeseriesResp, error)
 	SpikeDrivers(ctx context.Context, in SpikeDriversInput) (SpikeDriversResp, error)
 
 	ActorsLeaderboard(ctx context.Context, in ActorsLeaderboardInput) (ActorsLeaderboardResp, error)
 	ReposLeaderboard(ctx context.Context, in ReposLeaderboardInput) (ReposLeaderboardResp, error)
+	QuietStreaks(ctx context.Context, in QuietStreaksInput) (QuietStreaksResp, error)
 	TermsSuggest(ctx context.Context, in TermsSuggestInput) (TermsSuggestResp, error)
 	TimeseriesHourly(ctx context.Context, in TimeseriesHourlyInput) (TimeseriesHourlyResp, error)
 	ActorOverview(ctx context.Context, in ActorOverviewInput) (ActorOverviewResp, error)
 	RepoActorCrosstab(ctx context.Context, in RepoActorCrosstabInput) (RepoActorCrosstabResp, error)
 
 	KPIStrip(ctx context.Context, in KPIStripInput) (KPIStripResp, error)
+	Overview(ctx context.Context, in OverviewInput) (OverviewResp, error)
 	YearlyTrends(ctx context.Context, in YearlyTrendsInput) (YearlyTrendsResp, error)
+	Facets(ctx context.Context) (FacetsResp, error)
+	Search(ctx context.Context, in SearchInput) (SearchResp, error)
+	DetverDiff(ctx context.Context, in DetverDiffInput) (DetverDiffResp, error)
+	DetectorMeta(ctx context.Context) (DetectorMetaResp, error)
 }
@@ -0,0 +1,13 @@
+package domain
+
+import "time"
+
+// RedetectSource is what the repo loads for a redetect run
+type RedetectSource struct {
+	Found     bool
+	Norm      string
+	Raw       string  // text_raw, for the detector's shouting signal
+	LangCode  *string // NULL when ingest had none; the pipeline may infer one
+	CreatedAt time.Time
+	Hits      []RedetectStoredHit
+}
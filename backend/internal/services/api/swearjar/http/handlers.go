@@ -2,58 +2,74 @@
 package http
 
 import (
+	"encoding/json"
 	stdhttp "net/http"
 
 	"swearjar/internal/modkit/httpkit"
+	perr "swearjar/internal/platform/errors"
 	"swearjar/internal/services/api/swearjar/domain"
 	svc "swearjar/internal/services/api/swearjar/service"
 )
 
 // Register mounts swearjar endpoints on the given router.
 // We use POST with JSON bodies for composable, future-proof query shapes
-func Register(r httpkit.Router, s *svc.Service) {
+// Responses larger than lim's cap for the route are refused with 413
+func Register(r httpkit.Router, s *svc.Service, lim ResponseLimits) {
 	h := &handlers{svc: s}
 
 	// 1
-	httpkit.PostJSON[domain.TimeseriesHitsInput](r, "/timeseries/hits", h.timeseriesHits)
+	postJSON[domain.TimeseriesHitsInput](r, lim, "/timeseries/hits", h.timeseriesHits)
 	// 2
-	httpkit.PostJSON[domain.TimeseriesDetverInput](r, "/timeseries/hits-by-detver", h.timeseriesByDetver)
+	postJSON[domain.TimeseriesDetverInput](r, lim, "/timeseries/hits-by-detver", h.timeseriesByDetver)
 	// 3
-	httpkit.PostJSON[domain.HeatmapWeeklyInput](r, "/heatmap/weekly", h.heatmapWeekly)
+	postJSON[domain.HeatmapWeeklyInput](r, lim, "/heatmap/weekly", h.heatmapWeekly)
 	// 4
-	httpkit.PostJSON[domain.LangBarsInput](r, "/bars/nl-lang", h.langBars)
+	postJSON[domain.LangBarsInput](r, lim, "/bars/nl-lang", h.langBars)
 	// 5
-	httpkit.PostJSON[domain.CodeLangBarsInput](r, "/bars/code-lang", h.codeLangBars)
+	postJSON[domain.CodeLangBarsInput](r, lim, "/bars/code-lang", h.codeLangBars)
 	// 6
-	httpkit.PostJSON[domain.CategoriesStackInput](r, "/stacked/categories", h.categoriesStack)
+	postJSON[domain.CategoriesStackInput](r, lim, "/stacked/categories", h.categoriesStack)
 	// 7
-	httpkit.PostJSON[domain.TopTermsInput](r, "/terms/top", h.topTerms)
+	postJSON[domain.TopTermsInput](r, lim, "/terms/top", h.topTerms)
 	// 8
-	httpkit.PostJSON[domain.TermTimelineInput](r, "/timeseries/term", h.termTimeline)
+	postJSON[domain.TermTimelineInput](r, lim, "/timeseries/term", h.termTimeline)
 	// 9
-	httpkit.PostJSON[domain.TargetsMixInput](r, "/targets/mix", h.targetsMix)
+	postJSON[domain.TargetsMixInput](r, lim, "/targets/mix", h.targetsMix)
 	// 10
-	httpkit.PostJSON[domain.TermsMatrixInput](r, "/terms/matrix", h.termsMatrix)
+	postJSON[domain.TermsMatrixInput](r, lim, "/terms/matrix", h.termsMatrix)
 	// 11
-	httpkit.PostJSON[domain.RepoOverviewInput](r, "/repo/overview", h.repoOverview)
+	postJSON[domain.RepoOverviewInput](r, lim, "/repo/overview", h.repoOverview)
 	// 12
-	httpkit.PostJSON[domain.SamplesInput](r, "/samples/commit-crimes", h.samples)
+	postJSON[domain.SamplesInput](r, lim, "/samples/commit-crimes", h.samples)
 	// 13
-	httpkit.PostJSON[domain.RatiosTimeInput](r, "/ratios/time", h.ratiosTime)
+	postJSON[domain.RatiosTimeInput](r, lim, "/ratios/time", h.ratiosTime)
 	// 14
-	httpkit.PostJSON[domain.SeverityTimeseriesInput](r, "/timeseries/severity", h.severityTimeseries)
+	postJSON[domain.SeverityTimeseriesInput](r, lim, "/timeseries/severity", h.severityTimeseries)
 	// 15
-	httpkit.PostJSON[domain.SpikeDriversInput](r, "/spike/drivers", h.spikeDrivers)
+	postJSON[domain.SpikeDriversInput](r, lim, "/spike/drivers", h.spikeDrivers)
 
-	httpkit.PostJSON[domain.ActorsLeaderboardInput](r, "/leaders/actors", h.actorsLeaderboard)       // 16
-	httpkit.PostJSON[domain.ReposLeaderboardInput](r, "/leaders/repos", h.reposLeaderboard)          // 17
-	httpkit.PostJSON[domain.TermsSuggestInput](r, "/terms/suggest", h.termsSuggest)                  // 18
-	httpkit.PostJSON[domain.TimeseriesHourlyInput](r, "/timeseries/hits-hourly", h.timeseriesHourly) // 20
-	httpkit.PostJSON[domain.ActorOverviewInput](r, "/actors/overview", h.actorOverview)              // 21
-	httpkit.PostJSON[domain.RepoActorCrosstabInput](r, "/crosstab/repo-actor", h.repoActorCrosstab)  // 22
+	postJSON[domain.ActorsLeaderboardInput](r, lim, "/leaders/actors", h.actorsLeaderboard)       // 16
+	postJSON[domain.ReposLeaderboardInput](r, lim, "/leaders/repos", h.reposLeaderboard)          // 17
+	postJSON[domain.TermsSuggestInput](r, lim, "/terms/suggest", h.termsSuggest)                  // 18
+	postJSON[domain.TimeseriesHourlyInput](r, lim, "/timeseries/hits-hourly", h.timeseriesHourly) // 20
+	postJSON[domain.ActorOverviewInput](r, lim, "/actors/overview", h.actorOverview)              // 21
+	postJSON[domain.RepoActorCrosstabInput](r, lim, "/crosstab/repo-actor", h.repoActorCrosstab)  // 22
 
-	httpkit.PostJSON[domain.KPIStripInput](r, "/kpi", h.kpiStrip)                   // 23
-	httpkit.PostJSON[domain.YearlyTrendsInput](r, "/yearly/trends", h.yearlyTrends) // 24
+	postJSON[domain.KPIStripInput](r, lim, "/kpi", h.kpiStrip)                   // 23
+	postJSON[domain.YearlyTrendsInput](r, lim, "/yearly/trends", h.yearlyTrends) // 24
+
+	postJSON[domain.CodeLangTimeseriesInput](r, lim, "/timeseries/code-lang", h.codeLangTimeseries) // 25
+	postJSON[domain.QuietStreaksInput](r, lim, "/leaders/quiet-streaks", h.quietStreaks)            // 26
+
+	r.Get("/facets", httpkit.Call(h.facets)) // 27
+
+	postJSON[domain.SearchInput](r, lim, "/search", h.search) // 28
+
+	postJSON[domain.DetverDiffInput](r, lim, "/detver/diff", h.detverDiff) // 29
+
+	postJSON[domain.OverviewInput](r, lim, "/overview", h.overview) // 30
+
+	r.Get("/meta/detector", httpkit.Call(h.detectorMeta)) // 31
 }
 
 type handlers struct{ svc *svc.Service }
@@ -72,6 +88,7 @@ func (h *handlers) timeseriesHits(r *stdhttp.Request, in domain.TimeseriesHitsIn
 
 // swagger:route POST /swearjar/heatmap/weekly Swearjar swearjarHeatmapWeekly
 // @Summary Weekly rhythm heatmap (day-of-week x hour)
+// @Description Optional business_hours adds an in-hours vs off-hours split computed from the same grid
 // @Tags Swearjar
 // @Accept json
 // @Produce json
@@ -118,6 +135,18 @@ func (h *handlers) codeLangBars(r *stdhttp.Request, in domain.CodeLangBarsInput)
 	return h.svc.CodeLangBars(r.Context(), in)
 }
 
+// swagger:route POST /swearjar/timeseries/code-lang Swearjar swearjarCodeLangTimeseries
+// @Summary Programming language trends over time (repo primary language)
+// @Tags Swearjar
+// @Accept json
+// @Produce json
+// @Param payload body domain.CodeLangTimeseriesInput true "Query"
+// @Success 200 {object} domain.CodeLangTimeseriesResp "ok"
+// @Router /swearjar/timeseries/code-lang [post]
+func (h *handlers) codeLangTimeseries(r *stdhttp.Request, in domain.CodeLangTimeseriesInput) (any, error) {
+	return h.svc.CodeLangTimeseries(r.Context(), in)
+}
+
 // swagger:route POST /swearjar/stacked/categories Swearjar swearjarCategoriesStack
 // @Summary Categories & severity mix
 // @Tags Swearjar
@@ -243,6 +272,8 @@ func (h *handlers) spikeDrivers(r *stdhttp.Request, in domain.SpikeDriversInput)
 // @Tags Swearjar
 // @Accept json
 // @Produce json
+// @Description include_zero ranks a cohort fewest hits first, zero-hit principals included: cohort "seen"
+// @Description (default) is everyone with utterances in the window, "known" every cataloged principal
 // @Param payload body domain.ActorsLeaderboardInput true "Query"
 // @Success 200 {object} domain.ActorsLeaderboardResp "ok"
 // @Router /swearjar/leaders/actors [post]
@@ -255,6 +286,8 @@ func (h *handlers) actorsLeaderboard(r *stdhttp.Request, in domain.ActorsLeaderb
 // @Tags Swearjar
 // @Accept json
 // @Produce json
+// @Description include_zero ranks a cohort fewest hits first, zero-hit principals included: cohort "seen"
+// @Description (default) is everyone with utterances in the window, "known" every cataloged principal
 // @Param payload body domain.ReposLeaderboardInput true "Query"
 // @Success 200 {object} domain.ReposLeaderboardResp "ok"
 // @Router /swearjar/leaders/repos [post]
@@ -262,6 +295,18 @@ func (h *handlers) reposLeaderboard(r *stdhttp.Request, in domain.ReposLeaderboa
 	return h.svc.ReposLeaderboard(r.Context(), in)
 }
 
+// swagger:route POST /swearjar/leaders/quiet-streaks Swearjar swearjarQuietStreaks
+// @Summary Repos or actors ranked by days since their last offense (name revealed on opt-in only)
+// @Tags Swearjar
+// @Accept json
+// @Produce json
+// @Param payload body domain.QuietStreaksInput true "Query"
+// @Success 200 {object} domain.QuietStreaksResp "ok"
+// @Router /swearjar/leaders/quiet-streaks [post]
+func (h *handlers) quietStreaks(r *stdhttp.Request, in domain.QuietStreaksInput) (any, error) {
+	return h.svc.QuietStreaks(r.Context(), in)
+}
+
 // swagger:route POST /swearjar/terms/suggest Swearjar swearjarTermsSuggest
 // @Summary Term autocomplete/suggestions
 // @Tags Swearjar
@@ -333,3 +378,184 @@ func (h *handlers) kpiStrip(r *stdhttp.Request, in domain.KPIStripInput) (any, e
 func (h *handlers) yearlyTrends(r *stdhttp.Request, in domain.YearlyTrendsInput) (any, error) {
 	return h.svc.YearlyTrends(r.Context(), in)
 }
+
+// swagger:route POST /swearjar/overview Swearjar swearjarOverview
+// @Summary Homepage hero in one call: KPI strip, top terms and weekly heatmap for the window
+// @Description Panels are queried concurrently; a failed panel is null and listed in failed, the rest still return.
+// @Tags Swearjar
+// @Accept json
+// @Produce json
+// @Param payload body domain.OverviewInput true "Query"
+// @Success 200 {object} domain.OverviewResp "ok"
+// @Router /swearjar/overview [post]
+func (h *handlers) overview(r *stdhttp.Request, in domain.OverviewInput) (any, error) {
+	return h.svc.Overview(r.Context(), in)
+}
+
+// swagger:route GET /swearjar/facets Swearjar swearjarFacets
+// @Summary Filter values present in the data (NL languages by hits, code languages by repos)
+// @Description Cached server-side for a few minutes.
+// @Tags Swearjar
+// @Produce json
+// @Success 200 {object} domain.FacetsResp "ok"
+// @Router /swearjar/facets [get]
+func (h *handlers) facets(r *stdhttp.Request) (any, error) {
+	return h.svc.Facets(r.Context())
+}
+
+// swagger:route POST /swearjar/search Swearjar swearjarSearch
+// @Summary Find opted-in repos/actors by name (prefix or substring) and return their HIDs
+// @Description Only principals with an active opt-in are searchable; other names are never matched or returned.
+// @Tags Swearjar
+// @Accept json
+// @Produce json
+// @Param payload body domain.SearchInput true "Query"
+// @Success 200 {object} domain.SearchResp "ok"
+// @Router /swearjar/search [post]
+func (h *handlers) search(r *stdhttp.Request, in domain.SearchInput) (any, error) {
+	return h.svc.Search(r.Context(), in)
+}
+
+// swagger:route POST /swearjar/detver/diff Swearjar swearjarDetverDiff
+// @Summary Diff stored hits of two detector versions on a sample of utterances either detected
+// @Description Samples hours both versions ran over. Per (term, span): gained = B only, lost = A only,
+// @Description changed = category or severity differs.
+// @Tags Swearjar
+// @Accept json
+// @Produce json
+// @Param payload body domain.DetverDiffInput true "Window, versions and sample size"
+// @Success 200 {object} domain.DetverDiffResp "ok"
+// @Router /swearjar/detver/diff [post]
+func (h *handlers) detverDiff(r *stdhttp.Request, in domain.DetverDiffInput) (any, error) {
+	return h.svc.DetverDiff(r.Context(), in)
+}
+
+// swagger:route GET /swearjar/meta/detector Swearjar swearjarDetectorMeta
+// @Summary Detector versions: the loaded pack's, the default query filter, and those with stored hits
+// @Description Requests without detver are filtered to default; send detver to compare or aggregate versions.
+// @Tags Swearjar
+// @Produce json
+// @Success 200 {object} domain.DetectorMetaResp "ok"
+// @Router /swearjar/meta/detector [get]
+func (h *handlers) detectorMeta(r *stdhttp.Request) (any, error) {
+	return h.svc.DetectorMeta(r.Context())
+}
+
+// RegisterDetectTry mounts the ad-hoc detector endpoint
+// Callers gate this behind config; it exposes the raw rulepack behavior
+func RegisterDetectTry(r httpkit.Router, t *svc.Tryer) {
+	h := &tryHandlers{try: t}
+	httpkit.PostJSON[domain.DetectTryInput](r, "/detect/try", h.detectTry)
+}
+
+type tryHandlers struct{ try *svc.Tryer }
+
+// swagger:route POST /swearjar/detect/try Swearjar swearjarDetectTry
+// @Summary Run the detector over ad-hoc text (debug only; disabled unless CORE_API_SWEARJAR_DETECT_TRY=true)
+// @Tags Swearjar
+// @Accept json
+// @Produce json
+// @Param payload body domain.DetectTryInput true "Text and optional detector overrides"
+// @Success 200 {object} domain.DetectTryResp "ok"
+// @Router /swearjar/detect/try [post]
+func (h *tryHandlers) detectTry(r *stdhttp.Request, in domain.DetectTryInput) (any, error) {
+	return h.try.Try(r.Context(), in)
+}
+
+// RegisterRedetect mounts the single-utterance re-detection debug endpoint
+// Callers gate this behind config; it reads utterance text by ID
+func RegisterRedetect(r httpkit.Router, rd *svc.Redetector) {
+	h := &redetectHandlers{rd: rd}
+	httpkit.PostJSON[domain.RedetectInput](r, "/_debug/redetect", h.redetect)
+}
+
+type redetectHandlers struct{ rd *svc.Redetector }
+
+// swagger:route POST /swearjar/_debug/redetect Swearjar swearjarRedetect
+// @Summary Re-run the current detector on one stored utterance and diff against stored hits (debug only)
+// @Description Disabled unless CORE_API_SWEARJAR_REDETECT=true. Nothing is written.
+// @Tags Swearjar
+// @Accept json
+// @Produce json
+// @Param payload body domain.RedetectInput true "Utterance ID and optional created_at hint"
+// @Success 200 {object} domain.RedetectResp "ok"
+// @Failure 404 {object} httpkit.Envelope "utterance not found"
+// @Router /swearjar/_debug/redetect [post]
+func (h *redetectHandlers) redetect(r *stdhttp.Request, in domain.RedetectInput) (any, error) {
+	return h.rd.Redetect(r.Context(), in)
+}
+
+// ExportConfig bounds the researcher export endpoint
+type ExportConfig struct {
+	MaxRows    int // hard cap on records per request
+	Workers    int // concurrent sub-range scans for parallel exports (<= 1 = serial)
+	RatePerMin int // requests per minute per client
+	Burst      int
+}
+
+// RegisterExport mounts the NDJSON hits export behind a per-client rate limit
+func RegisterExport(r httpkit.Router, s *svc.Service, cfg ExportConfig) {
+	h := &exportHandlers{svc: s, maxRows: cfg.MaxRows, workers: cfg.Workers}
+	r.Group(func(g httpkit.Router) {
+		g.Use(httpkit.RateLimit(httpkit.RateLimitOptions{Rate: cfg.RatePerMin, Burst: cfg.Burst}))
+		g.Post("/export/hits", h.exportHits)
+	})
+}
+
+type exportHandlers struct {
+	svc     *svc.Service
+	maxRows int
+	workers int
+}
+
+// exportFlushEvery flushes the stream every N records so clients see steady progress
+const exportFlushEvery = 200
+
+// swagger:route POST /swearjar/export/hits Swearjar swearjarExportHits
+// @Summary Stream anonymized hits as NDJSON (HIDs only, masked text, keyset paged, rate limited)
+// @Description One domain.ExportHitRecord per line, then a final domain.ExportTrailer line.
+// @Description Resume with page.cursor = next_cursor from the trailer.
+// @Description parallel=true scans sub-ranges concurrently; records are then unordered.
+// @Tags Swearjar
+// @Accept json
+// @Produce application/x-ndjson
+// @Param payload body domain.ExportHitsInput true "Window, filters and cursor"
+// @Success 200 {object} domain.ExportHitRecord "ok (one per line)"
+// @Failure 429 {object} httpkit.Envelope "rate limited"
+// @Router /swearjar/export/hits [post]
+func (h *exportHandlers) exportHits(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+	in, err := httpkit.ParseJSON[domain.ExportHitsInput](r)
+	if err != nil {
+		httpkit.WriteError(w, r, err)
+		return
+	}
+
+	enc := json.NewEncoder(w)
+	flusher, _ := w.(stdhttp.Flusher)
+	rows := 0
+	next, err := h.svc.ExportHits(r.Context(), in, h.maxRows, h.workers, func(rec domain.ExportHitRecord) error {
+		if rows == 0 {
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			w.WriteHeader(stdhttp.StatusOK)
+		}
+		rows++
+		if err := enc.Encode(rec); err != nil {
+			return err
+		}
+		if flusher != nil && rows%exportFlushEvery == 0 {
+			flusher.Flush()
+		}
+		return nil
+	})
+	switch {
+	case err != nil && rows == 0:
+		httpkit.WriteError(w, r, err) // nothing streamed yet, so a normal error envelope still works
+		return
+	case err != nil:
+		_ = enc.Encode(domain.ExportTrailer{Rows: rows, Error: perr.WireFrom(err).Message})
+		return
+	case rows == 0:
+		w.Header().Set("Content-Type", "application/x-ndjson")
+	}
+	_ = enc.Encode(domain.ExportTrailer{Done: true, Rows: rows, NextCursor: next})
+}
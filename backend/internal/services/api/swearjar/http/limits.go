@@ -0,0 +1,80 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	stdhttp "net/http"
+	"strconv"
+	"strings"
+
+	"swearjar/internal/modkit/httpkit"
+	perr "swearjar/internal/platform/errors"
+)
+
+// ResponseLimits caps the serialized size of JSON responses (0 = unlimited)
+// ByPath overrides Default for routes that can fan out (matrix, crosstab)
+type ResponseLimits struct {
+	Default int
+	ByPath  map[string]int
+}
+
+// For returns the byte cap for a route path relative to the module prefix
+func (l ResponseLimits) For(path string) int {
+	if n, ok := l.ByPath[path]; ok {
+		return n
+	}
+	return l.Default
+}
+
+// ParseResponseLimits builds limits from a default and "path=bytes" pairs
+// (e.g., "/terms/matrix=4194304,/crosstab/repo-actor=4194304")
+func ParseResponseLimits(def int, spec string) (ResponseLimits, error) {
+	l := ResponseLimits{Default: def, ByPath: map[string]int{}}
+	for pair := range strings.SplitSeq(spec, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		path, v, ok := strings.Cut(pair, "=")
+		path = strings.TrimSpace(path)
+		if !ok || !strings.HasPrefix(path, "/") {
+			return ResponseLimits{}, fmt.Errorf("response limit %q: want /path=bytes", pair)
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil || n < 0 {
+			return ResponseLimits{}, fmt.Errorf("response limit %q: bytes must be a non-negative integer", pair)
+		}
+		l.ByPath[path] = n
+	}
+	return l, nil
+}
+
+// postJSON mounts a JSON handler whose response is refused with 413 past the path's cap
+func postJSON[T any](r httpkit.Router, lim ResponseLimits, path string, h func(*stdhttp.Request, T) (any, error)) {
+	httpkit.PostJSON[T](r, path, capResponse(lim.For(path), h))
+}
+
+// capResponse serializes the result once to measure it; under the cap the encoded bytes are
+// passed through as-is, over it the caller gets guidance to narrow the query instead of a
+// response big enough to stall the server and the client
+func capResponse[T any](limit int, h func(*stdhttp.Request, T) (any, error)) func(*stdhttp.Request, T) (any, error) {
+	if limit <= 0 {
+		return h
+	}
+	return func(r *stdhttp.Request, in T) (any, error) {
+		out, err := h(r, in)
+		if err != nil {
+			return nil, err
+		}
+		b, err := json.Marshal(out)
+		if err != nil {
+			return nil, err
+		}
+		if len(b) > limit {
+			return nil, perr.Newf(perr.ErrorCodeTooLarge,
+				"response is %d bytes, over the %d byte limit; narrow the range, add filters or lower top_n/limit",
+				len(b), limit)
+		}
+		return json.RawMessage(b), nil
+	}
+}
@@ -2,17 +2,23 @@
 package module
 
 import (
+	"context"
 	"net/http"
+	std "strings"
 
+	"swearjar/internal/core/rulepack"
 	"swearjar/internal/modkit"
 	"swearjar/internal/modkit/httpkit"
 	"swearjar/internal/modkit/repokit"
+	"swearjar/internal/platform/config"
+	"swearjar/internal/platform/logger"
 	"swearjar/internal/platform/strings"
 	"swearjar/internal/services/api/swearjar/domain"
 
 	swearjarhttp "swearjar/internal/services/api/swearjar/http"
 	"swearjar/internal/services/api/swearjar/repo"
 	"swearjar/internal/services/api/swearjar/service"
+	detectmod "swearjar/internal/services/detect/module"
 )
 
 // Ports exposes the service port for cross-module lookups
@@ -33,15 +39,55 @@ type Module struct {
 	subrouter func(httpkit.Router) httpkit.Router
 	register  func(httpkit.Router)
 
-	svc *service.Service
+	svc    *service.Service
+	try    *service.Tryer             // nil unless DetectTry is enabled
+	redet  *service.Redetector        // nil unless Redetect is enabled
+	export *swearjarhttp.ExportConfig // nil unless ExportHits is enabled
 }
 
 // New constructs the swearjar module
 func New(deps modkit.Deps, opts ...modkit.Option) modkit.Module {
 	b := modkit.Build(append([]modkit.Option{modkit.WithName("swearjar"), modkit.WithPrefix("/swearjar")}, opts...)...)
 
-	binder := repo.NewHybrid(deps.CH)
-	svc := service.New(repokit.TxRunner(deps.PG), binder)
+	o := FromConfig(deps.Cfg)
+	rp, err := rulepack.Load()
+	if err != nil {
+		panic(err)
+	}
+	weights, err := rulepack.ParseSeverityWeights(rp.SeverityWeights, o.SeverityWeights)
+	if err != nil {
+		panic(err)
+	}
+
+	limits, err := swearjarhttp.ParseResponseLimits(o.MaxResponseBytes, o.MaxResponseBytesByPath)
+	if err != nil {
+		panic(err)
+	}
+
+	caps, err := repo.ParseRowCaps(o.RowCaps)
+	if err != nil {
+		panic(err)
+	}
+
+	detver := o.DefaultDetVer
+	switch {
+	case detver == 0:
+		detver = rp.DetVer
+	case detver < 0:
+		detver = 0
+	}
+
+	binder := repo.NewHybrid(deps.CH, repo.HybridOptions{
+		Weights:           weights,
+		DetVer:            detver,
+		RowCaps:           caps,
+		ConsentedTextOnly: o.ConsentedTextOnly,
+	})
+	svc := service.New(repokit.TxRunner(deps.PG), binder).
+		WithTermBlocklist(service.NewTermBlocklist(std.Split(o.BlockedTerms, ","))).
+		WithOverviewConcurrency(o.OverviewConcurrency).
+		WithFreshnessLag(o.FreshnessLag).
+		WithDetVer(rp.DetVer, detver)
 
 	m := &Module{
 		deps:      deps,
@@ -54,9 +100,61 @@ func New(deps modkit.Deps, opts ...modkit.Option) modkit.Module {
 	}
 	m.ports = Ports{Service: svc}
 
+	if o.Prewarm {
+		years, err := service.ParsePrewarmYears(o.PrewarmYears)
+		if err != nil {
+			panic(err)
+		}
+		windows, err := service.ParsePrewarmWindows(o.PrewarmWindows)
+		if err != nil {
+			panic(err)
+		}
+		// runs for the life of the process, like the CH health loop
+		service.NewPrewarmer(svc, service.PrewarmConfig{
+			Years:    years,
+			Windows:  windows,
+			Interval: o.PrewarmInterval,
+			// the store's cache TTL is read at the env root, like cmd/swearjar-api does
+			CacheTTL: config.New().Prefix("SERVICE_CLICKHOUSE_").MayDuration("QUERY_CACHE_TTL", 0),
+			SkipIdle: o.PrewarmSkipIdle,
+		}).Start(context.Background())
+	}
+
+	if o.DetectTry {
+		m.try = service.NewTryer(rp, service.TryConfig{Version: o.DetectTryVersion, MaxBytes: o.DetectTryMaxBytes})
+		logger.Get().Warn().Int("max_bytes", o.DetectTryMaxBytes).Msg("swearjar: /detect/try is enabled (debug only)")
+	}
+	if o.Redetect {
+		t := m.try
+		if t == nil {
+			t = service.NewTryer(rp, service.TryConfig{Version: o.DetectTryVersion, MaxBytes: o.DetectTryMaxBytes})
+		}
+		// deps.Cfg is rooted at CORE_API_; the pipeline's CORE_DETECT_* options live at the env root
+		do := detectmod.FromConfig(config.New())
+		m.redet = service.NewRedetector(svc, t, do.DetectorOptions(), do.LangDetector())
+		logger.Get().Warn().Msg("swearjar: /_debug/redetect is enabled (debug only)")
+	}
+	if o.ExportHits {
+		m.export = &swearjarhttp.ExportConfig{
+			MaxRows:    o.ExportMaxRows,
+			Workers:    o.ExportWorkers,
+			RatePerMin: o.ExportRatePerMinute,
+			Burst:      o.ExportBurst,
+		}
+	}
+
 	external := b.Register
 	m.register = func(r httpkit.Router) {
-		swearjarhttp.Register(r, m.svc)
+		swearjarhttp.Register(r, m.svc, limits)
+		if m.try != nil {
+			swearjarhttp.RegisterDetectTry(r, m.try)
+		}
+		if m.redet != nil {
+			swearjarhttp.RegisterRedetect(r, m.redet)
+		}
+		if m.export != nil {
+			swearjarhttp.RegisterExport(r, m.svc, *m.export)
+		}
 		if external != nil {
 			external(r)
 		}
@@ -2,9 +2,11 @@ package module
 
 import (
 	"net/http"
+	"time"
 
 	modkit "swearjar/internal/modkit"
 	"swearjar/internal/modkit/httpkit"
+	"swearjar/internal/platform/config"
 )
 
 // Option is a configuration option for the samples module
@@ -26,3 +28,77 @@ func WithRegister(fn func(httpkit.Router)) Option { return modkit.WithRegister(f
 
 // WithSubrouter sets the subrouter function for the module
 func WithSubrouter(fn func(httpkit.Router) httpkit.Router) Option { return modkit.WithSubrouter(fn) }
+
+// Options controls optional swearjar API features
+type Options struct {
+	// DetectTry mounts POST /swearjar/detect/try (rulepack iteration only; keep off in production)
+	DetectTry         bool `env:"DETECT_TRY" default:"false"`
+	DetectTryMaxBytes int  `env:"DETECT_TRY_MAX_BYTES" default:"8192"`
+	DetectTryVersion  int  `env:"DETECT_TRY_VERSION" default:"1"`
+	// Redetect mounts POST /swearjar/_debug/redetect (re-scan one stored utterance, diff vs stored hits);
+	// it shares the DETECT_TRY_* version and detector defaults
+	Redetect bool `env:"REDETECT" default:"false"`
+
+	// ExportHits mounts POST /swearjar/export/hits (NDJSON, anonymized, rate limited per client)
+	// ExportWorkers bounds concurrent sub-range scans for requests that set parallel (<= 1 = serial)
+	ExportHits          bool `env:"EXPORT_HITS" default:"false"`
+	ExportMaxRows       int  `env:"EXPORT_MAX_ROWS" default:"5000"`
+	ExportWorkers       int  `env:"EXPORT_WORKERS" default:"4"`
+	ExportRatePerMinute int  `env:"EXPORT_RATE_PER_MINUTE" default:"6"`
+	ExportBurst         int  `env:"EXPORT_BURST" default:"2"`
+
+	// OverviewConcurrency bounds how many /overview panels (KPI, top terms, heatmap) query at once
+	OverviewConcurrency int `env:"OVERVIEW_CONCURRENCY" default:"3"`
+
+	// FreshnessLag adds freshness {complete, processed_through} to window responses (KPI strip,
+	// hits series, heatmap, categories, overview) whose range ends within this much of now; 0 omits it
+	FreshnessLag time.Duration `env:"FRESHNESS_LAG" default:"48h"`
+
+	// SeverityWeights overrides the rulepack's engine_hints.severity_weights for mean-severity
+	// indexes, as "label=weight" pairs (e.g., "slur_masked=10"); unset labels keep the pack weight
+	SeverityWeights string `env:"SEVERITY_WEIGHTS" default:""`
+
+	// MaxResponseBytes refuses JSON responses past this size with 413 (0 = unlimited);
+	// MaxResponseBytesByPath overrides it per route as "/path=bytes" pairs
+	MaxResponseBytes       int    `env:"MAX_RESPONSE_BYTES" default:"16777216"`
+	MaxResponseBytesByPath string `env:"MAX_RESPONSE_BYTES_BY_PATH" default:"/terms/matrix=4194304,/crosstab/repo-actor=4194304"` //nolint:lll
+
+	// RowCaps overrides the per-endpoint result row caps as "name=rows" pairs (top_terms,
+	// terms_matrix, crosstab_repo_actor); 0 uncaps one. Unset names keep repo.DefaultRowCaps.
+	// Those endpoints are still unimplemented, so the caps take effect once their queries land
+	RowCaps string `env:"ROW_CAPS" default:""`
+
+	// DefaultDetVer filters queries that send no detver to one detector version so dashboards
+	// don't mix versions; 0 uses the loaded rulepack's meta.detver, -1 aggregates every detver
+	DefaultDetVer int `env:"DEFAULT_DETVER" default:"0"`
+
+	// ConsentedTextOnly is a stricter privacy mode for samples, first offenses and the hits export:
+	// text (masked) is only returned when the repo or the actor has an active opt-in. Other cards
+	// keep HIDs, time, source, detver and each hit's category and severity; terms, spans and text
+	// are dropped and text_omitted is set
+	ConsentedTextOnly bool `env:"CONSENTED_TEXT_ONLY" default:"false"`
+
+	// Prewarm re-runs the KPI strip, hits series and overview every PREWARM_INTERVAL with the
+	// explore UI's default options, so those dashboards hit a warm CH query cache: for each of
+	// PREWARM_YEARS as the UI's period=year view asks for it (keep CORE_FRONTEND_EXPLORE_DEFAULT_YEAR
+	// in the list, the year the UI opens on) and for trailing PREWARM_WINDOWS (days ending today
+	// UTC, for API callers and custom ranges). It needs SERVICE_CLICKHOUSE_QUERY_CACHE_TTL above the
+	// interval. PREWARM_SKIP_IDLE skips rounds while detection hasn't moved and the dates are
+	// unchanged, but still refreshes before the cache TTL runs out
+	Prewarm         bool          `env:"PREWARM" default:"false"`
+	PrewarmYears    string        `env:"PREWARM_YEARS" default:"2014"`
+	PrewarmWindows  string        `env:"PREWARM_WINDOWS" default:"7,30,90"`
+	PrewarmInterval time.Duration `env:"PREWARM_INTERVAL" default:"5m"`
+	PrewarmSkipIdle bool          `env:"PREWARM_SKIP_IDLE" default:"true"`
+
+	// BlockedTerms is a comma-separated list of terms hidden from top-terms/suggest/matrix responses
+	// and refused as samples/term-timeline inputs; stored data is unaffected
+	BlockedTerms string `env:"BLOCKED_TERMS" default:""`
+}
+
+// FromConfig reads SWEARJAR_* values relative to the API config (CORE_API_SWEARJAR_*)
+func FromConfig(cfg config.Conf) Options {
+	var o Options
+	cfg.Prefix("SWEARJAR_").MustBind(&o)
+	return o
+}
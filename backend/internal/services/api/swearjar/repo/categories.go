@@ -67,17 +67,17 @@ func (s *hybridStore) CategoriesStack(
 	}
 	args := []any{startTS, endTS}
 
-	if len(in.DetVer) > 0 {
+	if dv := s.detvers(in.DetVer); len(dv) > 0 {
 		where = append(where, "detver IN ?")
-		args = append(args, in.DetVer)
+		args = append(args, dv)
 	}
 	if len(in.RepoHIDs) > 0 {
 		where = append(where, "repo_hid IN ?")
-		args = append(args, in.RepoHIDs)
+		args = append(args, hidFilter(in.RepoHIDs))
 	}
 	if len(in.ActorHIDs) > 0 {
 		where = append(where, "actor_hid IN ?")
-		args = append(args, in.ActorHIDs)
+		args = append(args, hidFilter(in.ActorHIDs))
 	}
 	if len(in.NLLangs) > 0 {
 		where = append(where, "lang_code IN ?")
@@ -102,6 +102,12 @@ func (s *hybridStore) CategoriesStack(
 		args = append(args, in.Categories)
 	}
 
+	ex, err := s.exclusionsFor(ctx, in.GlobalOptions)
+	if err != nil {
+		return domain.CategoriesStackResp{}, err
+	}
+	where, args = ex.applyCrimes(where, args)
+
 	// Map NULL/empty to a stable placeholder so grouping is predictable.
 	// We'll keep raw label for display; unknowns will get "unknown"
 	sql := `
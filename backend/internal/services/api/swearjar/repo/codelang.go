@@ -0,0 +1,234 @@
+package repo
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"swearjar/internal/services/api/swearjar/domain"
+)
+
+// defaultCodeLangTopN caps the series when the caller doesn't
+const defaultCodeLangTopN = 8
+
+// CodeLangTimeseries counts hits per repo primary language per bucket. commit_crimes has no
+// code language, so hits are grouped per (bucket, repo) in ClickHouse and folded onto
+// repositories.primary_lang from Postgres; repos without a cataloged language are left out.
+// hour/day/week buckets are dense-filled across the range, calendar buckets share the union
+// of observed keys; every series carries the same buckets
+func (s *hybridStore) CodeLangTimeseries(
+	ctx context.Context,
+	in domain.CodeLangTimeseriesInput,
+) (domain.CodeLangTimeseriesResp, error) {
+	interval := strings.ToLower(strings.TrimSpace(in.Interval))
+	switch interval {
+	case "hour", "day", "week", "month", "quarter", "year":
+	default:
+		interval = "day"
+	}
+	tz := strings.TrimSpace(in.TZ)
+	if tz == "" {
+		tz = "UTC"
+	}
+	topN := in.TopN
+	if topN <= 0 {
+		topN = defaultCodeLangTopN
+	}
+
+	start, err := time.Parse("2006-01-02", in.Range.Start)
+	if err != nil {
+		return domain.CodeLangTimeseriesResp{}, err
+	}
+	endIncl, err := time.Parse("2006-01-02", in.Range.End)
+	if err != nil {
+		return domain.CodeLangTimeseriesResp{}, err
+	}
+	endExcl := endIncl.Add(24 * time.Hour)
+
+	where := []string{"created_at >= ? AND created_at < ?"}
+	args := []any{start, endExcl}
+	if dv := s.detvers(in.DetVer); len(dv) > 0 {
+		where = append(where, "detver IN ?")
+		args = append(args, dv)
+	}
+	if len(in.RepoHIDs) > 0 {
+		where = append(where, "repo_hid IN ?")
+		args = append(args, hidFilter(in.RepoHIDs))
+	}
+	if len(in.ActorHIDs) > 0 {
+		where = append(where, "actor_hid IN ?")
+		args = append(args, hidFilter(in.ActorHIDs))
+	}
+	if len(in.NLLangs) > 0 {
+		where = append(where, "lang_code IN ?")
+		args = append(args, in.NLLangs)
+	}
+	if in.LangReliable != nil {
+		if *in.LangReliable {
+			where = append(where, "lang_reliable = 1")
+		} else {
+			where = append(where, "lang_reliable = 0")
+		}
+	}
+	ex, err := s.exclusionsFor(ctx, in.GlobalOptions)
+	if err != nil {
+		return domain.CodeLangTimeseriesResp{}, err
+	}
+	where, args = ex.applyCrimes(where, args)
+
+	bucket, fmtMask := timeBucket(interval)
+	sql := fmt.Sprintf(`
+		SELECT
+			formatDateTime(%s, '%s') AS t,
+			lower(hex(repo_hid)) AS hid,
+			count() AS hits
+		FROM swearjar.commit_crimes
+		WHERE %s
+		GROUP BY t, hid
+	`, bucket("created_at"), fmtMask, strings.Join(where, " AND "))
+
+	type cell struct {
+		t    string
+		hid  string
+		hits uint64
+	}
+	var cells []cell
+	hids := map[string]struct{}{}
+
+	rs, err := s.ch.Query(ctx, sql, append([]any{tz}, args...)...)
+	if err != nil {
+		return domain.CodeLangTimeseriesResp{}, err
+	}
+	defer rs.Close()
+	for rs.Next() {
+		var c cell
+		if err := rs.Scan(&c.t, &c.hid, &c.hits); err != nil {
+			return domain.CodeLangTimeseriesResp{}, err
+		}
+		cells = append(cells, c)
+		hids[c.hid] = struct{}{}
+	}
+	if err := rs.Err(); err != nil {
+		return domain.CodeLangTimeseriesResp{}, err
+	}
+
+	langOf, err := s.repoPrimaryLangs(ctx, slices.Sorted(maps.Keys(hids)), in.CodeLangs)
+	if err != nil {
+		return domain.CodeLangTimeseriesResp{}, err
+	}
+
+	// Fold repos onto their language; each cell is one repo in one bucket
+	byLang := map[string]map[string]domain.CodeLangTimeseriesPoint{}
+	totals := map[string]int64{}
+	keys := map[string]struct{}{}
+	for _, c := range cells {
+		lang, ok := langOf[c.hid]
+		if !ok {
+			continue
+		}
+		pts := byLang[lang]
+		if pts == nil {
+			pts = map[string]domain.CodeLangTimeseriesPoint{}
+			byLang[lang] = pts
+		}
+		p := pts[c.t]
+		p.Hits += int64(c.hits)
+		p.Repos++
+		pts[c.t] = p
+		totals[lang] += int64(c.hits)
+		keys[c.t] = struct{}{}
+	}
+
+	langs := slices.SortedFunc(maps.Keys(byLang), func(a, b string) int {
+		if totals[a] != totals[b] {
+			if totals[a] > totals[b] {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a, b)
+	})
+	if len(langs) > topN {
+		langs = langs[:topN]
+	}
+
+	buckets := denseBucketKeys(interval, start, endExcl)
+	if buckets == nil {
+		buckets = slices.Sorted(maps.Keys(keys))
+	}
+	series := make([]domain.CodeLangSeries, 0, len(langs))
+	for _, lang := range langs {
+		pts := make([]domain.CodeLangTimeseriesPoint, 0, len(buckets))
+		for _, k := range buckets {
+			p := byLang[lang][k]
+			p.T = k
+			pts = append(pts, p)
+		}
+		series = append(series, domain.CodeLangSeries{CodeLang: lang, Points: pts})
+	}
+
+	return domain.CodeLangTimeseriesResp{Interval: interval, Series: series}, nil
+}
+
+// denseBucketKeys lists every hour/day/week label in [start, endExcl) as timeBucket formats
+// it, weeks starting on the Sunday toStartOfWeek rounds to; nil for calendar intervals,
+// whose variable step is left to the observed keys
+func denseBucketKeys(interval string, start, endExcl time.Time) []string {
+	step, layout := 24*time.Hour, "2006-01-02"
+	switch interval {
+	case "hour":
+		step, layout = time.Hour, "2006-01-02T15:00:00"
+	case "week":
+		step = 7 * 24 * time.Hour
+		start = start.AddDate(0, 0, -int(start.Weekday()))
+	case "day":
+	default:
+		return nil
+	}
+	var out []string
+	for t := start; t.Before(endExcl); t = t.Add(step) {
+		out = append(out, t.Format(layout))
+	}
+	return out
+}
+
+// repoPrimaryLangs maps hex repo HIDs to repositories.primary_lang (PG), skipping repos with
+// no cataloged language and, when codeLangs is set, languages outside it
+func (s *hybridStore) repoPrimaryLangs(
+	ctx context.Context,
+	hidHexes []string,
+	codeLangs []string,
+) (map[string]string, error) {
+	out := map[string]string{}
+	hids, err := decodeHIDs(hidHexes)
+	if err != nil || len(hids) == 0 {
+		return out, err
+	}
+	sql := `
+		SELECT repo_hid, primary_lang
+		FROM repositories
+		WHERE repo_hid = ANY($1) AND primary_lang IS NOT NULL AND primary_lang <> ''`
+	args := []any{hids}
+	if len(codeLangs) > 0 {
+		sql += ` AND primary_lang = ANY($2)`
+		args = append(args, codeLangs)
+	}
+	rs, err := s.pg.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("lookup repo primary langs: %w", err)
+	}
+	defer rs.Close()
+	for rs.Next() {
+		var hid []byte
+		var lang string
+		if err := rs.Scan(&hid, &lang); err != nil {
+			return nil, fmt.Errorf("scan repo primary lang: %w", err)
+		}
+		out[hex.EncodeToString(hid)] = lang
+	}
+	return out, rs.Err()
+}
@@ -0,0 +1,171 @@
+package repo
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"swearjar/internal/services/api/swearjar/domain"
+)
+
+const codeLangCells = "lower(hex(repo_hid)) AS hid"
+
+func codeLangStore(cells [][]any, langs [][]any) (*hybridStore, *fakeCH, *fakePG) {
+	ch := &fakeCH{results: []fakeResult{
+		{match: codeLangCells, cols: []string{"t", "hid", "hits"}, data: cells},
+	}}
+	pg := &fakePG{fakeCH{results: []fakeResult{
+		{match: "FROM repositories", cols: []string{"repo_hid", "primary_lang"}, data: langs},
+	}}}
+	s := newTestStore(ch)
+	s.pg = pg
+	return s, ch, pg
+}
+
+func codeLangInput(interval, start, end string) domain.CodeLangTimeseriesInput {
+	return domain.CodeLangTimeseriesInput{GlobalOptions: domain.GlobalOptions{
+		Range:    domain.TimeRange{Start: start, End: end},
+		Interval: interval,
+	}}
+}
+
+func TestCodeLangTimeseries_FoldsReposOntoLanguages(t *testing.T) {
+	t.Parallel()
+
+	s, ch, pg := codeLangStore(
+		[][]any{
+			{"2024-01-01", "aa", uint64(3)},
+			{"2024-01-01", "bb", uint64(2)},
+			{"2024-01-01", "ab", uint64(1)},
+			{"2024-01-03", "aa", uint64(1)},
+			{"2024-01-02", "cc", uint64(9)}, // no cataloged language
+		},
+		[][]any{{[]byte{0xaa}, "Rust"}, {[]byte{0xab}, "Rust"}, {[]byte{0xbb}, "Go"}},
+	)
+
+	resp, err := s.CodeLangTimeseries(context.Background(), codeLangInput("", "2024-01-01", "2024-01-03"))
+	if err != nil {
+		t.Fatalf("CodeLangTimeseries err: %v", err)
+	}
+	want := domain.CodeLangTimeseriesResp{Interval: "day", Series: []domain.CodeLangSeries{
+		{CodeLang: "Rust", Points: []domain.CodeLangTimeseriesPoint{
+			{T: "2024-01-01", Hits: 4, Repos: 2},
+			{T: "2024-01-02"},
+			{T: "2024-01-03", Hits: 1, Repos: 1},
+		}},
+		{CodeLang: "Go", Points: []domain.CodeLangTimeseriesPoint{
+			{T: "2024-01-01", Hits: 2, Repos: 1},
+			{T: "2024-01-02"},
+			{T: "2024-01-03"},
+		}},
+	}}
+	if !reflect.DeepEqual(resp, want) {
+		t.Fatalf("resp\n got %+v\nwant %+v", resp, want)
+	}
+
+	c, _ := ch.call(codeLangCells)
+	if !strings.Contains(c.sql, "toStartOfDay(toTimeZone(created_at, ?))") ||
+		!reflect.DeepEqual(c.args, []any{"UTC", day("2024-01-01"), day("2024-01-04")}) {
+		t.Fatalf("crimes query %q args %v", c.sql, c.args)
+	}
+	p, _ := pg.call("FROM repositories")
+	wantHIDs := [][]byte{{0xaa}, {0xab}, {0xbb}, {0xcc}}
+	if len(p.args) != 1 || !reflect.DeepEqual(p.args[0], wantHIDs) {
+		t.Fatalf("pg args %v, want the distinct repos %v", p.args, wantHIDs)
+	}
+}
+
+func TestCodeLangTimeseries_ScopeFiltersAndTopN(t *testing.T) {
+	t.Parallel()
+
+	s, ch, pg := codeLangStore(
+		[][]any{{"2024-01-01", "aa", uint64(1)}, {"2024-01-01", "bb", uint64(5)}},
+		[][]any{{[]byte{0xaa}, "Rust"}, {[]byte{0xbb}, "Go"}},
+	)
+	s.detver = 2
+	reliable := true
+	in := codeLangInput("day", "2024-01-01", "2024-01-01")
+	in.TZ = "Europe/Berlin"
+	in.RepoHIDs = []string{"aa"}
+	in.NLLangs = []string{"en"}
+	in.LangReliable = &reliable
+	in.CodeLangs = []string{"Go", "Rust"}
+	in.TopN = 1
+
+	resp, err := s.CodeLangTimeseries(context.Background(), in)
+	if err != nil {
+		t.Fatalf("CodeLangTimeseries err: %v", err)
+	}
+	if len(resp.Series) != 1 || resp.Series[0].CodeLang != "Go" {
+		t.Fatalf("series %+v, want only the top language", resp.Series)
+	}
+
+	c, _ := ch.call(codeLangCells)
+	for _, clause := range []string{"detver IN ?", "repo_hid IN ?", "lang_code IN ?", "lang_reliable = 1"} {
+		if !strings.Contains(c.sql, clause) {
+			t.Fatalf("crimes query missing %q: %s", clause, c.sql)
+		}
+	}
+	wantArgs := []any{"Europe/Berlin", day("2024-01-01"), day("2024-01-02"), []int{2}, [][]byte{{0xaa}}, []string{"en"}}
+	if !reflect.DeepEqual(c.args, wantArgs) {
+		t.Fatalf("crimes args %v want %v", c.args, wantArgs)
+	}
+	p, _ := pg.call("FROM repositories")
+	if !strings.Contains(p.sql, "primary_lang = ANY($2)") || !reflect.DeepEqual(p.args[1], in.CodeLangs) {
+		t.Fatalf("code lang filter not passed to pg: %q %v", p.sql, p.args)
+	}
+}
+
+func TestCodeLangTimeseries_Buckets(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		interval, start, end string
+		cells                [][]any
+		want                 []string
+	}{
+		// weeks start on the Sunday toStartOfWeek rounds to
+		{"week", "2024-01-03", "2024-01-10", [][]any{{"2024-01-07", "aa", uint64(1)}},
+			[]string{"2023-12-31", "2024-01-07"}},
+		{"hour", "2024-01-01", "2024-01-01", [][]any{{"2024-01-01T05:00:00", "aa", uint64(1)}}, nil},
+		// calendar buckets keep the observed keys, sorted
+		{"month", "2024-01-01", "2024-06-30", [][]any{
+			{"2024-05-01", "aa", uint64(1)}, {"2024-02-01", "aa", uint64(1)},
+		}, []string{"2024-02-01", "2024-05-01"}},
+	}
+	for _, tc := range cases {
+		s, _, _ := codeLangStore(tc.cells, [][]any{{[]byte{0xaa}, "Rust"}})
+		resp, err := s.CodeLangTimeseries(context.Background(), codeLangInput(tc.interval, tc.start, tc.end))
+		if err != nil {
+			t.Fatalf("%s: %v", tc.interval, err)
+		}
+		var got []string
+		for _, p := range resp.Series[0].Points {
+			got = append(got, p.T)
+		}
+		if tc.interval == "hour" {
+			if len(got) != 24 || got[5] != "2024-01-01T05:00:00" || resp.Series[0].Points[5].Hits != 1 {
+				t.Fatalf("hour: %d buckets %v", len(got), got)
+			}
+			continue
+		}
+		if !slices.Equal(got, tc.want) {
+			t.Fatalf("%s: buckets %v, want %v", tc.interval, got, tc.want)
+		}
+	}
+}
+
+func TestCodeLangTimeseries_NoHitsSkipsPostgres(t *testing.T) {
+	t.Parallel()
+
+	s, _, pg := codeLangStore(nil, nil)
+	resp, err := s.CodeLangTimeseries(context.Background(), codeLangInput("", "2024-01-01", "2024-01-02"))
+	if err != nil || len(resp.Series) != 0 {
+		t.Fatalf("resp %+v err %v", resp, err)
+	}
+	if len(pg.calls) != 0 {
+		t.Fatalf("looked up languages with no repos: %v", pg.calls)
+	}
+}
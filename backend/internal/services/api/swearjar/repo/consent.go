@@ -0,0 +1,92 @@
+package repo
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+
+	"swearjar/internal/services/api/swearjar/domain"
+)
+
+// consentSet is the principals (lower hex HIDs) with an active opt-in among those looked up
+type consentSet struct{ repos, actors map[string]bool }
+
+// covers reports whether an utterance's text may be shown: its repo or its actor opted in
+func (c consentSet) covers(repoHex, actorHex string) bool {
+	return c.repos[repoHex] || c.actors[actorHex]
+}
+
+// consented looks up, in one Postgres round trip, which of the given repo and actor HIDs have
+// an active opt-in. It is the consent side of samples/export queries whose facts live in CH
+func (s *hybridStore) consented(ctx context.Context, repoHexes, actorHexes []string) (consentSet, error) {
+	out := consentSet{repos: map[string]bool{}, actors: map[string]bool{}}
+	repos, err := decodeHIDs(repoHexes)
+	if err != nil {
+		return out, err
+	}
+	actors, err := decodeHIDs(actorHexes)
+	if err != nil {
+		return out, err
+	}
+	if len(repos) == 0 && len(actors) == 0 {
+		return out, nil
+	}
+
+	rs, err := s.pg.Query(ctx, `
+		SELECT 'repo' AS principal, r.repo_hid AS hid
+		FROM repositories r
+		JOIN consent_receipts c ON c.consent_id = r.consent_id
+		WHERE r.repo_hid = ANY($1) AND c.action = 'opt_in' AND c.state = 'active'
+		UNION ALL
+		SELECT 'actor' AS principal, a.actor_hid AS hid
+		FROM actors a
+		JOIN consent_receipts c ON c.consent_id = a.consent_id
+		WHERE a.actor_hid = ANY($2) AND c.action = 'opt_in' AND c.state = 'active'
+	`, repos, actors)
+	if err != nil {
+		return out, fmt.Errorf("lookup text consent: %w", err)
+	}
+	defer rs.Close()
+	for rs.Next() {
+		var principal string
+		var hid []byte
+		if err := rs.Scan(&principal, &hid); err != nil {
+			return out, fmt.Errorf("scan text consent: %w", err)
+		}
+		if principal == "actor" {
+			out.actors[hex.EncodeToString(hid)] = true
+		} else {
+			out.repos[hex.EncodeToString(hid)] = true
+		}
+	}
+	return out, rs.Err()
+}
+
+// decodeHIDs turns hex HIDs into the bytea values Postgres keys on, dropping duplicates
+func decodeHIDs(hexes []string) ([][]byte, error) {
+	seen := make(map[string]bool, len(hexes))
+	out := make([][]byte, 0, len(hexes))
+	for _, h := range hexes {
+		if seen[h] {
+			continue
+		}
+		seen[h] = true
+		b, err := hex.DecodeString(h)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, b)
+	}
+	return out, nil
+}
+
+// omitText strips everything derived from an unconsented utterance's text: the text itself and
+// each hit's term and spans. Category and severity stay, so the card still counts and sorts
+func omitText(item *domain.SampleItem) {
+	item.TextMasked = ""
+	item.TextOmitted = true
+	for i := range item.Hits {
+		item.Hits[i].Term = ""
+		item.Hits[i].Spans = [][2]int{}
+	}
+}
@@ -0,0 +1,75 @@
+package repo
+
+import (
+	"context"
+	"encoding/hex"
+	"reflect"
+	"testing"
+
+	"swearjar/internal/services/api/swearjar/domain"
+)
+
+// sampleCols are the folded commit_crimes columns samplePage scans
+var sampleCols = []string{
+	"sample_key", "uid", "created_at", "src", "repo_hex", "actor_hex", "detver",
+	"terms", "cats", "sevs", "starts", "ends",
+}
+
+func TestSamples_ConsentedTextOnly(t *testing.T) {
+	t.Parallel()
+
+	const (
+		uidOK   = "00000000-0000-0000-0000-000000000001"
+		uidNope = "00000000-0000-0000-0000-000000000002"
+	)
+	at := day("2024-01-02")
+	ch := &fakeCH{results: []fakeResult{
+		{match: "FROM swearjar.commit_crimes", cols: sampleCols, data: [][]any{
+			{uint64(0), uidOK, at, "commit", hidA, hidB, int32(1),
+				[]string{"shit"}, []string{"tooling_rage"}, []string{"strong"}, []int32{5}, []int32{9}},
+			{uint64(0), uidNope, at, "commit", hidC, hidC, int32(1),
+				[]string{"fuck"}, []string{"generic"}, []string{"strong"}, []int32{0}, []int32{4}},
+		}},
+		{match: "FROM swearjar.utterances", cols: []string{"uid", "txt"}, data: [][]any{
+			{uidOK, "this shit again"},
+		}},
+	}}
+	actorB, _ := hex.DecodeString(hidB)
+	s := newTestStore(ch)
+	s.textConsent = true
+	s.pg = &fakePG{fakeCH{results: []fakeResult{
+		{match: "consent_receipts", cols: []string{"principal", "hid"}, data: [][]any{{"actor", actorB}}},
+	}}}
+
+	in := domain.SamplesInput{GlobalOptions: leaderOpts(0, "")}
+	resp, err := s.Samples(context.Background(), in)
+	if err != nil {
+		t.Fatalf("Samples err: %v", err)
+	}
+	if len(resp.Items) != 2 {
+		t.Fatalf("got %d items, want 2", len(resp.Items))
+	}
+
+	ok, nope := resp.Items[0], resp.Items[1]
+	if ok.TextOmitted || ok.TextMasked != "this s*** again" {
+		t.Fatalf("consented actor: text %q omitted %v", ok.TextMasked, ok.TextOmitted)
+	}
+	wantHits := []domain.SampleHit{{Term: "shit", Spans: [][2]int{{5, 9}}, Category: "tooling_rage", Severity: "strong"}}
+	if !reflect.DeepEqual(ok.Hits, wantHits) {
+		t.Fatalf("consented hits %+v", ok.Hits)
+	}
+
+	if !nope.TextOmitted || nope.TextMasked != "" || nope.Repo.HID != hidC || nope.DetVer != 1 {
+		t.Fatalf("unconsented card %+v", nope)
+	}
+	wantHits = []domain.SampleHit{{Spans: [][2]int{}, Category: "generic", Severity: "strong"}}
+	if !reflect.DeepEqual(nope.Hits, wantHits) {
+		t.Fatalf("unconsented hits %+v, want only category and severity", nope.Hits)
+	}
+
+	// only the consented utterance's text is read from ClickHouse
+	c, _ := ch.call("FROM swearjar.utterances")
+	if ids := c.args[len(c.args)-1]; !reflect.DeepEqual(ids, []string{uidOK}) {
+		t.Fatalf("text lookup ids %v", ids)
+	}
+}
@@ -0,0 +1,117 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"swearjar/internal/services/api/swearjar/domain"
+)
+
+// DetverDiffRows reads every stored hit at detvers A and B for a sample of utterances with a hit
+// at either. Only hours both detvers have hits in are sampled, so an utterance one version
+// dropped entirely (or newly flagged) counts, while hours the other never ran over don't read as
+// wholesale losses. The sample is ordered by a hash of utterance_id, so it is stable across calls
+// for the same window and spreads across repos instead of taking the first ones in sort order
+func (s *hybridStore) DetverDiffRows(ctx context.Context, in domain.DetverDiffInput) ([]domain.DetverDiffRow, error) {
+	startTS, endTS, err := sampleWindow(in.GlobalOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	where := []string{"created_at >= ?", "created_at < ?", "detector_version IN (?, ?)"}
+	args := []any{startTS, endTS, in.A, in.B}
+	if len(in.RepoHIDs) > 0 {
+		where = append(where, "repo_hid IN ?")
+		args = append(args, hidFilter(in.RepoHIDs))
+	}
+	if len(in.ActorHIDs) > 0 {
+		where = append(where, "actor_hid IN ?")
+		args = append(args, hidFilter(in.ActorHIDs))
+	}
+	if len(in.NLLangs) > 0 {
+		where = append(where, "lang_code IN ?")
+		args = append(args, in.NLLangs)
+	}
+
+	ex, err := s.exclusionsFor(ctx, in.GlobalOptions)
+	if err != nil {
+		return nil, err
+	}
+	where, args = ex.apply(where, args)
+	cond := strings.Join(where, " AND ")
+
+	// The outer read repeats the filters so the IN subquery only narrows, never widens, the scan.
+	// Which hours a detver processed ignores the filters: a repo B stopped flagging is still an
+	// hour B ran over
+	q := `
+		SELECT
+		  toString(utterance_id) AS uid,
+		  detector_version,
+		  term,
+		  toString(category),
+		  toString(severity),
+		  span_start,
+		  span_end
+		FROM swearjar.hits FINAL
+		WHERE ` + cond + `
+		  AND utterance_id IN (
+		    SELECT utterance_id
+		    FROM swearjar.hits
+		    WHERE ` + cond + `
+		      AND toStartOfHour(created_at) IN (
+		        SELECT toStartOfHour(created_at) AS h
+		        FROM swearjar.hits
+		        WHERE created_at >= ? AND created_at < ? AND detector_version IN (?, ?)
+		        GROUP BY h
+		        HAVING uniqExact(detector_version) = 2
+		      )
+		    GROUP BY utterance_id
+		    ORDER BY cityHash64(utterance_id) ASC, utterance_id ASC
+		    LIMIT ?
+		  )
+		ORDER BY uid ASC, detector_version ASC, span_start ASC, span_end ASC, term ASC
+	`
+	all := append(append(append([]any{}, args...), args...), startTS, endTS, in.A, in.B, in.Sample)
+	rs, err := s.ch.Query(ctx, q, all...)
+	if err != nil {
+		return nil, fmt.Errorf("detver diff: %w", err)
+	}
+	defer rs.Close()
+
+	out := make([]domain.DetverDiffRow, 0, in.Sample*2)
+	for rs.Next() {
+		var r domain.DetverDiffRow
+		var v, a, b int32
+		if err := rs.Scan(&r.UtteranceID, &v, &r.Term, &r.Category, &r.Severity, &a, &b); err != nil {
+			return nil, fmt.Errorf("scan detver diff: %w", err)
+		}
+		r.Detver, r.SpanStart, r.SpanEnd = int(v), int(a), int(b)
+		out = append(out, r)
+	}
+	if err := rs.Err(); err != nil {
+		return nil, fmt.Errorf("detver diff: %w", err)
+	}
+	return out, nil
+}
+
+// KnownDetvers lists the detector versions with stored hits, oldest first
+func (s *hybridStore) KnownDetvers(ctx context.Context) ([]int, error) {
+	rs, err := s.ch.Query(ctx, `SELECT detver FROM swearjar.commit_crimes GROUP BY detver ORDER BY detver`)
+	if err != nil {
+		return nil, fmt.Errorf("known detvers: %w", err)
+	}
+	defer rs.Close()
+	out := []int{}
+	for rs.Next() {
+		var v int32
+		if err := rs.Scan(&v); err != nil {
+			return nil, fmt.Errorf("scan known detver: %w", err)
+		}
+		out = append(out, int(v))
+	}
+	if err := rs.Err(); err != nil {
+		return nil, fmt.Errorf("known detvers: %w", err)
+	}
+	return out, nil
+}
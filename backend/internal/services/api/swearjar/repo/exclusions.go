@@ -0,0 +1,172 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"swearjar/internal/modkit/repokit"
+	"swearjar/internal/services/api/swearjar/domain"
+)
+
+// exclusionsTTL bounds how stale the exclusion list may be; a principal added to
+// analytics_exclusions drops out of every endpoint within this window, no restart needed
+// The bot actor list shares the TTL but loads on its own, on first use
+const exclusionsTTL = 5 * time.Minute
+
+// exclusions are principals removed from all analytics (legal requests), unlike consent
+// opt-outs which only mask identity; their hits never reach any aggregate or sample
+// The bot flags are per request (GlobalOptions); the lists are shared
+type exclusions struct {
+	repos  [][]byte
+	actors [][]byte
+	bots   [][]byte // actors cataloged as GitHub bots (type 'Bot' or a "[bot]" login)
+
+	dropBotActors  bool // also exclude utterances authored by bots
+	dropBotTargets bool // also exclude hits aimed at bots (commit_crimes only)
+}
+
+// scoped applies the request's bot options to a copy of the shared lists
+func (e exclusions) scoped(g domain.GlobalOptions) exclusions {
+	e.dropBotActors = g.ExcludeBotActors
+	e.dropBotTargets = g.ExcludeBotTargets
+	return e
+}
+
+// apply appends NOT IN predicates for the excluded principals to a WHERE list
+// Both commit_crimes and utt_hour_agg carry repo_hid/actor_hid, so it fits either
+func (e exclusions) apply(where []string, args []any) ([]string, []any) {
+	if len(e.repos) > 0 {
+		where = append(where, "repo_hid NOT IN ?")
+		args = append(args, e.repos)
+	}
+	if len(e.actors) > 0 {
+		where = append(where, "actor_hid NOT IN ?")
+		args = append(args, e.actors)
+	}
+	if e.dropBotActors && len(e.bots) > 0 {
+		where = append(where, "actor_hid NOT IN ?")
+		args = append(args, e.bots)
+	}
+	return where, args
+}
+
+// applyCrimes is apply plus the predicates only commit_crimes can answer (hit targets)
+func (e exclusions) applyCrimes(where []string, args []any) ([]string, []any) {
+	where, args = e.apply(where, args)
+	if e.dropBotTargets {
+		where = append(where, "target_type != 'bot'")
+	}
+	return where, args
+}
+
+// exclusionCache loads analytics_exclusions at most once per TTL, shared by all binds
+// The bot actor list is cached separately and only loaded once a request asks for ExcludeBotActors
+type exclusionCache struct {
+	mu     sync.Mutex
+	ttl    time.Duration
+	loaded time.Time
+	val    exclusions
+	now    func() time.Time
+
+	botsLoaded time.Time
+	bots       [][]byte
+}
+
+func newExclusionCache(ttl time.Duration) *exclusionCache {
+	return &exclusionCache{ttl: ttl, now: time.Now}
+}
+
+// fresh reports whether a list loaded at t is still within the TTL
+func (c *exclusionCache) fresh(t time.Time) bool {
+	return !t.IsZero() && c.now().Sub(t) < c.ttl
+}
+
+// get returns the cached list, reloading it through q when stale
+// A failed load fails the request rather than serving aggregates that include excluded principals
+func (c *exclusionCache) get(ctx context.Context, q repokit.Queryer) (exclusions, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.fresh(c.loaded) {
+		return c.val, nil
+	}
+
+	rs, err := q.Query(ctx, `SELECT principal::text, principal_hid FROM analytics_exclusions`)
+	if err != nil {
+		return exclusions{}, fmt.Errorf("load analytics exclusions: %w", err)
+	}
+	defer rs.Close()
+
+	var ex exclusions
+	for rs.Next() {
+		var principal string
+		var hid []byte
+		if err := rs.Scan(&principal, &hid); err != nil {
+			return exclusions{}, fmt.Errorf("scan analytics exclusion: %w", err)
+		}
+		switch principal {
+		case "repo":
+			ex.repos = append(ex.repos, hid)
+		case "actor":
+			ex.actors = append(ex.actors, hid)
+		}
+	}
+	if err := rs.Err(); err != nil {
+		return exclusions{}, fmt.Errorf("load analytics exclusions: %w", err)
+	}
+
+	c.val, c.loaded = ex, c.now()
+	return ex, nil
+}
+
+// botActors returns the cached bot actor list, reloading it through q when stale
+// Only requests with ExcludeBotActors get here, so a failed load fails just those
+func (c *exclusionCache) botActors(ctx context.Context, q repokit.Queryer) ([][]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.fresh(c.botsLoaded) {
+		return c.bots, nil
+	}
+
+	// Uncataloged bots (no actors row yet) are not known here, so ExcludeBotActors is best effort
+	rs, err := q.Query(ctx, `SELECT actor_hid FROM actors WHERE type = 'Bot' OR login LIKE '%[bot]'`)
+	if err != nil {
+		return nil, fmt.Errorf("load bot actors: %w", err)
+	}
+	defer rs.Close()
+
+	var bots [][]byte
+	for rs.Next() {
+		var hid []byte
+		if err := rs.Scan(&hid); err != nil {
+			return nil, fmt.Errorf("scan bot actor: %w", err)
+		}
+		bots = append(bots, hid)
+	}
+	if err := rs.Err(); err != nil {
+		return nil, fmt.Errorf("load bot actors: %w", err)
+	}
+
+	c.bots, c.botsLoaded = bots, c.now()
+	return bots, nil
+}
+
+// exclusions returns the current analytics exclusion list
+func (s *hybridStore) exclusions(ctx context.Context) (exclusions, error) {
+	return s.excl.get(ctx, s.pg)
+}
+
+// exclusionsFor returns the exclusion list scoped to a request's bot options
+func (s *hybridStore) exclusionsFor(ctx context.Context, g domain.GlobalOptions) (exclusions, error) {
+	ex, err := s.excl.get(ctx, s.pg)
+	if err != nil {
+		return exclusions{}, err
+	}
+	if g.ExcludeBotActors {
+		if ex.bots, err = s.excl.botActors(ctx, s.pg); err != nil {
+			return exclusions{}, err
+		}
+	}
+	return ex.scoped(g), nil
+}
@@ -0,0 +1,92 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"swearjar/internal/services/api/swearjar/domain"
+)
+
+func TestExclusionsLoadBotsOnlyWhenAsked(t *testing.T) {
+	pg := &fakePG{fakeCH{results: []fakeResult{
+		{match: "analytics_exclusions", cols: []string{"principal", "principal_hid"},
+			data: [][]any{{"repo", []byte{1}}, {"actor", []byte{2}}}},
+		{match: "FROM actors", err: errors.New("actors unavailable")},
+	}}}
+	s := newTestStore(&fakeCH{})
+	s.excl = newExclusionCache(time.Hour)
+	s.pg = pg
+
+	ex, err := s.exclusionsFor(context.Background(), domain.GlobalOptions{ExcludeBotTargets: true})
+	if err != nil {
+		t.Fatalf("a request without ExcludeBotActors must not need the bot list: %v", err)
+	}
+	if _, ok := pg.call("FROM actors"); ok {
+		t.Fatal("bot list loaded for a request that didn't ask for it")
+	}
+	where, args := ex.applyCrimes(nil, nil)
+	want := []string{"repo_hid NOT IN ?", "actor_hid NOT IN ?", "target_type != 'bot'"}
+	if !slices.Equal(where, want) || len(args) != 2 {
+		t.Fatalf("where = %v (%d args), want %v", where, len(args), want)
+	}
+
+	if _, err := s.exclusionsFor(context.Background(), domain.GlobalOptions{ExcludeBotActors: true}); err == nil {
+		t.Fatal("a failed bot load should fail the request that asked for it")
+	}
+}
+
+func TestExclusionsBotActors(t *testing.T) {
+	pg := &fakePG{fakeCH{results: []fakeResult{
+		{match: "analytics_exclusions", cols: []string{"principal", "principal_hid"}},
+		{match: "FROM actors", cols: []string{"actor_hid"}, data: [][]any{{[]byte{7}}}},
+	}}}
+	s := newTestStore(&fakeCH{})
+	s.excl = newExclusionCache(time.Hour)
+	s.pg = pg
+
+	for range 2 {
+		ex, err := s.exclusionsFor(context.Background(), domain.GlobalOptions{ExcludeBotActors: true})
+		if err != nil {
+			t.Fatalf("exclusionsFor: %v", err)
+		}
+		where, args := ex.apply(nil, nil)
+		if !slices.Equal(where, []string{"actor_hid NOT IN ?"}) || len(args) != 1 {
+			t.Fatalf("where = %v, args = %v", where, args)
+		}
+		if !ex.drops("actor", []byte{7}) || ex.drops("repo", []byte{7}) {
+			t.Fatal("drops should match the bot actor only")
+		}
+	}
+	n := 0
+	for _, c := range pg.calls {
+		if strings.Contains(c.sql, "FROM actors") {
+			n++
+		}
+	}
+	if n != 1 {
+		t.Fatalf("bot list loaded %d times, want once within the TTL", n)
+	}
+}
+
+func TestExclusionCacheTTL(t *testing.T) {
+	now := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	pg := &fakePG{fakeCH{results: []fakeResult{
+		{match: "analytics_exclusions", cols: []string{"principal", "principal_hid"}},
+	}}}
+	c := newExclusionCache(exclusionsTTL)
+	c.now = func() time.Time { return now }
+
+	for _, step := range []time.Duration{0, exclusionsTTL - time.Second, time.Second} {
+		now = now.Add(step)
+		if _, err := c.get(context.Background(), pg); err != nil {
+			t.Fatalf("get: %v", err)
+		}
+	}
+	if len(pg.calls) != 2 {
+		t.Fatalf("%d loads, want 2 (initial and after the TTL lapsed)", len(pg.calls))
+	}
+}
@@ -0,0 +1,268 @@
+package repo
+
+import (
+	"context"
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	perr "swearjar/internal/platform/errors"
+	"swearjar/internal/services/api/swearjar/domain"
+)
+
+// exportPageSize bounds each ClickHouse round trip while streaming an export
+const exportPageSize = 500
+
+// ExportHits streams up to maxRows anonymized records (newest first) to emit, paging with the
+// same keyset as Samples. It returns the cursor to resume from when the cap was reached, or ""
+// when the window is exhausted. Text is always masked; no names are looked up
+//
+// With in.Parallel and workers > 1 the window is scanned as range partitions instead (see
+// exportParallel); records then arrive in no global order and the cursor is a partition cursor
+func (s *hybridStore) ExportHits(
+	ctx context.Context,
+	in domain.ExportHitsInput,
+	maxRows, workers int,
+	emit func(domain.ExportHitRecord) error,
+) (string, error) {
+	limit := maxRows
+	if in.Limit > 0 && in.Limit < limit {
+		limit = in.Limit
+	}
+
+	switch {
+	case in.Parallel && workers > 1:
+		return s.exportParallel(ctx, in, limit, workers, emit)
+	case isParallelCursor(in.Page.Cursor):
+		return "", perr.WithField(perr.InvalidArgf("cursor was issued for a parallel export"), "page.cursor")
+	}
+
+	cursor, rows := in.Page.Cursor, 0
+	for rows < limit {
+		n := min(exportPageSize, limit-rows)
+		cards, err := s.samplePage(ctx, in.GlobalOptions, sampleFilter{term: in.Term}, cursor, n, nil)
+		if err != nil {
+			return "", err
+		}
+		for _, c := range cards {
+			if err := emit(c.exportRecord()); err != nil {
+				return "", err
+			}
+		}
+		rows += len(cards)
+		if len(cards) < n {
+			return "", nil
+		}
+		cursor = cards[len(cards)-1].cursor()
+	}
+	return cursor, nil
+}
+
+// exportPart is one half-open [from, to) sub-range of a parallel export and its keyset position
+type exportPart struct {
+	from, to time.Time
+	cursor   string // last emitted record in this sub-range ("" = not started)
+	done     bool
+}
+
+// exportParallel splits the window into one contiguous sub-range per worker and keyset-scans
+// them concurrently. Sub-ranges are half-open on created_at, so a row on a boundary belongs to
+// exactly one of them and every utterance is emitted once. emit calls are serialized and the
+// row cap is shared; a partition stops mid-page when the cap is hit and its cursor records the
+// last row actually emitted, so the returned cursor resumes every partition without gaps
+func (s *hybridStore) exportParallel(
+	ctx context.Context,
+	in domain.ExportHitsInput,
+	limit, workers int,
+	emit func(domain.ExportHitRecord) error,
+) (string, error) {
+	startTS, endTS, err := sampleWindow(in.GlobalOptions)
+	if err != nil {
+		return "", err
+	}
+	var parts []*exportPart
+	if c := strings.TrimSpace(in.Page.Cursor); c != "" {
+		if parts, err = decodeParallelCursor(c, startTS, endTS); err != nil {
+			return "", perr.WithField(err, "page.cursor")
+		}
+	} else {
+		parts = splitWindow(startTS, endTS, workers)
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	var (
+		mu   sync.Mutex // serializes emit, rows and partition cursors
+		rows int
+	)
+	scan := func(p *exportPart) error {
+		for !p.done {
+			mu.Lock()
+			n := min(exportPageSize, limit-rows)
+			mu.Unlock()
+			if n <= 0 {
+				return nil
+			}
+			f := sampleFilter{term: in.Term}
+			cards, err := s.sampleRangePage(ctx, in.GlobalOptions, p.from, p.to, f, p.cursor, n, nil)
+			if err != nil {
+				return err
+			}
+
+			mu.Lock()
+			emitted := 0
+			for _, c := range cards {
+				if rows >= limit {
+					break
+				}
+				if err := emit(c.exportRecord()); err != nil {
+					mu.Unlock()
+					return err
+				}
+				rows++
+				emitted++
+				p.cursor = c.cursor()
+			}
+			mu.Unlock()
+
+			if emitted < len(cards) {
+				return nil // cap reached mid-page; resume after p.cursor
+			}
+			p.done = len(cards) < n
+		}
+		return nil
+	}
+
+	queue := make(chan *exportPart, len(parts))
+	for _, p := range parts {
+		if !p.done {
+			queue <- p
+		}
+	}
+	close(queue)
+
+	var wg sync.WaitGroup
+	for range min(workers, len(queue)) {
+		wg.Go(func() {
+			for p := range queue {
+				if ctx.Err() != nil {
+					return
+				}
+				if err := scan(p); err != nil {
+					cancel(err)
+					return
+				}
+			}
+		})
+	}
+	wg.Wait()
+	if err := context.Cause(ctx); err != nil {
+		return "", err
+	}
+
+	for _, p := range parts {
+		if !p.done {
+			return encodeParallelCursor(parts), nil
+		}
+	}
+	return "", nil
+}
+
+// exportRecord projects a folded card onto the anonymized export record
+func (c sampleCard) exportRecord() domain.ExportHitRecord {
+	return domain.ExportHitRecord{
+		UtteranceID: c.item.UtteranceID,
+		CreatedAt:   c.item.CreatedAt,
+		Source:      c.item.Source,
+		RepoHID:     c.item.Repo.HID,
+		ActorHID:    c.item.Actor.HID,
+		DetVer:      c.item.DetVer,
+		Hits:        c.item.Hits,
+		TextMasked:  c.item.TextMasked,
+		TextOmitted: c.item.TextOmitted,
+	}
+}
+
+// splitWindow cuts [start, end) into n contiguous half-open sub-ranges on whole seconds
+// The last sub-range always ends at end, so rounding never drops the tail of the window
+func splitWindow(start, end time.Time, n int) []*exportPart {
+	step := end.Sub(start) / time.Duration(n)
+	parts := make([]*exportPart, 0, n)
+	from := start
+	for i := 1; i <= n; i++ {
+		to := start.Add(step * time.Duration(i)).Truncate(time.Second)
+		if i == n {
+			to = end
+		}
+		if !to.After(from) {
+			continue // window narrower than n seconds
+		}
+		parts = append(parts, &exportPart{from: from, to: to})
+		from = to
+	}
+	return parts
+}
+
+// parallel cursors are opaque base64url("p|<from_ms>~<to_ms>~<cursor>;...") where cursor is a
+// sample cursor, "" for a partition not yet started, or "-" for one that is exhausted
+func isParallelCursor(c string) bool {
+	raw, err := base64.RawURLEncoding.DecodeString(strings.TrimSpace(c))
+	return err == nil && strings.HasPrefix(string(raw), "p|")
+}
+
+func encodeParallelCursor(parts []*exportPart) string {
+	segs := make([]string, len(parts))
+	for i, p := range parts {
+		c := p.cursor
+		if p.done {
+			c = "-"
+		}
+		segs[i] = strconv.FormatInt(p.from.UnixMilli(), 10) + "~" + strconv.FormatInt(p.to.UnixMilli(), 10) + "~" + c
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte("p|" + strings.Join(segs, ";")))
+}
+
+// decodeParallelCursor rejects cursors whose partitions fall outside [start, end) so a cursor
+// can't widen the window it was issued for
+func decodeParallelCursor(c string, start, end time.Time) ([]*exportPart, error) {
+	bad := perr.InvalidArgf("invalid cursor")
+	raw, err := base64.RawURLEncoding.DecodeString(c)
+	if err != nil {
+		return nil, bad
+	}
+	body, ok := strings.CutPrefix(string(raw), "p|")
+	if !ok {
+		return nil, perr.InvalidArgf("cursor was issued for a serial export")
+	}
+	var parts []*exportPart
+	for seg := range strings.SplitSeq(body, ";") {
+		f := strings.SplitN(seg, "~", 3)
+		if len(f) != 3 {
+			return nil, bad
+		}
+		fromMS, err1 := strconv.ParseInt(f[0], 10, 64)
+		toMS, err2 := strconv.ParseInt(f[1], 10, 64)
+		if err1 != nil || err2 != nil {
+			return nil, bad
+		}
+		p := &exportPart{from: time.UnixMilli(fromMS).UTC(), to: time.UnixMilli(toMS).UTC()}
+		if p.from.Before(start) || p.to.After(end) || !p.to.After(p.from) {
+			return nil, perr.InvalidArgf("cursor does not match the requested range")
+		}
+		switch f[2] {
+		case "-":
+			p.done = true
+		case "":
+		default:
+			if _, _, _, err := decodeSampleCursor(f[2]); err != nil {
+				return nil, bad
+			}
+			p.cursor = f[2]
+		}
+		parts = append(parts, p)
+	}
+	return parts, nil
+}
@@ -0,0 +1,227 @@
+package repo
+
+import (
+	"context"
+	"encoding/base64"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"swearjar/internal/platform/store"
+	"swearjar/internal/services/api/swearjar/domain"
+)
+
+func TestSplitWindow(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	cases := []struct {
+		name string
+		end  time.Time
+		n    int
+		want []time.Duration // each partition's end, relative to start
+	}{
+		{"even", start.Add(24 * time.Hour), 3, []time.Duration{8 * time.Hour, 16 * time.Hour, 24 * time.Hour}},
+		{"ragged tail", start.Add(10500 * time.Millisecond), 3,
+			[]time.Duration{3 * time.Second, 7 * time.Second, 10500 * time.Millisecond}},
+		{"narrower than n seconds", start.Add(2 * time.Second), 5, []time.Duration{time.Second, 2 * time.Second}},
+		{"sub-second", start.Add(300 * time.Millisecond), 4, []time.Duration{300 * time.Millisecond}},
+	}
+	for _, tc := range cases {
+		parts := splitWindow(start, tc.end, tc.n)
+		if len(parts) != len(tc.want) {
+			t.Fatalf("%s: %d parts, want %d", tc.name, len(parts), len(tc.want))
+		}
+		from := start
+		for i, p := range parts {
+			if !p.from.Equal(from) || !p.to.Equal(start.Add(tc.want[i])) {
+				t.Fatalf("%s: part %d is [%v, %v), want [%v, %v)", tc.name, i, p.from, p.to, from, start.Add(tc.want[i]))
+			}
+			from = p.to
+		}
+		if !parts[len(parts)-1].to.Equal(tc.end) {
+			t.Fatalf("%s: last part ends at %v, not the window end", tc.name, parts[len(parts)-1].to)
+		}
+	}
+}
+
+func TestParallelCursorRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(24 * time.Hour)
+	mid := encodeSampleCursor(start.Add(20*time.Hour), "u-1", 2)
+	parts := []*exportPart{
+		{from: start, to: start.Add(8 * time.Hour), done: true},
+		{from: start.Add(8 * time.Hour), to: start.Add(16 * time.Hour)},
+		{from: start.Add(16 * time.Hour), to: end, cursor: mid},
+	}
+
+	c := encodeParallelCursor(parts)
+	if !isParallelCursor(c) || isParallelCursor(mid) {
+		t.Fatalf("isParallelCursor can't tell %q from %q", c, mid)
+	}
+	got, err := decodeParallelCursor(c, start, end)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(got) != len(parts) {
+		t.Fatalf("decoded %d parts, want %d", len(got), len(parts))
+	}
+	for i, p := range parts {
+		g := got[i]
+		if !g.from.Equal(p.from) || !g.to.Equal(p.to) || g.cursor != p.cursor || g.done != p.done {
+			t.Fatalf("part %d: got %+v, want %+v", i, *g, *p)
+		}
+	}
+}
+
+func TestParallelCursorRejects(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(24 * time.Hour)
+	enc := func(body string) string { return base64.RawURLEncoding.EncodeToString([]byte(body)) }
+	ms := func(d time.Duration) string { return strconv.FormatInt(start.Add(d).UnixMilli(), 10) }
+
+	cases := []struct {
+		name, cursor, want string
+	}{
+		{"serial", encodeSampleCursor(start, "u-1", 1), "serial export"},
+		{"not base64", "p|!!", "invalid cursor"},
+		{"before start", enc("p|" + ms(-time.Hour) + "~" + ms(time.Hour) + "~"), "requested range"},
+		{"past end", enc("p|" + ms(0) + "~" + ms(25*time.Hour) + "~-"), "requested range"},
+		{"empty partition", enc("p|" + ms(time.Hour) + "~" + ms(time.Hour) + "~"), "requested range"},
+		{"missing field", enc("p|" + ms(0) + "~" + ms(time.Hour)), "invalid cursor"},
+		{"bad sample cursor", enc("p|" + ms(0) + "~" + ms(time.Hour) + "~nope"), "invalid cursor"},
+	}
+	for _, tc := range cases {
+		_, err := decodeParallelCursor(tc.cursor, start, end)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Fatalf("%s: err %v, want %q", tc.name, err, tc.want)
+		}
+	}
+}
+
+// keysetCH answers sample pages from rows the way ClickHouse would: half-open window, the
+// (created_at, utterance_id, detver) DESC keyset and LIMIT. It is safe for concurrent use
+type keysetCH struct {
+	fakeCH
+	mu   sync.Mutex
+	rows []keysetRow // newest first
+}
+
+type keysetRow struct {
+	at  time.Time
+	uid string
+}
+
+func (f *keysetCH) Query(ctx context.Context, sql string, args ...any) (store.Rows, error) {
+	if strings.Contains(sql, "swearjar.utterances") {
+		return newRows([]string{"uid", "txt"}, nil), nil
+	}
+	from, to, limit := args[0].(time.Time), args[1].(time.Time), args[len(args)-1].(int)
+	after := func(keysetRow) bool { return true }
+	if len(args) == 8 { // window, cursor position (at, at, uid, uid, detver), limit
+		at, uid := args[2].(time.Time), args[4].(string)
+		after = func(r keysetRow) bool { return r.at.Before(at) || r.at.Equal(at) && r.uid < uid }
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var data [][]any
+	for _, r := range f.rows {
+		if r.at.Before(from) || !r.at.Before(to) || !after(r) || len(data) == limit {
+			continue
+		}
+		data = append(data, []any{
+			uint64(0), r.uid, r.at, "commit", "aa", "bb", int32(1),
+			[]string{"shit"}, []string{"profanity"}, []string{"mild"}, []int32{0}, []int32{4},
+		})
+	}
+	return newRows(make([]string, 12), data), nil
+}
+
+func TestExportParallelResumesWithoutGapsOrDuplicates(t *testing.T) {
+	t.Parallel()
+
+	// 40 utterances spread over the day, two sharing a timestamp and one on each partition boundary
+	start := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	var rows []keysetRow
+	for i := range 38 {
+		rows = append(rows, keysetRow{at: start.Add(time.Duration(i) * 37 * time.Minute), uid: "u-" + string(rune('A'+i))})
+	}
+	rows = append(rows,
+		keysetRow{at: start.Add(8 * time.Hour), uid: "u-boundary-1"},
+		keysetRow{at: start.Add(16 * time.Hour), uid: "u-boundary-2"},
+		keysetRow{at: rows[5].at, uid: "u-twin"},
+	)
+	slices.SortFunc(rows, func(a, b keysetRow) int {
+		if c := b.at.Compare(a.at); c != 0 {
+			return c
+		}
+		return strings.Compare(b.uid, a.uid)
+	})
+	s := newTestStore(&keysetCH{rows: rows})
+
+	in := domain.ExportHitsInput{
+		GlobalOptions: domain.GlobalOptions{Range: domain.TimeRange{Start: "2025-03-01", End: "2025-03-01"}},
+		Parallel:      true,
+	}
+	seen := map[string]int{}
+	var mu sync.Mutex
+	emit := func(r domain.ExportHitRecord) error {
+		mu.Lock()
+		defer mu.Unlock()
+		seen[r.UtteranceID]++
+		return nil
+	}
+
+	for round := 0; ; round++ {
+		if round > len(rows) {
+			t.Fatalf("export never finished; saw %d of %d", len(seen), len(rows))
+		}
+		before := len(seen)
+		next, err := s.ExportHits(context.Background(), in, 7, 3, emit)
+		if err != nil {
+			t.Fatalf("round %d: %v", round, err)
+		}
+		if n := len(seen) - before; n > 7 {
+			t.Fatalf("round %d emitted %d rows past the cap", round, n)
+		}
+		if next == "" {
+			break
+		}
+		if !isParallelCursor(next) {
+			t.Fatalf("round %d: cursor %q is not a parallel cursor", round, next)
+		}
+		in.Page.Cursor = next
+	}
+
+	for _, r := range rows {
+		if seen[r.uid] != 1 {
+			t.Fatalf("%s emitted %d times", r.uid, seen[r.uid])
+		}
+	}
+	if len(seen) != len(rows) {
+		t.Fatalf("emitted %d distinct rows, want %d", len(seen), len(rows))
+	}
+}
+
+func TestExportSerialRejectsParallelCursor(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	in := domain.ExportHitsInput{
+		GlobalOptions: domain.GlobalOptions{Range: domain.TimeRange{Start: "2025-03-01", End: "2025-03-01"}},
+	}
+	in.Page.Cursor = encodeParallelCursor(splitWindow(start, start.Add(24*time.Hour), 2))
+	s := newTestStore(&keysetCH{})
+	_, err := s.ExportHits(context.Background(), in, 10, 1, func(domain.ExportHitRecord) error { return nil })
+	if err == nil || !strings.Contains(err.Error(), "parallel export") {
+		t.Fatalf("err %v, want the parallel cursor rejected", err)
+	}
+}
@@ -0,0 +1,130 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"swearjar/internal/services/api/swearjar/domain"
+)
+
+// facetsTTL bounds how stale the filter dropdown values may be; new languages are rare,
+// so a short cache spares CH/PG a full scan on every dashboard load
+const facetsTTL = 10 * time.Minute
+
+// facetsCache holds the last Facets result, shared by all binds
+type facetsCache struct {
+	mu     sync.Mutex
+	ttl    time.Duration
+	loaded time.Time
+	val    domain.FacetsResp
+	now    func() time.Time
+}
+
+func newFacetsCache(ttl time.Duration) *facetsCache {
+	return &facetsCache{ttl: ttl, now: time.Now}
+}
+
+// Facets returns the distinct NL languages (commit_crimes) and code languages (repositories)
+// with counts, most common first. Excluded principals are left out of both lists
+func (s *hybridStore) Facets(ctx context.Context) (domain.FacetsResp, error) {
+	c := s.facets
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.loaded.IsZero() && c.now().Sub(c.loaded) < c.ttl {
+		return c.val, nil
+	}
+
+	ex, err := s.exclusions(ctx)
+	if err != nil {
+		return domain.FacetsResp{}, err
+	}
+
+	nl, err := s.nlLangFacets(ctx, ex)
+	if err != nil {
+		return domain.FacetsResp{}, err
+	}
+	code, err := s.codeLangFacets(ctx, ex)
+	if err != nil {
+		return domain.FacetsResp{}, err
+	}
+
+	c.val = domain.FacetsResp{
+		NLLangs:     nl,
+		CodeLangs:   code,
+		GeneratedAt: c.now().UTC().Format(time.RFC3339),
+	}
+	c.loaded = c.now()
+	return c.val, nil
+}
+
+// nlLangFacets counts hits per detected language; NULL/empty codes are not a selectable filter
+func (s *hybridStore) nlLangFacets(ctx context.Context, ex exclusions) ([]domain.FacetCount, error) {
+	where := []string{"lang_code IS NOT NULL", "lang_code != ''"}
+	args := []any{}
+	where, args = ex.applyCrimes(where, args)
+
+	rs, err := s.ch.Query(ctx, fmt.Sprintf(`
+		SELECT assumeNotNull(lang_code) AS v, count() AS n
+		FROM swearjar.commit_crimes
+		WHERE %s
+		GROUP BY v
+		ORDER BY n DESC, v ASC
+	`, strings.Join(where, " AND ")), args...)
+	if err != nil {
+		return nil, fmt.Errorf("nl lang facets: %w", err)
+	}
+	defer rs.Close()
+
+	out := make([]domain.FacetCount, 0, 64)
+	for rs.Next() {
+		var f domain.FacetCount
+		var n uint64
+		if err := rs.Scan(&f.Value, &n); err != nil {
+			return nil, fmt.Errorf("scan nl lang facet: %w", err)
+		}
+		f.Count = int64(n)
+		out = append(out, f)
+	}
+	if err := rs.Err(); err != nil {
+		return nil, fmt.Errorf("nl lang facets: %w", err)
+	}
+	return out, nil
+}
+
+// codeLangFacets counts cataloged repositories per primary language (PG)
+func (s *hybridStore) codeLangFacets(ctx context.Context, ex exclusions) ([]domain.FacetCount, error) {
+	sql := `
+		SELECT primary_lang, count(*)
+		FROM repositories
+		WHERE primary_lang IS NOT NULL AND primary_lang <> ''`
+	args := []any{}
+	if len(ex.repos) > 0 {
+		sql += ` AND repo_hid <> ALL($1)`
+		args = append(args, ex.repos)
+	}
+	sql += `
+		GROUP BY primary_lang
+		ORDER BY count(*) DESC, primary_lang ASC`
+
+	rs, err := s.pg.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("code lang facets: %w", err)
+	}
+	defer rs.Close()
+
+	out := make([]domain.FacetCount, 0, 64)
+	for rs.Next() {
+		var f domain.FacetCount
+		if err := rs.Scan(&f.Value, &f.Count); err != nil {
+			return nil, fmt.Errorf("scan code lang facet: %w", err)
+		}
+		out = append(out, f)
+	}
+	if err := rs.Err(); err != nil {
+		return nil, fmt.Errorf("code lang facets: %w", err)
+	}
+	return out, nil
+}
@@ -0,0 +1,58 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// freshnessTTL bounds how stale the processed-through mark may be; it moves at most once an
+// hour, and every recent-window request asks for it
+const freshnessTTL = time.Minute
+
+// freshnessCache holds the last ProcessedThrough result, shared by all binds
+type freshnessCache struct {
+	mu     sync.Mutex
+	ttl    time.Duration
+	loaded time.Time
+	mark   time.Time
+	ok     bool
+	now    func() time.Time
+}
+
+func newFreshnessCache(ttl time.Duration) *freshnessCache {
+	return &freshnessCache{ttl: ttl, now: time.Now}
+}
+
+// ProcessedThrough returns the exclusive UTC hour hits are complete up to. Incremental detect
+// owns that mark when it runs (detect_state, newest detector version); otherwise backfill
+// detects inline and the first hour it hasn't finished bounds it (hours finish out of order, so
+// the newest 'ok' hour would skip over failed or running ones). ok is false before anything is
+// processed
+func (s *hybridStore) ProcessedThrough(ctx context.Context) (time.Time, bool, error) {
+	c := s.fresh
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.loaded.IsZero() && c.now().Sub(c.loaded) < c.ttl {
+		return c.mark, c.ok, nil
+	}
+
+	var mark *time.Time
+	if err := s.pg.QueryRow(ctx, `
+		SELECT COALESCE(
+			(SELECT high_water_hour FROM detect_state ORDER BY detector_version DESC LIMIT 1),
+			(SELECT min(hour_utc) FROM ingest_hours WHERE bf_status <> 'ok'),
+			(SELECT max(hour_utc) + interval '1 hour' FROM ingest_hours)
+		)`,
+	).Scan(&mark); err != nil {
+		return time.Time{}, false, fmt.Errorf("processed through: %w", err)
+	}
+
+	c.mark, c.ok = time.Time{}, mark != nil
+	if c.ok {
+		c.mark = mark.UTC()
+	}
+	c.loaded = c.now()
+	return c.mark, c.ok, nil
+}
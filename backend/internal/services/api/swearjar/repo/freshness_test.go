@@ -0,0 +1,38 @@
+package repo
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProcessedThroughStopsAtFirstUnfinishedHour(t *testing.T) {
+	mark := time.Date(2025, 3, 1, 5, 0, 0, 0, time.UTC)
+	pg := &fakePG{fakeCH{results: []fakeResult{{match: "ingest_hours", cols: []string{"mark"}, data: [][]any{{&mark}}}}}}
+	s := newTestStore(nil)
+	s.pg = pg
+
+	got, ok, err := s.ProcessedThrough(context.Background())
+	if err != nil || !ok || !got.Equal(mark) {
+		t.Fatalf("got %v %v %v, want %v", got, ok, err, mark)
+	}
+	c, _ := pg.call("ingest_hours")
+	if !strings.Contains(c.sql, "min(hour_utc) FROM ingest_hours WHERE bf_status <> 'ok'") {
+		t.Fatalf("backfill mark must stop at the first hour that isn't ok:\n%s", c.sql)
+	}
+
+	if _, _, err := s.ProcessedThrough(context.Background()); err != nil || len(pg.calls) != 1 {
+		t.Fatalf("second read within the TTL: %d queries (%v), want the cached mark", len(pg.calls), err)
+	}
+}
+
+func TestProcessedThroughNothingProcessed(t *testing.T) {
+	pg := &fakePG{fakeCH{results: []fakeResult{{match: "ingest_hours", cols: []string{"mark"}, data: [][]any{{nil}}}}}}
+	s := newTestStore(nil)
+	s.pg = pg
+
+	if _, ok, err := s.ProcessedThrough(context.Background()); err != nil || ok {
+		t.Fatalf("got ok=%v err=%v, want not ok", ok, err)
+	}
+}
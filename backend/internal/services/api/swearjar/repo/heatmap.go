@@ -14,9 +14,9 @@ func (s *hybridStore) HeatmapWeekly(
 	ctx context.Context,
 	in domain.HeatmapWeeklyInput,
 ) (domain.HeatmapWeeklyResp, error) {
-	tz := strings.TrimSpace(in.TZ)
-	if tz == "" {
-		tz = "UTC"
+	loc, tz, err := loadTZ(in.TZ)
+	if err != nil {
+		return domain.HeatmapWeeklyResp{}, err
 	}
 	metric := strings.ToLower(strings.TrimSpace(in.Metric))
 	switch metric {
@@ -31,7 +31,8 @@ func (s *hybridStore) HeatmapWeekly(
 		series = "hits"
 	}
 
-	start, err := time.Parse("2006-01-02", in.Range.Start)
+	// Window handling (inclusive local dates -> [start, endExcl) instants)
+	startDay, err := time.Parse("2006-01-02", in.Range.Start)
 	if err != nil {
 		return domain.HeatmapWeeklyResp{}, err
 	}
@@ -39,23 +40,25 @@ func (s *hybridStore) HeatmapWeekly(
 	if err != nil {
 		return domain.HeatmapWeeklyResp{}, err
 	}
-	endExcl := endIncl.Add(24 * time.Hour)
+	start := time.Date(startDay.Year(), startDay.Month(), startDay.Day(), 0, 0, 0, 0, loc).UTC()
+	endExcl := time.Date(endIncl.Year(), endIncl.Month(), endIncl.Day()+1, 0, 0, 0, 0, loc).UTC()
 
 	// Numerator (crimes): from swearjar.commit_crimes
 	crWhere := []string{"created_at >= ? AND created_at < ?"}
 	crArgs := []any{start, endExcl}
 
-	if len(in.DetVer) > 0 {
+	if dv := s.detvers(in.DetVer); len(dv) > 0 {
 		crWhere = append(crWhere, "detver IN ?")
-		crArgs = append(crArgs, in.DetVer)
+		crArgs = append(crArgs, dv)
 	}
+	repoHIDs, actorHIDs := hidFilter(in.RepoHIDs), hidFilter(in.ActorHIDs)
 	if len(in.RepoHIDs) > 0 {
 		crWhere = append(crWhere, "repo_hid IN ?")
-		crArgs = append(crArgs, in.RepoHIDs)
+		crArgs = append(crArgs, repoHIDs)
 	}
 	if len(in.ActorHIDs) > 0 {
 		crWhere = append(crWhere, "actor_hid IN ?")
-		crArgs = append(crArgs, in.ActorHIDs)
+		crArgs = append(crArgs, actorHIDs)
 	}
 	if len(in.NLLangs) > 0 {
 		crWhere = append(crWhere, "lang_code IN ?")
@@ -75,13 +78,15 @@ func (s *hybridStore) HeatmapWeekly(
 	utArgs := []any{start, endExcl}
 
 	// Mirror feasible filters onto utt_hour_agg (same slice)
+	// It is bucketed by UTC hour; zones with sub-hour offsets land each bucket on the hour it starts in.
+	// detver does not apply to the denominator (every utterance is a candidate for every detver)
 	if len(in.RepoHIDs) > 0 {
 		utWhere = append(utWhere, "repo_hid IN ?")
-		utArgs = append(utArgs, in.RepoHIDs)
+		utArgs = append(utArgs, repoHIDs)
 	}
 	if len(in.ActorHIDs) > 0 {
 		utWhere = append(utWhere, "actor_hid IN ?")
-		utArgs = append(utArgs, in.ActorHIDs)
+		utArgs = append(utArgs, actorHIDs)
 	}
 	if len(in.NLLangs) > 0 {
 		utWhere = append(utWhere, "lang_code IN ?")
@@ -96,6 +101,13 @@ func (s *hybridStore) HeatmapWeekly(
 	}
 	// NOTE: utt_hour_agg has no code_lang; skip CodeLangs here too
 
+	ex, err := s.exclusionsFor(ctx, in.GlobalOptions)
+	if err != nil {
+		return domain.HeatmapWeeklyResp{}, err
+	}
+	crWhere, crArgs = ex.applyCrimes(crWhere, crArgs)
+	utWhere, utArgs = ex.apply(utWhere, utArgs)
+
 	sql := fmt.Sprintf(`
 		WITH
 		crimes AS (
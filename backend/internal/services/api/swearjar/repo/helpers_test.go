@@ -0,0 +1,168 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"time"
+
+	"swearjar/internal/core/rulepack"
+	"swearjar/internal/platform/store"
+)
+
+// fakeResult answers every query whose SQL contains match, in the order results are listed
+type fakeResult struct {
+	match string
+	cols  []string
+	data  [][]any
+	err   error // returned by Query
+}
+
+type fakeCall struct {
+	sql  string
+	args []any
+}
+
+// fakeCH is a store.Clickhouse that serves canned rows, mirroring store's fakeRowQuerier
+type fakeCH struct {
+	results []fakeResult
+	calls   []fakeCall
+}
+
+func (f *fakeCH) Query(ctx context.Context, sql string, args ...any) (store.Rows, error) {
+	f.calls = append(f.calls, fakeCall{sql: sql, args: args})
+	for _, r := range f.results {
+		if strings.Contains(sql, r.match) {
+			if r.err != nil {
+				return nil, r.err
+			}
+			return newRows(r.cols, r.data), nil
+		}
+	}
+	return nil, errors.New("fakeCH: unexpected query: " + sql)
+}
+
+// call returns the first recorded query containing match
+func (f *fakeCH) call(match string) (fakeCall, bool) {
+	for _, c := range f.calls {
+		if strings.Contains(c.sql, match) {
+			return c, true
+		}
+	}
+	return fakeCall{}, false
+}
+
+func (f *fakeCH) Insert(ctx context.Context, table string, data any) error {
+	return errors.New("fakeCH: insert not supported")
+}
+
+func (f *fakeCH) Exec(ctx context.Context, sql string, args ...any) error {
+	return errors.New("fakeCH: exec not supported")
+}
+
+func (f *fakeCH) ScalarUInt64(ctx context.Context, sql string, args ...any) (uint64, error) {
+	return 0, errors.New("fakeCH: scalar not supported")
+}
+
+func (f *fakeCH) ScalarInt64(ctx context.Context, sql string, args ...any) (int64, error) {
+	return 0, errors.New("fakeCH: scalar not supported")
+}
+
+func (f *fakeCH) Close() error { return nil }
+
+// fakePG is the Postgres side of a hybridStore, answering Query like fakeCH
+type fakePG struct{ fakeCH }
+
+func (f *fakePG) Exec(ctx context.Context, sql string, args ...any) (store.CommandTag, error) {
+	return nil, errors.New("fakePG: exec not supported")
+}
+
+func (f *fakePG) QueryRow(ctx context.Context, sql string, args ...any) store.Row {
+	rs, err := f.Query(ctx, sql, args...)
+	if err != nil {
+		return errRow{err}
+	}
+	rs.Next()
+	return rs
+}
+
+type errRow struct{ err error }
+
+func (r errRow) Scan(dest ...any) error { return r.err }
+
+type fakeRows struct {
+	cols   []string
+	data   [][]any // each row is len(cols)
+	idx    int     // -1 before first
+	err    error
+	closed bool
+}
+
+func newRows(cols []string, data [][]any) *fakeRows {
+	return &fakeRows{cols: cols, data: data, idx: -1}
+}
+func (r *fakeRows) Columns() []string { return r.cols }
+func (r *fakeRows) Next() bool {
+	if r.err != nil {
+		return false
+	}
+	r.idx++
+	return r.idx >= 0 && r.idx < len(r.data)
+}
+
+// Scan assigns each value to its dest. Like the CH driver it doesn't convert: the canned value
+// must be assignable to the dest, so tests catch a Go type that doesn't match the column's
+func (r *fakeRows) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+	if r.idx < 0 || r.idx >= len(r.data) {
+		return errors.New("scan out of bounds")
+	}
+	row := r.data[r.idx]
+	if len(dest) != len(row) {
+		return errors.New("dest len mismatch")
+	}
+	for i := range dest {
+		dv := reflect.ValueOf(dest[i])
+		if dv.Kind() != reflect.Pointer || !dv.Elem().CanSet() {
+			return errors.New("dest not settable")
+		}
+		val := reflect.ValueOf(row[i])
+		switch {
+		case !val.IsValid():
+			dv.Elem().Set(reflect.Zero(dv.Elem().Type()))
+		case val.Type().AssignableTo(dv.Elem().Type()):
+			dv.Elem().Set(val)
+		default:
+			return errors.New("cannot scan " + val.Type().String() + " into " + dv.Elem().Type().String())
+		}
+	}
+	return nil
+}
+func (r *fakeRows) Err() error { return r.err }
+func (r *fakeRows) Close()     { r.closed = true }
+
+// newTestStore binds a hybridStore to ch with an empty exclusion list already loaded,
+// so queries never reach Postgres
+func newTestStore(ch store.Clickhouse) *hybridStore {
+	excl := newExclusionCache(time.Hour)
+	excl.loaded = time.Now()
+	return &hybridStore{
+		ch:      ch,
+		weights: rulepack.DefaultSeverityWeights(),
+		caps:    DefaultRowCaps(),
+		excl:    excl,
+		facets:  newFacetsCache(facetsTTL),
+		fresh:   newFreshnessCache(freshnessTTL),
+	}
+}
+
+func day(s string) time.Time {
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
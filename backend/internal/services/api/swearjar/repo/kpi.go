@@ -2,15 +2,24 @@ package repo
 
 import (
 	"context"
+	"strings"
 	"time"
 
+	perr "swearjar/internal/platform/errors"
 	"swearjar/internal/services/api/swearjar/domain"
 )
 
 // KPIStrip computes headline KPIs for the requested window (usually a single day)
+// Range dates are calendar days in GlobalOptions.TZ (default UTC), so "today" near
+// midnight resolves to the caller's day rather than the UTC one
 func (s *hybridStore) KPIStrip(ctx context.Context, in domain.KPIStripInput) (domain.KPIStripResp, error) {
-	// Window handling (inclusive dates in UTC)
-	start, err := time.Parse("2006-01-02", in.Range.Start)
+	loc, tz, err := loadTZ(in.TZ)
+	if err != nil {
+		return domain.KPIStripResp{}, err
+	}
+
+	// Window handling (inclusive local dates -> [start, endExcl) instants)
+	startDay, err := time.Parse("2006-01-02", in.Range.Start)
 	if err != nil {
 		return domain.KPIStripResp{}, err
 	}
@@ -18,7 +27,71 @@ func (s *hybridStore) KPIStrip(ctx context.Context, in domain.KPIStripInput) (do
 	if err != nil {
 		return domain.KPIStripResp{}, err
 	}
-	endExcl := endIncl.Add(24 * time.Hour)
+	start := time.Date(startDay.Year(), startDay.Month(), startDay.Day(), 0, 0, 0, 0, loc).UTC()
+	endExcl := time.Date(endIncl.Year(), endIncl.Month(), endIncl.Day()+1, 0, 0, 0, 0, loc).UTC()
+
+	// Numerator (crimes): from swearjar.commit_crimes, honoring scope filters
+	crWhere := []string{"created_at >= ? AND created_at < ?"}
+	crArgs := []any{start, endExcl}
+
+	if dv := s.detvers(in.DetVer); len(dv) > 0 {
+		crWhere = append(crWhere, "detver IN ?")
+		crArgs = append(crArgs, dv)
+	}
+	repoHIDs, actorHIDs := hidFilter(in.RepoHIDs), hidFilter(in.ActorHIDs)
+	if len(in.RepoHIDs) > 0 {
+		crWhere = append(crWhere, "repo_hid IN ?")
+		crArgs = append(crArgs, repoHIDs)
+	}
+	if len(in.ActorHIDs) > 0 {
+		crWhere = append(crWhere, "actor_hid IN ?")
+		crArgs = append(crArgs, actorHIDs)
+	}
+	if len(in.NLLangs) > 0 {
+		crWhere = append(crWhere, "lang_code IN ?")
+		crArgs = append(crArgs, in.NLLangs)
+	}
+	if in.LangReliable != nil {
+		if *in.LangReliable {
+			crWhere = append(crWhere, "lang_reliable = 1")
+		} else {
+			crWhere = append(crWhere, "lang_reliable = 0")
+		}
+	}
+
+	// Denominator (all utterances): from swearjar.utt_hour_agg
+	// It is bucketed by UTC hour; zones with sub-hour offsets get the buckets that start inside the window.
+	// detver does not apply to the denominator (every utterance is a candidate for every detver)
+	utWhere := []string{"bucket_hour >= ? AND bucket_hour < ?"}
+	utArgs := []any{start, endExcl}
+
+	if len(in.RepoHIDs) > 0 {
+		utWhere = append(utWhere, "repo_hid IN ?")
+		utArgs = append(utArgs, repoHIDs)
+	}
+	if len(in.ActorHIDs) > 0 {
+		utWhere = append(utWhere, "actor_hid IN ?")
+		utArgs = append(utArgs, actorHIDs)
+	}
+	if len(in.NLLangs) > 0 {
+		utWhere = append(utWhere, "lang_code IN ?")
+		utArgs = append(utArgs, in.NLLangs)
+	}
+	if in.LangReliable != nil {
+		if *in.LangReliable {
+			utWhere = append(utWhere, "lang_reliable = 1")
+		} else {
+			utWhere = append(utWhere, "lang_reliable = 0")
+		}
+	}
+	// NOTE: neither table carries code_lang; CodeLangs is ignored here
+
+	ex, err := s.exclusionsFor(ctx, in.GlobalOptions)
+	if err != nil {
+		return domain.KPIStripResp{}, err
+	}
+	crWhere, crArgs = ex.applyCrimes(crWhere, crArgs)
+	utWhere, utArgs = ex.apply(utWhere, utArgs)
 
 	sql := `
 		WITH crimes AS (
@@ -28,16 +101,16 @@ func (s *hybridStore) KPIStrip(ctx context.Context, in domain.KPIStripInput) (do
 				uniqCombined(12)(repo_hid)                AS repos,
 				uniqCombined(12)(actor_hid)               AS actors
 			FROM swearjar.commit_crimes
-			WHERE created_at >= ? AND created_at < ?
+			WHERE ` + strings.Join(crWhere, " AND ") + `
 		),
 		utts AS (
 			SELECT
 				countMerge(cnt_state)                      AS all_utt
 			FROM swearjar.utt_hour_agg
-			WHERE bucket_hour >= ? AND bucket_hour < ?
+			WHERE ` + strings.Join(utWhere, " AND ") + `
 		)
 		SELECT
-			?                                           AS day,
+			formatDateTime(toTimeZone(toDateTime(?, 'UTC'), ?), '%Y-%m-%d') AS day,
 			c.hits                                      AS hits,
 			c.off_utt                                   AS off_utt,
 			c.repos                                     AS repos,
@@ -46,11 +119,8 @@ func (s *hybridStore) KPIStrip(ctx context.Context, in domain.KPIStripInput) (do
 		FROM crimes c
 		CROSS JOIN utts u
 	`
-	rs, err := s.ch.Query(ctx, sql,
-		start, endExcl, // crimes range
-		start, endExcl, // utt range
-		start.Format("2006-01-02"), // label day in response
-	)
+	args := append(append(crArgs, utArgs...), start, tz) // label day in response (window start in the caller's TZ)
+	rs, err := s.ch.Query(ctx, sql, args...)
 	if err != nil {
 		return domain.KPIStripResp{}, err
 	}
@@ -72,6 +142,9 @@ func (s *hybridStore) KPIStrip(ctx context.Context, in domain.KPIStripInput) (do
 	if err := rs.Err(); err != nil {
 		return domain.KPIStripResp{}, err
 	}
+	if day == "" {
+		day = start.In(loc).Format("2006-01-02")
+	}
 
 	resp := domain.KPIStripResp{
 		Day:                 day,
@@ -93,3 +166,16 @@ func (s *hybridStore) KPIStrip(ctx context.Context, in domain.KPIStripInput) (do
 
 	return resp, nil
 }
+
+// loadTZ resolves an IANA zone name (empty = UTC)
+func loadTZ(name string) (*time.Location, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return time.UTC, "UTC", nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, "", perr.WithField(perr.InvalidArgf("unknown time zone %q", name), "tz")
+	}
+	return loc, loc.String(), nil
+}
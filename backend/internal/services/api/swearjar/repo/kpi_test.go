@@ -0,0 +1,180 @@
+package repo
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"swearjar/internal/services/api/swearjar/domain"
+)
+
+const kpiMatch = "CROSS JOIN utts"
+
+func kpiInput(tz, start, end string) domain.KPIStripInput {
+	return domain.KPIStripInput{GlobalOptions: domain.GlobalOptions{
+		Range: domain.TimeRange{Start: start, End: end},
+		TZ:    tz,
+	}}
+}
+
+func TestKPIStripWindowInCallerTZ(t *testing.T) {
+	t.Parallel()
+
+	utc := func(s string) time.Time {
+		ts, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			panic(err)
+		}
+		return ts
+	}
+	cases := []struct {
+		name, tz, start, end string
+		from, to             time.Time
+		wantTZ               string
+	}{
+		{"default UTC, multi-day", "", "2024-01-01", "2024-01-03",
+			utc("2024-01-01T00:00:00Z"), utc("2024-01-04T00:00:00Z"), "UTC"},
+		{"los angeles day", "America/Los_Angeles", "2024-03-15", "2024-03-15",
+			utc("2024-03-15T07:00:00Z"), utc("2024-03-16T07:00:00Z"), "America/Los_Angeles"},
+		// 23h: clocks spring forward at 02:00 PST
+		{"spring forward", "America/Los_Angeles", "2024-03-10", "2024-03-10",
+			utc("2024-03-10T08:00:00Z"), utc("2024-03-11T07:00:00Z"), "America/Los_Angeles"},
+		// 25h: clocks fall back at 02:00 PDT
+		{"fall back", " America/Los_Angeles ", "2024-11-03", "2024-11-03",
+			utc("2024-11-03T07:00:00Z"), utc("2024-11-04T08:00:00Z"), "America/Los_Angeles"},
+		{"half-hour offset", "Asia/Kolkata", "2024-01-01", "2024-01-01",
+			utc("2023-12-31T18:30:00Z"), utc("2024-01-01T18:30:00Z"), "Asia/Kolkata"},
+	}
+	for _, tc := range cases {
+		ch := &fakeCH{results: []fakeResult{{match: kpiMatch}}} // no row: Day falls back to Go
+		s := newTestStore(ch)
+
+		resp, err := s.KPIStrip(context.Background(), kpiInput(tc.tz, tc.start, tc.end))
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if resp.Day != tc.start {
+			t.Fatalf("%s: day %q, want the local start date %q", tc.name, resp.Day, tc.start)
+		}
+		c, _ := ch.call(kpiMatch)
+		// crimes window, utts window, then the window start and zone CH labels the day with
+		want := []any{tc.from, tc.to, tc.from, tc.to, tc.from, tc.wantTZ}
+		if !reflect.DeepEqual(c.args, want) {
+			t.Fatalf("%s: args\n got %v\nwant %v", tc.name, c.args, want)
+		}
+	}
+}
+
+func TestKPIStripRejectsUnknownZone(t *testing.T) {
+	t.Parallel()
+
+	ch := &fakeCH{results: []fakeResult{{match: kpiMatch}}}
+	s := newTestStore(ch)
+	_, err := s.KPIStrip(context.Background(), kpiInput("Mars/Olympus_Mons", "2024-01-01", "2024-01-01"))
+	if err == nil || !strings.Contains(err.Error(), "unknown time zone") {
+		t.Fatalf("err %v, want an unknown time zone error", err)
+	}
+	if len(ch.calls) != 0 {
+		t.Fatalf("queried with an invalid zone: %v", ch.calls)
+	}
+}
+
+// kpiWheres splits the KPI query into its crimes and utts WHERE clauses
+func kpiWheres(sql string) (crimes, utts string) {
+	where := func(s string) string {
+		_, after, _ := strings.Cut(s, "WHERE ")
+		clause, _, _ := strings.Cut(after, "\n")
+		return strings.TrimSpace(clause)
+	}
+	cr, ut, _ := strings.Cut(sql, "utts AS (")
+	return where(cr), where(ut)
+}
+
+func TestKPIStripScopeFilters(t *testing.T) {
+	t.Parallel()
+
+	ch := &fakeCH{results: []fakeResult{{match: kpiMatch}}}
+	pg := &fakePG{fakeCH{results: []fakeResult{
+		{match: "analytics_exclusions", cols: []string{"principal", "principal_hid"},
+			data: [][]any{{"repo", []byte{0xee}}, {"actor", []byte{0xff}}}},
+	}}}
+	s := newTestStore(ch)
+	s.excl = newExclusionCache(time.Hour)
+	s.pg = pg
+	s.detver = 3
+
+	reliable := false
+	in := kpiInput("", "2024-01-01", "2024-01-01")
+	in.RepoHIDs = []string{"aa"}
+	in.ActorHIDs = []string{"bb"}
+	in.NLLangs = []string{"en", "de"}
+	in.LangReliable = &reliable
+	in.ExcludeBotTargets = true
+	if _, err := s.KPIStrip(context.Background(), in); err != nil {
+		t.Fatalf("KPIStrip: %v", err)
+	}
+
+	c, _ := ch.call(kpiMatch)
+	crimes, utts := kpiWheres(c.sql)
+	wantCrimes := "created_at >= ? AND created_at < ? AND detver IN ? AND repo_hid IN ? AND actor_hid IN ?" +
+		" AND lang_code IN ? AND lang_reliable = 0" +
+		" AND repo_hid NOT IN ? AND actor_hid NOT IN ? AND target_type != 'bot'"
+	// detver and hit targets only narrow the numerator: every utterance is a candidate
+	wantUtts := "bucket_hour >= ? AND bucket_hour < ? AND repo_hid IN ? AND actor_hid IN ?" +
+		" AND lang_code IN ? AND lang_reliable = 0" +
+		" AND repo_hid NOT IN ? AND actor_hid NOT IN ?"
+	if crimes != wantCrimes {
+		t.Fatalf("crimes WHERE\n got %s\nwant %s", crimes, wantCrimes)
+	}
+	if utts != wantUtts {
+		t.Fatalf("utts WHERE\n got %s\nwant %s", utts, wantUtts)
+	}
+
+	from, to := day("2024-01-01"), day("2024-01-02")
+	repos, actors, langs := [][]byte{{0xaa}}, [][]byte{{0xbb}}, []string{"en", "de"}
+	exRepos, exActors := [][]byte{{0xee}}, [][]byte{{0xff}}
+	want := []any{
+		from, to, []int{3}, repos, actors, langs, exRepos, exActors, // crimes
+		from, to, repos, actors, langs, exRepos, exActors, // utts
+		from, "UTC", // day label
+	}
+	if !reflect.DeepEqual(c.args, want) {
+		t.Fatalf("args\n got %v\nwant %v", c.args, want)
+	}
+}
+
+func TestKPIStripDerivedRatios(t *testing.T) {
+	t.Parallel()
+
+	cols := []string{"day", "hits", "off_utt", "repos", "actors", "all_utt"}
+	cases := []struct {
+		name string
+		row  []any
+		want domain.KPIStripResp
+	}{
+		{"all ratios", []any{"2024-01-01", uint64(12), uint64(8), uint64(3), uint64(5), uint64(100)},
+			domain.KPIStripResp{
+				Day: "2024-01-01", Hits: 12, OffendingUtterances: 8, Repos: 3, Actors: 5, AllUtterances: 100,
+				Intensity: 12.0 / 8, Coverage: 8.0 / 100, Rarity: 12.0 / 100,
+			}},
+		// the hourly rollup can lag the crimes table: no denominator, no coverage/rarity
+		{"no utterances", []any{"2024-01-01", uint64(4), uint64(2), uint64(1), uint64(1), uint64(0)},
+			domain.KPIStripResp{
+				Day: "2024-01-01", Hits: 4, OffendingUtterances: 2, Repos: 1, Actors: 1, Intensity: 2,
+			}},
+		{"no hits", []any{"2024-01-01", uint64(0), uint64(0), uint64(0), uint64(0), uint64(50)},
+			domain.KPIStripResp{Day: "2024-01-01", AllUtterances: 50}},
+	}
+	for _, tc := range cases {
+		ch := &fakeCH{results: []fakeResult{{match: kpiMatch, cols: cols, data: [][]any{tc.row}}}}
+		resp, err := newTestStore(ch).KPIStrip(context.Background(), kpiInput("", "2024-01-01", "2024-01-01"))
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if resp != tc.want {
+			t.Fatalf("%s:\n got %+v\nwant %+v", tc.name, resp, tc.want)
+		}
+	}
+}
@@ -0,0 +1,373 @@
+package repo
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	perr "swearjar/internal/platform/errors"
+	"swearjar/internal/services/api/swearjar/domain"
+)
+
+const (
+	leaderboardDefaultLimit = 50
+	leaderboardMaxLimit     = 200
+
+	// knownCohortMax bounds the cataloged principals an IncludeZero "known" cohort loads from
+	// Postgres and ships to ClickHouse as an IN list; larger catalogs should rank the seen cohort
+	knownCohortMax = 20000
+)
+
+// leaderRow is one ranked principal; utt is 0 when the cohort has no utterance counts
+type leaderRow struct {
+	hid  string
+	hits uint64
+	utt  uint64
+}
+
+// ActorsLeaderboard ranks actors in the window (see leaderboard)
+func (s *hybridStore) ActorsLeaderboard(
+	ctx context.Context,
+	in domain.ActorsLeaderboardInput,
+) (domain.ActorsLeaderboardResp, error) {
+	rows, next, err := s.leaderboard(ctx, "actor", in.GlobalOptions, in.LeaderboardOptions)
+	if err != nil {
+		return domain.ActorsLeaderboardResp{}, err
+	}
+	labels, err := s.leaderLabels(ctx, "actor", rows)
+	if err != nil {
+		return domain.ActorsLeaderboardResp{}, err
+	}
+	out := domain.ActorsLeaderboardResp{Items: make([]domain.ActorsLeaderboardRow, 0, len(rows)), NextPage: next}
+	for i, r := range rows {
+		out.Items = append(out.Items, domain.ActorsLeaderboardRow{
+			ActorHID: r.hid, Label: labels[i], Hits: int64(r.hits), Ratio: r.ratio(),
+		})
+	}
+	return out, nil
+}
+
+// ReposLeaderboard ranks repositories in the window (see leaderboard)
+func (s *hybridStore) ReposLeaderboard(
+	ctx context.Context,
+	in domain.ReposLeaderboardInput,
+) (domain.ReposLeaderboardResp, error) {
+	rows, next, err := s.leaderboard(ctx, "repo", in.GlobalOptions, in.LeaderboardOptions)
+	if err != nil {
+		return domain.ReposLeaderboardResp{}, err
+	}
+	labels, err := s.leaderLabels(ctx, "repo", rows)
+	if err != nil {
+		return domain.ReposLeaderboardResp{}, err
+	}
+	out := domain.ReposLeaderboardResp{Items: make([]domain.ReposLeaderboardRow, 0, len(rows)), NextPage: next}
+	for i, r := range rows {
+		out.Items = append(out.Items, domain.ReposLeaderboardRow{
+			RepoHID: r.hid, Label: labels[i], Hits: int64(r.hits), Ratio: r.ratio(),
+		})
+	}
+	return out, nil
+}
+
+func (r leaderRow) ratio() float64 {
+	if r.utt == 0 {
+		return 0
+	}
+	return float64(r.hits) / float64(r.utt)
+}
+
+// leaderLabels labels each row with its opted-in name when there is one, else the shortened
+// HID, looking the page's names up in one round trip
+func (s *hybridStore) leaderLabels(ctx context.Context, principal string, rows []leaderRow) ([]string, error) {
+	hids := make([]string, len(rows))
+	for i, r := range rows {
+		hids[i] = r.hid
+	}
+	names, err := s.optInNames(ctx, principal, hids)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]string, len(rows))
+	for i, r := range rows {
+		out[i] = hidLabel(r.hid)
+		if name, ok := names[r.hid]; ok {
+			out[i] = name
+		}
+	}
+	return out, nil
+}
+
+// leaderboard returns one page of principals plus the next page cursor ("" on the last page)
+// The default is a group-by over commit_crimes, most hits first. IncludeZero joins a cohort
+// against those counts instead so principals without hits rank too, fewest hits first
+func (s *hybridStore) leaderboard(
+	ctx context.Context,
+	principal string,
+	g domain.GlobalOptions,
+	o domain.LeaderboardOptions,
+) ([]leaderRow, string, error) {
+	col := "repo_hid"
+	if principal == "actor" {
+		col = "actor_hid"
+	}
+	limit := g.Page.Limit
+	if limit <= 0 {
+		limit = leaderboardDefaultLimit
+	}
+	limit = min(limit, leaderboardMaxLimit)
+	offset, err := decodeOffsetCursor(g.Page.Cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	start, err := time.Parse("2006-01-02", g.Range.Start)
+	if err != nil {
+		return nil, "", err
+	}
+	endIncl, err := time.Parse("2006-01-02", g.Range.End)
+	if err != nil {
+		return nil, "", err
+	}
+	endExcl := endIncl.Add(24 * time.Hour)
+
+	ex, err := s.exclusionsFor(ctx, g)
+	if err != nil {
+		return nil, "", err
+	}
+	crWhere, crArgs := leaderScope("created_at", g, start, endExcl)
+	if dv := s.detvers(g.DetVer); len(dv) > 0 {
+		crWhere = append(crWhere, "detver IN ?")
+		crArgs = append(crArgs, dv)
+	}
+	crWhere, crArgs = ex.applyCrimes(crWhere, crArgs)
+
+	var rows []leaderRow
+	switch {
+	case !o.IncludeZero:
+		rows, err = s.queryLeaders(ctx, fmt.Sprintf(`
+			SELECT lower(hex(%[1]s)) AS hid, count() AS hits, toUInt64(0) AS utt
+			FROM swearjar.commit_crimes
+			WHERE %[2]s
+			GROUP BY %[1]s
+			ORDER BY hits DESC, hid ASC
+			LIMIT ? OFFSET ?
+		`, col, strings.Join(crWhere, " AND ")), append(crArgs, limit+1, offset)...)
+
+	case o.Cohort == "known":
+		rows, err = s.knownLeaders(ctx, principal, col, g, ex, crWhere, crArgs)
+		if err == nil {
+			rows = rows[min(offset, len(rows)):]
+			rows = rows[:min(limit+1, len(rows))]
+		}
+
+	default: // seen: principals with utterances in the window, hits joined on
+		utWhere, utArgs := leaderScope("bucket_hour", g, start, endExcl)
+		utWhere, utArgs = ex.apply(utWhere, utArgs)
+		args := append(append(utArgs, crArgs...), limit+1, offset)
+		rows, err = s.queryLeaders(ctx, fmt.Sprintf(`
+			SELECT lower(hex(u.hid)) AS hid, ifNull(c.hits, 0) AS hits, u.utt AS utt
+			FROM (
+				SELECT %[1]s AS hid, countMerge(cnt_state) AS utt
+				FROM swearjar.utt_hour_agg
+				WHERE %[2]s
+				GROUP BY hid
+			) AS u
+			LEFT JOIN (
+				SELECT %[1]s AS hid, count() AS hits
+				FROM swearjar.commit_crimes
+				WHERE %[3]s
+				GROUP BY hid
+			) AS c ON c.hid = u.hid
+			ORDER BY hits ASC, utt DESC, hid ASC
+			LIMIT ? OFFSET ?
+		`, col, strings.Join(utWhere, " AND "), strings.Join(crWhere, " AND ")), args...)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	next := ""
+	if len(rows) > limit {
+		rows = rows[:limit]
+		next = encodeOffsetCursor(offset + limit)
+	}
+	return rows, next, nil
+}
+
+// knownLeaders ranks every cataloged principal (repositories/actors) by hits, zeros included
+// The catalog lives in Postgres, so its HIDs go to ClickHouse as an IN list and the zero fill,
+// ordering and paging happen here. A repo/actor scope filters the catalog query itself, so the
+// knownCohortMax cap applies to the scoped cohort rather than cutting it
+func (s *hybridStore) knownLeaders(
+	ctx context.Context,
+	principal, col string,
+	g domain.GlobalOptions,
+	ex exclusions,
+	crWhere []string,
+	crArgs []any,
+) ([]leaderRow, error) {
+	table, scoped := "repositories", g.RepoHIDs
+	if principal == "actor" {
+		table, scoped = "actors", g.ActorHIDs
+	}
+	scope, err := decodeHIDs(scoped)
+	if err != nil {
+		return nil, perr.InvalidArgf("invalid %s hid: %v", principal, err)
+	}
+	where, args := "", []any{knownCohortMax + 1}
+	if len(scope) > 0 {
+		where = " WHERE " + col + " = ANY($2)"
+		args = append(args, scope)
+	}
+
+	rs, err := s.pg.Query(ctx, fmt.Sprintf(`SELECT %[1]s FROM %[2]s%[3]s ORDER BY %[1]s LIMIT $1`, col, table, where),
+		args...)
+	if err != nil {
+		return nil, fmt.Errorf("load known %s cohort: %w", principal, err)
+	}
+	defer rs.Close()
+	var hids [][]byte
+	byHex := map[string]*leaderRow{}
+	for rs.Next() {
+		var hid []byte
+		if err := rs.Scan(&hid); err != nil {
+			return nil, fmt.Errorf("scan known %s: %w", principal, err)
+		}
+		h := hex.EncodeToString(hid)
+		if ex.drops(principal, hid) {
+			continue
+		}
+		hids = append(hids, hid)
+		byHex[h] = &leaderRow{hid: h}
+	}
+	if err := rs.Err(); err != nil {
+		return nil, fmt.Errorf("load known %s cohort: %w", principal, err)
+	}
+	if len(hids) > knownCohortMax {
+		return nil, perr.InvalidArgf("known %s cohort exceeds %d principals; rank the seen cohort instead",
+			principal, knownCohortMax)
+	}
+	if len(hids) == 0 {
+		return nil, nil
+	}
+
+	counts, err := s.queryLeaders(ctx, fmt.Sprintf(`
+		SELECT lower(hex(%[1]s)) AS hid, count() AS hits, toUInt64(0) AS utt
+		FROM swearjar.commit_crimes
+		WHERE %[2]s AND %[1]s IN ?
+		GROUP BY %[1]s
+	`, col, strings.Join(crWhere, " AND ")), append(crArgs, hids)...)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range counts {
+		if r := byHex[c.hid]; r != nil {
+			r.hits = c.hits
+		}
+	}
+
+	out := make([]leaderRow, 0, len(byHex))
+	for _, r := range byHex {
+		out = append(out, *r)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].hits != out[j].hits {
+			return out[i].hits < out[j].hits
+		}
+		return out[i].hid < out[j].hid
+	})
+	return out, nil
+}
+
+// leaderScope is the window plus the scope filters commit_crimes and utt_hour_agg share
+func leaderScope(tcol string, g domain.GlobalOptions, start, endExcl time.Time) ([]string, []any) {
+	where := []string{tcol + " >= ? AND " + tcol + " < ?"}
+	args := []any{start, endExcl}
+	if len(g.RepoHIDs) > 0 {
+		where = append(where, "repo_hid IN ?")
+		args = append(args, hidFilter(g.RepoHIDs))
+	}
+	if len(g.ActorHIDs) > 0 {
+		where = append(where, "actor_hid IN ?")
+		args = append(args, hidFilter(g.ActorHIDs))
+	}
+	if len(g.NLLangs) > 0 {
+		where = append(where, "lang_code IN ?")
+		args = append(args, g.NLLangs)
+	}
+	if g.LangReliable != nil {
+		if *g.LangReliable {
+			where = append(where, "lang_reliable = 1")
+		} else {
+			where = append(where, "lang_reliable = 0")
+		}
+	}
+	return where, args
+}
+
+// queryLeaders scans (hid, hits, utt) rows
+func (s *hybridStore) queryLeaders(ctx context.Context, sql string, args ...any) ([]leaderRow, error) {
+	rs, err := s.ch.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rs.Close()
+	var out []leaderRow
+	for rs.Next() {
+		var r leaderRow
+		if err := rs.Scan(&r.hid, &r.hits, &r.utt); err != nil {
+			return nil, err
+		}
+		out = append(out, r)
+	}
+	return out, rs.Err()
+}
+
+// drops reports whether a principal is excluded from analytics (bots only when requested)
+func (e exclusions) drops(principal string, hid []byte) bool {
+	list := e.repos
+	if principal == "actor" {
+		list = e.actors
+		if e.dropBotActors && containsHID(e.bots, hid) {
+			return true
+		}
+	}
+	return containsHID(list, hid)
+}
+
+func containsHID(xs [][]byte, hid []byte) bool {
+	for _, x := range xs {
+		if bytes.Equal(x, hid) {
+			return true
+		}
+	}
+	return false
+}
+
+// offset cursors are opaque base64url(`{"offset":N}`)
+func encodeOffsetCursor(offset int) string {
+	b, _ := json.Marshal(struct {
+		Offset int `json:"offset"`
+	}{offset})
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeOffsetCursor(c string) (int, error) {
+	if c == "" {
+		return 0, nil
+	}
+	var v struct {
+		Offset int `json:"offset"`
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(c)
+	if err != nil || json.Unmarshal(raw, &v) != nil || v.Offset < 0 {
+		return 0, perr.InvalidArgf("invalid cursor")
+	}
+	return v.Offset, nil
+}
@@ -0,0 +1,204 @@
+package repo
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"swearjar/internal/services/api/swearjar/domain"
+)
+
+const (
+	hidA = "aaaaaaaa01"
+	hidB = "bbbbbbbb02"
+	hidC = "cccccccc03"
+)
+
+// noOptIns answers every opt-in name lookup with no rows
+func noOptIns(extra ...fakeResult) *fakePG {
+	return &fakePG{fakeCH{results: append(extra, fakeResult{match: "consent_receipts", cols: []string{"hid", "name"}})}}
+}
+
+func leaderOpts(limit int, cursor string) domain.GlobalOptions {
+	return domain.GlobalOptions{
+		Range: domain.TimeRange{Start: "2024-01-01", End: "2024-01-31"},
+		Page:  domain.PageOpts{Limit: limit, Cursor: cursor},
+	}
+}
+
+func TestReposLeaderboard_MostHitsFirstWithNextPage(t *testing.T) {
+	t.Parallel()
+
+	ch := &fakeCH{results: []fakeResult{{match: "ORDER BY hits DESC", cols: []string{"hid", "hits", "utt"}, data: [][]any{
+		{hidA, uint64(9), uint64(0)},
+		{hidB, uint64(4), uint64(0)},
+		{hidC, uint64(1), uint64(0)}, // the limit+1 probe row
+	}}}}
+	s := newTestStore(ch)
+	s.pg = noOptIns()
+
+	resp, err := s.ReposLeaderboard(context.Background(), domain.ReposLeaderboardInput{GlobalOptions: leaderOpts(2, "")})
+	if err != nil {
+		t.Fatalf("ReposLeaderboard err: %v", err)
+	}
+	want := []domain.ReposLeaderboardRow{
+		{RepoHID: hidA, Label: hidLabel(hidA), Hits: 9},
+		{RepoHID: hidB, Label: hidLabel(hidB), Hits: 4},
+	}
+	if !reflect.DeepEqual(resp.Items, want) {
+		t.Fatalf("items %+v", resp.Items)
+	}
+	if off, err := decodeOffsetCursor(resp.NextPage); err != nil || off != 2 {
+		t.Fatalf("next page %q decodes to %d (%v), want offset 2", resp.NextPage, off, err)
+	}
+
+	c, _ := ch.call("ORDER BY hits DESC")
+	if n := len(c.args); n < 2 || c.args[n-2] != 3 || c.args[n-1] != 0 {
+		t.Fatalf("args %v should end with LIMIT 3 OFFSET 0", c.args)
+	}
+}
+
+func TestActorsLeaderboard_SeenCohortIncludesZeros(t *testing.T) {
+	t.Parallel()
+
+	ch := &fakeCH{results: []fakeResult{{match: "LEFT JOIN", cols: []string{"hid", "hits", "utt"}, data: [][]any{
+		{hidB, uint64(0), uint64(40)},
+		{hidA, uint64(2), uint64(8)},
+	}}}}
+	s := newTestStore(ch)
+	s.pg = noOptIns()
+
+	in := domain.ActorsLeaderboardInput{
+		GlobalOptions:      leaderOpts(0, encodeOffsetCursor(50)),
+		LeaderboardOptions: domain.LeaderboardOptions{IncludeZero: true},
+	}
+	resp, err := s.ActorsLeaderboard(context.Background(), in)
+	if err != nil {
+		t.Fatalf("ActorsLeaderboard err: %v", err)
+	}
+	want := []domain.ActorsLeaderboardRow{
+		{ActorHID: hidB, Label: hidLabel(hidB), Hits: 0},
+		{ActorHID: hidA, Label: hidLabel(hidA), Hits: 2, Ratio: 0.25},
+	}
+	if !reflect.DeepEqual(resp.Items, want) || resp.NextPage != "" {
+		t.Fatalf("items %+v next %q", resp.Items, resp.NextPage)
+	}
+
+	// utterance window args come first (inner subquery), then the crimes window, then paging
+	c, _ := ch.call("LEFT JOIN")
+	wantArgs := []any{day("2024-01-01"), day("2024-02-01"), day("2024-01-01"), day("2024-02-01"), 51, 50}
+	if !reflect.DeepEqual(c.args, wantArgs) {
+		t.Fatalf("args %v want %v", c.args, wantArgs)
+	}
+}
+
+func TestReposLeaderboard_KnownCohortZeroFillsAndDropsExcluded(t *testing.T) {
+	t.Parallel()
+
+	a, b, c := []byte{0xaa}, []byte{0xbb}, []byte{0xcc}
+	ch := &fakeCH{results: []fakeResult{{match: "repo_hid IN ?", cols: []string{"hid", "hits", "utt"}, data: [][]any{
+		{"aa", uint64(3), uint64(0)},
+	}}}}
+	s := newTestStore(ch)
+	s.excl.val.repos = [][]byte{b} // legally excluded: never ranked, even with zero hits
+	s.pg = noOptIns(fakeResult{match: "FROM repositories ORDER BY", cols: []string{"repo_hid"}, data: [][]any{
+		{a}, {b}, {c},
+	}})
+
+	in := domain.ReposLeaderboardInput{
+		GlobalOptions:      leaderOpts(0, ""),
+		LeaderboardOptions: domain.LeaderboardOptions{IncludeZero: true, Cohort: "known"},
+	}
+	resp, err := s.ReposLeaderboard(context.Background(), in)
+	if err != nil {
+		t.Fatalf("ReposLeaderboard err: %v", err)
+	}
+	want := []domain.ReposLeaderboardRow{
+		{RepoHID: "cc", Label: "cc", Hits: 0},
+		{RepoHID: "aa", Label: "aa", Hits: 3},
+	}
+	if !reflect.DeepEqual(resp.Items, want) {
+		t.Fatalf("items %+v", resp.Items)
+	}
+
+	call, _ := ch.call("repo_hid IN ?")
+	if got := call.args[len(call.args)-1]; !reflect.DeepEqual(got, [][]byte{a, c}) {
+		t.Fatalf("cohort IN list %v", got)
+	}
+}
+
+func TestReposLeaderboard_KnownCohortScopedInSQL(t *testing.T) {
+	t.Parallel()
+
+	a := []byte{0xaa}
+	ch := &fakeCH{results: []fakeResult{{match: "repo_hid IN ?", cols: []string{"hid", "hits", "utt"}}}}
+	s := newTestStore(ch)
+	pg := noOptIns(fakeResult{match: "FROM repositories WHERE repo_hid = ANY($2)", cols: []string{"repo_hid"},
+		data: [][]any{{a}}})
+	s.pg = pg
+
+	g := leaderOpts(0, "")
+	g.RepoHIDs = []string{"AA"}
+	in := domain.ReposLeaderboardInput{
+		GlobalOptions:      g,
+		LeaderboardOptions: domain.LeaderboardOptions{IncludeZero: true, Cohort: "known"},
+	}
+	resp, err := s.ReposLeaderboard(context.Background(), in)
+	if err != nil {
+		t.Fatalf("ReposLeaderboard err: %v", err)
+	}
+	if len(resp.Items) != 1 || resp.Items[0].RepoHID != "aa" {
+		t.Fatalf("items %+v, want only the scoped repo", resp.Items)
+	}
+	call, ok := pg.call("FROM repositories WHERE")
+	if !ok || !reflect.DeepEqual(call.args, []any{knownCohortMax + 1, [][]byte{a}}) {
+		t.Fatalf("catalog query %+v: want the scope bound as $2", call)
+	}
+	hits, _ := ch.call("repo_hid IN ?")
+	if strings.Contains(hits.sql, "hex(repo_hid)) IN") || !slices.ContainsFunc(hits.args, func(v any) bool {
+		return reflect.DeepEqual(v, [][]byte{a})
+	}) {
+		t.Fatalf("hits query %+v: want the scope unhexed once and compared as raw bytes", hits)
+	}
+}
+
+func TestLeaderboard_LabelsLookedUpOncePerPage(t *testing.T) {
+	t.Parallel()
+
+	ch := &fakeCH{results: []fakeResult{{match: "ORDER BY hits DESC", cols: []string{"hid", "hits", "utt"}, data: [][]any{
+		{"aa", uint64(9), uint64(0)},
+		{"bb", uint64(4), uint64(0)},
+	}}}}
+	s := newTestStore(ch)
+	pg := &fakePG{fakeCH{results: []fakeResult{{match: "consent_receipts", cols: []string{"hid", "name"}, data: [][]any{
+		{[]byte{0xbb}, "octo/cat"},
+	}}}}}
+	s.pg = pg
+
+	resp, err := s.ReposLeaderboard(context.Background(), domain.ReposLeaderboardInput{GlobalOptions: leaderOpts(0, "")})
+	if err != nil {
+		t.Fatalf("ReposLeaderboard err: %v", err)
+	}
+	want := []domain.ReposLeaderboardRow{
+		{RepoHID: "aa", Label: hidLabel("aa"), Hits: 9},
+		{RepoHID: "bb", Label: "octo/cat", Hits: 4},
+	}
+	if !reflect.DeepEqual(resp.Items, want) {
+		t.Fatalf("items %+v", resp.Items)
+	}
+	if len(pg.calls) != 1 || !reflect.DeepEqual(pg.calls[0].args, []any{[][]byte{{0xaa}, {0xbb}}}) {
+		t.Fatalf("opt-in name lookups %+v, want one batched = ANY($1) query", pg.calls)
+	}
+}
+
+func TestLeaderboard_RejectsBadCursor(t *testing.T) {
+	t.Parallel()
+
+	s := newTestStore(&fakeCH{})
+	_, err := s.ReposLeaderboard(context.Background(), domain.ReposLeaderboardInput{GlobalOptions: leaderOpts(0, "!!")})
+	if err == nil {
+		t.Fatal("expected an invalid cursor error")
+	}
+}
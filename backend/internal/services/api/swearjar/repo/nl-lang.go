@@ -33,17 +33,18 @@ func (s *hybridStore) LangBars(
 
 	crWhere := []string{"created_at >= ?", "created_at < ?"}
 	crArgs := []any{startTS, endTS}
-	if len(in.DetVer) > 0 {
+	if dv := s.detvers(in.DetVer); len(dv) > 0 {
 		crWhere = append(crWhere, "detver IN ?")
-		crArgs = append(crArgs, in.DetVer)
+		crArgs = append(crArgs, dv)
 	}
+	repoHIDs, actorHIDs := hidFilter(in.RepoHIDs), hidFilter(in.ActorHIDs)
 	if len(in.RepoHIDs) > 0 {
 		crWhere = append(crWhere, "repo_hid IN ?")
-		crArgs = append(crArgs, in.RepoHIDs)
+		crArgs = append(crArgs, repoHIDs)
 	}
 	if len(in.ActorHIDs) > 0 {
 		crWhere = append(crWhere, "actor_hid IN ?")
-		crArgs = append(crArgs, in.ActorHIDs)
+		crArgs = append(crArgs, actorHIDs)
 	}
 	if len(in.NLLangs) > 0 {
 		crWhere = append(crWhere, "lang_code IN ?")
@@ -61,11 +62,11 @@ func (s *hybridStore) LangBars(
 	utArgs := []any{startTS, endTS}
 	if len(in.RepoHIDs) > 0 {
 		utWhere = append(utWhere, "repo_hid IN ?")
-		utArgs = append(utArgs, in.RepoHIDs)
+		utArgs = append(utArgs, repoHIDs)
 	}
 	if len(in.ActorHIDs) > 0 {
 		utWhere = append(utWhere, "actor_hid IN ?")
-		utArgs = append(utArgs, in.ActorHIDs)
+		utArgs = append(utArgs, actorHIDs)
 	}
 	if len(in.NLLangs) > 0 {
 		utWhere = append(utWhere, "lang_code IN ?")
@@ -79,6 +80,13 @@ func (s *hybridStore) LangBars(
 		}
 	}
 
+	ex, err := s.exclusionsFor(ctx, in.GlobalOptions)
+	if err != nil {
+		return domain.LangBarsResp{}, err
+	}
+	crWhere, crArgs = ex.applyCrimes(crWhere, crArgs)
+	utWhere, utArgs = ex.apply(utWhere, utArgs)
+
 	lim := in.Page.Limit
 	if lim <= 0 {
 		lim = 25
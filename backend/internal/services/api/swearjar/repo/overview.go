@@ -0,0 +1,419 @@
+package repo
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"swearjar/internal/services/api/swearjar/domain"
+)
+
+// repoOverviewTopTerms is how many top terms the repo lens returns
+const repoOverviewTopTerms = 10
+
+// RepoOverview returns the repo lens: a bucketed hits/utterances series, category mix and
+// top terms for one repo over the window, plus the same series for everyone when
+// CompareGlobal is set. Scope filters other than repo/actor HIDs apply to both
+// FirstOffense is filled when requested
+func (s *hybridStore) RepoOverview(ctx context.Context, in domain.RepoOverviewInput) (domain.RepoOverviewResp, error) {
+	hid := strings.ToLower(in.RepoHID)
+	out := domain.RepoOverviewResp{
+		Repo:     domain.RepoRef{HID: hid, Label: hidLabel(hid)},
+		Series:   []domain.RepoOverviewSeriesPoint{},
+		Mix:      map[string]int64{},
+		TopTerms: []domain.TopTermItem{},
+	}
+	name, err := s.optInName(ctx, "repo", hid)
+	if err != nil {
+		return domain.RepoOverviewResp{}, err
+	}
+	out.Repo.NameOptIn = name
+
+	loc, tz, err := loadTZ(in.TZ)
+	if err != nil {
+		return domain.RepoOverviewResp{}, err
+	}
+	startDay, err := time.Parse("2006-01-02", in.Range.Start)
+	if err != nil {
+		return domain.RepoOverviewResp{}, err
+	}
+	endIncl, err := time.Parse("2006-01-02", in.Range.End)
+	if err != nil {
+		return domain.RepoOverviewResp{}, err
+	}
+	start := time.Date(startDay.Year(), startDay.Month(), startDay.Day(), 0, 0, 0, 0, loc).UTC()
+	endExcl := time.Date(endIncl.Year(), endIncl.Month(), endIncl.Day()+1, 0, 0, 0, 0, loc).UTC()
+
+	// Shared (non-principal) filters; commit_crimes also honours detver
+	crWhere := []string{"created_at >= ? AND created_at < ?"}
+	crArgs := []any{start, endExcl}
+	utWhere := []string{"bucket_hour >= ? AND bucket_hour < ?"}
+	utArgs := []any{start, endExcl}
+	if dv := s.detvers(in.DetVer); len(dv) > 0 {
+		crWhere = append(crWhere, "detver IN ?")
+		crArgs = append(crArgs, dv)
+	}
+	if len(in.NLLangs) > 0 {
+		crWhere, utWhere = append(crWhere, "lang_code IN ?"), append(utWhere, "lang_code IN ?")
+		crArgs, utArgs = append(crArgs, in.NLLangs), append(utArgs, in.NLLangs)
+	}
+	if in.LangReliable != nil {
+		cond := "lang_reliable = 0"
+		if *in.LangReliable {
+			cond = "lang_reliable = 1"
+		}
+		crWhere, utWhere = append(crWhere, cond), append(utWhere, cond)
+	}
+	ex, err := s.exclusionsFor(ctx, in.GlobalOptions)
+	if err != nil {
+		return domain.RepoOverviewResp{}, err
+	}
+	crWhere, crArgs = ex.applyCrimes(crWhere, crArgs)
+	utWhere, utArgs = ex.apply(utWhere, utArgs)
+
+	repoCr, repoCrArgs := append(slices.Clone(crWhere), "repo_hid = unhex(?)"), append(slices.Clone(crArgs), hid)
+	repoUt, repoUtArgs := append(slices.Clone(utWhere), "repo_hid = unhex(?)"), append(slices.Clone(utArgs), hid)
+
+	bucket, mask := timeBucket(in.Interval)
+	series, partial, err := s.overviewSeries(ctx, bucket, mask, tz, repoCr, repoCrArgs, repoUt, repoUtArgs)
+	if err != nil {
+		return domain.RepoOverviewResp{}, err
+	}
+	out.Series, out.Partial = series, partial
+
+	if in.CompareGlobal {
+		global, gPartial, err := s.overviewSeries(ctx, bucket, mask, tz, crWhere, crArgs, utWhere, utArgs)
+		if err != nil {
+			return domain.RepoOverviewResp{}, err
+		}
+		out.GlobalRatio, out.Partial = global, out.Partial || gPartial
+	}
+
+	if out.Mix, err = s.overviewMix(ctx, repoCr, repoCrArgs); err != nil {
+		return domain.RepoOverviewResp{}, err
+	}
+	if out.TopTerms, err = s.overviewTopTerms(ctx, repoCr, repoCrArgs); err != nil {
+		return domain.RepoOverviewResp{}, err
+	}
+
+	if in.FirstOffense {
+		first, err := s.FirstOffense(ctx, "repo", hid)
+		if err != nil {
+			return domain.RepoOverviewResp{}, err
+		}
+		out.FirstOffense = first
+	}
+	return out, nil
+}
+
+// overviewSeries returns hits/utterances per bucket (sparse, ascending)
+// The utterance side is optional: if it fails the series keeps its hits and partial is set
+func (s *hybridStore) overviewSeries(
+	ctx context.Context,
+	bucket func(string) string,
+	mask, tz string,
+	crWhere []string, crArgs []any,
+	utWhere []string, utArgs []any,
+) ([]domain.RepoOverviewSeriesPoint, bool, error) {
+	byT := map[string]domain.RepoOverviewSeriesPoint{}
+
+	rs, err := s.ch.Query(ctx, fmt.Sprintf(`
+		SELECT formatDateTime(%s, '%s') AS t, count() AS hits
+		FROM swearjar.commit_crimes
+		WHERE %s
+		GROUP BY t
+	`, bucket("created_at"), mask, strings.Join(crWhere, " AND ")), append([]any{tz}, crArgs...)...)
+	if err != nil {
+		return nil, false, err
+	}
+	defer rs.Close()
+	for rs.Next() {
+		var t string
+		var n uint64
+		if err := rs.Scan(&t, &n); err != nil {
+			return nil, false, err
+		}
+		byT[t] = domain.RepoOverviewSeriesPoint{T: t, Hits: int64(n)}
+	}
+	if err := rs.Err(); err != nil {
+		return nil, false, err
+	}
+
+	utt := map[string]uint64{}
+	partial, err := optionalQuery(ctx, "overview_series_utterances", func() error {
+		urs, err := s.ch.Query(ctx, fmt.Sprintf(`
+			SELECT formatDateTime(%s, '%s') AS t, countMerge(cnt_state) AS all_utt
+			FROM swearjar.utt_hour_agg
+			WHERE %s
+			GROUP BY t
+		`, bucket("bucket_hour"), mask, strings.Join(utWhere, " AND ")), append([]any{tz}, utArgs...)...)
+		if err != nil {
+			return err
+		}
+		defer urs.Close()
+		for urs.Next() {
+			var t string
+			var n uint64
+			if err := urs.Scan(&t, &n); err != nil {
+				return err
+			}
+			utt[t] = n
+		}
+		return urs.Err()
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	if !partial {
+		for t, n := range utt {
+			p := byT[t]
+			p.T, p.Utterances = t, int64(n)
+			byT[t] = p
+		}
+	}
+
+	out := make([]domain.RepoOverviewSeriesPoint, 0, len(byT))
+	for _, t := range slices.Sorted(maps.Keys(byT)) {
+		p := byT[t]
+		if p.Utterances > 0 {
+			p.Ratio = float64(p.Hits) / float64(p.Utterances)
+		}
+		out = append(out, p)
+	}
+	return out, partial, nil
+}
+
+// overviewMix counts hits per category
+func (s *hybridStore) overviewMix(ctx context.Context, where []string, args []any) (map[string]int64, error) {
+	rs, err := s.ch.Query(ctx, `
+		SELECT toString(category) AS cat, count() AS hits
+		FROM swearjar.commit_crimes
+		WHERE `+strings.Join(where, " AND ")+`
+		GROUP BY cat
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rs.Close()
+	mix := map[string]int64{}
+	for rs.Next() {
+		var cat string
+		var n uint64
+		if err := rs.Scan(&cat, &n); err != nil {
+			return nil, err
+		}
+		mix[cat] = int64(n)
+	}
+	return mix, rs.Err()
+}
+
+// overviewTopTerms ranks terms by hits, with the offending utterances each appears in
+func (s *hybridStore) overviewTopTerms(ctx context.Context, where []string, args []any) ([]domain.TopTermItem, error) {
+	rs, err := s.ch.Query(ctx, `
+		SELECT term, term_id, count() AS hits, uniqCombined(12)(utterance_id) AS utt
+		FROM swearjar.commit_crimes
+		WHERE `+strings.Join(where, " AND ")+`
+		GROUP BY term, term_id
+		ORDER BY hits DESC, term ASC
+		LIMIT ?
+	`, append(slices.Clone(args), repoOverviewTopTerms)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rs.Close()
+	items := []domain.TopTermItem{}
+	for rs.Next() {
+		var (
+			it        domain.TopTermItem
+			hits, utt uint64
+		)
+		if err := rs.Scan(&it.Term, &it.TermID, &hits, &utt); err != nil {
+			return nil, err
+		}
+		it.Hits, it.Utterances = int64(hits), int64(utt)
+		items = append(items, it)
+	}
+	return items, rs.Err()
+}
+
+// FirstOffense returns the earliest offending utterance for a repo or actor as a masked sample
+// It ignores the request window (first-ever) but honours analytics exclusions; names are
+// revealed only for principals with an active opt-in. Returns nil when there are no hits
+func (s *hybridStore) FirstOffense(ctx context.Context, principal, hidHex string) (*domain.SampleItem, error) {
+	col := map[string]string{"repo": "repo_hid", "actor": "actor_hid"}[principal]
+	if col == "" {
+		return nil, fmt.Errorf("first offense: unknown principal %q", principal)
+	}
+
+	where := []string{col + " = unhex(?)"}
+	args := []any{strings.ToLower(hidHex)}
+	ex, err := s.exclusions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	where, args = ex.apply(where, args)
+
+	rs, err := s.ch.Query(ctx, `
+		SELECT
+		  toString(utterance_id)          AS uid,
+		  created_at,
+		  toString(source)                AS src,
+		  lower(hex(repo_hid))            AS repo_hex,
+		  lower(hex(actor_hid))           AS actor_hex,
+		  detver,
+		  groupArray(term)                AS terms,
+		  groupArray(toString(category))  AS cats,
+		  groupArray(toString(severity))  AS sevs,
+		  groupArray(span_start)          AS starts,
+		  groupArray(span_end)            AS ends
+		FROM swearjar.commit_crimes
+		WHERE `+strings.Join(where, " AND ")+`
+		GROUP BY utterance_id, created_at, source, repo_hid, actor_hid, detver
+		ORDER BY created_at ASC, utterance_id ASC
+		LIMIT 1
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rs.Close()
+
+	if !rs.Next() {
+		return nil, rs.Err()
+	}
+	var (
+		uid, src, repoHex, actorHex string
+		at                          time.Time
+		detver                      int
+		terms, cats, sevs           []string
+		starts, ends                []int32
+	)
+	if err := rs.Scan(&uid, &at, &src, &repoHex, &actorHex, &detver, &terms, &cats, &sevs, &starts, &ends); err != nil {
+		return nil, err
+	}
+	hits, spans := foldSampleHits(terms, cats, sevs, starts, ends)
+
+	item := &domain.SampleItem{
+		UtteranceID: uid,
+		CreatedAt:   at.UTC().Format(time.RFC3339),
+		Source:      src,
+		Repo:        domain.SampleRepo{HID: repoHex, Label: hidLabel(repoHex)},
+		Actor:       domain.SampleActor{HID: actorHex, Label: hidLabel(actorHex)},
+		Hits:        hits,
+		DetVer:      detver,
+	}
+	if s.textConsent {
+		cs, err := s.consented(ctx, []string{repoHex}, []string{actorHex})
+		if err != nil {
+			return nil, err
+		}
+		if !cs.covers(repoHex, actorHex) {
+			omitText(item)
+		}
+	}
+	if !item.TextOmitted {
+		day := at.UTC().Truncate(24 * time.Hour)
+		texts, err := s.sampleTexts(ctx, []string{uid}, day, day.Add(24*time.Hour))
+		if err != nil {
+			return nil, err
+		}
+		item.TextMasked = maskSpans(texts[uid], spans)
+	}
+	if item.Repo.NameOptIn, item.Actor.LoginOptIn, err = s.optInPair(ctx, repoHex, actorHex); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// optInPair looks up a sample's repo and actor opt-in names together, in one Postgres round trip
+func (s *hybridStore) optInPair(ctx context.Context, repoHex, actorHex string) (repo, actor *string, err error) {
+	repoHID, err := hex.DecodeString(repoHex)
+	if err != nil {
+		return nil, nil, err
+	}
+	actorHID, err := hex.DecodeString(actorHex)
+	if err != nil {
+		return nil, nil, err
+	}
+	rs, err := s.pg.Query(ctx, `
+		SELECT 'repo' AS principal, r.full_name AS name
+		FROM repositories r
+		JOIN consent_receipts c ON c.consent_id = r.consent_id
+		WHERE r.repo_hid = $1 AND c.action = 'opt_in' AND c.state = 'active' AND r.full_name IS NOT NULL
+		UNION ALL
+		SELECT 'actor', a.login
+		FROM actors a
+		JOIN consent_receipts c ON c.consent_id = a.consent_id
+		WHERE a.actor_hid = $2 AND c.action = 'opt_in' AND c.state = 'active' AND a.login IS NOT NULL
+	`, repoHID, actorHID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("lookup opt-in names: %w", err)
+	}
+	defer rs.Close()
+	for rs.Next() {
+		var principal, name string
+		if err := rs.Scan(&principal, &name); err != nil {
+			return nil, nil, fmt.Errorf("scan opt-in name: %w", err)
+		}
+		if principal == "repo" {
+			repo = &name
+		} else {
+			actor = &name
+		}
+	}
+	return repo, actor, rs.Err()
+}
+
+// optInName returns the catalog name (repo full_name / actor login) only under an active opt-in
+// It is optInNames for a single HID; callers labelling several rows should batch instead
+func (s *hybridStore) optInName(ctx context.Context, principal, hidHex string) (*string, error) {
+	names, err := s.optInNames(ctx, principal, []string{strings.ToLower(hidHex)})
+	if err != nil {
+		return nil, err
+	}
+	if name, ok := names[strings.ToLower(hidHex)]; ok {
+		return &name, nil
+	}
+	return nil, nil
+}
+
+// optInNames maps each lower hex HID with an active opt-in and a known catalog name to that
+// name, in one Postgres round trip
+func (s *hybridStore) optInNames(ctx context.Context, principal string, hidHexes []string) (map[string]string, error) {
+	out := map[string]string{}
+	hids, err := decodeHIDs(hidHexes)
+	if err != nil || len(hids) == 0 {
+		return out, err
+	}
+	sql := `
+		SELECT r.repo_hid, r.full_name
+		FROM repositories r
+		JOIN consent_receipts c ON c.consent_id = r.consent_id
+		WHERE r.repo_hid = ANY($1) AND c.action = 'opt_in' AND c.state = 'active' AND r.full_name IS NOT NULL
+	`
+	if principal == "actor" {
+		sql = `
+			SELECT a.actor_hid, a.login
+			FROM actors a
+			JOIN consent_receipts c ON c.consent_id = a.consent_id
+			WHERE a.actor_hid = ANY($1) AND c.action = 'opt_in' AND c.state = 'active' AND a.login IS NOT NULL
+		`
+	}
+	rs, err := s.pg.Query(ctx, sql, hids)
+	if err != nil {
+		return nil, fmt.Errorf("lookup %s opt-in names: %w", principal, err)
+	}
+	defer rs.Close()
+	for rs.Next() {
+		var hid []byte
+		var name string
+		if err := rs.Scan(&hid, &name); err != nil {
+			return nil, fmt.Errorf("scan %s opt-in name: %w", principal, err)
+		}
+		out[hex.EncodeToString(hid)] = name
+	}
+	return out, rs.Err()
+}
@@ -0,0 +1,27 @@
+package repo
+
+import (
+	"context"
+	"testing"
+)
+
+func TestOptInPair_OneRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	s := newTestStore(&fakeCH{})
+	pg := &fakePG{fakeCH{results: []fakeResult{{match: "UNION ALL", cols: []string{"principal", "name"}, data: [][]any{
+		{"actor", "octocat"},
+	}}}}}
+	s.pg = pg
+
+	repo, actor, err := s.optInPair(context.Background(), "aa", "bb")
+	if err != nil {
+		t.Fatalf("optInPair err: %v", err)
+	}
+	if repo != nil || actor == nil || *actor != "octocat" {
+		t.Fatalf("repo %v, actor %v", repo, actor)
+	}
+	if len(pg.calls) != 1 {
+		t.Fatalf("%d lookups, want 1", len(pg.calls))
+	}
+}
@@ -0,0 +1,22 @@
+package repo
+
+import (
+	"context"
+
+	"swearjar/internal/platform/logger"
+)
+
+// optionalQuery runs a secondary aggregate (e.g. the utt_hour_agg denominator) in isolation
+// A failure there is logged under name and reported as partial=true with no error so the
+// endpoint can still serve its hits; the caller must discard anything fn collected. A
+// cancelled request still fails
+func optionalQuery(ctx context.Context, name string, fn func() error) (partial bool, err error) {
+	if err := fn(); err != nil {
+		if cerr := ctx.Err(); cerr != nil {
+			return false, cerr
+		}
+		logger.C(ctx).Warn().Err(err).Str("query", name).Msg("swearjar: optional aggregate failed, serving partial result")
+		return true, nil
+	}
+	return false, nil
+}
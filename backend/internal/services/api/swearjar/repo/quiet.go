@@ -0,0 +1,99 @@
+package repo
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"swearjar/internal/services/api/swearjar/domain"
+)
+
+const quietStreaksDefaultLimit = 50
+
+// QuietStreaks ranks repos or actors by the gap between their last offense and now
+// Last offense is all-time (the window is ignored) but exclusions and detver filters apply
+func (s *hybridStore) QuietStreaks(ctx context.Context, in domain.QuietStreaksInput) (domain.QuietStreaksResp, error) {
+	col := "repo_hid"
+	if in.Principal == "actor" {
+		col = "actor_hid"
+	}
+	order := "ASC" // longest streak = oldest last offense
+	if in.Order == "shortest" {
+		order = "DESC"
+	}
+	limit := in.Page.Limit
+	if limit <= 0 {
+		limit = quietStreaksDefaultLimit
+	}
+
+	var where []string
+	var args []any
+	if dv := s.detvers(in.DetVer); len(dv) > 0 {
+		where = append(where, "detver IN ?")
+		args = append(args, dv)
+	}
+	if len(in.RepoHIDs) > 0 {
+		where = append(where, "repo_hid IN ?")
+		args = append(args, hidFilter(in.RepoHIDs))
+	}
+	if len(in.ActorHIDs) > 0 {
+		where = append(where, "actor_hid IN ?")
+		args = append(args, hidFilter(in.ActorHIDs))
+	}
+	ex, err := s.exclusionsFor(ctx, in.GlobalOptions)
+	if err != nil {
+		return domain.QuietStreaksResp{}, err
+	}
+	where, args = ex.applyCrimes(where, args)
+	whereSQL := ""
+	if len(where) > 0 {
+		whereSQL = "WHERE " + strings.Join(where, " AND ")
+	}
+
+	rs, err := s.ch.Query(ctx, `
+		SELECT lower(hex(`+col+`)) AS hid, max(created_at) AS last_at
+		FROM swearjar.commit_crimes
+		`+whereSQL+`
+		GROUP BY `+col+`
+		ORDER BY last_at `+order+`, hid ASC
+		LIMIT ?
+	`, append(args, limit)...)
+	if err != nil {
+		return domain.QuietStreaksResp{}, err
+	}
+	defer rs.Close()
+
+	now := time.Now().UTC()
+	out := domain.QuietStreaksResp{Principal: in.Principal, Items: []domain.QuietStreakRow{}}
+	for rs.Next() {
+		var hid string
+		var last time.Time
+		if err := rs.Scan(&hid, &last); err != nil {
+			return domain.QuietStreaksResp{}, err
+		}
+		out.Items = append(out.Items, domain.QuietStreakRow{
+			HID:           hid,
+			Label:         hidLabel(hid),
+			LastOffenseAt: last.UTC().Format(time.RFC3339),
+			DaysQuiet:     int64(now.Sub(last) / (24 * time.Hour)),
+		})
+	}
+	if err := rs.Err(); err != nil {
+		return domain.QuietStreaksResp{}, err
+	}
+
+	hids := make([]string, len(out.Items))
+	for i, it := range out.Items {
+		hids[i] = it.HID
+	}
+	names, err := s.optInNames(ctx, in.Principal, hids)
+	if err != nil {
+		return domain.QuietStreaksResp{}, err
+	}
+	for i := range out.Items {
+		if name, ok := names[out.Items[i].HID]; ok {
+			out.Items[i].NameOptIn = &name
+		}
+	}
+	return out, nil
+}
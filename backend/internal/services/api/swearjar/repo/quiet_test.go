@@ -0,0 +1,38 @@
+package repo
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"swearjar/internal/services/api/swearjar/domain"
+)
+
+func TestQuietStreaks_NamesLookedUpOnce(t *testing.T) {
+	t.Parallel()
+
+	last := time.Now().UTC().Add(-72 * time.Hour)
+	ch := &fakeCH{results: []fakeResult{{match: "max(created_at)", cols: []string{"hid", "last_at"}, data: [][]any{
+		{"aa", last}, {"bb", last}, {"cc", last},
+	}}}}
+	s := newTestStore(ch)
+	pg := &fakePG{fakeCH{results: []fakeResult{{match: "consent_receipts", cols: []string{"hid", "name"}, data: [][]any{
+		{[]byte{0xbb}, "octo/cat"},
+	}}}}}
+	s.pg = pg
+
+	resp, err := s.QuietStreaks(context.Background(), domain.QuietStreaksInput{Principal: "repo"})
+	if err != nil {
+		t.Fatalf("QuietStreaks err: %v", err)
+	}
+	if len(resp.Items) != 3 || resp.Items[0].NameOptIn != nil || resp.Items[2].NameOptIn != nil {
+		t.Fatalf("items %+v", resp.Items)
+	}
+	if n := resp.Items[1].NameOptIn; n == nil || *n != "octo/cat" || resp.Items[1].DaysQuiet != 3 {
+		t.Fatalf("opted-in row %+v", resp.Items[1])
+	}
+	if len(pg.calls) != 1 || !strings.Contains(pg.calls[0].sql, "= ANY($1)") {
+		t.Fatalf("opt-in name lookups %+v, want one batched = ANY($1) query", pg.calls)
+	}
+}
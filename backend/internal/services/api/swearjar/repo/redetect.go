@@ -0,0 +1,105 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"swearjar/internal/services/api/swearjar/domain"
+)
+
+// redetectWindow bounds the utterance/hits lookups around a created_at hint; without a hint both
+// reads scan every partition (acceptable for a gated debug endpoint, not for anything hot)
+const redetectWindow = time.Hour
+
+// RedetectSource loads one utterance and the hits stored for it
+// detver 0 compares against the newest detector version stored for the utterance
+func (s *hybridStore) RedetectSource(
+	ctx context.Context,
+	id string,
+	at *time.Time,
+	detver int,
+) (domain.RedetectSource, error) {
+	var out domain.RedetectSource
+
+	where := []string{"id = toUUID(?)"}
+	args := []any{id}
+	if at != nil {
+		where = append(where, "created_at >= ? AND created_at < ?")
+		args = append(args, at.Add(-redetectWindow).UTC(), at.Add(redetectWindow).UTC())
+	}
+	rs, err := s.ch.Query(ctx, fmt.Sprintf(`
+		SELECT
+		  argMax(ifNull(text_normalized, ''), ver) AS norm,
+		  argMax(text_raw, ver)                    AS raw,
+		  argMax(tuple(lang_code), ver).1          AS lang,
+		  argMax(created_at, ver)                  AS at
+		FROM swearjar.utterances
+		WHERE %s
+		GROUP BY id
+	`, strings.Join(where, " AND ")), args...)
+	if err != nil {
+		return out, fmt.Errorf("redetect utterance: %w", err)
+	}
+	defer rs.Close()
+	if !rs.Next() {
+		return out, rs.Err()
+	}
+	if err := rs.Scan(&out.Norm, &out.Raw, &out.LangCode, &out.CreatedAt); err != nil {
+		return out, fmt.Errorf("scan redetect utterance: %w", err)
+	}
+	if err := rs.Err(); err != nil {
+		return out, fmt.Errorf("redetect utterance: %w", err)
+	}
+	rs.Close()
+	out.Found = true
+
+	// Hits share the utterance's created_at, so the real timestamp narrows the read to one partition
+	hwhere := []string{"utterance_id = toUUID(?)", "created_at = ?"}
+	hargs := []any{id, out.CreatedAt}
+	if detver > 0 {
+		hwhere = append(hwhere, "detector_version = ?")
+		hargs = append(hargs, detver)
+	} else {
+		hwhere = append(hwhere, `detector_version = (
+			SELECT max(detector_version) FROM swearjar.hits WHERE utterance_id = toUUID(?) AND created_at = ?
+		)`)
+		hargs = append(hargs, id, out.CreatedAt)
+	}
+	hs, err := s.ch.Query(ctx, fmt.Sprintf(`
+		SELECT
+		  term,
+		  toString(category),
+		  toString(severity),
+		  span_start,
+		  span_end,
+		  toString(target_type),
+		  target_id,
+		  toString(ctx_action),
+		  detector_version
+		FROM swearjar.hits FINAL
+		WHERE %s
+		ORDER BY span_start ASC, span_end ASC, term ASC
+	`, strings.Join(hwhere, " AND ")), hargs...)
+	if err != nil {
+		return out, fmt.Errorf("redetect stored hits: %w", err)
+	}
+	defer hs.Close()
+
+	out.Hits = make([]domain.RedetectStoredHit, 0, 8)
+	for hs.Next() {
+		var h domain.RedetectStoredHit
+		var a, b, v int32
+		err := hs.Scan(&h.Term, &h.Category, &h.Severity, &a, &b, &h.TargetType, &h.TargetID, &h.CtxAction, &v)
+		if err != nil {
+			return out, fmt.Errorf("scan redetect stored hit: %w", err)
+		}
+		h.SpanStart, h.SpanEnd, h.DetectorVersion = int(a), int(b), int(v)
+		out.Hits = append(out.Hits, h)
+	}
+	if err := hs.Err(); err != nil {
+		return out, fmt.Errorf("redetect stored hits: %w", err)
+	}
+	return out, nil
+}
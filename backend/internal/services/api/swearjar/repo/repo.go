@@ -5,9 +5,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
 	"time"
 
+	"swearjar/internal/core/rulepack"
 	"swearjar/internal/modkit/repokit"
 	"swearjar/internal/platform/store"
 	"swearjar/internal/services/api/swearjar/domain"
@@ -21,35 +24,130 @@ type StorageRepo interface {
 
 	TimeseriesByDetver(ctx context.Context, in domain.TimeseriesDetverInput) (domain.TimeseriesDetverResp, error)
 	CodeLangBars(ctx context.Context, in domain.CodeLangBarsInput) (domain.CodeLangBarsResp, error)
+	CodeLangTimeseries(ctx context.Context, in domain.CodeLangTimeseriesInput) (domain.CodeLangTimeseriesResp, error)
 	CategoriesStack(ctx context.Context, in domain.CategoriesStackInput) (domain.CategoriesStackResp, error)
 	TopTerms(ctx context.Context, in domain.TopTermsInput) (domain.TopTermsResp, error)
 	TermTimeline(ctx context.Context, in domain.TermTimelineInput) (domain.TermTimelineResp, error)
 	TargetsMix(ctx context.Context, in domain.TargetsMixInput) (domain.TargetsMixResp, error)
 	TermsMatrix(ctx context.Context, in domain.TermsMatrixInput) (domain.TermsMatrixResp, error)
 	RepoOverview(ctx context.Context, in domain.RepoOverviewInput) (domain.RepoOverviewResp, error)
+	ActorsLeaderboard(ctx context.Context, in domain.ActorsLeaderboardInput) (domain.ActorsLeaderboardResp, error)
+	ReposLeaderboard(ctx context.Context, in domain.ReposLeaderboardInput) (domain.ReposLeaderboardResp, error)
+	FirstOffense(ctx context.Context, principal, hidHex string) (*domain.SampleItem, error)
+	QuietStreaks(ctx context.Context, in domain.QuietStreaksInput) (domain.QuietStreaksResp, error)
 	Samples(ctx context.Context, in domain.SamplesInput) (domain.SamplesResp, error)
+	ExportHits(
+		ctx context.Context, in domain.ExportHitsInput, maxRows, workers int, emit func(domain.ExportHitRecord) error,
+	) (string, error)
 	RatiosTime(ctx context.Context, in domain.RatiosTimeInput) (domain.RatiosTimeResp, error)
 	SeverityTimeseries(ctx context.Context, in domain.SeverityTimeseriesInput) (domain.SeverityTimeseriesResp, error)
 	SpikeDrivers(ctx context.Context, in domain.SpikeDriversInput) (domain.SpikeDriversResp, error)
 	KPIStrip(ctx context.Context, in domain.KPIStripInput) (domain.KPIStripResp, error)
 	YearlyTrends(ctx context.Context, in domain.YearlyTrendsInput) (domain.YearlyTrendsResp, error)
+	Facets(ctx context.Context) (domain.FacetsResp, error)
+	Search(ctx context.Context, in domain.SearchInput) (domain.SearchResp, error)
+	DetverDiffRows(ctx context.Context, in domain.DetverDiffInput) ([]domain.DetverDiffRow, error)
+	KnownDetvers(ctx context.Context) ([]int, error)
+	RedetectSource(ctx context.Context, id string, at *time.Time, detver int) (domain.RedetectSource, error)
+	ProcessedThrough(ctx context.Context) (time.Time, bool, error)
+}
+
+// HybridOptions tunes the hybrid store
+type HybridOptions struct {
+	// Weights drive every mean-severity index (nil uses rulepack.DefaultSeverityWeights)
+	Weights rulepack.SeverityWeights
+	// DetVer filters requests that send no detver (0 = every detver)
+	DetVer int
+	// RowCaps bound fan-out endpoints (nil uses DefaultRowCaps)
+	RowCaps RowCaps
+	// ConsentedTextOnly omits sample/export text unless the repo or actor has an active opt-in
+	ConsentedTextOnly bool
 }
 
 // NewHybrid constructs a hybrid storage binder using PG and CH
-func NewHybrid(ch store.Clickhouse) repokit.Binder[StorageRepo] { return &hybridBinder{ch: ch} }
+func NewHybrid(ch store.Clickhouse, o HybridOptions) repokit.Binder[StorageRepo] {
+	if o.Weights == nil {
+		o.Weights = rulepack.DefaultSeverityWeights()
+	}
+	if o.RowCaps == nil {
+		o.RowCaps = DefaultRowCaps()
+	}
+	return &hybridBinder{
+		ch:          ch,
+		weights:     o.Weights,
+		detver:      o.DetVer,
+		caps:        o.RowCaps,
+		textConsent: o.ConsentedTextOnly,
+		excl:        newExclusionCache(exclusionsTTL),
+		facets:      newFacetsCache(facetsTTL),
+		fresh:       newFreshnessCache(freshnessTTL),
+	}
+}
 
-type hybridBinder struct{ ch store.Clickhouse }
+type hybridBinder struct {
+	ch          store.Clickhouse
+	weights     rulepack.SeverityWeights
+	detver      int
+	caps        RowCaps
+	textConsent bool
+	excl        *exclusionCache
+	facets      *facetsCache
+	fresh       *freshnessCache
+}
 
 // Bind binds a Queryer to produce a StorageRepo
-func (b *hybridBinder) Bind(q repokit.Queryer) StorageRepo { return &hybridStore{pg: q, ch: b.ch} }
+func (b *hybridBinder) Bind(q repokit.Queryer) StorageRepo {
+	return &hybridStore{
+		pg: q, ch: b.ch, weights: b.weights, detver: b.detver, caps: b.caps, textConsent: b.textConsent,
+		excl: b.excl, facets: b.facets, fresh: b.fresh,
+	}
+}
 
 type hybridStore struct {
-	pg repokit.Queryer
-	ch store.Clickhouse
+	pg          repokit.Queryer
+	ch          store.Clickhouse
+	weights     rulepack.SeverityWeights
+	detver      int // default detver filter for requests without one (0 = every detver)
+	caps        RowCaps
+	textConsent bool // omit text for unconsented principals (see HybridOptions.ConsentedTextOnly)
+	excl        *exclusionCache
+	facets      *facetsCache
+	fresh       *freshnessCache
 }
 
 func unimpl[T any]() (T, error) { var z T; return z, errors.New("unimplemented") }
 
+// detvers is the detver filter for a request: its own list, else the configured default
+// (nil = every detver). Mixing detvers counts the same utterance once per detector version
+func (s *hybridStore) detvers(req []int) []int {
+	if len(req) > 0 {
+		return req
+	}
+	if s.detver > 0 {
+		return []int{s.detver}
+	}
+	return nil
+}
+
+// timeBucket maps an interval to a bucket expression over a time column (tz bound as ?)
+// and its formatDateTime label mask; auto/empty and unknown intervals bucket by day
+func timeBucket(interval string) (func(col string) string, string) {
+	switch strings.ToLower(strings.TrimSpace(interval)) {
+	case "hour":
+		return func(c string) string { return "toStartOfHour(toTimeZone(" + c + ", ?))" }, "%Y-%m-%dT%H:00:00"
+	case "week":
+		return func(c string) string { return "toStartOfWeek(toTimeZone(" + c + ", ?))" }, "%Y-%m-%d"
+	case "month":
+		return func(c string) string { return "toStartOfMonth(toTimeZone(" + c + ", ?))" }, "%Y-%m-01"
+	case "quarter":
+		return func(c string) string { return "toStartOfQuarter(toTimeZone(" + c + ", ?))" }, "%Y-%m-01"
+	case "year":
+		return func(c string) string { return "toStartOfYear(toTimeZone(" + c + ", ?))" }, "%Y-01-01"
+	default:
+		return func(c string) string { return "toStartOfDay(toTimeZone(" + c + ", ?))" }, "%Y-%m-%d"
+	}
+}
+
 // TimeseriesHits queries ClickHouse for hits/utterances over time
 // This is a first pass. We'll make it better
 func (s *hybridStore) TimeseriesHits(
@@ -60,7 +158,7 @@ func (s *hybridStore) TimeseriesHits(
 	switch interval {
 	case "", "auto":
 		interval = "day"
-	case "hour", "day", "week", "month":
+	case "hour", "day", "week", "month", "quarter", "year":
 	default:
 		interval = "day"
 	}
@@ -79,64 +177,39 @@ func (s *hybridStore) TimeseriesHits(
 	}
 	endExcl := endIncl.Add(24 * time.Hour)
 
-	var bucketExprCrimes, bucketExprUtt, fmtMask string
-	switch interval {
-	case "hour":
-		bucketExprCrimes = "toStartOfHour(toTimeZone(created_at, ?))"
-		bucketExprUtt = "toStartOfHour(toTimeZone(bucket_hour, ?))"
-		fmtMask = "%Y-%m-%dT%H:00:00"
-	case "week":
-		bucketExprCrimes = "toStartOfWeek(toTimeZone(created_at, ?))"
-		bucketExprUtt = "toStartOfWeek(toTimeZone(bucket_hour, ?))"
-		fmtMask = "%Y-%m-%d"
-	case "month":
-		bucketExprCrimes = "toStartOfMonth(toTimeZone(created_at, ?))"
-		bucketExprUtt = "toStartOfMonth(toTimeZone(bucket_hour, ?))"
-		fmtMask = "%Y-%m-01"
-	default: // day
-		bucketExprCrimes = "toStartOfDay(toTimeZone(created_at, ?))"
-		bucketExprUtt = "toStartOfDay(toTimeZone(bucket_hour, ?))"
-		fmtMask = "%Y-%m-%d"
-	}
+	bucket, fmtMask := timeBucket(interval)
+	bucketExprCrimes, bucketExprUtt := bucket("created_at"), bucket("bucket_hour")
 
-	// Build combined series from commit_crimes (hits + offending_utt) and utt_hour_agg (all_utt)
-	sql := fmt.Sprintf(`
-		WITH
-		crimes AS (
-			SELECT
-				formatDateTime(%s, '%s') AS t,
-				count() AS hits,
-				uniqCombined(12)(utterance_id) AS off_utt
-			FROM swearjar.commit_crimes
-			WHERE created_at >= ? AND created_at < ?
-			GROUP BY t
-		),
-		utt AS (
-			SELECT
-				formatDateTime(%s, '%s') AS t,
-				countMerge(cnt_state) AS all_utt
-			FROM swearjar.utt_hour_agg
-			WHERE bucket_hour >= ? AND bucket_hour < ?
-			GROUP BY t
-		)
-		SELECT
-			coalesce(c.t, u.t)     AS t,
-			ifNull(c.hits, 0)      AS hits,
-			ifNull(c.off_utt, 0)   AS off_utt,
-			ifNull(u.all_utt, 0)   AS all_utt
-		FROM crimes c
-		FULL OUTER JOIN utt u ON c.t = u.t
-		ORDER BY t ASC
-	`, bucketExprCrimes, fmtMask, bucketExprUtt, fmtMask)
-
-	rs, err := s.ch.Query(ctx, sql,
-		tz, start, endExcl, // crimes tz + range
-		tz, start, endExcl, // utt tz + range
-	)
+	ex, err := s.exclusionsFor(ctx, in.GlobalOptions)
 	if err != nil {
 		return domain.TimeseriesHitsResp{}, err
 	}
-	defer rs.Close()
+	crWhere, crArgs := ex.applyCrimes([]string{"created_at >= ? AND created_at < ?"}, []any{start, endExcl})
+	if dv := s.detvers(in.DetVer); len(dv) > 0 {
+		crWhere = append(crWhere, "detver IN ?")
+		crArgs = append(crArgs, dv)
+	}
+	utWhere, utArgs := ex.apply([]string{"bucket_hour >= ? AND bucket_hour < ?"}, []any{start, endExcl})
+
+	// Hits come from commit_crimes; the all-utterance denominator from utt_hour_agg is queried
+	// separately so a failing or unpopulated aggregate degrades to Partial instead of a 500
+	crSQL := fmt.Sprintf(`
+		SELECT
+			formatDateTime(%s, '%s') AS t,
+			count() AS hits,
+			uniqCombined(12)(utterance_id) AS off_utt
+		FROM swearjar.commit_crimes
+		WHERE %s
+		GROUP BY t
+	`, bucketExprCrimes, fmtMask, strings.Join(crWhere, " AND "))
+	utSQL := fmt.Sprintf(`
+		SELECT
+			formatDateTime(%s, '%s') AS t,
+			countMerge(cnt_state) AS all_utt
+		FROM swearjar.utt_hour_agg
+		WHERE %s
+		GROUP BY t
+	`, bucketExprUtt, fmtMask, strings.Join(utWhere, " AND "))
 
 	type row struct {
 		t      string
@@ -144,18 +217,52 @@ func (s *hybridStore) TimeseriesHits(
 		offUtt uint64
 		allUtt uint64
 	}
-	var fetched []row
+	byKey := make(map[string]row)
+
+	rs, err := s.ch.Query(ctx, crSQL, append([]any{tz}, crArgs...)...)
+	if err != nil {
+		return domain.TimeseriesHitsResp{}, err
+	}
+	defer rs.Close()
 	for rs.Next() {
 		var r row
-		if err := rs.Scan(&r.t, &r.hits, &r.offUtt, &r.allUtt); err != nil {
+		if err := rs.Scan(&r.t, &r.hits, &r.offUtt); err != nil {
 			return domain.TimeseriesHitsResp{}, err
 		}
-		fetched = append(fetched, r)
+		byKey[r.t] = r
 	}
 	if err := rs.Err(); err != nil {
 		return domain.TimeseriesHitsResp{}, err
 	}
 
+	allUtt := make(map[string]uint64)
+	partial, err := optionalQuery(ctx, "timeseries_utterances", func() error {
+		urs, err := s.ch.Query(ctx, utSQL, append([]any{tz}, utArgs...)...)
+		if err != nil {
+			return err
+		}
+		defer urs.Close()
+		for urs.Next() {
+			var t string
+			var n uint64
+			if err := urs.Scan(&t, &n); err != nil {
+				return err
+			}
+			allUtt[t] = n
+		}
+		return urs.Err()
+	})
+	if err != nil {
+		return domain.TimeseriesHitsResp{}, err
+	}
+	if !partial {
+		for t, n := range allUtt {
+			r := byKey[t]
+			r.t, r.allUtt = t, n
+			byKey[t] = r
+		}
+	}
+
 	emitKey := func(t time.Time) string {
 		switch interval {
 		case "hour":
@@ -165,12 +272,6 @@ func (s *hybridStore) TimeseriesHits(
 		}
 	}
 
-	// Build lookup
-	byKey := make(map[string]row, len(fetched))
-	for _, r := range fetched {
-		byKey[r.t] = r
-	}
-
 	// helper to build a point (compute intensity/coverage/rarity when possible)
 	buildPoint := func(key string, r row) domain.TimeseriesPoint {
 		pt := domain.TimeseriesPoint{
@@ -191,11 +292,12 @@ func (s *hybridStore) TimeseriesHits(
 
 	var series []domain.TimeseriesPoint
 	switch interval {
-	case "month":
-		// keep sparse months (variable step)
-		series = make([]domain.TimeseriesPoint, 0, len(fetched))
-		for _, r := range fetched {
-			series = append(series, buildPoint(r.t, r))
+	case "month", "quarter", "year":
+		// keep sparse calendar buckets (variable step)
+		keys := slices.Sorted(maps.Keys(byKey))
+		series = make([]domain.TimeseriesPoint, 0, len(keys))
+		for _, k := range keys {
+			series = append(series, buildPoint(k, byKey[k]))
 		}
 	default:
 		// linear step fill for hour/day/week
@@ -216,6 +318,7 @@ func (s *hybridStore) TimeseriesHits(
 	return domain.TimeseriesHitsResp{
 		Interval: interval,
 		Series:   series,
+		Partial:  partial,
 	}, nil
 }
 
@@ -252,16 +355,6 @@ func (s *hybridStore) TermsMatrix(ctx context.Context, in domain.TermsMatrixInpu
 	return unimpl[domain.TermsMatrixResp]()
 }
 
-// RepoOverview is unimplemented
-func (s *hybridStore) RepoOverview(ctx context.Context, in domain.RepoOverviewInput) (domain.RepoOverviewResp, error) {
-	return unimpl[domain.RepoOverviewResp]()
-}
-
-// Samples is unimplemented
-func (s *hybridStore) Samples(ctx context.Context, in domain.SamplesInput) (domain.SamplesResp, error) {
-	return unimpl[domain.SamplesResp]()
-}
-
 // RatiosTime is unimplemented
 func (s *hybridStore) RatiosTime(ctx context.Context, in domain.RatiosTimeInput) (domain.RatiosTimeResp, error) {
 	return unimpl[domain.RatiosTimeResp]()
@@ -0,0 +1,194 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"swearjar/internal/services/api/swearjar/domain"
+)
+
+const (
+	crimesTS = "uniqCombined(12)(utterance_id) AS off_utt"
+	uttTS    = "countMerge(cnt_state) AS all_utt"
+)
+
+func tsInput(interval, start, end string) domain.TimeseriesHitsInput {
+	return domain.TimeseriesHitsInput{GlobalOptions: domain.GlobalOptions{
+		Range:    domain.TimeRange{Start: start, End: end},
+		Interval: interval,
+	}}
+}
+
+func TestTimeseriesHits_DayFillAndRatios(t *testing.T) {
+	t.Parallel()
+
+	ch := &fakeCH{results: []fakeResult{
+		{match: crimesTS, cols: []string{"t", "hits", "off_utt"}, data: [][]any{
+			{"2024-01-01", uint64(10), uint64(5)},
+			{"2024-01-03", uint64(4), uint64(4)},
+		}},
+		{match: uttTS, cols: []string{"t", "all_utt"}, data: [][]any{
+			{"2024-01-01", uint64(50)},
+			{"2024-01-02", uint64(20)},
+		}},
+	}}
+
+	resp, err := newTestStore(ch).TimeseriesHits(context.Background(), tsInput("", "2024-01-01", "2024-01-03"))
+	if err != nil {
+		t.Fatalf("TimeseriesHits err: %v", err)
+	}
+	if resp.Interval != "day" || resp.Partial {
+		t.Fatalf("interval=%q partial=%v", resp.Interval, resp.Partial)
+	}
+
+	want := []domain.TimeseriesPoint{
+		{T: "2024-01-01", Hits: 10, OffendingUtterances: 5, AllUtterances: 50, Intensity: 2, Coverage: 0.1, Rarity: 0.2},
+		{T: "2024-01-02", AllUtterances: 20},                             // utterances but no hits: ratios stay zero
+		{T: "2024-01-03", Hits: 4, OffendingUtterances: 4, Intensity: 1}, // no denominator
+	}
+	if !reflect.DeepEqual(resp.Series, want) {
+		t.Fatalf("series\n got %+v\nwant %+v", resp.Series, want)
+	}
+
+	// tz leads the args, then the [start, end+1d) window
+	c, ok := ch.call(crimesTS)
+	if !ok {
+		t.Fatal("crimes query not issued")
+	}
+	wantArgs := []any{"UTC", day("2024-01-01"), day("2024-01-04")}
+	if !reflect.DeepEqual(c.args, wantArgs) {
+		t.Fatalf("args %v want %v", c.args, wantArgs)
+	}
+}
+
+func TestTimeseriesHits_DefaultDetverUnlessRequested(t *testing.T) {
+	t.Parallel()
+
+	run := func(detver int, req []int) []any {
+		ch := &fakeCH{results: []fakeResult{
+			{match: crimesTS, cols: []string{"t", "hits", "off_utt"}},
+			{match: uttTS, cols: []string{"t", "all_utt"}},
+		}}
+		s := newTestStore(ch)
+		s.detver = detver
+		in := tsInput("", "2024-01-01", "2024-01-01")
+		in.DetVer = req
+		if _, err := s.TimeseriesHits(context.Background(), in); err != nil {
+			t.Fatalf("TimeseriesHits err: %v", err)
+		}
+		c, _ := ch.call(crimesTS)
+		if u, _ := ch.call(uttTS); len(u.args) != 3 {
+			t.Fatalf("detver leaked into the utterance denominator: %v", u.args)
+		}
+		return c.args[3:]
+	}
+
+	if got := run(0, nil); len(got) != 0 {
+		t.Fatalf("no default: extra args %v", got)
+	}
+	if got := run(2, nil); !reflect.DeepEqual(got, []any{[]int{2}}) {
+		t.Fatalf("default: args %v, want [[2]]", got)
+	}
+	if got := run(2, []int{1, 2}); !reflect.DeepEqual(got, []any{[]int{1, 2}}) {
+		t.Fatalf("requested: args %v, want [[1 2]]", got)
+	}
+}
+
+func TestTimeseriesHits_HourFillCoversWholeRange(t *testing.T) {
+	t.Parallel()
+
+	ch := &fakeCH{results: []fakeResult{
+		{match: crimesTS, cols: []string{"t", "hits", "off_utt"}, data: [][]any{
+			{"2024-01-01T05:00:00", uint64(3), uint64(2)},
+		}},
+		{match: uttTS, cols: []string{"t", "all_utt"}},
+	}}
+	in := tsInput("hour", "2024-01-01", "2024-01-01")
+	in.TZ = "Europe/Berlin"
+
+	resp, err := newTestStore(ch).TimeseriesHits(context.Background(), in)
+	if err != nil {
+		t.Fatalf("TimeseriesHits err: %v", err)
+	}
+	if len(resp.Series) != 24 {
+		t.Fatalf("got %d buckets, want 24", len(resp.Series))
+	}
+	if p := resp.Series[5]; p.T != "2024-01-01T05:00:00" || p.Hits != 3 || p.Intensity != 1.5 {
+		t.Fatalf("bucket 5 = %+v", p)
+	}
+	if c, _ := ch.call(uttTS); len(c.args) == 0 || c.args[0] != "Europe/Berlin" {
+		t.Fatalf("utt query args %v should lead with the tz", c.args)
+	}
+}
+
+func TestTimeseriesHits_MonthKeepsSparseSortedBuckets(t *testing.T) {
+	t.Parallel()
+
+	ch := &fakeCH{results: []fakeResult{
+		{match: crimesTS, cols: []string{"t", "hits", "off_utt"}, data: [][]any{
+			{"2024-03-01", uint64(6), uint64(3)},
+			{"2024-01-01", uint64(2), uint64(2)},
+		}},
+		{match: uttTS, cols: []string{"t", "all_utt"}, data: [][]any{
+			{"2024-03-01", uint64(60)},
+		}},
+	}}
+
+	resp, err := newTestStore(ch).TimeseriesHits(context.Background(), tsInput("month", "2024-01-01", "2024-03-31"))
+	if err != nil {
+		t.Fatalf("TimeseriesHits err: %v", err)
+	}
+	var keys []string
+	for _, p := range resp.Series {
+		keys = append(keys, p.T)
+	}
+	if !reflect.DeepEqual(keys, []string{"2024-01-01", "2024-03-01"}) {
+		t.Fatalf("keys %v", keys)
+	}
+	if p := resp.Series[1]; p.Coverage != 0.05 || p.Rarity != 0.1 {
+		t.Fatalf("march ratios %+v", p)
+	}
+}
+
+func TestTimeseriesHits_DenominatorFailureIsPartial(t *testing.T) {
+	t.Parallel()
+
+	ch := &fakeCH{results: []fakeResult{
+		{match: crimesTS, cols: []string{"t", "hits", "off_utt"}, data: [][]any{
+			{"2024-01-01", uint64(10), uint64(5)},
+		}},
+		{match: uttTS, err: errors.New("utt_hour_agg missing")},
+	}}
+
+	resp, err := newTestStore(ch).TimeseriesHits(context.Background(), tsInput("day", "2024-01-01", "2024-01-01"))
+	if err != nil {
+		t.Fatalf("TimeseriesHits err: %v", err)
+	}
+	if !resp.Partial {
+		t.Fatal("expected Partial when the denominator query fails")
+	}
+	want := domain.TimeseriesPoint{T: "2024-01-01", Hits: 10, OffendingUtterances: 5, Intensity: 2}
+	if len(resp.Series) != 1 || resp.Series[0] != want {
+		t.Fatalf("series %+v", resp.Series)
+	}
+}
+
+func TestTimeseriesHits_Errors(t *testing.T) {
+	t.Parallel()
+
+	ch := &fakeCH{results: []fakeResult{{match: crimesTS, err: errors.New("boom")}}}
+	s := newTestStore(ch)
+	if _, err := s.TimeseriesHits(context.Background(), tsInput("day", "2024-01-01", "2024-01-02")); err == nil ||
+		err.Error() != "boom" {
+		t.Fatalf("expected hits query error to bubble, got %v", err)
+	}
+
+	if _, err := s.TimeseriesHits(context.Background(), tsInput("day", "nope", "2024-01-02")); err == nil {
+		t.Fatal("expected a bad range start to fail")
+	}
+	if len(ch.calls) != 1 {
+		t.Fatalf("a bad range should fail before querying, got %d calls", len(ch.calls))
+	}
+}
@@ -0,0 +1,95 @@
+package repo
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	perr "swearjar/internal/platform/errors"
+)
+
+// Row-capped endpoints; names are what ROW_CAPS overrides refer to
+const (
+	CapTopTerms          = "top_terms"
+	CapTermsMatrix       = "terms_matrix"
+	CapRepoActorCrosstab = "crosstab_repo_actor"
+)
+
+// RowCap bounds the rows (or cells) a single endpoint's query may return, protecting both
+// ClickHouse and clients from result sets that fan out with the request (many terms x langs,
+// every repo x actor x day). The query asks for one row past Max: getting it back means the
+// result was cut, which the endpoint either reports (truncated: true) or, when a partial answer
+// would read as real zeros, refuses with ErrorCodeTooLarge (HTTP 413).
+// The capped endpoints' queries aren't implemented yet (they answer unimplemented); each applies
+// its cap with limitSQL and clipRows, and surfaces truncated/413 in its API, when it lands
+type RowCap struct {
+	Max    int  // 0 = uncapped
+	Refuse bool // error past Max instead of truncating
+}
+
+// RowCaps maps endpoint names to their caps
+type RowCaps map[string]RowCap
+
+// DefaultRowCaps is every capped endpoint with its declared behaviour. The matrix refuses
+// because a missing cell is indistinguishable from a zero one; ranked lists just truncate
+func DefaultRowCaps() RowCaps {
+	return RowCaps{
+		CapTopTerms:          {Max: 1000},
+		CapTermsMatrix:       {Max: 10000, Refuse: true},
+		CapRepoActorCrosstab: {Max: 50000},
+	}
+}
+
+// ParseRowCaps overrides DefaultRowCaps maxima from "name=rows" pairs
+// (e.g., "terms_matrix=5000,crosstab_repo_actor=20000"); 0 uncaps an endpoint.
+// Whether an endpoint truncates or refuses is declared in code, not configured
+func ParseRowCaps(spec string) (RowCaps, error) {
+	caps := DefaultRowCaps()
+	for pair := range strings.SplitSeq(spec, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		name, v, ok := strings.Cut(pair, "=")
+		name = strings.TrimSpace(name)
+		c, known := caps[name]
+		if !ok || !known {
+			names := strings.Join(slices.Sorted(maps.Keys(caps)), ", ")
+			return nil, fmt.Errorf("row cap %q: want name=rows with name one of %s", pair, names)
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("row cap %q: rows must be a non-negative integer", pair)
+		}
+		c.Max = n
+		caps[name] = c
+	}
+	return caps, nil
+}
+
+// rowCap is the cap for an endpoint; endpoints missing from the store's caps are uncapped
+func (s *hybridStore) rowCap(name string) RowCap { return s.caps[name] }
+
+// limitSQL is the LIMIT clause for a capped query, asking for one probe row past Max so
+// clipRows can tell a full result from a cut one ("" when uncapped)
+func (c RowCap) limitSQL() string {
+	if c.Max <= 0 {
+		return ""
+	}
+	return " LIMIT " + strconv.Itoa(c.Max+1)
+}
+
+// clipRows applies an endpoint's cap to rows fetched with limitSQL: within it rows come back
+// as-is; past it they are cut to Max with truncated set, or refused when the cap says so
+func clipRows[T any](name string, c RowCap, rows []T) (out []T, truncated bool, err error) {
+	if c.Max <= 0 || len(rows) <= c.Max {
+		return rows, false, nil
+	}
+	if c.Refuse {
+		return nil, false, perr.Newf(perr.ErrorCodeTooLarge,
+			"%s: result exceeds %d rows; narrow the range, add filters or request fewer terms", name, c.Max)
+	}
+	return rows[:c.Max], true, nil
+}
@@ -0,0 +1,57 @@
+package repo
+
+import (
+	"reflect"
+	"testing"
+
+	perr "swearjar/internal/platform/errors"
+)
+
+func TestParseRowCaps(t *testing.T) {
+	t.Parallel()
+
+	caps, err := ParseRowCaps(" terms_matrix=5000, top_terms=0 ")
+	if err != nil {
+		t.Fatalf("ParseRowCaps err: %v", err)
+	}
+	want := DefaultRowCaps()
+	want[CapTermsMatrix] = RowCap{Max: 5000, Refuse: true} // override keeps the declared behaviour
+	want[CapTopTerms] = RowCap{}
+	if !reflect.DeepEqual(caps, want) {
+		t.Fatalf("caps %+v want %+v", caps, want)
+	}
+
+	for _, bad := range []string{"nope=10", "top_terms", "top_terms=-1", "top_terms=lots"} {
+		if _, err := ParseRowCaps(bad); err == nil {
+			t.Fatalf("%q: expected an error", bad)
+		}
+	}
+}
+
+func TestClipRows(t *testing.T) {
+	t.Parallel()
+
+	c := RowCap{Max: 2}
+	if got := c.limitSQL(); got != " LIMIT 3" {
+		t.Fatalf("limitSQL %q, want one probe row past the cap", got)
+	}
+	if got := (RowCap{}).limitSQL(); got != "" {
+		t.Fatalf("uncapped limitSQL %q", got)
+	}
+
+	rows, trunc, err := clipRows(CapTopTerms, c, []int{1, 2})
+	if err != nil || trunc || len(rows) != 2 {
+		t.Fatalf("at cap: rows %v truncated %v err %v", rows, trunc, err)
+	}
+	rows, trunc, err = clipRows(CapTopTerms, c, []int{1, 2, 3})
+	if err != nil || !trunc || !reflect.DeepEqual(rows, []int{1, 2}) {
+		t.Fatalf("past cap: rows %v truncated %v err %v", rows, trunc, err)
+	}
+	_, _, err = clipRows(CapTermsMatrix, RowCap{Max: 2, Refuse: true}, []int{1, 2, 3})
+	if !perr.IsCode(err, perr.ErrorCodeTooLarge) {
+		t.Fatalf("refusing cap: err %v, want ErrorCodeTooLarge", err)
+	}
+	if s := newTestStore(&fakeCH{}); s.rowCap("unknown") != (RowCap{}) {
+		t.Fatal("endpoints without a cap should be uncapped")
+	}
+}
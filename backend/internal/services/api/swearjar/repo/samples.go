@@ -0,0 +1,612 @@
+package repo
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"math"
+	"math/rand/v2"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"swearjar/internal/core/rulepack"
+	"swearjar/internal/core/samplekey"
+	"swearjar/internal/core/termid"
+	perr "swearjar/internal/platform/errors"
+	"swearjar/internal/services/api/swearjar/domain"
+)
+
+// Samples returns masked sample cards (newest first, or a seeded random sample) with every hit span per term
+// Hits are stored one row per span; rows are folded per (utterance, term) so the UI
+// can highlight every occurrence and masking covers all of them
+func (s *hybridStore) Samples(ctx context.Context, in domain.SamplesInput) (domain.SamplesResp, error) {
+	limit := in.Limit
+	if limit <= 0 {
+		limit = in.Page.Limit
+	}
+	if limit <= 0 {
+		limit = 20
+	}
+	limit = min(limit, 200)
+
+	var seed *uint64
+	switch {
+	case in.Seed != nil:
+		seed = in.Seed
+	case in.Random:
+		// a random-sample cursor pins its seed, so later pages don't need it resent
+		if c := strings.TrimSpace(in.Page.Cursor); c != "" {
+			sd, _, _, _, err := decodeRandomCursor(c)
+			if err != nil {
+				return domain.SamplesResp{}, perr.WithField(err, "page.cursor")
+			}
+			seed = &sd
+		} else {
+			sd := rand.Uint64()
+			seed = &sd
+		}
+	}
+
+	minSev, err := rulepack.ParseMinSeverity(in.MinSeverity)
+	if err != nil {
+		return domain.SamplesResp{}, perr.WithField(perr.InvalidArgf("%v", err), "min_severity")
+	}
+
+	f := sampleFilter{term: in.Term, minSev: minSev}
+	cards, err := s.samplePage(ctx, in.GlobalOptions, f, in.Page.Cursor, limit, seed)
+	if err != nil {
+		return domain.SamplesResp{}, err
+	}
+	if len(cards) == 0 {
+		return domain.SamplesResp{Items: []domain.SampleItem{}, Seed: seed}, nil
+	}
+
+	out := domain.SamplesResp{Items: make([]domain.SampleItem, 0, len(cards)), Seed: seed}
+	for _, c := range cards {
+		if in.MaxChars > 0 && len(c.spans) > 0 {
+			var rebase func([2]int) ([2]int, bool)
+			c.item.TextMasked, rebase = truncateAround(c.item.TextMasked, c.spans[0], in.MaxChars)
+			for i := range c.item.Hits {
+				kept := make([][2]int, 0, len(c.item.Hits[i].Spans))
+				for _, sp := range c.item.Hits[i].Spans {
+					if nsp, ok := rebase(sp); ok {
+						kept = append(kept, nsp)
+					}
+				}
+				c.item.Hits[i].Spans = kept
+			}
+		}
+		out.Items = append(out.Items, c.item)
+	}
+	if len(cards) == limit {
+		out.NextCursor = cards[len(cards)-1].cursor()
+	}
+	return out, nil
+}
+
+// sampleCard is one utterance folded from commit_crimes, with its text already masked
+type sampleCard struct {
+	item  domain.SampleItem
+	at    time.Time
+	spans [][2]int // every span across terms, for masking
+	seed  *uint64  // set when the page is a random sample
+	key   uint64   // samplekey.Key(utterance_id, *seed)
+}
+
+// cursor is the keyset position just after this card
+func (c sampleCard) cursor() string {
+	if c.seed != nil {
+		return encodeRandomCursor(*c.seed, c.key, c.item.UtteranceID, c.item.DetVer)
+	}
+	return encodeSampleCursor(c.at, c.item.UtteranceID, c.item.DetVer)
+}
+
+// sampleFilter narrows sample cards beyond GlobalOptions
+type sampleFilter struct {
+	term   string // cards must contain this term
+	minSev int    // cards must contain a hit of at least this severity rank (0 = any)
+}
+
+// samplePage reads one keyset page (created_at DESC, utterance_id DESC, detver DESC) of folded
+// cards; an utterance detected at several detvers is one card per detver, so detver is part of
+// the key. With a seed it pages a random sample instead (samplekey ASC, utterance_id ASC, detver ASC)
+// It is shared by Samples and the NDJSON export so both page and mask identically
+func (s *hybridStore) samplePage(
+	ctx context.Context,
+	g domain.GlobalOptions,
+	f sampleFilter,
+	cursor string,
+	limit int,
+	seed *uint64,
+) ([]sampleCard, error) {
+	startTS, endTS, err := sampleWindow(g)
+	if err != nil {
+		return nil, err
+	}
+	return s.sampleRangePage(ctx, g, startTS, endTS, f, cursor, limit, seed)
+}
+
+// sampleWindow is the half-open [start, end) timestamp window covered by g.Range's inclusive days
+func sampleWindow(g domain.GlobalOptions) (time.Time, time.Time, error) {
+	startDay, err := time.Parse("2006-01-02", g.Range.Start)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	endDay, err := time.Parse("2006-01-02", g.Range.End)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return startDay.UTC(), endDay.UTC().Add(24 * time.Hour), nil
+}
+
+// sampleRangePage is samplePage over an explicit half-open [startTS, endTS) window, so callers
+// that partition a window can scan adjacent sub-ranges without overlap
+func (s *hybridStore) sampleRangePage(
+	ctx context.Context,
+	g domain.GlobalOptions,
+	startTS, endTS time.Time,
+	f sampleFilter,
+	cursor string,
+	limit int,
+	seed *uint64,
+) ([]sampleCard, error) {
+	where := []string{
+		"created_at >= ?",
+		"created_at < ?",
+	}
+	args := []any{startTS, endTS}
+
+	keyExpr, order := "toUInt64(0)", "created_at DESC, utterance_id DESC, detver DESC"
+	var keyArgs []any
+	if seed != nil {
+		keyExpr, order = samplekey.SQL("utterance_id"), "sample_key ASC, utterance_id ASC, detver ASC"
+		keyArgs = []any{*seed}
+	}
+
+	if c := strings.TrimSpace(cursor); c != "" && seed != nil {
+		sd, key, uid, dv, err := decodeRandomCursor(c)
+		if err != nil {
+			return nil, perr.WithField(err, "page.cursor")
+		}
+		if sd != *seed {
+			return nil, perr.WithField(perr.InvalidArgf("cursor was issued for a different seed"), "page.cursor")
+		}
+		where = append(where, "("+keyExpr+" > ? OR ("+keyExpr+" = ? AND (utterance_id > toUUID(?)"+
+			" OR (utterance_id = toUUID(?) AND detver > ?))))")
+		args = append(args, *seed, key, *seed, key, uid, uid, dv)
+	} else if c != "" {
+		at, uid, dv, err := decodeSampleCursor(c)
+		if err != nil {
+			return nil, perr.WithField(err, "page.cursor")
+		}
+		where = append(where, "(created_at < ? OR (created_at = ? AND (utterance_id < toUUID(?)"+
+			" OR (utterance_id = toUUID(?) AND detver < ?))))")
+		args = append(args, at, at, uid, uid, dv)
+	}
+	if t := strings.TrimSpace(f.term); t != "" {
+		where = append(where, "term_id = ?")
+		args = append(args, termid.Hash(strings.ToLower(t))) // stored terms are casefolded
+	}
+	if dv := s.detvers(g.DetVer); len(dv) > 0 {
+		where = append(where, "detver IN ?")
+		args = append(args, dv)
+	}
+	if len(g.RepoHIDs) > 0 {
+		where = append(where, "repo_hid IN ?")
+		args = append(args, hidFilter(g.RepoHIDs))
+	}
+	if len(g.ActorHIDs) > 0 {
+		where = append(where, "actor_hid IN ?")
+		args = append(args, hidFilter(g.ActorHIDs))
+	}
+	if len(g.NLLangs) > 0 {
+		where = append(where, "lang_code IN ?")
+		args = append(args, g.NLLangs)
+	}
+	if g.LangReliable != nil {
+		if *g.LangReliable {
+			where = append(where, "lang_reliable = 1")
+		} else {
+			where = append(where, "lang_reliable = 0")
+		}
+	}
+
+	ex, err := s.exclusionsFor(ctx, g)
+	if err != nil {
+		return nil, err
+	}
+	where, args = ex.applyCrimes(where, args)
+
+	// The severity floor applies per card, not per row, so a qualifying card keeps its milder
+	// hits and their spans are still masked
+	having := ""
+	if f.minSev > 0 {
+		having = "HAVING max(toInt8(severity)) >= ?"
+		args = append(args, f.minSev)
+	}
+
+	// groupArray calls over the same rows keep a consistent order, so terms/sevs/starts/ends line up
+	sql := `
+		SELECT
+		  ` + keyExpr + `                 AS sample_key,
+		  toString(utterance_id)          AS uid,
+		  created_at,
+		  toString(source)                AS src,
+		  lower(hex(repo_hid))            AS repo_hex,
+		  lower(hex(actor_hid))           AS actor_hex,
+		  detver,
+		  groupArray(term)                AS terms,
+		  groupArray(toString(category))  AS cats,
+		  groupArray(toString(severity))  AS sevs,
+		  groupArray(span_start)          AS starts,
+		  groupArray(span_end)            AS ends
+		FROM swearjar.commit_crimes
+		WHERE ` + strings.Join(where, " AND ") + `
+		GROUP BY utterance_id, created_at, source, repo_hid, actor_hid, detver
+		` + having + `
+		ORDER BY ` + order + `
+		LIMIT ?
+	`
+	args = append(append(keyArgs, args...), limit)
+
+	rs, err := s.ch.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rs.Close()
+
+	cards := make([]sampleCard, 0, limit)
+	for rs.Next() {
+		var (
+			key                         uint64
+			uid, src, repoHex, actorHex string
+			at                          time.Time
+			detver                      int32
+			terms, cats, sevs           []string
+			starts, ends                []int32
+		)
+		err := rs.Scan(&key, &uid, &at, &src, &repoHex, &actorHex, &detver, &terms, &cats, &sevs, &starts, &ends)
+		if err != nil {
+			return nil, err
+		}
+		hits, spans := foldSampleHits(terms, cats, sevs, starts, ends)
+		cards = append(cards, sampleCard{
+			item: domain.SampleItem{
+				UtteranceID: uid,
+				CreatedAt:   at.UTC().Format(time.RFC3339),
+				Source:      src,
+				Repo:        domain.SampleRepo{HID: repoHex, Label: hidLabel(repoHex)},
+				Actor:       domain.SampleActor{HID: actorHex, Label: hidLabel(actorHex)},
+				Hits:        hits,
+				DetVer:      int(detver),
+			},
+			at:    at,
+			spans: spans,
+			seed:  seed,
+			key:   key,
+		})
+	}
+	if err := rs.Err(); err != nil {
+		return nil, err
+	}
+	if len(cards) == 0 {
+		return cards, nil
+	}
+
+	// Under ConsentedTextOnly, unconsented cards never have their text read
+	if s.textConsent {
+		repos, actors := make([]string, 0, len(cards)), make([]string, 0, len(cards))
+		for _, c := range cards {
+			repos, actors = append(repos, c.item.Repo.HID), append(actors, c.item.Actor.HID)
+		}
+		cs, err := s.consented(ctx, repos, actors)
+		if err != nil {
+			return nil, err
+		}
+		for i := range cards {
+			if !cs.covers(cards[i].item.Repo.HID, cards[i].item.Actor.HID) {
+				omitText(&cards[i].item)
+				cards[i].spans = nil
+			}
+		}
+	}
+
+	// Spans are offsets into the normalized text, so mask that (raw only as a fallback)
+	ids := make([]string, 0, len(cards))
+	for _, c := range cards {
+		if !c.item.TextOmitted {
+			ids = append(ids, c.item.UtteranceID)
+		}
+	}
+	if len(ids) == 0 {
+		return cards, nil
+	}
+	texts, err := s.sampleTexts(ctx, ids, startTS, endTS)
+	if err != nil {
+		return nil, err
+	}
+	for i := range cards {
+		if !cards[i].item.TextOmitted {
+			cards[i].item.TextMasked = maskSpans(texts[cards[i].item.UtteranceID], cards[i].spans)
+		}
+	}
+	return cards, nil
+}
+
+// sampleTexts loads utterance text for the given ids within the window
+func (s *hybridStore) sampleTexts(ctx context.Context, ids []string, start, end time.Time) (map[string]string, error) {
+	const sql = `
+		SELECT toString(id) AS uid, any(ifNull(text_normalized, text_raw)) AS txt
+		FROM swearjar.utterances
+		WHERE created_at >= ? AND created_at < ? AND id IN ?
+		GROUP BY id
+	`
+	rs, err := s.ch.Query(ctx, sql, start, end, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rs.Close()
+
+	out := make(map[string]string, len(ids))
+	for rs.Next() {
+		var uid, txt string
+		if err := rs.Scan(&uid, &txt); err != nil {
+			return nil, err
+		}
+		out[uid] = txt
+	}
+	return out, rs.Err()
+}
+
+// foldSampleHits groups per-span rows into one SampleHit per term with sorted, de-duplicated spans
+// It also returns the union of all spans for masking
+func foldSampleHits(terms, cats, sevs []string, starts, ends []int32) ([]domain.SampleHit, [][2]int) {
+	n := min(len(terms), len(cats), len(sevs), len(starts), len(ends))
+	byTerm := make(map[string]*domain.SampleHit, n)
+	order := make([]string, 0, n)
+	all := make([][2]int, 0, n)
+	for i := range n {
+		sp := [2]int{int(starts[i]), int(ends[i])}
+		h := byTerm[terms[i]]
+		if h == nil {
+			h = &domain.SampleHit{Term: terms[i], Category: cats[i], Severity: sevs[i]}
+			byTerm[terms[i]] = h
+			order = append(order, terms[i])
+		}
+		h.Spans = append(h.Spans, sp)
+		all = append(all, sp)
+	}
+
+	hits := make([]domain.SampleHit, 0, len(order))
+	for _, t := range order {
+		h := byTerm[t]
+		h.Spans = sortSpans(h.Spans)
+		hits = append(hits, *h)
+	}
+	// Present hits in reading order (first occurrence)
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].Spans[0][0] < hits[j].Spans[0][0] })
+	return hits, sortSpans(all)
+}
+
+// sortSpans orders spans by start then end and drops exact duplicates
+func sortSpans(xs [][2]int) [][2]int {
+	sort.Slice(xs, func(i, j int) bool {
+		if xs[i][0] != xs[j][0] {
+			return xs[i][0] < xs[j][0]
+		}
+		return xs[i][1] < xs[j][1]
+	})
+	out := xs[:0]
+	for _, sp := range xs {
+		if len(out) > 0 && sp == out[len(out)-1] {
+			continue
+		}
+		out = append(out, sp)
+	}
+	return out
+}
+
+// maskSpans keeps the first rune of every span and stars out the rest
+// Each masked rune becomes one '*' per byte, so byte offsets stay valid against the result
+func maskSpans(text string, spans [][2]int) string {
+	if text == "" || len(spans) == 0 {
+		return text
+	}
+	b := []byte(text)
+	for _, sp := range spans {
+		a, z := max(sp[0], 0), min(sp[1], len(b))
+		for a < z && !utf8.RuneStart(b[a]) {
+			a++
+		}
+		for z < len(b) && !utf8.RuneStart(b[z]) {
+			z++ // never split a rune at the tail
+		}
+		if a >= z {
+			continue
+		}
+		_, w := utf8.DecodeRune(b[a:z])
+		for i := a + w; i < z; i++ {
+			b[i] = '*'
+		}
+	}
+	return string(b)
+}
+
+const ellipsis = "…"
+
+// truncateAround cuts text to roughly maxChars runes centered on focus (a byte span),
+// snapping both edges to whitespace and marking cuts with an ellipsis. The focus span is
+// always kept whole even if it alone exceeds maxChars. The returned rebase func maps a
+// byte span of the input onto the output, reporting false for spans that were cut away
+func truncateAround(text string, focus [2]int, maxChars int) (string, func([2]int) ([2]int, bool)) {
+	identity := func(sp [2]int) ([2]int, bool) { return sp, true }
+	n := utf8.RuneCountInString(text)
+	if maxChars <= 0 || n <= maxChars {
+		return text, identity
+	}
+
+	// rune index -> byte offset (starts[n] == len(text))
+	starts := make([]int, 0, n+1)
+	for i := range text {
+		starts = append(starts, i)
+	}
+	starts = append(starts, len(text))
+	runeAt := func(b int) int { // first rune starting at or after byte b
+		return sort.SearchInts(starts, b)
+	}
+
+	fa := runeAt(max(0, min(focus[0], len(text))))
+	fb := max(runeAt(max(0, min(focus[1], len(text)))), fa)
+
+	// Budget leaves room for the two ellipses; split the slack evenly around the focus
+	budget := max(maxChars-2, fb-fa)
+	slack := budget - (fb - fa)
+	lo := fa - slack/2
+	hi := fb + (slack - slack/2)
+	if lo < 0 {
+		hi, lo = min(n, hi-lo), 0
+	}
+	if hi > n {
+		lo, hi = max(0, lo-(hi-n)), n
+	}
+
+	// Snap to word boundaries without eating into the focus
+	isSpace := func(r int) bool {
+		c, _ := utf8.DecodeRuneInString(text[starts[r]:])
+		return unicode.IsSpace(c)
+	}
+	if lo > 0 && !isSpace(lo-1) {
+		for j := lo; j < fa; j++ {
+			if isSpace(j) {
+				lo = j + 1
+				break
+			}
+		}
+	}
+	if hi < n && !isSpace(hi) {
+		for j := hi - 1; j >= fb; j-- {
+			if isSpace(j) {
+				hi = j
+				break
+			}
+		}
+	}
+
+	loB, hiB := starts[lo], starts[hi]
+	body := strings.TrimSpace(text[loB:hiB])
+	lead := len(text[loB:hiB]) - len(strings.TrimLeftFunc(text[loB:hiB], unicode.IsSpace))
+	loB += lead
+	hiB = loB + len(body)
+
+	var b strings.Builder
+	shift := -loB
+	if loB > 0 {
+		b.WriteString(ellipsis)
+		shift += len(ellipsis)
+	}
+	b.WriteString(body)
+	if hiB < len(text) {
+		b.WriteString(ellipsis)
+	}
+
+	rebase := func(sp [2]int) ([2]int, bool) {
+		if sp[0] < loB || sp[1] > hiB {
+			return sp, false
+		}
+		return [2]int{sp[0] + shift, sp[1] + shift}, true
+	}
+	return b.String(), rebase
+}
+
+// hidLabel shortens a hex HID for display, e.g. "abc…def"
+func hidLabel(hexHID string) string {
+	if len(hexHID) <= 6 {
+		return hexHID
+	}
+	return hexHID[:3] + "…" + hexHID[len(hexHID)-3:]
+}
+
+// hidFilter decodes hex HID filters once so predicates compare the raw FixedString(32) column
+// (repo_hid IN ?) instead of hex-encoding every row. GlobalOptions are canonicalized at the
+// service boundary, so every entry decodes; one that somehow doesn't is dropped
+func hidFilter(hexes []string) [][]byte {
+	out := make([][]byte, 0, len(hexes))
+	for _, h := range hexes {
+		if b, err := hex.DecodeString(h); err == nil {
+			out = append(out, b)
+		}
+	}
+	return out
+}
+
+// sample cursors are opaque base64url("<unix_ms>|<utterance_id>|<detver>")
+func encodeSampleCursor(at time.Time, uid string, detver int) string {
+	raw := strconv.FormatInt(at.UnixMilli(), 10) + "|" + uid + "|" + strconv.Itoa(detver)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// random sample cursors are opaque base64url("r|<seed>|<key>|<utterance_id>|<detver>")
+func encodeRandomCursor(seed, key uint64, uid string, detver int) string {
+	raw := "r|" + strconv.FormatUint(seed, 10) + "|" + strconv.FormatUint(key, 10) + "|" + uid +
+		"|" + strconv.Itoa(detver)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// cursors issued before detver joined the key resume past every detver of their utterance,
+// as they did then: ascending pages start above the largest detver, descending below the smallest
+const (
+	legacyCursorDetverAsc  = math.MaxInt32
+	legacyCursorDetverDesc = 0
+)
+
+func decodeRandomCursor(c string) (seed, key uint64, uid string, detver int, err error) {
+	bad := perr.InvalidArgf("invalid cursor")
+	raw, err := base64.RawURLEncoding.DecodeString(c)
+	if err != nil {
+		return 0, 0, "", 0, bad
+	}
+	parts := strings.Split(string(raw), "|")
+	if (len(parts) != 4 && len(parts) != 5) || parts[0] != "r" || parts[3] == "" {
+		return 0, 0, "", 0, bad
+	}
+	if seed, err = strconv.ParseUint(parts[1], 10, 64); err != nil {
+		return 0, 0, "", 0, bad
+	}
+	if key, err = strconv.ParseUint(parts[2], 10, 64); err != nil {
+		return 0, 0, "", 0, bad
+	}
+	detver = legacyCursorDetverAsc
+	if len(parts) == 5 {
+		if detver, err = strconv.Atoi(parts[4]); err != nil {
+			return 0, 0, "", 0, bad
+		}
+	}
+	return seed, key, parts[3], detver, nil
+}
+
+func decodeSampleCursor(c string) (time.Time, string, int, error) {
+	bad := perr.InvalidArgf("invalid cursor")
+	raw, err := base64.RawURLEncoding.DecodeString(c)
+	if err != nil {
+		return time.Time{}, "", 0, bad
+	}
+	parts := strings.Split(string(raw), "|")
+	if (len(parts) != 2 && len(parts) != 3) || parts[1] == "" {
+		return time.Time{}, "", 0, bad
+	}
+	n, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return time.Time{}, "", 0, bad
+	}
+	detver := legacyCursorDetverDesc
+	if len(parts) == 3 {
+		if detver, err = strconv.Atoi(parts[2]); err != nil {
+			return time.Time{}, "", 0, bad
+		}
+	}
+	return time.UnixMilli(n).UTC(), parts[1], detver, nil
+}
@@ -0,0 +1,141 @@
+package repo
+
+import (
+	"context"
+	"encoding/base64"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"swearjar/internal/services/api/swearjar/domain"
+)
+
+func TestSampleCursorRoundTrip(t *testing.T) {
+	at := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	gotAt, uid, dv, err := decodeSampleCursor(encodeSampleCursor(at, "u-1", 3))
+	if err != nil || !gotAt.Equal(at) || uid != "u-1" || dv != 3 {
+		t.Fatalf("sample cursor: got %v %q %d %v", gotAt, uid, dv, err)
+	}
+
+	seed, key, uid, dv, err := decodeRandomCursor(encodeRandomCursor(7, 42, "u-2", 2))
+	if err != nil || seed != 7 || key != 42 || uid != "u-2" || dv != 2 {
+		t.Fatalf("random cursor: got %d %d %q %d %v", seed, key, uid, dv, err)
+	}
+}
+
+func TestSampleCursorLegacyFormats(t *testing.T) {
+	enc := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+
+	if _, uid, dv, err := decodeSampleCursor(enc("1740830400000|u-1")); err != nil || uid != "u-1" ||
+		dv != legacyCursorDetverDesc {
+		t.Fatalf("legacy sample cursor: got %q %d %v", uid, dv, err)
+	}
+	if _, _, uid, dv, err := decodeRandomCursor(enc("r|7|42|u-2")); err != nil || uid != "u-2" ||
+		dv != legacyCursorDetverAsc {
+		t.Fatalf("legacy random cursor: got %q %d %v", uid, dv, err)
+	}
+	for _, bad := range []string{enc("1740830400000|u-1|x"), enc("1740830400000|"), enc("1|u|2|3"), "%%"} {
+		if _, _, _, err := decodeSampleCursor(bad); err == nil {
+			t.Fatalf("sample cursor %q: want an invalid cursor error", bad)
+		}
+	}
+	for _, bad := range []string{enc("r|7|42|u-2|x"), enc("r|7|42|"), enc("x|7|42|u-2"), "%%"} {
+		if _, _, _, _, err := decodeRandomCursor(bad); err == nil {
+			t.Fatalf("random cursor %q: want an invalid cursor error", bad)
+		}
+	}
+}
+
+func TestSamplePageKeysOnDetver(t *testing.T) {
+	at := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	g := domain.GlobalOptions{Range: domain.TimeRange{Start: "2025-03-01", End: "2025-03-01"}}
+	seed := uint64(7)
+
+	cases := []struct {
+		name   string
+		cursor string
+		seed   *uint64
+		cond   string
+		order  string
+		want   []any // trailing cursor args: utterance_id, utterance_id, detver
+	}{
+		{"newest first", encodeSampleCursor(at, "u-1", 2), nil,
+			"utterance_id = toUUID(?) AND detver < ?", "utterance_id DESC, detver DESC", []any{"u-1", "u-1", 2}},
+		{"random", encodeRandomCursor(seed, 42, "u-1", 2), &seed,
+			"utterance_id = toUUID(?) AND detver > ?", "utterance_id ASC, detver ASC", []any{"u-1", "u-1", 2}},
+	}
+	for _, tc := range cases {
+		ch := &fakeCH{results: []fakeResult{{match: "swearjar.commit_crimes"}}}
+		s := newTestStore(ch)
+		if _, err := s.samplePage(context.Background(), g, sampleFilter{}, tc.cursor, 10, tc.seed); err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		c, ok := ch.call("swearjar.commit_crimes")
+		if !ok {
+			t.Fatalf("%s: no commit_crimes query", tc.name)
+		}
+		if !strings.Contains(c.sql, tc.cond) || !strings.Contains(c.sql, tc.order) {
+			t.Fatalf("%s: keyset ignores detver:\n%s", tc.name, c.sql)
+		}
+		if !containsRun(c.args, tc.want) {
+			t.Fatalf("%s: args %v missing cursor position %v", tc.name, c.args, tc.want)
+		}
+	}
+}
+
+// containsRun reports whether want appears as a contiguous run in args
+func containsRun(args, want []any) bool {
+	for i := 0; i+len(want) <= len(args); i++ {
+		if slices.Equal(args[i:i+len(want)], want) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestTruncateAround(t *testing.T) {
+	t.Parallel()
+
+	type span = [2]int
+	cases := []struct {
+		name     string
+		text     string
+		focus    span
+		maxChars int
+		want     string
+		wantHit  span   // focus rebased onto the output
+		dropped  []span // input spans cut away
+	}{
+		{"fits", "this build is shit", span{14, 18}, 18, "this build is shit", span{14, 18}, nil},
+		{"unbounded", "this build is shit", span{14, 18}, 0, "this build is shit", span{14, 18}, nil},
+		{"snaps to words", "the quick brown fox shit jumps over lazy dogs", span{20, 24}, 16,
+			"…fox shit…", span{7, 11}, []span{{4, 9}, {25, 30}}},
+		{"hit at start", "shit happens every single day here", span{0, 4}, 12,
+			"shit…", span{0, 4}, []span{{5, 12}}},
+		{"hit at end", "all day long this is shit", span{21, 25}, 12,
+			"…is shit", span{6, 10}, []span{{13, 17}}},
+		{"no space falls back to runes", "aaaaaaaaaashitaaaaaaaaaa", span{10, 14}, 8,
+			"…ashita…", span{4, 8}, []span{{0, 9}, {14, 16}}},
+		{"multibyte around the cut", "éééééshitééééé", span{10, 14}, 8,
+			"…éshité…", span{5, 9}, []span{{6, 8}, {16, 18}}},
+		{"focus wider than budget", "xx shitshitshit yy", span{3, 15}, 5,
+			"…shitshitshit…", span{3, 15}, []span{{0, 2}}},
+	}
+	for _, tc := range cases {
+		got, rebase := truncateAround(tc.text, tc.focus, tc.maxChars)
+		if got != tc.want || !utf8.ValidString(got) {
+			t.Fatalf("%s: got %q, want %q", tc.name, got, tc.want)
+		}
+		hit, ok := rebase(tc.focus)
+		if !ok || hit != tc.wantHit || got[hit[0]:hit[1]] != tc.text[tc.focus[0]:tc.focus[1]] {
+			t.Fatalf("%s: focus rebased to %v (%v), want %v", tc.name, hit, ok, tc.wantHit)
+		}
+		for _, sp := range tc.dropped {
+			if _, ok := rebase(sp); ok {
+				t.Fatalf("%s: span %v was cut away but still rebased", tc.name, sp)
+			}
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package repo
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"swearjar/internal/services/api/swearjar/domain"
+)
+
+// searchLikeEscaper escapes LIKE metacharacters so the query is matched literally
+var searchLikeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// Search matches opted-in repo full_names / actor logins by prefix or substring (case-insensitive)
+// Only rows joined to an active opt_in receipt are considered, so non-consented names can never
+// surface; excluded principals are dropped too. Exact matches rank first, then prefix matches
+// (for repos, a prefix of the name after the owner also counts), then substrings; shorter names win ties
+func (s *hybridStore) Search(ctx context.Context, in domain.SearchInput) (domain.SearchResp, error) {
+	q := strings.ToLower(strings.TrimSpace(in.Query))
+	limit := in.Limit
+	if limit <= 0 {
+		limit = 10
+	}
+
+	ex, err := s.exclusions(ctx)
+	if err != nil {
+		return domain.SearchResp{}, err
+	}
+
+	var parts []string
+	args := []any{q, searchLikeEscaper.Replace(q)}
+	if in.Kind == "" || in.Kind == "repo" {
+		p := `
+			SELECT 'repo' AS kind, r.repo_hid AS hid, r.full_name AS name,
+				CASE
+					WHEN lower(r.full_name) = $1 THEN 0
+					WHEN lower(r.full_name) LIKE $2 || '%' THEN 1
+					WHEN lower(split_part(r.full_name, '/', 2)) LIKE $2 || '%' THEN 1
+					ELSE 2
+				END AS rank
+			FROM repositories r
+			JOIN consent_receipts c ON c.consent_id = r.consent_id
+			WHERE c.principal = 'repo' AND c.action = 'opt_in' AND c.state = 'active'
+				AND r.full_name IS NOT NULL
+				AND lower(r.full_name) LIKE '%' || $2 || '%'`
+		if len(ex.repos) > 0 {
+			args = append(args, ex.repos)
+			p += fmt.Sprintf(` AND r.repo_hid <> ALL($%d)`, len(args))
+		}
+		parts = append(parts, p)
+	}
+	if in.Kind == "" || in.Kind == "actor" {
+		p := `
+			SELECT 'actor' AS kind, a.actor_hid AS hid, a.login AS name,
+				CASE
+					WHEN lower(a.login) = $1 THEN 0
+					WHEN lower(a.login) LIKE $2 || '%' THEN 1
+					ELSE 2
+				END AS rank
+			FROM actors a
+			JOIN consent_receipts c ON c.consent_id = a.consent_id
+			WHERE c.principal = 'actor' AND c.action = 'opt_in' AND c.state = 'active'
+				AND a.login IS NOT NULL
+				AND lower(a.login) LIKE '%' || $2 || '%'`
+		if len(ex.actors) > 0 {
+			args = append(args, ex.actors)
+			p += fmt.Sprintf(` AND a.actor_hid <> ALL($%d)`, len(args))
+		}
+		parts = append(parts, p)
+	}
+	args = append(args, limit)
+
+	rs, err := s.pg.Query(ctx, fmt.Sprintf(`
+		SELECT kind, hid, name, rank FROM (%s) m
+		ORDER BY rank ASC, length(name) ASC, name ASC
+		LIMIT $%d
+	`, strings.Join(parts, "\n\t\t\tUNION ALL\n"), len(args)), args...)
+	if err != nil {
+		return domain.SearchResp{}, fmt.Errorf("search opted-in names: %w", err)
+	}
+	defer rs.Close()
+
+	out := domain.SearchResp{Items: make([]domain.SearchItem, 0, limit)}
+	for rs.Next() {
+		var (
+			it   domain.SearchItem
+			hid  []byte
+			rank int
+		)
+		if err := rs.Scan(&it.Kind, &hid, &it.Name, &rank); err != nil {
+			return domain.SearchResp{}, fmt.Errorf("scan search row: %w", err)
+		}
+		it.HID = hex.EncodeToString(hid)
+		it.Match = "substring"
+		switch rank {
+		case 0:
+			it.Match = "exact"
+		case 1:
+			it.Match = "prefix"
+		}
+		out.Items = append(out.Items, it)
+	}
+	if err := rs.Err(); err != nil {
+		return domain.SearchResp{}, fmt.Errorf("search opted-in names: %w", err)
+	}
+	return out, nil
+}
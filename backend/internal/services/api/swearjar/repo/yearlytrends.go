@@ -8,6 +8,7 @@ import (
 	"strings"
 	"time"
 
+	"swearjar/internal/core/rulepack"
 	"swearjar/internal/services/api/swearjar/domain"
 )
 
@@ -22,33 +23,53 @@ func (s *hybridStore) YearlyTrends(
 	)
 	// Resolve year bounds with caps (default from data min/max)
 	// We take bounds primarily from crimes (commit_crimes); if absent, fall back to utt_hour_agg
+	// The fallback is optional: if utt_hour_agg errors, bounds simply stay empty
 
 	type bounds struct{ minY, maxY int }
+	scanBounds := func(ctx context.Context, sql string, b *bounds) error {
+		rs, err := s.ch.Query(ctx, sql)
+		if err != nil {
+			return err
+		}
+		defer rs.Close()
+		if rs.Next() {
+			var lo, hi uint16 // toYear is UInt16
+			if err := rs.Scan(&lo, &hi); err != nil {
+				return err
+			}
+			b.minY, b.maxY = int(lo), int(hi)
+		}
+		return rs.Err()
+	}
 	getBounds := func(ctx context.Context) (bounds, error) {
 		// Scope-less min/max first (filtered min/max can be quite expensive)
 		var b bounds
-		// Prefer commit_crimes
-		rs, err := s.ch.Query(ctx, `
-			WITH c AS (SELECT min(toYear(created_at)) AS ymin, max(toYear(created_at)) AS ymax FROM swearjar.commit_crimes),
-			     u AS (SELECT min(toYear(bucket_hour)) AS ymin, max(toYear(bucket_hour)) AS ymax FROM swearjar.utt_hour_agg)
+		// Prefer commit_crimes; min/max over an empty table are 0
+		err := scanBounds(ctx, `
 			SELECT
-			  coalesce((SELECT ymin FROM c), (SELECT ymin FROM u), 0) AS ymin,
-			  coalesce((SELECT ymax FROM c), (SELECT ymax FROM u), 0) AS ymax
-		`)
+			  min(toYear(created_at)) AS ymin,
+			  max(toYear(created_at)) AS ymax
+			FROM swearjar.commit_crimes
+		`, &b)
 		if err != nil {
 			return b, err
 		}
-		defer rs.Close()
-		if rs.Next() {
-			var ymin16, ymax16 uint16
-			if err := rs.Scan(&ymin16, &ymax16); err != nil {
+		if b.minY == 0 || b.maxY == 0 {
+			var ub bounds
+			failed, err := optionalQuery(ctx, "yearly_trends_bounds", func() error {
+				return scanBounds(ctx, `
+					SELECT
+					  min(toYear(bucket_hour)) AS ymin,
+					  max(toYear(bucket_hour)) AS ymax
+					FROM swearjar.utt_hour_agg
+				`, &ub)
+			})
+			if err != nil {
 				return b, err
 			}
-			b.minY = int(ymin16)
-			b.maxY = int(ymax16)
-		}
-		if err := rs.Err(); err != nil {
-			return b, err
+			if !failed {
+				b = ub
+			}
 		}
 		if b.minY == 0 || b.maxY == 0 || b.maxY < b.minY {
 			// no data - return empty span; caller will handle
@@ -107,17 +128,18 @@ func (s *hybridStore) YearlyTrends(
 		time.Date(minY, 1, 1, 0, 0, 0, 0, time.UTC),
 		time.Date(maxY+1, 1, 1, 0, 0, 0, 0, time.UTC),
 	}
-	if len(in.DetVer) > 0 {
+	if dv := s.detvers(in.DetVer); len(dv) > 0 {
 		crWhere = append(crWhere, "detver IN ?")
-		crArgs = append(crArgs, in.DetVer)
+		crArgs = append(crArgs, dv)
 	}
+	repoHIDs, actorHIDs := hidFilter(in.RepoHIDs), hidFilter(in.ActorHIDs)
 	if len(in.RepoHIDs) > 0 {
 		crWhere = append(crWhere, "repo_hid IN ?")
-		crArgs = append(crArgs, in.RepoHIDs)
+		crArgs = append(crArgs, repoHIDs)
 	}
 	if len(in.ActorHIDs) > 0 {
 		crWhere = append(crWhere, "actor_hid IN ?")
-		crArgs = append(crArgs, in.ActorHIDs)
+		crArgs = append(crArgs, actorHIDs)
 	}
 	if len(in.NLLangs) > 0 {
 		crWhere = append(crWhere, "lang_code IN ?")
@@ -142,11 +164,11 @@ func (s *hybridStore) YearlyTrends(
 	// Mirror feasible filters onto utt_hour_agg
 	if len(in.RepoHIDs) > 0 {
 		utWhere = append(utWhere, "repo_hid IN ?")
-		utArgs = append(utArgs, in.RepoHIDs)
+		utArgs = append(utArgs, repoHIDs)
 	}
 	if len(in.ActorHIDs) > 0 {
 		utWhere = append(utWhere, "actor_hid IN ?")
-		utArgs = append(utArgs, in.ActorHIDs)
+		utArgs = append(utArgs, actorHIDs)
 	}
 	if len(in.NLLangs) > 0 {
 		utWhere = append(utWhere, "lang_code IN ?")
@@ -159,42 +181,34 @@ func (s *hybridStore) YearlyTrends(
 			utWhere = append(utWhere, "lang_reliable = 0")
 		}
 	}
-	// Monthly aggregates: crimes + utterances (UTC months)
-	sqlMonthly := fmt.Sprintf(`
-		WITH
-		cr AS (
-			SELECT
-			  toStartOfMonth(created_at)                     AS month,
-			  count()                                        AS hits,
-			  sumIf(1, severity = 'mild')                    AS mild_hits,
-			  sumIf(1, severity = 'strong')                  AS strong_hits,
-			  sumIf(1, severity = 'slur_masked')             AS slur_hits
-			FROM swearjar.commit_crimes
-			WHERE %s
-			GROUP BY month
-		),
-		ut AS (
-			SELECT
-			  toStartOfMonth(bucket_hour)                     AS month,
-			  uniqMerge(u_state)                              AS utt
-			FROM swearjar.utt_hour_agg
-			WHERE %s
-			GROUP BY month
-		)
-		SELECT
-		  coalesce(cr.month, ut.month) AS month,
-		  ifNull(cr.hits, 0)           AS hits,
-		  ifNull(cr.mild_hits, 0)      AS mild_hits,
-		  ifNull(cr.strong_hits, 0)    AS strong_hits,
-		  ifNull(cr.slur_hits, 0)      AS slur_hits,
-		  ifNull(ut.utt, 0)            AS utt
-		FROM cr
-		FULL OUTER JOIN ut USING month
-		ORDER BY month ASC
-	`, strings.Join(crWhere, " AND "), strings.Join(utWhere, " AND "))
+	ex, err := s.exclusionsFor(ctx, in.GlobalOptions)
+	if err != nil {
+		return domain.YearlyTrendsResp{}, err
+	}
+	crWhere, crArgs = ex.applyCrimes(crWhere, crArgs)
+	utWhere, utArgs = ex.apply(utWhere, utArgs)
 
-	args := append([]any{}, crArgs...)
-	args = append(args, utArgs...)
+	// Monthly aggregates (UTC months): crimes from commit_crimes, utterances from utt_hour_agg
+	// The utterance query is isolated so a failing aggregate degrades to Partial (rates zeroed)
+	sqlCrimes := fmt.Sprintf(`
+		SELECT
+		  toStartOfMonth(created_at)                     AS month,
+		  count()                                        AS hits,
+		  sumIf(1, severity = 'mild')                    AS mild_hits,
+		  sumIf(1, severity = 'strong')                  AS strong_hits,
+		  sumIf(1, severity = 'slur_masked')             AS slur_hits
+		FROM swearjar.commit_crimes
+		WHERE %s
+		GROUP BY month
+	`, strings.Join(crWhere, " AND "))
+	sqlUtt := fmt.Sprintf(`
+		SELECT
+		  toStartOfMonth(bucket_hour)                     AS month,
+		  uniqMerge(u_state)                              AS utt
+		FROM swearjar.utt_hour_agg
+		WHERE %s
+		GROUP BY month
+	`, strings.Join(utWhere, " AND "))
 
 	type mrow struct {
 		month  time.Time
@@ -204,11 +218,50 @@ func (s *hybridStore) YearlyTrends(
 		slur   uint64
 		utt    uint64
 	}
-	rs, err := s.ch.Query(ctx, sqlMonthly, args...)
+	rows := make(map[time.Time]mrow)
+	rs, err := s.ch.Query(ctx, sqlCrimes, crArgs...)
 	if err != nil {
 		return domain.YearlyTrendsResp{}, err
 	}
 	defer rs.Close()
+	for rs.Next() {
+		var r mrow
+		if err := rs.Scan(&r.month, &r.hits, &r.mild, &r.strong, &r.slur); err != nil {
+			return domain.YearlyTrendsResp{}, err
+		}
+		rows[r.month.UTC()] = r
+	}
+	if err := rs.Err(); err != nil {
+		return domain.YearlyTrendsResp{}, err
+	}
+
+	uttByMonth := make(map[time.Time]uint64)
+	partial, err := optionalQuery(ctx, "yearly_trends_utterances", func() error {
+		urs, err := s.ch.Query(ctx, sqlUtt, utArgs...)
+		if err != nil {
+			return err
+		}
+		defer urs.Close()
+		for urs.Next() {
+			var m time.Time
+			var n uint64
+			if err := urs.Scan(&m, &n); err != nil {
+				return err
+			}
+			uttByMonth[m.UTC()] = n
+		}
+		return urs.Err()
+	})
+	if err != nil {
+		return domain.YearlyTrendsResp{}, err
+	}
+	if !partial {
+		for m, n := range uttByMonth {
+			r := rows[m]
+			r.month, r.utt = m, n
+			rows[m] = r
+		}
+	}
 
 	// Allocate dense 12-per-year vectors up front
 	years := make([]int, 0, maxY-minY+1)
@@ -234,11 +287,7 @@ func (s *hybridStore) YearlyTrends(
 	// also keep for seasonality computation across years
 	byYM := make(map[[2]int]perMonth, (maxY-minY+1)*12)
 
-	for rs.Next() {
-		var r mrow
-		if err := rs.Scan(&r.month, &r.hits, &r.mild, &r.strong, &r.slur, &r.utt); err != nil {
-			return domain.YearlyTrendsResp{}, err
-		}
+	for _, r := range rows {
 		y := r.month.UTC().Year()
 		m := int(r.month.UTC().Month()) // 1..12
 		if y < minY || y > maxY || m < 1 || m > 12 {
@@ -258,14 +307,11 @@ func (s *hybridStore) YearlyTrends(
 		if pm.utt > 0 {
 			rateByY[y][m-1] = float64(pm.hits) / float64(pm.utt)
 		}
-		totalSev := pm.mild + pm.strong + pm.slur
-		if totalSev > 0 {
-			mean := (1.0*float64(pm.mild) + 2.0*float64(pm.strong) + 3.0*float64(pm.slur)) / float64(totalSev)
-			seviByY[y][m-1] = mean
-		}
-	}
-	if err := rs.Err(); err != nil {
-		return domain.YearlyTrendsResp{}, err
+		seviByY[y][m-1] = s.weights.Index(map[string]int64{
+			rulepack.SeverityMild:       pm.mild,
+			rulepack.SeverityStrong:     pm.strong,
+			rulepack.SeveritySlurMasked: pm.slur,
+		})
 	}
 
 	// Seasonality bands (median/p25/p75 over selected years)
@@ -412,31 +458,45 @@ func (s *hybridStore) YearlyTrends(
 	}
 
 	// Detector version markers (first seen per detver)
-	// Typically scope-less; but if you *want* scoped markers, reuse crWhere
-	rs3, err := s.ch.Query(ctx, `
+	// Global by default; ScopedMarkers reuses the scope predicates from crWhere (minus the date
+	// window, so "first seen" stays a true first appearance rather than the start of the range).
+	// Either way markers are clamped to the displayed years
+	mkWhere := "1"
+	mkArgs := []any{}
+	if in.ScopedMarkers && len(crWhere) > 1 {
+		mkWhere = strings.Join(crWhere[1:], " AND ")
+		mkArgs = append(mkArgs, crArgs[2:]...)
+	}
+	mkArgs = append(mkArgs,
+		time.Date(minY, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(maxY+1, 1, 1, 0, 0, 0, 0, time.UTC),
+	)
+	rs3, err := s.ch.Query(ctx, fmt.Sprintf(`
 		WITH firsts AS (
 			SELECT detver AS v, min(toDate(created_at)) AS first_day
 			FROM swearjar.commit_crimes
+			WHERE %s
 			GROUP BY v
 		)
 		SELECT v, first_day
 		FROM firsts
+		WHERE first_day >= toDate(?) AND first_day < toDate(?)
 		ORDER BY first_day ASC
-	`)
+	`, mkWhere), mkArgs...)
 	if err != nil {
 		return domain.YearlyTrendsResp{}, err
 	}
 	defer rs3.Close()
 	markers := make([]domain.DetverMarker, 0, 16)
 	for rs3.Next() {
-		var ver32 int32
+		var ver int32 // detver is Int32
 		var day time.Time
-		if err := rs3.Scan(&ver32, &day); err != nil {
+		if err := rs3.Scan(&ver, &day); err != nil {
 			return domain.YearlyTrendsResp{}, err
 		}
 		markers = append(markers, domain.DetverMarker{
 			Date:    day.Format("2006-01-02"),
-			Version: int(ver32),
+			Version: int(ver),
 		})
 	}
 	if err := rs3.Err(); err != nil {
@@ -458,6 +518,7 @@ func (s *hybridStore) YearlyTrends(
 		Seasonality:   seasonality,
 		Mix:           mix,
 		DetverMarkers: markers,
+		Partial:       partial,
 	}
 	out.Meta.DataMinYear = b.minY
 	out.Meta.DataMaxYear = b.maxY
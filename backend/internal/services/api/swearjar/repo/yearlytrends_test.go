@@ -0,0 +1,175 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"swearjar/internal/core/rulepack"
+	"swearjar/internal/services/api/swearjar/domain"
+)
+
+const (
+	ytCrimeBounds = "min(toYear(created_at))"
+	ytUttBounds   = "min(toYear(bucket_hour))"
+	ytCrimes      = "sumIf(1, severity = 'mild')"
+	ytUtt         = "uniqMerge(u_state)"
+	ytMix         = "cast(category AS Nullable(String))"
+	ytMarkers     = "WITH firsts AS"
+)
+
+func strp(s string) *string { return &s }
+
+func yearlyResults(uttErr error) []fakeResult {
+	return []fakeResult{
+		{match: ytCrimeBounds, cols: []string{"ymin", "ymax"}, data: [][]any{{uint16(2022), uint16(2023)}}},
+		{match: ytCrimes, cols: []string{"month", "hits", "mild_hits", "strong_hits", "slur_hits"}, data: [][]any{
+			{day("2023-03-01"), uint64(10), uint64(6), uint64(4), uint64(0)},
+			{day("2022-12-01"), uint64(2), uint64(2), uint64(0), uint64(0)},
+		}},
+		{match: ytUtt, err: uttErr, cols: []string{"month", "utt"}, data: [][]any{
+			{day("2023-03-01"), uint64(40)},
+			{day("2023-04-01"), uint64(8)}, // utterances without hits
+		}},
+		{match: ytMix, cols: []string{"y", "cat", "hits"}, data: [][]any{
+			{uint16(2023), strp("bot_rage"), uint64(6)},
+			{uint16(2023), (*string)(nil), uint64(4)},
+			{uint16(2022), strp("generic"), uint64(2)},
+		}},
+		{match: ytMarkers, cols: []string{"v", "first_day"}, data: [][]any{
+			{int32(2), day("2023-03-05")},
+		}},
+	}
+}
+
+func TestYearlyTrends_MonthlyRatesMixAndMarkers(t *testing.T) {
+	t.Parallel()
+
+	ch := &fakeCH{results: yearlyResults(nil)}
+	s := newTestStore(ch)
+
+	resp, err := s.YearlyTrends(context.Background(), domain.YearlyTrendsInput{})
+	if err != nil {
+		t.Fatalf("YearlyTrends err: %v", err)
+	}
+	if !reflect.DeepEqual(resp.Years, []int{2022, 2023}) || resp.Partial {
+		t.Fatalf("years=%v partial=%v", resp.Years, resp.Partial)
+	}
+	if resp.Meta.DataMinYear != 2022 || resp.Meta.DataMaxYear != 2023 || resp.Meta.Interval != "month" {
+		t.Fatalf("meta %+v", resp.Meta)
+	}
+
+	// every year gets a dense 12-month vector
+	for _, y := range resp.Years {
+		if len(resp.Monthly.Hits[y]) != 12 || len(resp.Monthly.Rate[y]) != 12 || len(resp.Monthly.Severity[y]) != 12 {
+			t.Fatalf("year %d vectors not 12 wide", y)
+		}
+	}
+	if got := resp.Monthly.Hits[2023][2]; got != 10 {
+		t.Fatalf("march 2023 hits %d", got)
+	}
+	if got := resp.Monthly.Rate[2023][2]; got != 0.25 {
+		t.Fatalf("march 2023 rate %v want 0.25", got)
+	}
+	if got := resp.Monthly.Rate[2023][3]; got != 0 {
+		t.Fatalf("april 2023 rate %v, want 0 with no hits", got)
+	}
+	if got := resp.Monthly.Rate[2022][11]; got != 0 {
+		t.Fatalf("december 2022 rate %v, want 0 without utterances", got)
+	}
+	wantSev := s.weights.Index(map[string]int64{rulepack.SeverityMild: 6, rulepack.SeverityStrong: 4})
+	if got := resp.Monthly.Severity[2023][2]; got != wantSev {
+		t.Fatalf("march 2023 severity %v want %v", got, wantSev)
+	}
+
+	// two years: the march median sits halfway between 0 and 10
+	if band := resp.Seasonality["hits"][2]; band.M != 3 || band.Median != 5 || band.P25 != 2.5 || band.P75 != 7.5 {
+		t.Fatalf("march hits band %+v", band)
+	}
+
+	if resp.Mix == nil {
+		t.Fatal("expected a mix snapshot")
+	}
+	wantThis := []domain.CategoryShare{{Key: "bot_rage", Hits: 6, Share: 0.6}, {Key: "unknown", Hits: 4, Share: 0.4}}
+	if !reflect.DeepEqual(resp.Mix.ThisYear, wantThis) {
+		t.Fatalf("this year mix %+v", resp.Mix.ThisYear)
+	}
+	wantLast := []domain.CategoryShare{{Key: "generic", Hits: 2, Share: 1}}
+	if !reflect.DeepEqual(resp.Mix.LastYear, wantLast) {
+		t.Fatalf("last year mix %+v", resp.Mix.LastYear)
+	}
+
+	wantMarkers := []domain.DetverMarker{{Date: "2023-03-05", Version: 2}}
+	if !reflect.DeepEqual(resp.DetverMarkers, wantMarkers) {
+		t.Fatalf("markers %+v", resp.DetverMarkers)
+	}
+
+	// monthly crimes are bounded to whole calendar years
+	c, _ := ch.call(ytCrimes)
+	wantArgs := []any{day("2022-01-01"), day("2024-01-01")}
+	if !reflect.DeepEqual(c.args, wantArgs) {
+		t.Fatalf("crimes args %v want %v", c.args, wantArgs)
+	}
+}
+
+func TestYearlyTrends_DenominatorFailureIsPartial(t *testing.T) {
+	t.Parallel()
+
+	resp, err := newTestStore(&fakeCH{results: yearlyResults(errors.New("no agg"))}).
+		YearlyTrends(context.Background(), domain.YearlyTrendsInput{})
+	if err != nil {
+		t.Fatalf("YearlyTrends err: %v", err)
+	}
+	if !resp.Partial {
+		t.Fatal("expected Partial when the utterance query fails")
+	}
+	if resp.Monthly.Hits[2023][2] != 10 || resp.Monthly.Rate[2023][2] != 0 {
+		t.Fatalf("hits should survive with rates zeroed: hits=%d rate=%v",
+			resp.Monthly.Hits[2023][2], resp.Monthly.Rate[2023][2])
+	}
+}
+
+func TestYearlyTrends_IncludeTrimsPayload(t *testing.T) {
+	t.Parallel()
+
+	resp, err := newTestStore(&fakeCH{results: yearlyResults(nil)}).
+		YearlyTrends(context.Background(), domain.YearlyTrendsInput{Include: []string{"hits"}})
+	if err != nil {
+		t.Fatalf("YearlyTrends err: %v", err)
+	}
+	if resp.Monthly.Hits == nil {
+		t.Fatal("hits should be kept")
+	}
+	if resp.Monthly.Rate != nil || resp.Monthly.Severity != nil || resp.Seasonality != nil ||
+		resp.Mix != nil || resp.DetverMarkers != nil {
+		t.Fatalf("unrequested sections kept: %+v", resp)
+	}
+}
+
+func TestYearlyTrends_NoDataFallsBackThenReturnsEmpty(t *testing.T) {
+	t.Parallel()
+
+	ch := &fakeCH{results: []fakeResult{
+		{match: ytCrimeBounds, cols: []string{"ymin", "ymax"}, data: [][]any{{uint16(0), uint16(0)}}},
+		{match: ytUttBounds, cols: []string{"ymin", "ymax"}, data: [][]any{{uint16(0), uint16(0)}}},
+	}}
+
+	resp, err := newTestStore(ch).YearlyTrends(context.Background(), domain.YearlyTrendsInput{})
+	if err != nil {
+		t.Fatalf("YearlyTrends err: %v", err)
+	}
+	if resp.Years == nil || len(resp.Years) != 0 {
+		t.Fatalf("years %v, want an empty non-nil slice", resp.Years)
+	}
+	if _, ok := ch.call(ytUttBounds); !ok {
+		t.Fatal("expected the utt_hour_agg bounds fallback")
+	}
+	if len(ch.calls) != 2 {
+		t.Fatalf("no data should stop after the bounds queries, got %d calls", len(ch.calls))
+	}
+	if _, err := time.Parse(time.RFC3339, resp.Meta.GeneratedAt); err != nil {
+		t.Fatalf("generated_at %q", resp.Meta.GeneratedAt)
+	}
+}
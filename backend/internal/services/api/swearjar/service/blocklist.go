@@ -0,0 +1,105 @@
+package service
+
+import (
+	"strings"
+
+	perr "swearjar/internal/platform/errors"
+	"swearjar/internal/services/api/swearjar/domain"
+)
+
+// TermBlocklist hides configured terms from public responses and refuses them as inputs
+// It filters after the query, so stored data and aggregates are untouched.
+// A nil *TermBlocklist blocks nothing
+type TermBlocklist struct {
+	set map[string]struct{}
+}
+
+// NewTermBlocklist builds a blocklist from terms (trimmed, case-insensitive); empty input yields nil
+func NewTermBlocklist(terms []string) *TermBlocklist {
+	set := make(map[string]struct{}, len(terms))
+	for _, t := range terms {
+		if t = strings.ToLower(strings.TrimSpace(t)); t != "" {
+			set[t] = struct{}{}
+		}
+	}
+	if len(set) == 0 {
+		return nil
+	}
+	return &TermBlocklist{set: set}
+}
+
+// Blocked reports whether term is on the list
+func (b *TermBlocklist) Blocked(term string) bool {
+	if b == nil {
+		return false
+	}
+	_, ok := b.set[strings.ToLower(strings.TrimSpace(term))]
+	return ok
+}
+
+// reject returns InvalidArgument naming field when any of terms is blocked
+func (b *TermBlocklist) reject(field string, terms ...string) error {
+	for _, t := range terms {
+		if b.Blocked(t) {
+			return perr.WithField(perr.InvalidArgf("term %q is not available", t), field)
+		}
+	}
+	return nil
+}
+
+// strings drops blocked entries from xs (in place)
+func (b *TermBlocklist) strings(xs []string) []string {
+	if b == nil {
+		return xs
+	}
+	out := xs[:0]
+	for _, x := range xs {
+		if !b.Blocked(x) {
+			out = append(out, x)
+		}
+	}
+	return out
+}
+
+func (b *TermBlocklist) topTerms(r domain.TopTermsResp) domain.TopTermsResp {
+	if b == nil {
+		return r
+	}
+	items := r.Items[:0]
+	for _, it := range r.Items {
+		if !b.Blocked(it.Term) {
+			items = append(items, it)
+		}
+	}
+	r.Items = items
+	return r
+}
+
+func (b *TermBlocklist) repoOverview(r domain.RepoOverviewResp) domain.RepoOverviewResp {
+	if b == nil {
+		return r
+	}
+	items := r.TopTerms[:0]
+	for _, it := range r.TopTerms {
+		if !b.Blocked(it.Term) {
+			items = append(items, it)
+		}
+	}
+	r.TopTerms = items
+	return r
+}
+
+func (b *TermBlocklist) termsMatrix(r domain.TermsMatrixResp) domain.TermsMatrixResp {
+	if b == nil {
+		return r
+	}
+	r.Terms = b.strings(r.Terms)
+	cells := r.Cells[:0]
+	for _, c := range r.Cells {
+		if !b.Blocked(c.Term) {
+			cells = append(cells, c)
+		}
+	}
+	r.Cells = cells
+	return r
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"swearjar/internal/modkit/repokit"
+	"swearjar/internal/services/api/swearjar/domain"
+	srepo "swearjar/internal/services/api/swearjar/repo"
+)
+
+// inlineTx runs fn without a transaction; the fake repo ignores the Queryer
+type inlineTx struct{ repokit.Queryer }
+
+func (inlineTx) Tx(_ context.Context, fn func(q repokit.Queryer) error) error { return fn(nil) }
+
+// overviewRepo serves a fixed repo overview
+type overviewRepo struct {
+	srepo.StorageRepo
+
+	out domain.RepoOverviewResp
+}
+
+func (r overviewRepo) RepoOverview(context.Context, domain.RepoOverviewInput) (domain.RepoOverviewResp, error) {
+	return r.out, nil
+}
+
+func TestTermBlocklist(t *testing.T) {
+	if NewTermBlocklist([]string{" ", ""}) != nil {
+		t.Fatal("empty input should yield a nil blocklist")
+	}
+	var none *TermBlocklist
+	if none.Blocked("fuck") || !slices.Equal(none.strings([]string{"fuck"}), []string{"fuck"}) {
+		t.Fatal("a nil blocklist must block nothing")
+	}
+
+	b := NewTermBlocklist([]string{" Fuck ", "shit"})
+	for term, want := range map[string]bool{"fuck": true, "FUCK": true, " shit ": true, "damn": false} {
+		if got := b.Blocked(term); got != want {
+			t.Errorf("Blocked(%q) = %v, want %v", term, got, want)
+		}
+	}
+	if err := b.reject("terms", "damn", "Shit"); err == nil {
+		t.Error("reject should refuse a blocked term")
+	}
+	if got := b.strings([]string{"fuck", "damn", "shit"}); !slices.Equal(got, []string{"damn"}) {
+		t.Errorf("strings = %v, want [damn]", got)
+	}
+}
+
+func TestRepoOverviewHidesBlockedTopTerms(t *testing.T) {
+	repo := overviewRepo{out: domain.RepoOverviewResp{TopTerms: []domain.TopTermItem{
+		{Term: "fuck", Hits: 9}, {Term: "damn", Hits: 5}, {Term: "Shit", Hits: 3},
+	}}}
+	s := New(inlineTx{}, repokit.BindFunc[srepo.StorageRepo](func(repokit.Queryer) srepo.StorageRepo { return repo })).
+		WithTermBlocklist(NewTermBlocklist([]string{"fuck", "shit"}))
+
+	out, err := s.RepoOverview(context.Background(), domain.RepoOverviewInput{})
+	if err != nil {
+		t.Fatalf("RepoOverview: %v", err)
+	}
+	if len(out.TopTerms) != 1 || out.TopTerms[0].Term != "damn" {
+		t.Fatalf("top terms = %+v, want only damn", out.TopTerms)
+	}
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+
+	"swearjar/internal/core/detector"
+	"swearjar/internal/core/normalize"
+	"swearjar/internal/core/rulepack"
+	perr "swearjar/internal/platform/errors"
+	"swearjar/internal/services/api/swearjar/domain"
+)
+
+// TryConfig controls the ad-hoc detector endpoint
+type TryConfig struct {
+	Version  int // detector version stamped on hits
+	MaxBytes int // hard cap on input text length
+}
+
+// Tryer runs the loaded rulepack over caller-supplied text
+// It exists for rulepack iteration and is only mounted when explicitly enabled
+type Tryer struct {
+	cfg  TryConfig
+	pack *rulepack.Pack
+	norm *normalize.Normalizer
+	det  *detector.Detector // pipeline-default options, reused when no overrides are sent
+}
+
+// tryDefaults mirrors the detect service so results match what the pipeline would store,
+// plus rule IDs so each hit can be traced back to the template or lemma that fired
+var tryDefaults = detector.Options{
+	MaxTotalHits:              8000,
+	AllowOverlapping:          false,
+	ContextWindow:             64,
+	SeverityDeltaInCodeFence:  -1,
+	SeverityDeltaInCodeInline: -1,
+	SeverityDeltaInQuote:      -1,
+	SeverityDeltaInURL:        -1,
+	SeverityDeltaInDiff:       -1,
+	RuleIDs:                   true,
+}
+
+// NewTryer constructs a Tryer over the given rulepack
+func NewTryer(rp *rulepack.Pack, cfg TryConfig) *Tryer {
+	if rp == nil {
+		panic("swearjar.Tryer requires a non-nil rulepack")
+	}
+	if cfg.MaxBytes <= 0 {
+		cfg.MaxBytes = 8 << 10
+	}
+	return &Tryer{
+		cfg:  cfg,
+		pack: rp,
+		norm: normalize.New(),
+		det:  detector.NewWithOptions(rp, cfg.Version, tryDefaults),
+	}
+}
+
+// Try normalizes the input, scans it and returns every hit
+func (t *Tryer) Try(_ context.Context, in domain.DetectTryInput) (domain.DetectTryResp, error) {
+	if len(in.Text) > t.cfg.MaxBytes {
+		return domain.DetectTryResp{}, perr.WithField(
+			perr.InvalidArgf("text exceeds %d bytes", t.cfg.MaxBytes), "text",
+		)
+	}
+
+	det := t.det
+	if o := in.Options; o != nil && (o.ContextWindow != nil || o.AllowOverlapping != nil ||
+		o.CollapseOverlapping != nil || o.MaxHits > 0 || o.ReportSuppressed != nil || o.LangScoped != nil ||
+		o.ShoutingDelta != nil || o.FoldHomoglyphs != nil || o.RequireContext != nil) {
+		opts := tryDefaults
+		if o.ContextWindow != nil {
+			opts.ContextWindow = *o.ContextWindow
+		}
+		if o.AllowOverlapping != nil {
+			opts.AllowOverlapping = *o.AllowOverlapping
+		}
+		if o.CollapseOverlapping != nil {
+			opts.CollapseOverlapping = *o.CollapseOverlapping
+		}
+		if o.MaxHits > 0 {
+			opts.MaxTotalHits = o.MaxHits
+		}
+		if o.ReportSuppressed != nil {
+			opts.ReportSuppressed = *o.ReportSuppressed
+		}
+		if o.LangScoped != nil {
+			opts.LangScoped = *o.LangScoped
+		}
+		if o.ShoutingDelta != nil {
+			opts.SeverityDeltaShouting = *o.ShoutingDelta
+		}
+		if o.FoldHomoglyphs != nil {
+			opts.FoldHomoglyphs = *o.FoldHomoglyphs
+		}
+		if o.RequireContext != nil {
+			opts.RequireContextSignals = *o.RequireContext
+		}
+		det = detector.NewWithOptions(t.pack, t.cfg.Version, opts)
+	}
+
+	norm, cased := t.norm.NormalizeCased(in.Text)
+	hits, suppressed := det.ScanCased(norm, cased, in.Lang)
+
+	out := domain.DetectTryResp{
+		Norm:  norm,
+		Hits:  make([]domain.DetectTryHit, 0, len(hits)),
+		Count: len(hits),
+	}
+	for _, h := range hits {
+		out.Hits = append(out.Hits, tryHit(h))
+	}
+	for _, h := range suppressed {
+		out.Suppressed = append(out.Suppressed, tryHit(h))
+	}
+	return out, nil
+}
+
+// tryHit maps a detector hit onto the JSON shape
+func tryHit(h detector.Hit) domain.DetectTryHit {
+	return domain.DetectTryHit{
+		Term:            h.Term,
+		Category:        h.Category,
+		Severity:        h.Severity,
+		Spans:           h.Spans,
+		Source:          string(h.Source),
+		RuleID:          h.RuleID,
+		DetectorVersion: h.DetectorVersion,
+		Pre:             h.Pre,
+		Post:            h.Post,
+		Zones:           h.Zones,
+		TargetType:      h.TargetType,
+		TargetID:        h.TargetID,
+		TargetName:      h.TargetName,
+		TargetStart:     h.TargetStart,
+		TargetEnd:       h.TargetEnd,
+		TargetDistance:  h.TargetDistance,
+		CtxAction:       h.CtxAction,
+		Shouting:        h.Shouting,
+		SuppressedBy:    h.SuppressedBy,
+	}
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"swearjar/internal/modkit/repokit"
+	perr "swearjar/internal/platform/errors"
+	"swearjar/internal/services/api/swearjar/domain"
+)
+
+// detverDiffDefaults for omitted sample/examples sizes
+const (
+	detverDiffSample   = 500
+	detverDiffExamples = 10
+)
+
+// DetverDiff compares the stored hits of two detector versions on a sample of utterances either
+// version detected, within hours both ran over. It reads swearjar.hits only; nothing is
+// re-detected or written
+func (s *Service) DetverDiff(ctx context.Context, in domain.DetverDiffInput) (domain.DetverDiffResp, error) {
+	if in.A == in.B {
+		return domain.DetverDiffResp{}, perr.WithField(perr.InvalidArgf("b must differ from a"), "b")
+	}
+	if in.Sample <= 0 {
+		in.Sample = detverDiffSample
+	}
+	if in.Examples <= 0 {
+		in.Examples = detverDiffExamples
+	}
+
+	var rows []domain.DetverDiffRow
+	err := s.DB.Tx(ctx, func(q repokit.Queryer) error {
+		var e error
+		rows, e = s.Repo.Bind(q).DetverDiffRows(ctx, in)
+		return e
+	})
+	if err != nil {
+		return domain.DetverDiffResp{}, err
+	}
+	return diffDetvers(in.A, in.B, rows, in.Examples), nil
+}
+
+// DetectorMeta reports the current and default detector versions and those with stored hits
+func (s *Service) DetectorMeta(ctx context.Context) (domain.DetectorMetaResp, error) {
+	out := domain.DetectorMetaResp{Current: s.CurrentDetVer, Default: s.DefaultDetVer}
+	err := s.DB.Tx(ctx, func(q repokit.Queryer) error {
+		var e error
+		out.Known, e = s.Repo.Bind(q).KnownDetvers(ctx)
+		return e
+	})
+	return out, err
+}
+
+// diffDetvers keys hits per utterance by (term, span), the same identity the detect writer
+// dedupes on. rows must be grouped by utterance (the repo orders by utterance_id); an utterance
+// with no rows at one version has no hits there, so all of the other side's are gained or lost
+func diffDetvers(a, b int, rows []domain.DetverDiffRow, examples int) domain.DetverDiffResp {
+	out := domain.DetverDiffResp{
+		A:        a,
+		B:        b,
+		Terms:    []domain.DetverDiffTerm{},
+		Examples: []domain.DetverDiffExample{},
+	}
+
+	type key struct {
+		term string
+		a, b int
+	}
+	terms := map[string]*domain.DetverDiffTerm{}
+	term := func(t string) *domain.DetverDiffTerm {
+		if terms[t] == nil {
+			terms[t] = &domain.DetverDiffTerm{Term: t}
+		}
+		return terms[t]
+	}
+
+	for i := 0; i < len(rows); {
+		uid := rows[i].UtteranceID
+		j := i
+		for j < len(rows) && rows[j].UtteranceID == uid {
+			j++
+		}
+
+		var aOrder, bOrder []key
+		aHits := map[key]domain.DetverDiffHit{}
+		bHits := map[key]domain.DetverDiffHit{}
+		for _, r := range rows[i:j] {
+			k := key{r.Term, r.SpanStart, r.SpanEnd}
+			h := domain.DetverDiffHit{
+				Term: r.Term, Category: r.Category, Severity: r.Severity, SpanStart: r.SpanStart, SpanEnd: r.SpanEnd,
+			}
+			switch r.Detver {
+			case a:
+				if _, ok := aHits[k]; !ok {
+					aOrder = append(aOrder, k)
+				}
+				aHits[k] = h
+			case b:
+				if _, ok := bHits[k]; !ok {
+					bOrder = append(bOrder, k)
+				}
+				bHits[k] = h
+			}
+		}
+		i = j
+		out.Utterances++
+
+		ex := domain.DetverDiffExample{
+			UtteranceID: uid,
+			Gained:      []domain.DetverDiffHit{},
+			Lost:        []domain.DetverDiffHit{},
+			Changed:     []domain.DetverDiffChange{},
+		}
+		for _, k := range aOrder {
+			ha := aHits[k]
+			hb, ok := bHits[k]
+			switch {
+			case !ok:
+				out.Lost++
+				term(k.term).Lost++
+				ex.Lost = append(ex.Lost, ha)
+			case ha.Category != hb.Category || ha.Severity != hb.Severity:
+				out.Changed++
+				if ha.Category != hb.Category {
+					out.CategoryChanged++
+				}
+				if ha.Severity != hb.Severity {
+					out.SeverityChanged++
+				}
+				term(k.term).Changed++
+				ex.Changed = append(ex.Changed, domain.DetverDiffChange{A: ha, B: hb})
+			default:
+				out.Unchanged++
+			}
+		}
+		for _, k := range bOrder {
+			if _, ok := aHits[k]; !ok {
+				out.Gained++
+				term(k.term).Gained++
+				ex.Gained = append(ex.Gained, bHits[k])
+			}
+		}
+
+		differs := len(ex.Gained)+len(ex.Lost)+len(ex.Changed) > 0
+		if differs && len(out.Examples) < examples {
+			out.Examples = append(out.Examples, ex)
+		}
+	}
+
+	for _, t := range terms {
+		out.Terms = append(out.Terms, *t)
+	}
+	slices.SortFunc(out.Terms, func(x, y domain.DetverDiffTerm) int {
+		dx, dy := x.Gained+x.Lost+x.Changed, y.Gained+y.Lost+y.Changed
+		if dx != dy {
+			return dy - dx
+		}
+		return strings.Compare(x.Term, y.Term)
+	})
+	return out
+}
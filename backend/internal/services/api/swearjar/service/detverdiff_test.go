@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"swearjar/internal/services/api/swearjar/domain"
+)
+
+func TestDiffDetvers(t *testing.T) {
+	hit := func(uid string, v int, term, sev string, a, b int) domain.DetverDiffRow {
+		return domain.DetverDiffRow{
+			UtteranceID: uid, Detver: v, Term: term, Category: "generic", Severity: sev, SpanStart: a, SpanEnd: b,
+		}
+	}
+	type counts struct{ utts, unchanged, gained, lost, changed, sevChanged, examples int }
+	cases := []struct {
+		name string
+		rows []domain.DetverDiffRow
+		want counts
+	}{
+		{"unchanged", []domain.DetverDiffRow{
+			hit("u1", 1, "shit", "mild", 0, 4),
+			hit("u1", 2, "shit", "mild", 0, 4),
+		}, counts{utts: 1, unchanged: 1}},
+		{"severity changed", []domain.DetverDiffRow{
+			hit("u1", 1, "shit", "mild", 0, 4),
+			hit("u1", 2, "shit", "strong", 0, 4),
+		}, counts{utts: 1, changed: 1, sevChanged: 1, examples: 1}},
+		{"span moved is a loss and a gain", []domain.DetverDiffRow{
+			hit("u1", 1, "shit", "mild", 0, 4),
+			hit("u1", 2, "shit", "mild", 5, 9),
+		}, counts{utts: 1, gained: 1, lost: 1, examples: 1}},
+		{"B dropped every hit", []domain.DetverDiffRow{
+			hit("u1", 1, "shit", "mild", 0, 4),
+			hit("u1", 1, "damn", "mild", 10, 14),
+		}, counts{utts: 1, lost: 2, examples: 1}},
+		{"B first hit", []domain.DetverDiffRow{
+			hit("u1", 2, "shit", "mild", 0, 4),
+		}, counts{utts: 1, gained: 1, examples: 1}},
+		{"duplicate rows count once", []domain.DetverDiffRow{
+			hit("u1", 1, "shit", "mild", 0, 4),
+			hit("u1", 1, "shit", "mild", 0, 4),
+			hit("u1", 2, "shit", "mild", 0, 4),
+		}, counts{utts: 1, unchanged: 1}},
+		{"several utterances", []domain.DetverDiffRow{
+			hit("u1", 1, "shit", "mild", 0, 4),
+			hit("u2", 2, "damn", "mild", 0, 4),
+			hit("u3", 1, "hell", "mild", 0, 4),
+			hit("u3", 2, "hell", "mild", 0, 4),
+		}, counts{utts: 3, unchanged: 1, gained: 1, lost: 1, examples: 2}},
+		{"no rows", nil, counts{}},
+	}
+	for _, tc := range cases {
+		r := diffDetvers(1, 2, tc.rows, 10)
+		got := counts{
+			r.Utterances, r.Unchanged, r.Gained, r.Lost, r.Changed, r.SeverityChanged, len(r.Examples),
+		}
+		if got != tc.want {
+			t.Errorf("%s: got %+v, want %+v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestDiffDetversTermsAndExampleCap(t *testing.T) {
+	rows := []domain.DetverDiffRow{
+		{UtteranceID: "u1", Detver: 1, Term: "damn", SpanEnd: 4},
+		{UtteranceID: "u2", Detver: 1, Term: "shit", SpanEnd: 4},
+		{UtteranceID: "u3", Detver: 2, Term: "shit", SpanEnd: 4},
+	}
+	r := diffDetvers(1, 2, rows, 1)
+	if len(r.Examples) != 1 || r.Examples[0].UtteranceID != "u1" {
+		t.Fatalf("examples = %+v, want only u1", r.Examples)
+	}
+	if len(r.Terms) != 2 || r.Terms[0].Term != "shit" || r.Terms[0].Lost != 1 || r.Terms[0].Gained != 1 {
+		t.Fatalf("terms = %+v, want shit (most affected) first", r.Terms)
+	}
+}
+
+func TestDetverDiffRejectsSameVersion(t *testing.T) {
+	_, err := (&Service{}).DetverDiff(context.Background(), domain.DetverDiffInput{A: 3, B: 3})
+	if err == nil {
+		t.Fatal("A == B: want an invalid argument error")
+	}
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"swearjar/internal/modkit/repokit"
+	"swearjar/internal/platform/logger"
+	"swearjar/internal/services/api/swearjar/domain"
+)
+
+// freshness labels a window whose end falls within FreshnessLag of now: detection trails
+// ingestion, so its newest hours may still be filling in. Older windows, a zero lag and an
+// unreadable mark all return nil; the label is advisory and never fails the request
+func (s *Service) freshness(ctx context.Context, r domain.TimeRange) *domain.Freshness {
+	if s.FreshnessLag <= 0 {
+		return nil
+	}
+	endIncl, err := time.Parse("2006-01-02", r.End)
+	if err != nil {
+		return nil
+	}
+	endExcl, now := endIncl.Add(24*time.Hour), time.Now().UTC()
+	if endExcl.Before(now.Add(-s.FreshnessLag)) {
+		return nil
+	}
+
+	var (
+		mark time.Time
+		ok   bool
+	)
+	err = s.DB.Tx(ctx, func(q repokit.Queryer) error {
+		var err error
+		mark, ok, err = s.Repo.Bind(q).ProcessedThrough(ctx)
+		return err
+	})
+	if err != nil {
+		logger.Get().Warn().Err(err).Msg("swearjar: freshness mark unavailable")
+		return nil
+	}
+	out := &domain.Freshness{}
+	if !ok {
+		return out
+	}
+
+	// the hour in progress can't be processed yet, so a window reaching now is complete
+	// once every finished hour inside it is
+	want := now.Truncate(time.Hour)
+	if endExcl.Before(want) {
+		want = endExcl
+	}
+	out.Complete = !mark.Before(want)
+	out.ProcessedThrough = mark.Format(time.RFC3339)
+	return out
+}
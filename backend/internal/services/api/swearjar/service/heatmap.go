@@ -0,0 +1,46 @@
+package service
+
+import "swearjar/internal/services/api/swearjar/domain"
+
+// defaultWorkDays is Mon..Fri in the heatmap's 0=Sun convention
+var defaultWorkDays = []int{1, 2, 3, 4, 5}
+
+// splitBusinessHours folds the weekly grid into in-hours and off-hours buckets
+// Cells are already in the request TZ, so the work week applies to them as-is
+func splitBusinessHours(grid []domain.HeatmapCell, ww domain.WorkWeek) domain.HeatmapSplit {
+	days := ww.Days
+	if len(days) == 0 {
+		days = defaultWorkDays
+	}
+	var workDay [7]bool
+	for _, d := range days {
+		if d >= 0 && d < 7 {
+			workDay[d] = true
+		}
+	}
+	inRange := func(h int) bool {
+		if ww.StartHour < ww.EndHour {
+			return h >= ww.StartHour && h < ww.EndHour
+		}
+		return h >= ww.StartHour || h < ww.EndHour // wraps past midnight
+	}
+
+	var out domain.HeatmapSplit
+	for _, c := range grid {
+		b := &out.OffHours
+		if c.DOW >= 0 && c.DOW < 7 && workDay[c.DOW] && inRange(c.Hour) {
+			b = &out.InHours
+		}
+		b.Cells++
+		b.Hits += c.Hits
+		b.OffendingUtterances += c.OffendingUtterances
+		b.Utterances += c.Utterances
+	}
+	for _, b := range []*domain.HeatmapBucket{&out.InHours, &out.OffHours} {
+		if b.Utterances > 0 {
+			b.Rarity = float64(b.Hits) / float64(b.Utterances)
+			b.Coverage = float64(b.OffendingUtterances) / float64(b.Utterances)
+		}
+	}
+	return out
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"testing"
+
+	"swearjar/internal/services/api/swearjar/domain"
+)
+
+// weekGrid is a full 7x24 grid, one hit and one utterance per cell
+func weekGrid() []domain.HeatmapCell {
+	grid := make([]domain.HeatmapCell, 0, 7*24)
+	for dow := range 7 {
+		for h := range 24 {
+			grid = append(grid, domain.HeatmapCell{DOW: dow, Hour: h, Hits: 1, Utterances: 1})
+		}
+	}
+	return grid
+}
+
+func TestSplitBusinessHours(t *testing.T) {
+	t.Parallel()
+
+	// Each cell's hits are a distinct bit, so a bucket's hits name exactly the cells it took
+	probe := []domain.HeatmapCell{
+		{DOW: 1, Hour: 23, Hits: 1},  // Mon late
+		{DOW: 2, Hour: 0, Hits: 2},   // Tue midnight
+		{DOW: 1, Hour: 5, Hits: 4},   // Mon early
+		{DOW: 1, Hour: 6, Hits: 8},   // Mon, range end (exclusive)
+		{DOW: 1, Hour: 12, Hits: 16}, // Mon midday
+		{DOW: 6, Hour: 23, Hits: 32}, // Sat late
+		{DOW: 7, Hour: 23, Hits: 64}, // out of range dow
+	}
+
+	cases := []struct {
+		name            string
+		grid            []domain.HeatmapCell
+		ww              domain.WorkWeek
+		inCells         int
+		inHits, offHits int64
+	}{
+		{"default days", weekGrid(), domain.WorkWeek{StartHour: 9, EndHour: 17},
+			5 * 8, 5 * 8, 7*24 - 5*8},
+		{"explicit days", weekGrid(), domain.WorkWeek{Days: []int{0, 6, 9}, StartHour: 10, EndHour: 14},
+			2 * 4, 2 * 4, 7*24 - 2*4},
+		{"wraps past midnight", probe, domain.WorkWeek{StartHour: 22, EndHour: 6},
+			3, 1 + 2 + 4, 8 + 16 + 32 + 64},
+		{"equal bounds cover the whole day", probe, domain.WorkWeek{Days: []int{6}, StartHour: 0, EndHour: 0},
+			1, 32, 1 + 2 + 4 + 8 + 16 + 64},
+	}
+	for _, tc := range cases {
+		got := splitBusinessHours(tc.grid, tc.ww)
+		if got.InHours.Cells != tc.inCells || got.InHours.Hits != tc.inHits || got.OffHours.Hits != tc.offHits {
+			t.Fatalf("%s: in %d cells/%d hits, off %d hits; want %d/%d, %d",
+				tc.name, got.InHours.Cells, got.InHours.Hits, got.OffHours.Hits, tc.inCells, tc.inHits, tc.offHits)
+		}
+		if got.InHours.Cells+got.OffHours.Cells != len(tc.grid) {
+			t.Fatalf("%s: %d cells split, grid has %d", tc.name, got.InHours.Cells+got.OffHours.Cells, len(tc.grid))
+		}
+	}
+}
+
+func TestSplitBusinessHoursRatios(t *testing.T) {
+	t.Parallel()
+
+	grid := []domain.HeatmapCell{
+		{DOW: 1, Hour: 10, Hits: 3, OffendingUtterances: 2, Utterances: 8},
+		{DOW: 3, Hour: 11, Hits: 1, OffendingUtterances: 1, Utterances: 4},
+		{DOW: 0, Hour: 3, Hits: 5, OffendingUtterances: 5}, // hits without utterances: no ratio
+	}
+	got := splitBusinessHours(grid, domain.WorkWeek{StartHour: 9, EndHour: 17})
+
+	want := domain.HeatmapSplit{
+		InHours: domain.HeatmapBucket{
+			Cells: 2, Hits: 4, OffendingUtterances: 3, Utterances: 12,
+			Rarity: 4.0 / 12, Coverage: 3.0 / 12,
+		},
+		OffHours: domain.HeatmapBucket{Cells: 1, Hits: 5, OffendingUtterances: 5},
+	}
+	if got != want {
+		t.Fatalf("split\n got %+v\nwant %+v", got, want)
+	}
+}
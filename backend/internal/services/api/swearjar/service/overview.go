@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"sync"
+
+	"swearjar/internal/platform/logger"
+	"swearjar/internal/services/api/swearjar/domain"
+)
+
+const (
+	// defaultOverviewTopN is how many top terms the overview returns when top_n is unset
+	defaultOverviewTopN = 10
+	// defaultOverviewWorkers runs every overview panel at once when no bound is configured
+	defaultOverviewWorkers = 3
+)
+
+// overviewPanel is one query behind the overview; run fills its slot in the response
+type overviewPanel struct {
+	name string
+	run  func(context.Context) error
+}
+
+// Overview returns the homepage hero for one window: KPI strip, top N terms and the weekly
+// heatmap. Panels run concurrently, at most OverviewWorkers at a time, each through the same
+// service method its own endpoint uses. A failing panel is left null and named in Failed while
+// the rest still return; the request only fails when every panel does or ctx ends
+func (s *Service) Overview(ctx context.Context, in domain.OverviewInput) (domain.OverviewResp, error) {
+	topN := in.TopN
+	if topN <= 0 {
+		topN = defaultOverviewTopN
+	}
+	opts := in.GlobalOptions
+	opts.Page = domain.PageOpts{}
+	termOpts := opts
+	termOpts.Page.Limit = topN
+
+	var out domain.OverviewResp
+	panels := []overviewPanel{
+		{"kpi", func(ctx context.Context) error {
+			r, err := s.KPIStrip(ctx, domain.KPIStripInput{GlobalOptions: opts})
+			if err == nil {
+				out.KPI = &r
+			}
+			return err
+		}},
+		{"top_terms", func(ctx context.Context) error {
+			r, err := s.TopTerms(ctx, domain.TopTermsInput{GlobalOptions: termOpts})
+			if err == nil {
+				if len(r.Items) > topN {
+					r.Items = r.Items[:topN]
+				}
+				r.NextCursor = ""
+				out.TopTerms = &r
+			}
+			return err
+		}},
+		{"heatmap", func(ctx context.Context) error {
+			r, err := s.HeatmapWeekly(ctx, domain.HeatmapWeeklyInput{GlobalOptions: opts})
+			if err == nil {
+				out.Heatmap = &r
+			}
+			return err
+		}},
+	}
+
+	workers := s.OverviewWorkers
+	if workers <= 0 {
+		workers = defaultOverviewWorkers
+	}
+	sem := make(chan struct{}, workers)
+	errs := make([]error, len(panels))
+	var wg sync.WaitGroup
+	for i, p := range panels {
+		wg.Go(func() {
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			errs[i] = p.run(ctx)
+		})
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return domain.OverviewResp{}, err
+	}
+
+	var first error
+	for i, err := range errs {
+		if err == nil {
+			continue
+		}
+		if first == nil {
+			first = err
+		}
+		out.Failed = append(out.Failed, panels[i].name)
+		logger.Get().Warn().Err(err).Str("panel", panels[i].name).Msg("swearjar: overview panel failed")
+	}
+	if len(out.Failed) == len(panels) {
+		return domain.OverviewResp{}, first
+	}
+	out.Partial = len(out.Failed) > 0
+	out.Freshness = s.freshness(ctx, in.Range)
+	return out, nil
+}
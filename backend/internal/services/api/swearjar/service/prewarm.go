@@ -0,0 +1,226 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"swearjar/internal/modkit/repokit"
+	"swearjar/internal/platform/logger"
+	"swearjar/internal/platform/store/ch"
+	"swearjar/internal/services/api/swearjar/domain"
+)
+
+// PrewarmConfig controls the dashboard cache pre-warmer
+type PrewarmConfig struct {
+	// Years are calendar years warmed the way the explore UI's period=year view asks for them
+	// (Jan 1 through Dec 31, or today for the current year); the UI opens on its default year
+	Years []int
+	// Windows are trailing windows in days, each ending today (UTC), for API callers and
+	// custom-range links; the explore UI's own periods never produce them
+	Windows []int
+	// Interval between rounds; keep it under CacheTTL so entries are renewed before they
+	// lapse. It also bounds each round
+	Interval time.Duration
+	// CacheTTL is the CH query cache TTL (SERVICE_CLICKHOUSE_QUERY_CACHE_TTL). SkipIdle never
+	// skips a round that would let warmed entries expire before the next one; 0 = unknown
+	CacheTTL time.Duration
+	// SkipIdle skips a round while detection hasn't advanced since the last warmed one and the
+	// window dates are unchanged, as long as the cache still holds that round's results
+	SkipIdle bool
+}
+
+// Prewarmer re-runs the KPI strip, hits series and overview for the configured years and
+// windows with the explore UI's default options, so the CH query cache (keyed by SQL and args)
+// already holds what dashboards ask for. Rounds refresh entries rather than replaying them
+type Prewarmer struct {
+	svc *Service
+	cfg PrewarmConfig
+	now func() time.Time
+
+	// last successful round; only the Start loop touches these
+	warmed   bool
+	lastAt   time.Time
+	lastDay  string
+	lastMark time.Time
+}
+
+// NewPrewarmer constructs a pre-warmer over the service
+func NewPrewarmer(svc *Service, cfg PrewarmConfig) *Prewarmer {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 5 * time.Minute
+	}
+	return &Prewarmer{svc: svc, cfg: cfg, now: time.Now}
+}
+
+// ParsePrewarmWindows reads a comma-separated list of window lengths in days ("7,30,90";
+// a trailing "d" is allowed), dropping duplicates
+func ParsePrewarmWindows(spec string) ([]int, error) {
+	var out []int
+	for f := range strings.SplitSeq(spec, ",") {
+		f = strings.TrimSuffix(strings.TrimSpace(f), "d")
+		if f == "" {
+			continue
+		}
+		n, err := strconv.Atoi(f)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("prewarm window %q: want a positive number of days", f)
+		}
+		if !slices.Contains(out, n) {
+			out = append(out, n)
+		}
+	}
+	return out, nil
+}
+
+// ParsePrewarmYears reads a comma-separated list of calendar years ("2014,2024"), dropping
+// duplicates
+func ParsePrewarmYears(spec string) ([]int, error) {
+	var out []int
+	for f := range strings.SplitSeq(spec, ",") {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		y, err := strconv.Atoi(f)
+		if err != nil || y < 1970 || y > 9999 {
+			return nil, fmt.Errorf("prewarm year %q: want a four-digit year", f)
+		}
+		if !slices.Contains(out, y) {
+			out = append(out, y)
+		}
+	}
+	return out, nil
+}
+
+// Start runs a round right away and then every Interval until ctx is done
+func (p *Prewarmer) Start(ctx context.Context) {
+	logger.Get().Info().
+		Ints("years", p.cfg.Years).
+		Ints("windows_days", p.cfg.Windows).
+		Dur("interval", p.cfg.Interval).
+		Bool("skip_idle", p.cfg.SkipIdle).
+		Msg("swearjar: dashboard prewarm enabled")
+	if p.cfg.CacheTTL > 0 && p.cfg.CacheTTL <= p.cfg.Interval {
+		logger.Get().Warn().
+			Dur("cache_ttl", p.cfg.CacheTTL).
+			Dur("interval", p.cfg.Interval).
+			Msg("swearjar: prewarm interval isn't under the CH query cache TTL; entries lapse between rounds")
+	}
+	go func() {
+		t := time.NewTicker(p.cfg.Interval)
+		defer t.Stop()
+		for {
+			rctx, cancel := context.WithTimeout(ctx, p.cfg.Interval)
+			start := time.Now()
+			warmed, err := p.Round(rctx)
+			cancel()
+			l := logger.Get().Debug()
+			if err != nil {
+				l = logger.Get().Warn().Err(err)
+			}
+			l.Bool("warmed", warmed).Dur("took", time.Since(start)).Msg("swearjar: prewarm round")
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+			}
+		}
+	}()
+}
+
+// Round warms every year and window once and reports whether it ran; under SkipIdle it doesn't
+// while the processed-through mark and today's date match the last successful round, unless
+// waiting another Interval would outlive that round's cache entries. A failed round is
+// retried in full next time
+func (p *Prewarmer) Round(ctx context.Context) (bool, error) {
+	now := p.now().UTC()
+	today := now.Format(time.DateOnly)
+
+	var (
+		mark time.Time
+		ok   bool
+	)
+	if err := p.svc.DB.Tx(ctx, func(q repokit.Queryer) error {
+		var err error
+		mark, ok, err = p.svc.Repo.Bind(q).ProcessedThrough(ctx)
+		return err
+	}); err != nil {
+		ok = false // can't tell a lull apart; warm anyway
+	}
+	if p.idle(now, mark, ok) {
+		return false, nil
+	}
+
+	ctx = ch.WithQueryCacheRefresh(ctx)
+	var errs []error
+	warm := func(label string, g domain.GlobalOptions) {
+		if _, err := p.svc.KPIStrip(ctx, domain.KPIStripInput{GlobalOptions: g}); err != nil {
+			errs = append(errs, fmt.Errorf("kpi %s: %w", label, err))
+		}
+		if _, err := p.svc.TimeseriesHits(ctx, domain.TimeseriesHitsInput{GlobalOptions: g}); err != nil {
+			errs = append(errs, fmt.Errorf("timeseries %s: %w", label, err))
+		}
+		if _, err := p.svc.Overview(ctx, domain.OverviewInput{GlobalOptions: g}); err != nil {
+			errs = append(errs, fmt.Errorf("overview %s: %w", label, err))
+		}
+	}
+	for _, y := range p.cfg.Years {
+		if g, ok := prewarmYearOptions(now, y); ok {
+			warm(strconv.Itoa(y), g)
+		}
+	}
+	for _, days := range p.cfg.Windows {
+		warm(strconv.Itoa(days)+"d", prewarmOptions(now, days))
+	}
+	if len(errs) > 0 {
+		p.warmed = false
+		return true, errors.Join(errs...)
+	}
+	p.warmed, p.lastAt, p.lastDay, p.lastMark = ok, now, today, mark
+	return true, nil
+}
+
+// idle reports whether Round may skip: SkipIdle is on, nothing moved since the last warmed
+// round, and its entries (warmed at lastAt) outlive the round after this one
+func (p *Prewarmer) idle(now, mark time.Time, ok bool) bool {
+	if !p.cfg.SkipIdle || !p.warmed || !ok || !mark.Equal(p.lastMark) || now.Format(time.DateOnly) != p.lastDay {
+		return false
+	}
+	return p.cfg.CacheTTL <= 0 || now.Sub(p.lastAt)+p.cfg.Interval < p.cfg.CacheTTL
+}
+
+// prewarmYearOptions mirrors the explore UI's period=year request (deriveRangeFromParams): the
+// year's Jan 1 through Dec 31, cut at today. ok is false for years that haven't started
+func prewarmYearOptions(now time.Time, year int) (domain.GlobalOptions, bool) {
+	start := time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
+	if start.After(now) {
+		return domain.GlobalOptions{}, false
+	}
+	end := min(time.Date(year, 12, 31, 0, 0, 0, 0, time.UTC).Format(time.DateOnly), now.Format(time.DateOnly))
+	g := prewarmOptions(now, 1)
+	g.Range = domain.TimeRange{Start: start.Format(time.DateOnly), End: end}
+	return g, true
+}
+
+// prewarmOptions mirrors the explore UI's request options (buildGlobalOptionsFromParams) for a
+// trailing window: inclusive dates ending today, auto interval, UTC, counts of hits and no
+// filters. Any field that differs changes the query args, and with them the cache key
+func prewarmOptions(now time.Time, days int) domain.GlobalOptions {
+	return domain.GlobalOptions{
+		Range: domain.TimeRange{
+			Start: now.AddDate(0, 0, -(days - 1)).Format(time.DateOnly),
+			End:   now.Format(time.DateOnly),
+		},
+		Interval:  "auto",
+		TZ:        "UTC",
+		Normalize: "none",
+		Metric:    "counts",
+		Series:    "hits",
+	}
+}
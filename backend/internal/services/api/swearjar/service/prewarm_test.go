@@ -0,0 +1,103 @@
+package service
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestParsePrewarmWindows(t *testing.T) {
+	cases := []struct {
+		spec string
+		want []int
+		bad  bool
+	}{
+		{"7,30,90", []int{7, 30, 90}, false},
+		{" 7d, 30 ,7 ", []int{7, 30}, false},
+		{"", nil, false},
+		{",,", nil, false},
+		{"0", nil, true},
+		{"-7", nil, true},
+		{"week", nil, true},
+	}
+	for _, tc := range cases {
+		got, err := ParsePrewarmWindows(tc.spec)
+		if tc.bad != (err != nil) || !slices.Equal(got, tc.want) {
+			t.Errorf("%q: got %v, %v", tc.spec, got, err)
+		}
+	}
+}
+
+func TestParsePrewarmYears(t *testing.T) {
+	cases := []struct {
+		spec string
+		want []int
+		bad  bool
+	}{
+		{"2014", []int{2014}, false},
+		{" 2014, 2025,2014 ", []int{2014, 2025}, false},
+		{"", nil, false},
+		{"14", nil, true},
+		{"2014d", nil, true},
+	}
+	for _, tc := range cases {
+		got, err := ParsePrewarmYears(tc.spec)
+		if tc.bad != (err != nil) || !slices.Equal(got, tc.want) {
+			t.Errorf("%q: got %v, %v", tc.spec, got, err)
+		}
+	}
+}
+
+func TestPrewarmIdle(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	mark := now.Truncate(time.Hour)
+	warmed := func(cfg PrewarmConfig, lastAt time.Time) *Prewarmer {
+		p := NewPrewarmer(nil, cfg)
+		p.warmed, p.lastAt, p.lastDay, p.lastMark = true, lastAt, now.Format(time.DateOnly), mark
+		return p
+	}
+	cfg := PrewarmConfig{Interval: 5 * time.Minute, CacheTTL: 30 * time.Minute, SkipIdle: true}
+
+	cases := []struct {
+		name string
+		p    *Prewarmer
+		now  time.Time
+		mark time.Time
+		ok   bool
+		want bool
+	}{
+		{"nothing moved", warmed(cfg, now.Add(-10*time.Minute)), now, mark, true, true},
+		{"mark moved", warmed(cfg, now.Add(-10*time.Minute)), now, mark.Add(time.Hour), true, false},
+		{"mark unknown", warmed(cfg, now.Add(-10*time.Minute)), now, mark, false, false},
+		{"day rolled over", warmed(cfg, now.Add(-10*time.Minute)), now.Add(12 * time.Hour), mark, true, false},
+		{"entries would lapse before the next round", warmed(cfg, now.Add(-25*time.Minute)), now, mark, true, false},
+		{"entries already lapsed", warmed(cfg, now.Add(-time.Hour)), now, mark, true, false},
+		{"ttl unknown", warmed(PrewarmConfig{Interval: 5 * time.Minute, SkipIdle: true}, now.Add(-time.Hour)),
+			now, mark, true, true},
+		{"skip idle off", warmed(PrewarmConfig{Interval: 5 * time.Minute}, now), now, mark, true, false},
+		{"never warmed", NewPrewarmer(nil, cfg), now, mark, true, false},
+	}
+	for _, tc := range cases {
+		if got := tc.p.idle(tc.now, tc.mark, tc.ok); got != tc.want {
+			t.Errorf("%s: idle = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestPrewarmYearOptions(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	g, ok := prewarmYearOptions(now, 2014)
+	if !ok || g.Range.Start != "2014-01-01" || g.Range.End != "2014-12-31" {
+		t.Fatalf("past year: %+v %v", g.Range, ok)
+	}
+	if g.Interval != "auto" || g.TZ != "UTC" || g.Metric != "counts" || g.Series != "hits" || g.Normalize != "none" {
+		t.Fatalf("year options %+v don't match the explore UI's defaults", g)
+	}
+	if g, ok := prewarmYearOptions(now, 2025); !ok || g.Range.End != "2025-03-01" {
+		t.Fatalf("current year: %+v %v, want it cut at today", g.Range, ok)
+	}
+	if _, ok := prewarmYearOptions(now, 2026); ok {
+		t.Fatal("a year that hasn't started shouldn't be warmed")
+	}
+}
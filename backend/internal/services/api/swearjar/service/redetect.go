@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"swearjar/internal/core/detector"
+	"swearjar/internal/modkit/repokit"
+	perr "swearjar/internal/platform/errors"
+	"swearjar/internal/services/api/swearjar/domain"
+	detectdom "swearjar/internal/services/detect/domain"
+	detectsvc "swearjar/internal/services/detect/service"
+)
+
+// Redetector re-runs the current detector over one stored utterance and diffs the result
+// against swearjar.hits. Read-only; mounted only when explicitly enabled
+type Redetector struct {
+	svc     *Service
+	try     *Tryer             // rulepack and stamped version
+	det     *detector.Detector // scans with the pipeline's configured options
+	langDet detectdom.LangDetector
+}
+
+// NewRedetector constructs a Redetector over the service's storage and a Tryer's rulepack.
+// opts and langDet should be the detect pipeline's (detect/module Options.DetectorOptions and
+// LangDetector), so the current side of the diff is what a re-detect would store
+func NewRedetector(svc *Service, try *Tryer, opts detector.Options, langDet detectdom.LangDetector) *Redetector {
+	if svc == nil || try == nil {
+		panic("swearjar.Redetector requires a non-nil Service and Tryer")
+	}
+	opts.RuleIDs = true // traced like /detect/try; doesn't change which hits fire
+	return &Redetector{
+		svc:     svc,
+		try:     try,
+		det:     detector.NewWithOptions(try.pack, try.cfg.Version, opts),
+		langDet: langDet,
+	}
+}
+
+// Redetect loads the utterance, scans it the way the detect writer does (lang resolution,
+// cased text, best hit per span) and returns the per-span diff
+func (r *Redetector) Redetect(ctx context.Context, in domain.RedetectInput) (domain.RedetectResp, error) {
+	var at *time.Time
+	if in.CreatedAt != "" {
+		t, err := time.Parse(time.RFC3339, in.CreatedAt)
+		if err != nil {
+			return domain.RedetectResp{}, perr.WithField(perr.InvalidArgf("created_at must be RFC3339"), "created_at")
+		}
+		at = &t
+	}
+
+	var src domain.RedetectSource
+	err := r.svc.DB.Tx(ctx, func(q repokit.Queryer) error {
+		var e error
+		src, e = r.svc.Repo.Bind(q).RedetectSource(ctx, in.UtteranceID, at, in.DetVer)
+		return e
+	})
+	if err != nil {
+		return domain.RedetectResp{}, err
+	}
+	if !src.Found {
+		return domain.RedetectResp{}, perr.NotFoundf("utterance %s not found", in.UtteranceID)
+	}
+
+	// text_raw feeds the shouting signal exactly as the pipeline scans it, so all-caps hits
+	// don't show up as changed under CORE_DETECT_SHOUTING_DELTA
+	lang, reliable := detectsvc.ResolveLang(r.langDet, src.LangCode, src.Norm)
+	hits, _ := r.det.ScanCased(src.Norm, detectsvc.CasedText(src.Raw, src.Norm), detectsvc.ScanLang(lang, reliable))
+	out := domain.RedetectResp{
+		UtteranceID:   in.UtteranceID,
+		Norm:          src.Norm,
+		Lang:          lang,
+		LangReliable:  reliable,
+		CreatedAt:     src.CreatedAt.UTC().Format(time.RFC3339),
+		CurrentDetver: r.try.cfg.Version,
+		Current:       make([]domain.DetectTryHit, 0, len(hits)),
+		Stored:        src.Hits,
+		Added:         []domain.RedetectStoredHit{},
+		Removed:       []domain.RedetectStoredHit{},
+		Changed:       []domain.RedetectChange{},
+	}
+
+	for _, h := range hits {
+		out.Current = append(out.Current, tryHit(h))
+	}
+
+	// Project current hits into stored rows with the detect writer's best-per-span choice
+	type key struct {
+		term string
+		a, b int
+	}
+	rows := detectsvc.BestPerSpan(hits)
+	cur := make(map[key]domain.RedetectStoredHit, len(rows))
+	order := make([]key, 0, len(rows))
+	for _, h := range rows {
+		sp := h.Spans[0]
+		k := key{h.Term, sp[0], sp[1]}
+		order = append(order, k)
+		cur[k] = domain.RedetectStoredHit{
+			Term:            h.Term,
+			Category:        storedCategory(h.Category),
+			Severity:        r.try.pack.SeverityLabel(h.Severity),
+			SpanStart:       sp[0],
+			SpanEnd:         sp[1],
+			TargetType:      orNone(h.TargetType),
+			TargetID:        strings.TrimSpace(h.TargetID),
+			CtxAction:       orNone(h.CtxAction),
+			DetectorVersion: r.try.cfg.Version,
+		}
+	}
+
+	seen := make(map[key]bool, len(src.Hits))
+	for _, s := range src.Hits {
+		k := key{s.Term, s.SpanStart, s.SpanEnd}
+		seen[k] = true
+		c, ok := cur[k]
+		switch {
+		case !ok:
+			out.Removed = append(out.Removed, s)
+		case c.Category != s.Category || c.Severity != s.Severity ||
+			c.TargetType != s.TargetType || c.TargetID != s.TargetID || c.CtxAction != s.CtxAction:
+			out.Changed = append(out.Changed, domain.RedetectChange{Stored: s, Current: c})
+		default:
+			out.Unchanged++
+		}
+	}
+	for _, k := range order {
+		if !seen[k] {
+			out.Added = append(out.Added, cur[k])
+		}
+	}
+	return out, nil
+}
+
+// storedCategory mirrors the detect writer's mapping onto the hits.category enum
+func storedCategory(c string) string {
+	switch c {
+	case "bot_rage", "tooling_rage", "self_own", "generic", "lang_rage":
+		return c
+	default:
+		return "generic"
+	}
+}
+
+// orNone mirrors the writer's trimmed, "none"-defaulted enum columns
+func orNone(s string) string {
+	if s = strings.TrimSpace(s); s == "" {
+		return "none"
+	}
+	return s
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"swearjar/internal/core/detector"
+	"swearjar/internal/core/normalize"
+	"swearjar/internal/core/rulepack"
+	"swearjar/internal/modkit/repokit"
+	"swearjar/internal/services/api/swearjar/domain"
+	srepo "swearjar/internal/services/api/swearjar/repo"
+)
+
+// redetectStore serves one canned utterance
+type redetectStore struct {
+	srepo.StorageRepo
+	src domain.RedetectSource
+}
+
+func (s redetectStore) RedetectSource(context.Context, string, *time.Time, int) (domain.RedetectSource, error) {
+	return s.src, nil
+}
+
+// noTx runs fn without a transaction
+type noTx struct{ repokit.TxRunner }
+
+func (noTx) Tx(_ context.Context, fn func(q repokit.Queryer) error) error { return fn(nil) }
+
+func redetectService(src domain.RedetectSource) *Service {
+	bind := func(repokit.Queryer) srepo.StorageRepo { return redetectStore{src: src} }
+	return &Service{DB: noTx{}, Repo: repokit.BindFunc[srepo.StorageRepo](bind)}
+}
+
+func newTestRedetector(t *testing.T, opts detector.Options) func(domain.RedetectSource) domain.RedetectResp {
+	t.Helper()
+	rp, err := rulepack.Load()
+	if err != nil {
+		t.Fatalf("rulepack: %v", err)
+	}
+	try := NewTryer(rp, TryConfig{Version: 1})
+	return func(src domain.RedetectSource) domain.RedetectResp {
+		t.Helper()
+		src.Found = true
+		r := NewRedetector(redetectService(src), try, opts, nil)
+		out, err := r.Redetect(context.Background(), domain.RedetectInput{UtteranceID: "u"})
+		if err != nil {
+			t.Fatalf("Redetect: %v", err)
+		}
+		return out
+	}
+}
+
+func TestRedetectScansCased(t *testing.T) {
+	redetect := newTestRedetector(t, detector.Options{ContextWindow: 64, SeverityDeltaShouting: 1})
+	raw := "WHY IS THIS BUILD SUCH SHIT"
+	norm := normalize.New().Normalize(raw)
+
+	// What the pipeline stored: the shouted hit, boosted
+	stored := redetect(domain.RedetectSource{Norm: norm, Raw: raw}).Added
+	if len(stored) == 0 {
+		t.Fatal("no hits on the shouted text")
+	}
+
+	same := redetect(domain.RedetectSource{Norm: norm, Raw: raw, Hits: stored})
+	if same.Unchanged != len(stored) || len(same.Changed)+len(same.Added)+len(same.Removed) != 0 {
+		t.Fatalf("shouted re-detect diverged from what the pipeline stores: %+v", same)
+	}
+
+	// Without text_raw there's no shouting signal, so the same rows read as changed
+	quiet := redetect(domain.RedetectSource{Norm: norm, Hits: stored})
+	if len(quiet.Changed) == 0 {
+		t.Fatalf("shouting delta had no effect: %+v", quiet)
+	}
+}
+
+type fixedLang struct {
+	lang     string
+	reliable bool
+}
+
+func (f fixedLang) DetectLang(string) (string, bool) { return f.lang, f.reliable }
+
+func TestRedetectResolvesLangLikeThePipeline(t *testing.T) {
+	rp, err := rulepack.Load()
+	if err != nil {
+		t.Fatalf("rulepack: %v", err)
+	}
+	try := NewTryer(rp, TryConfig{Version: 1})
+	redetect := func(det fixedLang, code *string) domain.RedetectResp {
+		t.Helper()
+		src := domain.RedetectSource{Found: true, Norm: "this build is shit", LangCode: code}
+		r := NewRedetector(redetectService(src), try, detector.Options{LangScoped: true}, det)
+		out, err := r.Redetect(context.Background(), domain.RedetectInput{UtteranceID: "u"})
+		if err != nil {
+			t.Fatalf("Redetect: %v", err)
+		}
+		return out
+	}
+
+	// An unreliable guess is reported but scanned language-neutral
+	out := redetect(fixedLang{"de", false}, nil)
+	if out.Lang != "de" || out.LangReliable == nil || *out.LangReliable || len(out.Current) == 0 {
+		t.Fatalf("unreliable guess: lang %q/%v, %d hits", out.Lang, out.LangReliable, len(out.Current))
+	}
+	// A reliable one scopes the scan
+	if out := redetect(fixedLang{"de", true}, nil); len(out.Current) != 0 {
+		t.Fatalf("reliable guess should scope the scan, got %d hits", len(out.Current))
+	}
+	// A stored lang_code is used as is, without inference
+	en := "en"
+	out = redetect(fixedLang{"de", true}, &en)
+	if out.Lang != "en" || out.LangReliable != nil || len(out.Current) == 0 {
+		t.Fatalf("stored lang: %q/%v, %d hits", out.Lang, out.LangReliable, len(out.Current))
+	}
+}
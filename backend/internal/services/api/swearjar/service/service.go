@@ -3,6 +3,7 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"swearjar/internal/modkit/repokit"
 	"swearjar/internal/services/api/swearjar/domain"
@@ -11,8 +12,20 @@ import (
 
 // Service is the concrete implementation of domain.ServicePort
 type Service struct {
-	DB   repokit.TxRunner
-	Repo repokit.Binder[srepo.StorageRepo]
+	DB    repokit.TxRunner
+	Repo  repokit.Binder[srepo.StorageRepo]
+	Block *TermBlocklist // nil = no term filtering
+
+	// OverviewWorkers bounds concurrent panel queries per Overview call (<= 0 = all at once)
+	OverviewWorkers int
+
+	// FreshnessLag labels windows ending within this much of now with their freshness (0 = never)
+	FreshnessLag time.Duration
+
+	// CurrentDetVer is the loaded rulepack's detector version; DefaultDetVer filters queries
+	// without a detver (0 = none). Both are reported by DetectorMeta
+	CurrentDetVer int
+	DefaultDetVer int
 }
 
 // New constructs a swearjar service
@@ -26,6 +39,30 @@ func New(db repokit.TxRunner, binder repokit.Binder[srepo.StorageRepo]) *Service
 	return &Service{DB: db, Repo: binder}
 }
 
+// WithTermBlocklist sets the API-layer term blocklist (nil disables it)
+func (s *Service) WithTermBlocklist(b *TermBlocklist) *Service {
+	s.Block = b
+	return s
+}
+
+// WithOverviewConcurrency bounds concurrent panel queries per Overview call
+func (s *Service) WithOverviewConcurrency(n int) *Service {
+	s.OverviewWorkers = n
+	return s
+}
+
+// WithFreshnessLag sets how recent a window must end to carry a freshness label (0 disables it)
+func (s *Service) WithFreshnessLag(d time.Duration) *Service {
+	s.FreshnessLag = d
+	return s
+}
+
+// WithDetVer records the pack's detector version and the default detver filter for DetectorMeta
+func (s *Service) WithDetVer(current, def int) *Service {
+	s.CurrentDetVer, s.DefaultDetVer = current, def
+	return s
+}
+
 // TimeseriesHits returns timeseries of the swearjar
 func (s *Service) TimeseriesHits(
 	ctx context.Context,
@@ -37,6 +74,9 @@ func (s *Service) TimeseriesHits(
 		out, e = s.Repo.Bind(q).TimeseriesHits(ctx, in)
 		return e
 	})
+	if err == nil {
+		out.Freshness = s.freshness(ctx, in.Range)
+	}
 	return out, err
 }
 
@@ -48,6 +88,13 @@ func (s *Service) HeatmapWeekly(ctx context.Context, in domain.HeatmapWeeklyInpu
 		out, e = s.Repo.Bind(q).HeatmapWeekly(ctx, in)
 		return e
 	})
+	if err == nil && in.BusinessHours != nil {
+		split := splitBusinessHours(out.Grid, *in.BusinessHours)
+		out.BusinessHours = &split
+	}
+	if err == nil {
+		out.Freshness = s.freshness(ctx, in.Range)
+	}
 	return out, err
 }
 
@@ -87,6 +134,20 @@ func (s *Service) CodeLangBars(ctx context.Context, in domain.CodeLangBarsInput)
 	return out, err
 }
 
+// CodeLangTimeseries returns hits per repo primary language over time
+func (s *Service) CodeLangTimeseries(
+	ctx context.Context,
+	in domain.CodeLangTimeseriesInput,
+) (domain.CodeLangTimeseriesResp, error) {
+	var out domain.CodeLangTimeseriesResp
+	err := s.DB.Tx(ctx, func(q repokit.Queryer) error {
+		var e error
+		out, e = s.Repo.Bind(q).CodeLangTimeseries(ctx, in)
+		return e
+	})
+	return out, err
+}
+
 // CategoriesStack returns category and severity mix
 func (s *Service) CategoriesStack(
 	ctx context.Context,
@@ -98,6 +159,9 @@ func (s *Service) CategoriesStack(
 		out, e = s.Repo.Bind(q).CategoriesStack(ctx, in)
 		return e
 	})
+	if err == nil {
+		out.Freshness = s.freshness(ctx, in.Range)
+	}
 	return out, err
 }
 
@@ -109,11 +173,14 @@ func (s *Service) TopTerms(ctx context.Context, in domain.TopTermsInput) (domain
 		out, e = s.Repo.Bind(q).TopTerms(ctx, in)
 		return e
 	})
-	return out, err
+	return s.Block.topTerms(out), err
 }
 
 // TermTimeline is unimplemented
 func (s *Service) TermTimeline(ctx context.Context, in domain.TermTimelineInput) (domain.TermTimelineResp, error) {
+	if err := s.Block.reject("terms", in.Terms...); err != nil {
+		return domain.TermTimelineResp{}, err
+	}
 	var out domain.TermTimelineResp
 	err := s.DB.Tx(ctx, func(q repokit.Queryer) error {
 		var e error
@@ -142,10 +209,10 @@ func (s *Service) TermsMatrix(ctx context.Context, in domain.TermsMatrixInput) (
 		out, e = s.Repo.Bind(q).TermsMatrix(ctx, in)
 		return e
 	})
-	return out, err
+	return s.Block.termsMatrix(out), err
 }
 
-// RepoOverview is unimplemented
+// RepoOverview returns the repo lens, optionally against the global baseline
 func (s *Service) RepoOverview(ctx context.Context, in domain.RepoOverviewInput) (domain.RepoOverviewResp, error) {
 	var out domain.RepoOverviewResp
 	err := s.DB.Tx(ctx, func(q repokit.Queryer) error {
@@ -153,11 +220,14 @@ func (s *Service) RepoOverview(ctx context.Context, in domain.RepoOverviewInput)
 		out, e = s.Repo.Bind(q).RepoOverview(ctx, in)
 		return e
 	})
-	return out, err
+	return s.Block.repoOverview(out), err
 }
 
-// Samples is unimplemented
+// Samples returns masked sample cards with all hit spans
 func (s *Service) Samples(ctx context.Context, in domain.SamplesInput) (domain.SamplesResp, error) {
+	if err := s.Block.reject("term", in.Term); err != nil {
+		return domain.SamplesResp{}, err
+	}
 	var out domain.SamplesResp
 	err := s.DB.Tx(ctx, func(q repokit.Queryer) error {
 		var e error
@@ -167,6 +237,18 @@ func (s *Service) Samples(ctx context.Context, in domain.SamplesInput) (domain.S
 	return out, err
 }
 
+// ExportHits streams anonymized hit records to emit and returns the resume cursor ("" when done)
+// It runs outside a transaction: the export may outlive a PG tx budget and only reads ClickHouse
+// workers bounds the concurrent sub-range scans of an in.Parallel export (<= 1 scans serially)
+func (s *Service) ExportHits(
+	ctx context.Context,
+	in domain.ExportHitsInput,
+	maxRows, workers int,
+	emit func(domain.ExportHitRecord) error,
+) (string, error) {
+	return s.Repo.Bind(s.DB).ExportHits(ctx, in, maxRows, workers, emit)
+}
+
 // RatiosTime is unimplemented
 func (s *Service) RatiosTime(ctx context.Context, in domain.RatiosTimeInput) (domain.RatiosTimeResp, error) {
 	var out domain.RatiosTimeResp
@@ -203,23 +285,47 @@ func (s *Service) SpikeDrivers(ctx context.Context, in domain.SpikeDriversInput)
 	return out, err
 }
 
-// ActorsLeaderboard is unimplemented
+// ActorsLeaderboard ranks actors by hits in the window (IncludeZero: cleanest cohort members first)
 func (s *Service) ActorsLeaderboard(
-	_ context.Context,
-	_ domain.ActorsLeaderboardInput,
+	ctx context.Context,
+	in domain.ActorsLeaderboardInput,
 ) (domain.ActorsLeaderboardResp, error) {
-	return domain.ActorsLeaderboardResp{Items: []domain.ActorsLeaderboardRow{}}, nil
+	var out domain.ActorsLeaderboardResp
+	err := s.DB.Tx(ctx, func(q repokit.Queryer) error {
+		var e error
+		out, e = s.Repo.Bind(q).ActorsLeaderboard(ctx, in)
+		return e
+	})
+	return out, err
 }
 
-// ReposLeaderboard is unimplemented
+// ReposLeaderboard ranks repos by hits in the window (IncludeZero: cleanest cohort members first)
 func (s *Service) ReposLeaderboard(
-	_ context.Context,
-	_ domain.ReposLeaderboardInput,
+	ctx context.Context,
+	in domain.ReposLeaderboardInput,
 ) (domain.ReposLeaderboardResp, error) {
-	return domain.ReposLeaderboardResp{Items: []domain.ReposLeaderboardRow{}}, nil
+	var out domain.ReposLeaderboardResp
+	err := s.DB.Tx(ctx, func(q repokit.Queryer) error {
+		var e error
+		out, e = s.Repo.Bind(q).ReposLeaderboard(ctx, in)
+		return e
+	})
+	return out, err
+}
+
+// QuietStreaks ranks repos or actors by days since their last offense
+func (s *Service) QuietStreaks(ctx context.Context, in domain.QuietStreaksInput) (domain.QuietStreaksResp, error) {
+	var out domain.QuietStreaksResp
+	err := s.DB.Tx(ctx, func(q repokit.Queryer) error {
+		var e error
+		out, e = s.Repo.Bind(q).QuietStreaks(ctx, in)
+		return e
+	})
+	return out, err
 }
 
-// TermsSuggest is unimplemented
+// TermsSuggest is unimplemented and suggests nothing; once it reads terms they must go through
+// Block.strings like every other term-bearing response
 func (s *Service) TermsSuggest(_ context.Context, _ domain.TermsSuggestInput) (domain.TermsSuggestResp, error) {
 	return domain.TermsSuggestResp{Terms: []string{}}, nil
 }
@@ -232,8 +338,8 @@ func (s *Service) TimeseriesHourly(
 	return domain.TimeseriesHourlyResp{Series: []domain.TimeseriesHourlyPoint{}}, nil
 }
 
-// ActorOverview is unimplemented
-func (s *Service) ActorOverview(_ context.Context, in domain.ActorOverviewInput) (domain.ActorOverviewResp, error) {
+// ActorOverview is a stub apart from the optional first offense
+func (s *Service) ActorOverview(ctx context.Context, in domain.ActorOverviewInput) (domain.ActorOverviewResp, error) {
 	out := domain.ActorOverviewResp{}
 	out.Actor.HID = in.ActorHID
 	out.Actor.Label = in.ActorHID[0:6] + "..." + in.ActorHID[len(in.ActorHID)-6:]
@@ -243,6 +349,16 @@ func (s *Service) ActorOverview(_ context.Context, in domain.ActorOverviewInput)
 		Term string `json:"term" example:"fuck"`
 		Hits int64  `json:"hits" example:"4"`
 	}{}
+	if in.FirstOffense {
+		err := s.DB.Tx(ctx, func(q repokit.Queryer) error {
+			var e error
+			out.FirstOffense, e = s.Repo.Bind(q).FirstOffense(ctx, "actor", in.ActorHID)
+			return e
+		})
+		if err != nil {
+			return domain.ActorOverviewResp{}, err
+		}
+	}
 	return out, nil
 }
 
@@ -265,6 +381,9 @@ func (s *Service) KPIStrip(
 		out, e = s.Repo.Bind(q).KPIStrip(ctx, in)
 		return e
 	})
+	if err == nil {
+		out.Freshness = s.freshness(ctx, in.Range)
+	}
 	return out, err
 }
 
@@ -281,3 +400,25 @@ func (s *Service) YearlyTrends(
 	})
 	return out, err
 }
+
+// Facets returns the NL and code languages present in the data for filter dropdowns
+func (s *Service) Facets(ctx context.Context) (domain.FacetsResp, error) {
+	var out domain.FacetsResp
+	err := s.DB.Tx(ctx, func(q repokit.Queryer) error {
+		var e error
+		out, e = s.Repo.Bind(q).Facets(ctx)
+		return e
+	})
+	return out, err
+}
+
+// Search finds opted-in repos/actors by name and returns their HIDs
+func (s *Service) Search(ctx context.Context, in domain.SearchInput) (domain.SearchResp, error) {
+	var out domain.SearchResp
+	err := s.DB.Tx(ctx, func(q repokit.Queryer) error {
+		var e error
+		out, e = s.Repo.Bind(q).Search(ctx, in)
+		return e
+	})
+	return out, err
+}
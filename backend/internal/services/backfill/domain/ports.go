@@ -13,6 +13,9 @@ type RunnerPort interface {
 	PlanRange(ctx context.Context, start, end time.Time) error
 
 	RunResume(ctx context.Context) error
+
+	// Progress reports hour status counts and totals for [start, end] (read-only)
+	Progress(ctx context.Context, start, end time.Time) (RangeProgress, error)
 }
 
 // StorageRepo is the storage repository interface
@@ -28,6 +31,15 @@ type StorageRepo interface {
 	// It is recommended to batch inserts (e.g. 1000s of rows) for performance
 	InsertUtterances(ctx context.Context, us []Utterance) (inserted, deduped int, err error)
 
+	// InsertRawEvents stores sampled raw event lines (debugging/replay aid); empty slice is a no-op
+	InsertRawEvents(ctx context.Context, evs []RawEvent) (int, error)
+
+	// DetectDone reports whether detection at detver finished for every utterance of the hour
+	DetectDone(ctx context.Context, hourUTC time.Time, detver int) (bool, error)
+
+	// MarkDetected records that detection at detver finished for the hour
+	MarkDetected(ctx context.Context, hourUTC time.Time, detver int) error
+
 	// Bulk-seed ingest_hours with status 'pending'
 	// Returns number of rows inserted (ignores conflicts)
 	PreseedHours(ctx context.Context, startUTC, endUTC time.Time) (int, error)
@@ -38,6 +50,9 @@ type StorageRepo interface {
 	NextHourToProcess(ctx context.Context, startUTC, endUTC time.Time) (time.Time, bool, error)
 
 	NextHourToProcessAny(ctx context.Context) (time.Time, bool, error)
+
+	// Progress aggregates ingest_hours rows in [startUTC, endUTC] by bf_status with totals
+	Progress(ctx context.Context, startUTC, endUTC time.Time) (RangeProgress, error)
 }
 
 // LookupRow is what LookupIDs returns per natural key
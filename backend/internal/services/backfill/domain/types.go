@@ -10,6 +10,9 @@ import (
 // EventEnvelope re-exports the event envelope shape used by the extractor and reader
 type EventEnvelope = gharchive.EventEnvelope
 
+// ErrHourNotYetAvailable is returned by Fetchers for recent hours GH Archive has not posted yet
+var ErrHourNotYetAvailable = gharchive.ErrHourNotYetAvailable
+
 // HourRef is a reference to a specific hour
 type HourRef struct{ Year, Month, Day, Hour int }
 
@@ -32,6 +35,9 @@ type HourFinish struct {
 	DBMS              int
 	ElapsedMS         int
 	ErrText           string
+	EventCap          int // non-zero when reading stopped at Config.MaxEventsPerHour (partial hour)
+	Collapsed         int // duplicate utterances folded into a survivor (Config.CollapseDupes)
+	SkippedShort      int // utterances not inserted for too little normalized text (Config.MinTextRunes)
 }
 
 // Utterance is a single utterance extracted from an event
@@ -43,7 +49,20 @@ type Utterance struct {
 	Source, SourceDetail    string
 	Ordinal                 int
 	TextRaw, TextNormalized string
+	TextTruncated           bool // TextRaw was cut to the extraction byte cap
 	LangCode                *string
+	DupCount                int // identical texts this row stands for when collapsing (0 = not collapsed)
+}
+
+// RawEvent is a sampled original event line kept so extraction can be replayed
+// without re-downloading the hour. Only written when raw sampling is enabled
+type RawEvent struct {
+	EventID         string // deterministic UUID derived from the raw line (stable across replays)
+	HourUTC         time.Time
+	EventType       string
+	CreatedAt       time.Time
+	RepoID, ActorID int64  // used only to derive HIDs; not persisted
+	Payload         []byte // the full original event line
 }
 
 // BackfillStatus represents the status of an ingest hour
@@ -62,3 +81,24 @@ const (
 	// BackfillError is the error state
 	BackfillError BackfillStatus = "error"
 )
+
+// RangeProgress summarizes ingest_hours for an inclusive hour range
+// Hours not yet seeded (no ingest_hours row) are counted as Unplanned
+type RangeProgress struct {
+	Start, End time.Time // inclusive, truncated to the hour (UTC)
+	TotalHours int       // hours in [Start, End]
+	Unplanned  int       // hours with no ingest_hours row yet
+
+	ByStatus map[BackfillStatus]int
+
+	BytesUncompressed int64 // sum over finished hours
+	EventsScanned     int64
+	Utterances        int64 // utterances_extracted
+	Inserted          int64
+	Deduped           int64
+	Collapsed         int64
+	SkippedShort      int64
+
+	FirstStarted *time.Time // earliest started_at in range (nil when nothing started)
+	LastFinished *time.Time // latest finished_at in range (nil when nothing finished)
+}
@@ -14,6 +14,10 @@ import (
 // ErrLeaseHeld signals another worker owns the hour already
 var ErrLeaseHeld = errors.New("backfill: hour lease already held")
 
+// ErrLeaseLost signals the lease could not be renewed while the hour was processing;
+// the hour's context is cancelled with this cause so it is not finished twice
+var ErrLeaseLost = errors.New("backfill: hour lease lost")
+
 // MakeAdvisoryLease returns a function that tries to claim a cooperative lease
 // for the given hour inside the unified ingest_hours table. It uses an
 // expires_at field to auto-reclaim leases after crashes.
@@ -27,47 +31,111 @@ var ErrLeaseHeld = errors.New("backfill: hour lease already held")
 // The function treats a non-claimed/non-expired row as claimable, and returns
 // ErrLeaseHeld when another worker currently owns a non-expired lease.
 //
-// No explicit release is performed - the lease is time-based; if you want,
-// you can clear/refresh it elsewhere, but this keeps the simple one-shot model
+// While do runs, the lease is extended by ttl every renew interval (<=0 -> ttl/3), so a long
+// hour never outlives it. If a renewal finds the lease owned by someone else, or renewals keep
+// failing until the lease would expire, do's context is cancelled with ErrLeaseLost and that
+// error is returned. The lease is released once do returns
 func MakeAdvisoryLease(
 	deps modkit.Deps,
 	owner string,
 	ttl time.Duration,
+	renew time.Duration,
 ) func(ctx context.Context, hour time.Time, do func(context.Context) error) error {
 	owner = fmt.Sprintf("%s:%d", owner, os.Getpid())
 
 	if ttl <= 0 {
 		ttl = 3 * time.Minute
 	}
+	if renew <= 0 || renew >= ttl {
+		renew = ttl / 3
+	}
 
 	toInterval := func(d time.Duration) string {
 		return fmt.Sprintf("%d seconds", int64(d/time.Second))
 	}
 
-	return func(ctx context.Context, hour time.Time, do func(context.Context) error) error {
-		var claimed bool
+	// exec runs a lease UPDATE ... RETURNING true; false means no row matched. Any other
+	// failure is returned as an error so a transient PG hiccup isn't read as a lost lease
+	exec := func(ctx context.Context, query string, args ...any) (bool, error) {
+		var ok bool
 		err := deps.PG.Tx(ctx, func(q store.RowQuerier) error {
-			row := q.QueryRow(ctx, `
-				UPDATE ingest_hours
-				   SET bf_lease_claimed_at = now(), bf_lease_owner = $2, bf_lease_expires_at = now() + ($3)::interval
-				 WHERE hour_utc = $1
-				   AND (bf_lease_claimed_at IS NULL OR bf_lease_expires_at <= now())
-				RETURNING true
-			`, hour.UTC(), owner, toInterval(ttl))
-			var ok bool
-			if scanErr := row.Scan(&ok); scanErr != nil {
-				// no rows -> couldn't claim
+			err := q.QueryRow(ctx, query, args...).Scan(&ok)
+			if store.IsNoRows(err) {
+				ok = false // no rows -> not ours
 				return nil
 			}
-			claimed = ok
-			return nil
+			return err
 		})
+		return ok, err
+	}
+
+	return func(ctx context.Context, hour time.Time, do func(context.Context) error) error {
+		claimed, err := exec(ctx, `
+			UPDATE ingest_hours
+			   SET bf_lease_claimed_at = now(), bf_lease_owner = $2, bf_lease_expires_at = now() + ($3)::interval
+			 WHERE hour_utc = $1
+			   AND (bf_lease_claimed_at IS NULL OR bf_lease_expires_at <= now())
+			RETURNING true
+		`, hour.UTC(), owner, toInterval(ttl))
 		if err != nil {
 			return err
 		}
 		if !claimed {
 			return ErrLeaseHeld
 		}
-		return do(ctx)
+
+		runCtx, cancel := context.WithCancelCause(ctx)
+		defer cancel(nil)
+
+		stop := make(chan struct{})
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			t := time.NewTicker(renew)
+			defer t.Stop()
+			expires := time.Now().Add(ttl)
+			for {
+				select {
+				case <-stop:
+					return
+				case <-runCtx.Done():
+					return
+				case <-t.C:
+				}
+				ok, rerr := exec(runCtx, `
+					UPDATE ingest_hours
+					   SET bf_lease_expires_at = now() + ($3)::interval
+					 WHERE hour_utc = $1 AND bf_lease_owner = $2
+					RETURNING true
+				`, hour.UTC(), owner, toInterval(ttl))
+				switch {
+				case rerr == nil && ok:
+					expires = time.Now().Add(ttl)
+				case rerr == nil:
+					cancel(ErrLeaseLost) // reclaimed by another worker
+					return
+				case time.Now().Add(renew).After(expires):
+					// transient errors are tolerated until the next attempt would be too late
+					cancel(fmt.Errorf("%w: %w", ErrLeaseLost, rerr))
+					return
+				}
+			}
+		}()
+
+		err = do(runCtx)
+		close(stop)
+		<-done
+		if cause := context.Cause(runCtx); errors.Is(cause, ErrLeaseLost) {
+			return cause
+		}
+
+		// Best-effort release; if it fails the lease simply expires after ttl
+		_, _ = exec(context.WithoutCancel(ctx), `
+			UPDATE ingest_hours
+			   SET bf_lease_claimed_at = NULL, bf_lease_owner = NULL, bf_lease_expires_at = NULL
+			 WHERE hour_utc = $1 AND bf_lease_owner = $2
+			RETURNING true
+		`, hour.UTC(), owner)
+		return err
 	}
 }
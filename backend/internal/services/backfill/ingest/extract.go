@@ -6,14 +6,16 @@ import (
 	"swearjar/internal/services/backfill/domain"
 )
 
-type extractor struct{}
+type extractor struct{ opts extract.Options }
 
-// NewExtractor constructs a new Extractor
-func NewExtractor() domain.Extractor { return extractor{} }
+// NewExtractor constructs a new Extractor; maxTextBytes caps each utterance's text (0 = no cap)
+func NewExtractor(maxTextBytes int) domain.Extractor {
+	return extractor{opts: extract.Options{MaxTextBytes: maxTextBytes}}
+}
 
 // FromEvent extracts utterances from a gharchive event envelope
-func (extractor) FromEvent(env domain.EventEnvelope, n domain.Normalizer) []domain.Utterance {
-	us := extract.FromEvent(env, normalizerAdapter{n})
+func (e extractor) FromEvent(env domain.EventEnvelope, n domain.Normalizer) []domain.Utterance {
+	us := extract.FromEventWithOptions(env, normalizerAdapter{n}, e.opts)
 
 	out := make([]domain.Utterance, 0, len(us))
 	for i := range us {
@@ -38,6 +40,7 @@ func (extractor) FromEvent(env domain.EventEnvelope, n domain.Normalizer) []doma
 			SourceDetail:   sourceDet,
 			TextRaw:        textRaw,
 			TextNormalized: u.TextNormalized, // already sanitized via Normalizer.Normalize
+			TextTruncated:  u.Truncated,
 			Ordinal:        u.Ordinal,
 		})
 	}
@@ -12,27 +12,43 @@ import (
 	"swearjar/internal/adapters/ingest/gharchive"
 )
 
-// fetcher implements domain.Fetcher using the cached GH Archive fetcher
+// fetcher implements domain.Fetcher using the cached GH Archive fetcher, or a local
+// directory of hour files when localDir or CORE_INGEST_LOCAL_DIR is set (offline backfill)
 type fetcher struct {
 	f gharchive.Fetcher
 }
 
 // NewFetcher constructs a domain.Fetcher from config under CORE_INGEST_*.
-// This keeps config-reading outside service and avoids passing platform deps into repos
-func NewFetcher(deps modkit.Deps) domain.Fetcher {
+// This keeps config-reading outside service and avoids passing platform deps into repos.
+// A non-empty localDir takes precedence over CORE_INGEST_LOCAL_DIR
+func NewFetcher(deps modkit.Deps, localDir string) domain.Fetcher {
 	ing := deps.Cfg.Prefix("CORE_INGEST_")
 
+	if localDir == "" {
+		localDir = ing.MayString("LOCAL_DIR", "")
+	}
+	if localDir != "" {
+		return &fetcher{f: gharchive.NewDirFetcher(localDir)}
+	}
+
 	cacheDir := ing.MustString("CACHE_DIR")
 	refreshH := time.Duration(ing.MayInt("REFRESH_RECENT_HOURS", 0)) * time.Hour
 	retainDays := ing.MayInt("RETAIN_MAX_DAYS", 0)
 	retainBytes := int64(ing.MayInt("RETAIN_MAX_BYTES", 0))
 
 	httpTO := time.Duration(ing.MayInt("HTTP_TIMEOUT_SECONDS", 0)) * time.Second // 0 == no client timeout
+	// mirror layout, e.g. "https://mirror.example/{year}/{month}/{day}/{hour2}.json.gz"; empty = gharchive.org
+	urlTpl := gharchive.MustParseURLTemplate(ing.MayString("URL_TEMPLATE", ""))
 
 	return &fetcher{
 		f: gharchive.NewCachedFetcher(
 			cacheDir,
-			gharchive.NewHTTPFetcherWithTimeout(httpTO),
+			gharchive.NewHTTPFetcher(gharchive.HTTPOptions{
+				Timeout:             httpTO,
+				MaxIdleConnsPerHost: ing.MayInt("HTTP_MAX_IDLE_PER_HOST", 0),
+				IdleConnTimeout:     ing.MayDuration("HTTP_IDLE_CONN_TIMEOUT", 0),
+				URL:                 urlTpl,
+			}),
 			gharchive.WithRefreshRecent(refreshH),
 			gharchive.WithRetention(time.Duration(retainDays)*24*time.Hour, retainBytes),
 		),
@@ -3,6 +3,7 @@ package module
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"swearjar/internal/modkit"
@@ -36,24 +37,45 @@ type Module struct {
 }
 
 // New constructs the backfill module
-// It wires adapters and the service using config from deps.Cfg.
-// If detection is enabled (CORE_BACKFILL_DETECT or config), it looks up the
+// It wires adapters and the service using config from deps.Cfg, with non-zero overrides
+// (the CLI's flags) taking precedence.
+// If detection is enabled (CORE_BACKFILL_DETECT or overrides), it looks up the
 // already-registered detect module from the global registry and assigns its
 // Writer port to svc.DetectorPort.
 // If nightshift is enabled (CORE_BACKFILL_NIGHTSHIFT or config), it looks up the
 // already-registered nightshift module from the global registry and assigns its
 // Runner port to svc.NightshiftHour
-func New(deps modkit.Deps) *Module {
+func New(deps modkit.Deps, overrides Options) *Module {
 	opts := FromConfig(deps.Cfg)
+	if overrides.MaxEventsPerHour != 0 {
+		opts.MaxEventsPerHour = overrides.MaxEventsPerHour
+	}
+	if overrides.DetectVersion != 0 {
+		opts.DetectVersion = overrides.DetectVersion
+	}
+	if overrides.CollapseDupes != "" {
+		opts.CollapseDupes = strings.ToLower(overrides.CollapseDupes)
+	}
+	if overrides.PipelineDepth != 0 {
+		opts.PipelineDepth = overrides.PipelineDepth
+	}
+	if overrides.LocalDir != "" {
+		opts.LocalDir = overrides.LocalDir
+	}
+	opts.DetectEnabled = opts.DetectEnabled || overrides.DetectEnabled
+	opts.ForceDetect = opts.ForceDetect || overrides.ForceDetect
 
 	storeBinder := repo.NewHybrid(deps.CH)
 
 	// Non-DB adapters
-	fetch := ingest.NewFetcher(deps)
+	fetch := ingest.NewFetcher(deps, opts.LocalDir)
 	reader := ingest.NewReaderFactory()
-	extract := ingest.NewExtractor()
-	norm := ingest.NewNormalizer(normalize.New())
-	leaseFn := guardrails.MakeAdvisoryLease(deps, "backfill", opts.LeaseTTL)
+	extract := ingest.NewExtractor(opts.MaxTextBytes)
+	norm := ingest.NewNormalizer(normalize.NewWithOptions(normalize.Options{
+		DropQuotes: opts.DropQuotes,
+		DropDiffs:  opts.DropDiffs,
+	}))
+	leaseFn := guardrails.MakeAdvisoryLease(deps, "backfill", opts.LeaseTTL, opts.LeaseRenew)
 
 	var detWriter detectdom.WriterPort
 	if opts.DetectEnabled {
@@ -80,16 +102,27 @@ func New(deps modkit.Deps) *Module {
 		extract,
 		norm,
 		service.Config{
-			DelayPerHour:  opts.DelayPerHour,
-			Workers:       opts.Workers,
-			MaxRetries:    opts.MaxRetries,
-			RetryBase:     opts.RetryBase,
-			FetchTimeout:  opts.FetchTimeout,
-			ReadTimeout:   opts.ReadTimeout,
-			MaxRangeHours: opts.MaxRangeHours,
-			EnableLeases:  opts.EnableLeases,
-			InsertChunk:   0,
-			DetectEnabled: opts.DetectEnabled,
+			DelayPerHour:     opts.DelayPerHour,
+			Workers:          opts.Workers,
+			MaxRetries:       opts.MaxRetries,
+			RetryBase:        opts.RetryBase,
+			FetchTimeout:     opts.FetchTimeout,
+			ReadTimeout:      opts.ReadTimeout,
+			MaxRangeHours:    opts.MaxRangeHours,
+			MaxEventsPerHour: opts.MaxEventsPerHour,
+			EnableLeases:     opts.EnableLeases,
+			InsertChunk:      0,
+			DetectEnabled:    opts.DetectEnabled,
+			DetectVersion:    opts.DetectVersion,
+			ForceDetect:      opts.ForceDetect,
+
+			RawSampleFraction:   opts.RawSampleFraction,
+			RawSampleMaxPerHour: opts.RawSampleMaxPerHour,
+			RawSampleMaxBytes:   opts.RawSampleMaxBytes,
+
+			CollapseDupes: opts.CollapseDupes,
+			PipelineDepth: opts.PipelineDepth,
+			MinTextRunes:  opts.minTextRunes(),
 		},
 		leaseFn,
 		detWriter,
@@ -1,9 +1,11 @@
 package module
 
 import (
+	"strings"
 	"time"
 
 	"swearjar/internal/platform/config"
+	"swearjar/internal/services/backfill/service"
 )
 
 // Options holds configuration options for the backfill service
@@ -15,29 +17,88 @@ type Options struct {
 	FetchTimeout  time.Duration
 	ReadTimeout   time.Duration
 	MaxRangeHours int
-	EnableLeases  bool
-	LeaseTTL      time.Duration
+	// MaxEventsPerHour caps events read per hour for smoke tests (0 = unlimited)
+	MaxEventsPerHour int
+	EnableLeases     bool
+	LeaseTTL         time.Duration
+	// LeaseRenew extends a held lease by LeaseTTL at this interval while the hour runs (0 = LeaseTTL/3)
+	LeaseRenew time.Duration
 	// Detect integration
 	DetectEnabled bool
 	DetectVersion int
 	DetectDryRun  bool
+	// ForceDetect re-runs detection even when the hour was already detected at DetectVersion
+	ForceDetect bool
+	// Raw event sampling (off by default)
+	RawSampleFraction   float64
+	RawSampleMaxPerHour int
+	RawSampleMaxBytes   int
+	// CollapseDupes folds identical texts per hour: "" (off), "repo" or "global"
+	CollapseDupes string
+	// DropQuotes strips '>' quoted reply lines during normalization, before insert and detection
+	DropQuotes bool
+	// DropDiffs strips pasted unified-diff hunks (squash-merge bodies) the same way
+	DropDiffs bool
+	// MaxTextBytes truncates each utterance's text to its first N bytes (rune-safe) at extraction,
+	// flagging the row text_truncated; 0 keeps texts whole
+	MaxTextBytes int
+	// LocalDir reads hours from <dir>/<hour>.json.gz instead of GH Archive (offline backfill);
+	// empty falls back to CORE_INGEST_LOCAL_DIR
+	LocalDir string
+	// PipelineDepth > 0 streams each hour through a bounded read->insert queue of this many chunks
+	PipelineDepth int
+	// SkipShortText skips inserting utterances whose normalized text is empty or shorter than
+	// MinTextRunes runes, so they neither bloat utterances nor reach detection. utt_hour_agg
+	// counts utterances, so skipped rows also leave the per_utterance, coverage and rarity
+	// denominators
+	SkipShortText bool
+	MinTextRunes  int
 }
 
 // FromConfig reads the backfill options from config with CORE_BACKFILL_ prefix
 func FromConfig(cfg config.Conf) Options {
 	bf := cfg.Prefix("CORE_BACKFILL_")
 	return Options{
-		DelayPerHour:  bf.MayDuration("DELAY", 0),
-		Workers:       bf.MayInt("WORKERS", 4),
-		MaxRetries:    bf.MayInt("RETRIES", 3),
-		RetryBase:     bf.MayDuration("RETRY_BASE", 500*time.Millisecond),
-		FetchTimeout:  bf.MayDuration("FETCH_TIMEOUT", 10*time.Minute), // was 60s
-		ReadTimeout:   bf.MayDuration("READ_TIMEOUT", 10*time.Minute),
-		MaxRangeHours: bf.MayInt("MAX_RANGE_HOURS", 0),
-		EnableLeases:  bf.MayBool("LEASES", true),
-		LeaseTTL:      bf.MayDuration("LEASE_TTL", 3*time.Minute),
-		DetectEnabled: bf.MayBool("DETECT", false),
-		DetectVersion: bf.MayInt("DET_VERSION", 1),
-		DetectDryRun:  bf.MayBool("DET_DRY_RUN", false),
+		DelayPerHour:     bf.MayDuration("DELAY", 0),
+		Workers:          bf.MayInt("WORKERS", 4),
+		MaxRetries:       bf.MayInt("RETRIES", 3),
+		RetryBase:        bf.MayDuration("RETRY_BASE", 500*time.Millisecond),
+		FetchTimeout:     bf.MayDuration("FETCH_TIMEOUT", 10*time.Minute), // was 60s
+		ReadTimeout:      bf.MayDuration("READ_TIMEOUT", 10*time.Minute),
+		MaxRangeHours:    bf.MayInt("MAX_RANGE_HOURS", 0),
+		MaxEventsPerHour: bf.MayInt("MAX_EVENTS_PER_HOUR", 0),
+		EnableLeases:     bf.MayBool("LEASES", true),
+		LeaseTTL:         bf.MayDuration("LEASE_TTL", 3*time.Minute),
+		LeaseRenew:       bf.MayDuration("LEASE_RENEW", 0),
+		DetectEnabled:    bf.MayBool("DETECT", false),
+		DetectVersion:    bf.MayInt("DET_VERSION", 1),
+		DetectDryRun:     bf.MayBool("DET_DRY_RUN", false),
+		ForceDetect:      bf.MayBool("FORCE_DETECT", false),
+
+		RawSampleFraction:   bf.MayFloat64("RAW_SAMPLE", 0),
+		RawSampleMaxPerHour: bf.MayInt("RAW_SAMPLE_MAX_PER_HOUR", 200),
+		RawSampleMaxBytes:   bf.MayInt("RAW_SAMPLE_MAX_BYTES", 64<<10),
+
+		CollapseDupes: strings.ToLower(
+			bf.MayEnum("COLLAPSE_DUPES", service.CollapseOff, service.CollapseRepo, service.CollapseGlobal),
+		),
+		DropQuotes:    bf.MayBool("DROP_QUOTES", false),
+		DropDiffs:     bf.MayBool("DROP_DIFFS", false),
+		MaxTextBytes:  bf.MayInt("MAX_TEXT_BYTES", 0),
+		PipelineDepth: bf.MayInt("PIPELINE_DEPTH", 0),
+		SkipShortText: bf.MayBool("SKIP_SHORT_TEXT", false),
+		MinTextRunes:  bf.MayInt("MIN_TEXT_RUNES", service.DefaultMinTextRunes),
+	}
+}
+
+// minTextRunes is the service threshold: 0 (insert everything) unless SkipShortText is set,
+// with a non-positive MinTextRunes falling back to the default
+func (o Options) minTextRunes() int {
+	if !o.SkipShortText {
+		return 0
+	}
+	if o.MinTextRunes <= 0 {
+		return service.DefaultMinTextRunes
 	}
+	return o.MinTextRunes
 }
@@ -0,0 +1,84 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"swearjar/internal/services/backfill/domain"
+)
+
+// Progress aggregates ingest_hours over [startUTC, endUTC] (inclusive) by backfill status
+// Read-only; safe to call while workers are claiming hours in the same range
+func (s *hybridStore) Progress(ctx context.Context, startUTC, endUTC time.Time) (domain.RangeProgress, error) {
+	out := domain.RangeProgress{
+		Start:    startUTC.UTC(),
+		End:      endUTC.UTC(),
+		ByStatus: map[domain.BackfillStatus]int{},
+	}
+	if !endUTC.Before(startUTC) {
+		out.TotalHours = int(endUTC.Sub(startUTC)/time.Hour) + 1
+	}
+
+	rows, err := s.pg.Query(ctx, `
+        SELECT
+            bf_status::text,
+            count(*),
+            coalesce(sum(bytes_uncompressed), 0)::bigint,
+            coalesce(sum(events_scanned), 0)::bigint,
+            coalesce(sum(utterances_extracted), 0)::bigint,
+            coalesce(sum(inserted), 0)::bigint,
+            coalesce(sum(deduped), 0)::bigint,
+            coalesce(sum(collapsed), 0)::bigint,
+            coalesce(sum(skipped_short), 0)::bigint,
+            min(started_at),
+            max(finished_at)
+        FROM ingest_hours
+        WHERE hour_utc BETWEEN $1 AND $2
+        GROUP BY bf_status
+    `, out.Start, out.End)
+	if err != nil {
+		return out, err
+	}
+	defer rows.Close()
+
+	seen := 0
+	for rows.Next() {
+		var (
+			status                        string
+			n                             int
+			bytes, events, utts, ins, ded int64
+			col, skp                      int64
+			firstStarted, lastFinished    sql.NullTime
+		)
+		if err := rows.Scan(
+			&status, &n, &bytes, &events, &utts, &ins, &ded, &col, &skp, &firstStarted, &lastFinished,
+		); err != nil {
+			return out, err
+		}
+		out.ByStatus[domain.BackfillStatus(status)] = n
+		out.BytesUncompressed += bytes
+		out.EventsScanned += events
+		out.Utterances += utts
+		out.Inserted += ins
+		out.Deduped += ded
+		out.Collapsed += col
+		out.SkippedShort += skp
+		seen += n
+
+		if firstStarted.Valid && (out.FirstStarted == nil || firstStarted.Time.Before(*out.FirstStarted)) {
+			t := firstStarted.Time.UTC()
+			out.FirstStarted = &t
+		}
+		if lastFinished.Valid && (out.LastFinished == nil || lastFinished.Time.After(*out.LastFinished)) {
+			t := lastFinished.Time.UTC()
+			out.LastFinished = &t
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return out, err
+	}
+
+	out.Unplanned = max(out.TotalHours-seen, 0)
+	return out, nil
+}
@@ -0,0 +1,46 @@
+package repo
+
+import (
+	"context"
+	"strings"
+
+	"swearjar/internal/services/backfill/domain"
+	identdom "swearjar/internal/services/ident/domain"
+)
+
+// InsertRawEvents writes sampled raw event lines into ClickHouse (swearjar.raw_events)
+// The table is ReplacingMergeTree keyed on the deterministic event id, so replays are idempotent
+func (s *hybridStore) InsertRawEvents(ctx context.Context, evs []domain.RawEvent) (int, error) {
+	if len(evs) == 0 {
+		return 0, nil
+	}
+
+	const tableWithCols = "swearjar.raw_events (" +
+		"id, hour_utc, event_type, created_at, repo_hid, actor_hid, payload" +
+		")"
+
+	rows := make([][]any, 0, len(evs))
+	for _, e := range evs {
+		if strings.TrimSpace(e.EventID) == "" || len(e.Payload) == 0 {
+			continue
+		}
+		repoRaw := []byte(identdom.RepoHID32(e.RepoID).Bytes())
+		actorRaw := []byte(identdom.ActorHID32(e.ActorID).Bytes())
+		rows = append(rows, []any{
+			e.EventID,         // id (UUID)
+			e.HourUTC.UTC(),   // hour_utc (DateTime)
+			e.EventType,       // event_type
+			e.CreatedAt.UTC(), // created_at (DateTime64(3))
+			repoRaw,           // repo_hid (FixedString(32))
+			actorRaw,          // actor_hid (FixedString(32))
+			string(e.Payload), // payload (String)
+		})
+	}
+	if len(rows) == 0 {
+		return 0, nil
+	}
+	if err := s.ch.Insert(ctx, tableWithCols, rows); err != nil {
+		return 0, err
+	}
+	return len(rows), nil
+}
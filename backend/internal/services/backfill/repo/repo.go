@@ -60,11 +60,15 @@ func (s *hybridStore) FinishHour(ctx context.Context, hour time.Time, fin domain
             read_ms              = $10,
             db_ms                = $11,
             elapsed_ms           = $12,
-            error                = NULLIF($13,'')
+            error                = NULLIF($13,''),
+            event_cap            = NULLIF($14,0),
+            collapsed            = $15,
+            skipped_short        = $16
         WHERE hour_utc = $1
     `,
 		hour.UTC(), fin.Status, fin.CacheHit, fin.BytesUncompressed, fin.Events, fin.Utterances,
 		fin.Inserted, fin.Deduped, fin.FetchMS, fin.ReadMS, fin.DBMS, fin.ElapsedMS, fin.ErrText,
+		fin.EventCap, fin.Collapsed, fin.SkippedShort,
 	)
 	return err
 }
@@ -90,7 +94,7 @@ func (s *hybridStore) InsertUtterances(ctx context.Context, us []domain.Utteranc
 	const tableWithCols = "swearjar.utterances (" +
 		"id, event_type, repo_hid, actor_hid, hid_key_version," +
 		"created_at, source, source_detail, ordinal, text_raw, text_normalized," +
-		"ingest_batch_id, ver" +
+		"ingest_batch_id, ver, dup_count, text_truncated" +
 		")"
 
 	rows := make([][]any, 0, len(us))
@@ -121,13 +125,15 @@ func (s *hybridStore) InsertUtterances(ctx context.Context, us []domain.Utteranc
 			actorRaw,                              // actor_hid (FixedString(32))
 			1,                                     // hid_key_version
 			u.CreatedAt.UTC(),                     // created_at (DateTime64(3))
-			coerceSource(u.Source),                // source (Enum8) - string ok
+			u.Source,                              // source (Enum8) - coarse, from extract.SourceTable
 			zeroIfEmpty(u.SourceDetail, u.Source), // source_detail (String) - fallback to coarse source if empty
 			int32(u.Ordinal),                      // ordinal (Int32) - already assigned by extractor
 			u.TextRaw,                             // text_raw
 			norm,                                  // text_normalized (Nullable(String))
 			ingestBatchID,                         // ingest_batch_id
 			1,                                     // looks like a mistake, but its for ReplacingMergeTree(ver)
+			uint32(max(u.DupCount, 1)),            // dup_count (UInt32) - >1 only when collapsing
+			boolToUInt8(u.TextTruncated),          // text_truncated (UInt8)
 		}
 		rows = append(rows, row)
 	}
@@ -143,6 +149,27 @@ func (s *hybridStore) InsertUtterances(ctx context.Context, us []domain.Utteranc
 	return len(rows), 0, nil
 }
 
+// DetectDone reads ingest_hours.detected_ver; an hour whose detection stopped partway never gets
+// it set, so hits existing for the hour isn't enough to skip it
+func (s *hybridStore) DetectDone(ctx context.Context, hourUTC time.Time, detver int) (bool, error) {
+	var done bool
+	err := s.pg.QueryRow(ctx, `
+        SELECT coalesce(detected_ver = $2, false) FROM ingest_hours WHERE hour_utc = $1
+    `, hourUTC.Truncate(time.Hour).UTC(), detver).Scan(&done)
+	if store.IsNoRows(err) {
+		return false, nil
+	}
+	return done, err
+}
+
+// MarkDetected stamps ingest_hours.detected_ver once every utterance of the hour went through detect
+func (s *hybridStore) MarkDetected(ctx context.Context, hourUTC time.Time, detver int) error {
+	_, err := s.pg.Exec(ctx, `
+        UPDATE ingest_hours SET detected_ver = $2 WHERE hour_utc = $1
+    `, hourUTC.Truncate(time.Hour).UTC(), detver)
+	return err
+}
+
 func zeroIfEmpty(v, fb string) string {
 	if v == "" {
 		return fb
@@ -150,20 +177,11 @@ func zeroIfEmpty(v, fb string) string {
 	return v
 }
 
-func coerceSource(s string) string {
-	ls := strings.ToLower(s)
-	switch {
-	case strings.HasPrefix(ls, "push:"):
-		return "commit"
-	case strings.HasPrefix(ls, "issues:"):
-		return "issue"
-	case strings.HasPrefix(ls, "pr:"):
-		return "pr"
-	}
-	if strings.Contains(ls, "comment:") {
-		return "comment"
+func boolToUInt8(b bool) uint8 {
+	if b {
+		return 1
 	}
-	return ls
+	return 0
 }
 
 func (s *hybridStore) PreseedHours(ctx context.Context, startUTC, endUTC time.Time) (int, error) {
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"strings"
+
+	"swearjar/internal/services/backfill/domain"
+)
+
+// Duplicate collapsing modes (Config.CollapseDupes)
+// Bots often post the same message thousands of times an hour; collapsing keeps one row per
+// text with DupCount set so analytics can weight (or ignore) the repeats
+const (
+	// CollapseOff keeps every utterance (default)
+	CollapseOff = ""
+	// CollapseRepo collapses identical normalized text within the same repo and hour.
+	// The same text in different repos stays separate (distinct projects, distinct signal)
+	CollapseRepo = "repo"
+	// CollapseGlobal collapses identical normalized text across all repos in the hour
+	CollapseGlobal = "global"
+)
+
+// collapseDupes folds utterances with identical normalized text into the first occurrence,
+// returning the survivors (original order) and how many rows were folded away.
+// Utterances without normalized text are never collapsed. Collapsed rows are neither inserted
+// nor detected, so their hits are represented only through the survivor's DupCount
+func collapseDupes(us []domain.Utterance, mode string) ([]domain.Utterance, int) {
+	if mode == CollapseOff || len(us) < 2 {
+		return us, 0
+	}
+	type key struct {
+		repo int64
+		text string
+	}
+	first := make(map[key]int, len(us))
+	out := us[:0]
+	for _, u := range us {
+		text := strings.TrimSpace(u.TextNormalized)
+		if text == "" {
+			out = append(out, u)
+			continue
+		}
+		k := key{text: text}
+		if mode == CollapseRepo {
+			k.repo = u.RepoID
+		}
+		if i, ok := first[k]; ok {
+			out[i].DupCount++
+			continue
+		}
+		u.DupCount = 1
+		first[k] = len(out)
+		out = append(out, u)
+	}
+	return out, len(us) - len(out)
+}
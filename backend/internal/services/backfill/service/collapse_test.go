@@ -0,0 +1,48 @@
+package service
+
+import (
+	"fmt"
+	"testing"
+
+	"swearjar/internal/services/backfill/domain"
+)
+
+func TestCollapseDupes(t *testing.T) {
+	u := func(id string, repo int64, text string) domain.Utterance {
+		return domain.Utterance{UtteranceID: id, RepoID: repo, TextNormalized: text}
+	}
+	batch := func() []domain.Utterance {
+		return []domain.Utterance{
+			u("a", 1, "lgtm"),
+			u("b", 2, "lgtm"),
+			u("c", 1, " lgtm "),
+			u("d", 1, ""),
+			u("e", 1, "  "),
+			u("f", 2, "wtf"),
+			u("g", 2, "lgtm"),
+		}
+	}
+	cases := []struct {
+		name   string
+		in     []domain.Utterance
+		mode   string
+		want   string // survivors as id:DupCount, in order
+		folded int
+	}{
+		{"off keeps everything", batch(), CollapseOff, "[a:0 b:0 c:0 d:0 e:0 f:0 g:0]", 0},
+		{"repo folds within a repo only", batch(), CollapseRepo, "[a:2 b:2 d:0 e:0 f:1]", 2},
+		{"global folds across repos", batch(), CollapseGlobal, "[a:4 d:0 e:0 f:1]", 3},
+		{"single row is left alone", []domain.Utterance{u("a", 1, "lgtm")}, CollapseGlobal, "[a:0]", 0},
+		{"empty batch", nil, CollapseRepo, "[]", 0},
+	}
+	for _, tc := range cases {
+		out, folded := collapseDupes(tc.in, tc.mode)
+		got := make([]string, len(out))
+		for i, o := range out {
+			got[i] = fmt.Sprintf("%s:%d", o.UtteranceID, o.DupCount)
+		}
+		if fmt.Sprint(got) != tc.want || folded != tc.folded {
+			t.Errorf("%s: got %v (%d folded), want %s (%d folded)", tc.name, got, folded, tc.want, tc.folded)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"swearjar/internal/services/backfill/domain"
+	"swearjar/internal/services/backfill/guardrails"
+)
+
+// readTally is what reading an hour reports back for FinishHour, whichever insert mode is used
+type readTally struct {
+	events   int
+	utts     int
+	eventCap int
+	skipped  int // utterances dropped by Config.MinTextRunes; counted in utts, never inserted
+	raws     []domain.RawEvent
+}
+
+// readHour drains rd, extracting utterances from each event and handing them to emit.
+// Utterances with too little normalized text (Config.MinTextRunes) are counted, not emitted.
+// It stops at EOF, at MaxEventsPerHour, on a reader error, or when emit fails
+func (s *Service) readHour(
+	ctx context.Context,
+	rd domain.ReaderPort,
+	hourUTC time.Time,
+	t *readTally,
+	emit func([]domain.Utterance) error,
+) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		env, e := rd.Next()
+		if e == io.EOF {
+			return nil
+		}
+		if e != nil {
+			return e
+		}
+		if s.Cfg.MaxEventsPerHour > 0 && t.events >= s.Cfg.MaxEventsPerHour {
+			t.eventCap = s.Cfg.MaxEventsPerHour
+			return nil
+		}
+		t.events++
+		if re, ok := s.raw.take(env, hourUTC, len(t.raws)); ok {
+			t.raws = append(t.raws, re)
+		}
+		us := s.Extract.FromEvent(env, s.Norm)
+		if len(us) == 0 {
+			continue
+		}
+		for i := range us {
+			if us[i].SourceDetail == "" {
+				us[i].SourceDetail = us[i].Source
+			}
+		}
+		t.utts += len(us)
+		var n int
+		us, n = skipShortText(us, s.Cfg.MinTextRunes)
+		t.skipped += n
+		if len(us) == 0 {
+			continue
+		}
+		if err := emit(us); err != nil {
+			return err
+		}
+	}
+}
+
+// pipeTally adds the insert-side counters of a pipelined hour to what was read
+type pipeTally struct {
+	readTally
+	inserted  int
+	deduped   int
+	collapsed int
+	readMS    int
+	dbMS      int
+}
+
+// ingestPipelined streams an hour instead of buffering it: the reader fills chunk-sized batches
+// and sends them over a channel bounded at PipelineDepth, while one consumer inserts (and, if
+// detect is set, detects) each batch as it arrives. At most depth+2 chunks are in memory however
+// dense the hour is. CollapseDupes works per chunk here, since no stage sees the whole hour.
+// The first error on either side cancels the other; counts cover the chunks that landed.
+// readMS excludes time the reader spent blocked on a full channel; ReadTimeout still bounds the
+// whole stage, waits included
+func (s *Service) ingestPipelined(
+	ctx context.Context,
+	tos guardrails.Timeouts,
+	rd domain.ReaderPort,
+	hourUTC time.Time,
+	chunk int,
+	detect bool,
+) (pipeTally, error) {
+	pctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	var t pipeTally
+	batches := make(chan []domain.Utterance, s.Cfg.PipelineDepth)
+
+	// Consumer: sole writer of inserted/deduped/dbMS until wg.Wait
+	var (
+		wg      sync.WaitGroup
+		consErr error
+	)
+	wg.Go(func() {
+		for batch := range batches {
+			if consErr != nil {
+				continue // drain so the reader never blocks on a dead consumer
+			}
+			t0 := time.Now()
+			ins, dd, err := s.insertBatchRobust(pctx, batch)
+			t.inserted += ins
+			t.deduped += dd
+			if err == nil && detect {
+				err = s.detectUtterances(pctx, batch, chunk)
+			}
+			t.dbMS += int(time.Since(t0).Milliseconds())
+			if err != nil {
+				consErr = err
+				cancel(err)
+			}
+		}
+	})
+
+	// Producer
+	readCtx, readCancel := guardrails.ForRead(pctx, tos)
+	defer readCancel()
+	t0 := time.Now()
+	var blocked time.Duration
+	buf := make([]domain.Utterance, 0, chunk)
+	flush := func() error {
+		if len(buf) == 0 {
+			return nil
+		}
+		var n int
+		buf, n = collapseDupes(buf, s.Cfg.CollapseDupes)
+		t.collapsed += n
+		tb := time.Now()
+		select {
+		case batches <- buf:
+		case <-pctx.Done():
+			return context.Cause(pctx)
+		}
+		blocked += time.Since(tb)
+		buf = make([]domain.Utterance, 0, chunk)
+		return nil
+	}
+	rerr := s.readHour(readCtx, rd, hourUTC, &t.readTally, func(us []domain.Utterance) error {
+		buf = append(buf, us...)
+		if len(buf) < chunk {
+			return nil
+		}
+		return flush()
+	})
+	if rerr == nil {
+		rerr = flush()
+	}
+	t.readMS = int((time.Since(t0) - blocked).Milliseconds())
+	close(batches)
+	wg.Wait()
+
+	// A consumer failure surfaces on the reader as a cancellation; report the cause
+	if consErr != nil {
+		return t, consErr
+	}
+	return t, rerr
+}
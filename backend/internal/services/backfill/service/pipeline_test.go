@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"swearjar/internal/modkit/repokit"
+	"swearjar/internal/platform/store"
+	"swearjar/internal/services/backfill/domain"
+	"swearjar/internal/services/backfill/guardrails"
+)
+
+// noopQ swallows the tx tuning statements; InsertUtterances never reaches it
+type noopQ struct{ repokit.Queryer }
+
+func (noopQ) Exec(context.Context, string, ...any) (store.CommandTag, error) { return nil, nil }
+
+// fakeTx runs fn against noopQ instead of a transaction
+type fakeTx struct{ repokit.TxRunner }
+
+func (fakeTx) Tx(_ context.Context, fn func(q repokit.Queryer) error) error { return fn(noopQ{}) }
+
+// insertLog records inserted batches and fails the failAt-th insert (1-based; 0 = never)
+type insertLog struct {
+	domain.StorageRepo
+
+	batches []int
+	failAt  int
+}
+
+func (l *insertLog) InsertUtterances(_ context.Context, us []domain.Utterance) (int, int, error) {
+	if l.failAt > 0 && len(l.batches)+1 == l.failAt {
+		return 0, 0, errors.New("insert failed")
+	}
+	l.batches = append(l.batches, len(us))
+	return len(us), 0, nil
+}
+
+// events yields n envelopes, then err (io.EOF when nil); onNext runs before each one
+type events struct {
+	n, i   int
+	err    error
+	onNext func(i int)
+}
+
+func (r *events) Next() (domain.EventEnvelope, error) {
+	if r.onNext != nil {
+		r.onNext(r.i)
+	}
+	if r.i >= r.n {
+		if r.err != nil {
+			return domain.EventEnvelope{}, r.err
+		}
+		return domain.EventEnvelope{}, io.EOF
+	}
+	r.i++
+	return domain.EventEnvelope{Type: fmt.Sprint(r.i)}, nil
+}
+
+func (r *events) Close() error                                 { return nil }
+func (r *events) Stats() (int, int64)                          { return r.i, 0 }
+func (r *events) New(io.ReadCloser) (domain.ReaderPort, error) { return r, nil }
+
+// oneUtterance extracts one distinct utterance per event
+type oneUtterance struct{}
+
+func (oneUtterance) FromEvent(env domain.EventEnvelope, _ domain.Normalizer) []domain.Utterance {
+	return []domain.Utterance{{UtteranceID: env.Type, Source: "comment", TextNormalized: "text " + env.Type}}
+}
+
+func newPipelineService(repo *insertLog, depth int) *Service {
+	return New(fakeTx{}, repokit.BindFunc[domain.StorageRepo](func(repokit.Queryer) domain.StorageRepo { return repo }),
+		nil, nil, oneUtterance{}, nil, Config{PipelineDepth: depth}, nil, nil)
+}
+
+func TestIngestPipelined(t *testing.T) {
+	repo := &insertLog{}
+	s := newPipelineService(repo, 1)
+
+	got, err := s.ingestPipelined(context.Background(), guardrails.Timeouts{}, &events{n: 5},
+		time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), 2, false)
+	if err != nil {
+		t.Fatalf("ingestPipelined: %v", err)
+	}
+	if got.events != 5 || got.utts != 5 || got.inserted != 5 {
+		t.Fatalf("tally %+v, want 5 events, utterances and inserts", got)
+	}
+	if fmt.Sprint(repo.batches) != "[2 2 1]" {
+		t.Fatalf("batches %v, want [2 2 1]", repo.batches)
+	}
+}
+
+func TestIngestPipelinedReaderError(t *testing.T) {
+	repo := &insertLog{}
+	s := newPipelineService(repo, 1)
+	boom := errors.New("corrupt gzip")
+
+	got, err := s.ingestPipelined(context.Background(), guardrails.Timeouts{}, &events{n: 3, err: boom},
+		time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), 2, false)
+	if !errors.Is(err, boom) {
+		t.Fatalf("err = %v, want the reader's", err)
+	}
+	// The full chunk landed; the trailing partial one is dropped with the failed hour
+	if got.events != 3 || got.inserted != 2 {
+		t.Fatalf("tally %+v, want 3 events and 2 inserted", got)
+	}
+}
+
+func TestIngestPipelinedInsertError(t *testing.T) {
+	repo := &insertLog{failAt: 2}
+	s := newPipelineService(repo, 1)
+
+	// Far more events than the channel holds: the reader must notice the failure and stop
+	got, err := s.ingestPipelined(context.Background(), guardrails.Timeouts{}, &events{n: 1000},
+		time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), 1, false)
+	if err == nil || err.Error() != "insert failed" {
+		t.Fatalf("err = %v, want the insert failure rather than the cancellation it caused", err)
+	}
+	if got.inserted != 1 || got.events >= 1000 {
+		t.Fatalf("tally %+v, want 1 inserted and reading cut short", got)
+	}
+}
+
+func TestIngestPipelinedCanceled(t *testing.T) {
+	repo := &insertLog{}
+	s := newPipelineService(repo, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	rd := &events{n: 1000, onNext: func(i int) {
+		if i == 4 {
+			cancel()
+		}
+	}}
+	got, err := s.ingestPipelined(ctx, guardrails.Timeouts{}, rd, time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), 2, false)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if got.events > 5 {
+		t.Fatalf("read %d events after cancel", got.events)
+	}
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"encoding/binary"
+	"math"
+	"time"
+
+	"swearjar/internal/services/backfill/domain"
+)
+
+// rawSampler decides which events get their original line persisted to raw_events
+// Selection is a pure function of the event's deterministic id, so replays of the
+// same hour sample the same events; maxPerHour/maxBytes bound storage growth
+type rawSampler struct {
+	threshold  uint64 // sample when the id's leading 8 bytes fall below this
+	maxPerHour int
+	maxBytes   int
+}
+
+func newRawSampler(fraction float64, maxPerHour, maxBytes int) rawSampler {
+	if fraction <= 0 || maxPerHour <= 0 {
+		return rawSampler{}
+	}
+	fraction = min(fraction, 1)
+	th := uint64(math.MaxUint64)
+	if fraction < 1 {
+		th = uint64(fraction * float64(math.MaxUint64))
+	}
+	return rawSampler{threshold: th, maxPerHour: maxPerHour, maxBytes: maxBytes}
+}
+
+// enabled reports whether sampling is configured at all
+func (r rawSampler) enabled() bool { return r.threshold > 0 }
+
+// take returns the RawEvent for env when it is selected; taken is the count so far this hour
+func (r rawSampler) take(env domain.EventEnvelope, hour time.Time, taken int) (domain.RawEvent, bool) {
+	if !r.enabled() || taken >= r.maxPerHour || len(env.RawPayload) == 0 {
+		return domain.RawEvent{}, false
+	}
+	if r.maxBytes > 0 && len(env.RawPayload) > r.maxBytes {
+		return domain.RawEvent{}, false
+	}
+	id := env.DeterministicUUID("event", 0)
+	if binary.BigEndian.Uint64(id[:8]) >= r.threshold {
+		return domain.RawEvent{}, false
+	}
+	return domain.RawEvent{
+		EventID:   id.String(),
+		HourUTC:   hour,
+		EventType: env.Type,
+		CreatedAt: env.CreatedAt,
+		RepoID:    env.Repo.ID,
+		ActorID:   env.Actor.ID,
+		Payload:   env.RawPayload,
+	}, true
+}
@@ -4,7 +4,6 @@ package service
 import (
 	"context"
 	"errors"
-	"io"
 	"math/rand"
 	"strings"
 	"sync"
@@ -42,6 +41,10 @@ type Config struct {
 	// Range guard
 	MaxRangeHours int // 0 = unlimited
 
+	// MaxEventsPerHour stops reading an hour after K events (smoke tests); 0 = unlimited.
+	// Capped hours are recorded in ingest_hours.event_cap so stats aren't read as a full hour
+	MaxEventsPerHour int
+
 	// Distributed lease for an hour (optional)
 	EnableLeases bool
 
@@ -50,9 +53,31 @@ type Config struct {
 
 	// Detection toggle: if true, run detector writer after inserts
 	DetectEnabled bool
+	// DetectVersion is the detver hits are stamped with; hours already detected at this version
+	// (ingest_hours.detected_ver) skip detection unless ForceDetect is set
+	DetectVersion int
+	ForceDetect   bool
 
 	// PrincipalsConcurrency limits concurrent EnsurePrincipalsAndMaps calls; <=0 -> 2
 	PrincipalsConcurrency int
+
+	// Raw event sampling (debugging/replay); fraction 0 disables
+	RawSampleFraction   float64 // e.g. 0.001 for 0.1% of events
+	RawSampleMaxPerHour int     // hard cap on sampled lines per hour
+	RawSampleMaxBytes   int     // skip lines larger than this (0 = no limit)
+
+	// CollapseDupes folds identical normalized texts within an hour before insert:
+	// CollapseOff | CollapseRepo | CollapseGlobal (see collapse.go)
+	CollapseDupes string
+
+	// PipelineDepth > 0 overlaps read and insert: up to this many InsertChunk-sized batches queue
+	// between them, capping memory per hour (see pipeline.go). 0 reads the whole hour, then inserts
+	PipelineDepth int
+
+	// MinTextRunes > 0 skips inserting (and so detecting) utterances whose trimmed normalized
+	// text is shorter than this many runes, empty ones included. Skips are recorded in
+	// ingest_hours.skipped_short. 0 inserts every extracted utterance
+	MinTextRunes int
 }
 
 // Service implements the backfill service
@@ -68,10 +93,12 @@ type Service struct {
 	// Optional detect writer; used when Cfg.DetectEnabled == true
 	Detect detectdom.WriterPort
 
-	// Lease(ctx, hourUTC, do) should take an hour-scoped advisory lock and run do()
+	// Lease(ctx, hourUTC, do) should take an hour-scoped advisory lock and run do(), keeping the
+	// lock alive while do runs and cancelling do's context if it is lost
 	Lease func(ctx context.Context, hour time.Time, do func(context.Context) error) error
 
 	principalsSem chan struct{}
+	raw           rawSampler
 
 	identPort identdom.Ports
 
@@ -110,6 +137,7 @@ func New(
 		Detect:        detectWriter,
 		Lease:         lease,
 		principalsSem: make(chan struct{}, ps),
+		raw:           newRawSampler(cfg.RawSampleFraction, cfg.RawSampleMaxPerHour, cfg.RawSampleMaxBytes),
 	}
 }
 
@@ -139,6 +167,22 @@ func (s *Service) PlanRange(ctx context.Context, start, end time.Time) error {
 	})
 }
 
+// Progress reports how far a backfill range has progressed (read-only)
+func (s *Service) Progress(ctx context.Context, start, end time.Time) (domain.RangeProgress, error) {
+	start = start.Truncate(time.Hour).UTC()
+	end = end.Truncate(time.Hour).UTC()
+	if end.Before(start) {
+		return domain.RangeProgress{}, errors.New("end before start")
+	}
+	var out domain.RangeProgress
+	err := s.DB.Tx(ctx, func(q repokit.Queryer) error {
+		var e error
+		out, e = s.Binder.Bind(q).Progress(ctx, start, end)
+		return e
+	})
+	return out, err
+}
+
 // RunResume drains any pending/error hours globally, ignoring bounds
 func (s *Service) RunResume(ctx context.Context) error {
 	w := max(s.Cfg.Workers, 1)
@@ -165,6 +209,9 @@ func (s *Service) RunResume(ctx context.Context) error {
 				Day:   hr.Day(),
 				Hour:  hr.Hour(),
 			}); err != nil {
+				if notYetAvailable(ctx, hr, err) {
+					return // later hours are newer still; leave them for the next run
+				}
 				logger.C(ctx).Error().Time("hour", hr).Err(err).Msg("backfill: runHour failed")
 				atomic.AddInt64(&fails, 1)
 			}
@@ -259,6 +306,9 @@ func (s *Service) RunRange(ctx context.Context, start, end time.Time) error {
 				Day:   hr.Day(),
 				Hour:  hr.Hour(),
 			}); err != nil {
+				if notYetAvailable(ctx, hr, err) {
+					return // later hours are newer still; leave them for the next run
+				}
 				logger.C(ctx).Error().Time("hour", hr).Err(err).Msg("backfill: runHour failed")
 				atomic.AddInt64(&fails, 1)
 			}
@@ -322,6 +372,11 @@ func (s *Service) runHourWithRetry(ctx context.Context, hr domain.HourRef) error
 		}
 		last = err
 
+		// Unpublished hours won't appear within a backoff window; hand back for a later run
+		if errors.Is(err, domain.ErrHourNotYetAvailable) {
+			return last
+		}
+
 		// Stop early on non-retryable errors
 		if !perr.Retryable(err) && perr.CodeOf(err) != perr.ErrorCodeUnavailable {
 			return last
@@ -374,7 +429,7 @@ func (s *Service) runHourUnlocked(ctx context.Context, hr domain.HourRef) (retEr
 	startWall := time.Now()
 	var fetchMS, readMS, dbMS, elapsedMS int
 	var cacheHit bool
-	var events, utts, inserted, deduped int
+	var events, utts, inserted, deduped, eventCap, collapsed, skipped int
 	var bytesUncompressed int64
 	var errText string
 
@@ -398,7 +453,7 @@ func (s *Service) runHourUnlocked(ctx context.Context, hr domain.HourRef) (retEr
 		_ = s.DB.Tx(dbCtx, func(q repokit.Queryer) error {
 			applyTxTuning(ctx, q)
 			return s.Binder.Bind(q).FinishHour(dbCtx, hourUTC, domain.HourFinish{
-				Status:            map[bool]string{true: "error", false: "ok"}[retErr != nil],
+				Status:            hourStatus(retErr),
 				CacheHit:          cacheHit,
 				BytesUncompressed: bytesUncompressed,
 				Events:            events,
@@ -410,6 +465,9 @@ func (s *Service) runHourUnlocked(ctx context.Context, hr domain.HourRef) (retEr
 				DBMS:              dbMS,
 				ElapsedMS:         elapsedMS,
 				ErrText:           errText,
+				EventCap:          eventCap,
+				Collapsed:         collapsed,
+				SkippedShort:      skipped,
 			})
 		})
 		dbCancel()
@@ -443,55 +501,61 @@ func (s *Service) runHourUnlocked(ctx context.Context, hr domain.HourRef) (retEr
 		}
 	}()
 
+	chunk := s.Cfg.InsertChunk
+	if chunk <= 0 {
+		chunk = 1000 // production default
+	}
+
+	// Pipelined mode: read and insert concurrently with bounded buffering
+	if s.Cfg.PipelineDepth > 0 {
+		detect := s.Cfg.DetectEnabled && s.Detect != nil && s.shouldDetect(hrCtx, hourUTC)
+		pt, err := s.ingestPipelined(hrCtx, tos, rd, hourUTC, chunk, detect)
+		events, utts, eventCap, skipped = pt.events, pt.utts, pt.eventCap, pt.skipped
+		inserted, deduped, collapsed = pt.inserted, pt.deduped, pt.collapsed
+		readMS, dbMS = pt.readMS, pt.dbMS
+		if eventCap > 0 {
+			logger.C(hrCtx).Warn().Time("hour", hourUTC).Int("event_cap", eventCap).
+				Msg("backfill: hour capped by MaxEventsPerHour; stats cover a partial hour")
+		}
+		_, bytesUncompressed = rd.Stats()
+		if err != nil {
+			retErr = err
+			return
+		}
+		if detect {
+			s.markDetected(hrCtx, hourUTC)
+		}
+		s.insertRawSamples(hrCtx, hourUTC, pt.raws)
+		s.runNightshift(hrCtx, hourUTC)
+		return nil
+	}
+
 	// Read + extract (timeoutable)
 	t1 := time.Now()
 	var all []domain.Utterance
+	var rt readTally
 	readCtx, readCancel := guardrails.ForRead(hrCtx, tos)
-	rerr := func() error {
-		for {
-			if err := readCtx.Err(); err != nil {
-				return err
-			}
-			env, e := rd.Next()
-			if e == io.EOF {
-				break
-			}
-			if e != nil {
-				return e
-			}
-			events++
-			uttsSlice := s.Extract.FromEvent(env, s.Norm)
-			if len(uttsSlice) == 0 {
-				continue
-			}
-			for i := range uttsSlice {
-				if uttsSlice[i].SourceDetail == "" {
-					uttsSlice[i].SourceDetail = uttsSlice[i].Source
-				}
-				uttsSlice[i].Source = coarse(uttsSlice[i].Source)
-			}
-			all = append(all, uttsSlice...)
-		}
+	rerr := s.readHour(readCtx, rd, hourUTC, &rt, func(us []domain.Utterance) error {
+		all = append(all, us...)
 		return nil
-	}()
+	})
 	readCancel()
 	readMS = int(time.Since(t1).Milliseconds())
+	events, utts, eventCap, skipped = rt.events, rt.utts, rt.eventCap, rt.skipped
 	if rerr != nil {
 		retErr = rerr
 		return
 	}
-	utts = len(all)
-
-	if statser, ok := any(rd).(interface{ Stats() (int, int64) }); ok {
-		_, bytesUncompressed = statser.Stats()
+	all, collapsed = collapseDupes(all, s.Cfg.CollapseDupes)
+	if eventCap > 0 {
+		logger.C(hrCtx).Warn().Time("hour", hourUTC).Int("event_cap", eventCap).
+			Msg("backfill: hour capped by MaxEventsPerHour; stats cover a partial hour")
 	}
 
+	_, bytesUncompressed = rd.Stats()
+
 	// Batched insert with robust fallback
 	t2 := time.Now()
-	chunk := s.Cfg.InsertChunk
-	if chunk <= 0 {
-		chunk = 1000 // production default
-	}
 	for i := 0; i < len(all); i += chunk {
 		end := min(i+chunk, len(all))
 		ins, dd, err := s.insertBatchRobust(hrCtx, all[i:end])
@@ -505,56 +569,84 @@ func (s *Service) runHourUnlocked(ctx context.Context, hr domain.HourRef) (retEr
 	}
 	dbMS += int(time.Since(t2).Milliseconds())
 
-	// Detection (optional) - uses utterance IDs directly; no CH lookups
-	if s.Cfg.DetectEnabled && s.Detect != nil && len(all) > 0 {
-		wbatch := make([]detectdom.WriteInput, 0, len(all))
-		for _, u := range all {
-			if u.UtteranceID == "" || u.TextNormalized == "" {
-				continue
-			}
-			var lang *string
-			if u.LangCode != nil {
-				if v := strings.TrimSpace(*u.LangCode); v != "" {
-					lang = &v
-				}
-			}
+	s.insertRawSamples(hrCtx, hourUTC, rt.raws)
 
-			wbatch = append(wbatch, detectdom.WriteInput{
-				UtteranceID: u.UtteranceID,
-				TextNorm:    u.TextNormalized,
-				CreatedAt:   u.CreatedAt,
-				Source:      u.Source,
-				RepoHID:     identdom.RepoHID32(u.RepoID).Bytes(),
-				ActorHID:    identdom.ActorHID32(u.ActorID).Bytes(),
-				LangCode:    lang, // if nil, detect pipeline can infer or CH defaults will handle
-			})
+	// Detection (optional) - uses utterance IDs directly; no CH lookups
+	if s.Cfg.DetectEnabled && s.Detect != nil && len(all) > 0 && s.shouldDetect(hrCtx, hourUTC) {
+		if err := s.detectUtterances(hrCtx, all, chunk); err != nil {
+			retErr = err
+			return
 		}
+		s.markDetected(hrCtx, hourUTC)
+	}
 
-		if len(wbatch) > 0 {
-			wchunk := chunk
-			if wchunk <= 0 {
-				wchunk = 1000
-			}
-			for i := 0; i < len(wbatch); i += wchunk {
-				end := min(i+wchunk, len(wbatch))
-				if _, err := s.Detect.Write(hrCtx, wbatch[i:end]); err != nil {
-					retErr = err
-					return
-				}
+	s.runNightshift(hrCtx, hourUTC)
+	return nil
+}
+
+// insertRawSamples writes sampled raw events (optional, best-effort; never fails the hour)
+func (s *Service) insertRawSamples(ctx context.Context, hourUTC time.Time, raws []domain.RawEvent) {
+	if len(raws) == 0 {
+		return
+	}
+	var n int
+	err := s.DB.Tx(ctx, func(q repokit.Queryer) error {
+		var e error
+		n, e = s.Binder.Bind(q).InsertRawEvents(ctx, raws)
+		return e
+	})
+	if err != nil {
+		logger.C(ctx).Warn().Time("hour", hourUTC).Err(err).Msg("backfill: raw event sample insert failed")
+	} else {
+		logger.C(ctx).Debug().Time("hour", hourUTC).Int("raw_events", n).Msg("backfill: sampled raw events")
+	}
+}
+
+// detectUtterances runs the detect writer over us in chunks of at most chunk inputs
+func (s *Service) detectUtterances(ctx context.Context, us []domain.Utterance, chunk int) error {
+	wbatch := make([]detectdom.WriteInput, 0, len(us))
+	for _, u := range us {
+		if u.UtteranceID == "" || u.TextNormalized == "" {
+			continue
+		}
+		var lang *string
+		if u.LangCode != nil {
+			if v := strings.TrimSpace(*u.LangCode); v != "" {
+				lang = &v
 			}
 		}
+
+		wbatch = append(wbatch, detectdom.WriteInput{
+			UtteranceID: u.UtteranceID,
+			TextNorm:    u.TextNormalized,
+			TextRaw:     u.TextRaw,
+			CreatedAt:   u.CreatedAt,
+			Source:      u.Source,
+			RepoHID:     identdom.RepoHID32(u.RepoID).Bytes(),
+			ActorHID:    identdom.ActorHID32(u.ActorID).Bytes(),
+			LangCode:    lang, // if nil, detect pipeline can infer or CH defaults will handle
+		})
+	}
+
+	for i := 0; i < len(wbatch); i += chunk {
+		end := min(i+chunk, len(wbatch))
+		if _, err := s.Detect.Write(ctx, wbatch[i:end]); err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
+// runNightshift calls the Nightshift hook for a finished hour; failures are logged, not returned
+func (s *Service) runNightshift(ctx context.Context, hourUTC time.Time) {
 	if s.Nightshift != nil {
-		logger.C(hrCtx).Debug().Time("hour", hourUTC).Msg("backfill: running nightshift")
-		if err := s.Nightshift(hrCtx, hourUTC); err != nil {
-			logger.C(hrCtx).Warn().Time("hour", hourUTC).Err(err).Msg("backfill: nightshift apply failed")
+		logger.C(ctx).Debug().Time("hour", hourUTC).Msg("backfill: running nightshift")
+		if err := s.Nightshift(ctx, hourUTC); err != nil {
+			logger.C(ctx).Warn().Time("hour", hourUTC).Err(err).Msg("backfill: nightshift apply failed")
 		}
 	} else {
-		logger.C(hrCtx).Debug().Time("hour", hourUTC).Msg("backfill: no nightshift configured")
+		logger.C(ctx).Debug().Time("hour", hourUTC).Msg("backfill: no nightshift configured")
 	}
-
-	return nil
 }
 
 // insertBatchRobust writes a slice with retries; if it still fails with a
@@ -652,22 +744,6 @@ func (s *Service) insertBatchRobust(ctx context.Context, batch []domain.Utteranc
 	return totIns + lIns + rIns, totDd + lDd + rDd, rErr
 }
 
-func coarse(s string) string {
-	l := strings.ToLower(s)
-	switch {
-	case strings.HasPrefix(l, "push:"):
-		return "commit"
-	case strings.HasPrefix(l, "issues:"):
-		return "issue"
-	case strings.HasPrefix(l, "pr:"):
-		return "pr"
-	}
-	if strings.Contains(l, "comment:") {
-		return "comment"
-	}
-	return l
-}
-
 func sleepCtx(ctx context.Context, d time.Duration) error {
 	if d <= 0 {
 		return nil
@@ -690,6 +766,63 @@ func applyTxTuning(ctx context.Context, q repokit.Queryer) {
 	//_, _ = q.Exec(ctx, "SET LOCAL idle_in_transaction_session_timeout = 0")
 }
 
+// shouldDetect reports whether detection should run for the hour; hours a previous run finished
+// detecting at the configured detver (ingest_hours.detected_ver) are skipped unless ForceDetect.
+// A failed probe falls back to detecting, since hit writes are idempotent
+func (s *Service) shouldDetect(ctx context.Context, hourUTC time.Time) bool {
+	if s.Cfg.ForceDetect {
+		return true
+	}
+	var done bool
+	err := s.DB.Tx(ctx, func(q repokit.Queryer) error {
+		var e error
+		done, e = s.Binder.Bind(q).DetectDone(ctx, hourUTC, s.Cfg.DetectVersion)
+		return e
+	})
+	if err != nil {
+		logger.C(ctx).Warn().Time("hour", hourUTC).Err(err).Msg("backfill: detect state probe failed, detecting anyway")
+		return true
+	}
+	if done {
+		logger.C(ctx).Info().Time("hour", hourUTC).Int("detver", s.Cfg.DetectVersion).
+			Msg("backfill: hour already detected at detver, skipping detection")
+	}
+	return !done
+}
+
+// markDetected records that the hour's detection finished; a failed write only costs a re-detect
+// on the next run, so it's logged rather than failing the hour
+func (s *Service) markDetected(ctx context.Context, hourUTC time.Time) {
+	err := s.DB.Tx(ctx, func(q repokit.Queryer) error {
+		return s.Binder.Bind(q).MarkDetected(ctx, hourUTC, s.Cfg.DetectVersion)
+	})
+	if err != nil {
+		logger.C(ctx).Warn().Time("hour", hourUTC).Err(err).Msg("backfill: recording detect state failed")
+	}
+}
+
+// hourStatus maps a runHour result to bf_status; unpublished hours go back to pending, not error
+func hourStatus(err error) string {
+	switch {
+	case err == nil:
+		return "ok"
+	case errors.Is(err, domain.ErrHourNotYetAvailable):
+		return "pending"
+	default:
+		return "error"
+	}
+}
+
+// notYetAvailable logs and reports a skip for hours GH Archive has not posted yet
+// These don't count toward fails; the claim order means every later hour is unpublished too
+func notYetAvailable(ctx context.Context, hr time.Time, err error) bool {
+	if !errors.Is(err, domain.ErrHourNotYetAvailable) {
+		return false
+	}
+	logger.C(ctx).Warn().Time("hour", hr).Err(err).Msg("backfill: hour not yet published, stopping worker")
+	return true
+}
+
 // treat "hour lease already held" as a contention signal
 func isLeaseHeld(err error) bool {
 	return errors.Is(err, guardrails.ErrLeaseHeld)
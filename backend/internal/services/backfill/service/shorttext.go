@@ -0,0 +1,30 @@
+package service
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"swearjar/internal/services/backfill/domain"
+)
+
+// DefaultMinTextRunes is the threshold used when skipping short texts is enabled without one:
+// the shortest lemmas ("wtf", "fml") still get through, while leftovers like "." or "ok" don't
+const DefaultMinTextRunes = 3
+
+// skipShortText drops utterances whose normalized text, trimmed, is shorter than minRunes
+// (empty included) and returns the survivors and how many were dropped. Extraction already
+// skips blank raw text; this catches what normalization empties or shrinks (DropQuotes,
+// DropDiffs, bare "-" commit messages). minRunes <= 0 keeps everything
+func skipShortText(us []domain.Utterance, minRunes int) ([]domain.Utterance, int) {
+	if minRunes <= 0 || len(us) == 0 {
+		return us, 0
+	}
+	kept := us[:0]
+	for _, u := range us {
+		if utf8.RuneCountInString(strings.TrimSpace(u.TextNormalized)) < minRunes {
+			continue
+		}
+		kept = append(kept, u)
+	}
+	return kept, len(us) - len(kept)
+}
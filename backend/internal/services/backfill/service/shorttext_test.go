@@ -0,0 +1,37 @@
+package service
+
+import (
+	"slices"
+	"testing"
+
+	"swearjar/internal/services/backfill/domain"
+)
+
+func TestSkipShortText(t *testing.T) {
+	texts := []string{"", "   \n\t", "ok", " ok ", "wtf", "  wtf  ", "日本", "日本語", "ёлки"}
+	cases := []struct {
+		minRunes int
+		want     []string
+	}{
+		{0, texts},
+		{-1, texts},
+		{1, []string{"ok", " ok ", "wtf", "  wtf  ", "日本", "日本語", "ёлки"}},
+		{3, []string{"wtf", "  wtf  ", "日本語", "ёлки"}},
+		{4, []string{"ёлки"}},
+		{5, nil},
+	}
+	for _, tc := range cases {
+		us := make([]domain.Utterance, len(texts))
+		for i, s := range texts {
+			us[i] = domain.Utterance{TextNormalized: s}
+		}
+		kept, dropped := skipShortText(us, tc.minRunes)
+		var got []string
+		for _, u := range kept {
+			got = append(got, u.TextNormalized)
+		}
+		if !slices.Equal(got, tc.want) || dropped != len(texts)-len(tc.want) {
+			t.Errorf("minRunes %d: kept %q (dropped %d), want %q", tc.minRunes, got, dropped, tc.want)
+		}
+	}
+}
@@ -18,7 +18,24 @@ type EnqueuePort interface {
 	EnqueueVerification(ctx context.Context, args EnqueueArgs) error
 }
 
+// QueueDepth is a snapshot of the verification queue
+type QueueDepth struct {
+	Ready   int // unleased and due; the next leases take these
+	Waiting int // unleased but backing off or rate limited
+	Leased  int // held by a worker whose lease hasn't expired; expired leases count as Ready or Waiting
+}
+
+// DrainResult tallies one DrainOnce pass
+type DrainResult struct {
+	Succeeded int // verified (receipt recorded or revocation marked) or superseded, and removed
+	Failed    int // could not be completed or requeued; stays leased until the lease expires
+	Requeued  int // rescheduled with backoff (GitHub error, rate limit, store error)
+}
+
 // WorkerPort (run loop) is separate
+// DrainOnce is the one-shot alternative to Run: it processes up to limit due jobs and returns
 type WorkerPort interface {
 	Run(ctx context.Context) error
+	DrainOnce(ctx context.Context, limit int) (DrainResult, error)
+	QueueDepth(ctx context.Context) (QueueDepth, error)
 }
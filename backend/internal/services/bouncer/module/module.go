@@ -30,6 +30,9 @@ func New(deps modkit.Deps, overrides Options) *Module {
 	if overrides.TokensCSV != "" {
 		opts.TokensCSV = overrides.TokensCSV
 	}
+	if overrides.GHBaseURL != "" {
+		opts.GHBaseURL = overrides.GHBaseURL
+	}
 	if overrides.QueueTakeBatch != 0 {
 		opts.QueueTakeBatch = overrides.QueueTakeBatch
 	}
@@ -45,9 +48,13 @@ func New(deps modkit.Deps, overrides Options) *Module {
 		RatePerSec:     opts.RatePerSec,
 		Burst:          opts.Burst,
 		TokensCSV:      opts.TokensCSV,
+		GHBaseURL:      opts.GHBaseURL,
 		QueueTakeBatch: opts.QueueTakeBatch,
 		RetryBaseMs:    opts.RetryBaseMs,
 		MaxAttempts:    opts.MaxAttempts,
+
+		GHMaxIdlePerHost:  opts.GHMaxIdlePerHost,
+		GHIdleConnTimeout: opts.GHIdleConnTimeout,
 	})
 
 	m := &Module{deps: deps}
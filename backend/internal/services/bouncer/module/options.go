@@ -12,9 +12,14 @@ type Options struct {
 	RatePerSec     float64
 	Burst          int
 	TokensCSV      string
+	GHBaseURL      string // GitHub API root (GHES: https://host/api/v3); empty = api.github.com
 	QueueTakeBatch int
 	RetryBaseMs    int
 	MaxAttempts    int
+
+	// GitHub connection pool; zero keeps the client defaults
+	GHMaxIdlePerHost  int
+	GHIdleConnTimeout time.Duration
 }
 
 // FromConfig reads with BOUNCER_ prefix (parity with HM)
@@ -25,8 +30,12 @@ func FromConfig(cfg config.Conf) Options {
 		RatePerSec:     c.MayFloat64("GH_RPS", 2.0),
 		Burst:          c.MayInt("GH_BURST", 4),
 		TokensCSV:      c.MayString("GH_TOKENS", ""),
+		GHBaseURL:      c.MayString("GH_BASE_URL", ""),
 		QueueTakeBatch: c.MayInt("QUEUE_TAKE_BATCH", 64),
 		RetryBaseMs:    int(c.MayDuration("RETRY_BASE", 500*time.Millisecond).Milliseconds()),
 		MaxAttempts:    c.MayInt("MAX_ATTEMPTS", 10),
+
+		GHMaxIdlePerHost:  c.MayInt("GH_MAX_IDLE_PER_HOST", 0),
+		GHIdleConnTimeout: c.MayDuration("GH_IDLE_CONN_TIMEOUT", 0),
 	}
 }
@@ -16,7 +16,8 @@ type Repo interface {
 	InsertChallengeArgs(ctx context.Context,
 		principal string, resource string, action string,
 		hash string, evidenceKind string, artifactHint string,
-	) error
+		day time.Time,
+	) (domain.LatestChallenge, error)
 
 	UpsertReceipt(ctx context.Context,
 		principal string, principalHID []byte, action string,
@@ -58,6 +59,7 @@ type Repo interface {
 		rateResetAt *time.Time,
 		etagBranch, etagFile, etagGists *string,
 	) error
+	CountVerifications(ctx context.Context) (ready, waiting, leased int, err error)
 }
 
 type (
@@ -72,14 +74,17 @@ func NewPG() repokit.Binder[Repo] { return PG{} }
 // Bind attaches a Queryer to the Postgres implementation
 func (PG) Bind(q repokit.Queryer) Repo { return &queries{q: q} }
 
-// InsertChallengeArgs records a freshly issued challenge using explicit args
+// InsertChallengeArgs records a challenge for (principal, resource, day), idempotently
+// A repeat call on the same UTC day keeps the existing hash/artifact (only the requested
+// action is refreshed) and returns the stored row, so double submits converge on one challenge
 func (r *queries) InsertChallengeArgs(
 	ctx context.Context,
 	principal, resource, action, hash, evidenceKind, artifactHint string,
-) error {
+	day time.Time,
+) (domain.LatestChallenge, error) {
 	const sql = `
 		INSERT INTO consent_challenges (
-			challenge_hash, principal,  resource, action,  scope, evidence_kind,  artifact_hint, issued_at,  state
+			challenge_hash, principal,  resource, action,  scope, evidence_kind,  artifact_hint, issued_at, issued_day, state
 		) VALUES (
 			$1, $2::principal_enum, $3, $4::consent_action_enum,
 			CASE
@@ -87,40 +92,70 @@ func (r *queries) InsertChallengeArgs(
 				WHEN $2::principal_enum = 'actor'::principal_enum THEN ARRAY['demask_self'::consent_scope_enum]
 				ELSE NULL
 			END,
-			$5::evidence_kind_enum, $6, NOW(), 'pending'::consent_state_enum
+			$5::evidence_kind_enum, $6, NOW(), $7::date, 'pending'::consent_state_enum
 		)
-		ON CONFLICT (challenge_hash) DO UPDATE
-		SET principal     = EXCLUDED.principal,
-		    resource      = EXCLUDED.resource,
-		    action        = EXCLUDED.action,
-		    scope         = EXCLUDED.scope,
-		    evidence_kind = EXCLUDED.evidence_kind,
-		    artifact_hint = EXCLUDED.artifact_hint,
-		    issued_at     = EXCLUDED.issued_at,
-		    state         = EXCLUDED.state
+		ON CONFLICT (principal, resource, issued_day) DO UPDATE
+		SET action = EXCLUDED.action,
+		    scope  = EXCLUDED.scope
+		RETURNING action::text, evidence_kind::text, artifact_hint, challenge_hash,
+		          EXTRACT(EPOCH FROM issued_at)::bigint
 	`
-	_, err := r.q.Exec(ctx, sql, hash, principal, resource, action, evidenceKind, artifactHint)
-	return err
+	var lc domain.LatestChallenge
+	row := r.q.QueryRow(ctx, sql,
+		hash, principal, resource, action, evidenceKind, artifactHint, day.UTC().Format("2006-01-02"),
+	)
+	if err := row.Scan(&lc.Action, &lc.EvidenceKind, &lc.ArtifactHint, &lc.Hash, &lc.IssuedAtUnix); err != nil {
+		return domain.LatestChallenge{}, err
+	}
+	return lc, nil
 }
 
-// UpsertReceipt activates or refreshes a receipt for opt in or opt out
+// UpsertReceipt activates or refreshes a receipt for opt in or opt out, recording the
+// transition in consent_audit (verified_by 'worker') when the state or evidence changed; a
+// re-verification with the same evidence only bumps last_verified_at
 // After a successful upsert, the related challenge row is deleted by challenge_hash,
 // which also removes any queued consent_verifications via ON DELETE CASCADE
 func (r *queries) UpsertReceipt(ctx context.Context,
 	principal string, principalHID []byte, action string,
 	evidenceKind string, evidenceURL string, hash string,
 ) error {
+	// The prev CTE reads the pre-upsert snapshot, so the audit row can name the transition
 	const upsert = `
-		INSERT INTO consent_receipts (
-			principal, principal_hid, action, scope, evidence_kind, evidence_url, evidence_fingerprint,
-			created_at, last_verified_at, revoked_at, terms_version, state
-		) VALUES ($1, $2, $3, NULL, $4, $5, $6, NOW(), NOW(), NULL, NULL, 'active')
-		ON CONFLICT (principal, principal_hid, action) DO UPDATE
-		SET evidence_url         = EXCLUDED.evidence_url,
-		    evidence_fingerprint = EXCLUDED.evidence_fingerprint,
-		    last_verified_at     = EXCLUDED.last_verified_at,
-		    revoked_at           = NULL,
-		    state                = 'active'
+		WITH prev AS (
+			SELECT state, revoked_at, evidence_url, evidence_fingerprint
+			FROM consent_receipts
+			WHERE principal = $1 AND principal_hid = $2 AND action = $3
+		), up AS (
+			INSERT INTO consent_receipts (
+				principal, principal_hid, action, scope, evidence_kind, evidence_url, evidence_fingerprint,
+				created_at, last_verified_at, revoked_at, terms_version, state
+			) VALUES ($1, $2, $3, NULL, $4, $5, $6, NOW(), NOW(), NULL, NULL, 'active')
+			ON CONFLICT (principal, principal_hid, action) DO UPDATE
+			SET evidence_url         = EXCLUDED.evidence_url,
+			    evidence_fingerprint = EXCLUDED.evidence_fingerprint,
+			    last_verified_at     = EXCLUDED.last_verified_at,
+			    revoked_at           = NULL,
+			    state                = 'active'
+			RETURNING consent_id, principal, principal_hid, action, state, evidence_kind, evidence_url, evidence_fingerprint
+		)
+		INSERT INTO consent_audit (
+			consent_id, principal, principal_hid, transition, action, state,
+			evidence_kind, evidence_url, evidence_fingerprint, verified_by
+		)
+		SELECT up.consent_id, up.principal, up.principal_hid,
+			CASE
+				WHEN NOT EXISTS (SELECT 1 FROM prev) THEN 'granted'
+				WHEN EXISTS (SELECT 1 FROM prev WHERE state <> 'active' OR revoked_at IS NOT NULL) THEN 'reinstated'
+				ELSE 'reverified'
+			END,
+			up.action, up.state, up.evidence_kind, up.evidence_url, up.evidence_fingerprint, 'worker'
+		FROM up
+		WHERE NOT EXISTS (
+			SELECT 1 FROM prev
+			WHERE state = 'active' AND revoked_at IS NULL
+			  AND evidence_url IS NOT DISTINCT FROM $5
+			  AND evidence_fingerprint IS NOT DISTINCT FROM $6
+		)
 	`
 	if _, err := r.q.Exec(ctx, upsert, principal, principalHID, action, evidenceKind, evidenceURL, hash); err != nil {
 		return err
@@ -131,12 +166,30 @@ func (r *queries) UpsertReceipt(ctx context.Context,
 }
 
 // MarkRevocationPending sets a soft revoke marker without changing state logic elsewhere
+// and audits the first marking (verified_by 'worker')
 func (r *queries) MarkRevocationPending(ctx context.Context, principal string, principalHID []byte) error {
+	// Only receipts that were not already marked get an audit row, so repeat misses don't spam it
 	const sql = `
-		UPDATE consent_receipts
-		SET last_verified_at = COALESCE(last_verified_at, NOW()) - INTERVAL '1 second',
-		    revoked_at       = COALESCE(revoked_at, NOW())
-		WHERE principal = $1 AND principal_hid = $2
+		WITH prev AS (
+			SELECT consent_id, revoked_at
+			FROM consent_receipts
+			WHERE principal = $1 AND principal_hid = $2
+		), up AS (
+			UPDATE consent_receipts
+			SET last_verified_at = COALESCE(last_verified_at, NOW()) - INTERVAL '1 second',
+			    revoked_at       = COALESCE(revoked_at, NOW())
+			WHERE principal = $1 AND principal_hid = $2
+			RETURNING consent_id, principal, principal_hid, action, state, evidence_kind, evidence_url, evidence_fingerprint
+		)
+		INSERT INTO consent_audit (
+			consent_id, principal, principal_hid, transition, action, state,
+			evidence_kind, evidence_url, evidence_fingerprint, verified_by
+		)
+		SELECT up.consent_id, up.principal, up.principal_hid, 'revocation_pending',
+			up.action, up.state, up.evidence_kind, up.evidence_url, up.evidence_fingerprint, 'worker'
+		FROM up
+		JOIN prev ON prev.consent_id = up.consent_id
+		WHERE prev.revoked_at IS NULL
 	`
 	_, err := r.q.Exec(ctx, sql, principal, principalHID)
 	return err
@@ -38,7 +38,8 @@ func (r *queries) EnqueueVerification(
 	return id, nil
 }
 
-// LeaseVerifications leases up to limit ready jobs; leaseFor defines the TTL
+// LeaseVerifications leases up to limit ready jobs; leaseFor defines the TTL. A job whose
+// lease expired (its worker died mid-job) counts as ready again
 func (r *queries) LeaseVerifications(
 	ctx context.Context,
 	workerID string,
@@ -52,7 +53,7 @@ func (r *queries) LeaseVerifications(
         WITH ready AS (
             SELECT job_id
               FROM consent_verifications
-             WHERE leased_by IS NULL
+             WHERE (leased_by IS NULL OR lease_expires_at <= now())
                AND next_attempt_at <= now()
                AND (rate_reset_at IS NULL OR rate_reset_at <= now())
              ORDER BY next_attempt_at ASC
@@ -152,3 +153,21 @@ func (r *queries) RequeueVerification(
 	_, err := r.q.Exec(ctx, sqlq, jobID, lastStatus, lastErr, nextAttemptAt, rateResetAt, etagBranch, etagFile, etagGists)
 	return err
 }
+
+// CountVerifications reports queue depth: jobs LeaseVerifications would take now, unleased
+// jobs scheduled for later (backoff or rate limit), and jobs currently leased. Jobs with an
+// expired lease count as unleased, matching LeaseVerifications
+func (r *queries) CountVerifications(ctx context.Context) (ready, waiting, leased int, err error) {
+	const sqlq = `
+        SELECT count(*) FILTER (WHERE NOT leased AND due),
+               count(*) FILTER (WHERE NOT leased AND NOT due),
+               count(*) FILTER (WHERE leased)
+          FROM (
+            SELECT leased_by IS NOT NULL AND COALESCE(lease_expires_at > now(), true) AS leased,
+                   next_attempt_at <= now() AND (rate_reset_at IS NULL OR rate_reset_at <= now()) AS due
+              FROM consent_verifications
+          ) q
+    `
+	err = r.q.QueryRow(ctx, sqlq).Scan(&ready, &waiting, &leased)
+	return ready, waiting, leased, err
+}
@@ -11,21 +11,33 @@ import (
 	domain "swearjar/internal/services/api/bouncer/domain"
 )
 
+// jobOutcome is how handleJob left a job in the queue
+type jobOutcome int
+
+const (
+	jobCompleted jobOutcome = iota // verified (receipt or revocation marker) or superseded; deleted
+	jobRequeued                    // rescheduled with backoff after a GitHub or store error
+)
+
+func completed(err error) (jobOutcome, error) { return jobCompleted, err }
+func requeued(err error) (jobOutcome, error)  { return jobRequeued, err }
+
 // handleJob processes a single verification job
 // It re-reads the latest challenge to ensure we're verifying current intent,
 // then checks GitHub for the artifact, and records a receipt if found.
 // If not found, it marks the principal as pending revocation.
-// Any errors result in a requeue with backoff
-func (s *Svc) handleJob(ctx context.Context, j domain.VerificationJob) error {
+// Any errors result in a requeue with backoff. A non-nil error means the job could not even
+// be completed or requeued; it stays leased until the lease expires
+func (s *Svc) handleJob(ctx context.Context, j domain.VerificationJob) (jobOutcome, error) {
 	// Re-read the latest challenge to ensure we're verifying current intent
 	lc, err := s.repo.LatestChallenge(ctx, j.Principal, j.Resource)
 	if err != nil {
-		return s.repo.RequeueVerification(ctx, j.JobID, nil, fmt.Sprintf("latest_challenge: %v", err),
-			nextAfter(j.Attempts, s.cfg.RetryBaseMs), nil, nil, nil, nil)
+		return requeued(s.repo.RequeueVerification(ctx, j.JobID, nil, fmt.Sprintf("latest_challenge: %v", err),
+			nextAfter(j.Attempts, s.cfg.RetryBaseMs), nil, nil, nil, nil))
 	}
 	if lc.Hash == "" || lc.Hash != j.ChallengeHash {
 		// Challenge changed or missing; finish this job
-		return s.repo.CompleteVerification(ctx, j.JobID, nil, "", nil, nil, nil)
+		return completed(s.repo.CompleteVerification(ctx, j.JobID, nil, "", nil, nil, nil))
 	}
 
 	var exists bool
@@ -41,7 +53,7 @@ func (s *Svc) handleJob(ctx context.Context, j domain.VerificationJob) error {
 		repoDoc, eb, _, err := s.gh.RepoByFullName(ctx, owner, repo, valOr(j.ETagBranch))
 		if ghErr, ok := err.(*gh.GHStatusError); ok && (ghErr.Status == 429 || ghErr.Status == 403) {
 			rateReset = ghRateReset(ghErr)
-			return s.repo.RequeueVerification(
+			return requeued(s.repo.RequeueVerification(
 				ctx,
 				j.JobID,
 				&ghErr.Status,
@@ -51,11 +63,11 @@ func (s *Svc) handleJob(ctx context.Context, j domain.VerificationJob) error {
 				nil,
 				nil,
 				nil,
-			)
+			))
 		}
 		if err != nil {
-			return s.repo.RequeueVerification(ctx, j.JobID, lastStatusOf(err), fmt.Sprintf("repo: %v", err),
-				nextAfter(j.Attempts, s.cfg.RetryBaseMs), nil, nil, nil, nil)
+			return requeued(s.repo.RequeueVerification(ctx, j.JobID, lastStatusOf(err), fmt.Sprintf("repo: %v", err),
+				nextAfter(j.Attempts, s.cfg.RetryBaseMs), nil, nil, nil, nil))
 		}
 		if eb != "" {
 			etagBranch = &eb
@@ -66,7 +78,7 @@ func (s *Svc) handleJob(ctx context.Context, j domain.VerificationJob) error {
 		html, ef, _, err := s.gh.RepoContent(ctx, owner, repo, lc.ArtifactHint, branch, valOr(j.ETagFile))
 		if ghErr, ok := err.(*gh.GHStatusError); ok && (ghErr.Status == 429 || ghErr.Status == 403) {
 			rateReset = ghRateReset(ghErr)
-			return s.repo.RequeueVerification(
+			return requeued(s.repo.RequeueVerification(
 				ctx,
 				j.JobID,
 				&ghErr.Status,
@@ -76,11 +88,11 @@ func (s *Svc) handleJob(ctx context.Context, j domain.VerificationJob) error {
 				etagBranch,
 				nil,
 				nil,
-			)
+			))
 		}
 		if err != nil {
-			return s.repo.RequeueVerification(ctx, j.JobID, lastStatusOf(err), fmt.Sprintf("contents: %v", err),
-				nextAfter(j.Attempts, s.cfg.RetryBaseMs), nil, etagBranch, nil, nil)
+			return requeued(s.repo.RequeueVerification(ctx, j.JobID, lastStatusOf(err), fmt.Sprintf("contents: %v", err),
+				nextAfter(j.Attempts, s.cfg.RetryBaseMs), nil, etagBranch, nil, nil))
 		}
 		if ef != "" {
 			etagFile = &ef
@@ -97,7 +109,7 @@ func (s *Svc) handleJob(ctx context.Context, j domain.VerificationJob) error {
 			items, egp, _, err := s.gh.ListPublicGists(ctx, login, page, 100, valOr(j.ETagGists))
 			if ghErr, ok := err.(*gh.GHStatusError); ok && (ghErr.Status == 429 || ghErr.Status == 403) {
 				rateReset = ghRateReset(ghErr)
-				return s.repo.RequeueVerification(
+				return requeued(s.repo.RequeueVerification(
 					ctx,
 					j.JobID,
 					&ghErr.Status,
@@ -107,11 +119,11 @@ func (s *Svc) handleJob(ctx context.Context, j domain.VerificationJob) error {
 					nil,
 					nil,
 					nil,
-				)
+				))
 			}
 			if err != nil {
-				return s.repo.RequeueVerification(ctx, j.JobID, lastStatusOf(err), fmt.Sprintf("gists: %v", err),
-					nextAfter(j.Attempts, s.cfg.RetryBaseMs), nil, nil, nil, nil)
+				return requeued(s.repo.RequeueVerification(ctx, j.JobID, lastStatusOf(err), fmt.Sprintf("gists: %v", err),
+					nextAfter(j.Attempts, s.cfg.RetryBaseMs), nil, nil, nil, nil))
 			}
 			if egp != "" {
 				eg = egp
@@ -160,18 +172,18 @@ func (s *Svc) handleJob(ctx context.Context, j domain.VerificationJob) error {
 			url,
 			lc.Hash,
 		); err != nil {
-			return s.repo.RequeueVerification(ctx, j.JobID, nil, fmt.Sprintf("upsert_receipt: %v", err),
-				nextAfter(j.Attempts, s.cfg.RetryBaseMs), nil, etagBranch, etagFile, etagGists)
+			return requeued(s.repo.RequeueVerification(ctx, j.JobID, nil, fmt.Sprintf("upsert_receipt: %v", err),
+				nextAfter(j.Attempts, s.cfg.RetryBaseMs), nil, etagBranch, etagFile, etagGists))
 		}
-		return s.repo.CompleteVerification(ctx, j.JobID, lastStatus, url, etagBranch, etagFile, etagGists)
+		return completed(s.repo.CompleteVerification(ctx, j.JobID, lastStatus, url, etagBranch, etagFile, etagGists))
 	}
 
 	// Verified missing: soft revoke marker
 	if err := s.repo.MarkRevocationPending(ctx, j.Principal, j.PrincipalHID); err != nil {
-		return s.repo.RequeueVerification(ctx, j.JobID, nil, fmt.Sprintf("mark_revocation: %v", err),
-			nextAfter(j.Attempts, s.cfg.RetryBaseMs), nil, etagBranch, etagFile, etagGists)
+		return requeued(s.repo.RequeueVerification(ctx, j.JobID, nil, fmt.Sprintf("mark_revocation: %v", err),
+			nextAfter(j.Attempts, s.cfg.RetryBaseMs), nil, etagBranch, etagFile, etagGists))
 	}
-	return s.repo.CompleteVerification(ctx, j.JobID, lastStatus, "", etagBranch, etagFile, etagGists)
+	return completed(s.repo.CompleteVerification(ctx, j.JobID, lastStatus, "", etagBranch, etagFile, etagGists))
 }
 
 func valOr(p *string) string {
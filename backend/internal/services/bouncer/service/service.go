@@ -25,9 +25,13 @@ type Config struct {
 	RatePerSec     float64
 	Burst          int
 	TokensCSV      string
+	GHBaseURL      string
 	QueueTakeBatch int
 	RetryBaseMs    int
 	MaxAttempts    int
+
+	GHMaxIdlePerHost  int
+	GHIdleConnTimeout time.Duration
 }
 
 // Svc implements the bouncer worker and enqueue service
@@ -45,9 +49,13 @@ type Svc struct {
 func New(deps modkit.Deps, cfg Config) *Svc {
 	b := brepo.NewPG()
 	client := gh.NewClient(gh.Options{
+		BaseURL:    cfg.GHBaseURL,
 		TokensCSV:  cfg.TokensCSV,
 		MaxRetries: cfg.MaxAttempts,
 		RetryBase:  durationMs(cfg.RetryBaseMs),
+
+		MaxIdleConnsPerHost: cfg.GHMaxIdlePerHost,
+		IdleConnTimeout:     cfg.GHIdleConnTimeout,
 	})
 	return &Svc{
 		db:     deps.PG,
@@ -2,11 +2,18 @@ package service
 
 import (
 	"context"
+	"sync"
 	"time"
 
 	"swearjar/internal/platform/logger"
+
+	domain "swearjar/internal/services/api/bouncer/domain"
+	dom "swearjar/internal/services/bouncer/domain"
 )
 
+// leaseTTL is how long a leased job stays hidden from other workers
+const leaseTTL = 60 * time.Second
+
 // Run starts the worker loop to process verification jobs
 func (s *Svc) Run(ctx context.Context) error {
 	log := logger.Named("bouncer-worker")
@@ -20,7 +27,7 @@ func (s *Svc) Run(ctx context.Context) error {
 			return ctx.Err()
 		case <-ticker.C:
 			// lease a small batch; process concurrently with a simple semaphore
-			jobs, err := s.repo.LeaseVerifications(ctx, "bouncer", s.cfg.QueueTakeBatch, 60*time.Second)
+			jobs, err := s.repo.LeaseVerifications(ctx, "bouncer", s.cfg.QueueTakeBatch, leaseTTL)
 			if err != nil {
 				log.Error().Err(err).Msg("lease verifications failed")
 				continue
@@ -30,7 +37,7 @@ func (s *Svc) Run(ctx context.Context) error {
 				j := jobs[i]
 				go func() {
 					defer func() { <-sem }()
-					if err := s.handleJob(ctx, j); err != nil {
+					if _, err := s.handleJob(ctx, j); err != nil {
 						log.Warn().Err(err).Str("job_id", j.JobID).Msg("job failed")
 					}
 				}()
@@ -38,3 +45,66 @@ func (s *Svc) Run(ctx context.Context) error {
 		}
 	}
 }
+
+// DrainOnce leases and processes up to limit due jobs (<= 0 = one lease batch) with the
+// worker's concurrency, waits for them and returns the tally. A job requeued with a short
+// backoff may come due again within the pass; each attempt counts toward limit
+func (s *Svc) DrainOnce(ctx context.Context, limit int) (dom.DrainResult, error) {
+	log := logger.Named("bouncer-drain")
+	batch := max(1, s.cfg.QueueTakeBatch)
+	if limit <= 0 {
+		limit = batch
+	}
+	sem := make(chan struct{}, max(1, s.cfg.Concurrency))
+
+	var (
+		mu  sync.Mutex
+		res dom.DrainResult
+		wg  sync.WaitGroup
+	)
+
+	for taken := 0; taken < limit; {
+		if err := ctx.Err(); err != nil {
+			return res, err
+		}
+		jobs, err := s.repo.LeaseVerifications(ctx, "bouncer", min(batch, limit-taken), leaseTTL)
+		if err != nil {
+			return res, err
+		}
+		if len(jobs) == 0 {
+			break
+		}
+		taken += len(jobs)
+		for _, j := range jobs {
+			sem <- struct{}{}
+			wg.Add(1)
+			go func(j domain.VerificationJob) {
+				defer func() { <-sem; wg.Done() }()
+				out, err := s.handleJob(ctx, j)
+				mu.Lock()
+				defer mu.Unlock()
+				switch {
+				case err != nil:
+					res.Failed++
+					log.Warn().Err(err).Str("job_id", j.JobID).Msg("job failed")
+				case out == jobRequeued:
+					res.Requeued++
+				default:
+					res.Succeeded++
+				}
+			}(j)
+		}
+		// let this batch settle before leasing more, so requeued jobs see their backoff
+		wg.Wait()
+	}
+	return res, nil
+}
+
+// QueueDepth counts the verification queue by state
+func (s *Svc) QueueDepth(ctx context.Context) (dom.QueueDepth, error) {
+	ready, waiting, leased, err := s.repo.CountVerifications(ctx)
+	if err != nil {
+		return dom.QueueDepth{}, err
+	}
+	return dom.QueueDepth{Ready: ready, Waiting: waiting, Leased: leased}, nil
+}
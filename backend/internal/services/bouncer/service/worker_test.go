@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	domain "swearjar/internal/services/api/bouncer/domain"
+	dom "swearjar/internal/services/bouncer/domain"
+	brepo "swearjar/internal/services/bouncer/repo"
+)
+
+// memQueue is an in-memory verification queue. Jobs resolve without GitHub: a challenge
+// hash of "stale" is superseded (completed), "err" fails the challenge lookup (requeued),
+// and "stuck" also fails the requeue (failed)
+type memQueue struct {
+	brepo.Repo
+
+	mu      sync.Mutex
+	jobs    []domain.VerificationJob
+	leases  []int
+	counts  [3]int
+	countFn error
+}
+
+func (q *memQueue) LeaseVerifications(
+	_ context.Context, _ string, limit int, _ time.Duration,
+) ([]domain.VerificationJob, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.leases = append(q.leases, limit)
+	n := min(limit, len(q.jobs))
+	out := q.jobs[:n]
+	q.jobs = q.jobs[n:]
+	return out, nil
+}
+
+func (q *memQueue) LatestChallenge(_ context.Context, _, resource string) (domain.LatestChallenge, error) {
+	if resource == "err" || resource == "stuck" {
+		return domain.LatestChallenge{}, errors.New("boom")
+	}
+	return domain.LatestChallenge{Hash: "current"}, nil
+}
+
+func (q *memQueue) CompleteVerification(context.Context, string, *int, string, *string, *string, *string) error {
+	return nil
+}
+
+func (q *memQueue) RequeueVerification(
+	_ context.Context, jobID string, _ *int, _ string, _ time.Time, _ *time.Time, _, _, _ *string,
+) error {
+	if jobID == "stuck" {
+		return errors.New("store down")
+	}
+	return nil
+}
+
+func (q *memQueue) CountVerifications(context.Context) (ready, waiting, leased int, err error) {
+	return q.counts[0], q.counts[1], q.counts[2], q.countFn
+}
+
+func queued(resources ...string) []domain.VerificationJob {
+	out := make([]domain.VerificationJob, len(resources))
+	for i, r := range resources {
+		out[i] = domain.VerificationJob{JobID: r, Resource: r, ChallengeHash: "stale"}
+	}
+	return out
+}
+
+func TestDrainOnce(t *testing.T) {
+	cases := []struct {
+		name   string
+		jobs   []domain.VerificationJob
+		limit  int
+		want   dom.DrainResult
+		leases []int
+		left   int
+	}{
+		{"tallies each outcome", queued("a", "err", "stuck", "b"), 10,
+			dom.DrainResult{Succeeded: 2, Requeued: 1, Failed: 1}, []int{3, 3, 3}, 0},
+		{"stops at limit", queued("a", "b", "c", "d", "e"), 4,
+			dom.DrainResult{Succeeded: 4}, []int{3, 1}, 1},
+		{"no limit takes one batch", queued("a", "b", "c", "d"), 0,
+			dom.DrainResult{Succeeded: 3}, []int{3}, 1},
+		{"empty queue", nil, 10, dom.DrainResult{}, []int{3}, 0},
+	}
+	for _, tc := range cases {
+		q := &memQueue{jobs: tc.jobs}
+		s := &Svc{repo: q, cfg: Config{Concurrency: 2, QueueTakeBatch: 3}}
+		got, err := s.DrainOnce(context.Background(), tc.limit)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if got != tc.want {
+			t.Errorf("%s: result %+v, want %+v", tc.name, got, tc.want)
+		}
+		if len(q.leases) != len(tc.leases) {
+			t.Errorf("%s: leases %v, want %v", tc.name, q.leases, tc.leases)
+		} else {
+			for i := range q.leases {
+				if q.leases[i] != tc.leases[i] {
+					t.Errorf("%s: leases %v, want %v", tc.name, q.leases, tc.leases)
+					break
+				}
+			}
+		}
+		if len(q.jobs) != tc.left {
+			t.Errorf("%s: %d jobs left, want %d", tc.name, len(q.jobs), tc.left)
+		}
+	}
+}
+
+func TestDrainOnceCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	q := &memQueue{jobs: queued("a")}
+	s := &Svc{repo: q, cfg: Config{Concurrency: 1, QueueTakeBatch: 1}}
+	if _, err := s.DrainOnce(ctx, 5); !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if len(q.leases) != 0 {
+		t.Fatalf("leased %v after cancel", q.leases)
+	}
+}
+
+func TestQueueDepth(t *testing.T) {
+	s := &Svc{repo: &memQueue{counts: [3]int{4, 2, 1}}}
+	got, err := s.QueueDepth(context.Background())
+	if err != nil || got != (dom.QueueDepth{Ready: 4, Waiting: 2, Leased: 1}) {
+		t.Fatalf("QueueDepth = %+v, %v", got, err)
+	}
+	s = &Svc{repo: &memQueue{countFn: errors.New("down")}}
+	if _, err := s.QueueDepth(context.Background()); err == nil {
+		t.Fatal("count failure should surface")
+	}
+}
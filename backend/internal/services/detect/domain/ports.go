@@ -2,6 +2,7 @@ package domain
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	hitsdom "swearjar/internal/services/hits/domain"
@@ -11,14 +12,51 @@ import (
 // RunnerPort is the external port for the detect job
 type RunnerPort interface {
 	RunRange(ctx context.Context, start, end time.Time) error
+
+	// RunIncremental detects every hour ingested since the last incremental run for the
+	// configured detector version and advances the stored high-water mark
+	RunIncremental(ctx context.Context) error
 }
 
+// StateRepo persists the incremental high-water mark (PG: detect_state + ingest_hours)
+type StateRepo interface {
+	// HighWater returns the exclusive mark for a detector version: every hour before it is done.
+	// ok is false when no incremental run has completed an hour yet
+	HighWater(ctx context.Context, detver int) (mark time.Time, ok bool, err error)
+
+	// ReadyHours lists up to limit hours at or after from that backfill finished ('ok'), ascending
+	ReadyHours(ctx context.Context, from time.Time, limit int) ([]time.Time, error)
+
+	// HourStatus returns an hour's backfill status; "" when ingest_hours has no row for it
+	HourStatus(ctx context.Context, hour time.Time) (string, error)
+
+	// Advance moves the mark from prev (zero + !hadPrev = no mark yet) to next.
+	// It returns ErrMarkMoved when the stored mark is no longer prev
+	Advance(ctx context.Context, detver int, prev time.Time, hadPrev bool, next time.Time) error
+
+	// SaveAutoscale records the insert pool controller's latest snapshot for the metrics endpoint
+	SaveAutoscale(ctx context.Context, detver int, st AutoscaleStats) error
+}
+
+// ErrMarkMoved signals another incremental run advanced the mark concurrently
+var ErrMarkMoved = errors.New("detect: incremental mark moved by another run")
+
+// ErrIncrementalStalled signals the mark's hour isn't 'ok' while later hours are, so the
+// incremental run can't advance without skipping it
+var ErrIncrementalStalled = errors.New("detect: incremental run stalled on an unfinished hour")
+
 // Ports are dependencies injected into the detect module
 type Ports struct {
 	Utterances utdom.ReaderPort   // required
 	HitsWriter hitsdom.WriterPort // required
 }
 
+// LangDetector guesses the language of normalized text for utterances ingested without one.
+// reliable is false for short or ambiguous text; lang "" means no guess
+type LangDetector interface {
+	DetectLang(text string) (lang string, reliable bool)
+}
+
 // WriterPort accepts utterances and writes hits
 type WriterPort interface {
 	// Write processes a batch of normalized utterances and persists hits.
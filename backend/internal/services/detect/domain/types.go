@@ -22,4 +22,16 @@ type WriteInput struct {
 	RepoHID     []byte    // len=32, FixedString(32)
 	ActorHID    []byte    // len=32, FixedString(32)
 	LangCode    *string   // optional (nil => unknown/auto)
+	TextRaw     string    // optional original text; feeds the shouting signal when set
+}
+
+// AutoscaleStats is a snapshot of the hits insert pool controller (see CORE_DETECT_AUTOSCALE).
+// Counters cover the detect process so far
+type AutoscaleStats struct {
+	Inserters  int // current pool size
+	Min, Max   int
+	InsertEWMA time.Duration
+	Inserts    int64
+	Grows      int64
+	Shrinks    int64
 }
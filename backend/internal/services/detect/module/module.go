@@ -7,7 +7,9 @@ import (
 	"swearjar/internal/core/rulepack"
 	"swearjar/internal/modkit"
 	"swearjar/internal/modkit/httpkit"
+	"swearjar/internal/platform/logger"
 	"swearjar/internal/services/detect/domain"
+	"swearjar/internal/services/detect/repo"
 	"swearjar/internal/services/detect/service"
 )
 
@@ -54,6 +56,10 @@ func New(deps modkit.Deps, overrides Options, opts ...modkit.Option) *Module {
 	}
 	// bool override wins (defaults false if caller didn't set)
 	cfg.DryRun = overrides.DryRun
+	cfg.LangScoped = cfg.LangScoped || overrides.LangScoped
+
+	// Language fallback for utterances ingested without a lang_code
+	langDet := cfg.LangDetector()
 
 	// Shared rulepack for the range runner
 	rp, err := rulepack.Load()
@@ -61,24 +67,40 @@ func New(deps modkit.Deps, overrides Options, opts ...modkit.Option) *Module {
 		panic(err)
 	}
 
+	if rp.DetVer > 0 && rp.DetVer != cfg.Version {
+		// the API defaults queries to the pack's detver, so these hits won't show by default
+		logger.Named("detect").Warn().
+			Int("stamp_detver", cfg.Version).
+			Int("pack_detver", rp.DetVer).
+			Msg("detect: stamping hits with a detector version the rulepack doesn't declare (meta.detver)")
+	}
+
 	// Range runner (scan window over utterances and write hits)
-	runner := service.New(
-		ports.Utterances,
-		ports.HitsWriter,
-		rp,
-		service.Config{
-			Version:       cfg.Version,
-			Workers:       cfg.Workers,
-			PageSize:      cfg.PageSize,
-			MaxRangeHours: cfg.MaxRangeHours,
-			DryRun:        cfg.DryRun,
-		},
-	)
+	runner := service.New(ports.Utterances, ports.HitsWriter, rp, cfg.serviceConfig(langDet))
+
+	// Incremental runs keep their high-water mark in PG; without it RunIncremental errors out
+	if deps.PG != nil {
+		runner.DB = deps.PG
+		runner.State = repo.NewPG()
+	}
 
 	// Direct writer (per-utterance detection; used by backfill --detect and future live ingest)
 	writer := service.NewWriter(
 		ports.HitsWriter,
-		service.WriterConfig{Version: cfg.Version},
+		service.WriterConfig{
+			Version:       cfg.Version,
+			LangScoped:    cfg.LangScoped,
+			MaxSeverity:   cfg.MaxSeverity,
+			SelfDirected:  cfg.SelfDirected,
+			ContextWindow: cfg.ContextWindow,
+			SkipQuotes:    cfg.SkipQuotes,
+
+			QuotedReportDelta: cfg.QuotedReportDelta,
+			FoldHomoglyphs:    cfg.FoldHomoglyphs,
+			RequireContext:    cfg.RequireContext,
+			ShoutingDelta:     cfg.ShoutingDelta,
+			LangDetector:      langDet,
+		},
 	)
 
 	m := &Module{deps: deps}
@@ -1,24 +1,126 @@
 package module
 
-import "swearjar/internal/platform/config"
+import (
+	"fmt"
+	"time"
+
+	"swearjar/internal/core/detector"
+	"swearjar/internal/core/langhint"
+	"swearjar/internal/platform/config"
+	"swearjar/internal/services/detect/domain"
+	"swearjar/internal/services/detect/service"
+)
 
 // Options holds configuration settings for the detect module
 type Options struct {
-	Version       int
-	Workers       int
-	PageSize      int
-	MaxRangeHours int
-	DryRun        bool
+	Version       int  `env:"VERSION" default:"1"`
+	Workers       int  `env:"WORKERS" default:"2"`
+	PageSize      int  `env:"PAGE_SIZE" default:"5000"`
+	MaxRangeHours int  `env:"MAX_RANGE_HOURS" default:"0"`
+	DryRun        bool `env:"DRY_RUN" default:"false"`
+	// SkipFailedHours lets -incremental step over hours whose backfill ended in 'error' (logged)
+	// instead of stopping with a stall error; re-run them later with -start/-end
+	SkipFailedHours bool `env:"SKIP_FAILED_HOURS" default:"false"`
+	// LangScoped applies only rules for the utterance's lang_code plus language-neutral ones
+	LangScoped bool `env:"LANG_SCOPED" default:"false"`
+	// MaxSeverity caps emitted severities to [1, MaxSeverity] after dampening; 0 = no ceiling
+	MaxSeverity int `env:"MAX_SEVERITY" default:"0"`
+	// SelfDirected handles first-person targets ("my code is broken as fuck"): "" ignores them,
+	// "tag" | "downgrade" | "suppress" (see detector.SelfTag and friends)
+	SelfDirected string `env:"SELF_DIRECTED" default:""`
+	// ContextWindow is the byte radius captured into pre_context/post_context and searched
+	// for nearby targets; 0 disables both
+	ContextWindow int `env:"CONTEXT_WINDOW" default:"64"`
+	// SkipQuotes drops hits on '>' quoted reply lines so replies don't re-count quoted profanity
+	SkipQuotes bool `env:"SKIP_QUOTES" default:"false"`
+	// QuotedReportDelta is added to slur_masked hits on '>' quoted lines when the author's own
+	// text reads as a report ("this user called me ..."); negative downgrades, 0 = off
+	QuotedReportDelta int `env:"QUOTED_REPORT_DELTA" default:"0"`
+	// FoldHomoglyphs matches Cyrillic/Greek lookalikes inside Latin words ("fuсk") against Latin
+	// rules; whole Cyrillic/Greek words are left alone (see normalize.FoldHomoglyphs)
+	FoldHomoglyphs bool `env:"FOLD_HOMOGLYPHS" default:"false"`
+	// RequireContext drops template hits whose declared context signals (target, direct_address,
+	// frustration, outside_code) aren't found within CONTEXT_WINDOW of the match
+	RequireContext bool `env:"REQUIRE_CONTEXT" default:"false"`
+	// ShoutingDelta is added to hits whose span was all caps in utterances.text_raw ("SHIT");
+	// 0 = off, and range runs then skip reading text_raw
+	ShoutingDelta int `env:"SHOUTING_DELTA" default:"0"`
+	// InferLang guesses lang_code from the normalized text when an utterance has none
+	// (see langhint.Infer) and stamps hits.lang_reliable with the guess's confidence
+	InferLang bool `env:"INFER_LANG" default:"false"`
+	// Autoscale writes hits in the background while the next page is detected, keeping
+	// [MIN_INSERTERS, MAX_INSERTERS] inserts in flight sized from their latency against
+	// TARGET_INSERT_LATENCY; snapshots land in detect_autoscale for /metrics
+	Autoscale           bool          `env:"AUTOSCALE" default:"false"`
+	MinInserters        int           `env:"MIN_INSERTERS" default:"1"`
+	MaxInserters        int           `env:"MAX_INSERTERS" default:"8"`
+	TargetInsertLatency time.Duration `env:"TARGET_INSERT_LATENCY" default:"1s"`
 }
 
-// FromConfig extracts Options from the given config.Conf
+// maxContextWindow matches the ceiling accepted by the detect/try debug endpoint
+const maxContextWindow = 512
+
+// FromConfig extracts Options from the given config.Conf (CORE_DETECT_ prefix)
+// Invalid values panic so misconfiguration surfaces at boot
 func FromConfig(cfg config.Conf) Options {
-	df := cfg.Prefix("CORE_DETECT_")
-	return Options{
-		Version:       df.MayInt("VERSION", 1),
-		Workers:       df.MayInt("WORKERS", 2),
-		PageSize:      df.MayInt("PAGE_SIZE", 5000),
-		MaxRangeHours: df.MayInt("MAX_RANGE_HOURS", 0),
-		DryRun:        df.MayBool("DRY_RUN", false),
+	var o Options
+	cfg.Prefix("CORE_DETECT_").MustBind(&o)
+	switch o.SelfDirected {
+	case "", detector.SelfTag, detector.SelfDowngrade, detector.SelfSuppress:
+	default:
+		panic(fmt.Errorf("CORE_DETECT_SELF_DIRECTED: unknown mode %q", o.SelfDirected))
+	}
+	if o.ContextWindow < 0 || o.ContextWindow > maxContextWindow {
+		panic(fmt.Errorf("CORE_DETECT_CONTEXT_WINDOW: %d outside [0, %d]", o.ContextWindow, maxContextWindow))
+	}
+	if o.MaxInserters > 0 && o.MinInserters > o.MaxInserters {
+		panic(fmt.Errorf("CORE_DETECT_MIN_INSERTERS: %d above MAX_INSERTERS %d", o.MinInserters, o.MaxInserters))
+	}
+	return o
+}
+
+// serviceConfig maps the options onto the range runner's config
+func (o Options) serviceConfig(langDet domain.LangDetector) service.Config {
+	return service.Config{
+		Version:       o.Version,
+		Workers:       o.Workers,
+		PageSize:      o.PageSize,
+		MaxRangeHours: o.MaxRangeHours,
+		DryRun:        o.DryRun,
+		LangScoped:    o.LangScoped,
+
+		SkipFailedHours: o.SkipFailedHours,
+
+		MaxSeverity:   o.MaxSeverity,
+		SelfDirected:  o.SelfDirected,
+		ContextWindow: o.ContextWindow,
+		SkipQuotes:    o.SkipQuotes,
+
+		QuotedReportDelta: o.QuotedReportDelta,
+		ShoutingDelta:     o.ShoutingDelta,
+		FoldHomoglyphs:    o.FoldHomoglyphs,
+		RequireContext:    o.RequireContext,
+		LangDetector:      langDet,
+
+		Autoscale:           o.Autoscale,
+		MinInserters:        o.MinInserters,
+		MaxInserters:        o.MaxInserters,
+		TargetInsertLatency: o.TargetInsertLatency,
+	}
+}
+
+// LangDetector is the lang_code fallback the pipeline resolves utterances with: the langhint
+// guesser when InferLang is set, else nil
+func (o Options) LangDetector() domain.LangDetector {
+	if o.InferLang {
+		return langhint.Detector{}
 	}
+	return nil
+}
+
+// DetectorOptions are the detector settings the pipeline scans with under these options, for
+// callers outside the detect service (the API's re-detect, offline scans) whose hits must
+// match what the pipeline stores
+func (o Options) DetectorOptions() detector.Options {
+	return o.serviceConfig(nil).DetectorOptions()
 }
@@ -0,0 +1,122 @@
+// Package repo provides the detect state repository (Postgres)
+package repo
+
+import (
+	"context"
+	"time"
+
+	"swearjar/internal/modkit/repokit"
+	"swearjar/internal/platform/store"
+	"swearjar/internal/services/detect/domain"
+)
+
+type (
+	// PG is a Postgres implementation of the detect state repo
+	PG      struct{}
+	queries struct{ q repokit.Queryer }
+)
+
+// NewPG returns a binder for the Postgres implementation
+func NewPG() repokit.Binder[domain.StateRepo] { return PG{} }
+
+// Bind attaches a Queryer to the Postgres implementation
+func (PG) Bind(q repokit.Queryer) domain.StateRepo { return &queries{q: q} }
+
+// HighWater reads the incremental mark for a detector version
+func (r *queries) HighWater(ctx context.Context, detver int) (time.Time, bool, error) {
+	var mark time.Time
+	err := r.q.QueryRow(ctx,
+		`SELECT high_water_hour FROM detect_state WHERE detector_version = $1`, detver,
+	).Scan(&mark)
+	if store.IsNoRows(err) {
+		return time.Time{}, false, nil
+	}
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	return mark.UTC(), true, nil
+}
+
+// ReadyHours lists backfilled hours at or after from, oldest first
+func (r *queries) ReadyHours(ctx context.Context, from time.Time, limit int) ([]time.Time, error) {
+	rows, err := r.q.Query(ctx, `
+		SELECT hour_utc
+		FROM ingest_hours
+		WHERE bf_status = 'ok' AND hour_utc >= $1
+		ORDER BY hour_utc
+		LIMIT $2`,
+		from.UTC(), limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []time.Time
+	for rows.Next() {
+		var h time.Time
+		if err := rows.Scan(&h); err != nil {
+			return nil, err
+		}
+		out = append(out, h.UTC())
+	}
+	return out, rows.Err()
+}
+
+// HourStatus reads an hour's bf_status
+func (r *queries) HourStatus(ctx context.Context, hour time.Time) (string, error) {
+	var status string
+	err := r.q.QueryRow(ctx,
+		`SELECT bf_status::text FROM ingest_hours WHERE hour_utc = $1`, hour.UTC(),
+	).Scan(&status)
+	if store.IsNoRows(err) {
+		return "", nil
+	}
+	return status, err
+}
+
+// SaveAutoscale upserts the detector version's detect_autoscale row
+func (r *queries) SaveAutoscale(ctx context.Context, detver int, st domain.AutoscaleStats) error {
+	_, err := r.q.Exec(ctx, `
+		INSERT INTO detect_autoscale (
+			detector_version, inserters, min_inserters, max_inserters, insert_ewma_ms, inserts, grows, shrinks
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (detector_version) DO UPDATE SET
+			inserters      = EXCLUDED.inserters,
+			min_inserters  = EXCLUDED.min_inserters,
+			max_inserters  = EXCLUDED.max_inserters,
+			insert_ewma_ms = EXCLUDED.insert_ewma_ms,
+			inserts        = EXCLUDED.inserts,
+			grows          = EXCLUDED.grows,
+			shrinks        = EXCLUDED.shrinks,
+			updated_at     = now()`,
+		detver, st.Inserters, st.Min, st.Max, st.InsertEWMA.Milliseconds(), st.Inserts, st.Grows, st.Shrinks,
+	)
+	return err
+}
+
+// Advance compare-and-sets the mark; the first advance for a version inserts the row
+func (r *queries) Advance(ctx context.Context, detver int, prev time.Time, hadPrev bool, next time.Time) error {
+	var expect *time.Time
+	if hadPrev {
+		p := prev.UTC()
+		expect = &p
+	}
+	tag, err := r.q.Exec(ctx, `
+		INSERT INTO detect_state (detector_version, high_water_hour, updated_at)
+		VALUES ($1, $3, now())
+		ON CONFLICT (detector_version) DO UPDATE
+		SET high_water_hour = EXCLUDED.high_water_hour,
+		    updated_at      = now()
+		WHERE detect_state.high_water_hour IS NOT DISTINCT FROM $2::timestamptz`,
+		detver, expect, next.UTC(),
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrMarkMoved
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"swearjar/internal/platform/logger"
+	"swearjar/internal/services/detect/domain"
+	hitsdom "swearjar/internal/services/hits/domain"
+)
+
+// autoscaleAlpha weights the newest insert in the latency EWMA
+const autoscaleAlpha = 0.3
+
+// autoscaler sizes the hits insert pool from observed insert latency (AIMD): while the
+// smoothed latency stays under 80% of target it allows one more insert in flight per insert,
+// and once it exceeds target it halves the pool. Detection of the next page overlaps the
+// inserts in flight, so a full pool is what pushes back on the reader when ClickHouse slows
+type autoscaler struct {
+	mu       sync.Mutex
+	min      int
+	max      int
+	target   time.Duration
+	workers  int
+	inflight int
+	wake     chan struct{} // closed and replaced whenever a slot frees up
+	ewma     time.Duration
+	inserts  int64
+	grows    int64
+	shrinks  int64
+	log      *logger.Logger
+}
+
+func newAutoscaler(lo, hi int, target time.Duration) *autoscaler {
+	lo = max(lo, 1)
+	hi = max(hi, lo)
+	return &autoscaler{
+		min:     lo,
+		max:     hi,
+		target:  target,
+		workers: lo,
+		wake:    make(chan struct{}),
+		log:     logger.Named("detect"),
+	}
+}
+
+// acquire blocks until an insert slot is free under the current pool size
+func (a *autoscaler) acquire(ctx context.Context) error {
+	for {
+		a.mu.Lock()
+		if a.inflight < a.workers {
+			a.inflight++
+			a.mu.Unlock()
+			return nil
+		}
+		wake := a.wake
+		a.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-wake:
+		}
+	}
+}
+
+// release frees a slot taken by acquire and feeds the insert's latency into the controller
+func (a *autoscaler) release(d time.Duration) {
+	a.Observe(d)
+
+	a.mu.Lock()
+	a.inflight--
+	close(a.wake)
+	a.wake = make(chan struct{})
+	a.mu.Unlock()
+}
+
+// Observe feeds one insert's latency into the controller
+func (a *autoscaler) Observe(d time.Duration) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.inserts++
+	if a.inserts == 1 {
+		a.ewma = d
+	} else {
+		a.ewma += time.Duration(autoscaleAlpha * float64(d-a.ewma))
+	}
+
+	prev := a.workers
+	switch {
+	case a.ewma > a.target:
+		a.workers = max(a.min, a.workers/2)
+	case a.ewma*5 < a.target*4:
+		a.workers = min(a.max, a.workers+1)
+	}
+	if a.workers == prev {
+		return
+	}
+	if a.workers > prev {
+		a.grows++
+	} else {
+		a.shrinks++
+	}
+	a.log.Debug().
+		Int("inserters", a.workers).
+		Int("prev", prev).
+		Dur("insert_ewma", a.ewma).
+		Dur("target", a.target).
+		Msg("detect: insert pool resized")
+}
+
+// Stats snapshots the controller
+func (a *autoscaler) Stats() domain.AutoscaleStats {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return domain.AutoscaleStats{
+		Inserters:  a.workers,
+		Min:        a.min,
+		Max:        a.max,
+		InsertEWMA: a.ewma,
+		Inserts:    a.inserts,
+		Grows:      a.grows,
+		Shrinks:    a.shrinks,
+	}
+}
+
+// pageWriter writes each page's hits for one RunRange: inline without autoscale, otherwise in
+// the background with up to the autoscaler's pool size in flight
+type pageWriter struct {
+	s  *Service
+	wg sync.WaitGroup
+
+	mu  sync.Mutex
+	err error // first background insert failure
+}
+
+// write stores xs, or hands it to a background insert once a slot is free. A failure of an
+// earlier background insert is returned here so the run stops reading pages
+func (w *pageWriter) write(ctx context.Context, xs []hitsdom.HitWrite) error {
+	if w.s.scale == nil {
+		return w.s.Hits.WriteBatch(ctx, xs)
+	}
+	if err := w.failed(); err != nil {
+		return err
+	}
+	if err := w.s.scale.acquire(ctx); err != nil {
+		return err
+	}
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		t0 := time.Now()
+		err := w.s.Hits.WriteBatch(ctx, xs)
+		w.s.scale.release(time.Since(t0))
+		if err != nil {
+			w.mu.Lock()
+			if w.err == nil {
+				w.err = err
+			}
+			w.mu.Unlock()
+		}
+	}()
+	return nil
+}
+
+func (w *pageWriter) failed() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err
+}
+
+// wait blocks until every background insert finished and returns the first failure
+func (w *pageWriter) wait() error {
+	w.wg.Wait()
+	return w.failed()
+}
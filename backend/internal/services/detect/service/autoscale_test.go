@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"swearjar/internal/core/rulepack"
+	"swearjar/internal/services/detect/domain"
+	hitsdom "swearjar/internal/services/hits/domain"
+	utdom "swearjar/internal/services/utterances/domain"
+)
+
+func TestAutoscalerObserve(t *testing.T) {
+	target := time.Second
+	cases := []struct {
+		name      string
+		lo, hi    int
+		latencies []time.Duration
+		want      domain.AutoscaleStats
+	}{
+		{"fast inserts grow by one each", 1, 8, []time.Duration{100 * time.Millisecond, 100 * time.Millisecond},
+			domain.AutoscaleStats{Inserters: 3, Grows: 2}},
+		{"growth stops at max", 1, 2, []time.Duration{time.Millisecond, time.Millisecond, time.Millisecond},
+			domain.AutoscaleStats{Inserters: 2, Grows: 1}},
+		{"between 80% and target holds", 2, 8, []time.Duration{900 * time.Millisecond},
+			domain.AutoscaleStats{Inserters: 2}},
+		{"slow insert halves", 1, 8, []time.Duration{
+			100 * time.Millisecond, 100 * time.Millisecond, 100 * time.Millisecond, 5 * time.Second,
+		}, domain.AutoscaleStats{Inserters: 2, Grows: 3, Shrinks: 1}},
+		{"shrink stops at min", 3, 8, []time.Duration{3 * time.Second, 3 * time.Second},
+			domain.AutoscaleStats{Inserters: 3}},
+		// the EWMA smooths one spike: 100ms then 2s is 670ms, still under 80% of target
+		{"one spike is smoothed", 1, 8, []time.Duration{100 * time.Millisecond, 2 * time.Second},
+			domain.AutoscaleStats{Inserters: 3, Grows: 2}},
+	}
+	for _, tc := range cases {
+		a := newAutoscaler(tc.lo, tc.hi, target)
+		for _, d := range tc.latencies {
+			a.Observe(d)
+		}
+		got := a.Stats()
+		if got.Inserters != tc.want.Inserters || got.Grows != tc.want.Grows || got.Shrinks != tc.want.Shrinks {
+			t.Errorf("%s: got %+v, want %+v", tc.name, got, tc.want)
+		}
+		if got.Inserts != int64(len(tc.latencies)) || got.Min != tc.lo || got.Max != tc.hi {
+			t.Errorf("%s: stats %+v don't reflect the inputs", tc.name, got)
+		}
+	}
+}
+
+func TestAutoscalerAcquireWaitsForSlot(t *testing.T) {
+	a := newAutoscaler(1, 1, time.Second)
+	if err := a.acquire(context.Background()); err != nil {
+		t.Fatalf("first acquire: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := a.acquire(ctx); err == nil {
+		t.Fatal("second acquire got a slot past the pool size")
+	}
+
+	got := make(chan error, 1)
+	go func() { got <- a.acquire(context.Background()) }()
+	a.release(time.Millisecond)
+	select {
+	case err := <-got:
+		if err != nil {
+			t.Fatalf("acquire after release: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("release didn't wake the waiting insert")
+	}
+}
+
+// pages serves n single-utterance pages of the same text
+type pages struct{ n, served int }
+
+func (p *pages) List(_ context.Context, _ utdom.ListInput) ([]utdom.Row, utdom.AfterKey, error) {
+	if p.served == p.n {
+		return nil, utdom.AfterKey{}, nil
+	}
+	p.served++
+	return []utdom.Row{{ID: string(rune('a' + p.served)), TextNorm: "this build is shit"}}, utdom.AfterKey{}, nil
+}
+
+// slowHits records batches after a delay, tracking how many were in flight at once
+type slowHits struct {
+	mu            sync.Mutex
+	batches, peak int
+	inflight      int
+	delay         time.Duration
+}
+
+func (h *slowHits) WriteBatch(_ context.Context, _ []hitsdom.HitWrite) error {
+	h.mu.Lock()
+	h.inflight++
+	h.peak = max(h.peak, h.inflight)
+	h.mu.Unlock()
+	time.Sleep(h.delay)
+	h.mu.Lock()
+	h.inflight--
+	h.batches++
+	h.mu.Unlock()
+	return nil
+}
+
+func mustPack(t *testing.T) *rulepack.Pack {
+	t.Helper()
+	rp, err := rulepack.Load()
+	if err != nil {
+		t.Fatalf("rulepack.Load: %v", err)
+	}
+	return rp
+}
+
+func TestRunRangeAutoscaleInsertsInBackground(t *testing.T) {
+	hits := &slowHits{delay: 20 * time.Millisecond}
+	st := &fakeState{}
+	s := New(&pages{n: 6}, hits, mustPack(t), Config{
+		Version: 1, Workers: 1, Autoscale: true, MinInserters: 3, MaxInserters: 3, TargetInsertLatency: time.Second,
+	})
+	s.DB, s.State = fakeTx{}, st
+
+	if err := s.RunRange(context.Background(), time.Time{}, time.Time{}); err != nil {
+		t.Fatalf("RunRange: %v", err)
+	}
+	if hits.batches != 6 {
+		t.Fatalf("%d batches written by the time RunRange returned, want all 6", hits.batches)
+	}
+	if hits.peak < 2 || hits.peak > 3 {
+		t.Fatalf("peak inserts in flight = %d, want overlap capped at 3", hits.peak)
+	}
+	if len(st.scale) == 0 || st.scale[len(st.scale)-1].Inserts != 6 {
+		t.Fatalf("saved autoscale stats %+v, want a final snapshot covering 6 inserts", st.scale)
+	}
+}
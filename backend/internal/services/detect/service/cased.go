@@ -0,0 +1,27 @@
+package service
+
+import "swearjar/internal/core/normalize"
+
+// casedNormalizers covers every DropQuotes/DropDiffs combination ingest could have stored
+// text_normalized with; detect doesn't know which one a given row went through
+var casedNormalizers = []*normalize.Normalizer{
+	normalize.New(),
+	normalize.NewWithOptions(normalize.Options{DropQuotes: true}),
+	normalize.NewWithOptions(normalize.Options{DropDiffs: true}),
+	normalize.NewWithOptions(normalize.Options{DropQuotes: true, DropDiffs: true}),
+}
+
+// CasedText rebuilds the case-preserving projection of norm from the utterance's raw text.
+// It returns "" (shouting signal off) when raw is empty or no normalizer reproduces norm,
+// e.g. rows normalized by an older pipeline
+func CasedText(raw, norm string) string {
+	if raw == "" || norm == "" {
+		return ""
+	}
+	for _, n := range casedNormalizers {
+		if got, cased := n.NormalizeCased(raw); got == norm {
+			return cased
+		}
+	}
+	return ""
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"swearjar/internal/core/normalize"
+	dom "swearjar/internal/services/detect/domain"
+	hitsdom "swearjar/internal/services/hits/domain"
+)
+
+func TestCasedText(t *testing.T) {
+	quoted := "> old SHIT\nTHIS build is SHIT"
+	cases := []struct {
+		name, raw, norm, want string
+	}{
+		{"plain", "THIS build is SHIT", "this build is shit", "THIS build is SHIT"},
+		{"stored with DropQuotes", quoted, normalize.NewWithOptions(normalize.Options{DropQuotes: true}).Normalize(quoted),
+			"THIS build is SHIT"},
+		{"no raw text", "", "this build is shit", ""},
+		{"norm from another pipeline", "THIS build is SHIT", "something else", ""},
+	}
+	for _, tc := range cases {
+		if got := CasedText(tc.raw, tc.norm); got != tc.want {
+			t.Errorf("%s: got %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+type hitsLog struct{ xs []hitsdom.HitWrite }
+
+func (l *hitsLog) WriteBatch(_ context.Context, xs []hitsdom.HitWrite) error {
+	l.xs = append(l.xs, xs...)
+	return nil
+}
+
+func TestWriterShoutingDelta(t *testing.T) {
+	severity := func(cfg WriterConfig, raw string) string {
+		t.Helper()
+		hw := &hitsLog{}
+		_, err := NewWriter(hw, cfg).Write(context.Background(), []dom.WriteInput{{
+			UtteranceID: "u1",
+			TextNorm:    "this build is shit",
+			TextRaw:     raw,
+			CreatedAt:   time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+		}})
+		if err != nil || len(hw.xs) == 0 {
+			t.Fatalf("write: %d hits, %v", len(hw.xs), err)
+		}
+		return hw.xs[0].Severity
+	}
+
+	base := severity(WriterConfig{Version: 1}, "this build is SHIT")
+	if got := severity(WriterConfig{Version: 1, ShoutingDelta: 1}, "this build is SHIT"); got == base {
+		t.Fatalf("shouted span stayed %q with ShoutingDelta 1", got)
+	}
+	if got := severity(WriterConfig{Version: 1, ShoutingDelta: 1}, "this build is shit"); got != base {
+		t.Fatalf("lowercase span got %q, want %q", got, base)
+	}
+	if got := severity(WriterConfig{Version: 1, ShoutingDelta: 1}, ""); got != base {
+		t.Fatalf("no text_raw got %q, want %q", got, base)
+	}
+}
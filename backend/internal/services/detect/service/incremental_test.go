@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"swearjar/internal/modkit/repokit"
+	"swearjar/internal/services/detect/domain"
+	utdom "swearjar/internal/services/utterances/domain"
+)
+
+// fakeTx runs fn inline; the state fake ignores the Queryer
+type fakeTx struct{ repokit.Queryer }
+
+func (fakeTx) Tx(_ context.Context, fn func(q repokit.Queryer) error) error { return fn(nil) }
+
+// fakeState is an in-memory ingest_hours + detect_state + detect_autoscale
+type fakeState struct {
+	status map[time.Time]string
+	mark   time.Time
+	hasMrk bool
+	scale  []domain.AutoscaleStats
+}
+
+func (f *fakeState) Bind(repokit.Queryer) domain.StateRepo { return f }
+
+func (f *fakeState) HighWater(context.Context, int) (time.Time, bool, error) {
+	return f.mark, f.hasMrk, nil
+}
+
+func (f *fakeState) ReadyHours(_ context.Context, from time.Time, limit int) ([]time.Time, error) {
+	var out []time.Time
+	for h, st := range f.status {
+		if st == "ok" && !h.Before(from) {
+			out = append(out, h)
+		}
+	}
+	slices.SortFunc(out, func(a, b time.Time) int { return a.Compare(b) })
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+func (f *fakeState) HourStatus(_ context.Context, h time.Time) (string, error) {
+	return f.status[h], nil
+}
+
+func (f *fakeState) Advance(_ context.Context, _ int, prev time.Time, had bool, next time.Time) error {
+	if had != f.hasMrk || (had && !prev.Equal(f.mark)) {
+		return domain.ErrMarkMoved
+	}
+	f.mark, f.hasMrk = next, true
+	return nil
+}
+
+func (f *fakeState) SaveAutoscale(_ context.Context, _ int, st domain.AutoscaleStats) error {
+	f.scale = append(f.scale, st)
+	return nil
+}
+
+// hourLog records which hours RunRange listed; every hour is empty
+type hourLog struct{ hours []time.Time }
+
+func (l *hourLog) List(_ context.Context, in utdom.ListInput) ([]utdom.Row, utdom.AfterKey, error) {
+	l.hours = append(l.hours, in.Since)
+	return nil, utdom.AfterKey{}, nil
+}
+
+func TestRunIncrementalGaps(t *testing.T) {
+	t0 := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	hr := func(n int) time.Time { return t0.Add(time.Duration(n) * time.Hour) }
+
+	cases := []struct {
+		name     string
+		status   []string // hours 0..n-1; "" = no ingest_hours row
+		skip     bool
+		wantRun  []int
+		wantMark int
+		wantErr  bool
+	}{
+		{"frontier", []string{"ok", "ok", "running"}, false, []int{1}, 2, false},
+		{"all done", []string{"ok", "ok", "ok"}, false, []int{1, 2}, 3, false},
+		{"running gap", []string{"ok", "ok", "running", "ok"}, false, []int{1}, 2, true},
+		{"missing gap", []string{"ok", "ok", "", "ok"}, false, []int{1}, 2, true},
+		{"failed gap stalls", []string{"ok", "ok", "error", "ok"}, false, []int{1}, 2, true},
+		{"failed gap skipped", []string{"ok", "ok", "error", "error", "ok"}, true, []int{1, 4}, 5, false},
+		{"pending gap not skipped", []string{"ok", "ok", "error", "pending", "ok"}, true, []int{1}, 3, true},
+	}
+	for _, tc := range cases {
+		st := &fakeState{status: map[time.Time]string{}, mark: hr(1), hasMrk: true}
+		for i, s := range tc.status {
+			if s != "" {
+				st.status[hr(i)] = s
+			}
+		}
+		log := &hourLog{}
+		s := &Service{
+			Utters: log,
+			DB:     fakeTx{},
+			State:  st,
+			Cfg:    Config{Version: 1, Workers: 1, PageSize: 10, SkipFailedHours: tc.skip},
+		}
+
+		err := s.RunIncremental(context.Background())
+		if tc.wantErr != (err != nil) {
+			t.Fatalf("%s: err = %v, want error %v", tc.name, err, tc.wantErr)
+		}
+		if err != nil && !errors.Is(err, domain.ErrIncrementalStalled) {
+			t.Fatalf("%s: err = %v, want ErrIncrementalStalled", tc.name, err)
+		}
+		var want []time.Time
+		for _, n := range tc.wantRun {
+			want = append(want, hr(n))
+		}
+		if !slices.Equal(log.hours, want) {
+			t.Fatalf("%s: detected %v, want %v", tc.name, log.hours, want)
+		}
+		if !st.mark.Equal(hr(tc.wantMark)) {
+			t.Fatalf("%s: mark = %v, want %v", tc.name, st.mark, hr(tc.wantMark))
+		}
+	}
+}
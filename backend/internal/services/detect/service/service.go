@@ -4,13 +4,16 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
 	"strings"
 	"sync"
 	"time"
 
 	"swearjar/internal/core/detector"
 	"swearjar/internal/core/rulepack"
-	str "swearjar/internal/platform/strings"
+	"swearjar/internal/modkit/repokit"
+	"swearjar/internal/platform/logger"
+	"swearjar/internal/services/detect/domain"
 	hitsdom "swearjar/internal/services/hits/domain"
 	utdom "swearjar/internal/services/utterances/domain"
 )
@@ -22,6 +25,51 @@ type Config struct {
 	PageSize      int
 	MaxRangeHours int // 0 = unlimited
 	DryRun        bool
+	// SkipFailedHours lets RunIncremental step over hours whose backfill ended in 'error'
+	// (logged) instead of stalling on them; they can be re-run later with RunRange
+	SkipFailedHours bool
+	LangScoped      bool   // only run rules for the utterance's lang_code (+ language-neutral)
+	MaxSeverity     int    // cap on emitted severity (0 = no ceiling)
+	SelfDirected    string // first-person targeting mode ("" = off)
+	ContextWindow   int    // bytes of pre/post context per hit (0 = none)
+	SkipQuotes      bool   // drop hits inside '>' quoted reply lines
+
+	QuotedReportDelta int  // severity delta for quoted slurs in a reporting utterance (0 = off)
+	FoldHomoglyphs    bool // match Cyrillic/Greek lookalikes in mixed-script words as Latin
+	RequireContext    bool // enforce templates' declared context signals (requires/requires_any)
+	ShoutingDelta     int  // severity delta for all-caps spans; reads text_raw when set (0 = off)
+
+	// LangDetector fills lang_code for utterances without one (nil = leave it to ClickHouse)
+	LangDetector domain.LangDetector
+
+	// Autoscale writes each page's hits in the background, with up to [MinInserters, MaxInserters]
+	// inserts in flight sized from their latency (see autoscaler); off = one inline insert per page
+	Autoscale           bool
+	MinInserters        int           // default 1
+	MaxInserters        int           // default 8
+	TargetInsertLatency time.Duration // default 1s
+}
+
+// DetectorOptions are the detector settings the range runner scans with
+func (cfg Config) DetectorOptions() detector.Options {
+	return detector.Options{
+		MaxTotalHits:              8000,
+		AllowOverlapping:          false,
+		ContextWindow:             cfg.ContextWindow,
+		SeverityDeltaInCodeFence:  -1,
+		SeverityDeltaInCodeInline: -1,
+		SeverityDeltaInQuote:      -1,
+		SeverityDeltaInURL:        -1,
+		SeverityDeltaInDiff:       -1,
+		LangScoped:                cfg.LangScoped,
+		MaxSeverity:               cfg.MaxSeverity,
+		SelfDirected:              cfg.SelfDirected,
+		SkipQuoteZones:            cfg.SkipQuotes,
+		SeverityDeltaQuotedReport: cfg.QuotedReportDelta,
+		FoldHomoglyphs:            cfg.FoldHomoglyphs,
+		RequireContextSignals:     cfg.RequireContext,
+		SeverityDeltaShouting:     cfg.ShoutingDelta,
+	}
 }
 
 // Service implements domain.RunnerPort
@@ -29,9 +77,19 @@ type Service struct {
 	Utters utdom.ReaderPort
 	Hits   hitsdom.WriterPort
 	Det    *detector.Detector
+	Pack   *rulepack.Pack
 	Cfg    Config
+
+	// DB and State back RunIncremental; both nil when Postgres isn't wired
+	DB    repokit.TxRunner
+	State repokit.Binder[domain.StateRepo]
+
+	scale *autoscaler // nil unless Cfg.Autoscale
 }
 
+// incrementalBatch bounds how many ready hours RunIncremental lists per round trip
+const incrementalBatch = 168
+
 // New constructs a new detect service
 func New(utters utdom.ReaderPort, hits hitsdom.WriterPort, rp *rulepack.Pack, cfg Config) *Service {
 	w := cfg.Workers
@@ -43,31 +101,81 @@ func New(utters utdom.ReaderPort, hits hitsdom.WriterPort, rp *rulepack.Pack, cf
 		ps = 5000
 	}
 
-	det := detector.NewWithOptions(rp, cfg.Version, detector.Options{
-		MaxTotalHits:              8000,
-		AllowOverlapping:          false,
-		ContextWindow:             64,
-		SeverityDeltaInCodeFence:  -1,
-		SeverityDeltaInCodeInline: -1,
-		SeverityDeltaInQuote:      -1,
-	})
+	det := detector.NewWithOptions(rp, cfg.Version, cfg.DetectorOptions())
+
+	var scale *autoscaler
+	if cfg.Autoscale {
+		hi := cfg.MaxInserters
+		if hi <= 0 {
+			hi = 8
+		}
+		target := cfg.TargetInsertLatency
+		if target <= 0 {
+			target = time.Second
+		}
+		scale = newAutoscaler(cfg.MinInserters, hi, target)
+	}
 
 	return &Service{
 		Utters: utters,
 		Hits:   hits,
 		Det:    det,
+		Pack:   rp,
+		scale:  scale,
 		Cfg: Config{
 			Version:       cfg.Version,
 			Workers:       w,
 			PageSize:      ps,
 			MaxRangeHours: cfg.MaxRangeHours,
 			DryRun:        cfg.DryRun,
+			LangScoped:    cfg.LangScoped,
+
+			SkipFailedHours: cfg.SkipFailedHours,
+
+			MaxSeverity:   cfg.MaxSeverity,
+			SelfDirected:  cfg.SelfDirected,
+			ContextWindow: cfg.ContextWindow,
+			SkipQuotes:    cfg.SkipQuotes,
+
+			QuotedReportDelta: cfg.QuotedReportDelta,
+			FoldHomoglyphs:    cfg.FoldHomoglyphs,
+			RequireContext:    cfg.RequireContext,
+			LangDetector:      cfg.LangDetector,
+
+			Autoscale:           cfg.Autoscale,
+			MinInserters:        cfg.MinInserters,
+			MaxInserters:        cfg.MaxInserters,
+			TargetInsertLatency: cfg.TargetInsertLatency,
 		},
 	}
 }
 
-// RunRange processes utterances in the given time range, detecting hits and writing them to the hits service
-func (s *Service) RunRange(ctx context.Context, start, end time.Time) error {
+// AutoscaleStats reports the insert pool controller; ok is false when autoscaling is off
+func (s *Service) AutoscaleStats() (domain.AutoscaleStats, bool) {
+	if s.scale == nil {
+		return domain.AutoscaleStats{}, false
+	}
+	return s.scale.Stats(), true
+}
+
+// saveAutoscale records the controller snapshot for /metrics when Postgres is wired; a failed
+// write only leaves the gauges stale, so it's logged
+func (s *Service) saveAutoscale(ctx context.Context) {
+	st, ok := s.AutoscaleStats()
+	if !ok || s.DB == nil || s.State == nil {
+		return
+	}
+	err := s.DB.Tx(ctx, func(q repokit.Queryer) error {
+		return s.State.Bind(q).SaveAutoscale(ctx, s.Cfg.Version, st)
+	})
+	if err != nil {
+		logger.Named("detect").Warn().Err(err).Msg("detect: saving autoscale stats failed")
+	}
+}
+
+// RunRange processes utterances in the given time range, detecting hits and writing them to the hits service.
+// With Autoscale, inserts still in flight are waited for before it returns
+func (s *Service) RunRange(ctx context.Context, start, end time.Time) (retErr error) {
 	start = start.Truncate(time.Hour).UTC()
 	end = end.Truncate(time.Hour).UTC()
 	if end.Before(start) {
@@ -77,6 +185,25 @@ func (s *Service) RunRange(ctx context.Context, start, end time.Time) error {
 		return errors.New("range exceeds MaxRangeHours")
 	}
 
+	pw := &pageWriter{s: s}
+	defer func() {
+		if err := pw.wait(); retErr == nil {
+			retErr = err
+		}
+		if st, ok := s.AutoscaleStats(); ok {
+			s.saveAutoscale(ctx)
+			logger.Named("detect").Info().
+				Int("inserters", st.Inserters).
+				Int("min", st.Min).
+				Int("max", st.Max).
+				Dur("insert_ewma", st.InsertEWMA).
+				Int64("inserts", st.Inserts).
+				Int64("grows", st.Grows).
+				Int64("shrinks", st.Shrinks).
+				Msg("detect: autoscale summary")
+		}
+	}()
+
 	// ranking: template > lemma; bot_rage > tooling_rage > lang_rage > self_own > generic; then severity
 	srcPri := func(src detector.Source) int {
 		if src == detector.SourceTemplate {
@@ -110,6 +237,7 @@ func (s *Service) RunRange(ctx context.Context, start, end time.Time) error {
 		rows, next, err := s.Utters.List(ctx, utdom.ListInput{
 			Since: start, Until: end,
 			After: after, Limit: s.Cfg.PageSize,
+			TextRaw: s.Cfg.ShoutingDelta != 0,
 		})
 		if err != nil {
 			return err
@@ -135,7 +263,10 @@ func (s *Service) RunRange(ctx context.Context, start, end time.Time) error {
 					return
 				}
 
-				matches := s.Det.Scan(u.TextNorm)
+				// IMPORTANT: propagate utterance lang exactly; infer only when it has none
+				lang, reliable := ResolveLang(s.Cfg.LangDetector, u.LangCode, u.TextNorm)
+				cased := CasedText(u.TextRaw, u.TextNorm)
+				matches, _ := s.Det.ScanCased(u.TextNorm, cased, ScanLang(lang, reliable))
 
 				// best-per-(span,term)
 				type winner struct {
@@ -156,9 +287,6 @@ func (s *Service) RunRange(ctx context.Context, start, end time.Time) error {
 					}
 				}
 
-				// IMPORTANT: propagate utterance lang exactly
-				lang := str.Deref(u.LangCode)
-
 				buf := make([]hitsdom.HitWrite, 0, len(best))
 				for _, w := range best {
 					m, sp := w.hit, w.span
@@ -180,7 +308,7 @@ func (s *Service) RunRange(ctx context.Context, start, end time.Time) error {
 						CreatedAt:       u.CreatedAt,
 						Term:            m.Term,
 						Category:        mapCategory(m.Category),
-						Severity:        mapSeverity(m.Severity),
+						Severity:        s.Pack.SeverityLabel(m.Severity),
 						SpanStart:       sp[0],
 						SpanEnd:         sp[1],
 						DetectorVersion: s.Cfg.Version,
@@ -188,6 +316,7 @@ func (s *Service) RunRange(ctx context.Context, start, end time.Time) error {
 						RepoHID:         u.RepoHID,
 						ActorHID:        u.ActorHID,
 						LangCode:        lang,
+						LangReliable:    reliable,
 
 						DetectorSource: string(m.Source),
 						PreContext:     m.Pre,
@@ -222,12 +351,104 @@ func (s *Service) RunRange(ctx context.Context, start, end time.Time) error {
 				flat = append(flat, out[i].xs...)
 			}
 			if len(flat) > 0 {
-				if err := s.Hits.WriteBatch(ctx, flat); err != nil {
+				if err := pw.write(ctx, flat); err != nil {
 					return err
 				}
+				s.saveAutoscale(ctx)
 			}
 		}
 
 		after = next
 	}
 }
+
+// RunIncremental detects, hour by hour, everything backfill finished since the last incremental
+// run for Cfg.Version. It follows ingest_hours in order and returns nil once no later hour is
+// 'ok'; the next run resumes there. An hour that isn't 'ok' while later ones are is a gap: it
+// fails with domain.ErrIncrementalStalled, or, when the hour's backfill errored and
+// Cfg.SkipFailedHours is set, logs and steps over it. The mark advances in its own Tx after
+// each hour's hits are written, guarded on the previous mark: a crash repeats at most one hour
+// (hit ids are deterministic, so that's idempotent) and a concurrent run fails with
+// domain.ErrMarkMoved instead of double counting.
+// DryRun detects but never moves the mark
+func (s *Service) RunIncremental(ctx context.Context) error {
+	if s.DB == nil || s.State == nil {
+		return errors.New("incremental detect requires Postgres state")
+	}
+
+	var (
+		mark time.Time
+		ok   bool
+	)
+	if err := s.DB.Tx(ctx, func(q repokit.Queryer) error {
+		var err error
+		mark, ok, err = s.State.Bind(q).HighWater(ctx, s.Cfg.Version)
+		return err
+	}); err != nil {
+		return err
+	}
+
+	for {
+		var hours []time.Time
+		if err := s.DB.Tx(ctx, func(q repokit.Queryer) error {
+			var err error
+			hours, err = s.State.Bind(q).ReadyHours(ctx, mark, incrementalBatch)
+			return err
+		}); err != nil {
+			return err
+		}
+		if len(hours) == 0 {
+			return nil
+		}
+
+		for i := 0; i < len(hours); {
+			h := hours[i]
+			if ok && h.After(mark) {
+				// a later hour is ready, so mark's hour is a gap rather than the ingest frontier
+				if err := s.skipGap(ctx, mark); err != nil {
+					return err
+				}
+				h = mark
+			} else {
+				if err := s.RunRange(ctx, h, h.Add(time.Hour)); err != nil {
+					return err
+				}
+				i++
+			}
+
+			next := h.Add(time.Hour)
+			if !s.Cfg.DryRun {
+				if err := s.DB.Tx(ctx, func(q repokit.Queryer) error {
+					return s.State.Bind(q).Advance(ctx, s.Cfg.Version, mark, ok, next)
+				}); err != nil {
+					return err
+				}
+			}
+			mark, ok = next, true
+		}
+	}
+}
+
+// skipGap decides whether RunIncremental may step over hour, which isn't 'ok' while a later hour
+// is: only a failed backfill under Cfg.SkipFailedHours; anything else is a stall
+func (s *Service) skipGap(ctx context.Context, hour time.Time) error {
+	var status string
+	if err := s.DB.Tx(ctx, func(q repokit.Queryer) error {
+		var err error
+		status, err = s.State.Bind(q).HourStatus(ctx, hour)
+		return err
+	}); err != nil {
+		return err
+	}
+	if status == "" {
+		status = "missing"
+	}
+	if status != "error" || !s.Cfg.SkipFailedHours {
+		return fmt.Errorf("%w: %s is %s", domain.ErrIncrementalStalled, hour.Format(time.RFC3339), status)
+	}
+	logger.Named("detect").Warn().
+		Time("hour", hour).
+		Int("detver", s.Cfg.Version).
+		Msg("detect: skipping hour whose backfill failed; re-run it with -start/-end once it's ok")
+	return nil
+}
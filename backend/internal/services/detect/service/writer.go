@@ -13,13 +13,27 @@ import (
 
 // WriterConfig controls detector stamping
 type WriterConfig struct {
-	Version int // detector_version to stamp into hits
-	DryRun  bool
+	Version       int // detector_version to stamp into hits
+	DryRun        bool
+	LangScoped    bool   // only run rules for the utterance's lang_code (+ language-neutral)
+	MaxSeverity   int    // cap on emitted severity (0 = no ceiling)
+	SelfDirected  string // first-person targeting mode ("" = off)
+	ContextWindow int    // bytes of pre/post context per hit (0 = none)
+	SkipQuotes    bool   // drop hits inside '>' quoted reply lines
+
+	QuotedReportDelta int  // severity delta for quoted slurs in a reporting utterance (0 = off)
+	FoldHomoglyphs    bool // match Cyrillic/Greek lookalikes in mixed-script words as Latin
+	RequireContext    bool // enforce templates' declared context signals (requires/requires_any)
+	ShoutingDelta     int  // severity delta for all-caps spans (needs WriteInput.TextRaw; 0 = off)
+
+	// LangDetector fills lang_code for utterances without one (nil = leave it to ClickHouse)
+	LangDetector dom.LangDetector
 }
 
 // WriterService implements domain.WriterPort
 type WriterService struct {
 	cfg WriterConfig
+	rp  *rulepack.Pack
 	det *detector.Detector
 	hw  hitsdom.WriterPort // dependency: hits writer
 }
@@ -32,13 +46,24 @@ func NewWriter(hw hitsdom.WriterPort, cfg WriterConfig) *WriterService {
 	}
 	return &WriterService{
 		cfg: cfg,
+		rp:  rp,
 		det: detector.NewWithOptions(rp, cfg.Version, detector.Options{
 			MaxTotalHits:              0,
 			AllowOverlapping:          false,
-			ContextWindow:             64,
+			ContextWindow:             cfg.ContextWindow,
 			SeverityDeltaInCodeFence:  -1,
 			SeverityDeltaInCodeInline: -1,
 			SeverityDeltaInQuote:      -1,
+			SeverityDeltaInURL:        -1,
+			SeverityDeltaInDiff:       -1,
+			LangScoped:                cfg.LangScoped,
+			MaxSeverity:               cfg.MaxSeverity,
+			SelfDirected:              cfg.SelfDirected,
+			SkipQuoteZones:            cfg.SkipQuotes,
+			SeverityDeltaQuotedReport: cfg.QuotedReportDelta,
+			FoldHomoglyphs:            cfg.FoldHomoglyphs,
+			RequireContextSignals:     cfg.RequireContext,
+			SeverityDeltaShouting:     cfg.ShoutingDelta,
 		}),
 		hw: hw,
 	}
@@ -64,16 +89,13 @@ func (s *WriterService) Write(ctx context.Context, xs []dom.WriteInput) (int, er
 			continue
 		}
 
-		matches := s.det.Scan(u.TextNorm)
-		lang := str.Deref(u.LangCode) // "" => repo writes NULL
+		lang, reliable := ResolveLang(s.cfg.LangDetector, u.LangCode, u.TextNorm) // "" => repo writes NULL
+		matches, _ := s.det.ScanCased(u.TextNorm, CasedText(u.TextRaw, u.TextNorm), ScanLang(lang, reliable))
 
-		for _, m := range matches {
-			srcRank := 1
-			if m.Source == detector.SourceTemplate {
-				srcRank = 2
-			}
+		for _, m := range BestPerSpan(matches) {
+			srcRank := sourceRank(m.Source)
 			cat := mapCategory(m.Category)
-			sev := mapSeverity(m.Severity)
+			sev := s.rp.SeverityLabel(m.Severity)
 			cRank := categoryRank(cat)
 
 			for _, sp := range m.Spans {
@@ -97,7 +119,8 @@ func (s *WriterService) Write(ctx context.Context, xs []dom.WriteInput) (int, er
 					Source:          u.Source,
 					RepoHID:         u.RepoHID,
 					ActorHID:        u.ActorHID,
-					LangCode:        lang,   // "" => NULL
+					LangCode:        lang, // "" => NULL
+					LangReliable:    reliable,
 					Term:            m.Term, // normalized term
 					Category:        cat,
 					Severity:        sev,
@@ -158,13 +181,72 @@ func (s *WriterService) WriteOne(ctx context.Context, x dom.WriteInput) error {
 	return err
 }
 
-// mapSeverity coerces rulepack severities into the DB enum
-func mapSeverity(n int) string {
-	if n <= 1 {
-		return "mild"
+// BestPerSpan is the row projection Write stores: one single-span hit per (term, span),
+// preferring template over lemma matches, then the higher-ranked category; ties keep the
+// first. Order follows the first appearance of each (term, span)
+func BestPerSpan(matches []detector.Hit) []detector.Hit {
+	type key struct {
+		term string
+		a, b int
+	}
+	idx := make(map[key]int, len(matches))
+	out := make([]detector.Hit, 0, len(matches))
+	for _, m := range matches {
+		for _, sp := range m.Spans {
+			cp := m
+			cp.Spans = [][2]int{sp}
+			k := key{m.Term, sp[0], sp[1]}
+			i, ok := idx[k]
+			switch {
+			case !ok:
+				idx[k] = len(out)
+				out = append(out, cp)
+			case outranks(cp, out[i]):
+				out[i] = cp
+			}
+		}
+	}
+	return out
+}
+
+// outranks reports whether a wins a (term, span) over b: template beats lemma, then category rank
+func outranks(a, b detector.Hit) bool {
+	sa, sb := sourceRank(a.Source), sourceRank(b.Source)
+	if sa != sb {
+		return sa > sb
+	}
+	return categoryRank(mapCategory(a.Category)) > categoryRank(mapCategory(b.Category))
+}
+
+func sourceRank(s detector.Source) int {
+	if s == detector.SourceTemplate {
+		return 2
+	}
+	return 1
+}
+
+// ResolveLang returns the utterance's own lang_code, or infers one from the normalized text
+// when it has none and a detector is configured. reliable is nil for an upstream lang_code
+// (the column stays NULL) and the detector's verdict for an inferred one
+func ResolveLang(det dom.LangDetector, code *string, text string) (string, *bool) {
+	lang := str.Deref(code)
+	if lang != "" || det == nil {
+		return lang, nil
+	}
+	lang, ok := det.DetectLang(text)
+	if lang == "" {
+		return "", nil
+	}
+	return lang, &ok
+}
+
+// ScanLang is the lang to scope rules by: an unreliable guess is only stamped on hits, the
+// scan stays language-neutral so a wrong guess can't silence the utterance's real language
+func ScanLang(lang string, reliable *bool) string {
+	if reliable != nil && !*reliable {
+		return ""
 	}
-	// reserve "slur_masked" for future specialized pack
-	return "strong"
+	return lang
 }
 
 // mapCategory coerces rulepack categories into the DB enum
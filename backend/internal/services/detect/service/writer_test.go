@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"swearjar/internal/core/detector"
+	dom "swearjar/internal/services/detect/domain"
+)
+
+type fixedLang struct {
+	lang     string
+	reliable bool
+}
+
+func (f fixedLang) DetectLang(string) (string, bool) { return f.lang, f.reliable }
+
+func TestWriterUnreliableLangScansNeutral(t *testing.T) {
+	write := func(det dom.LangDetector) *hitsLog {
+		t.Helper()
+		hw := &hitsLog{}
+		w := NewWriter(hw, WriterConfig{Version: 1, LangScoped: true, LangDetector: det})
+		if _, err := w.Write(context.Background(), []dom.WriteInput{{
+			UtteranceID: "u1",
+			TextNorm:    "this build is shit",
+			CreatedAt:   time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+		}}); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		return hw
+	}
+
+	hw := write(fixedLang{"de", false})
+	if len(hw.xs) == 0 {
+		t.Fatal("an unreliable guess scoped the scan and dropped the English hit")
+	}
+	if h := hw.xs[0]; h.LangCode != "de" || h.LangReliable == nil || *h.LangReliable {
+		t.Fatalf("hit stamped %q/%v, want the guess kept as de/unreliable", h.LangCode, h.LangReliable)
+	}
+
+	if hw := write(fixedLang{"de", true}); len(hw.xs) != 0 {
+		t.Fatalf("a reliable guess should scope the scan, got %d hits", len(hw.xs))
+	}
+}
+
+func TestBestPerSpan(t *testing.T) {
+	lemma := func(term, cat string, spans ...[2]int) detector.Hit {
+		return detector.Hit{Term: term, Category: cat, Source: detector.SourceLemma, Spans: spans}
+	}
+	tmpl := lemma("shit", "tooling_rage", [2]int{5, 9})
+	tmpl.Source = detector.SourceTemplate
+
+	got := BestPerSpan([]detector.Hit{
+		lemma("shit", "generic", [2]int{5, 9}, [2]int{20, 24}),
+		tmpl,                                    // template beats the lemma at 5-9
+		lemma("shit", "bot_rage", [2]int{5, 9}), // higher category, but a lemma
+		lemma("shit", "bot_rage", [2]int{20, 24}), // same source, higher category wins
+		lemma("shit", "self_own", [2]int{20, 24}), // lower category loses
+		lemma("crap", "generic", [2]int{5, 9}),    // another term keeps its own row
+	})
+
+	type row struct {
+		term, cat string
+		src       detector.Source
+		span      [2]int
+	}
+	var rows []row
+	for _, h := range got {
+		if len(h.Spans) != 1 {
+			t.Fatalf("%+v: want exactly one span per row", h)
+		}
+		rows = append(rows, row{h.Term, h.Category, h.Source, h.Spans[0]})
+	}
+	want := []row{
+		{"shit", "tooling_rage", detector.SourceTemplate, [2]int{5, 9}},
+		{"shit", "bot_rage", detector.SourceLemma, [2]int{20, 24}},
+		{"crap", "generic", detector.SourceLemma, [2]int{5, 9}},
+	}
+	if !slices.Equal(rows, want) {
+		t.Fatalf("rows\n got %+v\nwant %+v", rows, want)
+	}
+}
@@ -75,3 +75,10 @@ type ActorRecord struct {
 	CreatedAt, UpdatedAt, NextRefreshAt            *time.Time
 	ETag, APIURL                                   *string
 }
+
+// QueueDepth summarizes a catalog queue for monitoring
+type QueueDepth struct {
+	Total    int64 // all queued rows
+	Due      int64 // next_attempt_at <= now()
+	Retrying int64 // attempts > 0 (at least one failed fetch)
+}
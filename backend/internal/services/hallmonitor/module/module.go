@@ -32,6 +32,9 @@ func New(deps modkit.Deps, overrides Options) *Module {
 	if overrides.TokensCSV != "" {
 		opts.TokensCSV = overrides.TokensCSV
 	}
+	if overrides.GHBaseURL != "" {
+		opts.GHBaseURL = overrides.GHBaseURL
+	}
 	if overrides.DryRun {
 		opts.DryRun = true
 	}
@@ -43,12 +46,21 @@ func New(deps modkit.Deps, overrides Options) *Module {
 		RatePerSec:          opts.RatePerSec,
 		Burst:               opts.Burst,
 		TokensCSV:           opts.TokensCSV,
+		GHBaseURL:           opts.GHBaseURL,
 		DryRun:              opts.DryRun,
+		RecordRenames:       opts.RecordRenames,
+		GHMaxIdlePerHost:    opts.GHMaxIdlePerHost,
+		GHIdleConnTimeout:   opts.GHIdleConnTimeout,
 		DefaultSeedLimit:    opts.DefaultSeedLimit,
 		DefaultRefreshLimit: opts.DefaultRefreshLimit,
 		QueueTakeBatch:      opts.QueueTakeBatch,
 		RetryBaseMs:         int(opts.RetryBase.Milliseconds()),
 		MaxAttempts:         opts.MaxAttempts,
+		RevalidateWindow:    opts.RevalidateWindow,
+		RepoTick:            opts.RepoTick,
+		ActorTick:           opts.ActorTick,
+		TickJitter:          opts.TickJitter,
+		TickMaxDelay:        opts.TickMaxDelay,
 	})
 
 	m := &Module{deps: deps}
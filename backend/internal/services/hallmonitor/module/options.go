@@ -12,16 +12,34 @@ type Options struct {
 	RatePerSec  float64
 	Burst       int
 	TokensCSV   string
+	GHBaseURL   string // GitHub API root (GHES: https://host/api/v3); empty = api.github.com
 	DryRun      bool
 
+	// RecordRenames appends opted-in repo renames to repo_renames; the label is updated either way
+	RecordRenames bool
+
+	// GitHub connection pool; zero keeps the client defaults
+	GHMaxIdlePerHost  int
+	GHIdleConnTimeout time.Duration
+
 	// Seeding/refresh defaults (can be overridden by flags)
 	DefaultSeedLimit    int
 	DefaultRefreshLimit int
 
+	// RevalidateWindow skips GitHub entirely for repos/actors revalidated this recently and
+	// just reschedules them (0 = always send the conditional request)
+	RevalidateWindow time.Duration
+
 	// DB knobs
 	QueueTakeBatch int
 	RetryBase      time.Duration
 	MaxAttempts    int
+
+	// Queue polling cadence
+	RepoTick     time.Duration
+	ActorTick    time.Duration
+	TickJitter   float64
+	TickMaxDelay time.Duration
 }
 
 // FromConfig reads options using HALLMONITOR_ prefix
@@ -32,11 +50,20 @@ func FromConfig(cfg config.Conf) Options {
 		RatePerSec:          hm.MayFloat64("GH_RPS", 2.0),
 		Burst:               hm.MayInt("GH_BURST", 4),
 		TokensCSV:           hm.MayString("GH_TOKENS", ""),
+		GHBaseURL:           hm.MayString("GH_BASE_URL", ""),
 		DryRun:              hm.MayBool("DRYRUN", false),
+		RecordRenames:       hm.MayBool("RECORD_RENAMES", false),
+		GHMaxIdlePerHost:    hm.MayInt("GH_MAX_IDLE_PER_HOST", 0),
+		GHIdleConnTimeout:   hm.MayDuration("GH_IDLE_CONN_TIMEOUT", 0),
 		DefaultSeedLimit:    hm.MayInt("SEED_LIMIT", 0),
 		DefaultRefreshLimit: hm.MayInt("REFRESH_LIMIT", 0),
+		RevalidateWindow:    hm.MayDuration("REVALIDATE_WINDOW", 0),
 		QueueTakeBatch:      hm.MayInt("QUEUE_TAKE_BATCH", 64),
 		RetryBase:           hm.MayDuration("RETRY_BASE", 500*time.Millisecond),
 		MaxAttempts:         hm.MayInt("MAX_ATTEMPTS", 10),
+		RepoTick:            hm.MayDuration("REPO_TICK", 500*time.Millisecond),
+		ActorTick:           hm.MayDuration("ACTOR_TICK", 750*time.Millisecond),
+		TickJitter:          hm.MayFloat64("TICK_JITTER", 0.2),
+		TickMaxDelay:        hm.MayDuration("TICK_MAX_DELAY", 30*time.Second),
 	}
 }
@@ -0,0 +1,30 @@
+// Package repo provides the hallmonitor repository implementation
+package repo
+
+import (
+	"context"
+
+	"swearjar/internal/services/hallmonitor/domain"
+)
+
+// countQueueSQL is shared by both catalog queues; table names are constants, never input
+const countQueueSQL = `
+	SELECT
+	  count(*),
+	  count(*) FILTER (WHERE next_attempt_at <= now()),
+	  count(*) FILTER (WHERE attempts > 0)
+	FROM `
+
+func (r *queries) CountRepoQueue(ctx context.Context) (domain.QueueDepth, error) {
+	return r.countQueue(ctx, "repo_catalog_queue")
+}
+
+func (r *queries) CountActorQueue(ctx context.Context) (domain.QueueDepth, error) {
+	return r.countQueue(ctx, "actor_catalog_queue")
+}
+
+func (r *queries) countQueue(ctx context.Context, table string) (domain.QueueDepth, error) {
+	var d domain.QueueDepth
+	err := r.q.QueryRow(ctx, countQueueSQL+table).Scan(&d.Total, &d.Due, &d.Retrying)
+	return d, err
+}
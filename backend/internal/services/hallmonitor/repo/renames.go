@@ -0,0 +1,21 @@
+package repo
+
+import (
+	"context"
+
+	perr "swearjar/internal/platform/errors"
+)
+
+// RecordRepoRenameHID appends a rename to repo_renames, tied to the repo's active opt-in
+// receipt so the history is dropped with it. Without an active opt-in nothing is written:
+// old and new names are PII like full_name itself
+func (r *queries) RecordRepoRenameHID(ctx context.Context, repoHID []byte, from, to string) error {
+	_, err := r.q.Exec(ctx, `
+		INSERT INTO repo_renames (repo_hid, consent_id, old_full_name, new_full_name)
+		SELECT $1, consent_id, $2, $3
+		FROM consent_receipts
+		WHERE principal='repo' AND principal_hid=$1 AND action='opt_in' AND state='active'
+		LIMIT 1
+	`, repoHID, from, to)
+	return perr.FromPostgresWithField(err, "record repo rename")
+}
@@ -3,12 +3,11 @@ package repo
 
 import (
 	"context"
-	"crypto/sha256"
 	"database/sql"
 	"fmt"
-	"strconv"
 	"time"
 
+	"swearjar/internal/core/hidscheme"
 	"swearjar/internal/modkit/repokit"
 	perr "swearjar/internal/platform/errors"
 	"swearjar/internal/services/hallmonitor/domain"
@@ -28,6 +27,10 @@ type Repo interface {
 	LeaseRepos(ctx context.Context, n int, leaseFor time.Duration) ([]domain.Job, error)
 	LeaseActors(ctx context.Context, n int, leaseFor time.Duration) ([]domain.ActorJob, error)
 
+	// Queue depth counters for monitoring (read-only)
+	CountRepoQueue(ctx context.Context) (domain.QueueDepth, error)
+	CountActorQueue(ctx context.Context) (domain.QueueDepth, error)
+
 	// Queue completion with retry backoff (numeric wrappers + HID-native)
 	AckRepo(ctx context.Context, repoID int64) error
 	NackRepo(ctx context.Context, repoID int64, backoff time.Duration, lastErr string) error
@@ -41,6 +44,7 @@ type Repo interface {
 	// Metadata upserts after successful fetches from GitHub (numeric wrappers + HID-native)
 	UpsertRepository(ctx context.Context, r domain.RepositoryRecord) error
 	UpsertRepositoryHID(ctx context.Context, repoHID []byte, r domain.RepositoryRecord) error
+	RecordRepoRenameHID(ctx context.Context, repoHID []byte, from, to string) error
 	TouchRepository304(ctx context.Context, repoID int64, nextRefreshAt time.Time, etag string) error
 	TouchRepository304HID(ctx context.Context, repoHID []byte, nextRefreshAt time.Time, etag string) error
 	UpsertActor(ctx context.Context, a domain.ActorRecord) error
@@ -48,6 +52,10 @@ type Repo interface {
 	TouchActor304(ctx context.Context, actorID int64, nextRefreshAt time.Time, etag string) error
 	TouchActor304HID(ctx context.Context, actorHID []byte, nextRefreshAt time.Time, etag string) error
 
+	// Revalidation guard: reschedule instead of fetching when revalidated within the window
+	SkipRecentRepoHID(ctx context.Context, repoHID []byte, within time.Duration) (bool, error)
+	SkipRecentActorHID(ctx context.Context, actorHID []byte, within time.Duration) (bool, error)
+
 	// Seed/refresh helpers to fill queues in bulk (all HID in SQL, numeric sentinels OK)
 	EnqueueMissingReposFromUtterances(ctx context.Context, since, until time.Time, limit int) (int, error)
 	EnqueueMissingActorsFromUtterances(ctx context.Context, since, until time.Time, limit int) (int, error)
@@ -113,14 +121,14 @@ func NewPG() repokit.Binder[Repo] { return PG{} }
 // Bind binds a Queryer to a Postgres implementation of Repo
 func (PG) Bind(q repokit.Queryer) Repo { return &queries{q: q} }
 
-// HID derivation (must match ingest/backfill)
+// HID derivation lives in hidscheme so it matches ingest/backfill
 func makeRepoHID(repoID int64) []byte {
-	h := sha256.Sum256([]byte("repo:" + strconv.FormatInt(repoID, 10)))
+	h := hidscheme.Repo(repoID)
 	return h[:]
 }
 
 func makeActorHID(actorID int64) []byte {
-	h := sha256.Sum256([]byte("actor:" + strconv.FormatInt(actorID, 10)))
+	h := hidscheme.Actor(actorID)
 	return h[:]
 }
 
@@ -313,6 +321,9 @@ func (r *queries) UpsertRepository(ctx context.Context, rec domain.RepositoryRec
 }
 
 // UpsertRepositoryHID upserts a repository record using repo_hid
+// Labels (full_name, api_url) follow GitHub while opted in: a fetched value replaces the stored
+// one, so a rename or transfer (same repo ID, same HID) relabels the row; a fetch without a
+// name keeps the stored label. Without consent no label is written
 func (r *queries) UpsertRepositoryHID(ctx context.Context, repoHID []byte, rec domain.RepositoryRecord) error {
 	// Ensure principal exists (idempotent)
 	if _, err := r.q.Exec(ctx,
@@ -0,0 +1,54 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	perr "swearjar/internal/platform/errors"
+	"swearjar/internal/platform/store"
+)
+
+// SkipRecentRepoHID reschedules a repo that was revalidated within the last `within` instead of
+// letting the worker spend a GitHub request on what would almost surely be another 304.
+// "Revalidated" reads the existing columns: fetched_at is within the window and an etag is
+// stored, so the next call would be conditional. next_refresh_at moves to at least
+// fetched_at + within. Reports false, changing nothing, when the repo is due a real fetch
+func (r *queries) SkipRecentRepoHID(ctx context.Context, repoHID []byte, within time.Duration) (bool, error) {
+	return r.skipRecent(ctx, `
+		UPDATE repositories
+		SET next_refresh_at = GREATEST(next_refresh_at, fetched_at + $2::interval)
+		WHERE repo_hid = $1
+		  AND gone_at IS NULL
+		  AND etag IS NOT NULL
+		  AND fetched_at > now() - $2::interval
+		RETURNING next_refresh_at
+	`, repoHID, within)
+}
+
+// SkipRecentActorHID is SkipRecentRepoHID for actors
+func (r *queries) SkipRecentActorHID(ctx context.Context, actorHID []byte, within time.Duration) (bool, error) {
+	return r.skipRecent(ctx, `
+		UPDATE actors
+		SET next_refresh_at = GREATEST(next_refresh_at, fetched_at + $2::interval)
+		WHERE actor_hid = $1
+		  AND gone_at IS NULL
+		  AND etag IS NOT NULL
+		  AND fetched_at > now() - $2::interval
+		RETURNING next_refresh_at
+	`, actorHID, within)
+}
+
+func (r *queries) skipRecent(ctx context.Context, sqlText string, hid []byte, within time.Duration) (bool, error) {
+	if within <= 0 {
+		return false, nil
+	}
+	var next sql.NullTime
+	if err := r.q.QueryRow(ctx, sqlText, hid, within.String()).Scan(&next); err != nil {
+		if store.IsNoRows(err) {
+			return false, nil
+		}
+		return false, perr.FromPostgresWithField(err, "skip recently revalidated")
+	}
+	return true, nil
+}
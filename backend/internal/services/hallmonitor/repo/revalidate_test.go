@@ -0,0 +1,78 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"swearjar/internal/modkit/repokit"
+)
+
+// rowQ answers QueryRow with a single next_refresh_at, or err, and records the call
+type rowQ struct {
+	repokit.Queryer
+
+	err   error
+	calls int
+	sql   string
+	args  []any
+}
+
+type row struct{ err error }
+
+func (r row) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+	return dest[0].(interface{ Scan(any) error }).Scan(time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC))
+}
+
+func (q *rowQ) QueryRow(_ context.Context, sql string, args ...any) repokit.Row {
+	q.calls++
+	q.sql, q.args = sql, args
+	return row{err: q.err}
+}
+
+func TestSkipRecent(t *testing.T) {
+	hid := []byte{0xab}
+	cases := []struct {
+		name   string
+		within time.Duration
+		err    error
+		want   bool
+		fails  bool
+	}{
+		{"skipped", time.Hour, nil, true, false},
+		{"due a real fetch", time.Hour, pgx.ErrNoRows, false, false},
+		{"disabled", 0, nil, false, false},
+		{"store error", time.Hour, errors.New("conn reset"), false, true},
+	}
+	for _, kind := range []struct {
+		table string
+		fn    func(*queries, context.Context, []byte, time.Duration) (bool, error)
+	}{
+		{"repositories", (*queries).SkipRecentRepoHID},
+		{"actors", (*queries).SkipRecentActorHID},
+	} {
+		for _, tc := range cases {
+			q := &rowQ{err: tc.err}
+			got, err := kind.fn(&queries{q: q}, context.Background(), hid, tc.within)
+			if got != tc.want || (err != nil) != tc.fails {
+				t.Errorf("%s %s: got %v, %v; want %v (error %v)", kind.table, tc.name, got, err, tc.want, tc.fails)
+			}
+			if tc.within <= 0 {
+				if q.calls != 0 {
+					t.Errorf("%s %s: queried with the skip disabled", kind.table, tc.name)
+				}
+				continue
+			}
+			if !strings.Contains(q.sql, "UPDATE "+kind.table) || len(q.args) != 2 || q.args[1] != "1h0m0s" {
+				t.Errorf("%s %s: sql/args %q %v", kind.table, tc.name, q.sql, q.args)
+			}
+		}
+	}
+}
@@ -0,0 +1,122 @@
+// Package service contains hallmonitor workflows
+package service
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	gh "swearjar/internal/adapters/ingest/github"
+	perr "swearjar/internal/platform/errors"
+)
+
+// PacerConfig controls the polling cadence of a queue loop
+type PacerConfig struct {
+	Interval time.Duration // base tick between lease attempts
+	Jitter   float64       // +/- fraction applied to each tick (0..1), e.g. 0.2
+	MaxDelay time.Duration // ceiling for the adaptive slowdown
+	MaxBatch int           // lease batch ceiling (QueueTakeBatch)
+	MinBatch int           // lease batch floor under pressure
+}
+
+// pacer is a per-loop AIMD controller over (tick interval, lease batch)
+//
+//   - clean rounds: additive increase - batch grows by one, delay shrinks by one base interval
+//   - rate-limited round: multiplicative decrease - batch halves, delay doubles (capped)
+//
+// Every tick is jittered and the first tick is phase-shifted by a random offset so
+// several worker processes started together do not lease and hit GitHub in lockstep
+type pacer struct {
+	cfg PacerConfig
+
+	mu      sync.Mutex
+	rng     *rand.Rand
+	delay   time.Duration
+	batch   int
+	limited bool // a rate limit was observed during the current round
+}
+
+func newPacer(cfg PacerConfig, seed int64) *pacer {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 500 * time.Millisecond
+	}
+	if cfg.Jitter < 0 {
+		cfg.Jitter = 0
+	}
+	if cfg.Jitter > 1 {
+		cfg.Jitter = 1
+	}
+	if cfg.MaxDelay < cfg.Interval {
+		cfg.MaxDelay = max(cfg.Interval, 30*time.Second)
+	}
+	if cfg.MaxBatch <= 0 {
+		cfg.MaxBatch = 64
+	}
+	if cfg.MinBatch <= 0 {
+		cfg.MinBatch = 1
+	}
+	if cfg.MinBatch > cfg.MaxBatch {
+		cfg.MinBatch = cfg.MaxBatch
+	}
+	return &pacer{
+		cfg:   cfg,
+		rng:   rand.New(rand.NewSource(seed)),
+		delay: cfg.Interval,
+		batch: cfg.MaxBatch,
+	}
+}
+
+// First returns a random phase offset in [0, Interval) for the first tick
+func (p *pacer) First() time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return time.Duration(p.rng.Int63n(int64(p.cfg.Interval)))
+}
+
+// Batch returns the current lease batch size
+func (p *pacer) Batch() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.batch
+}
+
+// Observe records a job error; rate-limit responses mark the round as congested
+func (p *pacer) Observe(err error) {
+	if p == nil || err == nil || !isRateLimited(err) {
+		return
+	}
+	p.mu.Lock()
+	p.limited = true
+	p.mu.Unlock()
+}
+
+// Next closes the current round, adapts delay/batch and returns the jittered wait
+// idle=true means the lease returned nothing; cadence relaxes to base without growing batch
+func (p *pacer) Next(idle bool) time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	switch {
+	case p.limited:
+		p.delay = min(p.delay*2, p.cfg.MaxDelay)
+		p.batch = max(p.batch/2, p.cfg.MinBatch)
+	case idle:
+		p.delay = max(p.delay-p.cfg.Interval, p.cfg.Interval)
+	default:
+		p.delay = max(p.delay-p.cfg.Interval, p.cfg.Interval)
+		p.batch = min(p.batch+1, p.cfg.MaxBatch)
+	}
+	p.limited = false
+
+	if p.cfg.Jitter == 0 {
+		return p.delay
+	}
+	// uniform in [delay*(1-j), delay*(1+j)]
+	f := 1 + p.cfg.Jitter*(2*p.rng.Float64()-1)
+	return time.Duration(float64(p.delay) * f)
+}
+
+// isRateLimited reports GitHub primary/secondary rate limits in either error shape
+func isRateLimited(err error) bool {
+	return gh.IsRateLimited(err) || perr.IsCode(err, perr.ErrorCodeTooManyRequests)
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	perr "swearjar/internal/platform/errors"
+)
+
+var errLimited = perr.New(perr.ErrorCodeTooManyRequests, "rate limited")
+
+func TestPacerBacksOffOnRateLimit(t *testing.T) {
+	p := newPacer(PacerConfig{Interval: 100 * time.Millisecond, MaxDelay: time.Second, MaxBatch: 16, MinBatch: 2}, 1)
+
+	steps := []struct {
+		delay time.Duration
+		batch int
+	}{
+		{200 * time.Millisecond, 8},
+		{400 * time.Millisecond, 4},
+		{800 * time.Millisecond, 2},
+		{time.Second, 2}, // capped at MaxDelay, floored at MinBatch
+		{time.Second, 2},
+	}
+	for i, want := range steps {
+		p.Observe(errors.New("transient")) // not a rate limit: ignored
+		p.Observe(errLimited)
+		if got := p.Next(false); got != want.delay || p.Batch() != want.batch {
+			t.Fatalf("limited round %d: wait %v batch %d, want %v/%d", i, got, p.Batch(), want.delay, want.batch)
+		}
+	}
+
+	// Additive recovery: one interval and one lease slot per clean round
+	for i := 1; i <= 20; i++ {
+		wantDelay := max(time.Second-time.Duration(i)*100*time.Millisecond, 100*time.Millisecond)
+		wantBatch := min(2+i, 16)
+		if got := p.Next(false); got != wantDelay || p.Batch() != wantBatch {
+			t.Fatalf("clean round %d: wait %v batch %d, want %v/%d", i, got, p.Batch(), wantDelay, wantBatch)
+		}
+	}
+
+	// A rate limit only counts for the round it was observed in
+	p.Observe(errLimited)
+	p.Next(false)
+	if got := p.Next(false); got != 100*time.Millisecond || p.Batch() != 9 {
+		t.Fatalf("round after a limited one: wait %v batch %d", got, p.Batch())
+	}
+}
+
+func TestPacerIdleRoundsDoNotGrowBatch(t *testing.T) {
+	p := newPacer(PacerConfig{Interval: 100 * time.Millisecond, MaxDelay: time.Second, MaxBatch: 16}, 1)
+	p.Observe(errLimited)
+	p.Next(false)
+	p.Observe(errLimited)
+	p.Next(false) // 400ms, batch 4
+
+	for i, want := range []time.Duration{300, 200, 100, 100} {
+		if got := p.Next(true); got != want*time.Millisecond || p.Batch() != 4 {
+			t.Fatalf("idle round %d: wait %v batch %d, want %v/4", i, got, p.Batch(), want*time.Millisecond)
+		}
+	}
+}
+
+func TestPacerJitterBounds(t *testing.T) {
+	const j = 0.25
+	p := newPacer(PacerConfig{Interval: 100 * time.Millisecond, Jitter: j, MaxDelay: 2 * time.Second}, 42)
+
+	for i := range 200 {
+		if i%10 == 0 {
+			p.Observe(errLimited)
+		}
+		got := p.Next(i%3 == 0)
+		lo, hi := time.Duration(float64(p.delay)*(1-j)), time.Duration(float64(p.delay)*(1+j))
+		if got < lo || got > hi {
+			t.Fatalf("round %d: wait %v outside [%v, %v]", i, got, lo, hi)
+		}
+	}
+}
+
+func TestPacerFirstWithinInterval(t *testing.T) {
+	p := newPacer(PacerConfig{Interval: 100 * time.Millisecond}, 7)
+	seen := map[time.Duration]bool{}
+	for range 500 {
+		d := p.First()
+		if d < 0 || d >= 100*time.Millisecond {
+			t.Fatalf("first tick offset %v outside [0, 100ms)", d)
+		}
+		seen[d] = true
+	}
+	if len(seen) < 2 {
+		t.Fatal("first tick offset is not randomized")
+	}
+}
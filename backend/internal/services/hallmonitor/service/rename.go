@@ -0,0 +1,36 @@
+package service
+
+import (
+	"context"
+
+	"swearjar/internal/modkit/repokit"
+	str "swearjar/internal/platform/strings"
+	"swearjar/internal/services/hallmonitor/domain"
+)
+
+// repoRename reports the stored full_name when GitHub now returns a different one for the same
+// repo ID (and so the same HID). Labels are only stored for opted-in repos, so an unlabeled repo
+// never reports a rename; a case-only change counts since the label is shown verbatim
+func repoRename(stored *string, fetched string) (from string, renamed bool) {
+	if stored == nil || *stored == "" || fetched == "" || *stored == fetched {
+		return "", false
+	}
+	return *stored, true
+}
+
+// upsertRepo writes a fetched repo record. When the stored label no longer matches GitHub and
+// RecordRenames is on, the rename is recorded in the same Tx, so a failed upsert is retried
+// against the old label and the rename lands exactly once
+func (s *Svc) upsertRepo(ctx context.Context, repoHID []byte, label *string, rec domain.RepositoryRecord) error {
+	from, renamed := repoRename(label, str.Deref(rec.FullName))
+	if !renamed || !s.config.RecordRenames {
+		return s.Repo.UpsertRepositoryHID(ctx, repoHID, rec)
+	}
+	return s.db.Tx(ctx, func(q repokit.Queryer) error {
+		r := s.binder.Bind(q)
+		if err := r.RecordRepoRenameHID(ctx, repoHID, from, *rec.FullName); err != nil {
+			return err
+		}
+		return r.UpsertRepositoryHID(ctx, repoHID, rec)
+	})
+}
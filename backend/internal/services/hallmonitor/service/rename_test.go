@@ -0,0 +1,28 @@
+package service
+
+import "testing"
+
+func TestRepoRename(t *testing.T) {
+	name := func(s string) *string { return &s }
+	cases := []struct {
+		desc    string
+		stored  *string
+		fetched string
+		from    string
+		renamed bool
+	}{
+		{"renamed", name("acme/widgets"), "acme/gadgets", "acme/widgets", true},
+		{"transferred", name("acme/widgets"), "newco/widgets", "acme/widgets", true},
+		{"case only", name("acme/Widgets"), "acme/widgets", "acme/Widgets", true},
+		{"unchanged", name("acme/widgets"), "acme/widgets", "", false},
+		{"no label stored", nil, "acme/widgets", "", false},
+		{"empty label stored", name(""), "acme/widgets", "", false},
+		{"fetch without name", name("acme/widgets"), "", "", false},
+	}
+	for _, c := range cases {
+		from, renamed := repoRename(c.stored, c.fetched)
+		if from != c.from || renamed != c.renamed {
+			t.Fatalf("%s: got (%q, %v), want (%q, %v)", c.desc, from, renamed, c.from, c.renamed)
+		}
+	}
+}
@@ -50,13 +50,27 @@ type Config struct {
 	RatePerSec          float64
 	Burst               int
 	TokensCSV           string
+	GHBaseURL           string
 	DryRun              bool
+	RecordRenames       bool
+	GHMaxIdlePerHost    int
+	GHIdleConnTimeout   time.Duration
 	DefaultSeedLimit    int
 	DefaultRefreshLimit int
 	QueueTakeBatch      int
 	RetryBaseMs         int
 	MaxAttempts         int
 	Cadence             CadenceConfig
+
+	// RevalidateWindow skips the GitHub call for jobs whose row was revalidated (fetched with an
+	// ETag) this recently and just reschedules them; 0 always asks GitHub
+	RevalidateWindow time.Duration
+
+	// Queue polling cadence (jittered per process; slows down on rate limits)
+	RepoTick     time.Duration
+	ActorTick    time.Duration
+	TickJitter   float64
+	TickMaxDelay time.Duration
 }
 
 // Svc implements the hallmonitor service
@@ -67,6 +81,10 @@ type Svc struct {
 	deps   modkit.Deps
 	config Config
 	gh     *gh.Client
+
+	// per-loop pacers; set by Run
+	repoPace  *pacer
+	actorPace *pacer
 }
 
 // New constructs a hallmonitor service
@@ -78,9 +96,13 @@ func New(deps modkit.Deps, cfg Config) *Svc {
 
 	b := repo.NewPG()
 	client := gh.NewClient(gh.Options{
+		BaseURL:    cfg.GHBaseURL,
 		TokensCSV:  cfg.TokensCSV,
 		MaxRetries: cfg.MaxAttempts,
 		RetryBase:  durationMs(cfg.RetryBaseMs),
+
+		MaxIdleConnsPerHost: cfg.GHMaxIdlePerHost,
+		IdleConnTimeout:     cfg.GHIdleConnTimeout,
 	})
 
 	return &Svc{
@@ -6,6 +6,7 @@ import (
 	"encoding/hex"
 	"errors"
 	"math/rand"
+	"os"
 	"strings"
 	"time"
 
@@ -23,9 +24,24 @@ func (s *Svc) Run(ctx context.Context) error {
 		batch = 64
 	}
 
+	// Per-process seed so concurrently started workers drift apart
+	seed := time.Now().UnixNano() ^ int64(os.Getpid())<<20
+	s.repoPace = newPacer(PacerConfig{
+		Interval: s.config.RepoTick,
+		Jitter:   s.config.TickJitter,
+		MaxDelay: s.config.TickMaxDelay,
+		MaxBatch: batch,
+	}, seed)
+	s.actorPace = newPacer(PacerConfig{
+		Interval: s.config.ActorTick,
+		Jitter:   s.config.TickJitter,
+		MaxDelay: s.config.TickMaxDelay,
+		MaxBatch: batch,
+	}, seed+1)
+
 	errCh := make(chan error, 2)
-	go func() { errCh <- s.runRepoLoop(ctx, batch, leaseFor) }()
-	go func() { errCh <- s.runActorLoop(ctx, batch, leaseFor) }()
+	go func() { errCh <- s.runRepoLoop(ctx, s.repoPace, leaseFor) }()
+	go func() { errCh <- s.runActorLoop(ctx, s.actorPace, leaseFor) }()
 
 	select {
 	case <-ctx.Done():
@@ -35,8 +51,8 @@ func (s *Svc) Run(ctx context.Context) error {
 	}
 }
 
-func (s *Svc) runRepoLoop(ctx context.Context, batch int, leaseFor time.Duration) error {
-	t := time.NewTicker(500 * time.Millisecond)
+func (s *Svc) runRepoLoop(ctx context.Context, p *pacer, leaseFor time.Duration) error {
+	t := time.NewTimer(p.First())
 	defer t.Stop()
 
 	for {
@@ -44,11 +60,12 @@ func (s *Svc) runRepoLoop(ctx context.Context, batch int, leaseFor time.Duration
 		case <-ctx.Done():
 			return ctx.Err()
 		case <-t.C:
-			jobs, err := s.Repo.LeaseRepos(ctx, batch, leaseFor) // HID-keyed jobs
+			jobs, err := s.Repo.LeaseRepos(ctx, p.Batch(), leaseFor) // HID-keyed jobs
 			if err != nil {
 				return err
 			}
 			if len(jobs) == 0 {
+				t.Reset(p.Next(true))
 				continue
 			}
 
@@ -83,6 +100,7 @@ func (s *Svc) runRepoLoop(ctx context.Context, batch int, leaseFor time.Duration
 				// Local hints (don't require consent to read; full_name won't be stored unless opted-in)
 				var etagIn string
 				var owner, name string
+				var label *string
 
 				if fn, et, gone, _, _, err := s.Repo.RepoHintsHID(ctx, j.RepoHID); err == nil {
 					// If repo is tombstoned, drop the job immediately (no GH call)
@@ -93,9 +111,18 @@ func (s *Svc) runRepoLoop(ctx context.Context, batch int, leaseFor time.Duration
 					if et != nil {
 						etagIn = *et
 					}
-					if fn != nil {
-						owner, name = splitOwnerName(*fn)
+					label = fn
+				}
+
+				// Revalidated recently: another conditional request would almost surely 304
+				if skipped, err := s.Repo.SkipRecentRepoHID(ctx, j.RepoHID, s.config.RevalidateWindow); err != nil {
+					s.handleRepoErrorHID(ctx, j.RepoHID, j.Attempts, err)
+					continue
+				} else if skipped {
+					if err := s.Repo.AckRepoHID(ctx, j.RepoHID); err != nil {
+						return err
 					}
+					continue
 				}
 
 				// Fetch repo by numeric ID with conditional ETag
@@ -121,14 +148,15 @@ func (s *Svc) runRepoLoop(ctx context.Context, batch int, leaseFor time.Duration
 					continue
 				}
 
-				// Languages: prefer owner/name; if absent, try from repoDoc
-				if owner == "" || name == "" {
-					if repoDoc.FullName != "" {
-						owner, name = splitOwnerName(repoDoc.FullName)
-					} else if repoDoc.Owner.Login != "" && repoDoc.Name != "" {
-						owner = repoDoc.Owner.Login
-						name = repoDoc.Name
-					}
+				// Languages: prefer the fetched name (the stored label is stale after a rename),
+				// then owner/name from repoDoc, then the stored label
+				switch {
+				case repoDoc.FullName != "":
+					owner, name = splitOwnerName(repoDoc.FullName)
+				case repoDoc.Owner.Login != "" && repoDoc.Name != "":
+					owner, name = repoDoc.Owner.Login, repoDoc.Name
+				case label != nil:
+					owner, name = splitOwnerName(*label)
 				}
 				var langs map[string]int64
 				if owner != "" && name != "" {
@@ -139,7 +167,7 @@ func (s *Svc) runRepoLoop(ctx context.Context, batch int, leaseFor time.Duration
 
 				rec := mapRepoToRecord(s.config.Cadence, repoDoc, langs, etagOut)
 				// NOTE: UpsertRepositoryHID will only persist PII (full_name) when an active opt-in exists
-				if err := s.Repo.UpsertRepositoryHID(ctx, j.RepoHID, rec); err != nil {
+				if err := s.upsertRepo(ctx, j.RepoHID, label, rec); err != nil {
 					s.handleRepoErrorHID(ctx, j.RepoHID, j.Attempts, err)
 					continue
 				}
@@ -147,12 +175,13 @@ func (s *Svc) runRepoLoop(ctx context.Context, batch int, leaseFor time.Duration
 					return err
 				}
 			}
+			t.Reset(p.Next(false))
 		}
 	}
 }
 
-func (s *Svc) runActorLoop(ctx context.Context, batch int, leaseFor time.Duration) error {
-	t := time.NewTicker(750 * time.Millisecond)
+func (s *Svc) runActorLoop(ctx context.Context, p *pacer, leaseFor time.Duration) error {
+	t := time.NewTimer(p.First())
 	defer t.Stop()
 
 	for {
@@ -160,11 +189,12 @@ func (s *Svc) runActorLoop(ctx context.Context, batch int, leaseFor time.Duratio
 		case <-ctx.Done():
 			return ctx.Err()
 		case <-t.C:
-			jobs, err := s.Repo.LeaseActors(ctx, batch, leaseFor) // HID-keyed jobs
+			jobs, err := s.Repo.LeaseActors(ctx, p.Batch(), leaseFor) // HID-keyed jobs
 			if err != nil {
 				return err
 			}
 			if len(jobs) == 0 {
+				t.Reset(p.Next(true))
 				continue
 			}
 
@@ -204,6 +234,16 @@ func (s *Svc) runActorLoop(ctx context.Context, batch int, leaseFor time.Duratio
 					}
 				}
 
+				if skipped, err := s.Repo.SkipRecentActorHID(ctx, j.ActorHID, s.config.RevalidateWindow); err != nil {
+					s.handleActorErrorHID(ctx, j.ActorHID, j.Attempts, err)
+					continue
+				} else if skipped {
+					if err := s.Repo.AckActorHID(ctx, j.ActorHID); err != nil {
+						return err
+					}
+					continue
+				}
+
 				userDoc, etagOut, notmod, err := s.gh.UserByID(ctx, ghUserID, etagIn)
 				if err != nil {
 					s.handleActorErrorHID(ctx, j.ActorHID, j.Attempts, err)
@@ -232,6 +272,7 @@ func (s *Svc) runActorLoop(ctx context.Context, batch int, leaseFor time.Duratio
 					return err
 				}
 			}
+			t.Reset(p.Next(false))
 		}
 	}
 }
@@ -252,6 +293,7 @@ func (s *Svc) handleRepoErrorHID(ctx context.Context, repoHID []byte, attempts i
 	}
 
 	// Non-terminal -> NACK with exponential backoff (+ extra for 429)
+	s.repoPace.Observe(err)
 	msg := trimErr(err)
 	back := backoffFor(attempts, s.config.RetryBaseMs)
 	if perr.IsCode(err, perr.ErrorCodeTooManyRequests) {
@@ -278,6 +320,7 @@ func (s *Svc) handleActorErrorHID(ctx context.Context, actorHID []byte, attempts
 		return
 	}
 
+	s.actorPace.Observe(err)
 	msg := trimErr(err)
 	back := backoffFor(attempts, s.config.RetryBaseMs)
 	if perr.IsCode(err, perr.ErrorCodeTooManyRequests) {
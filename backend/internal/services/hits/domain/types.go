@@ -38,6 +38,10 @@ type Filters struct {
 	Category   string
 	Severity   string
 	Version    *int
+
+	// MinSeverity keeps hits at or above this severity rank (1 mild, 2 strong, 3 slur_masked;
+	// see rulepack.ParseMinSeverity); 0 = any
+	MinSeverity int
 }
 
 // HitWrite represents a hit to be written to the storage
@@ -48,9 +52,11 @@ type HitWrite struct {
 	RepoHID     []byte
 	ActorHID    []byte
 	LangCode    string // empty => NULL in DB
-	Term        string
-	Category    Category
-	Severity    Severity
+	// LangReliable is set when the detect service inferred LangCode; nil => NULL in DB
+	LangReliable *bool
+	Term         string
+	Category     Category
+	Severity     Severity
 
 	// Span in normalized text
 	SpanStart       int
@@ -66,7 +72,7 @@ type HitWrite struct {
 	// Context gating / targeting (persisted 1:1 to ClickHouse)
 	// Enum8 labels in CH expect non-empty strings; repo will coerce "" -> "none" where applicable
 	CtxAction       string  // "none" | "upgraded" | "downgraded"
-	TargetType      string  // "none" | "bot" | "tool" | "lang" | "framework"
+	TargetType      string  // "none" | "bot" | "tool" | "lang" | "framework" | "self"
 	TargetID        string  // LowCardinality(String); empty -> ""
 	TargetName      *string // Nullable(String)
 	TargetSpanStart *int    // Nullable(Int32)
@@ -84,8 +90,7 @@ type Sample struct {
 	Term        string
 	Category    string
 	Severity    string
-	SpanStart   int
-	SpanEnd     int
+	Spans       [][2]int // every [start,end) of this term in the utterance, ascending
 }
 
 // AggByLangRow represents an aggregation of hits by language and day
@@ -28,7 +28,7 @@ func New(deps modkit.Deps) *Module {
 	}
 	opts := FromConfig(deps.Cfg)
 
-	storage := repo.NewCH(deps.CH)
+	storage := repo.NewCH(deps.CH, opts.VerifyInserts)
 	svc := service.New(storage, service.Config{HardLimit: opts.HardLimit})
 
 	m := &Module{deps: deps}
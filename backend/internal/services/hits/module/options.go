@@ -5,12 +5,16 @@ import "swearjar/internal/platform/config"
 // Options holds configuration settings for the hits module
 type Options struct {
 	HardLimit int
+
+	// VerifyInserts re-counts each written batch by ingest_batch_id and fails it on a shortfall
+	VerifyInserts bool
 }
 
 // FromConfig reads configuration settings from the config.Conf
 func FromConfig(cfg config.Conf) Options {
 	hf := cfg.Prefix("CORE_HITS_")
 	return Options{
-		HardLimit: hf.MayInt("HARD_LIMIT", 100),
+		HardLimit:     hf.MayInt("HARD_LIMIT", 100),
+		VerifyInserts: hf.MayBool("VERIFY_INSERTS", false),
 	}
 }
@@ -6,18 +6,22 @@ import (
 	"encoding/binary"
 	"hash/fnv"
 	"strings"
+	"time"
 
+	perr "swearjar/internal/platform/errors"
 	"swearjar/internal/platform/store"
 	dom "swearjar/internal/services/hits/domain"
 )
 
 // CH implements the hits repo with ClickHouse
 type CH struct {
-	ch store.Clickhouse
+	ch     store.Clickhouse
+	verify bool
 }
 
 // NewCH constructs a new hits repo with a required CH instance
-func NewCH(ch store.Clickhouse) *CH { return &CH{ch: ch} }
+// verify re-counts each batch after insert (see verifyBatch); it costs one query per batch
+func NewCH(ch store.Clickhouse, verify bool) *CH { return &CH{ch: ch, verify: verify} }
 
 // WriteBatch inserts hits into swearjar.hits using a column list.
 // If LangCode is empty for any hit, we hydrate it from swearjar.utterances(id)
@@ -52,7 +56,7 @@ func (r *CH) WriteBatch(ctx context.Context, xs []dom.HitWrite) error {
 
 	const table = "swearjar.hits (" +
 		"id, utterance_id, created_at, source, repo_hid, actor_hid, " +
-		"lang_code, term, category, severity, " +
+		"lang_code, lang_reliable, term, category, severity, " +
 		"ctx_action, target_type, target_id, target_name, target_span_start, target_span_end, target_distance, " +
 		"span_start, span_end, " +
 		"detector_version, detector_source, pre_context, post_context, zones, " +
@@ -71,6 +75,15 @@ func (r *CH) WriteBatch(ctx context.Context, xs []dom.HitWrite) error {
 			lang = h.LangCode
 		}
 
+		// lang_reliable -> Nullable(UInt8)
+		var langRel any
+		if h.LangReliable != nil {
+			langRel = uint8(0)
+			if *h.LangReliable {
+				langRel = uint8(1)
+			}
+		}
+
 		// detector_source -> Enum8 label
 		dsrc := h.DetectorSource
 		if dsrc == "" {
@@ -128,6 +141,7 @@ func (r *CH) WriteBatch(ctx context.Context, xs []dom.HitWrite) error {
 			[]byte(h.RepoHID),              // repo_hid
 			[]byte(h.ActorHID),             // actor_hid
 			lang,                           // lang_code (Nullable)
+			langRel,                        // lang_reliable (Nullable(UInt8))
 
 			h.Term,     // term
 			h.Category, // category (Enum8 label)
@@ -153,7 +167,55 @@ func (r *CH) WriteBatch(ctx context.Context, xs []dom.HitWrite) error {
 		})
 	}
 
-	return r.ch.Insert(ctx, table, rows)
+	if err := r.ch.Insert(ctx, table, rows); err != nil {
+		return err
+	}
+	if r.verify {
+		return r.verifyBatch(ctx, batchID, xs)
+	}
+	return nil
+}
+
+// verifyBatch checks the server holds every distinct hit id just sent under batchID, so a
+// chunk lost mid-insert surfaces as a retryable Unavailable error instead of silently
+// shrinking the batch. Needs synchronous inserts: with async_insert and wait=false the
+// rows may not be visible yet. Replays reuse ids and batch ids, so >= is the success test
+func (r *CH) verifyBatch(ctx context.Context, batchID uint64, xs []dom.HitWrite) error {
+	ids := make(map[string]struct{}, len(xs))
+	lo, hi := xs[0].CreatedAt.UTC(), xs[0].CreatedAt.UTC()
+	for _, h := range xs {
+		ids[h.DeterministicUUID().String()] = struct{}{}
+		lo, hi = minTime(lo, h.CreatedAt.UTC()), maxTime(hi, h.CreatedAt.UTC())
+	}
+
+	// created_at bounds let CH prune partitions; widened to whole seconds since the column
+	// keeps milliseconds only and the table is not ordered by batch id
+	got, err := r.ch.ScalarUInt64(ctx, `
+		SELECT uniqExact(id) FROM swearjar.hits
+		WHERE ingest_batch_id = ? AND created_at BETWEEN ? AND ?
+	`, batchID, lo.Truncate(time.Second), hi.Truncate(time.Second).Add(time.Second))
+	if err != nil {
+		return err
+	}
+	if want := uint64(len(ids)); got < want {
+		return perr.Newf(perr.ErrorCodeUnavailable,
+			"hits: batch %d verify: server has %d of %d rows", batchID, got, want)
+	}
+	return nil
+}
+
+func minTime(a, b time.Time) time.Time {
+	if b.Before(a) {
+		return b
+	}
+	return a
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
 }
 
 // lookupLangByUtterance returns a map[utterance_id]string for a set of IDs.
@@ -206,6 +268,8 @@ func (r *CH) lookupLangByUtterance(ctx context.Context, uttIDs []string) (map[st
 }
 
 // ListSamples returns hits joined with utterances with keyset pagination.
+// Hits are stored one row per span; rows for the same term in an utterance are folded
+// into a single Sample carrying every span so callers can highlight/mask all of them.
 // Keyset: (u.created_at, u.id) > (after.CreatedAt, toUUID(after.UtteranceID))
 func (r *CH) ListSamples(
 	ctx context.Context,
@@ -222,7 +286,9 @@ func (r *CH) ListSamples(
 	  u.repo_name,
 	  u.lang_code,
 	  u.source,
-	  h.term, h.category, h.severity, h.span_start, h.span_end
+	  h.term, h.category, h.severity,
+	  arrayMap(t -> t.1, arraySort(groupArray((h.span_start, h.span_end)))) AS starts,
+	  arrayMap(t -> t.2, arraySort(groupArray((h.span_start, h.span_end)))) AS ends
 	FROM swearjar.hits AS h
 	INNER JOIN swearjar.utterances AS u ON u.id = h.utterance_id
 	WHERE u.created_at >= ? AND u.created_at < ?
@@ -257,12 +323,17 @@ func (r *CH) ListSamples(
 		q += "  AND h.severity = ?\n"
 		args = append(args, f.Severity)
 	}
+	if f.MinSeverity > 0 {
+		q += "  AND toInt8(h.severity) >= ?\n"
+		args = append(args, f.MinSeverity)
+	}
 	if f.Version != nil {
 		q += "  AND h.detector_version = ?\n"
 		args = append(args, *f.Version)
 	}
 
-	q += "ORDER BY u.created_at, h.utterance_id, h.span_start\nLIMIT ?"
+	q += "GROUP BY h.utterance_id, u.created_at, u.repo_name, u.lang_code, u.source, h.term, h.category, h.severity\n"
+	q += "ORDER BY u.created_at, h.utterance_id, starts[1]\nLIMIT ?"
 	args = append(args, limit)
 
 	rows, err := r.ch.Query(ctx, q, args...)
@@ -275,7 +346,7 @@ func (r *CH) ListSamples(
 	var last dom.AfterKey
 	for rows.Next() {
 		var s dom.Sample
-		var spanStart, spanEnd int32
+		var starts, ends []int32
 
 		if err := rows.Scan(
 			&s.UtteranceID,
@@ -286,14 +357,16 @@ func (r *CH) ListSamples(
 			&s.Term,
 			&s.Category,
 			&s.Severity,
-			&spanStart,
-			&spanEnd,
+			&starts,
+			&ends,
 		); err != nil {
 			return nil, dom.AfterKey{}, err
 		}
 
-		s.SpanStart = int(spanStart)
-		s.SpanEnd = int(spanEnd)
+		s.Spans = make([][2]int, 0, len(starts))
+		for i := range min(len(starts), len(ends)) {
+			s.Spans = append(s.Spans, [2]int{int(starts[i]), int(ends[i])})
+		}
 
 		out = append(out, s)
 		last = dom.AfterKey{CreatedAt: s.CreatedAt, UtteranceID: s.UtteranceID}
@@ -334,17 +407,18 @@ func (r *CH) AggByLang(ctx context.Context, w dom.Window, f dom.Filters) ([]dom.
 	var out []dom.AggByLangRow
 	for rows.Next() {
 		var rrow dom.AggByLangRow
-		var detVer int32
+		var hits uint64  // count() is UInt64
+		var detVer int32 // detector_version is Int32
 
 		if err := rows.Scan(
 			&rrow.Day,
 			&rrow.LangCode,
-			&rrow.Hits,
+			&hits,
 			&detVer,
 		); err != nil {
 			return nil, err
 		}
-		rrow.DetectorVersion = int(detVer)
+		rrow.Hits, rrow.DetectorVersion = int64(hits), int(detVer)
 		out = append(out, rrow)
 	}
 	return out, rows.Err()
@@ -384,9 +458,11 @@ func (r *CH) AggByRepo(
 	var out []dom.AggByRepoRow
 	for rows.Next() {
 		var rrow dom.AggByRepoRow
-		if err := rows.Scan(&rrow.RepoName, &rrow.Hits); err != nil {
+		var hits uint64 // count() is UInt64
+		if err := rows.Scan(&rrow.RepoName, &hits); err != nil {
 			return nil, err
 		}
+		rrow.Hits = int64(hits)
 		out = append(out, rrow)
 	}
 	return out, rows.Err()
@@ -420,9 +496,11 @@ func (r *CH) AggByCategory(ctx context.Context, w dom.Window, f dom.Filters) ([]
 	var out []dom.AggByCategoryRow
 	for rows.Next() {
 		var rrow dom.AggByCategoryRow
-		if err := rows.Scan(&rrow.Category, &rrow.Severity, &rrow.Hits); err != nil {
+		var hits uint64 // count() is UInt64
+		if err := rows.Scan(&rrow.Category, &rrow.Severity, &hits); err != nil {
 			return nil, err
 		}
+		rrow.Hits = int64(hits)
 		out = append(out, rrow)
 	}
 	return out, rows.Err()
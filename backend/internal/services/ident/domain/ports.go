@@ -3,9 +3,9 @@ package domain
 
 import (
 	"context"
-	"crypto/sha256"
 	"encoding/hex"
-	"strconv"
+
+	"swearjar/internal/core/hidscheme"
 )
 
 type (
@@ -59,12 +59,12 @@ type Ports interface {
 
 // RepoHID32 computes the HID32 for a repo given its GitHub numeric ID
 func RepoHID32(id int64) HID32 {
-	return sha256.Sum256([]byte("repo:" + strconv.FormatInt(id, 10)))
+	return hidscheme.Repo(id)
 }
 
 // ActorHID32 computes the HID32 for an actor given its GitHub numeric ID
 func ActorHID32(id int64) HID32 {
-	return sha256.Sum256([]byte("actor:" + strconv.FormatInt(id, 10)))
+	return hidscheme.Actor(id)
 }
 
 // Bytes returns the slice form of the HID32
@@ -9,6 +9,9 @@ import (
 	"swearjar/internal/services/ident/domain"
 )
 
+// ensureMaxAttempts bounds tx retries for principal/map upserts
+const ensureMaxAttempts = 3
+
 // Reusable error when resolver isn't wired yet
 var errResolverNotImplemented = errors.New("ident.ResolverPort not implemented")
 
@@ -37,8 +40,9 @@ func (s *Svc) EnsurePrincipalsAndMaps(
 	if len(repos) == 0 && len(actors) == 0 {
 		return nil
 	}
-	// Single tx to guarantee temp-table connection affinity
-	return s.db.Tx(ctx, func(q repokit.Queryer) error {
+	// Single tx to guarantee temp-table connection affinity; concurrent hours upsert
+	// overlapping principals, so deadlocks/serialization aborts are retried
+	return repokit.TxRetry(ctx, s.db, ensureMaxAttempts, func(q repokit.Queryer) error {
 		return s.binder.Bind(q).EnsurePrincipalsAndMaps(ctx, repos, actors)
 	})
 }
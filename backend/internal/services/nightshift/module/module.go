@@ -24,9 +24,21 @@ type Module struct {
 	ports Ports
 }
 
-// New constructs and wires the Nightshift module using deps.Cfg
-func New(deps modkit.Deps) *Module {
+// New constructs and wires the Nightshift module using deps.Cfg, with non-zero overrides
+// (the CLI's flags) taking precedence
+func New(deps modkit.Deps, overrides Options) *Module {
 	opts := FromConfig(deps.Cfg)
+	if overrides.Workers != 0 {
+		opts.Workers = overrides.Workers
+	}
+	if overrides.DetectorVersion != 0 {
+		opts.DetectorVersion = overrides.DetectorVersion
+	}
+	if overrides.RetentionMode != "" {
+		opts.RetentionMode = overrides.RetentionMode
+	}
+	// bool override wins, like the -ns-leases flag it carries
+	opts.EnableLeases = overrides.EnableLeases
 
 	binder := nsrepo.NewHybrid(deps.CH)
 
@@ -63,5 +75,5 @@ func (m *Module) MountRoutes(_ httpkit.Router) {}
 
 // Register convenience: allow others to resolve our ports via registry
 func Register(deps modkit.Deps) {
-	modreg.Register("nightshift", New(deps))
+	modreg.Register("nightshift", New(deps, FromConfig(deps.Cfg)))
 }
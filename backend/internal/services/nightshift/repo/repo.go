@@ -8,6 +8,7 @@ import (
 	"strings"
 	"time"
 
+	"swearjar/internal/core/termid"
 	"swearjar/internal/modkit/repokit"
 	"swearjar/internal/platform/store"
 	nsdom "swearjar/internal/services/nightshift/domain"
@@ -103,7 +104,7 @@ func (s *hybridStore) WriteArchives(ctx context.Context, hour time.Time, detver
 		  u.source_detail,
 		  u.lang_code, u.lang_confidence, u.lang_reliable, u.sentiment_score,
 		  length(u.text_raw)                             AS text_len,
-		  cityHash64(lower(h.term))                      AS term_id,
+		  `+termid.SQL("h.term")+`                      AS term_id,
 		  h.term,
 		  h.category, h.severity,
 		  h.ctx_action, h.target_type, h.target_id, h.target_name,
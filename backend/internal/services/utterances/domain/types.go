@@ -23,6 +23,10 @@ type ListInput struct {
 	ActorLogin string
 	ActorID    *int64
 	LangCode   string
+
+	// TextRaw also reads text_raw into Row.TextRaw (detect's shouting signal); off by default
+	// since it roughly doubles the bytes read
+	TextRaw bool
 }
 
 // Row is the minimal utterance view shared across consumers
@@ -35,4 +39,5 @@ type Row struct {
 	SourceDetail string
 	LangCode     *string
 	TextNorm     string // normalized; service guarantees non-empty when text exists
+	TextRaw      string // original text; only filled when ListInput.TextRaw is set
 }
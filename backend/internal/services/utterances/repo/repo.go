@@ -19,6 +19,10 @@ func NewCH(ch store.Clickhouse) *CH { return &CH{ch: ch} }
 
 // List returns up to hardLimit rows ordered by (created_at, id)
 func (r *CH) List(ctx context.Context, in dom.ListInput, limit int) ([]dom.Row, dom.AfterKey, error) {
+	raw := "''"
+	if in.TextRaw {
+		raw = "text_raw"
+	}
 	q := `
 		SELECT
 			id,
@@ -28,7 +32,8 @@ func (r *CH) List(ctx context.Context, in dom.ListInput, limit int) ([]dom.Row,
 			source,
 			source_detail,
 			lang_code,
-			coalesce(text_normalized, '') AS text_norm
+			coalesce(text_normalized, '') AS text_norm,
+			` + raw + ` AS text_raw
 		FROM swearjar.utterances
 		WHERE created_at >= ? AND created_at < ?
 	`
@@ -72,6 +77,7 @@ func (r *CH) List(ctx context.Context, in dom.ListInput, limit int) ([]dom.Row,
 			&it.ActorHID,
 			&it.Source, &it.SourceDetail,
 			&it.LangCode, &it.TextNorm,
+			&it.TextRaw,
 		); err != nil {
 			return nil, dom.AfterKey{}, err
 		}